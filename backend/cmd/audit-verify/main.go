@@ -0,0 +1,152 @@
+// audit-verify checks an exported audit bundle offline, without database
+// access: every entry's hash-chain hash, the links between adjacent entries,
+// every Merkle inclusion proof, and the Ed25519 signature on every
+// checkpoint those proofs use.
+//
+// Bundles come from GET /api/audit/export?format=bundle, or from
+// GET /api/audit/{id}/proof for a single entry (the response envelope is
+// accepted as is). Exported bundles also carry a detached signature over
+// the whole bundle, which is checked against the same keys and covers
+// entries no checkpoint anchors yet; -require-signature rejects bundles
+// without one.
+//
+// Usage:
+//
+//	go run ./cmd/audit-verify -pubkey <base64 key> audit-bundle.json
+//
+// -pubkey takes a comma-separated list when the signing key has been
+// rotated. It should come from somewhere other than the bundle; the server
+// logs its key at startup. -trust-bundle-key uses the key embedded in the
+// bundle instead, which only proves the bundle is self-consistent.
+//
+// The report is printed as JSON. The exit status is 0 when the bundle
+// verifies, 1 when it does not, and 2 on usage or read errors.
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+)
+
+func main() {
+	pubKeys := flag.String("pubkey", "", "trusted checkpoint public key(s), base64, comma-separated")
+	trustBundleKey := flag.Bool("trust-bundle-key", false, "verify against the key embedded in the bundle")
+	requireSignature := flag.Bool("require-signature", false, "fail bundles without a detached signature")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: audit-verify -pubkey <base64 key>[,<key>...] <bundle.json | ->")
+		os.Exit(2)
+	}
+
+	valid, err := run(flag.Arg(0), *pubKeys, *trustBundleKey, *requireSignature, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "audit-verify:", err)
+		os.Exit(2)
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+func run(path, pubKeys string, trustBundleKey, requireSignature bool, out io.Writer) (bool, error) {
+	var raw []byte
+	var err error
+	if path == "-" {
+		raw, err = io.ReadAll(os.Stdin)
+	} else {
+		raw, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return false, fmt.Errorf("read bundle: %w", err)
+	}
+
+	bundle, signed, err := parseBundle(raw)
+	if err != nil {
+		return false, err
+	}
+
+	var trusted []ed25519.PublicKey
+	for _, s := range strings.Split(pubKeys, ",") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		pub, err := auditchain.ParsePublicKey(s)
+		if err != nil {
+			return false, err
+		}
+		trusted = append(trusted, pub)
+	}
+	if trustBundleKey {
+		pub, err := auditchain.ParsePublicKey(bundle.PublicKey)
+		if err != nil {
+			return false, fmt.Errorf("bundle key: %w", err)
+		}
+		trusted = append(trusted, pub)
+	}
+	if len(trusted) == 0 {
+		return false, errors.New("no trusted key: pass -pubkey or -trust-bundle-key")
+	}
+
+	var report *auditchain.BundleReport
+	if signed != nil {
+		if report, err = auditchain.VerifySignedBundle(signed, trusted...); err != nil {
+			return false, err
+		}
+	} else {
+		report = auditchain.VerifyBundle(bundle, trusted...)
+		if requireSignature {
+			report.Failures = append([]auditchain.BundleFailure{{Reason: "bundle is not signed"}}, report.Failures...)
+			report.Valid = false
+		}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return false, fmt.Errorf("write report: %w", err)
+	}
+	return report.Valid, nil
+}
+
+// parseBundle accepts a bare or signed bundle, either of them optionally
+// wrapped in the API response envelope. For a signed bundle it also
+// returns the signed wrapper.
+func parseBundle(raw []byte) (*auditchain.Bundle, *auditchain.SignedBundle, error) {
+	var probe struct {
+		Format string          `json:"format"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, nil, fmt.Errorf("parse bundle: %w", err)
+	}
+	switch {
+	case len(probe.Data) > 0 && string(probe.Data) != "null":
+		return parseBundle(probe.Data)
+	case probe.Format == auditchain.SignedBundleFormat:
+		var signed auditchain.SignedBundle
+		if err := json.Unmarshal(raw, &signed); err != nil {
+			return nil, nil, fmt.Errorf("parse signed bundle: %w", err)
+		}
+		bundle, err := signed.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		return bundle, &signed, nil
+	case probe.Format != "":
+		return nil, nil, fmt.Errorf("unsupported bundle format %q", probe.Format)
+	}
+
+	var bundle auditchain.Bundle
+	if err := json.Unmarshal(raw, &bundle); err != nil {
+		return nil, nil, fmt.Errorf("parse bundle: %w", err)
+	}
+	return &bundle, nil, nil
+}
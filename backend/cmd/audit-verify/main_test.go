@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// Bundle file forms.
+const (
+	plainBundle  = iota
+	wrappedProof // in the API response envelope, as the proof route returns it
+	signedBundle // with a detached signature, as the export route returns it
+)
+
+// writeBundle writes a one-entry bundle, anchored and signed with key, in
+// the given form and returns its path.
+func writeBundle(t *testing.T, key ed25519.PrivateKey, form int) string {
+	t.Helper()
+	prev := ""
+	entry := model.AuditLog{
+		ID: "entry-1", TenantID: "org-1", Seq: 1, PrevHash: &prev, Action: model.AuditDataExport,
+		Details: json.RawMessage(`{"format": "json"}`), CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+	hash := auditchain.EntryHash(prev, &entry)
+	entry.DetailsHash = &hash
+
+	tree := auditchain.NewTree([]string{hash})
+	cp := model.AuditCheckpoint{ID: "cp-1", TenantID: "org-1", FromSeq: 1, ToSeq: 1, RootHash: tree.Root(), CreatedAt: entry.CreatedAt}
+	auditchain.SignCheckpoint(&cp, key)
+	path, _ := tree.Proof(0)
+
+	bundle := &auditchain.Bundle{
+		Version:     auditchain.BundleVersion,
+		PublicKey:   auditchain.EncodePublicKey(key.Public().(ed25519.PublicKey)),
+		Entries:     []auditchain.BundleEntry{auditchain.NewBundleEntry(entry, &auditchain.InclusionProof{CheckpointID: cp.ID, TreeSize: 1, Path: path})},
+		Checkpoints: []model.AuditCheckpoint{cp},
+	}
+	var v interface{} = bundle
+	switch form {
+	case wrappedProof:
+		v = map[string]interface{}{"success": true, "data": bundle}
+	case signedBundle:
+		sb, err := auditchain.SignBundle(bundle, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v = sb
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(file, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestRun(t *testing.T) {
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	trusted := auditchain.EncodePublicKey(pub)
+
+	tests := []struct {
+		name        string
+		form        int
+		pubKeys     string
+		trustBundle bool
+		requireSig  bool
+		valid       bool
+	}{
+		{"trusted key", plainBundle, trusted, false, false, true},
+		{"proof response envelope", wrappedProof, trusted, false, false, true},
+		{"rotated keys", plainBundle, auditchain.EncodePublicKey(other) + "," + trusted, false, false, true},
+		{"untrusted key", plainBundle, auditchain.EncodePublicKey(other), false, false, false},
+		{"bundle key", plainBundle, "", true, false, true},
+		{"signed bundle", signedBundle, trusted, false, true, true},
+		{"signed bundle, bundle key", signedBundle, "", true, true, true},
+		{"signed bundle, untrusted key", signedBundle, auditchain.EncodePublicKey(other), false, false, false},
+		{"unsigned bundle, signature required", plainBundle, trusted, false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			valid, err := run(writeBundle(t, key, tt.form), tt.pubKeys, tt.trustBundle, tt.requireSig, &out)
+			if err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if valid != tt.valid {
+				t.Errorf("valid = %v, want %v; report: %s", valid, tt.valid, out.String())
+			}
+			var report auditchain.BundleReport
+			if err := json.Unmarshal(out.Bytes(), &report); err != nil || report.Entries != 1 {
+				t.Errorf("report = %s, %v", out.String(), err)
+			}
+		})
+	}
+}
+
+func TestRun_RequiresTrustedKey(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := run(writeBundle(t, key, plainBundle), "", false, false, &bytes.Buffer{}); err == nil {
+		t.Error("run verified without any trusted key")
+	}
+}
+
+func TestRun_SignedBundleTampered(t *testing.T) {
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	file := writeBundle(t, key, signedBundle)
+
+	// Dropping an entry leaves every checkpoint and proof left in the
+	// bundle valid; only the bundle signature catches it.
+	raw, _ := os.ReadFile(file)
+	var sb auditchain.SignedBundle
+	if err := json.Unmarshal(raw, &sb); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := sb.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle.Entries = []auditchain.BundleEntry{}
+	sb.Bundle, _ = json.Marshal(bundle)
+	raw, _ = json.Marshal(sb)
+	os.WriteFile(file, raw, 0o600)
+
+	var out bytes.Buffer
+	valid, err := run(file, auditchain.EncodePublicKey(pub), false, false, &out)
+	if err != nil || valid || !bytes.Contains(out.Bytes(), []byte("signature is not valid")) {
+		t.Errorf("valid = %v, %v; want a signature failure: %s", valid, err, out.String())
+	}
+}
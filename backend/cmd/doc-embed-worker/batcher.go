@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// chunkBatcher coalesces chunks from concurrent push requests into a single
+// EmbedAndStore call. Pub/Sub delivers one chunk per message, so without it a
+// large document costs one embedding request per chunk. Each caller blocks
+// until the batch containing its chunks has been embedded and stored.
+type chunkBatcher struct {
+	embedder chunkEmbedder
+	maxBatch int
+	maxWait  time.Duration
+	timeout  time.Duration // per-flush deadline, independent of any one request
+
+	mu      sync.Mutex
+	pending []pendingEmbed
+	size    int
+	timer   *time.Timer
+}
+
+type pendingEmbed struct {
+	chunks []service.Chunk
+	done   chan error
+}
+
+func newChunkBatcher(embedder chunkEmbedder, maxBatch int, maxWait time.Duration) *chunkBatcher {
+	if maxBatch <= 0 {
+		maxBatch = 1
+	}
+	return &chunkBatcher{
+		embedder: embedder,
+		maxBatch: maxBatch,
+		maxWait:  maxWait,
+		timeout:  2 * time.Minute,
+	}
+}
+
+// EmbedAndStore queues chunks and waits for their batch to flush. A batch is
+// flushed when it reaches maxBatch chunks or maxWait after its first chunk.
+func (b *chunkBatcher) EmbedAndStore(ctx context.Context, chunks []service.Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	done := make(chan error, 1)
+
+	b.mu.Lock()
+	b.pending = append(b.pending, pendingEmbed{chunks: chunks, done: done})
+	b.size += len(chunks)
+	var ready []pendingEmbed
+	if b.size >= b.maxBatch {
+		ready = b.takeLocked()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.maxWait, b.flushTimer)
+	}
+	b.mu.Unlock()
+
+	if ready != nil {
+		go b.flush(ready)
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *chunkBatcher) flushTimer() {
+	b.mu.Lock()
+	ready := b.takeLocked()
+	b.mu.Unlock()
+	if ready != nil {
+		b.flush(ready)
+	}
+}
+
+// takeLocked detaches the pending batch. Caller holds b.mu.
+func (b *chunkBatcher) takeLocked() []pendingEmbed {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	ready := b.pending
+	b.pending = nil
+	b.size = 0
+	return ready
+}
+
+// flush embeds the batch in one call. If that call fails and the batch holds
+// more than one caller, each caller's chunks are retried on their own so one
+// bad request (e.g. a chunk whose document was deleted mid-ingest) does not
+// fail every message that happened to share its batch.
+func (b *chunkBatcher) flush(batch []pendingEmbed) {
+	var all []service.Chunk
+	for _, p := range batch {
+		all = append(all, p.chunks...)
+	}
+
+	err := b.embed(all)
+	if err == nil || len(batch) == 1 {
+		for _, p := range batch {
+			p.done <- err
+		}
+		return
+	}
+	for _, p := range batch {
+		p.done <- b.embed(p.chunks)
+	}
+}
+
+func (b *chunkBatcher) embed(chunks []service.Chunk) error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	return b.embedder.EmbedAndStore(ctx, chunks)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// countingEmbedder records each EmbedAndStore call's batch size.
+type countingEmbedder struct {
+	mu      sync.Mutex
+	batches []int
+	err     error
+}
+
+func (c *countingEmbedder) EmbedAndStore(ctx context.Context, chunks []service.Chunk) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, len(chunks))
+	return c.err
+}
+
+func TestChunkBatcher_CoalescesConcurrentChunks(t *testing.T) {
+	inner := &countingEmbedder{}
+	b := newChunkBatcher(inner, 10, time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := b.EmbedAndStore(context.Background(), []service.Chunk{{Index: i}}); err != nil {
+				t.Errorf("EmbedAndStore() error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(inner.batches) != 1 || inner.batches[0] != 10 {
+		t.Errorf("batches = %v, want one batch of 10", inner.batches)
+	}
+}
+
+func TestChunkBatcher_FlushesAfterMaxWait(t *testing.T) {
+	inner := &countingEmbedder{}
+	b := newChunkBatcher(inner, 100, 20*time.Millisecond)
+
+	start := time.Now()
+	if err := b.EmbedAndStore(context.Background(), []service.Chunk{{Index: 0}}); err != nil {
+		t.Fatalf("EmbedAndStore() error: %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("partial batch flushed before maxWait")
+	}
+	if len(inner.batches) != 1 || inner.batches[0] != 1 {
+		t.Errorf("batches = %v, want one batch of 1", inner.batches)
+	}
+}
+
+func TestChunkBatcher_PropagatesErrorToEveryCaller(t *testing.T) {
+	inner := &countingEmbedder{err: fmt.Errorf("vertex unavailable")}
+	b := newChunkBatcher(inner, 2, time.Second)
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func(i int) {
+			errs <- b.EmbedAndStore(context.Background(), []service.Chunk{{Index: i}})
+		}(i)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err == nil {
+			t.Error("expected batch error for every caller")
+		}
+	}
+}
+
+// missingDocEmbedder fails any call that includes a chunk of the given document,
+// the way ChunkRepo.BulkInsert fails when a document was deleted mid-ingest.
+type missingDocEmbedder struct {
+	countingEmbedder
+	missing string
+}
+
+func (m *missingDocEmbedder) EmbedAndStore(ctx context.Context, chunks []service.Chunk) error {
+	m.countingEmbedder.EmbedAndStore(ctx, chunks)
+	for _, c := range chunks {
+		if c.DocumentID == m.missing {
+			return fmt.Errorf("document %s not found", c.DocumentID)
+		}
+	}
+	return nil
+}
+
+func TestChunkBatcher_MissingDocumentFailsOnlyItsCaller(t *testing.T) {
+	inner := &missingDocEmbedder{missing: "doc-gone"}
+	b := newChunkBatcher(inner, 3, time.Second)
+
+	docs := []string{"doc-1", "doc-gone", "doc-2"}
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, doc := range docs {
+		wg.Add(1)
+		go func(i int, doc string) {
+			defer wg.Done()
+			err := b.EmbedAndStore(context.Background(), []service.Chunk{{DocumentID: doc, Index: i}})
+			mu.Lock()
+			errs[doc] = err
+			mu.Unlock()
+		}(i, doc)
+	}
+	wg.Wait()
+
+	if errs["doc-gone"] == nil {
+		t.Error("caller with the missing document got nil error")
+	}
+	for _, doc := range []string{"doc-1", "doc-2"} {
+		if errs[doc] != nil {
+			t.Errorf("caller for %s got error %v, want nil", doc, errs[doc])
+		}
+	}
+	if len(inner.batches) != 4 || inner.batches[0] != 3 {
+		t.Errorf("batches = %v, want the batch of 3 then one retry per caller", inner.batches)
+	}
+}
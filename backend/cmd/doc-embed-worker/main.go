@@ -11,12 +11,15 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/connexus-ai/ragbox-backend/internal/gcpclient"
 	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/pii"
 	"github.com/connexus-ai/ragbox-backend/internal/repository"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 	"github.com/connexus-ai/ragbox-backend/internal/worker"
@@ -157,7 +160,12 @@ func main() {
 	}
 	defer pool.Close()
 
-	chunkRepo := repository.NewChunkRepo(pool)
+	keyWrapper, err := gcpclient.KeyWrapperFromEnv(ctx)
+	if err != nil {
+		slog.Error("init data key provider failed", "error", err)
+		os.Exit(1)
+	}
+	chunkRepo := repository.NewChunkRepo(pool).WithKeyring(repository.NewDataKeyring(pool, keyWrapper))
 	docRepo := repository.NewDocumentRepo(pool)
 
 	// Init embedding client
@@ -167,6 +175,52 @@ func main() {
 		os.Exit(1)
 	}
 	embedder := service.NewEmbedderService(embeddingAdapter, chunkRepo)
+	embedOpts := service.DefaultEmbedOptions()
+	embedOpts.Concurrency = envInt("EMBED_CONCURRENCY", embedOpts.Concurrency)
+	embedder.SetOptions(embedOpts)
+	embedder.SetVectorReuse(chunkRepo)
+
+	// Store PII/PHI findings per chunk for query-time redaction.
+	var nameList pii.Gazetteer
+	if path := os.Getenv("PII_NAME_LIST"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			slog.Error("open pii name list failed", "path", path, "error", err)
+			os.Exit(1)
+		}
+		list, err := pii.ParseNameList(f)
+		f.Close()
+		if err != nil {
+			slog.Error("parse pii name list failed", "path", path, "error", err)
+			os.Exit(1)
+		}
+		nameList = list
+	}
+	embedder.SetPIIScanner(service.NewRedactorService(pii.NewDetector(nameList), project))
+
+	// Stamp chunks with the active embedding space and follow re-embed cutovers.
+	bindSpace := func(space model.EmbeddingSpace) error {
+		adapter := embeddingAdapter
+		if space.Model != embeddingModel {
+			a, err := gcpclient.NewEmbeddingAdapter(ctx, project, embeddingLocation, space.Model)
+			if err != nil {
+				return err
+			}
+			adapter = a
+		}
+		embedder.SetEmbedding(space, adapter)
+		return nil
+	}
+	spaceRepo := repository.NewEmbeddingSpaceRepo(pool)
+	if space, err := spaceRepo.ActiveSpace(ctx); err != nil || space.IsZero() {
+		slog.Warn("no active embedding space, chunks stored unversioned", "error", err)
+	} else {
+		if err := bindSpace(space); err != nil {
+			slog.Error("bind embedding space failed", "space", space.Key(), "error", err)
+			os.Exit(1)
+		}
+		go service.NewEmbeddingSpaceWatcher(spaceRepo, space, 30*time.Second, bindSpace).Run(ctx)
+	}
 
 	// Init Redis for progress tracking
 	redisCli := service.NewRedisClient(redisAddr)
@@ -182,7 +236,20 @@ func main() {
 	finalizePublisher := worker.NewPublisher(psClient, "doc-finalize")
 	defer finalizePublisher.Close()
 
+	// Coalesce one-chunk push messages into batched embedding requests.
+	batcher := newChunkBatcher(embedder,
+		envInt("EMBED_BATCH_MAX", 64),
+		time.Duration(envInt("EMBED_BATCH_WAIT_MS", 200))*time.Millisecond)
+
 	worker.Run("doc-embed-worker", func(ctx context.Context, data []byte) error {
-		return processEmbed(ctx, data, embedder, redisCli, docRepo, finalizePublisher)
+		return processEmbed(ctx, data, batcher, redisCli, docRepo, finalizePublisher)
 	})
 }
+
+// envInt reads an integer env var, returning def when unset or invalid.
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
@@ -20,6 +20,7 @@ type mockCache struct {
 	invalidatedTenantID   string
 	invalidatedDocID      string
 	statsTenantID         string
+	broadcast             []service.CacheInvalidation
 }
 
 func (m *mockCache) InvalidateQueryCache(ctx context.Context, tenantID string) {
@@ -27,6 +28,11 @@ func (m *mockCache) InvalidateQueryCache(ctx context.Context, tenantID string) {
 	m.invalidatedTenantID = tenantID
 }
 
+func (m *mockCache) PublishCacheInvalidation(ctx context.Context, msg service.CacheInvalidation) error {
+	m.broadcast = append(m.broadcast, msg)
+	return nil
+}
+
 func (m *mockCache) InvalidateDocCache(ctx context.Context, documentID string) {
 	m.docCacheInvalidated = true
 	m.invalidatedDocID = documentID
@@ -121,6 +127,9 @@ func TestProcessFinalize_InvalidatesQueryCache(t *testing.T) {
 	if cache.invalidatedTenantID != "tenant-001" {
 		t.Errorf("invalidated tenant = %q, want %q", cache.invalidatedTenantID, "tenant-001")
 	}
+	if len(cache.broadcast) != 1 || cache.broadcast[0].UserID != "tenant-001" || len(cache.broadcast[0].DocumentIDs) != 0 {
+		t.Errorf("broadcast = %+v, want one user-wide invalidation for tenant-001", cache.broadcast)
+	}
 }
 
 func TestProcessFinalize_InvalidatesDocCache(t *testing.T) {
@@ -29,6 +29,7 @@ type finalizeInput struct {
 // cacheManager abstracts Redis cache operations for testability.
 type cacheManager interface {
 	InvalidateQueryCache(ctx context.Context, tenantID string)
+	PublishCacheInvalidation(ctx context.Context, msg service.CacheInvalidation) error
 	InvalidateDocCache(ctx context.Context, documentID string)
 	SetVaultStats(ctx context.Context, tenantID string, stats interface{})
 }
@@ -67,8 +68,16 @@ func processFinalize(ctx context.Context, data []byte, cache cacheManager, docRe
 
 	slog.Info("finalizing", "document_id", input.DocumentID, "tenant_id", input.TenantID)
 
-	// 1. Invalidate query caches for this tenant
+	// 1. Invalidate query caches for this tenant. The server's retrieval and
+	// response caches live in its instances' memory and Redis, so they are
+	// told via broadcast; a newly indexed document can change any answer.
 	cache.InvalidateQueryCache(ctx, input.TenantID)
+	if err := cache.PublishCacheInvalidation(ctx, service.CacheInvalidation{
+		Origin: "doc-finalize-worker",
+		UserID: input.TenantID,
+	}); err != nil {
+		slog.Error("cache invalidation broadcast failed", "tenant_id", input.TenantID, "error", err)
+	}
 
 	// 2. Invalidate doc metadata cache
 	cache.InvalidateDocCache(ctx, input.DocumentID)
@@ -119,11 +128,7 @@ func main() {
 
 	docRepo := repository.NewDocumentRepo(pool)
 	auditRepo := repository.NewAuditRepo(pool)
-	auditService, err := service.NewAuditService(auditRepo, nil)
-	if err != nil {
-		slog.Error("init audit failed", "error", err)
-		os.Exit(1)
-	}
+	auditService := service.NewAuditService(auditRepo, nil)
 
 	// Init Redis
 	redisCli := service.NewRedisClient(redisAddr)
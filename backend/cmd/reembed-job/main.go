@@ -0,0 +1,117 @@
+// reembed-job re-embeds every document chunk with a new embedding model while
+// retrieval keeps serving the current one. New vectors go to a shadow table;
+// the final cutover swaps them in and activates the new embedding space in a
+// single transaction. The API server and doc-embed-worker pick up the new
+// space on their next poll (EMBEDDING_SPACE_POLL_SECONDS).
+//
+// Usage:
+//
+//	DATABASE_URL=... GOOGLE_CLOUD_PROJECT=ragbox-sovereign-prod \
+//	  go run ./cmd/reembed-job -model text-embedding-005 -dimensions 768
+//
+// Resume a failed or interrupted job from its last saved cursor:
+//
+//	go run ./cmd/reembed-job -job <job-id>
+//
+// After cutover the job waits -sweep-after, then re-embeds any chunk that an
+// ingest worker stored with the old model before it observed the switch.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/gcpclient"
+	"github.com/connexus-ai/ragbox-backend/internal/repository"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func main() {
+	modelName := flag.String("model", "", "target embedding model (required for a new job)")
+	dimensions := flag.Int("dimensions", 768, "target embedding dimensions")
+	jobID := flag.String("job", "", "resume an existing job instead of starting a new one")
+	batchSize := flag.Int("batch", 100, "chunks per embedding API call")
+	sweepAfter := flag.Duration("sweep-after", 2*time.Minute, "wait before sweeping chunks stored with the old model (0 disables)")
+	flag.Parse()
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	if err := run(*modelName, *dimensions, *jobID, *batchSize, *sweepAfter); err != nil {
+		slog.Error("reembed job failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(modelName string, dimensions int, jobID string, batchSize int, sweepAfter time.Duration) error {
+	ctx := context.Background()
+	project := os.Getenv("GOOGLE_CLOUD_PROJECT")
+	location := os.Getenv("VERTEX_AI_EMBEDDING_LOCATION")
+	if location == "" {
+		location = "us-east4"
+	}
+
+	pool, err := pgxpool.New(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return fmt.Errorf("init db: %w", err)
+	}
+	defer pool.Close()
+	keyWrapper, err := gcpclient.KeyWrapperFromEnv(ctx)
+	if err != nil {
+		return fmt.Errorf("data key provider: %w", err)
+	}
+	store := repository.NewEmbeddingSpaceRepo(pool).WithKeyring(repository.NewDataKeyring(pool, keyWrapper))
+
+	if jobID != "" {
+		job, err := store.GetReembedJob(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		modelName = job.Target.Model
+	}
+	if modelName == "" {
+		return fmt.Errorf("-model or -job is required")
+	}
+
+	client, err := gcpclient.NewEmbeddingAdapter(ctx, project, location, modelName)
+	if err != nil {
+		return fmt.Errorf("init embedding: %w", err)
+	}
+	svc := service.NewReembedService(store, client)
+	svc.SetBatchSize(batchSize)
+
+	if jobID == "" {
+		job, err := svc.Start(ctx, modelName, dimensions)
+		if err != nil {
+			return err
+		}
+		jobID = job.ID
+	}
+
+	job, err := svc.Run(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	slog.Info("reembed job completed",
+		"job_id", job.ID,
+		"space", job.Target.Key(),
+		"processed", job.ProcessedChunks,
+	)
+
+	if sweepAfter <= 0 {
+		return nil
+	}
+	slog.Info("waiting before sweep", "duration", sweepAfter.String())
+	time.Sleep(sweepAfter)
+	repaired, err := svc.Sweep(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("sweep completed", "repaired", repaired)
+	return nil
+}
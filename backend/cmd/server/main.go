@@ -13,12 +13,17 @@ import (
 	"cloud.google.com/go/pubsub"
 	firebase "firebase.google.com/go/v4"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/auditsink"
 	"github.com/connexus-ai/ragbox-backend/internal/cache"
 	"github.com/connexus-ai/ragbox-backend/internal/config"
 	"github.com/connexus-ai/ragbox-backend/internal/gcpclient"
 	"github.com/connexus-ai/ragbox-backend/internal/handler"
 	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/pii"
 	"github.com/connexus-ai/ragbox-backend/internal/repository"
 	internalrouter "github.com/connexus-ai/ragbox-backend/internal/router"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
@@ -59,7 +64,32 @@ func run() error {
 		return fmt.Errorf("firebase auth client: %w", err)
 	}
 
-	authService := service.NewAuthService(authClient)
+	// Enterprise SSO (Okta, Azure AD, ...) alongside Firebase, chosen by issuer
+	var oidcProviders []*service.OIDCVerifier
+	if cfg.OIDCIssuer != "" {
+		roleMap, err := service.ParseRoleMap(cfg.OIDCRoleMap)
+		if err != nil {
+			return fmt.Errorf("oidc role map: %w", err)
+		}
+		verifier, err := service.NewOIDCVerifier(service.OIDCConfig{
+			Issuer:      cfg.OIDCIssuer,
+			Audience:    cfg.OIDCAudience,
+			JWKSURL:     cfg.OIDCJWKSURL,
+			ProviderID:  cfg.OIDCProviderID,
+			UserIDClaim: cfg.OIDCUserIDClaim,
+			EmailClaim:  cfg.OIDCEmailClaim,
+			RoleClaim:   cfg.OIDCRoleClaim,
+			RoleMap:     roleMap,
+			CacheTTL:    time.Duration(cfg.OIDCJWKSCacheSec) * time.Second,
+		})
+		if err != nil {
+			return fmt.Errorf("oidc verifier: %w", err)
+		}
+		oidcProviders = append(oidcProviders, verifier)
+		slog.Info("oidc auth enabled", "issuer", cfg.OIDCIssuer)
+	}
+
+	authService := service.NewAuthService(authClient, oidcProviders...)
 
 	// ─── GCP clients ───────────────────────────────────────────────────
 
@@ -123,11 +153,25 @@ func run() error {
 	defer storageAdapter.Close()
 	slog.Info("cloud storage client initialized")
 
+	// Data encryption: extracted text and chunk content are sealed with
+	// per-tenant data keys wrapped by DATA_KEY_PROVIDER (migration 031).
+	keyWrapper, err := gcpclient.NewKeyWrapper(ctx, cfg.DataKeyProvider, cfg.DataKeyLocal,
+		gcpclient.KMSKeyName(cfg.GCPProject, cfg.KMSLocation, cfg.KMSKeyRing, cfg.KMSKeyName))
+	if err != nil {
+		return fmt.Errorf("data key provider: %w", err)
+	}
+	dataKeys := repository.NewDataKeyring(pool, keyWrapper)
+	if dataKeys != nil {
+		slog.Info("document text encryption enabled", "provider", cfg.DataKeyProvider, "kek", keyWrapper.KeyID())
+	} else {
+		slog.Warn("document text encryption disabled (DATA_KEY_PROVIDER not set)")
+	}
+
 	// ─── Repositories ──────────────────────────────────────────────────
 
-	docRepo := repository.NewDocumentRepo(pool)
+	docRepo := repository.NewDocumentRepo(pool).WithKeyring(dataKeys)
 	folderRepo := repository.NewFolderRepo(pool)
-	chunkRepo := repository.NewChunkRepo(pool)
+	chunkRepo := repository.NewChunkRepo(pool).WithKeyring(dataKeys)
 	auditRepo := repository.NewAuditRepo(pool)
 	userRepo := repository.NewUserRepo(pool)
 	personaRepo := repository.NewPersonaRepo(pool)
@@ -153,10 +197,58 @@ func run() error {
 	// Document service (signed URL generation + DB record creation)
 	docService := service.NewDocumentService(storageAdapter, docRepo, cfg.GCSBucketName, urlExpiry)
 
-	// Audit service (hash-chain + optional BigQuery)
-	auditService, err := service.NewAuditService(auditRepo, nil) // BQ disabled for now
-	if err != nil {
-		return fmt.Errorf("audit service: %w", err)
+	// External audit sinks, fed through the Postgres outbox
+	var auditSinks []service.AuditSink
+	if cfg.AuditSinkFile != "" {
+		fileSink, err := auditsink.NewFileSink(cfg.AuditSinkFile, int64(cfg.AuditSinkFileMaxMB)<<20)
+		if err != nil {
+			return fmt.Errorf("audit file sink: %w", err)
+		}
+		defer fileSink.Close()
+		auditSinks = append(auditSinks, fileSink)
+	}
+	if cfg.AuditSinkSyslogAddr != "" {
+		syslogSink, err := auditsink.NewSyslogSink(cfg.AuditSinkSyslogAddr)
+		if err != nil {
+			return fmt.Errorf("audit syslog sink: %w", err)
+		}
+		defer syslogSink.Close()
+		auditSinks = append(auditSinks, syslogSink)
+	}
+	if cfg.AuditSinkWebhookURL != "" {
+		if cfg.AuditSinkWebhookSecret == "" {
+			return fmt.Errorf("audit webhook sink: AUDIT_SINK_WEBHOOK_SECRET is required")
+		}
+		auditSinks = append(auditSinks, auditsink.NewWebhookSink(cfg.AuditSinkWebhookURL, cfg.AuditSinkWebhookSecret))
+	}
+	var auditDispatcher *service.AuditDispatcher
+	if len(auditSinks) > 0 {
+		auditDispatcher = service.NewAuditDispatcher(auditRepo, auditSinks...)
+		slog.Info("audit sinks enabled", "sinks", auditDispatcher.SinkNames())
+	}
+
+	// Audit service (hash-chain + optional BigQuery); with sinks configured,
+	// every entry is queued for them in the transaction that appends it
+	auditAppender := auditRepo
+	if auditDispatcher != nil {
+		auditAppender = auditRepo.WithOutbox(auditDispatcher.SinkNames())
+	}
+	auditService := service.NewAuditService(auditAppender, nil) // BQ disabled for now
+
+	// Signed Merkle checkpoints over the audit chains, for inclusion proofs
+	var auditCheckpoints handler.AuditCheckpoints
+	if cfg.AuditCheckpointKey != "" {
+		key, err := auditchain.ParsePrivateKey(cfg.AuditCheckpointKey)
+		if err != nil {
+			return fmt.Errorf("audit checkpoint key: %w", err)
+		}
+		checkpointer := service.NewAuditCheckpointer(auditRepo, key)
+		auditCheckpoints = checkpointer
+		checkpointCtx, stopCheckpoints := context.WithCancel(ctx)
+		defer stopCheckpoints()
+		go checkpointer.Run(checkpointCtx, time.Duration(cfg.AuditCheckpointSec)*time.Second)
+		slog.Info("audit checkpoints enabled", "key_id", auditchain.KeyID(checkpointer.PublicKey()),
+			"public_key", auditchain.EncodePublicKey(checkpointer.PublicKey()))
 	}
 	slog.Info("audit service initialized")
 
@@ -173,7 +265,7 @@ func run() error {
 	retrieverService := service.NewRetrieverService(embeddingAdapter, chunkRepo)
 
 	// BM25 full-text search (hybrid retrieval via Reciprocal Rank Fusion — STORY-154)
-	bm25Repo := repository.NewBM25Repository(pool)
+	bm25Repo := repository.NewBM25Repository(pool).WithKeyring(dataKeys)
 	retrieverService.SetBM25(bm25Repo)
 	slog.Info("hybrid BM25 search enabled")
 
@@ -187,14 +279,66 @@ func run() error {
 	// Pipeline service (document processing: parse → PII scan → chunk → embed)
 	chunkerSvc := service.NewSemanticChunkerService()
 	embedderSvc := service.NewEmbedderService(embeddingAdapter, chunkRepo)
+	embedOpts := service.DefaultEmbedOptions()
+	embedOpts.Concurrency = cfg.EmbedConcurrency
+	embedderSvc.SetOptions(embedOpts)
+	embedderSvc.SetVectorReuse(chunkRepo) // skip re-embedding unchanged chunks on reprocess
+
+	// Embedding space binding: query vectors and newly stored chunks use the
+	// active space's model, and retrieval only compares vectors within it.
+	// The watcher switches models after a re-embed cutover (cmd/reembed-job).
+	spaceRepo := repository.NewEmbeddingSpaceRepo(pool).WithKeyring(dataKeys)
+	bindEmbeddingSpace := func(space model.EmbeddingSpace) error {
+		adapter := embeddingAdapter
+		if space.Model != cfg.EmbeddingModel {
+			a, err := gcpclient.NewEmbeddingAdapter(ctx, cfg.GCPProject, cfg.EmbeddingLocation, space.Model)
+			if err != nil {
+				return err
+			}
+			adapter = a
+		}
+		retrieverService.SetEmbedding(space, adapter)
+		embedderSvc.SetEmbedding(space, adapter)
+		return nil
+	}
+	activeSpace, err := spaceRepo.ActiveSpace(ctx)
+	if err != nil || activeSpace.IsZero() {
+		slog.Warn("no active embedding space, retrieval is unversioned", "error", err)
+	} else {
+		if err := bindEmbeddingSpace(activeSpace); err != nil {
+			return fmt.Errorf("embedding space %s: %w", activeSpace.Key(), err)
+		}
+		spaceCtx, stopSpaceWatch := context.WithCancel(ctx)
+		defer stopSpaceWatch()
+		spaceWatcher := service.NewEmbeddingSpaceWatcher(spaceRepo, activeSpace,
+			time.Duration(cfg.EmbeddingSpacePollSec)*time.Second, bindEmbeddingSpace)
+		go spaceWatcher.Run(spaceCtx)
+		slog.Info("embedding space bound", "space", activeSpace.Key(), "dimensions", activeSpace.Dimensions)
+	}
+
+	// Offline PII/PHI detector for the pipeline's scan step
+	var nameList pii.Gazetteer
+	if cfg.PIINameList != "" {
+		f, err := os.Open(cfg.PIINameList)
+		if err != nil {
+			return fmt.Errorf("pii name list: %w", err)
+		}
+		list, err := pii.ParseNameList(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("pii name list: %w", err)
+		}
+		nameList = list
+	}
+	redactorSvc := service.NewRedactorService(pii.NewDetector(nameList), cfg.GCPProject)
+	embedderSvc.SetPIIScanner(redactorSvc) // findings stored per chunk for query-time redaction
+	slog.Info("pii detector initialized", "name_list", cfg.PIINameList)
 
 	var pipelineSvc *service.PipelineService
 	if docAIAdapter != nil {
 		processorName := fmt.Sprintf("projects/%s/locations/%s/processors/%s",
 			cfg.GCPProject, cfg.DocAILocation, cfg.DocAIProcessorID)
 		parserSvc := service.NewParserService(docAIAdapter, processorName, storageAdapter, cfg.GCSBucketName)
-		dlpAdapter := gcpclient.NewStubDLPAdapter()
-		redactorSvc := service.NewRedactorService(dlpAdapter, cfg.GCPProject)
 
 		pipelineSvc = service.NewPipelineService(
 			docRepo, parserSvc, redactorSvc, chunkerSvc, embedderSvc, auditService, cfg.GCSBucketName,
@@ -202,10 +346,9 @@ func run() error {
 		slog.Info("pipeline service initialized", "parser", "document_ai")
 	} else {
 		textParser := gcpclient.NewTextParser(storageAdapter)
-		noopRedactor := gcpclient.NewNoopRedactor()
 
 		pipelineSvc = service.NewPipelineService(
-			docRepo, textParser, noopRedactor, chunkerSvc, embedderSvc, auditService, cfg.GCSBucketName,
+			docRepo, textParser, redactorSvc, chunkerSvc, embedderSvc, auditService, cfg.GCSBucketName,
 		)
 		slog.Info("pipeline service initialized", "parser", "text_fallback")
 	}
@@ -248,6 +391,28 @@ func run() error {
 	usageSvc := service.NewUsageService(usageRepo)
 	slog.Info("usage service initialized (token allocation enforcement active)")
 
+	// Organizations — shared vaults, member roles, pooled usage
+	orgRepo := repository.NewOrgRepo(pool)
+	orgSvc := service.NewOrganizationService(orgRepo, userRepo.GetEmail)
+	vaultRepo := repository.NewVaultRepo(pool)
+	sharingSvc := service.NewSharingService(repository.NewShareRepo(pool), orgRepo, auditService)
+
+	// Legal holds — freeze evidence for a matter (triggers enforce them too)
+	legalHoldRepo := repository.NewLegalHoldRepo(pool)
+	legalHoldSvc := service.NewLegalHoldService(legalHoldRepo, orgRepo, auditService)
+
+	// PII redaction — per-vault policies over the findings stored at ingest
+	piiDefaults, err := service.PIIDefaultPolicy(cfg.PIIRedactTypes, cfg.PIIRedactExemptRoles)
+	if err != nil {
+		return fmt.Errorf("PII_REDACT_TYPES / PII_REDACT_EXEMPT_ROLES: %w", err)
+	}
+	piiSvc := service.NewPIIRedactionService(repository.NewPIIPolicyRepo(pool), userRepo, redactorSvc, auditService, piiDefaults)
+	slog.Info("pii redaction initialized", "default_info_types", piiDefaults.InfoTypes, "default_exempt_roles", piiDefaults.ExemptRoles)
+
+	// Personal access tokens for programmatic API access
+	apiTokenSvc := service.NewAPITokenService(repository.NewAPITokenRepo(pool), auditService)
+	usageSvc.SetAccountResolver(orgSvc.UsageAccount)
+
 	// Proactive insights (EPIC-028 Phase 4)
 	insightRepo := repository.NewInsightRepo(pool)
 	insightScannerSvc := service.NewInsightScannerService(genAI, insightRepo, chunkRepo)
@@ -263,6 +428,32 @@ func run() error {
 		return tier
 	}
 
+	// Quota enforcement for every TierLimits metric
+	usageSvc.SetTierResolver(userTierFunc)
+	usageSvc.SetDocumentCounter(docRepo)
+	grace := service.DefaultGracePolicy()
+	grace.Percent = cfg.QuotaGracePercent
+	usageSvc.SetGracePolicy(grace)
+	docService.SetQuota(usageSvc)
+
+	// Usage threshold alerts and billing events (transactional outbox)
+	var usageSinks []service.UsageEventSink
+	if cfg.UsageWebhookURL != "" {
+		usageSinks = append(usageSinks, service.NewWebhookSink(cfg.UsageWebhookURL, cfg.UsageWebhookSecret))
+	}
+	if cfg.SMTPHost != "" {
+		usageSinks = append(usageSinks, service.NewSMTPEmailSink(cfg.SMTPHost, cfg.SMTPPort,
+			cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, orgRepo.BillingEmail))
+	}
+	if len(usageSinks) > 0 {
+		usageSvc.SetEventOutbox(usageRepo)
+		usageDispatcher := service.NewUsageEventDispatcher(usageRepo, usageSinks...)
+		dispatchCtx, stopDispatch := context.WithCancel(ctx)
+		defer stopDispatch()
+		go usageDispatcher.Run(dispatchCtx, time.Duration(cfg.UsageEventsPollSec)*time.Second)
+		slog.Info("usage event dispatcher started", "sinks", len(usageSinks))
+	}
+
 	// Privilege state — DB-backed with in-memory cache (STORY-S04)
 	privilegeState := handler.NewPrivilegeStateWithStore(userRepo)
 
@@ -276,54 +467,128 @@ func run() error {
 	reg := prometheus.NewRegistry()
 	metrics := middleware.NewMetrics(reg)
 
-	// ─── Rate limiters ────────────────────────────────────────────────
-
-	generalRL := middleware.NewRateLimiter(middleware.RateLimiterConfig{
-		MaxRequests: 60,
-		Window:      1 * time.Minute,
-	})
-	defer generalRL.Stop()
+	if auditDispatcher != nil {
+		auditDispatcher.SetObserver(metrics)
+		dispatchCtx, stopDispatch := context.WithCancel(ctx)
+		defer stopDispatch()
+		go auditDispatcher.Run(dispatchCtx, time.Duration(cfg.AuditSinkIntervalSec)*time.Second)
+	}
 
-	chatRL := middleware.NewRateLimiter(middleware.RateLimiterConfig{
-		MaxRequests: 10,
-		Window:      1 * time.Minute,
-	})
-	defer chatRL.Stop()
+	// ─── Rate limiters ────────────────────────────────────────────────
 
-	forgeRL := middleware.NewRateLimiter(middleware.RateLimiterConfig{
-		MaxRequests: 5,
-		Window:      1 * time.Minute,
-	})
-	defer forgeRL.Stop()
+	// Shared windows across instances when Redis is configured; each window
+	// falls back to in-memory while Redis is unreachable.
+	var rateLimitRedis *redis.Client
+	if cfg.RedisAddr != "" {
+		rateLimitRedis = redis.NewClient(&redis.Options{
+			Addr:         cfg.RedisAddr,
+			DialTimeout:  1 * time.Second,
+			ReadTimeout:  250 * time.Millisecond,
+			WriteTimeout: 250 * time.Millisecond,
+			PoolSize:     20,
+		})
+		defer rateLimitRedis.Close()
+	}
 
-	// Insight scan: 1 per 5 min per user (prevents abuse)
-	insightScanRL := middleware.NewRateLimiter(middleware.RateLimiterConfig{
-		MaxRequests: 1,
-		Window:      5 * time.Minute,
-	})
-	defer insightScanRL.Stop()
+	// Tier × route group policy (built-in table unless RATE_LIMIT_POLICY_FILE
+	// is set); tiers resolve through the same lookup as chat usage metering.
+	rateLimitPolicy := middleware.DefaultRateLimitPolicy()
+	if cfg.RateLimitPolicyFile != "" {
+		rateLimitPolicy, err = middleware.LoadRateLimitPolicy(cfg.RateLimitPolicyFile)
+		if err != nil {
+			return fmt.Errorf("rate limit policy: %w", err)
+		}
+	}
+	policyLimiter, err := middleware.NewPolicyLimiter(rateLimitPolicy, userTierFunc, rateLimitRedis)
+	if err != nil {
+		return fmt.Errorf("rate limit policy: %w", err)
+	}
+	defer policyLimiter.Stop()
+	if cfg.RateLimitPolicyFile != "" {
+		go policyLimiter.WatchFile(ctx, cfg.RateLimitPolicyFile, time.Duration(cfg.RateLimitPolicyPollSec)*time.Second)
+	}
 
-	slog.Info("rate limiters initialized", "general", "60/min", "chat", "10/min", "forge", "5/min", "insight_scan", "1/5min")
+	slog.Info("rate limit policy initialized",
+		"default_tier", rateLimitPolicy.DefaultTier,
+		"tiers", len(rateLimitPolicy.Tiers),
+		"tenant_overrides", len(rateLimitPolicy.Tenants),
+		"file", cfg.RateLimitPolicyFile,
+		"distributed", rateLimitRedis != nil)
 
 	// ─── Query cache ──────────────────────────────────────────────────
 
 	queryCache := cache.New(1 * time.Hour)
 	defer queryCache.Stop()
+	queryCache.SetLimits(cfg.QueryCacheMaxEntries, int64(cfg.QueryCacheMaxMB)<<20)
+	queryCache.SetObserver(metrics)
 
-	slog.Info("query cache initialized", "ttl", "1h")
+	slog.Info("query cache initialized", "ttl", "1h",
+		"max_entries", cfg.QueryCacheMaxEntries, "max_mb", cfg.QueryCacheMaxMB)
 
 	// Embedding cache (in-memory, deduplicates repeated query embeddings — STORY-152)
 	embedCache := cache.NewEmbeddingCache(15 * time.Minute)
 	defer embedCache.Stop()
-	slog.Info("embedding cache initialized", "ttl", "15m")
+	embedCache.SetLimits(cfg.EmbedCacheMaxEntries, int64(cfg.EmbedCacheMaxMB)<<20)
+	embedCache.SetObserver(metrics)
+	slog.Info("embedding cache initialized", "ttl", "15m",
+		"max_entries", cfg.EmbedCacheMaxEntries, "max_mb", cfg.EmbedCacheMaxMB)
+
+	// Semantic answer cache (optional — reuses answers to near-duplicate queries)
+	var semanticCache *cache.SemanticCache
+	if cfg.SemanticCacheEnabled {
+		semanticCache = cache.NewSemanticCache(30*time.Minute, cfg.SemanticCacheThreshold)
+		defer semanticCache.Stop()
+		semanticCache.SetObserver(metrics)
+		slog.Info("semantic cache initialized", "ttl", "30m", "threshold", semanticCache.Threshold())
+	}
 
 	// EPIC-028: Redis L2 cache (optional — nil if REDIS_ADDR not set)
 	redisCache := cache.NewRedisCache(cfg.RedisAddr)
 	if redisCache != nil {
 		defer redisCache.Close()
+		redisCache.SetObserver(metrics)
 		slog.Info("redis L2 cache initialized", "addr", cfg.RedisAddr)
 	}
 
+	// Document-driven invalidation across all cache layers and instances
+	cacheInvalidator := cache.NewInvalidator(queryCache, semanticCache, redisCache)
+	invalidateCtx, stopInvalidate := context.WithCancel(ctx)
+	defer stopInvalidate()
+	go cacheInvalidator.Run(invalidateCtx)
+
+	cacheStats := func() []cache.Stats {
+		layers := []cache.Stats{queryCache.Stats(), embedCache.Stats()}
+		if semanticCache != nil {
+			layers = append(layers, semanticCache.Stats())
+		}
+		return append(layers, redisCache.Stats()...)
+	}
+
+	// ─── Retention ─────────────────────────────────────────────────────
+
+	// Hard-deletes soft-deleted documents once their retention period ends;
+	// legal holds keep them.
+	retentionSvc := service.NewRetentionService(repository.NewRetentionRepo(pool), orgRepo, legalHoldRepo,
+		auditService, cfg.RetentionDefaultDays)
+	retentionSvc.Storage = storageAdapter
+	retentionSvc.Bucket = cfg.GCSBucketName
+	retentionSvc.Cache = cacheInvalidator
+	if cfg.Neo4jURL != "" {
+		neo4jClient, err := service.NewNeo4jClient(cfg.Neo4jURL, cfg.Neo4jUsername, cfg.Neo4jPassword)
+		if err != nil {
+			return fmt.Errorf("neo4j: %w", err)
+		}
+		defer neo4jClient.Close(context.Background())
+		retentionSvc.Graph = neo4jClient
+	}
+	if cfg.RetentionPurgeSec > 0 {
+		purgeCtx, stopPurge := context.WithCancel(ctx)
+		defer stopPurge()
+		go retentionSvc.Run(purgeCtx, time.Duration(cfg.RetentionPurgeSec)*time.Second)
+		slog.Info("retention purge job started", "default_days", cfg.RetentionDefaultDays,
+			"interval_s", cfg.RetentionPurgeSec, "graph", cfg.Neo4jURL != "")
+	}
+
 	// ─── Router ────────────────────────────────────────────────────────
 
 	router := internalrouter.New(&internalrouter.Dependencies{
@@ -338,6 +603,7 @@ func run() error {
 		DocService: docService,
 		DocRepo:          docRepo,
 		ChunkDeleter:     chunkRepo,
+		ChunkLister:      chunkRepo,
 		FolderRepo:       folderRepo,
 		Storage:          storageAdapter,
 		ObjectDownloader: storageAdapter,
@@ -363,10 +629,14 @@ func run() error {
 			QueryCache:     queryCache,
 			EmbedCache:     embedCache,
 			RedisCache:     redisCache,
+			SemanticCache:  semanticCache,
 			UsageSvc:       usageSvc,      // STORY-199: token allocation enforcement
 			UserTierFunc:   userTierFunc,   // STORY-199: tier lookup from users table
 			DocStatus:      docRepo,         // STORY-172: processing status + document summaries
 			PrivilegeState: privilegeState,  // STORY-S01 Gap 3: server-side privilege state
+			Vaults:         vaultRepo,
+			PII:            piiSvc,
+			AuditLogger:    auditService,
 		},
 
 		ContentGapDeps: handler.ContentGapDeps{
@@ -374,12 +644,14 @@ func run() error {
 		},
 
 		KBHealthDeps: handler.KBHealthDeps{
-			Svc: kbHealthSvc,
+			Svc:    kbHealthSvc,
+			Vaults: vaultRepo,
 		},
 
 		AuditDeps: handler.AuditDeps{
-			Lister:   auditRepo,
-			Verifier: auditService,
+			Lister:      auditRepo,
+			Verifier:    service.NewAuditVerifier(auditRepo),
+			Checkpoints: auditCheckpoints,
 		},
 
 		ExportDeps: handler.ExportDeps{
@@ -391,24 +663,31 @@ func run() error {
 		PipelineSvc: pipelineSvc,
 
 		IngestDeps: handler.IngestDeps{
-			DocRepo:    docRepo,
-			Pipeline:   pipelinePublisher,
-			QueryCache: queryCache,
+			DocRepo:     docRepo,
+			Pipeline:    pipelinePublisher,
+			Invalidator: cacheInvalidator,
+			Quota:       usageSvc,
+			Holds:       legalHoldSvc,
 		},
 		IngestTextDeps: handler.IngestTextDeps{
-			DocRepo:    docRepo,
-			Pipeline:   pipelineSvc,
-			QueryCache: queryCache,
+			DocRepo:     docRepo,
+			Pipeline:    pipelineSvc,
+			Invalidator: cacheInvalidator,
+			Quota:       usageSvc,
+			Holds:       legalHoldSvc,
 		},
 
 		RelatedDocsDeps: handler.RelatedDocsDeps{
-			DocRepo:  docRepo,
-			Searcher: chunkRepo,
+			DocRepo:        docRepo,
+			Searcher:       chunkRepo,
+			PrivilegeState: privilegeState,
 		},
 
 		ChunkPreviewDeps: handler.ChunkPreviewDeps{
-			DocRepo:     docRepo,
-			ChunkReader: chunkRepo,
+			DocRepo:        docRepo,
+			ChunkReader:    chunkRepo,
+			PII:            piiSvc,
+			PrivilegeState: privilegeState,
 		},
 
 		VonageDeps: handler.VonageDeps{
@@ -425,6 +704,7 @@ func run() error {
 
 		TranscribeDeps: handler.TranscribeDeps{
 			DeepgramAPIKey: cfg.DeepgramAPIKey,
+			UsageSvc:       usageSvc,
 		},
 
 		AdminMigrateDeps: handler.AdminMigrateDeps{
@@ -436,12 +716,45 @@ func run() error {
 		},
 
 		UserEnsurer: userRepo,
+		APIUsage:    usageSvc,
+		OrgResolver: orgRepo,
+		OrgDeps:     &handler.OrgDeps{Svc: orgSvc, Invalidator: cacheInvalidator},
+		VaultDeps: &handler.VaultDeps{
+			VaultRepo:   vaultRepo,
+			DocRepo:     docRepo,
+			Invalidator: cacheInvalidator,
+			Holds:       legalHoldSvc,
+		},
+		ShareDeps: &handler.ShareDeps{
+			Svc:            sharingSvc,
+			DocRepo:        docRepo,
+			FolderRepo:     folderRepo,
+			Invalidator:    cacheInvalidator,
+			PrivilegeState: privilegeState,
+		},
+		LegalHolds:    legalHoldSvc,
+		LegalHoldDeps: &handler.LegalHoldDeps{Svc: legalHoldSvc},
+		RetentionDeps: &handler.RetentionDeps{Svc: retentionSvc},
+		PIIPolicyDeps: &handler.PIIPolicyDeps{VaultRepo: vaultRepo, Svc: piiSvc, Invalidator: cacheInvalidator},
+		APITokens:     apiTokenSvc,
+		APITokenDeps:  &handler.APITokenDeps{Svc: apiTokenSvc},
+		RoleResolver:  userRepo,
+
+		AuditLogger:    auditService,
+		SessionTracker: userRepo,
+		LoginIdle:      time.Duration(cfg.AuditLoginIdleMin) * time.Minute,
+
+		RateLimitPolicy: policyLimiter,
+		RateLimitAdmin: handler.RateLimitAdminDeps{
+			Policy:     policyLimiter,
+			PolicyFile: cfg.RateLimitPolicyFile,
+		},
 
-		GeneralRateLimiter: generalRL,
-		ChatRateLimiter:    chatRL,
-		ForgeRateLimiter:   forgeRL,
-
-		QueryCache: queryCache,
+		CacheInvalidator: cacheInvalidator,
+		CacheAdminDeps: handler.CacheAdminDeps{
+			Stats:       cacheStats,
+			Invalidator: cacheInvalidator,
+		},
 
 		MercuryConfigDeps: handler.MercuryConfigDeps{
 			Reader: mercuryConfigRepo,
@@ -458,7 +771,6 @@ func run() error {
 		InsightDeps: handler.InsightDeps{
 			Scanner: insightScannerSvc,
 		},
-		InsightRateLimiter: insightScanRL,
 	})
 
 	// ─── HTTP server ───────────────────────────────────────────────────
@@ -0,0 +1,147 @@
+package auditchain
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// BundleVersion is the current export bundle format.
+const BundleVersion = 1
+
+// Bundle is a self-contained export of audit entries with the signed
+// checkpoints anchoring them, verifiable without database access.
+type Bundle struct {
+	Version int `json:"version"`
+	// PublicKey is the checkpoint key at export time. It is informational:
+	// verifiers should check against a key they obtained independently.
+	PublicKey   string                  `json:"publicKey,omitempty"`
+	Entries     []BundleEntry           `json:"entries"`
+	Checkpoints []model.AuditCheckpoint `json:"checkpoints"`
+}
+
+// BundleEntry is an audit entry with its inclusion proof.
+type BundleEntry struct {
+	model.AuditLog
+	// Details shadows AuditLog.Details with the exact stored text: the
+	// entry hash covers those bytes, and re-encoding the JSON would change
+	// them.
+	Details string          `json:"details,omitempty"`
+	Proof   *InclusionProof `json:"proof,omitempty"` // nil until checkpointed
+}
+
+// NewBundleEntry wraps e, preserving its stored details.
+func NewBundleEntry(e model.AuditLog, proof *InclusionProof) BundleEntry {
+	return BundleEntry{AuditLog: e, Details: string(e.Details), Proof: proof}
+}
+
+// Entry returns the audit entry with its details restored.
+func (be *BundleEntry) Entry() model.AuditLog {
+	e := be.AuditLog
+	e.Details = nil
+	if be.Details != "" {
+		e.Details = json.RawMessage(be.Details)
+	}
+	return e
+}
+
+// InclusionProof proves an entry is a leaf of a checkpoint's Merkle tree.
+type InclusionProof struct {
+	CheckpointID string   `json:"checkpointId"`
+	LeafIndex    int64    `json:"leafIndex"`
+	TreeSize     int64    `json:"treeSize"`
+	Path         []string `json:"path"` // hex sibling hashes, leaf to root
+}
+
+// BundleReport is the outcome of verifying a bundle.
+type BundleReport struct {
+	Valid       bool            `json:"valid"`
+	Signed      bool            `json:"signed"` // a detached bundle signature was checked
+	Entries     int             `json:"entries"`
+	Anchored    int             `json:"anchored"`
+	Unanchored  []string        `json:"unanchored,omitempty"` // IDs of entries with no proof yet
+	Checkpoints int             `json:"checkpoints"`
+	Failures    []BundleFailure `json:"failures,omitempty"`
+}
+
+// BundleFailure describes one failed check.
+type BundleFailure struct {
+	EntryID      string `json:"entryId,omitempty"`
+	CheckpointID string `json:"checkpointId,omitempty"`
+	Reason       string `json:"reason"`
+}
+
+// VerifyBundle checks b against the trusted checkpoint keys (several, when
+// the signing key has been rotated): every checkpoint signature, every entry
+// hash, the chain links between adjacent entries, and every inclusion
+// proof. It reports all failures rather than stopping at the first.
+func VerifyBundle(b *Bundle, trusted ...ed25519.PublicKey) *BundleReport {
+	rep := &BundleReport{Entries: len(b.Entries), Checkpoints: len(b.Checkpoints)}
+	fail := func(entryID, checkpointID, format string, args ...interface{}) {
+		rep.Failures = append(rep.Failures, BundleFailure{
+			EntryID: entryID, CheckpointID: checkpointID, Reason: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if b.Version != BundleVersion {
+		fail("", "", "unsupported bundle version %d", b.Version)
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(trusted))
+	for _, pub := range trusted {
+		keys[KeyID(pub)] = pub
+	}
+	checkpoints := make(map[string]*model.AuditCheckpoint, len(b.Checkpoints))
+	for i := range b.Checkpoints {
+		cp := &b.Checkpoints[i]
+		pub, ok := keys[cp.KeyID]
+		if !ok {
+			fail("", cp.ID, "checkpoint is signed by untrusted key %q", cp.KeyID)
+			continue
+		}
+		if !VerifyCheckpoint(cp, pub) {
+			fail("", cp.ID, "checkpoint signature is not valid for the trusted key")
+			continue
+		}
+		checkpoints[cp.ID] = cp
+	}
+
+	var prev *model.AuditLog
+	for i := range b.Entries {
+		e := b.Entries[i].Entry()
+		if !Intact(&e) {
+			fail(e.ID, "", "hash does not match entry contents")
+		}
+		if prev != nil && prev.TenantID == e.TenantID && prev.Seq+1 == e.Seq && !Follows(prev, &e) {
+			fail(e.ID, "", "does not link to the previous entry (seq %d)", prev.Seq)
+		}
+		prev = &e
+
+		proof := b.Entries[i].Proof
+		if proof == nil {
+			rep.Unanchored = append(rep.Unanchored, e.ID)
+			continue
+		}
+		cp, ok := checkpoints[proof.CheckpointID]
+		if !ok {
+			fail(e.ID, proof.CheckpointID, "proof refers to a missing or invalid checkpoint")
+			continue
+		}
+		if cp.TenantID != e.TenantID || e.Seq < cp.FromSeq || e.Seq > cp.ToSeq {
+			fail(e.ID, cp.ID, "entry (%s seq %d) is outside the checkpoint range", e.TenantID, e.Seq)
+			continue
+		}
+		index, size := e.Seq-cp.FromSeq, cp.ToSeq-cp.FromSeq+1
+		if proof.LeafIndex != index || proof.TreeSize != size ||
+			!VerifyInclusion(deref(e.DetailsHash), index, size, proof.Path, cp.RootHash) {
+			fail(e.ID, cp.ID, "inclusion proof does not match the checkpoint root")
+			continue
+		}
+		rep.Anchored++
+	}
+
+	rep.Valid = len(rep.Failures) == 0
+	return rep
+}
@@ -0,0 +1,224 @@
+package auditchain
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// testChain returns n linked entries of one tenant. Details are in
+// Postgres' JSONB text form, which encoding/json would not reproduce.
+func testChain(n int) []model.AuditLog {
+	entries := make([]model.AuditLog, n)
+	prevHash := ""
+	for i := range entries {
+		prev := prevHash
+		entries[i] = model.AuditLog{
+			ID:        fmt.Sprintf("entry-%d", i),
+			TenantID:  "org-1",
+			Seq:       int64(i + 1),
+			PrevHash:  &prev,
+			Action:    model.AuditDocumentUpload,
+			Details:   json.RawMessage(fmt.Sprintf(`{"n": %d, "note": "<a&b>"}`, i)),
+			CreatedAt: time.Date(2026, 3, 1, 9, 0, i, 1000, time.UTC),
+		}
+		hash := EntryHash(prevHash, &entries[i])
+		entries[i].DetailsHash = &hash
+		prevHash = hash
+	}
+	return entries
+}
+
+// testBundle checkpoints seqs 1..anchored of a chain of n entries and
+// exports all of them, JSON round-tripped.
+func testBundle(t *testing.T, key ed25519.PrivateKey, n, anchored int) *Bundle {
+	t.Helper()
+	entries := testChain(n)
+	hashes := make([]string, anchored)
+	for i := range hashes {
+		hashes[i] = *entries[i].DetailsHash
+	}
+	tree := NewTree(hashes)
+	cp := model.AuditCheckpoint{
+		ID: "cp-1", TenantID: "org-1", FromSeq: 1, ToSeq: int64(anchored),
+		RootHash: tree.Root(), CreatedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+	SignCheckpoint(&cp, key)
+
+	b := &Bundle{Version: BundleVersion, Checkpoints: []model.AuditCheckpoint{cp}}
+	for i, e := range entries {
+		var proof *InclusionProof
+		if i < anchored {
+			path, err := tree.Proof(int64(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			proof = &InclusionProof{CheckpointID: cp.ID, LeafIndex: int64(i), TreeSize: int64(anchored), Path: path}
+		}
+		b.Entries = append(b.Entries, NewBundleEntry(e, proof))
+	}
+
+	raw, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Bundle
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	return &out
+}
+
+func testKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, key
+}
+
+func TestVerifyBundle_Valid(t *testing.T) {
+	pub, key := testKey(t)
+	rep := VerifyBundle(testBundle(t, key, 7, 5), pub)
+
+	if !rep.Valid || rep.Anchored != 5 || len(rep.Unanchored) != 2 || rep.Checkpoints != 1 {
+		t.Errorf("report = %+v", rep)
+	}
+}
+
+func TestVerifyBundle_DetectsTampering(t *testing.T) {
+	pub, key := testKey(t)
+	otherPub, _ := testKey(t)
+
+	tests := []struct {
+		name   string
+		mutate func(b *Bundle)
+		pub    ed25519.PublicKey
+	}{
+		{"untrusted key", func(b *Bundle) {}, otherPub},
+		{"edited details", func(b *Bundle) { b.Entries[2].Details = `{"n": 99, "note": "<a&b>"}` }, pub},
+		{"edited and rehashed", func(b *Bundle) {
+			e := b.Entries[2].Entry()
+			e.Action = model.AuditDocumentDelete
+			h := EntryHash(*e.PrevHash, &e)
+			b.Entries[2].Action, b.Entries[2].DetailsHash = e.Action, &h
+		}, pub},
+		{"resigned root", func(b *Bundle) { b.Checkpoints[0].RootHash = NewTree([]string{"x"}).Root() }, pub},
+		{"moved proof", func(b *Bundle) { b.Entries[1].Proof = b.Entries[0].Proof }, pub},
+		{"proof to missing checkpoint", func(b *Bundle) { b.Entries[0].Proof.CheckpointID = "cp-2" }, pub},
+		{"wrong tenant", func(b *Bundle) { b.Checkpoints[0].TenantID = "org-2"; SignCheckpoint(&b.Checkpoints[0], key) }, pub},
+		{"unknown version", func(b *Bundle) { b.Version = 99 }, pub},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testBundle(t, key, 7, 5)
+			tt.mutate(b)
+			if rep := VerifyBundle(b, tt.pub); rep.Valid || len(rep.Failures) == 0 {
+				t.Errorf("tampered bundle verified: %+v", rep)
+			}
+		})
+	}
+}
+
+func TestVerifyBundle_AcceptsRotatedKeys(t *testing.T) {
+	oldPub, oldKey := testKey(t)
+	newPub, newKey := testKey(t)
+	b := testBundle(t, oldKey, 4, 4)
+	rotated := testBundle(t, newKey, 4, 4).Checkpoints[0]
+	rotated.ID = "cp-2"
+	b.Checkpoints = append(b.Checkpoints, rotated)
+
+	if rep := VerifyBundle(b, newPub, oldPub); !rep.Valid {
+		t.Errorf("report = %+v", rep)
+	}
+	if rep := VerifyBundle(b, newPub); rep.Valid {
+		t.Error("checkpoint by a key that is no longer trusted verified")
+	}
+}
+
+func TestVerifyBundle_ReportsEveryFailure(t *testing.T) {
+	pub, key := testKey(t)
+	b := testBundle(t, key, 6, 6)
+	b.Entries[1].Details = `{}`
+	b.Entries[4].Details = `{}`
+
+	rep := VerifyBundle(b, pub)
+	failed := map[string]bool{}
+	for _, f := range rep.Failures {
+		failed[f.EntryID] = true
+	}
+	if !failed["entry-1"] || !failed["entry-4"] {
+		t.Errorf("failures = %+v, want entry-1 and entry-4", rep.Failures)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	pub, key := testKey(t)
+	enc := func(b []byte) string { return EncodePublicKey(ed25519.PublicKey(b)) }
+
+	for _, s := range []string{enc(key.Seed()), enc(key)} {
+		got, err := ParsePrivateKey(s)
+		if err != nil || !got.Equal(key) {
+			t.Errorf("ParsePrivateKey: %v", err)
+		}
+	}
+	if _, err := ParsePrivateKey(enc(key[:40])); err == nil {
+		t.Error("ParsePrivateKey accepted a 40-byte key")
+	}
+	mismatched := append(append([]byte(nil), key.Seed()...), make([]byte, 32)...)
+	if _, err := ParsePrivateKey(enc(mismatched)); err == nil {
+		t.Error("ParsePrivateKey accepted a key whose public half does not match")
+	}
+	got, err := ParsePublicKey(EncodePublicKey(pub))
+	if err != nil || !got.Equal(pub) {
+		t.Errorf("ParsePublicKey: %v", err)
+	}
+}
+
+func TestSignedBundle(t *testing.T) {
+	pub, key := testKey(t)
+	sb, err := SignBundle(testBundle(t, key, 4, 2), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file round-trips byte for byte through JSON, HTML-sensitive
+	// details included.
+	raw, err := json.Marshal(sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got SignedBundle
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	rep, err := VerifySignedBundle(&got, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.Valid || !rep.Signed || rep.Anchored != 2 || len(rep.Unanchored) != 2 {
+		t.Errorf("report = %+v", rep)
+	}
+
+	// Unanchored entries are covered by the signature too.
+	tampered := got
+	tampered.Bundle = json.RawMessage(strings.Replace(string(got.Bundle), "entry-3", "entry-9", 1))
+	if rep, _ := VerifySignedBundle(&tampered, pub); rep.Valid {
+		t.Error("edited bundle verified")
+	}
+
+	otherPub, _ := testKey(t)
+	if rep, _ := VerifySignedBundle(&got, otherPub); rep.Valid {
+		t.Error("bundle verified against an untrusted key")
+	}
+	if err := got.VerifySignature(otherPub); err == nil || !strings.Contains(err.Error(), "untrusted") {
+		t.Errorf("VerifySignature = %v, want untrusted key", err)
+	}
+}
@@ -0,0 +1,86 @@
+package auditchain
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// checkpointDomain prefixes every signed checkpoint message, so a
+// checkpoint signature can never be replayed as any other signed object.
+const checkpointDomain = "ragbox-audit-checkpoint/v1"
+
+// CheckpointMessage returns the canonical bytes signed for cp: the domain,
+// tenant, seq range, root and creation time, one per line.
+func CheckpointMessage(cp *model.AuditCheckpoint) []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%d\n%d\n%s\n%s",
+		checkpointDomain, cp.TenantID, cp.FromSeq, cp.ToSeq, cp.RootHash,
+		cp.CreatedAt.UTC().Format(time.RFC3339Nano)))
+}
+
+// SignCheckpoint sets cp's KeyID and Signature using key.
+func SignCheckpoint(cp *model.AuditCheckpoint, key ed25519.PrivateKey) {
+	cp.KeyID = KeyID(key.Public().(ed25519.PublicKey))
+	cp.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, CheckpointMessage(cp)))
+}
+
+// VerifyCheckpoint reports whether cp carries a valid signature by pub.
+func VerifyCheckpoint(cp *model.AuditCheckpoint, pub ed25519.PublicKey) bool {
+	sig, err := base64.StdEncoding.DecodeString(cp.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(pub, CheckpointMessage(cp), sig)
+}
+
+// KeyID returns a short, stable identifier for a checkpoint public key: the
+// first 8 bytes of its SHA-256, in hex.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ParsePrivateKey decodes a base64 Ed25519 key, given either as a 32-byte
+// seed or as the 64-byte seed-and-public-key form.
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("auditchain.ParsePrivateKey: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		key := ed25519.NewKeyFromSeed(raw[:ed25519.SeedSize])
+		if !key.Equal(ed25519.PrivateKey(raw)) {
+			return nil, fmt.Errorf("auditchain.ParsePrivateKey: public half does not match seed")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("auditchain.ParsePrivateKey: want %d or %d bytes, got %d",
+			ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+	}
+}
+
+// ParsePublicKey decodes a base64 32-byte Ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("auditchain.ParsePublicKey: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("auditchain.ParsePublicKey: want %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// EncodePublicKey returns pub in the base64 form ParsePublicKey accepts.
+func EncodePublicKey(pub ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(pub)
+}
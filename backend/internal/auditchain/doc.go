@@ -0,0 +1,8 @@
+// Package auditchain holds the pure, database-free parts of audit trail
+// integrity: the per-tenant hash chain formula, RFC 6962 Merkle trees over
+// chain entries, Ed25519-signed checkpoints of those trees, and the export
+// bundle that lets a third party verify entries offline.
+//
+// It is shared by the server and the standalone cmd/audit-verify tool, so it
+// must not import anything that needs a database or cloud client.
+package auditchain
@@ -0,0 +1,69 @@
+package auditchain
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// EntryHash produces the SHA-256 hash linking entry to the previous entry.
+// It covers every stored column but details_hash itself, in the order
+// prev_hash, id, tenant_id, seq, user_id, action, resource_id,
+// resource_type, severity, ip_address, user_agent, created_at (RFC3339Nano,
+// UTC), details. Each is written as its byte length, ':' and its value, or
+// as "-" when NULL, so no two rows hash the same input.
+// It mirrors audit_entry_hash() in migration 036, which computes the hash
+// on insert; details must be in their stored (JSONB) form.
+func EntryHash(previousHash string, entry *model.AuditLog) string {
+	h := sha256.New()
+	field := func(v string) {
+		h.Write([]byte(strconv.Itoa(len(v)) + ":" + v))
+	}
+	nullable := func(v *string) {
+		if v == nil {
+			h.Write([]byte("-"))
+			return
+		}
+		field(*v)
+	}
+	field(previousHash)
+	field(entry.ID)
+	field(entry.TenantID)
+	field(strconv.FormatInt(entry.Seq, 10))
+	nullable(entry.UserID)
+	field(entry.Action)
+	nullable(entry.ResourceID)
+	nullable(entry.ResourceType)
+	field(entry.Severity)
+	nullable(entry.IPAddress)
+	nullable(entry.UserAgent)
+	field(entry.CreatedAt.UTC().Format(time.RFC3339Nano))
+	if entry.Details != nil {
+		field(string(entry.Details))
+	} else {
+		h.Write([]byte("-"))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// Intact reports whether entry's stored hash matches its contents and
+// previous hash.
+func Intact(entry *model.AuditLog) bool {
+	return entry.DetailsHash != nil && EntryHash(deref(entry.PrevHash), entry) == *entry.DetailsHash
+}
+
+// Follows reports whether entry directly follows prev in the same chain.
+func Follows(prev, entry *model.AuditLog) bool {
+	return entry.TenantID == prev.TenantID && entry.Seq == prev.Seq+1 &&
+		deref(entry.PrevHash) == deref(prev.DetailsHash)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
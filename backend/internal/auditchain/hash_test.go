@@ -0,0 +1,132 @@
+package auditchain
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+func TestEntryHash_Deterministic(t *testing.T) {
+	entry := &model.AuditLog{
+		Action:    model.AuditDocumentUpload,
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	hash1 := EntryHash("prev", entry)
+	hash2 := EntryHash("prev", entry)
+
+	if hash1 != hash2 {
+		t.Error("EntryHash should be deterministic")
+	}
+}
+
+func TestEntryHash_DifferentPrevHash(t *testing.T) {
+	entry := &model.AuditLog{
+		Action:    model.AuditDocumentUpload,
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	hash1 := EntryHash("prev1", entry)
+	hash2 := EntryHash("prev2", entry)
+
+	if hash1 == hash2 {
+		t.Error("different previous hashes should yield different results")
+	}
+}
+
+func TestEntryHash_UsesUTC(t *testing.T) {
+	utc := &model.AuditLog{Action: "A", CreatedAt: time.Date(2025, 6, 15, 12, 0, 0, 123456000, time.UTC)}
+	local := &model.AuditLog{Action: "A", CreatedAt: utc.CreatedAt.In(time.FixedZone("EST", -5*3600))}
+
+	if EntryHash("", utc) != EntryHash("", local) {
+		t.Error("hash depends on the time zone the timestamp was read in")
+	}
+}
+
+func TestEntryHash_MatchesExpected(t *testing.T) {
+	entry := &model.AuditLog{
+		ID:        "e1",
+		TenantID:  "org-1",
+		Seq:       3,
+		Action:    "TEST_ACTION",
+		Severity:  "INFO",
+		CreatedAt: time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC),
+	}
+
+	// Manually compute expected hash: length-prefixed fields, "-" for NULL.
+	h := sha256.New()
+	h.Write([]byte("0:"))                      // empty prev hash
+	h.Write([]byte("2:e1"))                    // id
+	h.Write([]byte("5:org-1"))                 // tenant_id
+	h.Write([]byte("1:3"))                     // seq
+	h.Write([]byte("-"))                       // user_id
+	h.Write([]byte("11:TEST_ACTION"))          // action
+	h.Write([]byte("--"))                      // resource_id, resource_type
+	h.Write([]byte("4:INFO"))                  // severity
+	h.Write([]byte("--"))                      // ip_address, user_agent
+	h.Write([]byte("20:2025-06-15T12:00:00Z")) // created_at
+	h.Write([]byte("-"))                       // details
+	expected := fmt.Sprintf("%x", h.Sum(nil))
+
+	got := EntryHash("", entry)
+	if got != expected {
+		t.Errorf("hash mismatch: got %q, want %q", got, expected)
+	}
+}
+
+func TestIntact_DetectsChangeToAnyColumn(t *testing.T) {
+	str := func(s string) *string { return &s }
+	sealed := func() model.AuditLog {
+		e := model.AuditLog{
+			ID:           "e1",
+			TenantID:     "org-1",
+			Seq:          7,
+			PrevHash:     str("abc"),
+			UserID:       str("u1"),
+			Action:       model.AuditDocumentView,
+			ResourceID:   str("d1"),
+			ResourceType: str("document"),
+			Severity:     "INFO",
+			Details:      []byte(`{"a": 1}`),
+			IPAddress:    str("203.0.113.7"),
+			UserAgent:    str("Mozilla/5.0"),
+			CreatedAt:    time.Date(2025, 6, 15, 12, 0, 0, 123456000, time.UTC),
+		}
+		hash := EntryHash(*e.PrevHash, &e)
+		e.DetailsHash = &hash
+		return e
+	}
+	base := sealed()
+	if !Intact(&base) {
+		t.Fatal("untouched entry is not intact")
+	}
+
+	for name, tamper := range map[string]func(e *model.AuditLog){
+		"prev_hash":        func(e *model.AuditLog) { e.PrevHash = str("abd") },
+		"id":               func(e *model.AuditLog) { e.ID = "e2" },
+		"tenant_id":        func(e *model.AuditLog) { e.TenantID = "org-2" },
+		"seq":              func(e *model.AuditLog) { e.Seq = 8 },
+		"user_id":          func(e *model.AuditLog) { e.UserID = str("u2") },
+		"user_id null":     func(e *model.AuditLog) { e.UserID = nil },
+		"action":           func(e *model.AuditLog) { e.Action = model.AuditDocumentDelete },
+		"resource_id":      func(e *model.AuditLog) { e.ResourceID = str("d2") },
+		"resource_type":    func(e *model.AuditLog) { e.ResourceType = str("folder") },
+		"severity":         func(e *model.AuditLog) { e.Severity = "CRITICAL" },
+		"ip_address":       func(e *model.AuditLog) { e.IPAddress = str("198.51.100.1") },
+		"ip_address empty": func(e *model.AuditLog) { e.IPAddress = str("") },
+		"user_agent":       func(e *model.AuditLog) { e.UserAgent = str("curl/8.0") },
+		"created_at":       func(e *model.AuditLog) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) },
+		"details":          func(e *model.AuditLog) { e.Details = []byte(`{"a": 2}`) },
+		"details null":     func(e *model.AuditLog) { e.Details = nil },
+		"fields run into":  func(e *model.AuditLog) { e.ResourceID, e.ResourceType = str("d1document"), str("") },
+	} {
+		e := sealed()
+		tamper(&e)
+		if Intact(&e) {
+			t.Errorf("changing %s left the entry intact", name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package auditchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Merkle trees follow RFC 6962 §2.1: leaves and interior nodes are hashed
+// with distinct prefixes so a leaf can never be passed off as a node, and a
+// tree of n leaves splits at the largest power of two below n. A leaf's data
+// is an audit entry's DetailsHash (the hex string), so each tree commits to
+// a run of chain entries.
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// LeafHash returns the Merkle leaf hash for an entry's DetailsHash. The
+// DetailsHash is EntryHash over every stored column, so a checkpoint commits
+// to all of them; verifiers must check Intact before trusting a proof.
+func LeafHash(detailsHash string) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write([]byte(detailsHash))
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Tree is a Merkle tree over a run of audit entries, kept level by level so
+// that inclusion proofs for many leaves cost O(log n) each.
+type Tree struct {
+	// levels[0] holds the leaf hashes; each level above pairs adjacent
+	// nodes and carries an unpaired last node up unchanged, which yields
+	// the same left-balanced tree as the RFC 6962 recursive definition.
+	levels [][][]byte
+}
+
+// NewTree builds the tree over the given entry DetailsHashes, in chain order.
+func NewTree(detailsHashes []string) *Tree {
+	level := make([][]byte, len(detailsHashes))
+	for i, dh := range detailsHashes {
+		level[i] = LeafHash(dh)
+	}
+	t := &Tree{levels: [][][]byte{level}}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, nodeHash(level[i], level[i+1]))
+			} else {
+				next = append(next, level[i])
+			}
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+	return t
+}
+
+// Size returns the number of leaves.
+func (t *Tree) Size() int64 {
+	return int64(len(t.levels[0]))
+}
+
+// Root returns the tree's root hash in hex. The root of an empty tree is
+// the hash of the empty string, as in RFC 6962.
+func (t *Tree) Root() string {
+	top := t.levels[len(t.levels)-1]
+	if len(top) == 0 {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:])
+	}
+	return hex.EncodeToString(top[0])
+}
+
+// Proof returns the audit path for leaf index: the hex sibling hashes from
+// the leaf up to the root.
+func (t *Tree) Proof(index int64) ([]string, error) {
+	if index < 0 || index >= t.Size() {
+		return nil, errors.New("auditchain: leaf index out of range")
+	}
+	path := []string{}
+	i := int(index)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := i ^ 1
+		if sibling < len(level) {
+			path = append(path, hex.EncodeToString(level[sibling]))
+		}
+		i /= 2
+	}
+	return path, nil
+}
+
+// VerifyInclusion reports whether path proves that the entry with
+// detailsHash is leaf index of a tree of size leaves with the given root.
+// It is the verification algorithm of RFC 9162 §2.1.3.2.
+func VerifyInclusion(detailsHash string, index, size int64, path []string, root string) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	want, err := hex.DecodeString(root)
+	if err != nil {
+		return false
+	}
+
+	fn, sn := index, size-1
+	r := LeafHash(detailsHash)
+	for _, p := range path {
+		sibling, err := hex.DecodeString(p)
+		if err != nil || sn == 0 {
+			return false
+		}
+		if fn%2 == 1 || fn == sn {
+			r = nodeHash(sibling, r)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, sibling)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, want)
+}
@@ -0,0 +1,96 @@
+package auditchain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// referenceRoot is the recursive RFC 6962 definition of the tree hash.
+func referenceRoot(leaves []string) []byte {
+	if len(leaves) == 1 {
+		return LeafHash(leaves[0])
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	return nodeHash(referenceRoot(leaves[:k]), referenceRoot(leaves[k:]))
+}
+
+func testLeaves(n int) []string {
+	leaves := make([]string, n)
+	for i := range leaves {
+		leaves[i] = fmt.Sprintf("%064x", i+1)
+	}
+	return leaves
+}
+
+func TestTree_RootMatchesRFC6962(t *testing.T) {
+	for n := 1; n <= 70; n++ {
+		leaves := testLeaves(n)
+		if got, want := NewTree(leaves).Root(), hex.EncodeToString(referenceRoot(leaves)); got != want {
+			t.Errorf("n=%d: root = %s, want %s", n, got, want)
+		}
+	}
+}
+
+func TestTree_EveryProofVerifies(t *testing.T) {
+	for n := 1; n <= 70; n++ {
+		leaves := testLeaves(n)
+		tree := NewTree(leaves)
+		for i, leaf := range leaves {
+			path, err := tree.Proof(int64(i))
+			if err != nil {
+				t.Fatalf("n=%d i=%d: %v", n, i, err)
+			}
+			if !VerifyInclusion(leaf, int64(i), int64(n), path, tree.Root()) {
+				t.Errorf("n=%d i=%d: valid proof rejected", n, i)
+			}
+		}
+	}
+}
+
+func TestVerifyInclusion_RejectsForgeries(t *testing.T) {
+	leaves := testLeaves(11)
+	tree := NewTree(leaves)
+	root := tree.Root()
+	path, _ := tree.Proof(6)
+
+	tampered := append([]string(nil), path...)
+	tampered[1] = fmt.Sprintf("%064x", 0)
+
+	tests := []struct {
+		name  string
+		leaf  string
+		index int64
+		size  int64
+		path  []string
+		root  string
+	}{
+		{"other leaf", leaves[5], 6, 11, path, root},
+		{"wrong index", leaves[6], 5, 11, path, root},
+		{"wrong size", leaves[6], 6, 8, path, root},
+		{"truncated path", leaves[6], 6, 11, path[:len(path)-1], root},
+		{"extended path", leaves[6], 6, 11, append(append([]string(nil), path...), path[0]), root},
+		{"tampered path", leaves[6], 6, 11, tampered, root},
+		{"other root", leaves[6], 6, 11, path, NewTree(leaves[:10]).Root()},
+		{"index out of range", leaves[6], 11, 11, path, root},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if VerifyInclusion(tt.leaf, tt.index, tt.size, tt.path, tt.root) {
+				t.Error("forged proof accepted")
+			}
+		})
+	}
+}
+
+func TestTree_ProofOutOfRange(t *testing.T) {
+	tree := NewTree(testLeaves(3))
+	for _, i := range []int64{-1, 3} {
+		if _, err := tree.Proof(i); err == nil {
+			t.Errorf("Proof(%d) succeeded", i)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package auditchain
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// SignedBundleFormat identifies a SignedBundle file.
+const SignedBundleFormat = "ragbox-audit-bundle+ed25519"
+
+// bundleDomain prefixes every signed bundle message, so a bundle signature
+// can never be replayed as a checkpoint signature or the other way round.
+const bundleDomain = "ragbox-audit-bundle/v1\n"
+
+// SignedBundle is a Bundle with a detached Ed25519 signature over its exact
+// encoded bytes, so the whole export (entries, hashes, proofs and
+// checkpoints) is attributable to the server, including entries no
+// checkpoint covers yet. Bundle is kept as raw bytes: re-encoding it would
+// change what was signed.
+type SignedBundle struct {
+	Format    string          `json:"format"`
+	KeyID     string          `json:"keyId"`
+	Signature string          `json:"signature"` // base64
+	Bundle    json.RawMessage `json:"bundle"`
+}
+
+// SignBundle encodes b and signs the encoding with key.
+func SignBundle(b *Bundle, key ed25519.PrivateKey) (*SignedBundle, error) {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("auditchain.SignBundle: %w", err)
+	}
+	return &SignedBundle{
+		Format:    SignedBundleFormat,
+		KeyID:     KeyID(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, bundleMessage(raw))),
+		Bundle:    raw,
+	}, nil
+}
+
+func bundleMessage(raw []byte) []byte {
+	return append([]byte(bundleDomain), raw...)
+}
+
+// VerifySignature checks the detached signature against the trusted key
+// with the bundle's key ID.
+func (sb *SignedBundle) VerifySignature(trusted ...ed25519.PublicKey) error {
+	if sb.Format != SignedBundleFormat {
+		return fmt.Errorf("unsupported signed bundle format %q", sb.Format)
+	}
+	for _, pub := range trusted {
+		if KeyID(pub) != sb.KeyID {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(sb.Signature)
+		if err != nil || !ed25519.Verify(pub, bundleMessage(sb.Bundle), sig) {
+			return fmt.Errorf("bundle signature is not valid for key %q", sb.KeyID)
+		}
+		return nil
+	}
+	return fmt.Errorf("bundle is signed by untrusted key %q", sb.KeyID)
+}
+
+// Open decodes the signed bundle without checking the signature.
+func (sb *SignedBundle) Open() (*Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal(sb.Bundle, &b); err != nil {
+		return nil, fmt.Errorf("auditchain.SignedBundle: %w", err)
+	}
+	return &b, nil
+}
+
+// VerifySignedBundle checks the detached signature and then the bundle as
+// VerifyBundle does, reporting a bad signature as one more failure.
+func VerifySignedBundle(sb *SignedBundle, trusted ...ed25519.PublicKey) (*BundleReport, error) {
+	b, err := sb.Open()
+	if err != nil {
+		return nil, err
+	}
+	rep := VerifyBundle(b, trusted...)
+	rep.Signed = true
+	if err := sb.VerifySignature(trusted...); err != nil {
+		rep.Failures = append([]BundleFailure{{Reason: err.Error()}}, rep.Failures...)
+		rep.Valid = false
+	}
+	return rep, nil
+}
@@ -0,0 +1,95 @@
+package auditchain
+
+import (
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// Chain break reasons.
+const (
+	BreakHash    = "hash does not match entry contents"
+	BreakLink    = "previous hash does not match the previous entry"
+	BreakMissing = "entries are missing"
+	BreakHead    = "chain head does not match the last entry"
+)
+
+// DefaultMaxReportedBreaks caps the breaks a ChainVerifier lists; it keeps
+// counting past the cap.
+const DefaultMaxReportedBreaks = 1000
+
+// ChainBreak is one integrity failure in a chain.
+type ChainBreak struct {
+	Seq     int64  `json:"seq"`
+	ToSeq   int64  `json:"toSeq,omitempty"` // last missing seq, for BreakMissing
+	EntryID string `json:"entryId,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// ChainVerifier checks a run of one tenant's chain fed to it in seq order,
+// without holding the run in memory. Unlike a first-failure check it
+// carries on past every break, so one tampered entry does not hide others.
+type ChainVerifier struct {
+	next     int64   // seq expected next
+	prevHash *string // hash the next entry must link to; nil when unknown
+
+	MaxReported int
+	Checked     int
+	BreakCount  int
+	Breaks      []ChainBreak
+}
+
+// NewChainVerifier starts a run at seq from. prevHash is the details hash
+// of entry from-1, "" at the start of a chain, or nil when that entry is
+// not available, in which case the first link is not checked.
+func NewChainVerifier(from int64, prevHash *string) *ChainVerifier {
+	return &ChainVerifier{next: from, prevHash: prevHash, MaxReported: DefaultMaxReportedBreaks}
+}
+
+// Add checks the next entry of the run. Entries must come in increasing
+// seq order; skipped seqs are reported as missing.
+func (v *ChainVerifier) Add(e *model.AuditLog) {
+	if e.Seq > v.next {
+		v.fail(ChainBreak{Seq: v.next, ToSeq: e.Seq - 1, Reason: BreakMissing})
+		v.prevHash = nil
+	}
+	if !Intact(e) {
+		v.fail(ChainBreak{Seq: e.Seq, EntryID: e.ID, Reason: BreakHash})
+	}
+	if v.prevHash != nil && deref(e.PrevHash) != *v.prevHash {
+		v.fail(ChainBreak{Seq: e.Seq, EntryID: e.ID, Reason: BreakLink})
+	}
+	h := deref(e.DetailsHash)
+	v.prevHash = &h
+	v.next = e.Seq + 1
+	v.Checked++
+}
+
+// Finish ends a run that should reach seq to, reporting any entries
+// missing at its end.
+func (v *ChainVerifier) Finish(to int64) {
+	if v.next <= to {
+		v.fail(ChainBreak{Seq: v.next, ToSeq: to, Reason: BreakMissing})
+		v.next = to + 1
+	}
+}
+
+// FinishAtHead ends a run that reaches the chain head, which records the
+// last entry's seq and hash: missing tail entries and a head that does
+// not match are both reported.
+func (v *ChainVerifier) FinishAtHead(headSeq int64, headHash string) {
+	v.Finish(headSeq)
+	if v.prevHash != nil && *v.prevHash != headHash {
+		v.fail(ChainBreak{Seq: headSeq, Reason: BreakHead})
+	}
+}
+
+// Valid reports whether no break was found.
+func (v *ChainVerifier) Valid() bool {
+	return v.BreakCount == 0
+}
+
+func (v *ChainVerifier) fail(b ChainBreak) {
+	v.BreakCount++
+	if len(v.Breaks) < v.MaxReported {
+		v.Breaks = append(v.Breaks, b)
+	}
+}
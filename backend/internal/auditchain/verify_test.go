@@ -0,0 +1,106 @@
+package auditchain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChainVerifier_IntactChain(t *testing.T) {
+	entries := testChain(5)
+	v := NewChainVerifier(1, new(string))
+	for i := range entries {
+		v.Add(&entries[i])
+	}
+	v.FinishAtHead(5, *entries[4].DetailsHash)
+
+	if !v.Valid() || v.Checked != 5 {
+		t.Errorf("verifier = %+v, want 5 valid entries", v)
+	}
+}
+
+func TestChainVerifier_ReportsEveryBreak(t *testing.T) {
+	entries := testChain(10)
+	entries[1].Action = "TAMPERED" // seq 2: content no longer matches its hash
+
+	// seq 5: re-hashed onto a forged link, so its own hash checks out.
+	forgedPrev := "forged"
+	entries[4].PrevHash = &forgedPrev
+	forged := EntryHash(forgedPrev, &entries[4])
+	entries[4].DetailsHash = &forged
+
+	v := NewChainVerifier(1, new(string))
+	for i := range entries {
+		if i == 6 || i == 7 { // seqs 7-8 deleted
+			continue
+		}
+		v.Add(&entries[i])
+	}
+	v.FinishAtHead(12, *entries[9].DetailsHash) // head says two more were written
+
+	want := []ChainBreak{
+		{Seq: 2, EntryID: "entry-1", Reason: BreakHash},
+		{Seq: 5, EntryID: "entry-4", Reason: BreakLink},
+		{Seq: 6, EntryID: "entry-5", Reason: BreakLink}, // links to the original seq 5
+		{Seq: 7, ToSeq: 8, Reason: BreakMissing},
+		{Seq: 11, ToSeq: 12, Reason: BreakMissing},
+	}
+	if !reflect.DeepEqual(v.Breaks, want) {
+		t.Errorf("breaks = %+v\nwant %+v", v.Breaks, want)
+	}
+	if v.Checked != 8 || v.BreakCount != 5 || v.Valid() {
+		t.Errorf("checked = %d, breaks = %d", v.Checked, v.BreakCount)
+	}
+}
+
+func TestChainVerifier_MidChainRange(t *testing.T) {
+	entries := testChain(6)
+
+	// A run starting mid-chain links to the entry before it.
+	v := NewChainVerifier(3, entries[1].DetailsHash)
+	for i := 2; i < 5; i++ {
+		v.Add(&entries[i])
+	}
+	v.Finish(5)
+	if !v.Valid() {
+		t.Errorf("breaks = %+v", v.Breaks)
+	}
+
+	// Anchored to the wrong hash, the first entry does not link.
+	v = NewChainVerifier(3, entries[0].DetailsHash)
+	v.Add(&entries[2])
+	if v.Valid() || v.Breaks[0].Reason != BreakLink {
+		t.Errorf("breaks = %+v, want a link break", v.Breaks)
+	}
+
+	// Without an anchor the first link cannot be checked.
+	v = NewChainVerifier(3, nil)
+	v.Add(&entries[2])
+	if !v.Valid() {
+		t.Errorf("breaks = %+v", v.Breaks)
+	}
+}
+
+func TestChainVerifier_HeadMismatch(t *testing.T) {
+	entries := testChain(3)
+	v := NewChainVerifier(1, new(string))
+	for i := range entries {
+		v.Add(&entries[i])
+	}
+	v.FinishAtHead(3, "rewritten")
+	if len(v.Breaks) != 1 || v.Breaks[0].Reason != BreakHead {
+		t.Errorf("breaks = %+v, want head mismatch", v.Breaks)
+	}
+}
+
+func TestChainVerifier_CapsReportedBreaks(t *testing.T) {
+	entries := testChain(5)
+	v := NewChainVerifier(1, new(string))
+	v.MaxReported = 2
+	for i := range entries {
+		entries[i].Action = "TAMPERED"
+		v.Add(&entries[i])
+	}
+	if v.BreakCount != 5 || len(v.Breaks) != 2 {
+		t.Errorf("count = %d, listed = %d", v.BreakCount, len(v.Breaks))
+	}
+}
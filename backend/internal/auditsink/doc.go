@@ -0,0 +1,10 @@
+// Package auditsink implements the external destinations audit entries are
+// delivered to through the audit outbox (see service.AuditDispatcher): an
+// append-only rotated JSONL file, an RFC 5424 syslog forwarder and an
+// HMAC-signed webhook for SIEM ingestion.
+//
+// Every sink writes each entry as an auditchain.BundleEntry, whose details
+// are the exact stored text, so a receiver can recompute the entry hash
+// with auditchain.EntryHash and check the chain itself. Delivery is at
+// least once; receivers deduplicate on the entry ID.
+package auditsink
@@ -0,0 +1,132 @@
+package auditsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Compile-time check.
+var _ service.AuditSink = (*FileSink)(nil)
+
+// FileSink appends audit entries to a JSONL file, one entry per line. When
+// a write would take the file past maxBytes it is renamed with a timestamp
+// suffix, made read-only, and a new file is started. Rotated files are
+// never deleted here; retention is left to the operator.
+type FileSink struct {
+	path     string
+	maxBytes int64
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+	now  func() time.Time
+}
+
+// NewFileSink opens (or creates) the JSONL file at path for appending.
+// maxBytes <= 0 disables rotation.
+func NewFileSink(path string, maxBytes int64) (*FileSink, error) {
+	s := &FileSink{path: path, maxBytes: maxBytes, now: time.Now}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name implements service.AuditSink.
+func (s *FileSink) Name() string { return "file" }
+
+// Write appends entries and syncs the file before returning.
+func (s *FileSink) Write(_ context.Context, entries []model.AuditLog) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(auditchain.NewBundleEntry(e, nil)); err != nil {
+			return fmt.Errorf("auditsink.File: encode %s: %w", e.ID, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		// A previous rotation failed half way; try again.
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	if s.maxBytes > 0 && s.size > 0 && s.size+int64(buf.Len()) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.f.Write(buf.Bytes()); err != nil {
+		// Drop a torn line so the retry leaves the file parseable.
+		if s.f.Truncate(s.size) != nil {
+			s.f.Close()
+			s.f = nil
+		}
+		return fmt.Errorf("auditsink.File: write: %w", err)
+	}
+	s.size += int64(buf.Len())
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("auditsink.File: sync: %w", err)
+	}
+	return nil
+}
+
+// Close closes the current file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("auditsink.File: open: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("auditsink.File: stat: %w", err)
+	}
+	s.f, s.size = f, info.Size()
+	return nil
+}
+
+// rotate moves the current file aside as path-<UTC timestamp>.ext and
+// opens a fresh one.
+func (s *FileSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return fmt.Errorf("auditsink.File: close: %w", err)
+	}
+	s.f = nil
+
+	ext := filepath.Ext(s.path)
+	rotated := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(s.path, ext),
+		s.now().UTC().Format("20060102T150405.000000000Z"), ext)
+	if err := os.Rename(s.path, rotated); err != nil {
+		return fmt.Errorf("auditsink.File: rotate: %w", err)
+	}
+	if err := os.Chmod(rotated, 0o400); err != nil {
+		return fmt.Errorf("auditsink.File: rotate: %w", err)
+	}
+	return s.open()
+}
@@ -0,0 +1,134 @@
+package auditsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+func testEntry(seq int64, details string) model.AuditLog {
+	user := "user-1"
+	e := model.AuditLog{
+		ID:        "entry-" + string(rune('a'+seq)),
+		TenantID:  "user-1",
+		Seq:       seq,
+		UserID:    &user,
+		Action:    model.AuditDocumentDelete,
+		Severity:  "HIGH",
+		Details:   json.RawMessage(details),
+		CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC),
+	}
+	prev := "0000"
+	e.PrevHash = &prev
+	h := auditchain.EntryHash(prev, &e)
+	e.DetailsHash = &h
+	return e
+}
+
+func readLines(t *testing.T, path string) []auditchain.BundleEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var out []auditchain.BundleEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var be auditchain.BundleEntry
+		if err := json.Unmarshal(sc.Bytes(), &be); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		out = append(out, be)
+	}
+	return out
+}
+
+func TestFileSink_AppendsVerifiableLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	s, err := NewFileSink(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// JSONB text keeps its spacing; re-encoding would break the hash.
+	entries := []model.AuditLog{testEntry(1, `{"a": 1, "b": [1, 2]}`), testEntry(2, `{"z": "x"}`)}
+	if err := s.Write(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// Reopening appends rather than truncating.
+	s, err = NewFileSink(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Write(context.Background(), entries[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("lines = %d, want 3", len(lines))
+	}
+	for _, be := range lines {
+		e := be.Entry()
+		if !auditchain.Intact(&e) {
+			t.Errorf("entry %s does not verify after export", e.ID)
+		}
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestFileSink_RotatesAtMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	s, err := NewFileSink(path, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	tick := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { tick = tick.Add(time.Second); return tick }
+
+	for seq := int64(1); seq <= 6; seq++ {
+		if err := s.Write(context.Background(), []model.AuditLog{testEntry(seq, `{"n": 1}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rotated, _ := filepath.Glob(filepath.Join(dir, "audit-*.jsonl"))
+	if len(rotated) < 2 {
+		t.Fatalf("rotated files = %v, want several", rotated)
+	}
+	total := len(readLines(t, path))
+	for _, f := range rotated {
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 600 {
+			t.Errorf("%s is %d bytes, over the limit", f, info.Size())
+		}
+		if info.Mode().Perm() != 0o400 {
+			t.Errorf("%s mode = %v, want read-only", f, info.Mode().Perm())
+		}
+		if !strings.HasPrefix(filepath.Base(f), "audit-20260301T") {
+			t.Errorf("rotated name = %s", f)
+		}
+		total += len(readLines(t, f))
+	}
+	if total != 6 {
+		t.Errorf("entries across files = %d, want 6", total)
+	}
+}
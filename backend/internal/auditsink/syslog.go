@@ -0,0 +1,179 @@
+package auditsink
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Compile-time check.
+var _ service.AuditSink = (*SyslogSink)(nil)
+
+// Syslog message fields.
+const (
+	syslogFacilityAudit = 13 // "log audit" (RFC 5424 §6.2.1)
+	syslogAppName       = "ragbox"
+	// syslogSDID is the structured data ID; 32473 is the enterprise number
+	// RFC 5612 reserves for documentation, used until one is registered.
+	syslogSDID = "audit@32473"
+)
+
+// SyslogSink forwards audit entries as RFC 5424 messages, one per entry,
+// over UDP, TCP or TLS. Stream transports use octet-counting framing
+// (RFC 6587); UDP sends one message per datagram and cannot report loss,
+// so prefer TCP or TLS for audit trails.
+type SyslogSink struct {
+	network  string // "udp" or "tcp"
+	addr     string
+	tls      *tls.Config // non-nil for TLS
+	hostname string
+
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink creates a sink for a collector address such as
+// "tcp://siem.internal:601", "tls://siem.internal:6514" or
+// "udp://10.0.0.5:514". It connects on first write.
+func NewSyslogSink(rawURL string) (*SyslogSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("auditsink.Syslog: invalid address %q", rawURL)
+	}
+	s := &SyslogSink{addr: u.Host, Timeout: 10 * time.Second, hostname: syslogHostname()}
+	switch u.Scheme {
+	case "udp", "tcp":
+		s.network = u.Scheme
+	case "tls":
+		s.network = "tcp"
+		s.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	default:
+		return nil, fmt.Errorf("auditsink.Syslog: unsupported scheme %q (want udp, tcp or tls)", u.Scheme)
+	}
+	return s, nil
+}
+
+// Name implements service.AuditSink.
+func (s *SyslogSink) Name() string { return "syslog" }
+
+// Write sends one message per entry. On any error the connection is
+// dropped and redialled on the next write.
+func (s *SyslogSink) Write(ctx context.Context, entries []model.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return fmt.Errorf("auditsink.Syslog: dial: %w", err)
+		}
+		s.conn = conn
+	}
+
+	for _, e := range entries {
+		msg, err := formatSyslog(e, s.hostname)
+		if err != nil {
+			return fmt.Errorf("auditsink.Syslog: format %s: %w", e.ID, err)
+		}
+		if s.network == "tcp" {
+			msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+		s.conn.SetWriteDeadline(time.Now().Add(s.Timeout))
+		if _, err := s.conn.Write(msg); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return fmt.Errorf("auditsink.Syslog: write: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection, if open.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *SyslogSink) dial(ctx context.Context) (net.Conn, error) {
+	d := &net.Dialer{Timeout: s.Timeout}
+	if s.tls != nil {
+		return (&tls.Dialer{NetDialer: d, Config: s.tls}).DialContext(ctx, s.network, s.addr)
+	}
+	return d.DialContext(ctx, s.network, s.addr)
+}
+
+// formatSyslog renders e as an RFC 5424 message: the entry's identity in
+// structured data and the full entry as JSON in MSG.
+func formatSyslog(e model.AuditLog, hostname string) ([]byte, error) {
+	body, err := json.Marshal(auditchain.NewBundleEntry(e, nil))
+	if err != nil {
+		return nil, err
+	}
+	pri := syslogFacilityAudit*8 + syslogSeverity(e.Severity)
+	header := fmt.Sprintf("<%d>1 %s %s %s - %s ",
+		pri, e.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		headerField(hostname, 255), syslogAppName, headerField(e.Action, 32))
+	sd := fmt.Sprintf(`[%s id="%s" tenant="%s" seq="%d" severity="%s"]`,
+		syslogSDID, sdEscape(e.ID), sdEscape(e.TenantID), e.Seq, sdEscape(e.Severity))
+	return append([]byte(header+sd+" "), body...), nil
+}
+
+// syslogSeverity maps audit severities to syslog severities.
+func syslogSeverity(severity string) int {
+	switch severity {
+	case "HIGH":
+		return 4 // warning
+	case "MEDIUM":
+		return 5 // notice
+	default:
+		return 6 // informational
+	}
+}
+
+// headerField makes s a valid header field: printable US-ASCII without
+// spaces, at most max characters, "-" if empty.
+func headerField(s string, max int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < max; i++ {
+		if c := s[i]; c > ' ' && c < 0x7f {
+			b = append(b, c)
+		}
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// sdEscape escapes a structured data parameter value (RFC 5424 §6.3.3).
+func sdEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+func syslogHostname() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return "-"
+	}
+	return h
+}
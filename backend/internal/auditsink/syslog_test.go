@@ -0,0 +1,177 @@
+package auditsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// rfc5424 matches a header and structured data; MSG follows.
+var rfc5424 = regexp.MustCompile(`^<(\d+)>1 (\S+) (\S+) (\S+) (\S+) (\S+) (\[(?:[^\]\\]|\\.)*\]) (.*)$`)
+
+func TestFormatSyslog(t *testing.T) {
+	e := testEntry(7, `{"reason": "x"}`)
+	e.TenantID = `org"1]`
+	msg, err := formatSyslog(e, "api host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rfc5424.FindStringSubmatch(string(msg))
+	if m == nil {
+		t.Fatalf("not RFC 5424: %s", msg)
+	}
+	if m[1] != "108" { // log audit (13) * 8 + warning (4)
+		t.Errorf("PRI = %s, want 108", m[1])
+	}
+	if m[2] != "2026-03-01T12:00:00.123456Z" {
+		t.Errorf("TIMESTAMP = %s", m[2])
+	}
+	if m[3] != "apihost" || m[4] != "ragbox" || m[5] != "-" || m[6] != model.AuditDocumentDelete {
+		t.Errorf("header = %q", m[3:7])
+	}
+	if want := `[audit@32473 id="entry-h" tenant="org\"1\]" seq="7" severity="HIGH"]`; m[7] != want {
+		t.Errorf("SD = %s, want %s", m[7], want)
+	}
+	var be auditchain.BundleEntry
+	if err := json.Unmarshal([]byte(m[8]), &be); err != nil {
+		t.Fatalf("MSG: %v", err)
+	}
+	if be.Details != `{"reason": "x"}` {
+		t.Errorf("details = %q", be.Details)
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	for severity, want := range map[string]int{"HIGH": 4, "MEDIUM": 5, "LOW": 6, "INFO": 6} {
+		if got := syslogSeverity(severity); got != want {
+			t.Errorf("syslogSeverity(%s) = %d, want %d", severity, got, want)
+		}
+	}
+}
+
+func TestSyslogSink_TCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	got := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var msgs []string
+		for len(msgs) < 2 {
+			n, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(n))
+			buf := make([]byte, size)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				break
+			}
+			msgs = append(msgs, string(buf))
+		}
+		got <- msgs
+	}()
+
+	s, err := NewSyslogSink("tcp://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	entries := []model.AuditLog{testEntry(1, `{"a": 1}`), testEntry(2, `{"b": 2}`)}
+	if err := s.Write(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := <-got
+	if len(msgs) != 2 {
+		t.Fatalf("messages = %d, want 2", len(msgs))
+	}
+	for i, msg := range msgs {
+		if m := rfc5424.FindStringSubmatch(msg); m == nil || !strings.Contains(m[7], `id="`+entries[i].ID+`"`) {
+			t.Errorf("message %d = %q", i, msg)
+		}
+	}
+}
+
+func TestSyslogSink_RedialsAfterFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	s, err := NewSyslogSink("tcp://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The collector goes away: the write fails and the connection is dropped.
+	ln.Close()
+	if err := s.Write(context.Background(), []model.AuditLog{testEntry(1, `{}`)}); err == nil {
+		t.Fatal("write to a closed collector succeeded")
+	}
+
+	// It comes back on the same address: the next write redials.
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot rebind %s: %v", addr, err)
+	}
+	defer ln.Close()
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			io.Copy(io.Discard, conn)
+		}
+	}()
+	if err := s.Write(context.Background(), []model.AuditLog{testEntry(1, `{}`)}); err != nil {
+		t.Errorf("write after collector returned: %v", err)
+	}
+}
+
+func TestSyslogSink_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	s, err := NewSyslogSink("udp://" + pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Write(context.Background(), []model.AuditLog{testEntry(1, `{}`)}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64<<10)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// No octet count on datagrams.
+	if !rfc5424.Match(buf[:n]) {
+		t.Errorf("datagram = %q", buf[:n])
+	}
+}
+
+func TestNewSyslogSink_RejectsBadAddress(t *testing.T) {
+	for _, addr := range []string{"", "siem:514", "http://siem:514", "tcp://"} {
+		if _, err := NewSyslogSink(addr); err == nil {
+			t.Errorf("NewSyslogSink(%q) succeeded", addr)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package auditsink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Compile-time check.
+var _ service.AuditSink = (*WebhookSink)(nil)
+
+// Webhook signature headers.
+const (
+	WebhookTimestampHeader = "X-Ragbox-Timestamp"
+	WebhookSignatureHeader = "X-Ragbox-Signature"
+)
+
+// WebhookPayload is the JSON body POSTed to the webhook.
+type WebhookPayload struct {
+	Entries []auditchain.BundleEntry `json:"entries"`
+}
+
+// WebhookSink POSTs batches of audit entries to a SIEM endpoint. Each
+// request is signed with HMAC-SHA256 over "<timestamp>.<body>", sent as
+// X-Ragbox-Signature: sha256=<hex> with the Unix timestamp in
+// X-Ragbox-Timestamp, so the receiver can authenticate the batch and
+// reject replays. Any non-2xx response is a failed delivery.
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewWebhookSink creates a sink posting to url, signed with secret.
+func NewWebhookSink(url, secret string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// Name implements service.AuditSink.
+func (s *WebhookSink) Name() string { return "webhook" }
+
+// Write POSTs entries as one signed batch.
+func (s *WebhookSink) Write(ctx context.Context, entries []model.AuditLog) error {
+	payload := WebhookPayload{Entries: make([]auditchain.BundleEntry, len(entries))}
+	for i, e := range entries {
+		payload.Entries[i] = auditchain.NewBundleEntry(e, nil)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("auditsink.Webhook: encode: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("auditsink.Webhook: request: %w", err)
+	}
+	ts := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, ts)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(s.secret, ts, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("auditsink.Webhook: post: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("auditsink.Webhook: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>" under
+// secret. Receivers recompute it and compare with hmac.Equal.
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,66 @@
+package auditsink
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+func TestWebhookSink_PostsSignedBatch(t *testing.T) {
+	secret := "s3cret"
+	var gotBody []byte
+	var gotTS, gotSig string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotTS = r.Header.Get(WebhookTimestampHeader)
+		gotSig = r.Header.Get(WebhookSignatureHeader)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	s := NewWebhookSink(srv.URL, secret)
+	s.now = func() time.Time { return time.Unix(1700000000, 0) }
+	entries := []model.AuditLog{testEntry(1, `{"a": 1}`), testEntry(2, `{"b": 2}`)}
+	if err := s.Write(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotTS != "1700000000" {
+		t.Errorf("timestamp = %q", gotTS)
+	}
+	want := "sha256=" + SignWebhook([]byte(secret), gotTS, gotBody)
+	if !hmac.Equal([]byte(gotSig), []byte(want)) {
+		t.Errorf("signature = %q, want %q", gotSig, want)
+	}
+	if SignWebhook([]byte("other"), gotTS, gotBody) == strings.TrimPrefix(gotSig, "sha256=") {
+		t.Error("signature does not depend on the secret")
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Entries) != 2 || payload.Entries[0].ID != entries[0].ID || payload.Entries[1].Details != `{"b": 2}` {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestWebhookSink_Non2xxFails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookSink(srv.URL, "k").Write(context.Background(), []model.AuditLog{testEntry(1, `{}`)})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("err = %v, want status 503", err)
+	}
+}
@@ -15,12 +15,21 @@ import (
 	"time"
 )
 
+const (
+	// DefaultEmbeddingCacheMaxEntries bounds the embedding cache by entry count.
+	DefaultEmbeddingCacheMaxEntries = 50000
+	// DefaultEmbeddingCacheMaxBytes bounds the embedding cache by estimated size (128 MiB).
+	DefaultEmbeddingCacheMaxBytes = 128 << 20
+)
+
 // EmbeddingCache caches query embedding vectors keyed by normalized query hash.
-// Thread-safe via sync.RWMutex. Entries auto-expire after TTL.
+// Thread-safe via sync.Mutex. Entries auto-expire after TTL, and the least
+// recently used are evicted once the entry or byte limit is exceeded.
 type EmbeddingCache struct {
-	mu      sync.RWMutex
-	entries map[string]*embeddingEntry
+	mu      sync.Mutex
+	entries *lru[*embeddingEntry]
 	ttl     time.Duration
+	meter   meter
 	stopCh  chan struct{}
 }
 
@@ -40,32 +49,52 @@ func DefaultEmbeddingTTL() time.Duration {
 	return 15 * time.Minute
 }
 
-// NewEmbeddingCache creates an EmbeddingCache with the given TTL and starts background cleanup.
+// NewEmbeddingCache creates an EmbeddingCache with the given TTL and default
+// size limits, and starts background cleanup.
 func NewEmbeddingCache(ttl time.Duration) *EmbeddingCache {
 	c := &EmbeddingCache{
-		entries: make(map[string]*embeddingEntry),
-		ttl:     ttl,
-		stopCh:  make(chan struct{}),
+		ttl:    ttl,
+		meter:  meter{layer: "embedding"},
+		stopCh: make(chan struct{}),
 	}
+	c.entries = newLRU(DefaultEmbeddingCacheMaxEntries, DefaultEmbeddingCacheMaxBytes,
+		func(_ string, _ *embeddingEntry, reason string) {
+			if reason != "" {
+				c.meter.evict(reason)
+			}
+		})
 	go c.cleanup()
 	return c
 }
 
+// SetLimits changes the entry and byte bounds, evicting immediately if the
+// cache is over them. Zero disables a bound.
+func (c *EmbeddingCache) SetLimits(maxEntries int, maxBytes int64) {
+	c.mu.Lock()
+	c.entries.setLimits(maxEntries, maxBytes)
+	c.mu.Unlock()
+}
+
+// SetObserver reports hits, misses, evictions and lookup latency to o.
+func (c *EmbeddingCache) SetObserver(o Observer) {
+	c.meter.setObserver(o)
+}
+
 // Get returns a cached embedding vector if present and not expired.
 func (c *EmbeddingCache) Get(queryHash string) ([]float32, bool) {
-	c.mu.RLock()
-	entry, ok := c.entries[queryHash]
-	c.mu.RUnlock()
+	start := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries.get(queryHash)
+	if ok && start.After(entry.expiresAt) {
+		c.entries.remove(queryHash, EvictExpired)
+		ok = false
+	}
+	c.mu.Unlock()
+	c.meter.lookup(ok, start)
 
 	if !ok {
 		return nil, false
 	}
-	if time.Now().After(entry.expiresAt) {
-		c.mu.Lock()
-		delete(c.entries, queryHash)
-		c.mu.Unlock()
-		return nil, false
-	}
 
 	slog.Info("[EMBED-CACHE] hit",
 		"query_hash", queryHash,
@@ -78,11 +107,11 @@ func (c *EmbeddingCache) Get(queryHash string) ([]float32, bool) {
 func (c *EmbeddingCache) Set(queryHash string, vec []float32) {
 	now := time.Now()
 	c.mu.Lock()
-	c.entries[queryHash] = &embeddingEntry{
+	c.entries.add(queryHash, &embeddingEntry{
 		vec:       vec,
 		createdAt: now,
 		expiresAt: now.Add(c.ttl),
-	}
+	}, embeddingBytes(queryHash, vec))
 	c.mu.Unlock()
 
 	slog.Info("[EMBED-CACHE] set",
@@ -94,9 +123,21 @@ func (c *EmbeddingCache) Set(queryHash string, vec []float32) {
 
 // Len returns the number of entries in the cache.
 func (c *EmbeddingCache) Len() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return len(c.entries)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.len()
+}
+
+// Stats returns a snapshot of size and hit counters.
+func (c *EmbeddingCache) Stats() Stats {
+	st := c.meter.stats()
+	c.mu.Lock()
+	st.Entries = c.entries.len()
+	st.Bytes = c.entries.bytes
+	st.MaxEntries = c.entries.maxEntries
+	st.MaxBytes = c.entries.maxBytes
+	c.mu.Unlock()
+	return st
 }
 
 // Stop halts the background cleanup goroutine.
@@ -113,13 +154,17 @@ func (c *EmbeddingCache) cleanup() {
 		case <-ticker.C:
 			now := time.Now()
 			c.mu.Lock()
-			before := len(c.entries)
-			for key, entry := range c.entries {
+			before := c.entries.len()
+			var expired []string
+			c.entries.each(func(key string, entry *embeddingEntry) {
 				if now.After(entry.expiresAt) {
-					delete(c.entries, key)
+					expired = append(expired, key)
 				}
+			})
+			for _, key := range expired {
+				c.entries.remove(key, EvictExpired)
 			}
-			after := len(c.entries)
+			after := c.entries.len()
 			c.mu.Unlock()
 			if before != after {
 				slog.Info("[EMBED-CACHE] cleanup", "removed", before-after, "remaining", after)
@@ -137,3 +182,15 @@ func EmbeddingQueryHash(query string) string {
 	h := sha256.Sum256([]byte(normalized))
 	return fmt.Sprintf("emb:%x", h[:16])
 }
+
+// EmbeddingSpaceQueryHash returns a cache key for a query embedded in a specific
+// embedding space, so vectors cached before a model cutover are never reused
+// after it. An empty spaceKey yields the same key as EmbeddingQueryHash.
+func EmbeddingSpaceQueryHash(spaceKey, query string) string {
+	if spaceKey == "" {
+		return EmbeddingQueryHash(query)
+	}
+	normalized := strings.ToLower(strings.TrimSpace(query))
+	h := sha256.Sum256([]byte(spaceKey + "\x00" + normalized))
+	return fmt.Sprintf("emb:%x", h[:16])
+}
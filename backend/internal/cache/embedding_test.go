@@ -86,6 +86,17 @@ func TestEmbeddingQueryHash_Different(t *testing.T) {
 	}
 }
 
+func TestEmbeddingSpaceQueryHash_ScopedBySpace(t *testing.T) {
+	h1 := EmbeddingSpaceQueryHash("text-embedding-004@1", "query")
+	h2 := EmbeddingSpaceQueryHash("text-embedding-005@2", "query")
+	if h1 == h2 {
+		t.Fatal("same query in different embedding spaces should produce different hashes")
+	}
+	if got := EmbeddingSpaceQueryHash("", "query"); got != EmbeddingQueryHash("query") {
+		t.Fatalf("empty space key = %s, want %s", got, EmbeddingQueryHash("query"))
+	}
+}
+
 func TestEmbeddingCache_Roundtrip768(t *testing.T) {
 	c := NewEmbeddingCache(1 * time.Minute)
 	defer c.Stop()
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Invalidator fans cache invalidations out to every layer that holds
+// document-derived results: the in-memory QueryCache and SemanticCache on
+// this instance, the shared Redis entries, and — via Redis pub/sub — the
+// in-memory caches of every other server instance.
+//
+// All methods are nil-safe, and nil layers are skipped.
+type Invalidator struct {
+	query    *QueryCache
+	semantic *SemanticCache
+	redis    *RedisCache
+	origin   string
+}
+
+// NewInvalidator creates an Invalidator over the given layers. Any may be nil.
+func NewInvalidator(query *QueryCache, semantic *SemanticCache, redis *RedisCache) *Invalidator {
+	return &Invalidator{
+		query:    query,
+		semantic: semantic,
+		redis:    redis,
+		origin:   uuid.NewString(),
+	}
+}
+
+// InvalidateDocuments drops exactly the cached retrievals and answers built
+// from docIDs, on every instance. Use it when a document's content, ranking
+// or visibility changes (delete, chunk deletion, tier or privilege change).
+func (inv *Invalidator) InvalidateDocuments(ctx context.Context, userID string, docIDs ...string) {
+	if inv == nil || len(docIDs) == 0 {
+		return
+	}
+	inv.applyLocal(userID, docIDs)
+	inv.redis.InvalidateDocuments(ctx, docIDs...)
+	inv.publish(ctx, service.CacheInvalidation{UserID: userID, DocumentIDs: docIDs})
+}
+
+// InvalidateUser drops every cached retrieval and answer for userID, on every
+// instance. Use it when the user's corpus grows (ingest, recovery, a document
+// becoming visible outside Privileged Mode), since a new document can change
+// results that no cached entry attributes to it.
+func (inv *Invalidator) InvalidateUser(ctx context.Context, userID string) {
+	if inv == nil || userID == "" {
+		return
+	}
+	inv.applyLocal(userID, nil)
+	inv.redis.InvalidateUser(ctx, userID)
+	inv.publish(ctx, service.CacheInvalidation{UserID: userID})
+}
+
+// Apply handles an invalidation received from another instance or a worker.
+// In-memory layers are always cleared; shared Redis entries are cleared by the
+// first instance to claim the message unless the publisher already did.
+func (inv *Invalidator) Apply(ctx context.Context, msg service.CacheInvalidation) {
+	if inv == nil || msg.Origin == inv.origin {
+		return
+	}
+	inv.applyLocal(msg.UserID, msg.DocumentIDs)
+	if msg.SharedCleared || inv.redis == nil || !inv.redis.claimInvalidation(ctx, msg.ID) {
+		return
+	}
+	if len(msg.DocumentIDs) > 0 {
+		inv.redis.InvalidateDocuments(ctx, msg.DocumentIDs...)
+	} else if msg.UserID != "" {
+		inv.redis.InvalidateUser(ctx, msg.UserID)
+	}
+}
+
+// Run applies invalidations broadcast by other instances until ctx is
+// cancelled. It returns immediately when Redis is not configured.
+func (inv *Invalidator) Run(ctx context.Context) {
+	if inv == nil || inv.redis == nil {
+		return
+	}
+	sub := inv.redis.Subscribe(ctx)
+	defer sub.Close()
+	slog.Info("[CACHE] listening for invalidations", "channel", service.CacheInvalidationChannel)
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m, ok := <-ch:
+			if !ok {
+				return
+			}
+			var msg service.CacheInvalidation
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				slog.Warn("[CACHE] malformed invalidation", "error", err)
+				continue
+			}
+			inv.Apply(ctx, msg)
+		}
+	}
+}
+
+func (inv *Invalidator) applyLocal(userID string, docIDs []string) {
+	if len(docIDs) == 0 {
+		if userID == "" {
+			return
+		}
+		if inv.query != nil {
+			inv.query.InvalidateUser(userID)
+		}
+		if inv.semantic != nil {
+			inv.semantic.InvalidateUser(userID)
+		}
+		return
+	}
+	for _, id := range docIDs {
+		if inv.query != nil {
+			inv.query.InvalidateDocument(id)
+		}
+		if inv.semantic != nil {
+			inv.semantic.InvalidateDocument(id)
+		}
+	}
+}
+
+func (inv *Invalidator) publish(ctx context.Context, msg service.CacheInvalidation) {
+	if inv.redis == nil {
+		return
+	}
+	msg.ID = uuid.NewString()
+	msg.Origin = inv.origin
+	msg.SharedCleared = true
+	if err := inv.redis.PublishInvalidation(ctx, msg); err != nil {
+		slog.Warn("[CACHE] invalidation broadcast failed", "user_id", msg.UserID, "error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func newTestInvalidator(t *testing.T) (*Invalidator, *QueryCache, *SemanticCache) {
+	t.Helper()
+	q := New(time.Hour)
+	s := NewSemanticCache(time.Hour, 0.9)
+	t.Cleanup(func() { q.Stop(); s.Stop() })
+	return NewInvalidator(q, s, nil), q, s
+}
+
+func TestInvalidator_DocumentsAreExact(t *testing.T) {
+	inv, q, s := newTestInvalidator(t)
+
+	other := makeResult("b.pdf")
+	other.Chunks[0].Document.ID = "doc-2"
+	q.Set("user-1", "query-a", false, makeResult("a.pdf"))
+	q.Set("user-1", "query-b", false, other)
+	s.Store(testScope("user-1"), "query-a", []float32{1, 0}, semanticAnswer("doc-1"), []string{"doc-1"})
+	s.Store(testScope("user-1"), "query-b", []float32{0, 1}, semanticAnswer("doc-2"), []string{"doc-2"})
+
+	inv.InvalidateDocuments(context.Background(), "user-1", "doc-1")
+
+	if _, ok := q.Get("user-1", "query-a", false); ok {
+		t.Error("retrieval built from doc-1 should be invalidated")
+	}
+	if _, ok := q.Get("user-1", "query-b", false); !ok {
+		t.Error("retrieval built from doc-2 should survive")
+	}
+	if s.Len() != 1 {
+		t.Errorf("semantic entries = %d, want 1", s.Len())
+	}
+}
+
+func TestInvalidator_ApplyRemote(t *testing.T) {
+	inv, q, s := newTestInvalidator(t)
+	q.Set("user-1", "query-a", false, makeResult("a.pdf"))
+	s.Store(testScope("user-1"), "query-a", []float32{1, 0}, semanticAnswer("doc-1"), []string{"doc-1"})
+
+	// Messages this instance published were already applied locally.
+	inv.Apply(context.Background(), service.CacheInvalidation{Origin: inv.origin, UserID: "user-1"})
+	if q.Len() != 1 || s.Len() != 1 {
+		t.Fatal("own broadcast must be ignored")
+	}
+
+	inv.Apply(context.Background(), service.CacheInvalidation{Origin: "other", UserID: "user-1"})
+	if q.Len() != 0 || s.Len() != 0 {
+		t.Errorf("user-wide remote invalidation left %d retrieval and %d semantic entries", q.Len(), s.Len())
+	}
+}
+
+func TestInvalidator_NilSafe(t *testing.T) {
+	var inv *Invalidator
+	inv.InvalidateDocuments(context.Background(), "user-1", "doc-1")
+	inv.InvalidateUser(context.Background(), "user-1")
+	inv.Apply(context.Background(), service.CacheInvalidation{UserID: "user-1"})
+	inv.Run(context.Background())
+}
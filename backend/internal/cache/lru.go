@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Eviction reasons reported to Observer.
+const (
+	EvictCapacity    = "capacity"    // over the entry or byte limit
+	EvictExpired     = "expired"     // past TTL
+	EvictInvalidated = "invalidated" // dropped by a user or document invalidation
+)
+
+// Observer receives per-layer cache events. Implemented by middleware.Metrics.
+type Observer interface {
+	ObserveCacheLookup(layer string, hit bool, d time.Duration)
+	ObserveCacheEviction(layer, reason string)
+}
+
+// Stats is a point-in-time snapshot of one cache layer.
+type Stats struct {
+	Layer      string `json:"layer"`
+	Entries    int    `json:"entries"`
+	Bytes      int64  `json:"bytes"`
+	MaxEntries int    `json:"maxEntries"`
+	MaxBytes   int64  `json:"maxBytes"`
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
+	Evictions  uint64 `json:"evictions"`
+}
+
+// meter counts hits, misses and evictions for one layer and forwards them to
+// an optional Observer.
+type meter struct {
+	layer     string
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+
+	mu       sync.RWMutex
+	observer Observer
+}
+
+func (m *meter) setObserver(o Observer) {
+	m.mu.Lock()
+	m.observer = o
+	m.mu.Unlock()
+}
+
+func (m *meter) lookup(hit bool, start time.Time) {
+	if hit {
+		m.hits.Add(1)
+	} else {
+		m.misses.Add(1)
+	}
+	m.mu.RLock()
+	o := m.observer
+	m.mu.RUnlock()
+	if o != nil {
+		o.ObserveCacheLookup(m.layer, hit, time.Since(start))
+	}
+}
+
+func (m *meter) evict(reason string) {
+	m.evictions.Add(1)
+	m.mu.RLock()
+	o := m.observer
+	m.mu.RUnlock()
+	if o != nil {
+		o.ObserveCacheEviction(m.layer, reason)
+	}
+}
+
+func (m *meter) stats() Stats {
+	return Stats{
+		Layer:     m.layer,
+		Hits:      m.hits.Load(),
+		Misses:    m.misses.Load(),
+		Evictions: m.evictions.Load(),
+	}
+}
+
+// lru is a least-recently-used map bounded by entry count and estimated bytes.
+// A zero limit means unbounded on that axis. Not safe for concurrent use;
+// owners guard it with their own mutex.
+type lru[V any] struct {
+	ll         *list.List
+	items      map[string]*list.Element
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	onEvict    func(key string, value V, reason string)
+}
+
+type lruItem[V any] struct {
+	key   string
+	value V
+	size  int64
+}
+
+func newLRU[V any](maxEntries int, maxBytes int64, onEvict func(string, V, string)) *lru[V] {
+	return &lru[V]{
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		onEvict:    onEvict,
+	}
+}
+
+// get returns the value for key and marks it most recently used.
+func (l *lru[V]) get(key string) (V, bool) {
+	if el, ok := l.items[key]; ok {
+		l.ll.MoveToFront(el)
+		return el.Value.(*lruItem[V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// add inserts or replaces key, then evicts least recently used entries until
+// both limits hold. A value larger than maxBytes on its own is not stored.
+// A replaced value is passed to onEvict with an empty reason.
+func (l *lru[V]) add(key string, value V, size int64) {
+	if el, ok := l.items[key]; ok {
+		l.removeElement(el, "")
+	}
+	if l.maxBytes > 0 && size > l.maxBytes {
+		if l.onEvict != nil {
+			l.onEvict(key, value, EvictCapacity)
+		}
+		return
+	}
+	l.items[key] = l.ll.PushFront(&lruItem[V]{key: key, value: value, size: size})
+	l.bytes += size
+	l.trim()
+}
+
+// remove deletes key, reporting reason to onEvict. Returns false if absent.
+func (l *lru[V]) remove(key, reason string) bool {
+	el, ok := l.items[key]
+	if !ok {
+		return false
+	}
+	l.removeElement(el, reason)
+	return true
+}
+
+// keys returns all keys, most recently used first.
+func (l *lru[V]) keys() []string {
+	out := make([]string, 0, len(l.items))
+	for el := l.ll.Front(); el != nil; el = el.Next() {
+		out = append(out, el.Value.(*lruItem[V]).key)
+	}
+	return out
+}
+
+// each calls fn for every entry, most recently used first, without changing recency.
+func (l *lru[V]) each(fn func(key string, value V)) {
+	for el := l.ll.Front(); el != nil; el = el.Next() {
+		it := el.Value.(*lruItem[V])
+		fn(it.key, it.value)
+	}
+}
+
+func (l *lru[V]) len() int {
+	return len(l.items)
+}
+
+func (l *lru[V]) setLimits(maxEntries int, maxBytes int64) {
+	l.maxEntries = maxEntries
+	l.maxBytes = maxBytes
+	l.trim()
+}
+
+func (l *lru[V]) trim() {
+	for (l.maxEntries > 0 && len(l.items) > l.maxEntries) || (l.maxBytes > 0 && l.bytes > l.maxBytes) {
+		back := l.ll.Back()
+		if back == nil {
+			return
+		}
+		l.removeElement(back, EvictCapacity)
+	}
+}
+
+func (l *lru[V]) removeElement(el *list.Element, reason string) {
+	it := el.Value.(*lruItem[V])
+	l.ll.Remove(el)
+	delete(l.items, it.key)
+	l.bytes -= it.size
+	if l.onEvict != nil {
+		l.onEvict(it.key, it.value, reason)
+	}
+}
+
+// Estimated in-memory sizes. They only need to be proportional to real usage
+// for the byte limit to bound memory; struct and map overhead is approximated.
+const (
+	entryOverheadBytes = 128
+	chunkOverheadBytes = 512
+)
+
+func embeddingBytes(key string, vec []float32) int64 {
+	return int64(entryOverheadBytes + len(key) + 4*len(vec))
+}
+
+func retrievalBytes(key string, r *service.RetrievalResult) int64 {
+	n := int64(entryOverheadBytes + len(key))
+	if r == nil {
+		return n
+	}
+	n += int64(4 * len(r.QueryEmbedding))
+	for _, rc := range r.Chunks {
+		n += int64(chunkOverheadBytes + len(rc.Chunk.Content) + 4*len(rc.Chunk.Embedding) +
+			len(rc.Document.OriginalName) + len(rc.Document.Filename) + len(rc.Document.Metadata))
+		if rc.Document.ExtractedText != nil {
+			n += int64(len(*rc.Document.ExtractedText))
+		}
+	}
+	for _, tm := range r.ThreadMessages {
+		n += int64(chunkOverheadBytes + len(tm.Content))
+	}
+	return n
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+type fakeObserver struct {
+	mu        sync.Mutex
+	hits      map[string]int
+	misses    map[string]int
+	evictions map[string]int // "layer/reason" → count
+}
+
+func newFakeObserver() *fakeObserver {
+	return &fakeObserver{hits: map[string]int{}, misses: map[string]int{}, evictions: map[string]int{}}
+}
+
+func (o *fakeObserver) ObserveCacheLookup(layer string, hit bool, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if hit {
+		o.hits[layer]++
+	} else {
+		o.misses[layer]++
+	}
+}
+
+func (o *fakeObserver) ObserveCacheEviction(layer, reason string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.evictions[layer+"/"+reason]++
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	var evicted []string
+	l := newLRU(3, 0, func(key string, _ int, reason string) {
+		if reason == EvictCapacity {
+			evicted = append(evicted, key)
+		}
+	})
+	l.add("a", 1, 1)
+	l.add("b", 2, 1)
+	l.add("c", 3, 1)
+	l.get("a") // a is now most recent; b is least
+	l.add("d", 4, 1)
+
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Fatalf("evicted = %v, want [b]", evicted)
+	}
+	if _, ok := l.get("a"); !ok {
+		t.Error("recently used a should survive")
+	}
+}
+
+func TestLRU_ByteLimit(t *testing.T) {
+	l := newLRU[int](0, 100, nil)
+	l.add("a", 1, 40)
+	l.add("b", 2, 40)
+	l.add("c", 3, 40)
+
+	if l.len() != 2 || l.bytes != 80 {
+		t.Errorf("len = %d, bytes = %d; want 2, 80", l.len(), l.bytes)
+	}
+	if _, ok := l.get("a"); ok {
+		t.Error("oldest entry should be evicted to fit the byte limit")
+	}
+
+	l.add("huge", 4, 101)
+	if _, ok := l.get("huge"); ok {
+		t.Error("an entry larger than the byte limit must not be stored")
+	}
+	if l.len() != 2 {
+		t.Errorf("oversized add must not evict others; len = %d", l.len())
+	}
+}
+
+func TestQueryCache_CapacityEvictionCleansDocIndex(t *testing.T) {
+	c := New(time.Hour)
+	defer c.Stop()
+	obs := newFakeObserver()
+	c.SetObserver(obs)
+	c.SetLimits(2, 0)
+
+	for i := 0; i < 3; i++ {
+		c.Set("u1", fmt.Sprintf("q%d", i), false, &service.RetrievalResult{
+			Chunks: []service.RankedChunk{{Document: model.Document{ID: fmt.Sprintf("doc-%d", i)}}},
+		})
+	}
+
+	if c.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", c.Len())
+	}
+	c.mu.Lock()
+	_, indexed := c.docIndex["doc-0"]
+	c.mu.Unlock()
+	if indexed {
+		t.Error("evicted entry's document must be dropped from the reverse index")
+	}
+
+	c.Get("u1", "q0", false)
+	c.Get("u1", "q2", false)
+	st := c.Stats()
+	if st.Hits != 1 || st.Misses != 1 || st.Evictions != 1 || st.MaxEntries != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+	if obs.hits["query"] != 1 || obs.misses["query"] != 1 || obs.evictions["query/"+EvictCapacity] != 1 {
+		t.Errorf("observer hits=%v misses=%v evictions=%v", obs.hits, obs.misses, obs.evictions)
+	}
+}
+
+func TestQueryCache_OverwriteIsNotEviction(t *testing.T) {
+	c := New(time.Hour)
+	defer c.Stop()
+
+	c.Set("u1", "q", false, makeResult("a.pdf"))
+	c.Set("u1", "q", false, makeResult("b.pdf"))
+	if st := c.Stats(); st.Entries != 1 || st.Evictions != 0 {
+		t.Errorf("stats = %+v, want 1 entry and no evictions", st)
+	}
+}
+
+func TestEmbeddingCache_ByteLimitAndStats(t *testing.T) {
+	c := NewEmbeddingCache(time.Hour)
+	defer c.Stop()
+	obs := newFakeObserver()
+	c.SetObserver(obs)
+
+	vec := make([]float32, 768)
+	size := embeddingBytes(EmbeddingQueryHash("q0"), vec)
+	c.SetLimits(0, 2*size)
+
+	for i := 0; i < 3; i++ {
+		c.Set(EmbeddingQueryHash(fmt.Sprintf("q%d", i)), vec)
+	}
+	st := c.Stats()
+	if st.Entries != 2 || st.Bytes > st.MaxBytes || st.Evictions != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+	if _, ok := c.Get(EmbeddingQueryHash("q0")); ok {
+		t.Error("oldest embedding should be evicted")
+	}
+	if obs.evictions["embedding/"+EvictCapacity] != 1 || obs.misses["embedding"] != 1 {
+		t.Errorf("observer misses=%v evictions=%v", obs.misses, obs.evictions)
+	}
+}
+
+func TestSemanticCache_Stats(t *testing.T) {
+	c := NewSemanticCache(time.Hour, 0.9)
+	defer c.Stop()
+
+	c.Store(testScope("u1"), "q", []float32{1, 0}, semanticAnswer("d1"), []string{"d1"})
+	c.Lookup(testScope("u1"), []float32{1, 0})
+	c.Lookup(testScope("u1"), []float32{0, 1})
+	c.InvalidateDocument("d1")
+
+	st := c.Stats()
+	if st.Layer != "semantic" || st.Hits != 1 || st.Misses != 1 || st.Evictions != 1 || st.Entries != 0 {
+		t.Errorf("stats = %+v", st)
+	}
+}
@@ -12,48 +12,77 @@ import (
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
 
+const (
+	// DefaultQueryCacheMaxEntries bounds the retrieval cache by entry count.
+	DefaultQueryCacheMaxEntries = 10000
+	// DefaultQueryCacheMaxBytes bounds the retrieval cache by estimated size (256 MiB).
+	DefaultQueryCacheMaxBytes = 256 << 20
+)
+
 // QueryCache caches RetrievalResult by (userID, query, privilegeMode).
-// Thread-safe via sync.RWMutex. Entries auto-expire after TTL.
+// Thread-safe via sync.Mutex. Entries auto-expire after TTL, and the least
+// recently used are evicted once the entry or byte limit is exceeded.
+// A reverse index maps each document ID to the entries whose chunks came
+// from it, so a document change drops exactly the affected results.
 type QueryCache struct {
-	mu      sync.RWMutex
-	entries map[string]*cacheEntry
-	ttl     time.Duration
-	stopCh  chan struct{}
+	mu       sync.Mutex
+	entries  *lru[*cacheEntry]
+	docIndex map[string]map[string]struct{} // document ID → entry keys
+	ttl      time.Duration
+	meter    meter
+	stopCh   chan struct{}
 }
 
 type cacheEntry struct {
-	result    *service.RetrievalResult
-	createdAt time.Time
-	expiresAt time.Time
+	result      *service.RetrievalResult
+	documentIDs []string
+	createdAt   time.Time
+	expiresAt   time.Time
 }
 
-// New creates a QueryCache with the given TTL and starts background cleanup.
+// New creates a QueryCache with the given TTL and default size limits, and
+// starts background cleanup.
 func New(ttl time.Duration) *QueryCache {
 	c := &QueryCache{
-		entries: make(map[string]*cacheEntry),
-		ttl:     ttl,
-		stopCh:  make(chan struct{}),
+		docIndex: make(map[string]map[string]struct{}),
+		ttl:      ttl,
+		meter:    meter{layer: "query"},
+		stopCh:   make(chan struct{}),
 	}
+	c.entries = newLRU(DefaultQueryCacheMaxEntries, DefaultQueryCacheMaxBytes, c.onEvict)
 	go c.cleanup()
 	return c
 }
 
+// SetLimits changes the entry and byte bounds, evicting immediately if the
+// cache is over them. Zero disables a bound.
+func (c *QueryCache) SetLimits(maxEntries int, maxBytes int64) {
+	c.mu.Lock()
+	c.entries.setLimits(maxEntries, maxBytes)
+	c.mu.Unlock()
+}
+
+// SetObserver reports hits, misses, evictions and lookup latency to o.
+func (c *QueryCache) SetObserver(o Observer) {
+	c.meter.setObserver(o)
+}
+
 // Get returns a cached RetrievalResult if present and not expired.
 func (c *QueryCache) Get(userID, query string, privilegeMode bool) (*service.RetrievalResult, bool) {
+	start := time.Now()
 	key := cacheKey(userID, query, privilegeMode)
-	c.mu.RLock()
-	entry, ok := c.entries[key]
-	c.mu.RUnlock()
+	c.mu.Lock()
+	entry, ok := c.entries.get(key)
+	if ok && start.After(entry.expiresAt) {
+		c.entries.remove(key, EvictExpired)
+		ok = false
+	}
+	c.mu.Unlock()
+	c.meter.lookup(ok, start)
 
 	if !ok {
 		return nil, false
 	}
-	if time.Now().After(entry.expiresAt) {
-		c.mu.Lock()
-		delete(c.entries, key)
-		c.mu.Unlock()
-		return nil, false
-	}
 
 	slog.Info("[CACHE] hit",
 		"user_id", userID,
@@ -67,19 +96,31 @@ func (c *QueryCache) Get(userID, query string, privilegeMode bool) (*service.Ret
 func (c *QueryCache) Set(userID, query string, privilegeMode bool, result *service.RetrievalResult) {
 	key := cacheKey(userID, query, privilegeMode)
 	now := time.Now()
+	docIDs := RetrievalDocumentIDs(result)
 	c.mu.Lock()
-	c.entries[key] = &cacheEntry{
-		result:    result,
-		createdAt: now,
-		expiresAt: now.Add(c.ttl),
+	c.entries.remove(key, "") // drop the old entry's index references first
+	for _, id := range docIDs {
+		keys, ok := c.docIndex[id]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.docIndex[id] = keys
+		}
+		keys[key] = struct{}{}
 	}
+	c.entries.add(key, &cacheEntry{
+		result:      result,
+		documentIDs: docIDs,
+		createdAt:   now,
+		expiresAt:   now.Add(c.ttl),
+	}, retrievalBytes(key, result))
+	total := c.entries.len()
 	c.mu.Unlock()
 
 	slog.Info("[CACHE] set",
 		"user_id", userID,
 		"query_hash", key[strings.LastIndex(key, ":")+1:],
 		"ttl_s", int(c.ttl.Seconds()),
-		"total_entries", c.Len(),
+		"total_entries", total,
 	)
 }
 
@@ -89,9 +130,9 @@ func (c *QueryCache) InvalidateUser(userID string) {
 	prefix := "qc:" + userID + ":"
 	c.mu.Lock()
 	count := 0
-	for key := range c.entries {
+	for _, key := range c.entries.keys() {
 		if strings.HasPrefix(key, prefix) {
-			delete(c.entries, key)
+			c.entries.remove(key, EvictInvalidated)
 			count++
 		}
 	}
@@ -105,11 +146,60 @@ func (c *QueryCache) InvalidateUser(userID string) {
 	}
 }
 
+// InvalidateDocument removes every cached result containing chunks of docID.
+// Returns the number of entries removed.
+func (c *QueryCache) InvalidateDocument(docID string) int {
+	c.mu.Lock()
+	count := 0
+	for key := range c.docIndex[docID] {
+		if c.entries.remove(key, EvictInvalidated) {
+			count++
+		}
+	}
+	c.mu.Unlock()
+
+	if count > 0 {
+		slog.Info("[CACHE] invalidated document",
+			"document_id", docID,
+			"entries_removed", count,
+		)
+	}
+	return count
+}
+
+// onEvict drops an entry's reverse-index references and counts the eviction.
+// An empty reason marks an overwrite, which is not an eviction. c.mu is held.
+func (c *QueryCache) onEvict(key string, entry *cacheEntry, reason string) {
+	for _, id := range entry.documentIDs {
+		if keys, ok := c.docIndex[id]; ok {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(c.docIndex, id)
+			}
+		}
+	}
+	if reason != "" {
+		c.meter.evict(reason)
+	}
+}
+
 // Len returns the number of entries in the cache.
 func (c *QueryCache) Len() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return len(c.entries)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.len()
+}
+
+// Stats returns a snapshot of size and hit counters.
+func (c *QueryCache) Stats() Stats {
+	st := c.meter.stats()
+	c.mu.Lock()
+	st.Entries = c.entries.len()
+	st.Bytes = c.entries.bytes
+	st.MaxEntries = c.entries.maxEntries
+	st.MaxBytes = c.entries.maxBytes
+	c.mu.Unlock()
+	return st
 }
 
 // Stop halts the background cleanup goroutine.
@@ -126,13 +216,17 @@ func (c *QueryCache) cleanup() {
 		case <-ticker.C:
 			now := time.Now()
 			c.mu.Lock()
-			before := len(c.entries)
-			for key, entry := range c.entries {
+			before := c.entries.len()
+			var expired []string
+			c.entries.each(func(key string, entry *cacheEntry) {
 				if now.After(entry.expiresAt) {
-					delete(c.entries, key)
+					expired = append(expired, key)
 				}
+			})
+			for _, key := range expired {
+				c.entries.remove(key, EvictExpired)
 			}
-			after := len(c.entries)
+			after := c.entries.len()
 			c.mu.Unlock()
 			if before != after {
 				slog.Info("[CACHE] cleanup", "removed", before-after, "remaining", after)
@@ -143,6 +237,42 @@ func (c *QueryCache) cleanup() {
 	}
 }
 
+// RetrievalDocumentIDs returns the distinct document IDs behind a retrieval
+// result's chunks, in rank order. Web pseudo-chunks have no document ID.
+func RetrievalDocumentIDs(result *service.RetrievalResult) []string {
+	if result == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(result.Chunks))
+	for _, rc := range result.Chunks {
+		ids = append(ids, rc.Document.ID)
+	}
+	return uniqueStrings(ids)
+}
+
+// VaultScopedQuery returns the query string to key retrieval and response
+// caches with, so results retrieved from one vault are never served for
+// another or for an unscoped query. An empty vaultID returns query unchanged.
+func VaultScopedQuery(vaultID, query string) string {
+	if vaultID == "" {
+		return query
+	}
+	return "vault:" + vaultID + "\n" + query
+}
+
+// CachedAnswer is a generated answer held by the response caches (Redis and
+// semantic), with what the chat handler needs to decide whether a later
+// caller may be served it.
+type CachedAnswer struct {
+	Result *service.GenerationResult
+	// VaultIDs are the vaults of the documents the answer was built from.
+	VaultIDs []string
+	// Redaction is the service.PIIRedactionPlan fingerprint the answer was
+	// redacted under, and Redacted what that redaction removed, by info type.
+	Redaction string
+	Redacted  map[string]int
+}
+
 // cacheKey builds a deterministic key: "qc:{userID}:{privilegeMode}:{sha256(query)}"
 func cacheKey(userID, query string, privilegeMode bool) string {
 	h := sha256.Sum256([]byte(query))
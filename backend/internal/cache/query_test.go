@@ -76,6 +76,23 @@ func TestQueryCache_UserIsolation(t *testing.T) {
 	}
 }
 
+func TestQueryCache_VaultIsolation(t *testing.T) {
+	c := New(1 * time.Hour)
+	defer c.Stop()
+
+	c.Set("user-1", VaultScopedQuery("vault-a", "query"), false, makeResult("a.pdf"))
+
+	if _, ok := c.Get("user-1", VaultScopedQuery("vault-b", "query"), false); ok {
+		t.Fatal("vault-b query should not see vault-a's cache")
+	}
+	if _, ok := c.Get("user-1", VaultScopedQuery("", "query"), false); ok {
+		t.Fatal("unscoped query should not see vault-a's cache")
+	}
+	if VaultScopedQuery("", "query") != "query" {
+		t.Fatal("empty vault should leave the query unchanged")
+	}
+}
+
 func TestQueryCache_Expiry(t *testing.T) {
 	c := New(50 * time.Millisecond)
 	defer c.Stop()
@@ -126,6 +143,34 @@ func TestQueryCache_InvalidateUser(t *testing.T) {
 	}
 }
 
+func TestQueryCache_InvalidateDocument(t *testing.T) {
+	c := New(1 * time.Hour)
+	defer c.Stop()
+
+	other := makeResult("b.pdf")
+	other.Chunks[0].Document.ID = "doc-2"
+
+	c.Set("user-1", "query-a", false, makeResult("a.pdf")) // doc-1
+	c.Set("user-1", "query-b", false, other)               // doc-2
+	c.Set("user-2", "query-a", true, makeResult("a.pdf"))  // doc-1
+
+	if n := c.InvalidateDocument("doc-1"); n != 2 {
+		t.Fatalf("removed = %d, want 2", n)
+	}
+	if _, ok := c.Get("user-1", "query-b", false); !ok {
+		t.Error("entry built only from doc-2 should survive")
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len = %d, want 1", c.Len())
+	}
+
+	// Overwriting an entry drops its old index references.
+	c.Set("user-1", "query-b", false, makeResult("a.pdf"))
+	if n := c.InvalidateDocument("doc-2"); n != 0 {
+		t.Errorf("stale index entry for doc-2 removed %d entries", n)
+	}
+}
+
 func TestQueryCache_Len(t *testing.T) {
 	c := New(1 * time.Hour)
 	defer c.Stop()
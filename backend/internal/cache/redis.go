@@ -24,14 +24,21 @@ type RedisCache struct {
 	EmbedTTL     time.Duration // query embedding vectors
 	RetrievalTTL time.Duration // retrieval results (chunks)
 	ResponseTTL  time.Duration // full generation responses
+
+	embedMeter     meter
+	retrievalMeter meter
+	responseMeter  meter
 }
 
 // cachedResponse is the JSON-serializable form stored in Redis for full responses.
 type cachedResponse struct {
-	Answer     string             `json:"answer"`
+	Answer     string                `json:"answer"`
 	Citations  []service.CitationRef `json:"citations"`
-	Confidence float64            `json:"confidence"`
-	ModelUsed  string             `json:"model_used"`
+	Confidence float64               `json:"confidence"`
+	ModelUsed  string                `json:"model_used"`
+	VaultIDs   []string              `json:"vault_ids,omitempty"`
+	Redaction  string                `json:"redaction,omitempty"`
+	Redacted   map[string]int        `json:"redacted,omitempty"`
 }
 
 // NewRedisCache connects to Redis and returns an L2 cache.
@@ -66,7 +73,30 @@ func NewRedisCache(addr string) *RedisCache {
 		EmbedTTL:     10 * time.Minute,
 		RetrievalTTL: 5 * time.Minute,
 		ResponseTTL:  2 * time.Minute,
+
+		embedMeter:     meter{layer: "redis_embedding"},
+		retrievalMeter: meter{layer: "redis_retrieval"},
+		responseMeter:  meter{layer: "redis_response"},
+	}
+}
+
+// SetObserver reports hits, misses and lookup latency for each Redis tier to o.
+func (rc *RedisCache) SetObserver(o Observer) {
+	if rc == nil {
+		return
+	}
+	rc.embedMeter.setObserver(o)
+	rc.retrievalMeter.setObserver(o)
+	rc.responseMeter.setObserver(o)
+}
+
+// Stats returns hit counters for each Redis tier. Size and eviction are
+// governed by Redis itself (TTL and maxmemory policy) and are not reported.
+func (rc *RedisCache) Stats() []Stats {
+	if rc == nil {
+		return nil
 	}
+	return []Stats{rc.embedMeter.stats(), rc.retrievalMeter.stats(), rc.responseMeter.stats()}
 }
 
 // Close shuts down the Redis connection.
@@ -84,15 +114,15 @@ func embedRedisKey(queryHash string) string {
 }
 
 // GetEmbedding returns a cached embedding vector from Redis.
-func (rc *RedisCache) GetEmbedding(ctx context.Context, queryHash string) ([]float32, bool) {
+func (rc *RedisCache) GetEmbedding(ctx context.Context, queryHash string) (vec []float32, ok bool) {
 	if rc == nil {
 		return nil, false
 	}
+	defer func(start time.Time) { rc.embedMeter.lookup(ok, start) }(time.Now())
 	data, err := rc.client.Get(ctx, embedRedisKey(queryHash)).Bytes()
 	if err != nil {
 		return nil, false
 	}
-	var vec []float32
 	if err := json.Unmarshal(data, &vec); err != nil {
 		return nil, false
 	}
@@ -120,10 +150,11 @@ func retrievalRedisKey(userID, query string, privilegeMode bool) string {
 }
 
 // GetRetrieval returns cached retrieval results from Redis.
-func (rc *RedisCache) GetRetrieval(ctx context.Context, userID, query string, privilegeMode bool) (*service.RetrievalResult, bool) {
+func (rc *RedisCache) GetRetrieval(ctx context.Context, userID, query string, privilegeMode bool) (_ *service.RetrievalResult, ok bool) {
 	if rc == nil {
 		return nil, false
 	}
+	defer func(start time.Time) { rc.retrievalMeter.lookup(ok, start) }(time.Now())
 	data, err := rc.client.Get(ctx, retrievalRedisKey(userID, query, privilegeMode)).Bytes()
 	if err != nil {
 		return nil, false
@@ -145,7 +176,9 @@ func (rc *RedisCache) SetRetrieval(ctx context.Context, userID, query string, pr
 	if err != nil {
 		return
 	}
-	rc.client.Set(ctx, retrievalRedisKey(userID, query, privilegeMode), data, rc.RetrievalTTL)
+	key := retrievalRedisKey(userID, query, privilegeMode)
+	rc.client.Set(ctx, key, data, rc.RetrievalTTL)
+	rc.indexDocuments(ctx, key, RetrievalDocumentIDs(result))
 }
 
 // --- Full response cache (L2) ---
@@ -155,11 +188,12 @@ func responseRedisKey(userID, query string, privilegeMode bool) string {
 	return fmt.Sprintf("rc:resp:%s:%v:%x", userID, privilegeMode, h[:8])
 }
 
-// GetResponse returns a cached generation result from Redis.
-func (rc *RedisCache) GetResponse(ctx context.Context, userID, query string, privilegeMode bool) (*service.GenerationResult, bool) {
+// GetResponse returns a cached answer from Redis.
+func (rc *RedisCache) GetResponse(ctx context.Context, userID, query string, privilegeMode bool) (_ *CachedAnswer, ok bool) {
 	if rc == nil {
 		return nil, false
 	}
+	defer func(start time.Time) { rc.responseMeter.lookup(ok, start) }(time.Now())
 	data, err := rc.client.Get(ctx, responseRedisKey(userID, query, privilegeMode)).Bytes()
 	if err != nil {
 		return nil, false
@@ -169,30 +203,130 @@ func (rc *RedisCache) GetResponse(ctx context.Context, userID, query string, pri
 		return nil, false
 	}
 	slog.Info("[REDIS] response hit", "user_id", userID)
-	return &service.GenerationResult{
-		Answer:     cr.Answer,
-		Citations:  cr.Citations,
-		Confidence: cr.Confidence,
-		ModelUsed:  cr.ModelUsed,
+	return &CachedAnswer{
+		Result: &service.GenerationResult{
+			Answer:     cr.Answer,
+			Citations:  cr.Citations,
+			Confidence: cr.Confidence,
+			ModelUsed:  cr.ModelUsed,
+		},
+		VaultIDs:  cr.VaultIDs,
+		Redaction: cr.Redaction,
+		Redacted:  cr.Redacted,
 	}, true
 }
 
-// SetResponse stores a generation result in Redis.
-func (rc *RedisCache) SetResponse(ctx context.Context, userID, query string, privilegeMode bool, result *service.GenerationResult) {
+// SetResponse stores an answer in Redis. documentIDs are the documents the
+// answer was built from; a change to any of them drops it.
+func (rc *RedisCache) SetResponse(ctx context.Context, userID, query string, privilegeMode bool, answer *CachedAnswer, documentIDs []string) {
 	if rc == nil {
 		return
 	}
 	cr := cachedResponse{
-		Answer:     result.Answer,
-		Citations:  result.Citations,
-		Confidence: result.Confidence,
-		ModelUsed:  result.ModelUsed,
+		Answer:     answer.Result.Answer,
+		Citations:  answer.Result.Citations,
+		Confidence: answer.Result.Confidence,
+		ModelUsed:  answer.Result.ModelUsed,
+		VaultIDs:   answer.VaultIDs,
+		Redaction:  answer.Redaction,
+		Redacted:   answer.Redacted,
 	}
 	data, err := json.Marshal(cr)
 	if err != nil {
 		return
 	}
-	rc.client.Set(ctx, responseRedisKey(userID, query, privilegeMode), data, rc.ResponseTTL)
+	key := responseRedisKey(userID, query, privilegeMode)
+	rc.client.Set(ctx, key, data, rc.ResponseTTL)
+	rc.indexDocuments(ctx, key, uniqueStrings(documentIDs))
+}
+
+// --- Document reverse index ---
+
+// docIndexRedisKey is a set of the retrieval and response keys built from a document.
+func docIndexRedisKey(docID string) string {
+	return "rc:doc:" + docID
+}
+
+// indexDocuments adds key to the reverse-index set of each document. Sets
+// outlive the longest entry TTL; members whose entry already expired are
+// harmless and removed on the next invalidation.
+func (rc *RedisCache) indexDocuments(ctx context.Context, key string, docIDs []string) {
+	if len(docIDs) == 0 {
+		return
+	}
+	ttl := rc.RetrievalTTL
+	if rc.ResponseTTL > ttl {
+		ttl = rc.ResponseTTL
+	}
+	pipe := rc.client.Pipeline()
+	for _, id := range docIDs {
+		pipe.SAdd(ctx, docIndexRedisKey(id), key)
+		pipe.Expire(ctx, docIndexRedisKey(id), ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.Warn("[REDIS] doc index update failed", "error", err)
+	}
+}
+
+// InvalidateDocuments removes every retrieval and response entry built from
+// any of docIDs. Returns the number of keys deleted.
+func (rc *RedisCache) InvalidateDocuments(ctx context.Context, docIDs ...string) int {
+	if rc == nil {
+		return 0
+	}
+	count := 0
+	for _, id := range docIDs {
+		idx := docIndexRedisKey(id)
+		keys, err := rc.client.SMembers(ctx, idx).Result()
+		if err != nil {
+			slog.Warn("[REDIS] doc index read failed", "document_id", id, "error", err)
+			continue
+		}
+		n, err := rc.client.Del(ctx, append(keys, idx)...).Result()
+		if err != nil {
+			slog.Warn("[REDIS] doc invalidation failed", "document_id", id, "error", err)
+			continue
+		}
+		if n > 0 && len(keys) > 0 {
+			count += int(n) - 1
+		}
+	}
+	if count > 0 {
+		slog.Info("[REDIS] invalidated documents", "documents", len(docIDs), "keys_removed", count)
+	}
+	return count
+}
+
+// --- Cross-instance invalidation ---
+
+// PublishInvalidation broadcasts msg to every instance subscribed via Subscribe.
+func (rc *RedisCache) PublishInvalidation(ctx context.Context, msg service.CacheInvalidation) error {
+	if rc == nil {
+		return nil
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("cache.PublishInvalidation: %w", err)
+	}
+	if err := rc.client.Publish(ctx, service.CacheInvalidationChannel, data).Err(); err != nil {
+		return fmt.Errorf("cache.PublishInvalidation: %w", err)
+	}
+	return nil
+}
+
+// Subscribe returns a subscription to the invalidation channel.
+func (rc *RedisCache) Subscribe(ctx context.Context) *redis.PubSub {
+	return rc.client.Subscribe(ctx, service.CacheInvalidationChannel)
+}
+
+// claimInvalidation reports whether this instance is the first to handle
+// invalidation id, so shared Redis entries are cleared once, not per instance.
+func (rc *RedisCache) claimInvalidation(ctx context.Context, id string) bool {
+	ok, err := rc.client.SetNX(ctx, "rc:inv:"+id, 1, time.Minute).Result()
+	if err != nil {
+		return true // clearing twice is harmless; skipping is not
+	}
+	return ok
 }
 
 // InvalidateUser removes all cached entries for a user across all tiers.
@@ -0,0 +1,328 @@
+package cache
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSemanticThreshold is the minimum cosine similarity for a semantic hit.
+	defaultSemanticThreshold = 0.95
+	// defaultSemanticMaxPerScope bounds the entries compared per lookup.
+	defaultSemanticMaxPerScope = 50
+)
+
+// SemanticScope identifies which cached answers a query may reuse. Answers are
+// only shared between queries from the same user, privilege mode, request
+// filters and embedding space.
+type SemanticScope struct {
+	UserID        string
+	PrivilegeMode bool
+	Filters       string // canonical encoding of request options that change the answer
+	Space         string // embedding space key; vectors from different spaces are not comparable
+}
+
+func (s SemanticScope) key() string {
+	return fmt.Sprintf("sc:%s:%v:%s:%s", s.UserID, s.PrivilegeMode, s.Space, s.Filters)
+}
+
+// SemanticMatch is a cached answer returned for a near-duplicate query.
+type SemanticMatch struct {
+	Query      string // the previously answered query that matched
+	Similarity float64
+	Answer     *CachedAnswer
+}
+
+type semanticEntry struct {
+	scope       string
+	query       string
+	vector      []float32
+	answer      *CachedAnswer
+	documentIDs []string
+	createdAt   time.Time
+	expiresAt   time.Time
+}
+
+// SemanticCache matches query embeddings against recently answered queries.
+// Unlike QueryCache and RedisCache, which key on an exact hash of the query
+// string, it returns an answer when a new query is within Threshold cosine
+// similarity of a cached one. Entries record the documents behind their
+// citations and are dropped when any of them changes.
+type SemanticCache struct {
+	mu          sync.RWMutex
+	scopes      map[string][]*semanticEntry
+	docIndex    map[string]map[*semanticEntry]struct{} // document ID → entries built from it
+	threshold   float64
+	ttl         time.Duration
+	maxPerScope int
+	meter       meter
+	stopCh      chan struct{}
+}
+
+// NewSemanticCache creates a SemanticCache and starts background cleanup.
+// A threshold outside (0, 1] falls back to the default (0.95).
+func NewSemanticCache(ttl time.Duration, threshold float64) *SemanticCache {
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultSemanticThreshold
+	}
+	c := &SemanticCache{
+		scopes:      make(map[string][]*semanticEntry),
+		docIndex:    make(map[string]map[*semanticEntry]struct{}),
+		threshold:   threshold,
+		ttl:         ttl,
+		maxPerScope: defaultSemanticMaxPerScope,
+		meter:       meter{layer: "semantic"},
+		stopCh:      make(chan struct{}),
+	}
+	go c.cleanup()
+	return c
+}
+
+// Threshold returns the minimum similarity for a hit.
+func (c *SemanticCache) Threshold() float64 {
+	return c.threshold
+}
+
+// Lookup returns the most similar unexpired answer in scope, if it meets the threshold.
+func (c *SemanticCache) Lookup(scope SemanticScope, vec []float32) (*SemanticMatch, bool) {
+	if len(vec) == 0 {
+		return nil, false
+	}
+	var best *semanticEntry
+	now := time.Now()
+	defer func() { c.meter.lookup(best != nil, now) }()
+	c.mu.RLock()
+	bestSim := c.threshold
+	for _, e := range c.scopes[scope.key()] {
+		if now.After(e.expiresAt) {
+			continue
+		}
+		if sim := cosineSimilarity(vec, e.vector); sim >= bestSim {
+			best, bestSim = e, sim
+		}
+	}
+	c.mu.RUnlock()
+
+	if best == nil {
+		return nil, false
+	}
+	slog.Info("[CACHE] semantic hit",
+		"user_id", scope.UserID,
+		"similarity", fmt.Sprintf("%.4f", bestSim),
+		"age_ms", time.Since(best.createdAt).Milliseconds(),
+	)
+	return &SemanticMatch{Query: best.query, Similarity: bestSim, Answer: best.answer}, true
+}
+
+// Store caches an answer for query. documentIDs are the documents the answer
+// was built from; a change to any of them invalidates the entry.
+func (c *SemanticCache) Store(scope SemanticScope, query string, vec []float32, answer *CachedAnswer, documentIDs []string) {
+	if len(vec) == 0 || answer == nil || answer.Result == nil {
+		return
+	}
+	now := time.Now()
+	key := scope.key()
+	entry := &semanticEntry{
+		scope:       key,
+		query:       query,
+		vector:      vec,
+		answer:      answer,
+		documentIDs: uniqueStrings(documentIDs),
+		createdAt:   now,
+		expiresAt:   now.Add(c.ttl),
+	}
+
+	c.mu.Lock()
+	for _, e := range append([]*semanticEntry(nil), c.scopes[key]...) {
+		switch {
+		case e.query == query:
+			c.removeLocked(e, "")
+		case !now.Before(e.expiresAt):
+			c.removeLocked(e, EvictExpired)
+		}
+	}
+	if entries := c.scopes[key]; len(entries) >= c.maxPerScope {
+		for _, e := range entries[:len(entries)-c.maxPerScope+1] {
+			c.removeLocked(e, EvictCapacity)
+		}
+	}
+	c.scopes[key] = append(c.scopes[key], entry)
+	for _, id := range entry.documentIDs {
+		entries, ok := c.docIndex[id]
+		if !ok {
+			entries = make(map[*semanticEntry]struct{})
+			c.docIndex[id] = entries
+		}
+		entries[entry] = struct{}{}
+	}
+	c.mu.Unlock()
+}
+
+// InvalidateDocument removes every entry whose answer was built from docID.
+// Returns the number of entries removed.
+func (c *SemanticCache) InvalidateDocument(docID string) int {
+	c.mu.Lock()
+	entries := c.docIndex[docID]
+	count := len(entries)
+	for e := range entries {
+		c.removeLocked(e, EvictInvalidated)
+	}
+	c.mu.Unlock()
+
+	if count > 0 {
+		slog.Info("[CACHE] semantic invalidated document",
+			"document_id", docID,
+			"entries_removed", count,
+		)
+	}
+	return count
+}
+
+// InvalidateUser removes all cached answers for a user. Call this when the
+// user's corpus grows, since a new document can change any answer.
+func (c *SemanticCache) InvalidateUser(userID string) {
+	prefix := "sc:" + userID + ":"
+	count := 0
+	c.mu.Lock()
+	for key, entries := range c.scopes {
+		if strings.HasPrefix(key, prefix) {
+			count += len(entries)
+			for _, e := range append([]*semanticEntry(nil), entries...) {
+				c.removeLocked(e, EvictInvalidated)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	if count > 0 {
+		slog.Info("[CACHE] semantic invalidated user",
+			"user_id", userID,
+			"entries_removed", count,
+		)
+	}
+}
+
+// Len returns the number of cached answers across all scopes.
+func (c *SemanticCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	n := 0
+	for _, entries := range c.scopes {
+		n += len(entries)
+	}
+	return n
+}
+
+// SetObserver reports hits, misses, evictions and lookup latency to o.
+func (c *SemanticCache) SetObserver(o Observer) {
+	c.meter.setObserver(o)
+}
+
+// Stats returns a snapshot of size and hit counters. Size is bounded per
+// scope (50 answers) rather than globally.
+func (c *SemanticCache) Stats() Stats {
+	st := c.meter.stats()
+	c.mu.RLock()
+	for _, entries := range c.scopes {
+		for _, e := range entries {
+			st.Entries++
+			st.Bytes += int64(entryOverheadBytes + 4*len(e.vector) + len(e.query) + len(e.answer.Result.Answer))
+		}
+	}
+	c.mu.RUnlock()
+	return st
+}
+
+// Stop halts the background cleanup goroutine.
+func (c *SemanticCache) Stop() {
+	close(c.stopCh)
+}
+
+// cleanup removes expired entries every 5 minutes.
+func (c *SemanticCache) cleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			c.mu.Lock()
+			var expired []*semanticEntry
+			for _, entries := range c.scopes {
+				for _, e := range entries {
+					if !now.Before(e.expiresAt) {
+						expired = append(expired, e)
+					}
+				}
+			}
+			for _, e := range expired {
+				c.removeLocked(e, EvictExpired)
+			}
+			c.mu.Unlock()
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// removeLocked deletes an entry from its scope and the reverse index, counting
+// it as an eviction unless reason is empty (an overwrite). c.mu must be held.
+func (c *SemanticCache) removeLocked(entry *semanticEntry, reason string) {
+	if reason != "" {
+		c.meter.evict(reason)
+	}
+	entries := c.scopes[entry.scope]
+	for i, e := range entries {
+		if e == entry {
+			entries = append(entries[:i:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(entries) == 0 {
+		delete(c.scopes, entry.scope)
+	} else {
+		c.scopes[entry.scope] = entries
+	}
+	for _, id := range entry.documentIDs {
+		if set, ok := c.docIndex[id]; ok {
+			delete(set, entry)
+			if len(set) == 0 {
+				delete(c.docIndex, id)
+			}
+		}
+	}
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// their dimensions differ or either is a zero vector.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func uniqueStrings(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func semanticAnswer(docID string) *CachedAnswer {
+	return &CachedAnswer{Result: &service.GenerationResult{
+		Answer:     "The notice period is 30 days [1].",
+		Citations:  []service.CitationRef{{ChunkID: "c1", DocumentID: docID, Index: 1}},
+		Confidence: 0.9,
+	}}
+}
+
+func testScope(user string) SemanticScope {
+	return SemanticScope{UserID: user, Space: "text-embedding-004@1"}
+}
+
+func TestSemanticCache_NearDuplicateHits(t *testing.T) {
+	c := NewSemanticCache(time.Hour, 0.9)
+	defer c.Stop()
+
+	c.Store(testScope("u1"), "what's the termination notice?", []float32{1, 0, 0}, semanticAnswer("d1"), []string{"d1"})
+
+	match, ok := c.Lookup(testScope("u1"), []float32{0.95, 0.2, 0})
+	if !ok {
+		t.Fatal("expected semantic hit for near-duplicate vector")
+	}
+	if match.Query != "what's the termination notice?" {
+		t.Errorf("matched query = %q", match.Query)
+	}
+	if match.Similarity < 0.9 || match.Similarity > 1 {
+		t.Errorf("similarity = %f", match.Similarity)
+	}
+
+	if _, ok := c.Lookup(testScope("u1"), []float32{0, 1, 0}); ok {
+		t.Error("expected miss for dissimilar vector")
+	}
+}
+
+func TestSemanticCache_ScopeIsolation(t *testing.T) {
+	c := NewSemanticCache(time.Hour, 0.9)
+	defer c.Stop()
+
+	vec := []float32{1, 0, 0}
+	c.Store(testScope("u1"), "q", vec, semanticAnswer("d1"), []string{"d1"})
+
+	other := []SemanticScope{
+		testScope("u2"),
+		{UserID: "u1", PrivilegeMode: true, Space: "text-embedding-004@1"},
+		{UserID: "u1", Space: "text-embedding-004@1", Filters: "scope=d9"},
+		{UserID: "u1", Space: "text-embedding-005@2"},
+	}
+	for _, s := range other {
+		if _, ok := c.Lookup(s, vec); ok {
+			t.Errorf("scope %+v must not see u1's default-scope answer", s)
+		}
+	}
+}
+
+func TestSemanticCache_InvalidateDocument(t *testing.T) {
+	c := NewSemanticCache(time.Hour, 0.9)
+	defer c.Stop()
+
+	c.Store(testScope("u1"), "q1", []float32{1, 0, 0}, semanticAnswer("d1"), []string{"d1", "d2"})
+	c.Store(testScope("u1"), "q2", []float32{0, 1, 0}, semanticAnswer("d3"), []string{"d3"})
+
+	if n := c.InvalidateDocument("d2"); n != 1 {
+		t.Fatalf("removed = %d, want 1", n)
+	}
+	if _, ok := c.Lookup(testScope("u1"), []float32{1, 0, 0}); ok {
+		t.Error("answer citing d2 should be invalidated")
+	}
+	if _, ok := c.Lookup(testScope("u1"), []float32{0, 1, 0}); !ok {
+		t.Error("answer citing only d3 should survive")
+	}
+}
+
+func TestSemanticCache_InvalidateUser(t *testing.T) {
+	c := NewSemanticCache(time.Hour, 0.9)
+	defer c.Stop()
+
+	c.Store(testScope("u1"), "q", []float32{1, 0}, semanticAnswer("d1"), nil)
+	c.Store(testScope("u10"), "q", []float32{1, 0}, semanticAnswer("d1"), nil)
+
+	c.InvalidateUser("u1")
+	if _, ok := c.Lookup(testScope("u1"), []float32{1, 0}); ok {
+		t.Error("u1 entries should be gone")
+	}
+	if _, ok := c.Lookup(testScope("u10"), []float32{1, 0}); !ok {
+		t.Error("u10 entries must not be removed by u1 prefix")
+	}
+}
+
+func TestSemanticCache_ExpiryAndBound(t *testing.T) {
+	c := NewSemanticCache(10*time.Millisecond, 0.9)
+	defer c.Stop()
+
+	c.Store(testScope("u1"), "q", []float32{1, 0}, semanticAnswer("d1"), nil)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.Lookup(testScope("u1"), []float32{1, 0}); ok {
+		t.Error("expected miss after TTL")
+	}
+
+	c2 := NewSemanticCache(time.Hour, 0.9)
+	defer c2.Stop()
+	for i := 0; i < defaultSemanticMaxPerScope+10; i++ {
+		c2.Store(testScope("u1"), fmt.Sprintf("q%d", i), []float32{1, float32(i)}, semanticAnswer("d1"), nil)
+	}
+	if c2.Len() != defaultSemanticMaxPerScope {
+		t.Errorf("Len = %d, want %d", c2.Len(), defaultSemanticMaxPerScope)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds all application configuration loaded from environment variables.
@@ -46,6 +47,54 @@ type Config struct {
 	RerankUseEmbeddings      bool
 	ConfidenceFloor          float64
 	RedisAddr                string
+	EmbeddingSpacePollSec    int
+	EmbedConcurrency         int
+	SemanticCacheEnabled     bool
+	SemanticCacheThreshold   float64
+	QueryCacheMaxEntries     int
+	QueryCacheMaxMB          int
+	EmbedCacheMaxEntries     int
+	EmbedCacheMaxMB          int
+	RateLimitPolicyFile      string
+	RateLimitPolicyPollSec   int
+	QuotaGracePercent        int
+	UsageWebhookURL          string
+	UsageWebhookSecret       string
+	SMTPHost                 string
+	SMTPPort                 int
+	SMTPUsername             string
+	SMTPPassword             string
+	SMTPFrom                 string
+	UsageEventsPollSec       int
+	OIDCIssuer               string
+	OIDCAudience             string
+	OIDCJWKSURL              string
+	OIDCProviderID           string
+	OIDCUserIDClaim          string
+	OIDCEmailClaim           string
+	OIDCRoleClaim            string
+	OIDCRoleMap              string
+	OIDCJWKSCacheSec         int
+	AuditCheckpointKey       string
+	AuditCheckpointSec       int
+	AuditLoginIdleMin        int
+	AuditSinkFile            string
+	AuditSinkFileMaxMB       int
+	AuditSinkSyslogAddr      string
+	AuditSinkWebhookURL      string
+	AuditSinkWebhookSecret   string
+	AuditSinkIntervalSec     int
+	RetentionDefaultDays     int
+	RetentionPurgeSec        int
+	Neo4jURL                 string
+	Neo4jUsername            string
+	Neo4jPassword            string
+	DataKeyProvider          string
+	DataKeyLocal             string
+	KMSLocation              string
+	PIINameList              string
+	PIIRedactTypes           []string
+	PIIRedactExemptRoles     []string
 }
 
 // Load reads configuration from environment variables.
@@ -100,6 +149,79 @@ func Load() (*Config, error) {
 		RerankUseEmbeddings:      envBool("RERANK_USE_EMBEDDINGS", true),
 		ConfidenceFloor:          envFloat("CONFIDENCE_FLOOR", 0.3),
 		RedisAddr:                envStr("REDIS_ADDR", ""),
+		EmbeddingSpacePollSec:    envInt("EMBEDDING_SPACE_POLL_SECONDS", 30),
+		EmbedConcurrency:         envInt("EMBED_CONCURRENCY", 4),
+		SemanticCacheEnabled:     envBool("SEMANTIC_CACHE_ENABLED", false),
+		SemanticCacheThreshold:   envFloat("SEMANTIC_CACHE_THRESHOLD", 0.95),
+		QueryCacheMaxEntries:     envInt("QUERY_CACHE_MAX_ENTRIES", 10000),
+		QueryCacheMaxMB:          envInt("QUERY_CACHE_MAX_MB", 256),
+		EmbedCacheMaxEntries:     envInt("EMBED_CACHE_MAX_ENTRIES", 50000),
+		EmbedCacheMaxMB:          envInt("EMBED_CACHE_MAX_MB", 128),
+		RateLimitPolicyFile:      envStr("RATE_LIMIT_POLICY_FILE", ""),
+		RateLimitPolicyPollSec:   envInt("RATE_LIMIT_POLICY_POLL_SECONDS", 30),
+		QuotaGracePercent:        envInt("QUOTA_GRACE_PERCENT", 10),
+		UsageWebhookURL:          envStr("USAGE_WEBHOOK_URL", ""),
+		UsageWebhookSecret:       envStr("USAGE_WEBHOOK_SECRET", ""),
+		SMTPHost:                 envStr("SMTP_HOST", ""),
+		SMTPPort:                 envInt("SMTP_PORT", 587),
+		SMTPUsername:             envStr("SMTP_USERNAME", ""),
+		SMTPPassword:             envStr("SMTP_PASSWORD", ""),
+		SMTPFrom:                 envStr("SMTP_FROM", "alerts@ragbox.co"),
+		UsageEventsPollSec:       envInt("USAGE_EVENTS_POLL_SECONDS", 15),
+		OIDCIssuer:               envStr("OIDC_ISSUER", ""),
+		OIDCAudience:             envStr("OIDC_AUDIENCE", ""),
+		OIDCJWKSURL:              envStr("OIDC_JWKS_URL", ""),
+		OIDCProviderID:           envStr("OIDC_PROVIDER_ID", ""),
+		OIDCUserIDClaim:          envStr("OIDC_USER_ID_CLAIM", "sub"),
+		OIDCEmailClaim:           envStr("OIDC_EMAIL_CLAIM", "email"),
+		OIDCRoleClaim:            envStr("OIDC_ROLE_CLAIM", ""),
+		OIDCRoleMap:              envStr("OIDC_ROLE_MAP", ""),
+		OIDCJWKSCacheSec:         envInt("OIDC_JWKS_CACHE_SECONDS", 3600),
+		AuditCheckpointKey:       envStr("AUDIT_CHECKPOINT_KEY", ""),
+		AuditCheckpointSec:       envInt("AUDIT_CHECKPOINT_INTERVAL_SECONDS", 3600),
+		AuditLoginIdleMin:        envInt("AUDIT_LOGIN_IDLE_MINUTES", 30),
+		AuditSinkFile:            envStr("AUDIT_SINK_FILE", ""),
+		AuditSinkFileMaxMB:       envInt("AUDIT_SINK_FILE_MAX_MB", 100),
+		AuditSinkSyslogAddr:      envStr("AUDIT_SINK_SYSLOG_ADDR", ""),
+		AuditSinkWebhookURL:      envStr("AUDIT_SINK_WEBHOOK_URL", ""),
+		AuditSinkWebhookSecret:   envStr("AUDIT_SINK_WEBHOOK_SECRET", ""),
+		AuditSinkIntervalSec:     envInt("AUDIT_SINK_INTERVAL_SECONDS", 5),
+		RetentionDefaultDays:     envInt("RETENTION_DEFAULT_DAYS", 30),
+		RetentionPurgeSec:        envInt("RETENTION_PURGE_INTERVAL_SECONDS", 3600),
+		Neo4jURL:                 envStr("NEO4J_URL", ""),
+		Neo4jUsername:            envStr("NEO4J_USERNAME", "neo4j"),
+		Neo4jPassword:            envStr("NEO4J_PASSWORD", ""),
+		DataKeyProvider:          envStr("DATA_KEY_PROVIDER", ""),
+		DataKeyLocal:             envStr("DATA_KEY_LOCAL", ""),
+		KMSLocation:              envStr("KMS_LOCATION", "global"),
+		PIINameList:              envStr("PII_NAME_LIST", ""),
+		PIIRedactTypes:           envList("PII_REDACT_TYPES"),
+		PIIRedactExemptRoles:     envList("PII_REDACT_EXEMPT_ROLES"),
+	}
+
+	if cfg.UsageWebhookURL != "" && cfg.UsageWebhookSecret == "" {
+		return nil, fmt.Errorf("config.Load: USAGE_WEBHOOK_SECRET is required when USAGE_WEBHOOK_URL is set")
+	}
+
+	if cfg.OIDCIssuer != "" && cfg.OIDCAudience == "" {
+		return nil, fmt.Errorf("config.Load: OIDC_AUDIENCE is required when OIDC_ISSUER is set")
+	}
+
+	if cfg.RetentionDefaultDays < 0 {
+		return nil, fmt.Errorf("config.Load: RETENTION_DEFAULT_DAYS must not be negative")
+	}
+
+	switch cfg.DataKeyProvider {
+	case "", "kms":
+	case "local":
+		if cfg.DataKeyLocal == "" {
+			return nil, fmt.Errorf("config.Load: DATA_KEY_LOCAL is required when DATA_KEY_PROVIDER is local")
+		}
+		if cfg.Environment == "production" {
+			return nil, fmt.Errorf("config.Load: DATA_KEY_PROVIDER=local is not allowed in production")
+		}
+	default:
+		return nil, fmt.Errorf("config.Load: DATA_KEY_PROVIDER must be local or kms, got %q", cfg.DataKeyProvider)
 	}
 
 	// Internal auth secret is required in non-development environments
@@ -117,6 +239,17 @@ func envStr(key, fallback string) string {
 	return fallback
 }
 
+// envList splits a comma-separated variable, dropping empty entries.
+func envList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func envInt(key string, fallback int) int {
 	v := os.Getenv(key)
 	if v == "" {
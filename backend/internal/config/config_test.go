@@ -170,3 +170,87 @@ func TestLoad_RequiredFieldsPresent(t *testing.T) {
 		t.Errorf("GCPProject = %q, want set value", cfg.GCPProject)
 	}
 }
+
+func TestLoad_OIDCIssuerRequiresAudience(t *testing.T) {
+	clearEnv(t)
+	setRequired(t)
+	t.Setenv("OIDC_ISSUER", "https://acme.okta.com")
+	t.Setenv("OIDC_AUDIENCE", "")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for OIDC_ISSUER without OIDC_AUDIENCE")
+	}
+
+	t.Setenv("OIDC_AUDIENCE", "ragbox")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.OIDCUserIDClaim != "sub" || cfg.OIDCJWKSCacheSec != 3600 {
+		t.Errorf("OIDC defaults = %q, %d", cfg.OIDCUserIDClaim, cfg.OIDCJWKSCacheSec)
+	}
+}
+
+func TestLoad_DataKeyProvider(t *testing.T) {
+	clearEnv(t)
+	setRequired(t)
+	t.Setenv("ENVIRONMENT", "development")
+
+	t.Setenv("DATA_KEY_PROVIDER", "vault")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown DATA_KEY_PROVIDER")
+	}
+
+	t.Setenv("DATA_KEY_PROVIDER", "local")
+	t.Setenv("DATA_KEY_LOCAL", "")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for local provider without DATA_KEY_LOCAL")
+	}
+
+	t.Setenv("DATA_KEY_LOCAL", "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("INTERNAL_AUTH_SECRET", "secret")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for local provider in production")
+	}
+
+	t.Setenv("DATA_KEY_PROVIDER", "kms")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.KMSLocation != "global" {
+		t.Errorf("KMSLocation = %q, want global", cfg.KMSLocation)
+	}
+}
+
+func TestLoad_PIIRedactLists(t *testing.T) {
+	clearEnv(t)
+	setRequired(t)
+	t.Setenv("ENVIRONMENT", "development")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(cfg.PIIRedactTypes) != 0 || len(cfg.PIIRedactExemptRoles) != 0 {
+		t.Errorf("defaults = %v / %v, want redaction off", cfg.PIIRedactTypes, cfg.PIIRedactExemptRoles)
+	}
+
+	t.Setenv("PII_REDACT_TYPES", " US_SOCIAL_SECURITY_NUMBER, ,EMAIL_ADDRESS ")
+	t.Setenv("PII_REDACT_EXEMPT_ROLES", "Partner")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(cfg.PIIRedactTypes) != 2 || cfg.PIIRedactTypes[0] != "US_SOCIAL_SECURITY_NUMBER" || cfg.PIIRedactTypes[1] != "EMAIL_ADDRESS" {
+		t.Errorf("PIIRedactTypes = %q", cfg.PIIRedactTypes)
+	}
+	if len(cfg.PIIRedactExemptRoles) != 1 || cfg.PIIRedactExemptRoles[0] != "Partner" {
+		t.Errorf("PIIRedactExemptRoles = %q", cfg.PIIRedactExemptRoles)
+	}
+}
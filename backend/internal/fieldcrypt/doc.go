@@ -0,0 +1,17 @@
+// Package fieldcrypt encrypts document text at rest with per-tenant data
+// encryption keys (DEKs). Each tenant's DEK is generated here, wrapped by a
+// KeyWrapper holding the key encryption key (a local key in development, a
+// KMS key in production) and persisted only in wrapped form through a
+// KeyStore.
+//
+// Sealed values are self-describing text, so they fit the existing TEXT
+// columns and can sit next to rows written before encryption was enabled:
+//
+//	ragbox:enc:v1:<key version>:<tenant ID>:<base64 nonce||AES-256-GCM ciphertext>
+//
+// The header is bound into the GCM additional data, so a value cannot be
+// moved to another tenant or key version without failing to open.
+//
+// Like auditchain, it must not import anything that needs a database or
+// cloud client.
+package fieldcrypt
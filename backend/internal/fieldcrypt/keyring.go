@@ -0,0 +1,364 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefix starts every sealed value.
+const Prefix = "ragbox:enc:v1:"
+
+// dekSize is the AES-256 data key length.
+const dekSize = 32
+
+// hashKeyLabel derives a tenant's hash key from its first DEK, so hashes
+// stay stable when later key versions are added.
+const hashKeyLabel = "ragbox:hash:v1"
+
+var (
+	// ErrNoKeyring is returned when a sealed value is read without a keyring.
+	ErrNoKeyring = errors.New("fieldcrypt: value is encrypted but no data key provider is configured")
+	// ErrMalformed is returned for a value with the prefix that cannot be parsed.
+	ErrMalformed = errors.New("fieldcrypt: malformed sealed value")
+	// ErrUnknownKey is returned when the key a value names is not in the store.
+	ErrUnknownKey = errors.New("fieldcrypt: unknown data key")
+)
+
+// KeyWrapper wraps and unwraps DEKs with a key encryption key it never
+// reveals. Implemented by LocalWrapper and gcpclient.KMSKeyWrapper.
+type KeyWrapper interface {
+	// KeyID names the key encryption key; it is stored with each wrapped DEK.
+	KeyID() string
+	Wrap(ctx context.Context, dek []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// DataKey is a tenant's wrapped DEK as persisted.
+type DataKey struct {
+	TenantID   string
+	Version    int
+	WrappedKey []byte
+	KEKID      string
+	CreatedAt  time.Time
+}
+
+// KeyStore persists wrapped DEKs. Implemented by repository.DataKeyRepo.
+type KeyStore interface {
+	// ActiveKey returns the tenant's newest key, or nil if it has none.
+	ActiveKey(ctx context.Context, tenantID string) (*DataKey, error)
+	// Key returns one version of the tenant's key, or nil if absent.
+	Key(ctx context.Context, tenantID string, version int) (*DataKey, error)
+	// CreateKey stores k unless the tenant already has that version, and
+	// returns the stored key, so concurrent creators agree on one DEK.
+	CreateKey(ctx context.Context, k *DataKey) (*DataKey, error)
+}
+
+type keyRef struct {
+	tenantID string
+	version  int
+}
+
+// Keyring seals and opens tenant text, creating a tenant's DEK on first
+// use and caching unwrapped DEKs in memory so the key provider is called
+// once per tenant and version per process.
+//
+// A nil *Keyring is valid and means encryption is disabled: Seal returns
+// its input and Open returns unsealed values unchanged.
+type Keyring struct {
+	store   KeyStore
+	wrapper KeyWrapper
+
+	mu     sync.Mutex
+	dek    map[keyRef]cipher.AEAD
+	active map[string]int
+	hash   map[string][]byte
+}
+
+// NewKeyring creates a Keyring.
+func NewKeyring(store KeyStore, wrapper KeyWrapper) *Keyring {
+	return &Keyring{
+		store:   store,
+		wrapper: wrapper,
+		dek:     make(map[keyRef]cipher.AEAD),
+		active:  make(map[string]int),
+		hash:    make(map[string][]byte),
+	}
+}
+
+// IsSealed reports whether v carries the sealed-value prefix.
+func IsSealed(v string) bool {
+	return strings.HasPrefix(v, Prefix)
+}
+
+// Seal encrypts plaintext under the tenant's active DEK. Empty strings are
+// returned unchanged.
+func (k *Keyring) Seal(ctx context.Context, tenantID, plaintext string) (string, error) {
+	if k == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	if tenantID == "" {
+		return "", fmt.Errorf("fieldcrypt.Seal: tenant ID is required")
+	}
+	version, aead, err := k.activeKey(ctx, tenantID)
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt.Seal: %w", err)
+	}
+
+	header := Prefix + strconv.Itoa(version) + ":" + tenantID + ":"
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("fieldcrypt.Seal: nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(header))
+	return header + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a sealed value. Values without the prefix were written
+// before encryption was enabled and are returned unchanged.
+func (k *Keyring) Open(ctx context.Context, v string) (string, error) {
+	if !IsSealed(v) {
+		return v, nil
+	}
+	if k == nil {
+		return "", ErrNoKeyring
+	}
+	ref, header, payload, err := parse(v)
+	if err != nil {
+		return "", err
+	}
+	aead, err := k.key(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt.Open: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(raw) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	plain, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], []byte(header))
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt.Open: tenant %s key v%d: %w", ref.tenantID, ref.version, err)
+	}
+	return string(plain), nil
+}
+
+// SealedTenant returns the tenant a sealed value was sealed for, or "" for
+// a value that is not sealed.
+func SealedTenant(v string) string {
+	if !IsSealed(v) {
+		return ""
+	}
+	ref, _, _, err := parse(v)
+	if err != nil {
+		return ""
+	}
+	return ref.tenantID
+}
+
+// Hash keys a hex digest of tenant text with the tenant's hash key, so a
+// stored hash cannot be matched against guessed text, or against the same
+// text in another tenant, without the key. A nil Keyring returns digest
+// unchanged.
+func (k *Keyring) Hash(ctx context.Context, tenantID, digest string) (string, error) {
+	if k == nil {
+		return digest, nil
+	}
+	if tenantID == "" {
+		return "", fmt.Errorf("fieldcrypt.Hash: tenant ID is required")
+	}
+	key, err := k.hashKey(ctx, tenantID)
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt.Hash: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(digest))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// OpenPtr is Open for nullable columns.
+func (k *Keyring) OpenPtr(ctx context.Context, v *string) error {
+	if v == nil {
+		return nil
+	}
+	plain, err := k.Open(ctx, *v)
+	if err != nil {
+		return err
+	}
+	*v = plain
+	return nil
+}
+
+// parse splits a sealed value into its key reference, the header bound as
+// additional data, and the base64 payload.
+func parse(v string) (keyRef, string, string, error) {
+	rest := strings.TrimPrefix(v, Prefix)
+	versionStr, rest, ok := strings.Cut(rest, ":")
+	if !ok {
+		return keyRef{}, "", "", ErrMalformed
+	}
+	// The payload is base64 and never contains ':', so a tenant ID may.
+	i := strings.LastIndex(rest, ":")
+	if i <= 0 {
+		return keyRef{}, "", "", ErrMalformed
+	}
+	tenantID, payload := rest[:i], rest[i+1:]
+	version, err := strconv.Atoi(versionStr)
+	if err != nil || version < 1 {
+		return keyRef{}, "", "", ErrMalformed
+	}
+	header := v[:len(v)-len(payload)]
+	return keyRef{tenantID: tenantID, version: version}, header, payload, nil
+}
+
+// activeKey returns the tenant's newest DEK, creating version 1 if the
+// tenant has none.
+func (k *Keyring) activeKey(ctx context.Context, tenantID string) (int, cipher.AEAD, error) {
+	k.mu.Lock()
+	version, ok := k.active[tenantID]
+	if ok {
+		aead := k.dek[keyRef{tenantID, version}]
+		k.mu.Unlock()
+		return version, aead, nil
+	}
+	k.mu.Unlock()
+
+	dk, err := k.store.ActiveKey(ctx, tenantID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if dk == nil {
+		if dk, err = k.createKey(ctx, tenantID); err != nil {
+			return 0, nil, err
+		}
+	}
+	aead, err := k.unwrap(ctx, dk)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	ref := keyRef{tenantID, dk.Version}
+	k.dek[ref] = aead
+	k.active[tenantID] = dk.Version
+	return dk.Version, aead, nil
+}
+
+func (k *Keyring) createKey(ctx context.Context, tenantID string) (*DataKey, error) {
+	dek := make([]byte, dekSize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+	wrapped, err := k.wrapper.Wrap(ctx, dek)
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key: %w", err)
+	}
+	return k.store.CreateKey(ctx, &DataKey{
+		TenantID:   tenantID,
+		Version:    1,
+		WrappedKey: wrapped,
+		KEKID:      k.wrapper.KeyID(),
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+// hashKey returns the tenant's hash key, creating its DEK if it has none.
+func (k *Keyring) hashKey(ctx context.Context, tenantID string) ([]byte, error) {
+	k.mu.Lock()
+	key, ok := k.hash[tenantID]
+	k.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	if _, _, err := k.activeKey(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	dk, err := k.store.Key(ctx, tenantID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if dk == nil {
+		return nil, fmt.Errorf("%w: tenant %s v1", ErrUnknownKey, tenantID)
+	}
+	dek, err := k.unwrapDEK(ctx, dk)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, dek)
+	mac.Write([]byte(hashKeyLabel))
+	key = mac.Sum(nil)
+
+	k.mu.Lock()
+	k.hash[tenantID] = key
+	k.mu.Unlock()
+	return key, nil
+}
+
+// key returns the DEK a sealed value names.
+func (k *Keyring) key(ctx context.Context, ref keyRef) (cipher.AEAD, error) {
+	k.mu.Lock()
+	aead, ok := k.dek[ref]
+	k.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	dk, err := k.store.Key(ctx, ref.tenantID, ref.version)
+	if err != nil {
+		return nil, err
+	}
+	if dk == nil {
+		return nil, fmt.Errorf("%w: tenant %s v%d", ErrUnknownKey, ref.tenantID, ref.version)
+	}
+	if aead, err = k.unwrap(ctx, dk); err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	k.dek[ref] = aead
+	k.mu.Unlock()
+	return aead, nil
+}
+
+func (k *Keyring) unwrap(ctx context.Context, dk *DataKey) (cipher.AEAD, error) {
+	dek, err := k.unwrapDEK(ctx, dk)
+	if err != nil {
+		return nil, err
+	}
+	return newAEAD(dek)
+}
+
+func (k *Keyring) unwrapDEK(ctx context.Context, dk *DataKey) ([]byte, error) {
+	if dk.KEKID != k.wrapper.KeyID() {
+		return nil, fmt.Errorf("data key for tenant %s v%d is wrapped by %q, provider holds %q",
+			dk.TenantID, dk.Version, dk.KEKID, k.wrapper.KeyID())
+	}
+	dek, err := k.wrapper.Unwrap(ctx, dk.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+	return dek, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != dekSize {
+		return nil, fmt.Errorf("key is %d bytes, want %d", len(key), dekSize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,201 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type memKeyStore struct {
+	mu      sync.Mutex
+	keys    map[keyRef]DataKey
+	creates int
+}
+
+func newMemKeyStore() *memKeyStore {
+	return &memKeyStore{keys: make(map[keyRef]DataKey)}
+}
+
+func (s *memKeyStore) ActiveKey(_ context.Context, tenantID string) (*DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var best *DataKey
+	for ref, k := range s.keys {
+		if ref.tenantID == tenantID && (best == nil || k.Version > best.Version) {
+			k := k
+			best = &k
+		}
+	}
+	return best, nil
+}
+
+func (s *memKeyStore) Key(_ context.Context, tenantID string, version int) (*DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if k, ok := s.keys[keyRef{tenantID, version}]; ok {
+		return &k, nil
+	}
+	return nil, nil
+}
+
+func (s *memKeyStore) CreateKey(_ context.Context, k *DataKey) (*DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ref := keyRef{k.TenantID, k.Version}
+	if existing, ok := s.keys[ref]; ok {
+		return &existing, nil
+	}
+	s.creates++
+	s.keys[ref] = *k
+	return k, nil
+}
+
+func newTestWrapper(t *testing.T, seed byte) *LocalWrapper {
+	t.Helper()
+	w, err := NewLocalWrapper(bytes.Repeat([]byte{seed}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	ctx := context.Background()
+	store := newMemKeyStore()
+	k := NewKeyring(store, newTestWrapper(t, 1))
+
+	sealed, err := k.Seal(ctx, "org-1", "privileged memo text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "memo") {
+		t.Fatalf("sealed = %q, want opaque sealed value", sealed)
+	}
+	again, _ := k.Seal(ctx, "org-1", "privileged memo text")
+	if again == sealed {
+		t.Error("sealing twice produced the same value; nonce is not random")
+	}
+
+	// A fresh process with the same store and key provider can read it.
+	plain, err := NewKeyring(store, newTestWrapper(t, 1)).Open(ctx, sealed)
+	if err != nil || plain != "privileged memo text" {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+	if store.creates != 1 {
+		t.Errorf("data keys created = %d, want 1 per tenant", store.creates)
+	}
+
+	if _, err := k.Seal(ctx, "user:abc", "no org"); err != nil {
+		t.Fatalf("tenant ID with a colon: %v", err)
+	}
+	if store.creates != 2 {
+		t.Errorf("data keys created = %d, want a second tenant key", store.creates)
+	}
+}
+
+func TestKeyring_PassthroughAndDisabled(t *testing.T) {
+	ctx := context.Background()
+	k := NewKeyring(newMemKeyStore(), newTestWrapper(t, 1))
+
+	if got, err := k.Open(ctx, "written before encryption"); err != nil || got != "written before encryption" {
+		t.Errorf("Open(plaintext) = %q, %v", got, err)
+	}
+	if got, _ := k.Seal(ctx, "org-1", ""); got != "" {
+		t.Errorf("Seal(\"\") = %q, want empty", got)
+	}
+
+	var disabled *Keyring
+	if got, _ := disabled.Seal(ctx, "org-1", "text"); got != "text" {
+		t.Errorf("nil keyring Seal = %q, want passthrough", got)
+	}
+	sealed, _ := k.Seal(ctx, "org-1", "text")
+	if _, err := disabled.Open(ctx, sealed); !errors.Is(err, ErrNoKeyring) {
+		t.Errorf("nil keyring Open(sealed) err = %v, want ErrNoKeyring", err)
+	}
+
+	var s *string
+	if err := k.OpenPtr(ctx, s); err != nil {
+		t.Errorf("OpenPtr(nil) = %v", err)
+	}
+	p := &sealed
+	if err := k.OpenPtr(ctx, p); err != nil || *p != "text" {
+		t.Errorf("OpenPtr = %q, %v", *p, err)
+	}
+}
+
+func TestKeyring_Hash(t *testing.T) {
+	ctx := context.Background()
+	store := newMemKeyStore()
+	k := NewKeyring(store, newTestWrapper(t, 1))
+	const digest = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	h1, err := k.Hash(ctx, "org-1", digest)
+	if err != nil || h1 == digest || len(h1) != 64 {
+		t.Fatalf("Hash = %q, %v; want a keyed hex digest", h1, err)
+	}
+	// Stable across processes sharing the key store.
+	if again, _ := NewKeyring(store, newTestWrapper(t, 1)).Hash(ctx, "org-1", digest); again != h1 {
+		t.Errorf("Hash in a fresh keyring = %q, want %q", again, h1)
+	}
+	if other, _ := k.Hash(ctx, "org-2", digest); other == h1 {
+		t.Error("two tenants hash the same text to the same value")
+	}
+	if _, err := k.Hash(ctx, "", digest); err == nil {
+		t.Error("Hash without a tenant succeeded")
+	}
+
+	var disabled *Keyring
+	if got, _ := disabled.Hash(ctx, "org-1", digest); got != digest {
+		t.Errorf("nil keyring Hash = %q, want passthrough", got)
+	}
+
+	sealed, _ := k.Seal(ctx, "user:abc", "text")
+	if got := SealedTenant(sealed); got != "user:abc" {
+		t.Errorf("SealedTenant = %q, want user:abc", got)
+	}
+	if got := SealedTenant("plain text"); got != "" {
+		t.Errorf("SealedTenant(plaintext) = %q, want empty", got)
+	}
+}
+
+func TestKeyring_RejectsTamperingAndWrongKey(t *testing.T) {
+	ctx := context.Background()
+	store := newMemKeyStore()
+	k := NewKeyring(store, newTestWrapper(t, 1))
+	sealedA, _ := k.Seal(ctx, "org-a", "tenant A text")
+	if _, err := k.Seal(ctx, "org-b", "tenant B text"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Relabelling A's ciphertext as B's must not decrypt under B's key.
+	moved := strings.Replace(sealedA, ":org-a:", ":org-b:", 1)
+	if _, err := k.Open(ctx, moved); err == nil {
+		t.Error("ciphertext moved to another tenant opened")
+	}
+	if _, err := k.Open(ctx, strings.Replace(sealedA, Prefix+"1:", Prefix+"2:", 1)); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown version err = %v, want ErrUnknownKey", err)
+	}
+	if _, err := k.Open(ctx, Prefix+"garbage"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("malformed err = %v, want ErrMalformed", err)
+	}
+
+	// A different key encryption key cannot unwrap the stored data keys.
+	if _, err := NewKeyring(store, newTestWrapper(t, 2)).Open(ctx, sealedA); err == nil {
+		t.Error("opened with the wrong key encryption key")
+	}
+}
+
+func TestParseLocalKey(t *testing.T) {
+	if _, err := ParseLocalKey("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="); err != nil {
+		t.Errorf("valid key: %v", err)
+	}
+	if _, err := ParseLocalKey("c2hvcnQ="); err == nil {
+		t.Error("short key accepted")
+	}
+	if _, err := ParseLocalKey("not base64!"); err == nil {
+		t.Error("non-base64 key accepted")
+	}
+}
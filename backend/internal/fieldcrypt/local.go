@@ -0,0 +1,70 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// localWrapDomain is the GCM additional data for locally wrapped DEKs.
+var localWrapDomain = []byte("ragbox-dek-wrap/v1")
+
+// LocalWrapper wraps DEKs with AES-256-GCM under a key held in process
+// memory. It is meant for development; production should use a KMS.
+type LocalWrapper struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewLocalWrapper creates a LocalWrapper from a 32-byte key.
+func NewLocalWrapper(key []byte) (*LocalWrapper, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("fieldcrypt.NewLocalWrapper: %w", err)
+	}
+	sum := sha256.Sum256(key)
+	return &LocalWrapper{id: "local:" + hex.EncodeToString(sum[:8]), aead: aead}, nil
+}
+
+// ParseLocalKey decodes a base64 (standard or URL alphabet) 32-byte key,
+// as produced by `openssl rand -base64 32`.
+func ParseLocalKey(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(s); err == nil {
+			if len(key) != dekSize {
+				return nil, fmt.Errorf("fieldcrypt.ParseLocalKey: key is %d bytes, want %d", len(key), dekSize)
+			}
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("fieldcrypt.ParseLocalKey: key is not base64")
+}
+
+// KeyID returns "local:" and a fingerprint of the key.
+func (w *LocalWrapper) KeyID() string { return w.id }
+
+// Wrap encrypts dek under the local key.
+func (w *LocalWrapper) Wrap(_ context.Context, dek []byte) ([]byte, error) {
+	nonce := make([]byte, w.aead.NonceSize(), w.aead.NonceSize()+len(dek)+w.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("fieldcrypt.LocalWrapper: %w", err)
+	}
+	return w.aead.Seal(nonce, nonce, dek, localWrapDomain), nil
+}
+
+// Unwrap decrypts a DEK wrapped by Wrap.
+func (w *LocalWrapper) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < w.aead.NonceSize() {
+		return nil, fmt.Errorf("fieldcrypt.LocalWrapper: wrapped key too short")
+	}
+	n := w.aead.NonceSize()
+	dek, err := w.aead.Open(nil, wrapped[:n], wrapped[n:], localWrapDomain)
+	if err != nil {
+		return nil, fmt.Errorf("fieldcrypt.LocalWrapper: %w", err)
+	}
+	return dek, nil
+}
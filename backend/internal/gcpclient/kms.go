@@ -0,0 +1,101 @@
+package gcpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	cloudkms "google.golang.org/api/cloudkms/v1"
+
+	"github.com/connexus-ai/ragbox-backend/internal/fieldcrypt"
+)
+
+// KMSKeyWrapper implements fieldcrypt.KeyWrapper with a Cloud KMS symmetric
+// key, so tenant data keys are only ever unwrapped by KMS.
+type KMSKeyWrapper struct {
+	keys    *cloudkms.ProjectsLocationsKeyRingsCryptoKeysService
+	keyName string
+}
+
+// NewKMSKeyWrapper creates a KMSKeyWrapper for the full crypto key resource
+// name (see KMSKeyName).
+func NewKMSKeyWrapper(ctx context.Context, keyName string) (*KMSKeyWrapper, error) {
+	svc, err := cloudkms.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gcpclient.NewKMSKeyWrapper: %w", err)
+	}
+	return &KMSKeyWrapper{keys: svc.Projects.Locations.KeyRings.CryptoKeys, keyName: keyName}, nil
+}
+
+// KMSKeyName builds a crypto key resource name.
+func KMSKeyName(project, location, keyRing, key string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/keyRings/%s/cryptoKeys/%s", project, location, keyRing, key)
+}
+
+// KeyID returns the crypto key resource name.
+func (w *KMSKeyWrapper) KeyID() string { return w.keyName }
+
+// Wrap encrypts dek with the primary version of the crypto key.
+func (w *KMSKeyWrapper) Wrap(ctx context.Context, dek []byte) ([]byte, error) {
+	resp, err := w.keys.Encrypt(w.keyName, &cloudkms.EncryptRequest{
+		Plaintext: base64.StdEncoding.EncodeToString(dek),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("gcpclient.KMSWrap: %w", err)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(resp.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("gcpclient.KMSWrap: decode: %w", err)
+	}
+	return wrapped, nil
+}
+
+// Unwrap decrypts a wrapped DEK; KMS picks the key version from the ciphertext.
+func (w *KMSKeyWrapper) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	resp, err := w.keys.Decrypt(w.keyName, &cloudkms.DecryptRequest{
+		Ciphertext: base64.StdEncoding.EncodeToString(wrapped),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("gcpclient.KMSUnwrap: %w", err)
+	}
+	dek, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("gcpclient.KMSUnwrap: decode: %w", err)
+	}
+	return dek, nil
+}
+
+// NewKeyWrapper returns the key wrapper for a DATA_KEY_PROVIDER setting:
+// nil for "" (encryption disabled), a fieldcrypt.LocalWrapper for "local"
+// and a KMSKeyWrapper for "kms".
+func NewKeyWrapper(ctx context.Context, provider, localKey, kmsKeyName string) (fieldcrypt.KeyWrapper, error) {
+	switch provider {
+	case "":
+		return nil, nil
+	case "local":
+		key, err := fieldcrypt.ParseLocalKey(localKey)
+		if err != nil {
+			return nil, fmt.Errorf("gcpclient.NewKeyWrapper: DATA_KEY_LOCAL: %w", err)
+		}
+		return fieldcrypt.NewLocalWrapper(key)
+	case "kms":
+		return NewKMSKeyWrapper(ctx, kmsKeyName)
+	default:
+		return nil, fmt.Errorf("gcpclient.NewKeyWrapper: unknown DATA_KEY_PROVIDER %q", provider)
+	}
+}
+
+// KeyWrapperFromEnv is NewKeyWrapper configured from the environment, with
+// the server's defaults, for the workers that read or write document text.
+func KeyWrapperFromEnv(ctx context.Context) (fieldcrypt.KeyWrapper, error) {
+	env := func(key, fallback string) string {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+		return fallback
+	}
+	return NewKeyWrapper(ctx, os.Getenv("DATA_KEY_PROVIDER"), os.Getenv("DATA_KEY_LOCAL"),
+		KMSKeyName(os.Getenv("GOOGLE_CLOUD_PROJECT"), env("KMS_LOCATION", "global"),
+			env("KMS_KEY_RING", "ragbox-keys"), env("KMS_KEY_NAME", "document-key")))
+}
@@ -7,10 +7,14 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
 
 // ErrRateLimited is returned when all retries are exhausted on a 429 response.
-var ErrRateLimited = fmt.Errorf("the system is experiencing high demand. Please try again in a few seconds")
+// It is service.ErrRateLimited, so callers above the client (e.g. the embedder's
+// shared backoff) can detect it without importing gcpclient.
+var ErrRateLimited = service.ErrRateLimited
 
 // retryConfig holds the backoff schedule for Vertex AI 429 mitigation.
 var retryConfig = struct {
@@ -2,6 +2,7 @@ package gcpclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -70,6 +71,15 @@ func (a *StorageAdapter) Download(ctx context.Context, bucket, object string) ([
 	return io.ReadAll(r)
 }
 
+// Delete removes a GCS object. Deleting a missing object succeeds.
+func (a *StorageAdapter) Delete(ctx context.Context, bucket, object string) error {
+	err := a.client.Bucket(bucket).Object(object).Delete(ctx)
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("gcpclient.Delete: %w", err)
+	}
+	return nil
+}
+
 // Close closes the underlying client.
 func (a *StorageAdapter) Close() {
 	a.client.Close()
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// sameOrg reports whether content scoped to orgID belongs to the caller's
+// organization. Personal scopes never match another user.
+func sameOrg(ctx context.Context, orgID string) bool {
+	callerOrg := middleware.OrgIDFromContext(ctx)
+	return orgID != "" && callerOrg != "" && orgID == callerOrg && !model.IsPersonalOrg(orgID)
+}
+
+// canRead reports whether userID may read content owned by ownerID in orgID:
+// the owner, or any member of the same organization.
+func canRead(ctx context.Context, userID, ownerID, orgID string) bool {
+	return ownerID == userID || sameOrg(ctx, orgID)
+}
+
+// canWrite reports whether userID may modify content owned by ownerID in
+// orgID: the owner, or a Partner of the same organization.
+func canWrite(ctx context.Context, userID, ownerID, orgID string) bool {
+	if ownerID == userID {
+		return true
+	}
+	return sameOrg(ctx, orgID) && middleware.OrgRoleFromContext(ctx) == model.UserRolePartner
+}
+
+// canReadDocument reports whether userID may read doc: by ownership or
+// organization, or through a share grant when repo resolves them. Another
+// user's privileged document is only readable in Privileged Mode.
+func canReadDocument(ctx context.Context, repo service.DocumentRepository, doc *model.Document, userID string, privileged bool) bool {
+	if doc == nil {
+		return false
+	}
+	if doc.IsPrivileged && doc.UserID != userID && !privileged {
+		return false
+	}
+	return canRead(ctx, userID, doc.UserID, doc.OrgID) || documentShareRole(ctx, repo, doc.ID, userID) != ""
+}
+
+// canWriteDocument reports whether userID may modify doc: as owner or
+// Partner, or through an editor share grant.
+func canWriteDocument(ctx context.Context, repo service.DocumentRepository, doc *model.Document, userID string) bool {
+	if doc == nil {
+		return false
+	}
+	return canManageDocument(ctx, doc, userID) || documentShareRole(ctx, repo, doc.ID, userID) == model.ShareEditor
+}
+
+// canManageDocument reports whether userID may manage doc's sharing. Share
+// grants never confer it.
+func canManageDocument(ctx context.Context, doc *model.Document, userID string) bool {
+	return doc != nil && canWrite(ctx, userID, doc.UserID, doc.OrgID)
+}
+
+// canManageFolder reports whether userID may delete or share folder. Editor
+// grants on a folder reach its documents, not the folder itself.
+func canManageFolder(ctx context.Context, folder *model.Folder, userID string) bool {
+	return folder != nil && canWrite(ctx, userID, folder.UserID, folder.OrgID)
+}
+
+func canWriteVault(ctx context.Context, vault *model.Vault, userID string) bool {
+	return vault != nil && canWrite(ctx, userID, vault.UserID, vault.OrgID)
+}
+
+// documentShareRole returns userID's share role on docID, or "" when repo
+// does not resolve share grants or the lookup fails.
+func documentShareRole(ctx context.Context, repo service.DocumentRepository, docID, userID string) model.ShareRole {
+	resolver, ok := repo.(service.DocumentShareResolver)
+	if !ok {
+		return ""
+	}
+	role, err := resolver.DocumentShareRole(ctx, docID, userID)
+	if err != nil {
+		slog.Warn("[Access] share lookup failed", "document_id", docID, "user_id", userID, "error", err)
+		return ""
+	}
+	return role
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// APITokenDeps bundles dependencies for personal access token handlers.
+type APITokenDeps struct {
+	Svc *service.APITokenService
+}
+
+// CreateAPITokenRequest is the request body for creating a token.
+type CreateAPITokenRequest struct {
+	Name      string                `json:"name"`
+	Scopes    []model.APITokenScope `json:"scopes"`
+	ExpiresAt *time.Time            `json:"expiresAt,omitempty"` // nil = never expires
+}
+
+// apiTokenResponse returns the token secret once, at creation.
+type apiTokenResponse struct {
+	Token  *model.APIToken `json:"token"`
+	Secret string          `json:"secret"`
+}
+
+// respondAPITokenError maps token service errors to HTTP statuses.
+func respondAPITokenError(w http.ResponseWriter, err error, op string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrAPITokenNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrAPITokenLimit):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidAPIToken):
+		status = http.StatusBadRequest
+	}
+	if status == http.StatusInternalServerError {
+		slog.Error("[APIToken] "+op+" failed", "error", err)
+		respondJSON(w, status, envelope{Success: false, Error: op + " failed"})
+		return
+	}
+	respondJSON(w, status, envelope{Success: false, Error: err.Error()})
+}
+
+// ListAPITokens handles GET /api/tokens.
+func ListAPITokens(deps APITokenDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		tokens, err := deps.Svc.List(r.Context(), userID)
+		if err != nil {
+			respondAPITokenError(w, err, "list API tokens")
+			return
+		}
+		if tokens == nil {
+			tokens = []model.APIToken{}
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: tokens})
+	}
+}
+
+// CreateAPIToken handles POST /api/tokens. The secret is only returned here.
+func CreateAPIToken(deps APITokenDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req CreateAPITokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		token, secret, err := deps.Svc.Create(r.Context(), userID, service.CreateAPITokenRequest{
+			Name:      req.Name,
+			Scopes:    req.Scopes,
+			ExpiresAt: req.ExpiresAt,
+		})
+		if err != nil {
+			respondAPITokenError(w, err, "create API token")
+			return
+		}
+		respondJSON(w, http.StatusCreated, envelope{Success: true, Data: apiTokenResponse{Token: token, Secret: secret}})
+	}
+}
+
+// RevokeAPIToken handles DELETE /api/tokens/{id}.
+func RevokeAPIToken(deps APITokenDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		tokenID := chi.URLParam(r, "id")
+		if !validateUUID(tokenID) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid token ID format"})
+			return
+		}
+
+		if err := deps.Svc.Revoke(r.Context(), userID, tokenID); err != nil {
+			respondAPITokenError(w, err, "revoke API token")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
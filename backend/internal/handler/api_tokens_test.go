@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// memTokenRepo implements service.APITokenRepository in memory.
+type memTokenRepo struct {
+	tokens []model.APIToken
+}
+
+func (m *memTokenRepo) Create(_ context.Context, t *model.APIToken, _ string) error {
+	m.tokens = append(m.tokens, *t)
+	return nil
+}
+
+func (m *memTokenRepo) ListByUser(_ context.Context, userID string) ([]model.APIToken, error) {
+	var out []model.APIToken
+	for _, t := range m.tokens {
+		if t.UserID == userID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m *memTokenRepo) GetByHash(context.Context, string) (*model.APIToken, error) { return nil, nil }
+
+func (m *memTokenRepo) Revoke(_ context.Context, userID, id string, at time.Time) error {
+	for i := range m.tokens {
+		if m.tokens[i].ID == id && m.tokens[i].UserID == userID && m.tokens[i].RevokedAt == nil {
+			m.tokens[i].RevokedAt = &at
+			return nil
+		}
+	}
+	return service.ErrAPITokenNotFound
+}
+
+func (m *memTokenRepo) CountActive(context.Context, string, time.Time) (int, error) {
+	return len(m.tokens), nil
+}
+
+func (m *memTokenRepo) RecordUse(context.Context, string, time.Time) error { return nil }
+
+func TestAPITokens_CreateListRevoke(t *testing.T) {
+	repo := &memTokenRepo{}
+	deps := APITokenDeps{Svc: service.NewAPITokenService(repo, nil)}
+
+	body := `{"name":"ci","scopes":["read","query"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/tokens", strings.NewReader(body))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "u1"))
+	rec := httptest.NewRecorder()
+	CreateAPIToken(deps).ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Data apiTokenResponse `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if !strings.HasPrefix(created.Data.Secret, model.APITokenPrefix) || created.Data.Token == nil {
+		t.Fatalf("create response = %+v, want token and secret", created.Data)
+	}
+
+	// Listing never returns the secret.
+	req = httptest.NewRequest(http.MethodGet, "/api/tokens", nil)
+	req = req.WithContext(middleware.WithUserID(req.Context(), "u1"))
+	rec = httptest.NewRecorder()
+	ListAPITokens(deps).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), created.Data.Secret) {
+		t.Errorf("list: status = %d, leaked secret = %v", rec.Code, strings.Contains(rec.Body.String(), created.Data.Secret))
+	}
+
+	revoke := func(userID, id string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/api/tokens/x", nil)
+		req = withChiParam(req.WithContext(middleware.WithUserID(req.Context(), userID)), "id", id)
+		rec := httptest.NewRecorder()
+		RevokeAPIToken(deps).ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := revoke("u2", created.Data.Token.ID); code != http.StatusNotFound {
+		t.Errorf("revoke by another user: status = %d, want 404", code)
+	}
+	if code := revoke("u1", "not-a-uuid"); code != http.StatusBadRequest {
+		t.Errorf("revoke bad id: status = %d, want 400", code)
+	}
+	if code := revoke("u1", created.Data.Token.ID); code != http.StatusOK {
+		t.Errorf("revoke: status = %d, want 200", code)
+	}
+}
+
+func TestCreateAPIToken_InvalidScope(t *testing.T) {
+	deps := APITokenDeps{Svc: service.NewAPITokenService(&memTokenRepo{}, nil)}
+	req := httptest.NewRequest(http.MethodPost, "/api/tokens", strings.NewReader(`{"name":"ci","scopes":["write"]}`))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "u1"))
+	rec := httptest.NewRecorder()
+	CreateAPIToken(deps).ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
@@ -2,12 +2,23 @@ package handler
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
 	"github.com/connexus-ai/ragbox-backend/internal/middleware"
 	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/rbac"
 	"github.com/connexus-ai/ragbox-backend/internal/repository"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
@@ -17,10 +28,25 @@ type AuditLister interface {
 	List(ctx context.Context, f repository.ListFilter) ([]model.AuditLog, int, error)
 }
 
+// AuditCheckpoints abstracts signed checkpoint proofs.
+// Implemented by *service.AuditCheckpointer.
+type AuditCheckpoints interface {
+	Proof(ctx context.Context, entryID, userID string) (*auditchain.Bundle, error)
+	SignedBundle(ctx context.Context, entries []model.AuditLog) (*auditchain.SignedBundle, error)
+}
+
+// AuditChainVerifier abstracts range verification of audit hash chains.
+// Implemented by *service.AuditVerifier.
+type AuditChainVerifier interface {
+	Tenant(ctx context.Context, userID string) (string, error)
+	VerifyRange(ctx context.Context, tenantID string, r service.ChainRange) (*service.ChainReport, error)
+}
+
 // AuditDeps bundles dependencies for audit handlers.
 type AuditDeps struct {
-	Lister   AuditLister
-	Verifier *service.AuditService
+	Lister      AuditLister
+	Verifier    AuditChainVerifier
+	Checkpoints AuditCheckpoints // nil when no checkpoint signing key is configured
 }
 
 // ListAudit returns a handler for GET /api/audit.
@@ -38,16 +64,19 @@ func ListAudit(deps AuditDeps) http.HandlerFunc {
 		limit, _ := strconv.Atoi(q.Get("limit"))
 		offset, _ := strconv.Atoi(q.Get("offset"))
 
-		filter := repository.ListFilter{
-			UserID:     userID, // Always scoped to the authenticated user
-			Action:     q.Get("action"),
-			Severity:   q.Get("severity"),
-			DocumentID: q.Get("documentId"),
-			StartDate:  q.Get("startDate"),
-			EndDate:    q.Get("endDate"),
-			Limit:      limit,
-			Offset:     offset,
+		filter, err := auditScope(r.Context(), deps.Verifier, userID)
+		if err != nil {
+			slog.Error("audit scope lookup failed", "user_id", userID, "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to list audit logs"})
+			return
 		}
+		filter.Action = q.Get("action")
+		filter.Severity = q.Get("severity")
+		filter.DocumentID = q.Get("documentId")
+		filter.StartDate = q.Get("startDate")
+		filter.EndDate = q.Get("endDate")
+		filter.Limit = limit
+		filter.Offset = offset
 
 		entries, total, err := deps.Lister.List(r.Context(), filter)
 		if err != nil {
@@ -68,7 +97,10 @@ func ListAudit(deps AuditDeps) http.HandlerFunc {
 }
 
 // ExportAudit returns a handler for GET /api/audit/export.
-// Generates a plain-text report with hash verification summary.
+// Generates a plain-text report with a chain verification summary by
+// default, or with format=csv or format=jsonl the matching entries, or with
+// format=bundle a signed JSON bundle with inclusion proofs that
+// cmd/audit-verify checks offline.
 func ExportAudit(deps AuditDeps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := middleware.UserIDFromContext(r.Context())
@@ -78,16 +110,26 @@ func ExportAudit(deps AuditDeps) http.HandlerFunc {
 		}
 
 		q := r.URL.Query()
+		format := q.Get("format")
+		switch format {
+		case "", "text", "csv", "jsonl", "bundle":
+		default:
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "format must be text, csv, jsonl or bundle"})
+			return
+		}
 
-		filter := repository.ListFilter{
-			UserID:     userID,
-			Action:     q.Get("action"),
-			Severity:   q.Get("severity"),
-			DocumentID: q.Get("documentId"),
-			StartDate:  q.Get("startDate"),
-			EndDate:    q.Get("endDate"),
-			Limit:      10000, // Export all matching entries (capped)
+		filter, err := auditScope(r.Context(), deps.Verifier, userID)
+		if err != nil {
+			slog.Error("audit scope lookup failed", "user_id", userID, "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to fetch audit logs"})
+			return
 		}
+		filter.Action = q.Get("action")
+		filter.Severity = q.Get("severity")
+		filter.DocumentID = q.Get("documentId")
+		filter.StartDate = q.Get("startDate")
+		filter.EndDate = q.Get("endDate")
+		filter.Limit = 10000 // Export all matching entries (capped)
 
 		entries, _, err := deps.Lister.List(r.Context(), filter)
 		if err != nil {
@@ -95,23 +137,20 @@ func ExportAudit(deps AuditDeps) http.HandlerFunc {
 			return
 		}
 
-		// Verify chain integrity if we have entries
-		var chainStatus string
-		if len(entries) >= 2 {
-			result, verifyErr := deps.Verifier.VerifyChain(r.Context(), entries[0].ID, entries[len(entries)-1].ID)
-			if verifyErr != nil {
-				chainStatus = "VERIFICATION_ERROR"
-			} else if result.Valid {
-				chainStatus = fmt.Sprintf("INTACT (%d entries verified)", result.EntriesChecked)
-			} else {
-				chainStatus = fmt.Sprintf("BROKEN at entry %s (index %d)", result.BrokenAt, result.BrokenIndex)
-			}
-		} else if len(entries) == 1 {
-			chainStatus = "INTACT (1 entry)"
-		} else {
-			chainStatus = "NO ENTRIES"
+		switch format {
+		case "bundle":
+			exportAuditBundle(w, r, deps, entries)
+			return
+		case "csv":
+			exportAuditCSV(w, entries)
+			return
+		case "jsonl":
+			exportAuditJSONL(w, entries)
+			return
 		}
 
+		chainStatus, breaks := auditChainStatus(r.Context(), deps.Verifier, entries)
+
 		// Generate plain-text report
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		w.Header().Set("Content-Disposition", "attachment; filename=audit-report.txt")
@@ -119,9 +158,15 @@ func ExportAudit(deps AuditDeps) http.HandlerFunc {
 		fmt.Fprintf(w, "RAGbox.co Audit Report\n")
 		fmt.Fprintf(w, "======================\n\n")
 		fmt.Fprintf(w, "User: %s\n", userID)
+		if filter.TenantID != "" {
+			fmt.Fprintf(w, "Organization: %s\n", filter.TenantID)
+		}
 		fmt.Fprintf(w, "Filters: action=%s severity=%s start=%s end=%s\n",
 			filter.Action, filter.Severity, filter.StartDate, filter.EndDate)
 		fmt.Fprintf(w, "Chain Integrity: %s\n", chainStatus)
+		for _, b := range breaks {
+			fmt.Fprintf(w, "  %s\n", b)
+		}
 		fmt.Fprintf(w, "Total Entries: %d\n\n", len(entries))
 		fmt.Fprintf(w, "%-36s  %-24s  %-8s  %-20s  %s\n",
 			"ID", "Action", "Severity", "Timestamp", "Resource")
@@ -140,3 +185,259 @@ func ExportAudit(deps AuditDeps) http.HandlerFunc {
 		}
 	}
 }
+
+// auditScope returns the filter that limits an audit listing to what the
+// caller may read: their whole organization's chain when their role has
+// the audit permission, else only their own entries.
+func auditScope(ctx context.Context, verifier AuditChainVerifier, userID string) (repository.ListFilter, error) {
+	role := middleware.RoleFromContext(ctx)
+	if role == "" || verifier == nil || !rbac.Can(role, rbac.PermAuditRead) {
+		return repository.ListFilter{UserID: userID}, nil
+	}
+	tenantID, err := verifier.Tenant(ctx, userID)
+	if err != nil {
+		return repository.ListFilter{}, err
+	}
+	return repository.ListFilter{TenantID: tenantID}, nil
+}
+
+// auditChainStatus verifies the chain under the exported entries. Filters
+// skip entries, so rather than the filtered subset it verifies every entry
+// of each chain from the first exported seq to the last, and lists any
+// breaks found.
+func auditChainStatus(ctx context.Context, verifier AuditChainVerifier, entries []model.AuditLog) (string, []string) {
+	if len(entries) == 0 {
+		return "NO ENTRIES", nil
+	}
+
+	type seqRange struct{ from, to int64 }
+	ranges := make(map[string]*seqRange)
+	var tenants []string
+	for _, e := range entries {
+		rng, ok := ranges[e.TenantID]
+		if !ok {
+			ranges[e.TenantID] = &seqRange{e.Seq, e.Seq}
+			tenants = append(tenants, e.TenantID)
+			continue
+		}
+		if e.Seq < rng.from {
+			rng.from = e.Seq
+		}
+		if e.Seq > rng.to {
+			rng.to = e.Seq
+		}
+	}
+	sort.Strings(tenants)
+
+	checked, breakCount := 0, 0
+	var breaks []string
+	for _, tenantID := range tenants {
+		rng := ranges[tenantID]
+		report, err := verifier.VerifyRange(ctx, tenantID, service.ChainRange{FromSeq: rng.from, ToSeq: rng.to})
+		if err != nil {
+			slog.Error("[Audit] export verification failed", "tenant_id", tenantID, "error", err)
+			return "VERIFICATION_ERROR", nil
+		}
+		checked += report.EntriesChecked
+		breakCount += report.BreakCount
+		for _, b := range report.Breaks {
+			breaks = append(breaks, formatChainBreak(tenantID, b))
+		}
+	}
+	if breakCount > 0 {
+		return fmt.Sprintf("BROKEN (%d breaks in %d entries verified)", breakCount, checked), breaks
+	}
+	return fmt.Sprintf("INTACT (%d entries verified)", checked), nil
+}
+
+func formatChainBreak(tenantID string, b auditchain.ChainBreak) string {
+	switch {
+	case b.ToSeq > b.Seq:
+		return fmt.Sprintf("%s seq %d-%d: %s", tenantID, b.Seq, b.ToSeq, b.Reason)
+	case b.EntryID != "":
+		return fmt.Sprintf("%s seq %d (%s): %s", tenantID, b.Seq, b.EntryID, b.Reason)
+	default:
+		return fmt.Sprintf("%s seq %d: %s", tenantID, b.Seq, b.Reason)
+	}
+}
+
+// auditCSVHeader lists the columns of a CSV export.
+var auditCSVHeader = []string{
+	"id", "tenantId", "seq", "createdAt", "userId", "action", "severity", "resourceType", "resourceId",
+	"ipAddress", "userAgent", "prevHash", "detailsHash", "details",
+}
+
+// exportAuditCSV writes entries as CSV, one row per entry.
+func exportAuditCSV(w http.ResponseWriter, entries []model.AuditLog) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=audit-export.csv")
+
+	cw := csv.NewWriter(w)
+	cw.Write(auditCSVHeader)
+	for _, e := range entries {
+		cw.Write([]string{
+			csvCell(e.ID), csvCell(e.TenantID), strconv.FormatInt(e.Seq, 10),
+			e.CreatedAt.UTC().Format(time.RFC3339Nano), csvCell(ptrStr(e.UserID)),
+			csvCell(e.Action), csvCell(e.Severity), csvCell(ptrStr(e.ResourceType)),
+			csvCell(ptrStr(e.ResourceID)), csvCell(ptrStr(e.IPAddress)),
+			csvCell(ptrStr(e.UserAgent)), csvCell(ptrStr(e.PrevHash)),
+			csvCell(ptrStr(e.DetailsHash)), csvCell(string(e.Details)),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		slog.Error("[Audit] csv export failed", "error", err)
+	}
+}
+
+// csvCell neutralises cells a spreadsheet would evaluate as a formula;
+// user agents, resource IDs and details are caller-controlled.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// exportAuditJSONL writes entries as JSON lines in the bundle entry form,
+// which keeps the exact stored details each hash covers.
+func exportAuditJSONL(w http.ResponseWriter, entries []model.AuditLog) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", "attachment; filename=audit-export.jsonl")
+
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(auditchain.NewBundleEntry(e, nil)); err != nil {
+			slog.Error("[Audit] jsonl export failed", "error", err)
+			return
+		}
+	}
+}
+
+// exportAuditBundle writes entries as a signed, verifiable audit bundle.
+func exportAuditBundle(w http.ResponseWriter, r *http.Request, deps AuditDeps, entries []model.AuditLog) {
+	if deps.Checkpoints == nil {
+		respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "audit checkpoints are not enabled"})
+		return
+	}
+	bundle, err := deps.Checkpoints.SignedBundle(r.Context(), entries)
+	if err != nil {
+		slog.Error("[Audit] bundle export failed", "error", err)
+		respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to build audit bundle"})
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename=audit-bundle.json")
+	respondJSON(w, http.StatusOK, bundle)
+}
+
+// VerifyAudit returns a handler for GET /api/audit/verify.
+// Verifies every entry of the caller's audit chain in a range and reports
+// each break found. Supports query params: fromSeq, toSeq, startDate,
+// endDate; with none, the whole chain is verified.
+func VerifyAudit(deps AuditDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		q := r.URL.Query()
+		var rng service.ChainRange
+		var err error
+		if rng.FromSeq, err = parseSeqParam(q.Get("fromSeq")); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "fromSeq must be a positive integer"})
+			return
+		}
+		if rng.ToSeq, err = parseSeqParam(q.Get("toSeq")); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "toSeq must be a positive integer"})
+			return
+		}
+		if rng.Start, err = parseAuditTime(q.Get("startDate")); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "startDate must be an ISO 8601 date or timestamp"})
+			return
+		}
+		if rng.End, err = parseAuditTime(q.Get("endDate")); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "endDate must be an ISO 8601 date or timestamp"})
+			return
+		}
+
+		tenantID, err := deps.Verifier.Tenant(r.Context(), userID)
+		if err != nil {
+			slog.Error("[Audit] verify tenant lookup failed", "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to verify audit chain"})
+			return
+		}
+		report, err := deps.Verifier.VerifyRange(r.Context(), tenantID, rng)
+		switch {
+		case errors.Is(err, service.ErrInvalidChainRange):
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "toSeq must not be before fromSeq"})
+		case err != nil:
+			slog.Error("[Audit] verify failed", "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to verify audit chain"})
+		default:
+			respondJSON(w, http.StatusOK, envelope{Success: true, Data: report})
+		}
+	}
+}
+
+func parseSeqParam(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 1 {
+		return 0, errors.New("invalid seq")
+	}
+	return n, nil
+}
+
+// parseAuditTime accepts the RFC 3339 timestamps and dates the list
+// filters take; a date is midnight UTC.
+func parseAuditTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// AuditProof returns a handler for GET /api/audit/{id}/proof.
+// Responds with a one-entry audit bundle: the entry, its Merkle inclusion
+// proof and the signed checkpoint it is anchored in.
+func AuditProof(deps AuditDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+		if deps.Checkpoints == nil {
+			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "audit checkpoints are not enabled"})
+			return
+		}
+
+		bundle, err := deps.Checkpoints.Proof(r.Context(), chi.URLParam(r, "id"), userID)
+		switch {
+		case errors.Is(err, service.ErrAuditEntryNotFound):
+			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "audit entry not found"})
+		case errors.Is(err, service.ErrAuditNotAnchored):
+			respondJSON(w, http.StatusConflict, envelope{Success: false, Error: "audit entry is not covered by a checkpoint yet"})
+		case err != nil:
+			slog.Error("[Audit] proof failed", "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to build proof"})
+		default:
+			respondJSON(w, http.StatusOK, envelope{Success: true, Data: bundle})
+		}
+	}
+}
+
+func ptrStr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
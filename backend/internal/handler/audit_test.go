@@ -2,13 +2,18 @@ package handler
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
 	"github.com/connexus-ai/ragbox-backend/internal/middleware"
 	"github.com/connexus-ai/ragbox-backend/internal/model"
 	"github.com/connexus-ai/ragbox-backend/internal/repository"
@@ -20,27 +25,41 @@ type stubAuditLister struct {
 	entries []model.AuditLog
 	total   int
 	err     error
+	filter  repository.ListFilter // last filter listed
 }
 
 func (s *stubAuditLister) List(ctx context.Context, f repository.ListFilter) ([]model.AuditLog, int, error) {
+	s.filter = f
 	if s.err != nil {
 		return nil, 0, s.err
 	}
 	return s.entries, s.total, nil
 }
 
-// stubAuditRepo implements service.AuditRepository for wiring AuditService.
-type stubAuditRepo struct {
-	latestHash   string
-	rangeEntries []model.AuditLog
+// stubChainVerifier implements AuditChainVerifier for testing.
+type stubChainVerifier struct {
+	report   *service.ChainReport
+	err      error
+	verified map[string]service.ChainRange // tenant -> last range verified
 }
 
-func (s *stubAuditRepo) Create(ctx context.Context, entry *model.AuditLog) error { return nil }
-func (s *stubAuditRepo) GetLatestHash(ctx context.Context) (string, error) {
-	return s.latestHash, nil
+func (s *stubChainVerifier) Tenant(ctx context.Context, userID string) (string, error) {
+	return "org-" + userID, nil
 }
-func (s *stubAuditRepo) GetRange(ctx context.Context, startID, endID string) ([]model.AuditLog, error) {
-	return s.rangeEntries, nil
+
+func (s *stubChainVerifier) VerifyRange(ctx context.Context, tenantID string, r service.ChainRange) (*service.ChainReport, error) {
+	if s.verified == nil {
+		s.verified = map[string]service.ChainRange{}
+	}
+	s.verified[tenantID] = r
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.report != nil {
+		return s.report, nil
+	}
+	return &service.ChainReport{TenantID: tenantID, FromSeq: r.FromSeq, ToSeq: r.ToSeq,
+		EntriesChecked: int(r.ToSeq - r.FromSeq + 1), Valid: true}, nil
 }
 
 func testAuditEntries() []model.AuditLog {
@@ -52,12 +71,12 @@ func testAuditEntries() []model.AuditLog {
 
 	return []model.AuditLog{
 		{
-			ID: "entry-1", UserID: &userID, Action: model.AuditDocumentUpload,
+			ID: "entry-1", TenantID: "org-test-user", Seq: 1, UserID: &userID, Action: model.AuditDocumentUpload,
 			ResourceID: &docID, ResourceType: &docType, Severity: "LOW",
 			DetailsHash: &hash1, CreatedAt: time.Now().Add(-time.Hour),
 		},
 		{
-			ID: "entry-2", UserID: &userID, Action: model.AuditQueryExecuted,
+			ID: "entry-2", TenantID: "org-test-user", Seq: 3, UserID: &userID, Action: model.AuditQueryExecuted,
 			Severity: "LOW", DetailsHash: &hash2, CreatedAt: time.Now(),
 		},
 	}
@@ -70,11 +89,9 @@ func auditRequest(path string) *http.Request {
 }
 
 func makeAuditDeps(lister *stubAuditLister) AuditDeps {
-	repo := &stubAuditRepo{rangeEntries: lister.entries}
-	verifier, _ := service.NewAuditService(repo, nil)
 	return AuditDeps{
 		Lister:   lister,
-		Verifier: verifier,
+		Verifier: &stubChainVerifier{},
 	}
 }
 
@@ -106,6 +123,40 @@ func TestListAudit_Success(t *testing.T) {
 	}
 }
 
+func TestListAudit_ScopeByRole(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		role       string
+		wantTenant string
+		wantUser   string
+	}{
+		{"auditor lists the organization", "Auditor", "org-test-user", ""},
+		{"partner lists the organization", "Partner", "org-test-user", ""},
+		{"no resolved role stays personal", "", "", "test-user"},
+		{"unknown role stays personal", "Guest", "", "test-user"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, h := range []func(AuditDeps) http.HandlerFunc{ListAudit, ExportAudit} {
+				lister := &stubAuditLister{}
+				req := auditRequest("/api/audit")
+				if tt.role != "" {
+					req = req.WithContext(middleware.WithRole(req.Context(), tt.role))
+				}
+				w := httptest.NewRecorder()
+				h(makeAuditDeps(lister)).ServeHTTP(w, req)
+
+				if w.Code != http.StatusOK {
+					t.Fatalf("status = %d, want 200", w.Code)
+				}
+				if lister.filter.TenantID != tt.wantTenant || lister.filter.UserID != tt.wantUser {
+					t.Errorf("filter tenant=%q user=%q, want tenant=%q user=%q",
+						lister.filter.TenantID, lister.filter.UserID, tt.wantTenant, tt.wantUser)
+				}
+			}
+		})
+	}
+}
+
 func TestListAudit_WithFilters(t *testing.T) {
 	lister := &stubAuditLister{entries: nil, total: 0}
 	deps := makeAuditDeps(lister)
@@ -197,3 +248,250 @@ func TestExportAudit_Unauthorized(t *testing.T) {
 		t.Errorf("status = %d, want 401", w.Code)
 	}
 }
+
+// stubCheckpoints implements AuditCheckpoints for testing.
+type stubCheckpoints struct {
+	key      ed25519.PrivateKey
+	proofErr error
+	gotEntry string
+	gotUser  string
+	bundled  []model.AuditLog
+}
+
+func (s *stubCheckpoints) Proof(ctx context.Context, entryID, userID string) (*auditchain.Bundle, error) {
+	s.gotEntry, s.gotUser = entryID, userID
+	if s.proofErr != nil {
+		return nil, s.proofErr
+	}
+	return &auditchain.Bundle{Version: auditchain.BundleVersion}, nil
+}
+
+func (s *stubCheckpoints) SignedBundle(ctx context.Context, entries []model.AuditLog) (*auditchain.SignedBundle, error) {
+	s.bundled = entries
+	b := &auditchain.Bundle{Version: auditchain.BundleVersion, Checkpoints: []model.AuditCheckpoint{}}
+	for _, e := range entries {
+		b.Entries = append(b.Entries, auditchain.NewBundleEntry(e, nil))
+	}
+	return auditchain.SignBundle(b, s.key)
+}
+
+func TestAuditProof(t *testing.T) {
+	tests := []struct {
+		name   string
+		cps    *stubCheckpoints
+		status int
+	}{
+		{"anchored", &stubCheckpoints{}, http.StatusOK},
+		{"not found or not the caller's", &stubCheckpoints{proofErr: service.ErrAuditEntryNotFound}, http.StatusNotFound},
+		{"not yet checkpointed", &stubCheckpoints{proofErr: service.ErrAuditNotAnchored}, http.StatusConflict},
+		{"store failure", &stubCheckpoints{proofErr: errors.New("db down")}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			AuditProof(AuditDeps{Checkpoints: tt.cps}).ServeHTTP(w, withChiParam(auditRequest("/api/audit/entry-1/proof"), "id", "entry-1"))
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.cps.gotEntry != "entry-1" || tt.cps.gotUser != "test-user" {
+				t.Errorf("Proof(%q, %q), want entry-1 for test-user", tt.cps.gotEntry, tt.cps.gotUser)
+			}
+		})
+	}
+}
+
+func TestAuditProof_CheckpointsDisabled(t *testing.T) {
+	w := httptest.NewRecorder()
+	AuditProof(AuditDeps{}).ServeHTTP(w, withChiParam(auditRequest("/api/audit/entry-1/proof"), "id", "entry-1"))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestExportAudit_Bundle(t *testing.T) {
+	entries := testAuditEntries()
+	entries[0].Details = json.RawMessage(`{"note":"<a&b>"}`)
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	cps := &stubCheckpoints{key: key}
+	deps := makeAuditDeps(&stubAuditLister{entries: entries, total: 2})
+	deps.Checkpoints = cps
+
+	w := httptest.NewRecorder()
+	ExportAudit(deps).ServeHTTP(w, auditRequest("/api/audit/export?format=bundle"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if len(cps.bundled) != 2 {
+		t.Errorf("bundled %d entries, want 2", len(cps.bundled))
+	}
+	// The signed bundle is the whole body, and the signature still holds
+	// once written out, so the file verifies as downloaded.
+	var sb auditchain.SignedBundle
+	if err := json.Unmarshal(w.Body.Bytes(), &sb); err != nil || sb.Format != auditchain.SignedBundleFormat {
+		t.Fatalf("body is not a signed bundle: %v", err)
+	}
+	if err := sb.VerifySignature(pub); err != nil {
+		t.Errorf("VerifySignature: %v", err)
+	}
+}
+
+func TestExportAudit_VerifiesWholeChainRange(t *testing.T) {
+	verifier := &stubChainVerifier{}
+	deps := makeAuditDeps(&stubAuditLister{entries: testAuditEntries(), total: 2})
+	deps.Verifier = verifier
+
+	w := httptest.NewRecorder()
+	ExportAudit(deps).ServeHTTP(w, auditRequest("/api/audit/export?action=QUERY_EXECUTED"))
+
+	// The exported entries are seq 1 and 3: seq 2, skipped by the filter,
+	// must be verified too.
+	if got := verifier.verified["org-test-user"]; got.FromSeq != 1 || got.ToSeq != 3 {
+		t.Errorf("verified %+v, want seq 1..3", got)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "INTACT (3 entries verified)") {
+		t.Errorf("report missing chain status:\n%s", body)
+	}
+}
+
+func TestExportAudit_ListsChainBreaks(t *testing.T) {
+	deps := makeAuditDeps(&stubAuditLister{entries: testAuditEntries(), total: 2})
+	deps.Verifier = &stubChainVerifier{report: &service.ChainReport{
+		EntriesChecked: 2, BreakCount: 2,
+		Breaks: []auditchain.ChainBreak{
+			{Seq: 2, ToSeq: 2, Reason: auditchain.BreakMissing},
+			{Seq: 3, EntryID: "entry-2", Reason: auditchain.BreakLink},
+		},
+	}}
+
+	w := httptest.NewRecorder()
+	ExportAudit(deps).ServeHTTP(w, auditRequest("/api/audit/export"))
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"BROKEN (2 breaks in 2 entries verified)",
+		"org-test-user seq 2: " + auditchain.BreakMissing,
+		"org-test-user seq 3 (entry-2): " + auditchain.BreakLink,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("report missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestExportAudit_CSV(t *testing.T) {
+	entries := testAuditEntries()
+	agent := "=HYPERLINK(\"http://evil\")"
+	entries[0].UserAgent = &agent
+	deps := makeAuditDeps(&stubAuditLister{entries: entries, total: 2})
+
+	w := httptest.NewRecorder()
+	ExportAudit(deps).ServeHTTP(w, auditRequest("/api/audit/export?format=csv"))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("rows = %d, %v; want header and 2 entries", len(rows), err)
+	}
+	if rows[0][0] != "id" || rows[1][0] != "entry-1" || rows[1][2] != "1" || rows[1][12] != "abc123" {
+		t.Errorf("rows = %q", rows[:2])
+	}
+	// Spreadsheets must not evaluate caller-controlled cells.
+	if rows[1][10] != "'"+agent {
+		t.Errorf("userAgent cell = %q, want it escaped", rows[1][10])
+	}
+}
+
+func TestExportAudit_JSONL(t *testing.T) {
+	entries := testAuditEntries()
+	entries[0].Details = json.RawMessage(`{"b":1,"a":"<x>"}`)
+	deps := makeAuditDeps(&stubAuditLister{entries: entries, total: 2})
+
+	w := httptest.NewRecorder()
+	ExportAudit(deps).ServeHTTP(w, auditRequest("/api/audit/export?format=jsonl"))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d, want 2", len(lines))
+	}
+	var be auditchain.BundleEntry
+	if err := json.Unmarshal([]byte(lines[0]), &be); err != nil {
+		t.Fatal(err)
+	}
+	// Details keep their stored bytes, which the entry hash covers.
+	if be.ID != "entry-1" || be.Seq != 1 || be.Details != `{"b":1,"a":"<x>"}` {
+		t.Errorf("entry = %+v", be)
+	}
+}
+
+func TestExportAudit_UnknownFormat(t *testing.T) {
+	w := httptest.NewRecorder()
+	ExportAudit(makeAuditDeps(&stubAuditLister{})).ServeHTTP(w, auditRequest("/api/audit/export?format=xml"))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestVerifyAudit(t *testing.T) {
+	verifier := &stubChainVerifier{}
+	deps := AuditDeps{Verifier: verifier}
+
+	w := httptest.NewRecorder()
+	VerifyAudit(deps).ServeHTTP(w, auditRequest("/api/audit/verify?fromSeq=10&toSeq=20&startDate=2025-01-01&endDate=2025-02-01T12:00:00Z"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	got := verifier.verified["org-test-user"]
+	want := service.ChainRange{
+		FromSeq: 10, ToSeq: 20,
+		Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if got.FromSeq != want.FromSeq || got.ToSeq != want.ToSeq || !got.Start.Equal(want.Start) || !got.End.Equal(want.End) {
+		t.Errorf("verified %+v, want %+v", got, want)
+	}
+	var resp struct {
+		Success bool                `json:"success"`
+		Data    service.ChainReport `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || !resp.Success || !resp.Data.Valid || resp.Data.EntriesChecked != 11 {
+		t.Errorf("response = %+v, %v", resp, err)
+	}
+}
+
+func TestVerifyAudit_BadRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		err   error
+	}{
+		{"non-numeric seq", "?fromSeq=abc", nil},
+		{"zero seq", "?toSeq=0", nil},
+		{"bad date", "?startDate=yesterday", nil},
+		{"inverted range", "?fromSeq=5&toSeq=2", service.ErrInvalidChainRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			VerifyAudit(AuditDeps{Verifier: &stubChainVerifier{err: tt.err}}).ServeHTTP(w, auditRequest("/api/audit/verify"+tt.query))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", w.Code)
+			}
+		})
+	}
+}
+
+func TestVerifyAudit_Unauthorized(t *testing.T) {
+	w := httptest.NewRecorder()
+	VerifyAudit(AuditDeps{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/audit/verify", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/cache"
+)
+
+// CacheAdminDeps holds dependencies for the cache admin handlers.
+type CacheAdminDeps struct {
+	// Stats returns a snapshot of every configured cache layer.
+	Stats func() []cache.Stats
+	// Invalidator flushes a user's entries on every instance.
+	Invalidator *cache.Invalidator
+}
+
+// CacheStats returns hit, miss, eviction and size counters per cache layer.
+// GET /api/admin/cache/stats
+func CacheStats(deps CacheAdminDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		layers := []cache.Stats{}
+		if deps.Stats != nil {
+			layers = append(layers, deps.Stats()...)
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: map[string]interface{}{
+			"layers": layers,
+		}})
+	}
+}
+
+// FlushUserCache drops every cached retrieval and answer for a user, on all
+// instances. Embeddings are keyed by query text alone and are not flushed.
+// DELETE /api/admin/cache/users/{userId}
+func FlushUserCache(deps CacheAdminDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := strings.TrimSpace(chi.URLParam(r, "userId"))
+		if userID == "" || strings.Contains(userID, ":") {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid user ID"})
+			return
+		}
+		if deps.Invalidator == nil {
+			respondJSON(w, http.StatusServiceUnavailable, envelope{Success: false, Error: "caching is not enabled"})
+			return
+		}
+
+		deps.Invalidator.InvalidateUser(r.Context(), userID)
+		slog.Info("[CACHE] admin flush", "user_id", userID)
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: map[string]interface{}{
+			"userId":  userID,
+			"flushed": true,
+		}})
+	}
+}
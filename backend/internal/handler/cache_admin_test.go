@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/cache"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func TestCacheStats_ReturnsLayers(t *testing.T) {
+	qc := cache.New(time.Hour)
+	defer qc.Stop()
+	qc.Get("user-1", "q", false)
+
+	h := CacheStats(CacheAdminDeps{Stats: func() []cache.Stats { return []cache.Stats{qc.Stats()} }})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/cache/stats", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp struct {
+		Data struct {
+			Layers []cache.Stats `json:"layers"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Data.Layers) != 1 || resp.Data.Layers[0].Layer != "query" || resp.Data.Layers[0].Misses != 1 {
+		t.Errorf("layers = %+v", resp.Data.Layers)
+	}
+}
+
+func TestFlushUserCache(t *testing.T) {
+	qc := cache.New(time.Hour)
+	defer qc.Stop()
+	qc.Set("user-1", "q", false, &service.RetrievalResult{})
+	qc.Set("user-2", "q", false, &service.RetrievalResult{})
+
+	h := FlushUserCache(CacheAdminDeps{Invalidator: cache.NewInvalidator(qc, nil, nil)})
+	req := withChiParam(httptest.NewRequest(http.MethodDelete, "/api/admin/cache/users/user-1", nil), "userId", "user-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if _, ok := qc.Get("user-1", "q", false); ok {
+		t.Error("user-1 entries should be flushed")
+	}
+	if _, ok := qc.Get("user-2", "q", false); !ok {
+		t.Error("user-2 entries must survive")
+	}
+}
+
+func TestFlushUserCache_InvalidUser(t *testing.T) {
+	h := FlushUserCache(CacheAdminDeps{Invalidator: cache.NewInvalidator(nil, nil, nil)})
+	req := withChiParam(httptest.NewRequest(http.MethodDelete, "/api/admin/cache/users/x", nil), "userId", "a:b")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
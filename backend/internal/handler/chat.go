@@ -59,6 +59,9 @@ type DoneEvidence struct {
 	Model                  string  `json:"model"`
 	LatencyMs              int64   `json:"latencyMs"`
 	CitationCount          int     `json:"citationCount"`
+	// PIIRedaction records the policies applied to the caller and what they
+	// redacted (nil = no PII redaction service configured).
+	PIIRedaction *service.PIIRedactionEvidence `json:"piiRedaction,omitempty"`
 }
 
 // buildDonePayload constructs a DonePayload from nullable pipeline outputs.
@@ -185,6 +188,8 @@ type ChatRequest struct {
 	WebContext string `json:"webContext,omitempty"`
 	// Document scope: when set, retrieval results are filtered to this document only (E24-001)
 	DocumentScope string `json:"documentScope,omitempty"`
+	// Vault scope: when set, retrieval is limited to this vault's documents
+	VaultID string `json:"vaultId,omitempty"`
 	// BYOLLM fields (optional — absent means use AEGIS/Vertex AI)
 	LLMProvider string `json:"llmProvider,omitempty"`
 	LLMModel    string `json:"llmModel,omitempty"`
@@ -218,7 +223,7 @@ type PersonaFetcher interface {
 type CortexSearcher interface {
 	Search(ctx context.Context, tenantID, query string, limit int) ([]model.CortexEntry, error)
 	GetActiveInstructions(ctx context.Context, tenantID string) ([]model.CortexEntry, error)
-	Ingest(ctx context.Context, tenantID, content, sourceChannel string, sourceMessageID *string, isInstruction bool) error
+	Ingest(ctx context.Context, tenantID, content, sourceChannel string, sourceMessageID *string, isInstruction bool, sourceDocumentIDs ...string) error
 }
 
 // ChatDeps bundles the services needed by the chat handler.
@@ -241,10 +246,24 @@ type ChatDeps struct {
 	QueryCache     *cache.QueryCache // optional — nil disables retrieval caching
 	EmbedCache     *cache.EmbeddingCache // optional — nil disables embedding caching
 	RedisCache     *cache.RedisCache // optional — nil disables Redis L2 caching (EPIC-028)
+	SemanticCache  *cache.SemanticCache // optional — nil disables near-duplicate answer reuse
 	UsageSvc       *service.UsageService // optional — nil disables usage metering
 	UserTierFunc   func(ctx context.Context, userID string) string // optional — returns user's subscription tier
 	DocStatus      DocumentStatusChecker // optional — STORY-172: check if docs are still processing
 	PrivilegeState *PrivilegeState // STORY-S01 Gap 3: server-side privilege state (ignores request body)
+	Vaults         VaultQueryChecker // optional — nil rejects vault-scoped queries
+	PII            *service.PIIRedactionService // optional — nil disables PII redaction
+	AuditLogger    ChatAuditLogger // optional — nil disables QUERY_EXECUTED entries
+}
+
+// ChatAuditLogger records audit entries. Implemented by *service.AuditService.
+type ChatAuditLogger interface {
+	LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error
+}
+
+// VaultQueryChecker reports whether a user may query a vault's documents.
+type VaultQueryChecker interface {
+	CanQuery(ctx context.Context, vaultID, userID string) (bool, error)
 }
 
 // selfRAGSkipThreshold: skip SelfRAG reflection when initial confidence is above this.
@@ -412,67 +431,73 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 				usageTier = deps.UserTierFunc(ctx, userID)
 			}
 
-			// Check query count limit
-			allowed, currentCount, limit, err := deps.UsageSvc.CheckLimit(ctx, userID, "aegis_queries", usageTier)
-			if err != nil {
-				slog.Error("usage check failed", "user_id", userID, "error", err)
-				// Allow query on metering error — don't block on infra failure
-			} else if !allowed {
-				slog.Warn("usage limit reached", "user_id", userID, "tier", usageTier, "count", currentCount, "limit", limit)
-				limitMsg := fmt.Sprintf(`{"error":"monthly_limit_reached","message":"You've used %d of %d AEGIS queries this month. Upgrade your plan to continue.","used":%d,"limit":%d}`, currentCount, limit, currentCount, limit)
-				sendEvent(w, flusher, "error", limitMsg)
-				sendEvent(w, flusher, "done", `{}`)
-				return
+			// Check the query count, then the token budget. A request already
+			// running is allowed to finish — enforcement blocks the NEXT one.
+			for _, metric := range []string{service.MetricAegisQueries, service.MetricTokensUsed} {
+				decision, err := deps.UsageSvc.CheckQuota(ctx, userID, usageTier, metric, 1)
+				var qe *service.QuotaError
+				switch {
+				case errors.As(err, &qe):
+					slog.Warn("usage limit reached", "user_id", userID, "tier", usageTier,
+						"metric", metric, "used", qe.Used, "limit", qe.Limit)
+					body, _ := json.Marshal(qe.Body())
+					sendEvent(w, flusher, "error", string(body))
+					sendEvent(w, flusher, "done", `{}`)
+					return
+				case err != nil:
+					// Allow query on metering error — don't block on infra failure
+					slog.Error("usage check failed", "user_id", userID, "metric", metric, "error", err)
+				default:
+					setQuotaWarning(w, decision)
+				}
 			}
+		}
 
-			// STORY-199: Check token budget limit before processing.
-			// Current request is allowed to complete — enforcement blocks the NEXT request.
-			tokenAllowed, tokensUsed, tokenBudget, tokenErr := deps.UsageSvc.CheckTokenLimit(ctx, userID, usageTier)
-			if tokenErr != nil {
-				slog.Error("token limit check failed", "user_id", userID, "error", tokenErr)
-				// Allow query on metering error — don't block on infra failure
-			} else if !tokenAllowed {
-				slog.Warn("token budget exhausted",
-					"user_id", userID, "tier", usageTier,
-					"tokens_used", tokensUsed, "token_budget", tokenBudget)
-				limitMsg := fmt.Sprintf(`{"error":"token_budget_exhausted","message":"You've used %d of %d tokens this month. Upgrade your plan to continue.","used":%d,"limit":%d}`,
-					tokensUsed, tokenBudget, tokensUsed, tokenBudget)
-				sendEvent(w, flusher, "error", limitMsg)
+		// Vault scope: reject vaults the caller cannot query (archived, or
+		// closed/secure to them) before any cache lookup could answer.
+		if req.VaultID != "" {
+			allowed := false
+			if deps.Vaults != nil && validateVaultID(req.VaultID) {
+				var err error
+				allowed, err = deps.Vaults.CanQuery(ctx, req.VaultID, userID)
+				if err != nil {
+					slog.Error("vault access check failed", "user_id", userID, "vault_id", req.VaultID, "error", err)
+				}
+			}
+			if !allowed {
+				sendEvent(w, flusher, "error", `{"message":"vault not found or access denied"}`)
 				sendEvent(w, flusher, "done", `{}`)
 				return
 			}
 		}
+		cacheQuery := cache.VaultScopedQuery(req.VaultID, req.Query)
 
 		// EPIC-028: Fast-path — check Redis for a cached full response before any work.
 		// This returns the final answer in <500ms for repeated identical queries.
 		if deps.RedisCache != nil {
-			if cachedResp, ok := deps.RedisCache.GetResponse(ctx, userID, req.Query, privilegeMode); ok {
+			cachedResp, ok := deps.RedisCache.GetResponse(ctx, userID, cacheQuery, privilegeMode)
+			var piiEvidence *service.PIIRedactionEvidence
+			if ok {
+				piiEvidence, ok = cachedAnswerRedaction(ctx, deps.PII, userID, privilegeMode, cachedResp)
+			}
+			if ok {
 				w.Header().Set("X-Cache", "HIT")
 				fastTTFB := time.Since(startTime).Milliseconds()
-				// Stream the cached answer as token events
-				answerTokens := splitIntoTokens(sanitizeAnswer(cachedResp.Answer))
-				for _, token := range answerTokens {
-					if ctx.Err() != nil {
-						return
-					}
-					tokenJSON, _ := json.Marshal(map[string]string{"text": token})
-					sendEvent(w, flusher, "token", string(tokenJSON))
-				}
-				// Send confidence + citations
-				confJSON, _ := json.Marshal(map[string]interface{}{"confidence": cachedResp.Confidence})
-				sendEvent(w, flusher, "confidence", string(confJSON))
-				if len(cachedResp.Citations) > 0 {
-					citJSON, _ := json.Marshal(cachedResp.Citations)
-					sendEvent(w, flusher, "citations", string(citJSON))
+				if !streamCachedAnswer(ctx, w, flusher, cachedResp.Result) {
+					return
 				}
 				donePayload := map[string]interface{}{
 					"totalMs":   time.Since(startTime).Milliseconds(),
 					"ttfbMs":    fastTTFB,
 					"cached":    true,
-					"modelUsed": cachedResp.ModelUsed,
+					"modelUsed": cachedResp.Result.ModelUsed,
+				}
+				if piiEvidence != nil {
+					donePayload["evidence"] = DoneEvidence{PIIRedaction: piiEvidence}
 				}
 				doneJSON, _ := json.Marshal(donePayload)
 				sendEvent(w, flusher, "done", string(doneJSON))
+				auditQuery(ctx, deps.AuditLogger, userID, req, "cached")
 				slog.Info("[Chat] Redis response cache hit",
 					"user_id", userID,
 					"ttfb_ms", fastTTFB,
@@ -493,14 +518,21 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 
 		// Run cache check and embedding in parallel via errgroup
 		var queryVec []float32
-		queryHash := cache.EmbeddingQueryHash(req.Query)
+		// Snapshot the embedding binding so the query vector, its cache key and
+		// the search filter all belong to the same embedding space.
+		binding := deps.Retriever.Binding()
+		var spaceKey string
+		if !binding.Space.IsZero() {
+			spaceKey = binding.Space.Key()
+		}
+		queryHash := cache.EmbeddingSpaceQueryHash(spaceKey, req.Query)
 
 		g, gCtx := errgroup.WithContext(ctx)
 
 		// Goroutine 1: check query result cache (L1 in-memory → L2 Redis)
 		g.Go(func() error {
 			if deps.QueryCache != nil {
-				if cached, ok := deps.QueryCache.Get(userID, req.Query, privilegeMode); ok {
+				if cached, ok := deps.QueryCache.Get(userID, cacheQuery, privilegeMode); ok {
 					retrieval = cached
 					cacheHit = true
 					return nil
@@ -508,11 +540,11 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 			}
 			// EPIC-028: L2 Redis fallback for retrieval results
 			if deps.RedisCache != nil {
-				if cached, ok := deps.RedisCache.GetRetrieval(gCtx, userID, req.Query, privilegeMode); ok {
+				if cached, ok := deps.RedisCache.GetRetrieval(gCtx, userID, cacheQuery, privilegeMode); ok {
 					retrieval = cached
 					cacheHit = true
 					if deps.QueryCache != nil {
-						deps.QueryCache.Set(userID, req.Query, privilegeMode, cached) // backfill L1
+						deps.QueryCache.Set(userID, cacheQuery, privilegeMode, cached) // backfill L1
 					}
 				}
 			}
@@ -539,7 +571,7 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 					return nil
 				}
 			}
-			vecs, err := deps.Retriever.Embedder().Embed(gCtx, []string{req.Query})
+			vecs, err := binding.Embedder.Embed(gCtx, []string{req.Query})
 			if err != nil {
 				return err
 			}
@@ -562,11 +594,59 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 
 		tEmbedEnd := time.Now()
 
+		// Semantic cache: reuse the answer to a near-duplicate query the user
+		// asked recently with the same privilege mode and filters.
+		semanticOK := deps.SemanticCache != nil && semanticCacheable(req)
+		semanticScope := cache.SemanticScope{
+			UserID:        userID,
+			PrivilegeMode: privilegeMode,
+			Filters:       semanticFilterKey(req, personaKey),
+			Space:         spaceKey,
+		}
+		if semanticOK {
+			match, ok := deps.SemanticCache.Lookup(semanticScope, queryVec)
+			var piiEvidence *service.PIIRedactionEvidence
+			if ok {
+				piiEvidence, ok = cachedAnswerRedaction(ctx, deps.PII, userID, privilegeMode, match.Answer)
+			}
+			if ok {
+				w.Header().Set("X-Cache", "HIT")
+				fastTTFB := time.Since(startTime).Milliseconds()
+				if !streamCachedAnswer(ctx, w, flusher, match.Answer.Result) {
+					return
+				}
+				donePayload := map[string]interface{}{
+					"totalMs":      time.Since(startTime).Milliseconds(),
+					"ttfbMs":       fastTTFB,
+					"cached":       "semantic",
+					"matchedQuery": match.Query,
+					"similarity":   match.Similarity,
+					"modelUsed":    match.Answer.Result.ModelUsed,
+				}
+				if piiEvidence != nil {
+					donePayload["evidence"] = DoneEvidence{PIIRedaction: piiEvidence}
+				}
+				doneJSON, _ := json.Marshal(donePayload)
+				sendEvent(w, flusher, "done", string(doneJSON))
+				auditQuery(ctx, deps.AuditLogger, userID, req, "semantic_cache")
+				slog.Info("[Chat] semantic cache hit",
+					"user_id", userID,
+					"matched_query", truncate(match.Query, 100),
+					"ttfb_ms", fastTTFB,
+				)
+				return
+			}
+		}
+
 		// If result cache hit, skip retrieval entirely
 		if retrieval == nil {
 			tSearchStart := time.Now()
 			var err error
-			retrieval, err = deps.Retriever.RetrieveWithVec(ctx, userID, req.Query, queryVec, privilegeMode)
+			if req.VaultID != "" {
+				retrieval, err = deps.Retriever.RetrieveInVault(ctx, binding.Space, userID, req.VaultID, req.Query, queryVec, privilegeMode)
+			} else {
+				retrieval, err = deps.Retriever.RetrieveInSpace(ctx, binding.Space, userID, req.Query, queryVec, privilegeMode)
+			}
 			if err != nil {
 				slog.Error("chat retrieval failed", "user_id", userID, "stage", "retrieval", "error", err)
 				sendEvent(w, flusher, "error", fmt.Sprintf(`{"message":%q}`, rateLimitMessage(err)))
@@ -575,10 +655,10 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 			}
 			_ = tSearchStart // used in latency log below
 			if deps.QueryCache != nil {
-				deps.QueryCache.Set(userID, req.Query, privilegeMode, retrieval)
+				deps.QueryCache.Set(userID, cacheQuery, privilegeMode, retrieval)
 			}
 			if deps.RedisCache != nil {
-				deps.RedisCache.SetRetrieval(ctx, userID, req.Query, privilegeMode, retrieval)
+				deps.RedisCache.SetRetrieval(ctx, userID, cacheQuery, privilegeMode, retrieval)
 			}
 		}
 
@@ -614,6 +694,24 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 			retrieval.Chunks = filtered
 		}
 
+		// PII redaction: the generator, citations and snippets only see
+		// chunks redacted for this caller. The cached retrieval is not touched.
+		piiPlan, err := deps.PII.Plan(ctx, userID, middleware.RoleFromContext(ctx), privilegeMode, chunkVaultIDs(retrieval.Chunks))
+		if err == nil && piiPlan.Active() {
+			var chunks []service.RankedChunk
+			if chunks, err = piiPlan.RedactChunks(ctx, retrieval.Chunks); err == nil {
+				redacted := *retrieval
+				redacted.Chunks = chunks
+				retrieval = &redacted
+			}
+		}
+		if err != nil {
+			slog.Error("chat PII redaction failed", "user_id", userID, "stage", "redaction", "error", err)
+			sendEvent(w, flusher, "error", `{"message":"failed to apply PII redaction"}`)
+			sendEvent(w, flusher, "done", `{}`)
+			return
+		}
+
 		slog.Info("[DEBUG-CHAT] retrieval complete",
 			"user_id", userID,
 			"chunks_returned", len(retrieval.Chunks),
@@ -656,6 +754,7 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 				}
 				doneJSON, _ := json.Marshal(donePayload)
 				sendEvent(w, flusher, "done", string(doneJSON))
+				auditQuery(ctx, deps.AuditLogger, userID, req, "documents_processing")
 				return
 			}
 		}
@@ -695,6 +794,7 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 				}
 				doneJSON, _ := json.Marshal(donePayload)
 				sendEvent(w, flusher, "done", string(doneJSON))
+				auditQuery(ctx, deps.AuditLogger, userID, req, "document_summary")
 				return
 			}
 		}
@@ -715,8 +815,10 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 				go deps.ContentGapSvc.LogGap(context.Background(), userID, req.Query, 0.0)
 			}
 			donePayload := buildDonePayload(retrieval, nil, nil, startTime, "aegis")
+			donePayload.Evidence.PIIRedaction = piiPlan.Evidence()
 			doneJSON, _ := json.Marshal(donePayload)
 			sendEvent(w, flusher, "done", string(doneJSON))
+			auditQuery(ctx, deps.AuditLogger, userID, req, "silence")
 			return
 		}
 
@@ -845,7 +947,9 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 				// EPIC-028: Forward streaming tokens directly to SSE for
 				// instant TTFB. The prompt now requests plain text with
 				// inline [N] citations instead of JSON, so tokens are safe
-				// to send to the client as-is.
+				// to send to the client as-is — unless PII redaction is
+				// active, when the answer is redacted whole and sent below.
+				forward := !piiPlan.Active()
 				gotTokens := false
 				for token := range streamResult.TokenCh {
 					if ctx.Err() != nil {
@@ -855,8 +959,10 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 						gotTokens = true
 						ttfbMs = time.Since(tGenerateStart).Milliseconds()
 					}
-					tokenJSON, _ := json.Marshal(map[string]string{"text": token})
-					sendEvent(w, flusher, "token", string(tokenJSON))
+					if forward {
+						tokenJSON, _ := json.Marshal(map[string]string{"text": token})
+						sendEvent(w, flusher, "token", string(tokenJSON))
+					}
 				}
 
 				// Check for generation errors
@@ -892,7 +998,7 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 					initial = service.ParseStreamingAnswer(fullText, retrieval.Chunks)
 					initial.ModelUsed = streamResult.Model
 					initial.LatencyMs = time.Since(tGenerateStart).Milliseconds()
-					streamedTokens = true
+					streamedTokens = forward
 				}
 			}
 		}
@@ -958,6 +1064,15 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 
 		tSelfRAGEnd := time.Now()
 
+		if piiPlan.Active() {
+			if err := redactReflection(ctx, piiPlan, result); err != nil {
+				slog.Error("chat PII redaction failed", "user_id", userID, "stage", "redaction", "error", err)
+				sendEvent(w, flusher, "error", `{"message":"failed to apply PII redaction"}`)
+				sendEvent(w, flusher, "done", `{}`)
+				return
+			}
+		}
+
 		// STORY-171: Confidence floor — suppress misleading citations when confidence is too low.
 		floor := confidenceFloor()
 		if result.FinalConfidence < floor {
@@ -998,10 +1113,12 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 					ConfidenceScore: result.FinalConfidence,
 					Model:           "aegis/" + initial.ModelUsed,
 					LatencyMs:       time.Since(startTime).Milliseconds(),
+					PIIRedaction:    piiPlan.Evidence(),
 				},
 			}
 			doneJSON, _ := json.Marshal(donePayload)
 			sendEvent(w, flusher, "done", string(doneJSON))
+			auditQuery(ctx, deps.AuditLogger, userID, req, "low_confidence")
 
 			if deps.ContentGapSvc != nil {
 				go deps.ContentGapSvc.LogGap(context.Background(), userID, req.Query, result.FinalConfidence)
@@ -1089,15 +1206,33 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 		})
 		sendEvent(w, flusher, "metadata", string(metadataJSON))
 
-		// EPIC-028: Cache final response in Redis for next identical query
-		if deps.RedisCache != nil && result != nil {
-			cacheableResult := &service.GenerationResult{
-				Answer:     result.FinalAnswer,
-				Citations:  result.Citations,
-				Confidence: result.FinalConfidence,
-				ModelUsed:  initial.ModelUsed,
+		// EPIC-028: Cache final response in Redis for next identical query.
+		// Both response caches record the documents behind the answer so a
+		// change to any of them invalidates it (see cache.Invalidator).
+		if result != nil && (deps.RedisCache != nil || semanticOK) {
+			cacheableResult := &cache.CachedAnswer{
+				Result: &service.GenerationResult{
+					Answer:     result.FinalAnswer,
+					Citations:  result.Citations,
+					Confidence: result.FinalConfidence,
+					ModelUsed:  initial.ModelUsed,
+				},
+				VaultIDs:  chunkVaultIDs(retrieval.Chunks),
+				Redaction: piiPlan.Fingerprint(),
+			}
+			if ev := piiPlan.Evidence(); ev != nil {
+				cacheableResult.Redacted = ev.Redacted
+			}
+			docIDs := cache.RetrievalDocumentIDs(retrieval)
+			for _, c := range result.Citations {
+				docIDs = append(docIDs, c.DocumentID)
+			}
+			if deps.RedisCache != nil {
+				deps.RedisCache.SetResponse(ctx, userID, cacheQuery, privilegeMode, cacheableResult, docIDs)
+			}
+			if semanticOK && tier != "silence" {
+				deps.SemanticCache.Store(semanticScope, req.Query, queryVec, cacheableResult, docIDs)
 			}
-			deps.RedisCache.SetResponse(ctx, userID, req.Query, privilegeMode, cacheableResult)
 		}
 
 		// Structured latency log (STORY-150 + STORY-151)
@@ -1117,8 +1252,10 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 		)
 
 		donePayload := buildDonePayload(retrieval, initial, result, startTime, providerName)
+		donePayload.Evidence.PIIRedaction = piiPlan.Evidence()
 		doneJSON, _ := json.Marshal(donePayload)
 		sendEvent(w, flusher, "done", string(doneJSON))
+		auditQuery(ctx, deps.AuditLogger, userID, req, queryOutcome(tier))
 
 		// Usage metering: increment after successful query
 		if deps.UsageSvc != nil {
@@ -1164,7 +1301,12 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 				if len(response) > 2000 {
 					response = response[:2000]
 				}
-				if err := deps.CortexSvc.Ingest(bgCtx, userID, response, "assistant", nil, false); err != nil {
+				// Recorded against the cited documents so a purge removes it
+				cited := make([]string, 0, len(result.Citations))
+				for _, c := range result.Citations {
+					cited = append(cited, c.DocumentID)
+				}
+				if err := deps.CortexSvc.Ingest(bgCtx, userID, response, "assistant", nil, false, cited...); err != nil {
 					slog.Error("cortex ingest response failed", "user_id", userID, "error", err)
 				}
 			}()
@@ -1172,6 +1314,94 @@ func Chat(deps ChatDeps) http.HandlerFunc {
 	}
 }
 
+// auditQuery writes the QUERY_EXECUTED entry for a query that ran and got
+// an answer, a silence response or a fallback message. Refused and failed
+// queries also end with a 200 SSE stream, so chat audits itself rather than
+// through the route-level Audit middleware. The entry is written under a
+// context that outlives the request.
+func auditQuery(ctx context.Context, logger ChatAuditLogger, userID string, req ChatRequest, outcome string) {
+	if logger == nil {
+		return
+	}
+	details := map[string]interface{}{"outcome": outcome}
+	if req.VaultID != "" {
+		details["vaultId"] = req.VaultID
+	}
+	if req.DocumentScope != "" {
+		details["documentScope"] = req.DocumentScope
+	}
+	if t := middleware.APITokenFromContext(ctx); t != nil {
+		details["apiTokenId"] = t.ID
+	}
+	if err := logger.LogWithDetails(context.WithoutCancel(ctx), model.AuditQueryExecuted, userID, "", "query", details); err != nil {
+		slog.Error("[Chat] failed to audit query", "user_id", userID, "error", err)
+	}
+}
+
+// queryOutcome names the audited outcome of a generated answer's tier.
+func queryOutcome(tier string) string {
+	if tier == "silence" {
+		return "silence"
+	}
+	return "answered"
+}
+
+// chunkVaultIDs returns the distinct vaults the chunks' documents sit in.
+func chunkVaultIDs(chunks []service.RankedChunk) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, c := range chunks {
+		if c.Document.VaultID != nil && !seen[*c.Document.VaultID] {
+			seen[*c.Document.VaultID] = true
+			ids = append(ids, *c.Document.VaultID)
+		}
+	}
+	return ids
+}
+
+// cachedAnswerRedaction builds the caller's PII redaction plan over the
+// vaults a cached answer was built from. The answer may be served only if it
+// was redacted exactly as that plan would redact it, so a role or policy
+// change since it was cached falls through to the full pipeline. The returned
+// evidence is for the "done" event.
+func cachedAnswerRedaction(ctx context.Context, pii *service.PIIRedactionService, userID string, privilegeMode bool, answer *cache.CachedAnswer) (*service.PIIRedactionEvidence, bool) {
+	plan, err := pii.Plan(ctx, userID, middleware.RoleFromContext(ctx), privilegeMode, answer.VaultIDs)
+	if err != nil {
+		slog.Warn("[Chat] cached answer redaction check failed", "user_id", userID, "error", err)
+		return nil, false
+	}
+	if plan.Fingerprint() != answer.Redaction {
+		return nil, false
+	}
+	ev := plan.Evidence()
+	if ev != nil {
+		for t, n := range answer.Redacted {
+			ev.Redacted[t] = n
+		}
+	}
+	return ev, true
+}
+
+// redactReflection redacts PII from the final answer and the citation
+// excerpts. The generator only saw redacted chunks, but may still repeat a
+// value from elsewhere, so the text is scanned as well.
+func redactReflection(ctx context.Context, plan *service.PIIRedactionPlan, result *service.ReflectionResult) error {
+	answer, err := plan.RedactAnswer(ctx, result.FinalAnswer)
+	if err != nil {
+		return err
+	}
+	citations := make([]service.CitationRef, len(result.Citations))
+	for i, c := range result.Citations {
+		if c.Excerpt, err = plan.RedactAnswer(ctx, c.Excerpt); err != nil {
+			return err
+		}
+		citations[i] = c
+	}
+	result.FinalAnswer = answer
+	result.Citations = citations
+	return nil
+}
+
 // sanitizeAnswer ensures the answer text is clean prose, not a raw JSON object.
 // BUG-054: If the LLM returns {"answer":"...", "citations":[...], "confidence":0.9}
 // as the answer text (because ParseGenerationResponse's fallback path returned raw),
@@ -1205,6 +1435,45 @@ func sendEvent(w http.ResponseWriter, f http.Flusher, event, data string) {
 	f.Flush()
 }
 
+// streamCachedAnswer replays a cached answer as token, confidence and citation
+// events. Returns false if the client went away mid-stream.
+func streamCachedAnswer(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, res *service.GenerationResult) bool {
+	for _, token := range splitIntoTokens(sanitizeAnswer(res.Answer)) {
+		if ctx.Err() != nil {
+			return false
+		}
+		tokenJSON, _ := json.Marshal(map[string]string{"text": token})
+		sendEvent(w, flusher, "token", string(tokenJSON))
+	}
+	confJSON, _ := json.Marshal(map[string]interface{}{"confidence": res.Confidence})
+	sendEvent(w, flusher, "confidence", string(confJSON))
+	if len(res.Citations) > 0 {
+		citJSON, _ := json.Marshal(res.Citations)
+		sendEvent(w, flusher, "citations", string(citJSON))
+	}
+	return true
+}
+
+// semanticCacheable reports whether a request's answer depends only on the
+// query and vault, so it may be served from or stored in the semantic cache.
+// Web context and conversation history make answers request-specific.
+func semanticCacheable(req ChatRequest) bool {
+	return req.WebContext == "" && len(req.ConversationHistory) == 0 && req.UserContext == nil
+}
+
+// semanticFilterKey encodes the request options that change the answer, so
+// semantic matches never cross document scope, vault, mode, persona or model.
+func semanticFilterKey(req ChatRequest, personaKey string) string {
+	return strings.Join([]string{
+		"scope=" + req.DocumentScope,
+		"vault=" + req.VaultID,
+		"mode=" + req.Mode,
+		"persona=" + personaKey,
+		"strict=" + strconv.FormatBool(req.StrictMode),
+		"llm=" + req.LLMProvider + "/" + req.LLMModel,
+	}, "|")
+}
+
 // truncate returns the first n characters of s, appending "…" if truncated.
 func truncate(s string, n int) string {
 	if len(s) <= n {
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/connexus-ai/ragbox-backend/internal/cache"
 	"github.com/connexus-ai/ragbox-backend/internal/middleware"
 	"github.com/connexus-ai/ragbox-backend/internal/model"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
@@ -34,9 +35,13 @@ func (m *mockRetriever) Retrieve(ctx context.Context, query string, privilegeMod
 type mockChatGenerator struct {
 	result *service.GenerationResult
 	err    error
+	calls  int
+	chunks []service.RankedChunk // context passed to the last call
 }
 
 func (m *mockChatGenerator) Generate(ctx context.Context, query string, chunks []service.RankedChunk, opts service.GenerateOpts) (*service.GenerationResult, error) {
+	m.calls++
+	m.chunks = chunks
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -101,7 +106,7 @@ type stubSearcher struct {
 	err    error
 }
 
-func (s *stubSearcher) SimilaritySearch(ctx context.Context, queryVec []float32, topK int, threshold float64, userID string, excludePrivileged bool) ([]service.VectorSearchResult, error) {
+func (s *stubSearcher) SimilaritySearch(ctx context.Context, queryVec []float32, space model.EmbeddingSpace, topK int, threshold float64, userID string, excludePrivileged bool) ([]service.VectorSearchResult, error) {
 	if s.err != nil {
 		return nil, s.err
 	}
@@ -741,6 +746,68 @@ func TestChat_SummarizeQuery_ListsDocuments(t *testing.T) {
 	}
 }
 
+func TestChat_SemanticCacheHit(t *testing.T) {
+	retriever := &mockRetriever{result: testRetrievalResult()}
+	generator := &mockChatGenerator{result: testGenerationResult()}
+	deps := makeChatDeps(retriever, generator)
+	deps.SemanticCache = cache.NewSemanticCache(time.Hour, 0.95)
+	defer deps.SemanticCache.Stop()
+
+	handler := Chat(deps)
+	handler.ServeHTTP(httptest.NewRecorder(), chatRequest("what's the termination notice?"))
+	callsAfterFirst := generator.calls
+
+	// stubEmbedder returns the same vector for every text, so the rephrased
+	// query is a semantic near-duplicate of the first.
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, chatRequest("termination notice period?"))
+
+	if generator.calls != callsAfterFirst {
+		t.Errorf("generator called %d more times, want 0 on semantic hit", generator.calls-callsAfterFirst)
+	}
+	events := parseSSEEvents(w.Body.String())
+	last := events[len(events)-1]
+	var done map[string]interface{}
+	if err := json.Unmarshal([]byte(last.Data), &done); err != nil {
+		t.Fatalf("parse done: %v", err)
+	}
+	if done["cached"] != "semantic" {
+		t.Errorf("cached = %v, want semantic", done["cached"])
+	}
+	if done["matchedQuery"] != "what's the termination notice?" {
+		t.Errorf("matchedQuery = %v", done["matchedQuery"])
+	}
+
+	// Changing the cited document invalidates the cached answer.
+	deps.SemanticCache.InvalidateDocument("d1")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, chatRequest("termination notice period?"))
+	if generator.calls == callsAfterFirst {
+		t.Error("expected regeneration after cited document changed")
+	}
+}
+
+func TestChat_SemanticCacheRespectsDocumentScope(t *testing.T) {
+	retriever := &mockRetriever{result: testRetrievalResult()}
+	generator := &mockChatGenerator{result: testGenerationResult()}
+	deps := makeChatDeps(retriever, generator)
+	deps.SemanticCache = cache.NewSemanticCache(time.Hour, 0.95)
+	defer deps.SemanticCache.Stop()
+
+	handler := Chat(deps)
+	handler.ServeHTTP(httptest.NewRecorder(), chatRequest("what's the termination notice?"))
+	callsAfterFirst := generator.calls
+
+	body, _ := json.Marshal(ChatRequest{Query: "what's the termination notice?", Mode: "concise", DocumentScope: "d1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/chat", bytes.NewReader(body))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "test-user"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if generator.calls == callsAfterFirst {
+		t.Error("scoped query must not reuse an unscoped answer")
+	}
+}
+
 func TestIsSummarizeQuery(t *testing.T) {
 	positives := []string{
 		"Summarize my documents",
@@ -766,3 +833,155 @@ func TestIsSummarizeQuery(t *testing.T) {
 		}
 	}
 }
+
+// ssnDLPClient implements service.DLPClient, finding one fixed SSN.
+type ssnDLPClient struct{}
+
+func (ssnDLPClient) InspectContent(ctx context.Context, project, text string, infoTypes []string) ([]service.Finding, error) {
+	var findings []service.Finding
+	if i := strings.Index(text, "536-22-1847"); i >= 0 {
+		findings = append(findings, service.Finding{InfoType: "US_SOCIAL_SECURITY_NUMBER", Content: "536-22-1847", StartIndex: i, EndIndex: i + 11, Score: 0.9})
+	}
+	return findings, nil
+}
+
+func TestChat_RedactsPII(t *testing.T) {
+	retrieval := testRetrievalResult()
+	retrieval.Chunks[0].Chunk.Content = "Employee SSN 536-22-1847 is on file."
+	generation := testGenerationResult()
+	generation.Answer = "The SSN on file is 536-22-1847 [1]."
+	generation.Citations[0].Excerpt = "SSN 536-22-1847"
+	generator := &mockChatGenerator{result: generation}
+	deps := makeChatDeps(&mockRetriever{result: retrieval}, generator)
+	defaults := model.PIIRedactionPolicy{InfoTypes: []string{"US_SOCIAL_SECURITY_NUMBER"}}
+	deps.PII = service.NewPIIRedactionService(nil, nil, service.NewRedactorService(ssnDLPClient{}, ""), nil, defaults)
+
+	w := httptest.NewRecorder()
+	Chat(deps).ServeHTTP(w, chatRequest("What is the SSN on file?"))
+
+	if strings.Contains(w.Body.String(), "536-22-1847") {
+		t.Errorf("SSN leaked into the stream: %s", w.Body.String())
+	}
+	if len(generator.chunks) != 1 || generator.chunks[0].Chunk.Content != "Employee SSN [REDACTED-SSN] is on file." {
+		t.Errorf("generator context = %+v, want redacted chunk", generator.chunks)
+	}
+
+	events := parseSSEEvents(w.Body.String())
+	var payload DonePayload
+	if err := json.Unmarshal([]byte(events[len(events)-1].Data), &payload); err != nil {
+		t.Fatalf("failed to parse done payload: %v", err)
+	}
+	if payload.Answer != "The SSN on file is [REDACTED-SSN] [1]." {
+		t.Errorf("answer = %q", payload.Answer)
+	}
+	ev := payload.Evidence.PIIRedaction
+	if ev == nil || len(ev.Policies) != 1 || !ev.Policies[0].Applied || ev.Redacted["US_SOCIAL_SECURITY_NUMBER"] == 0 {
+		t.Errorf("piiRedaction evidence = %+v", ev)
+	}
+}
+
+func TestChat_CachedAnswerHonorsCallerRedaction(t *testing.T) {
+	retrieval := testRetrievalResult()
+	retrieval.Chunks[0].Chunk.Content = "Employee SSN 536-22-1847 is on file."
+	generation := testGenerationResult()
+	generation.Answer = "The SSN on file is 536-22-1847 [1]."
+	generator := &mockChatGenerator{result: generation}
+	deps := makeChatDeps(&mockRetriever{result: retrieval}, generator)
+	deps.SemanticCache = cache.NewSemanticCache(time.Hour, 0.95)
+	defer deps.SemanticCache.Stop()
+	defaults := model.PIIRedactionPolicy{
+		InfoTypes:   []string{"US_SOCIAL_SECURITY_NUMBER"},
+		ExemptRoles: []model.UserRole{model.UserRolePartner},
+	}
+	deps.PII = service.NewPIIRedactionService(nil, nil, service.NewRedactorService(ssnDLPClient{}, ""), nil, defaults)
+	handler := Chat(deps)
+
+	ask := func(role string) (*httptest.ResponseRecorder, map[string]json.RawMessage) {
+		t.Helper()
+		req := chatRequest("What is the SSN on file?")
+		req = req.WithContext(middleware.WithRole(req.Context(), role))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		events := parseSSEEvents(w.Body.String())
+		var done map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(events[len(events)-1].Data), &done); err != nil {
+			t.Fatalf("parse done: %v", err)
+		}
+		return w, done
+	}
+
+	// A Partner is exempt and fills the cache with the unredacted answer.
+	if w, _ := ask("Partner"); !strings.Contains(w.Body.String(), "536-22-1847") {
+		t.Fatalf("Partner answer should be unredacted: %s", w.Body.String())
+	}
+	callsAfterPartner := generator.calls
+
+	// The same user, now an Associate, must not be served the Partner's answer.
+	w, done := ask("Associate")
+	if strings.Contains(w.Body.String(), "536-22-1847") {
+		t.Errorf("SSN leaked to an Associate from the cache: %s", w.Body.String())
+	}
+	if generator.calls == callsAfterPartner {
+		t.Error("Associate was served the Partner's cached answer")
+	}
+	if _, cached := done["cached"]; cached {
+		t.Error("done event reports a cache hit for the Associate's first query")
+	}
+
+	// A second Associate query may reuse the redacted answer, with evidence.
+	callsAfterAssociate := generator.calls
+	w, done = ask("Associate")
+	if generator.calls != callsAfterAssociate {
+		t.Error("expected the Associate's redacted answer to be reused")
+	}
+	if strings.Contains(w.Body.String(), "536-22-1847") {
+		t.Errorf("SSN leaked from the cached Associate answer: %s", w.Body.String())
+	}
+	var evidence DoneEvidence
+	if err := json.Unmarshal(done["evidence"], &evidence); err != nil {
+		t.Fatalf("parse evidence: %v", err)
+	}
+	ev := evidence.PIIRedaction
+	if ev == nil || len(ev.Policies) != 1 || !ev.Policies[0].Applied || ev.Redacted["US_SOCIAL_SECURITY_NUMBER"] == 0 {
+		t.Errorf("cached piiRedaction evidence = %+v", ev)
+	}
+}
+
+func TestChat_AuditsQueriesThatRan(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		retriever *mockRetriever
+		vaultID   string
+		want      int
+	}{
+		{"answered", &mockRetriever{result: testRetrievalResult()}, "", 1},
+		{"retrieval failed", &mockRetriever{err: fmt.Errorf("search down")}, "", 0},
+		{"vault refused", &mockRetriever{result: testRetrievalResult()}, "20000000-0000-0000-0000-000000000002", 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &stubAuditLogger{}
+			deps := makeChatDeps(tt.retriever, &mockChatGenerator{result: testGenerationResult()})
+			deps.AuditLogger = logger
+
+			body, _ := json.Marshal(ChatRequest{Query: "when does the contract expire?", Mode: "concise", VaultID: tt.vaultID})
+			req := httptest.NewRequest(http.MethodPost, "/api/chat", bytes.NewReader(body))
+			req = req.WithContext(middleware.WithUserID(req.Context(), "test-user"))
+			w := httptest.NewRecorder()
+			Chat(deps).ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 over SSE", w.Code)
+			}
+			if len(logger.calls) != tt.want {
+				t.Fatalf("audit entries = %d, want %d", len(logger.calls), tt.want)
+			}
+			if tt.want > 0 {
+				call := logger.calls[0]
+				details, _ := call["details"].(map[string]interface{})
+				if call["action"] != model.AuditQueryExecuted || call["userID"] != "test-user" || details["outcome"] != "answered" {
+					t.Errorf("audit entry = %+v", call)
+				}
+			}
+		})
+	}
+}
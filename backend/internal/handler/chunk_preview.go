@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -18,8 +19,10 @@ type ChunkWithNeighborsReader interface {
 
 // ChunkPreviewDeps bundles dependencies for the chunk preview handler.
 type ChunkPreviewDeps struct {
-	DocRepo     service.DocumentRepository
-	ChunkReader ChunkWithNeighborsReader
+	DocRepo        service.DocumentRepository
+	ChunkReader    ChunkWithNeighborsReader
+	PII            *service.PIIRedactionService // optional — nil disables PII redaction
+	PrivilegeState *PrivilegeState              // exempts callers in Privileged Mode from redaction
 }
 
 // chunkPreviewItem is a single chunk in the preview response.
@@ -59,9 +62,10 @@ func ChunkPreview(deps ChunkPreviewDeps) http.HandlerFunc {
 			return
 		}
 
-		// Verify document exists and belongs to the user
+		// Verify document exists and the caller may read it
+		privileged := deps.PrivilegeState.IsPrivileged(userID)
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc == nil || doc.UserID != userID {
+		if err != nil || !canReadDocument(r.Context(), deps.DocRepo, doc, userID, privileged) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -78,6 +82,20 @@ func ChunkPreview(deps ChunkPreviewDeps) http.HandlerFunc {
 			return
 		}
 
+		var vaultIDs []string
+		if doc.VaultID != nil {
+			vaultIDs = []string{*doc.VaultID}
+		}
+		plan, err := deps.PII.Plan(r.Context(), userID, middleware.RoleFromContext(r.Context()), privileged, vaultIDs)
+		if err == nil {
+			chunks, err = plan.RedactDocumentChunks(r.Context(), doc.VaultID, chunks)
+		}
+		if err != nil {
+			slog.Error("[ChunkPreview] PII redaction failed", "document_id", docID, "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to fetch chunk preview"})
+			return
+		}
+
 		// Find the target chunk in the result set
 		targetIdx := -1
 		for i, c := range chunks {
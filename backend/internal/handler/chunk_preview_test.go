@@ -280,3 +280,39 @@ func findStr(s, sub string) bool {
 	}
 	return false
 }
+
+func TestChunkPreview_RedactsPII(t *testing.T) {
+	docID := "d0000000-0000-0000-0000-000000000001"
+	chunkID := "c0000000-0000-0000-0000-000000000001"
+	userID := "test-user"
+
+	chunks := testChunksMiddle()
+	chunks[0].PIIFindings = []model.PIIFinding{} // scanned, nothing found
+	chunks[2].PIIFindings = []model.PIIFinding{}
+	chunks[1].Content = "SSN 536-22-1847."
+	chunks[1].PIIFindings = []model.PIIFinding{{InfoType: "US_SOCIAL_SECURITY_NUMBER", Start: 4, End: 15, Score: 0.9}}
+	defaults := model.PIIRedactionPolicy{InfoTypes: []string{"US_SOCIAL_SECURITY_NUMBER"}}
+	privilege := NewPrivilegeState()
+	deps := ChunkPreviewDeps{
+		DocRepo:        &mockDocRepoForPreview{doc: testDocForPreview(userID)},
+		ChunkReader:    &mockChunkReader{chunks: chunks},
+		PII:            service.NewPIIRedactionService(nil, nil, service.NewRedactorService(nil, ""), nil, defaults),
+		PrivilegeState: privilege,
+	}
+
+	w := httptest.NewRecorder()
+	ChunkPreview(deps).ServeHTTP(w, chunkPreviewRequest(userID, docID, chunkID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	if !containsStr(w.Body.String(), `"content":"SSN [REDACTED-SSN]."`) {
+		t.Errorf("expected redacted target chunk in %s", w.Body.String())
+	}
+
+	privilege.modes[userID] = true
+	w = httptest.NewRecorder()
+	ChunkPreview(deps).ServeHTTP(w, chunkPreviewRequest(userID, docID, chunkID))
+	if !containsStr(w.Body.String(), `"content":"SSN 536-22-1847."`) {
+		t.Errorf("expected unredacted content in Privileged Mode in %s", w.Body.String())
+	}
+}
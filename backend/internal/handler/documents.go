@@ -74,6 +74,9 @@ func UploadDocument(docService *service.DocumentService) http.HandlerFunc {
 		}
 
 		resp, err := docService.GenerateUploadURL(r.Context(), userID, req.Filename, req.ContentType, req.SizeBytes, req.FolderID)
+		if respondQuotaError(w, err) {
+			return
+		}
 		if err != nil {
 			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: err.Error()})
 			return
@@ -100,6 +103,11 @@ type ChunkDeleter interface {
 	DeleteByDocumentID(ctx context.Context, documentID string) error
 }
 
+// ChunkLister lists a document's chunks with their content decrypted.
+type ChunkLister interface {
+	ListByDocumentID(ctx context.Context, documentID string) ([]model.DocumentChunk, error)
+}
+
 // StorageSigner generates signed URLs for document download.
 type StorageSigner interface {
 	SignedURL(bucket, object string, opts *service.SignedURLOptions) (string, error)
@@ -114,10 +122,13 @@ type ObjectDownloader interface {
 type DocCRUDDeps struct {
 	DocRepo          service.DocumentRepository
 	ChunkDeleter     ChunkDeleter
+	ChunkLister      ChunkLister
 	Storage          StorageSigner
 	ObjectDownloader ObjectDownloader
 	BucketName       string
-	QueryCache       *cache.QueryCache // optional — invalidated on doc changes
+	Invalidator      *cache.Invalidator // optional — drops cached results built from changed docs
+	Holds            LegalHoldGuard     // optional — refuses changes to documents under legal hold
+	PrivilegeState   *PrivilegeState    // server-side Privileged Mode; nil = never privileged
 }
 
 // ListDocuments handles GET /api/documents.
@@ -132,14 +143,19 @@ func ListDocuments(deps DocCRUDDeps) http.HandlerFunc {
 		q := r.URL.Query()
 		limit, _ := strconv.Atoi(q.Get("limit"))
 		offset, _ := strconv.Atoi(q.Get("offset"))
-		privilegeMode := q.Get("privilegeMode") == "true"
 		search := strings.TrimSpace(q.Get("search"))
+		vaultID := q.Get("vaultId")
+		if vaultID != "" && !validateVaultID(vaultID) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid vault ID format"})
+			return
+		}
 
 		docs, total, err := deps.DocRepo.ListByUser(r.Context(), userID, service.ListOpts{
 			Limit:         limit,
 			Offset:        offset,
-			PrivilegeMode: privilegeMode,
+			PrivilegeMode: deps.PrivilegeState.IsPrivileged(userID),
 			Search:        search,
+			VaultID:       vaultID,
 		})
 		if err != nil {
 			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to list documents"})
@@ -178,7 +194,7 @@ func GetDocument(deps DocCRUDDeps) http.HandlerFunc {
 			return
 		}
 
-		if doc.UserID != userID {
+		if !canReadDocument(r.Context(), deps.DocRepo, doc, userID, deps.PrivilegeState.IsPrivileged(userID)) {
 			respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "access denied"})
 			return
 		}
@@ -207,19 +223,24 @@ func DeleteDocument(deps DocCRUDDeps) http.HandlerFunc {
 		}
 
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
 
+		if !guardDocuments(w, r, deps.Holds, userID, service.LegalHoldOpDelete, docID) {
+			return
+		}
+
 		if err := deps.DocRepo.SoftDelete(r.Context(), docID); err != nil {
+			if respondLegalHoldError(w, err) {
+				return
+			}
 			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to delete document"})
 			return
 		}
 
-		if deps.QueryCache != nil {
-			deps.QueryCache.InvalidateUser(userID)
-		}
+		deps.Invalidator.InvalidateDocuments(r.Context(), userID, docID)
 
 		respondJSON(w, http.StatusOK, envelope{Success: true})
 	}
@@ -241,7 +262,7 @@ func RecoverDocument(deps DocCRUDDeps) http.HandlerFunc {
 		}
 
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -251,9 +272,7 @@ func RecoverDocument(deps DocCRUDDeps) http.HandlerFunc {
 			return
 		}
 
-		if deps.QueryCache != nil {
-			deps.QueryCache.InvalidateUser(userID)
-		}
+		deps.Invalidator.InvalidateUser(r.Context(), userID)
 
 		respondJSON(w, http.StatusOK, envelope{Success: true})
 	}
@@ -280,7 +299,7 @@ func UpdateDocumentTier(deps DocCRUDDeps) http.HandlerFunc {
 		}
 
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -302,6 +321,8 @@ func UpdateDocumentTier(deps DocCRUDDeps) http.HandlerFunc {
 			return
 		}
 
+		deps.Invalidator.InvalidateDocuments(r.Context(), userID, docID)
+
 		respondJSON(w, http.StatusOK, envelope{Success: true})
 	}
 }
@@ -332,7 +353,7 @@ func UpdateDocument(deps DocCRUDDeps) http.HandlerFunc {
 		}
 
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -348,6 +369,14 @@ func UpdateDocument(deps DocCRUDDeps) http.HandlerFunc {
 			return
 		}
 
+		// A legal hold freezes the document's name and location.
+		if req.Name != nil && !guardDocuments(w, r, deps.Holds, userID, service.LegalHoldOpRename, docID) {
+			return
+		}
+		if req.FolderID != nil && !guardDocuments(w, r, deps.Holds, userID, service.LegalHoldOpMove, docID) {
+			return
+		}
+
 		// Rename
 		if req.Name != nil {
 			if *req.Name == "" {
@@ -359,6 +388,9 @@ func UpdateDocument(deps DocCRUDDeps) http.HandlerFunc {
 				return
 			}
 			if err := deps.DocRepo.Update(r.Context(), docID, *req.Name); err != nil {
+				if respondLegalHoldError(w, err) {
+					return
+				}
 				respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to rename document"})
 				return
 			}
@@ -376,11 +408,16 @@ func UpdateDocument(deps DocCRUDDeps) http.HandlerFunc {
 				folderPtr = &folderID
 			}
 			if err := deps.DocRepo.UpdateFolder(r.Context(), docID, folderPtr); err != nil {
+				if respondLegalHoldError(w, err) {
+					return
+				}
 				respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to move document"})
 				return
 			}
 		}
 
+		deps.Invalidator.InvalidateDocuments(r.Context(), userID, docID)
+
 		respondJSON(w, http.StatusOK, envelope{Success: true})
 	}
 }
@@ -406,7 +443,7 @@ func ToggleDocPrivilege(deps DocCRUDDeps) http.HandlerFunc {
 		}
 
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -422,14 +459,63 @@ func ToggleDocPrivilege(deps DocCRUDDeps) http.HandlerFunc {
 			return
 		}
 
-		if deps.QueryCache != nil {
-			deps.QueryCache.InvalidateUser(userID)
+		// Privileging a document only removes it from results, so dropping the
+		// entries built from it is exact. Un-privileging can add it to any
+		// non-privileged result, so the whole user is flushed.
+		if req.Privileged {
+			deps.Invalidator.InvalidateDocuments(r.Context(), userID, docID)
+		} else {
+			deps.Invalidator.InvalidateUser(r.Context(), userID)
 		}
 
 		respondJSON(w, http.StatusOK, envelope{Success: true})
 	}
 }
 
+// ListChunks handles GET /api/documents/{id}/chunks. Chunk content is
+// encrypted at rest, so the web app reads chunks here rather than from the
+// database.
+func ListChunks(deps DocCRUDDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		docID := chi.URLParam(r, "id")
+		if !validateUUID(docID) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid document ID format"})
+			return
+		}
+
+		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
+		if err != nil {
+			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
+			return
+		}
+		if !canReadDocument(r.Context(), deps.DocRepo, doc, userID, deps.PrivilegeState.IsPrivileged(userID)) {
+			respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "access denied"})
+			return
+		}
+
+		if deps.ChunkLister == nil {
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "chunk listing not configured"})
+			return
+		}
+		chunks, err := deps.ChunkLister.ListByDocumentID(r.Context(), docID)
+		if err != nil {
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to list chunks"})
+			return
+		}
+		if chunks == nil {
+			chunks = []model.DocumentChunk{}
+		}
+
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: map[string]interface{}{"chunks": chunks}})
+	}
+}
+
 // DeleteChunks handles DELETE /api/documents/{id}/chunks.
 // Removes all embeddings for a document and resets index status to Pending.
 func DeleteChunks(deps DocCRUDDeps) http.HandlerFunc {
@@ -451,7 +537,7 @@ func DeleteChunks(deps DocCRUDDeps) http.HandlerFunc {
 		}
 
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -461,7 +547,14 @@ func DeleteChunks(deps DocCRUDDeps) http.HandlerFunc {
 			return
 		}
 
+		if !guardDocuments(w, r, deps.Holds, userID, service.LegalHoldOpDeleteChunks, docID) {
+			return
+		}
+
 		if err := deps.ChunkDeleter.DeleteByDocumentID(r.Context(), docID); err != nil {
+			if respondLegalHoldError(w, err) {
+				return
+			}
 			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to delete chunks"})
 			return
 		}
@@ -476,9 +569,7 @@ func DeleteChunks(deps DocCRUDDeps) http.HandlerFunc {
 			return
 		}
 
-		if deps.QueryCache != nil {
-			deps.QueryCache.InvalidateUser(userID)
-		}
+		deps.Invalidator.InvalidateDocuments(r.Context(), userID, docID)
 
 		respondJSON(w, http.StatusOK, envelope{Success: true})
 	}
@@ -501,7 +592,7 @@ func DownloadDocument(deps DocCRUDDeps) http.HandlerFunc {
 		}
 
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canReadDocument(r.Context(), deps.DocRepo, doc, userID, deps.PrivilegeState.IsPrivileged(userID)) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -549,7 +640,7 @@ func VerifyIntegrity(deps DocCRUDDeps) http.HandlerFunc {
 		}
 
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canReadDocument(r.Context(), deps.DocRepo, doc, userID, deps.PrivilegeState.IsPrivileged(userID)) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -604,7 +695,7 @@ func ToggleStar(deps DocCRUDDeps) http.HandlerFunc {
 		}
 
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -205,6 +206,8 @@ type crudDocRepo struct {
 	listErr   error
 	deleteErr error
 	updateErr error
+	listOpts  service.ListOpts // opts of the last ListByUser call
+	text      string           // text of the last UpdateText call
 }
 
 func (m *crudDocRepo) Create(ctx context.Context, doc *model.Document) error { return nil }
@@ -215,6 +218,7 @@ func (m *crudDocRepo) GetByID(ctx context.Context, id string) (*model.Document,
 	return m.singleDoc, nil
 }
 func (m *crudDocRepo) ListByUser(ctx context.Context, userID string, opts service.ListOpts) ([]model.Document, int, error) {
+	m.listOpts = opts
 	if m.listErr != nil {
 		return nil, 0, m.listErr
 	}
@@ -224,6 +228,7 @@ func (m *crudDocRepo) UpdateStatus(ctx context.Context, id string, status model.
 	return nil
 }
 func (m *crudDocRepo) UpdateText(ctx context.Context, id string, text string, pageCount int) error {
+	m.text = text
 	return nil
 }
 func (m *crudDocRepo) UpdateChunkCount(ctx context.Context, id string, count int) error { return nil }
@@ -333,6 +338,45 @@ func TestGetDocument_Success(t *testing.T) {
 	}
 }
 
+// stubChunkLister returns fixed chunks.
+type stubChunkLister struct {
+	chunks []model.DocumentChunk
+}
+
+func (s *stubChunkLister) ListByDocumentID(_ context.Context, documentID string) ([]model.DocumentChunk, error) {
+	return s.chunks, nil
+}
+
+func TestListChunks(t *testing.T) {
+	const docID = "10000000-0000-0000-0000-000000000001"
+	lister := &stubChunkLister{chunks: []model.DocumentChunk{{ID: "c-0", DocumentID: docID, Content: "decrypted text"}}}
+
+	for _, tc := range []struct {
+		name  string
+		owner string
+		want  int
+	}{
+		{"owner", "user-1", http.StatusOK},
+		{"other user", "other-user", http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &crudDocRepo{singleDoc: &model.Document{ID: docID, UserID: tc.owner}}
+			req := httptest.NewRequest(http.MethodGet, "/api/documents/"+docID+"/chunks", nil)
+			req = req.WithContext(middleware.WithUserID(req.Context(), "user-1"))
+			req = withChiParam(req, "id", docID)
+			rec := httptest.NewRecorder()
+			ListChunks(DocCRUDDeps{DocRepo: repo, ChunkLister: lister}).ServeHTTP(rec, req)
+
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.want)
+			}
+			if tc.want == http.StatusOK && !strings.Contains(rec.Body.String(), "decrypted text") {
+				t.Errorf("body = %s, want the chunk content", rec.Body)
+			}
+		})
+	}
+}
+
 func TestGetDocument_Forbidden(t *testing.T) {
 	repo := &crudDocRepo{singleDoc: &model.Document{ID: "10000000-0000-0000-0000-000000000001", UserID: "other-user"}}
 	deps := DocCRUDDeps{DocRepo: repo}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/connexus-ai/ragbox-backend/internal/middleware"
 	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/rbac"
 	"github.com/connexus-ai/ragbox-backend/internal/repository"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
@@ -26,6 +27,9 @@ type ExportDeps struct {
 
 // ExportData returns a handler for GET /api/export.
 // Generates a ZIP file with all user data (GDPR data portability).
+// Roles without document content access (Auditors) get each document's
+// descriptive fields (name, type, size, status, dates) but not its extracted
+// text, storage location or free-form metadata map.
 func ExportData(deps ExportDeps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := middleware.UserIDFromContext(r.Context())
@@ -45,6 +49,11 @@ func ExportData(deps ExportDeps) http.HandlerFunc {
 			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to fetch documents"})
 			return
 		}
+		if role := middleware.RoleFromContext(ctx); role != "" && !rbac.Can(role, rbac.PermDocumentContent) {
+			for i := range docs {
+				stripDocumentContent(&docs[i])
+			}
+		}
 
 		// Fetch audit logs
 		auditEntries, _, err := deps.AuditLister.List(ctx, repository.ListFilter{
@@ -90,6 +99,16 @@ func ExportData(deps ExportDeps) http.HandlerFunc {
 	}
 }
 
+// stripDocumentContent clears everything that reveals what a document
+// says or where its bytes live, keeping only descriptive metadata.
+func stripDocumentContent(d *model.Document) {
+	d.ExtractedText = nil
+	d.StorageURI = nil
+	d.StoragePath = nil
+	d.Metadata = nil
+	d.MatchField = ""
+}
+
 // writeJSONToZip adds a JSON file to the ZIP archive.
 func writeJSONToZip(zw *zip.Writer, filename string, data interface{}) error {
 	f, err := zw.Create(filename)
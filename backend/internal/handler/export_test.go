@@ -182,3 +182,55 @@ func TestExportData_EmptyData(t *testing.T) {
 		t.Errorf("expected 3 files in ZIP, got %d", len(zr.File))
 	}
 }
+
+func TestExportData_AuditorGetsMetadataOnly(t *testing.T) {
+	text, uri := "privileged settlement terms", "gs://bucket/contract.pdf"
+	docs := testDocuments()
+	docs[0].ExtractedText, docs[0].StorageURI, docs[0].StoragePath = &text, &uri, &uri
+	docs[0].Metadata = []byte(`{"parties":["Acme"]}`)
+
+	for _, tt := range []struct {
+		role        string
+		wantContent bool
+	}{
+		{"Auditor", false},
+		{"Associate", true},
+		{"", true}, // no Policy middleware
+	} {
+		t.Run(tt.role, func(t *testing.T) {
+			deps := ExportDeps{
+				DocRepo:     &stubDocLister{docs: append([]model.Document(nil), docs...), total: 1},
+				AuditLister: &stubExportAuditLister{},
+			}
+			req := exportRequest()
+			if tt.role != "" {
+				req = req.WithContext(middleware.WithRole(req.Context(), tt.role))
+			}
+			w := httptest.NewRecorder()
+			ExportData(deps).ServeHTTP(w, req)
+
+			zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+			if err != nil {
+				t.Fatalf("read ZIP: %v", err)
+			}
+			var documents string
+			for _, f := range zr.File {
+				if f.Name == "documents.json" {
+					rc, _ := f.Open()
+					var buf bytes.Buffer
+					buf.ReadFrom(rc)
+					rc.Close()
+					documents = buf.String()
+				}
+			}
+			if !strings.Contains(documents, "contract.pdf") {
+				t.Errorf("documents.json missing metadata: %s", documents)
+			}
+			for _, secret := range []string{text, uri, "Acme"} {
+				if got := strings.Contains(documents, secret); got != tt.wantContent {
+					t.Errorf("documents.json contains %q = %v, want %v", secret, got, tt.wantContent)
+				}
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ const maxFolderNameLength = 100
 // FolderDeps bundles dependencies for folder handlers.
 type FolderDeps struct {
 	FolderRepo service.FolderRepository
+	Holds      LegalHoldGuard // optional — refuses deleting folders under legal hold
 }
 
 // CreateFolderRequest is the request body for creating a folder.
@@ -111,18 +112,25 @@ func DeleteFolder(deps FolderDeps) http.HandlerFunc {
 			return
 		}
 
-		// Verify folder exists and belongs to requesting user
+		// Verify folder exists and the caller may modify it
 		folder, err := deps.FolderRepo.GetByID(r.Context(), folderID)
 		if err != nil {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "folder not found"})
 			return
 		}
-		if folder.UserID != userID {
+		if !canManageFolder(r.Context(), folder, userID) {
 			respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "access denied"})
 			return
 		}
 
+		if !guardFolder(w, r, deps.Holds, userID, folderID, service.LegalHoldOpDelete) {
+			return
+		}
+
 		if err := deps.FolderRepo.Delete(r.Context(), folderID); err != nil {
+			if respondLegalHoldError(w, err) {
+				return
+			}
 			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to delete folder"})
 			return
 		}
@@ -21,9 +21,11 @@ type Ingester interface {
 
 // IngestDeps bundles dependencies for the ingest handler.
 type IngestDeps struct {
-	DocRepo    service.DocumentRepository
-	Pipeline   Ingester
-	QueryCache *cache.QueryCache // optional — invalidated after pipeline completes
+	DocRepo     service.DocumentRepository
+	Pipeline    Ingester
+	Invalidator *cache.Invalidator    // optional — user caches dropped after pipeline completes
+	Quota       service.QuotaEnforcer // optional — documents_stored checked before processing
+	Holds       LegalHoldGuard        // optional — refuses re-ingesting documents under legal hold
 }
 
 // IngestDocument handles POST /api/documents/{id}/ingest.
@@ -53,7 +55,7 @@ func IngestDocument(deps IngestDeps) http.HandlerFunc {
 			return
 		}
 
-		if doc.UserID != userID {
+		if !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
 			respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "access denied"})
 			return
 		}
@@ -66,6 +68,17 @@ func IngestDocument(deps IngestDeps) http.HandlerFunc {
 			return
 		}
 
+		// A checksum means the pipeline has processed the document before.
+		if doc.Checksum != nil && !guardDocuments(w, r, deps.Holds, userID, service.LegalHoldOpReingest, docID) {
+			return
+		}
+
+		// The pending document is already counted, so only refuse when the
+		// user is over their limit (e.g. documents created outside UploadDocument).
+		if !enforceQuota(w, r, deps.Quota, userID, service.MetricDocumentsStored, 0) {
+			return
+		}
+
 		go func(id, uid string) {
 			// STORY-224: Increased timeout from 120s → 300s for large PDFs
 			// that go through Document AI extraction.
@@ -85,9 +98,7 @@ func IngestDocument(deps IngestDeps) http.HandlerFunc {
 				}
 			} else {
 				slog.Info("ingest pipeline completed", "document_id", id)
-				if deps.QueryCache != nil {
-					deps.QueryCache.InvalidateUser(uid)
-				}
+				deps.Invalidator.InvalidateUser(ctx, uid)
 			}
 		}(docID, userID)
 
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"time"
@@ -21,9 +22,11 @@ type TextIngester interface {
 
 // IngestTextDeps bundles dependencies for the ingest-text handler.
 type IngestTextDeps struct {
-	DocRepo    service.DocumentRepository
-	Pipeline   TextIngester
-	QueryCache *cache.QueryCache // optional — invalidated after pipeline completes
+	DocRepo     service.DocumentRepository
+	Pipeline    TextIngester
+	Invalidator *cache.Invalidator    // optional — user caches dropped after pipeline completes
+	Quota       service.QuotaEnforcer // optional — documents_stored checked before processing
+	Holds       LegalHoldGuard        // optional — refuses re-ingesting documents under legal hold
 }
 
 // IngestText handles POST /api/documents/{id}/ingest-text.
@@ -54,7 +57,7 @@ func IngestText(deps IngestTextDeps) http.HandlerFunc {
 			return
 		}
 
-		if doc.UserID != userID {
+		if !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
 			respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "access denied"})
 			return
 		}
@@ -67,6 +70,17 @@ func IngestText(deps IngestTextDeps) http.HandlerFunc {
 			return
 		}
 
+		// A checksum means the pipeline has processed the document before.
+		if doc.Checksum != nil && !guardDocuments(w, r, deps.Holds, userID, service.LegalHoldOpReingest, docID) {
+			return
+		}
+
+		// The pending document is already counted, so only refuse when the
+		// user is over their limit (e.g. documents created outside UploadDocument).
+		if !enforceQuota(w, r, deps.Quota, userID, service.MetricDocumentsStored, 0) {
+			return
+		}
+
 		if doc.ExtractedText == nil || *doc.ExtractedText == "" {
 			respondJSON(w, http.StatusBadRequest, envelope{
 				Success: false,
@@ -83,9 +97,7 @@ func IngestText(deps IngestTextDeps) http.HandlerFunc {
 				slog.Error("ingest-text pipeline failed", "document_id", id, "error", err)
 			} else {
 				slog.Info("ingest-text pipeline completed", "document_id", id)
-				if deps.QueryCache != nil {
-					deps.QueryCache.InvalidateUser(uid)
-				}
+				deps.Invalidator.InvalidateUser(ctx, uid)
 			}
 		}(docID, userID)
 
@@ -98,3 +110,68 @@ func IngestText(deps IngestTextDeps) http.HandlerFunc {
 		})
 	}
 }
+
+// storeTextRequest is the body of PUT /api/documents/{id}/text.
+type storeTextRequest struct {
+	Text string `json:"text"`
+}
+
+// StoreText handles PUT /api/documents/{id}/text.
+// Lets callers that create documents outside the backend (webhook knowledge
+// ingestion) hand over the extracted text so the repository seals it, rather
+// than writing it to the database themselves. Only Pending documents accept text.
+func StoreText(deps IngestTextDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		docID := chi.URLParam(r, "id")
+		if !validateUUID(docID) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid document ID format"})
+			return
+		}
+
+		var req storeTextRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+		if req.Text == "" {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "text is required"})
+			return
+		}
+
+		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
+		if err != nil {
+			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
+			return
+		}
+
+		if !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
+			respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "access denied"})
+			return
+		}
+
+		if doc.IndexStatus != model.IndexPending {
+			respondJSON(w, http.StatusConflict, envelope{
+				Success: false,
+				Error:   "document is not in Pending status",
+			})
+			return
+		}
+
+		if err := deps.DocRepo.UpdateText(r.Context(), docID, req.Text, 0); err != nil {
+			slog.Error("store text failed", "document_id", docID, "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to store text"})
+			return
+		}
+
+		respondJSON(w, http.StatusOK, envelope{
+			Success: true,
+			Data:    map[string]string{"documentId": docID},
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+const storeTextDocID = "10000000-0000-0000-0000-000000000001"
+
+func storeTextRequestFor(userID, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPut, "/api/documents/"+storeTextDocID+"/text", strings.NewReader(body))
+	req = req.WithContext(middleware.WithUserID(req.Context(), userID))
+	return withChiParam(req, "id", storeTextDocID)
+}
+
+func TestStoreText_HandsTextToRepository(t *testing.T) {
+	repo := &crudDocRepo{singleDoc: &model.Document{ID: storeTextDocID, UserID: "user-1", IndexStatus: model.IndexPending}}
+	rec := httptest.NewRecorder()
+	StoreText(IngestTextDeps{DocRepo: repo}).ServeHTTP(rec, storeTextRequestFor("user-1", `{"text":"webhook body"}`))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200. body: %s", rec.Code, rec.Body.String())
+	}
+	if repo.text != "webhook body" {
+		t.Errorf("UpdateText got %q, want %q", repo.text, "webhook body")
+	}
+}
+
+func TestStoreText_Rejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    model.Document
+		userID string
+		body   string
+		want   int
+	}{
+		{"empty text", model.Document{UserID: "user-1", IndexStatus: model.IndexPending}, "user-1", `{"text":""}`, http.StatusBadRequest},
+		{"bad body", model.Document{UserID: "user-1", IndexStatus: model.IndexPending}, "user-1", `not json`, http.StatusBadRequest},
+		{"other owner", model.Document{UserID: "user-2", IndexStatus: model.IndexPending}, "user-1", `{"text":"x"}`, http.StatusForbidden},
+		{"already indexed", model.Document{UserID: "user-1", IndexStatus: model.IndexIndexed}, "user-1", `{"text":"x"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := tt.doc
+			doc.ID = storeTextDocID
+			repo := &crudDocRepo{singleDoc: &doc}
+			rec := httptest.NewRecorder()
+			StoreText(IngestTextDeps{DocRepo: repo}).ServeHTTP(rec, storeTextRequestFor(tt.userID, tt.body))
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d. body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if repo.text != "" {
+				t.Errorf("UpdateText called with %q", repo.text)
+			}
+		})
+	}
+}
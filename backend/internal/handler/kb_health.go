@@ -12,7 +12,18 @@ import (
 
 // KBHealthDeps bundles dependencies for KB health handlers.
 type KBHealthDeps struct {
-	Svc *service.KBHealthService
+	Svc    *service.KBHealthService
+	Vaults service.VaultRepository // optional — restricts checks to vaults the caller can see
+}
+
+// vaultVisible reports whether the caller may see the vault. Without a
+// vault repository every vault passes, as before vault access rules existed.
+func (d KBHealthDeps) vaultVisible(r *http.Request, vaultID, userID string) bool {
+	if d.Vaults == nil {
+		return true
+	}
+	_, err := d.Vaults.GetForUser(r.Context(), vaultID, userID)
+	return err == nil
 }
 
 // RunHealthCheck returns a handler for POST /api/vaults/{id}/health-check.
@@ -30,6 +41,10 @@ func RunHealthCheck(deps KBHealthDeps) http.HandlerFunc {
 			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "vault id is required"})
 			return
 		}
+		if !deps.vaultVisible(r, vaultID, userID) {
+			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "vault not found"})
+			return
+		}
 
 		freshness, err := deps.Svc.RunFreshnessCheck(r.Context(), vaultID)
 		if err != nil {
@@ -67,6 +82,10 @@ func GetHealthHistory(deps KBHealthDeps) http.HandlerFunc {
 			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "vault id is required"})
 			return
 		}
+		if !deps.vaultVisible(r, vaultID, userID) {
+			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "vault not found"})
+			return
+		}
 
 		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 		if limit <= 0 {
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+const heldDocID = "10000000-0000-0000-0000-000000000001"
+
+var acmeHold = model.LegalHold{ID: "hold-1", Name: "Acme v. Widget", MatterID: "M-1024", Custodian: "J. Doe"}
+
+// stubHoldGuard refuses every operation on the held resources.
+type stubHoldGuard struct {
+	held map[string]bool
+	err  error // returned instead when set
+}
+
+func (g *stubHoldGuard) GuardDocuments(_ context.Context, _ string, op service.LegalHoldOp, docIDs ...string) error {
+	if g.err != nil {
+		return g.err
+	}
+	for _, id := range docIDs {
+		if g.held[id] {
+			return &service.LegalHoldError{Op: op, ResourceType: model.LegalHoldDocument, ResourceID: id, Holds: []model.LegalHold{acmeHold}}
+		}
+	}
+	return nil
+}
+
+func (g *stubHoldGuard) GuardFolder(_ context.Context, _, folderID string, op service.LegalHoldOp) error {
+	if g.held[folderID] {
+		return &service.LegalHoldError{Op: op, ResourceType: model.LegalHoldFolder, ResourceID: folderID, Holds: []model.LegalHold{acmeHold}}
+	}
+	return nil
+}
+
+// stubChunkDeleter records chunk deletions.
+type stubChunkDeleter struct {
+	deleted []string
+}
+
+func (d *stubChunkDeleter) DeleteByDocumentID(_ context.Context, documentID string) error {
+	d.deleted = append(d.deleted, documentID)
+	return nil
+}
+
+func heldRequest(method, body string) *http.Request {
+	req := httptest.NewRequest(method, "/api/documents/"+heldDocID, strings.NewReader(body))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "user-1"))
+	return withChiParam(req, "id", heldDocID)
+}
+
+func TestLegalHold_RefusesDocumentChanges(t *testing.T) {
+	checksum := "abc123"
+	doc := &model.Document{ID: heldDocID, UserID: "user-1", IndexStatus: model.IndexPending, Checksum: &checksum}
+	guard := &stubHoldGuard{held: map[string]bool{heldDocID: true}}
+	chunks := &stubChunkDeleter{}
+	crud := DocCRUDDeps{DocRepo: &crudDocRepo{singleDoc: doc}, ChunkDeleter: chunks, Holds: guard}
+	pipeline := &mockIngester{}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		body    string
+		op      service.LegalHoldOp
+	}{
+		{"delete", DeleteDocument(crud), http.MethodDelete, "", service.LegalHoldOpDelete},
+		{"rename", UpdateDocument(crud), http.MethodPatch, `{"name":"renamed.pdf"}`, service.LegalHoldOpRename},
+		{"move", UpdateDocument(crud), http.MethodPatch, `{"folderId":""}`, service.LegalHoldOpMove},
+		{"delete chunks", DeleteChunks(crud), http.MethodDelete, "", service.LegalHoldOpDeleteChunks},
+		{"re-ingest", IngestDocument(IngestDeps{DocRepo: crud.DocRepo, Pipeline: pipeline, Holds: guard}), http.MethodPost, "", service.LegalHoldOpReingest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, heldRequest(tt.method, tt.body))
+			if rec.Code != http.StatusLocked {
+				t.Fatalf("status = %d, want 423 (body %s)", rec.Code, rec.Body.String())
+			}
+			var resp struct {
+				Error string `json:"error"`
+				Data  struct {
+					Operation service.LegalHoldOp `json:"operation"`
+					Holds     []legalHoldRef      `json:"holds"`
+				} `json:"data"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp.Data.Operation != tt.op || len(resp.Data.Holds) != 1 || resp.Data.Holds[0].MatterID != "M-1024" {
+				t.Errorf("body = %s", rec.Body.String())
+			}
+			if !strings.Contains(resp.Error, "Acme v. Widget") {
+				t.Errorf("error = %q, want the hold named", resp.Error)
+			}
+		})
+	}
+	if len(chunks.deleted) != 0 || pipeline.called {
+		t.Error("a refused change reached the repository or pipeline")
+	}
+}
+
+func TestLegalHold_FirstIngestIsAllowed(t *testing.T) {
+	doc := &model.Document{ID: heldDocID, UserID: "user-1", IndexStatus: model.IndexPending}
+	deps := IngestDeps{DocRepo: &crudDocRepo{singleDoc: doc}, Pipeline: &mockIngester{},
+		Holds: &stubHoldGuard{held: map[string]bool{heldDocID: true}}}
+
+	rec := httptest.NewRecorder()
+	IngestDocument(deps).ServeHTTP(rec, heldRequest(http.MethodPost, ""))
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want 202 for a document never ingested", rec.Code)
+	}
+}
+
+func TestLegalHold_DatabaseRefusalIsLocked(t *testing.T) {
+	repo := &crudDocRepo{
+		singleDoc: &model.Document{ID: heldDocID, UserID: "user-1"},
+		deleteErr: fmt.Errorf("repository.SoftDelete: %w: cannot delete document %s: under legal hold \"Acme v. Widget\" (matter M-1024)",
+			service.ErrUnderLegalHold, heldDocID),
+	}
+	// The guard check failing open leaves the triggers to refuse the change.
+	deps := DocCRUDDeps{DocRepo: repo, Holds: &stubHoldGuard{err: fmt.Errorf("connection refused")}}
+
+	rec := httptest.NewRecorder()
+	DeleteDocument(deps).ServeHTTP(rec, heldRequest(http.MethodDelete, ""))
+	if rec.Code != http.StatusLocked || !strings.Contains(rec.Body.String(), "cannot delete document "+heldDocID) ||
+		strings.Contains(rec.Body.String(), "repository.") {
+		t.Errorf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLegalHold_RefusesFolderDelete(t *testing.T) {
+	const folderID = "30000000-0000-0000-0000-000000000003"
+	guard := &stubHoldGuard{held: map[string]bool{folderID: true}}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/documents/folders/"+folderID, nil)
+	req = withChiParam(req.WithContext(middleware.WithUserID(req.Context(), "user-1")), "id", folderID)
+	rec := httptest.NewRecorder()
+	DeleteFolder(FolderDeps{FolderRepo: &stubFolderRepo{}, Holds: guard}).ServeHTTP(rec, req)
+	if rec.Code != http.StatusLocked {
+		t.Errorf("delete folder: status = %d, want 423", rec.Code)
+	}
+}
+
+func TestCreateLegalHold_ValidatesIDs(t *testing.T) {
+	body := `{"name":"Acme","matterId":"M-1","custodian":"J. Doe","documentIds":["../etc"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/legal-holds", strings.NewReader(body))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "user-1"))
+	rec := httptest.NewRecorder()
+	CreateLegalHold(LegalHoldDeps{}).ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestRespondLegalHoldServiceError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{service.ErrLegalHoldNotFound, http.StatusNotFound},
+		{service.ErrNotOrgMember, http.StatusNotFound},
+		{service.ErrOrgForbidden, http.StatusForbidden},
+		{service.ErrLegalHoldReleased, http.StatusConflict},
+		{fmt.Errorf("%w: reason required", service.ErrInvalidLegalHold), http.StatusBadRequest},
+		{fmt.Errorf("db down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		respondLegalHoldServiceError(rec, tt.err, "release legal hold")
+		if rec.Code != tt.want {
+			t.Errorf("%v: status = %d, want %d", tt.err, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/cache"
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// OrgDeps bundles dependencies for organization handlers.
+type OrgDeps struct {
+	Svc         *service.OrganizationService
+	Invalidator *cache.Invalidator // optional — drops a member's cached results when their access changes
+}
+
+// OrgRequest is the request body for creating or renaming an organization.
+type OrgRequest struct {
+	Name string `json:"name"`
+}
+
+// OrgMemberRoleRequest is the request body for changing a member's role.
+type OrgMemberRoleRequest struct {
+	Role model.UserRole `json:"role"`
+}
+
+// OrgInviteRequest is the request body for inviting a member.
+type OrgInviteRequest struct {
+	Email string         `json:"email"`
+	Role  model.UserRole `json:"role"`
+}
+
+// AcceptInviteRequest is the request body for accepting an invitation.
+// MoveContent opts in to moving the caller's personal content into the
+// organization; by default it stays in their personal scope.
+type AcceptInviteRequest struct {
+	Token       string `json:"token"`
+	MoveContent bool   `json:"moveContent"`
+}
+
+// orgResponse is the GET /api/org payload.
+type orgResponse struct {
+	Organization *model.Organization `json:"organization"`
+	Role         model.UserRole      `json:"role"`
+}
+
+// inviteResponse returns the invite token once, at creation.
+type inviteResponse struct {
+	Invite *model.OrgInvite `json:"invite"`
+	Token  string           `json:"token"`
+}
+
+// respondOrgError maps organization service errors to HTTP statuses.
+func respondOrgError(w http.ResponseWriter, err error, op string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrNotOrgMember), errors.Is(err, service.ErrMemberNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrOrgForbidden), errors.Is(err, service.ErrInviteWrongEmail):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrAlreadyInOrg), errors.Is(err, service.ErrLastPartner):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInviteInvalid):
+		status = http.StatusGone
+	case errors.Is(err, service.ErrInvalidOrgRole), errors.Is(err, service.ErrInvalidOrgRequest):
+		status = http.StatusBadRequest
+	}
+	if status == http.StatusInternalServerError {
+		slog.Error("[Org] "+op+" failed", "error", err)
+		respondJSON(w, status, envelope{Success: false, Error: op + " failed"})
+		return
+	}
+	respondJSON(w, status, envelope{Success: false, Error: err.Error()})
+}
+
+// GetOrganization handles GET /api/org.
+func GetOrganization(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		org, m, err := deps.Svc.Current(r.Context(), userID)
+		if err != nil {
+			respondOrgError(w, err, "get organization")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: orgResponse{Organization: org, Role: m.Role}})
+	}
+}
+
+// CreateOrganization handles POST /api/org.
+func CreateOrganization(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req OrgRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		org, err := deps.Svc.Create(r.Context(), userID, req.Name)
+		if err != nil {
+			respondOrgError(w, err, "create organization")
+			return
+		}
+		respondJSON(w, http.StatusCreated, envelope{Success: true, Data: orgResponse{Organization: org, Role: model.UserRolePartner}})
+	}
+}
+
+// RenameOrganization handles PATCH /api/org.
+func RenameOrganization(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req OrgRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		if err := deps.Svc.Rename(r.Context(), userID, req.Name); err != nil {
+			respondOrgError(w, err, "rename organization")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// ListOrgMembers handles GET /api/org/members.
+func ListOrgMembers(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		members, err := deps.Svc.ListMembers(r.Context(), userID)
+		if err != nil {
+			respondOrgError(w, err, "list members")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: members})
+	}
+}
+
+// UpdateOrgMember handles PATCH /api/org/members/{userId}.
+func UpdateOrgMember(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req OrgMemberRoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		targetID := chi.URLParam(r, "userId")
+		if err := deps.Svc.SetMemberRole(r.Context(), userID, targetID, req.Role); err != nil {
+			respondOrgError(w, err, "update member")
+			return
+		}
+		// Cached answers were built with the old role's vault access.
+		deps.Invalidator.InvalidateUser(r.Context(), targetID)
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// RemoveOrgMember handles DELETE /api/org/members/{userId}. Members may
+// remove themselves to leave the organization.
+func RemoveOrgMember(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		targetID := chi.URLParam(r, "userId")
+		if err := deps.Svc.RemoveMember(r.Context(), userID, targetID); err != nil {
+			respondOrgError(w, err, "remove member")
+			return
+		}
+		// The removed member must stop seeing cached org answers.
+		deps.Invalidator.InvalidateUser(r.Context(), targetID)
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// CreateOrgInvite handles POST /api/org/invites.
+func CreateOrgInvite(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req OrgInviteRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		inv, token, err := deps.Svc.Invite(r.Context(), userID, req.Email, req.Role)
+		if err != nil {
+			respondOrgError(w, err, "create invite")
+			return
+		}
+		respondJSON(w, http.StatusCreated, envelope{Success: true, Data: inviteResponse{Invite: inv, Token: token}})
+	}
+}
+
+// ListOrgInvites handles GET /api/org/invites.
+func ListOrgInvites(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		invites, err := deps.Svc.ListInvites(r.Context(), userID)
+		if err != nil {
+			respondOrgError(w, err, "list invites")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: invites})
+	}
+}
+
+// RevokeOrgInvite handles DELETE /api/org/invites/{id}.
+func RevokeOrgInvite(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		inviteID := chi.URLParam(r, "id")
+		if !validateUUID(inviteID) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid invite ID format"})
+			return
+		}
+
+		if err := deps.Svc.RevokeInvite(r.Context(), userID, inviteID); err != nil {
+			respondOrgError(w, err, "revoke invite")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// AcceptOrgInvite handles POST /api/org/invites/accept.
+func AcceptOrgInvite(deps OrgDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req AcceptInviteRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		org, err := deps.Svc.AcceptInvite(r.Context(), userID, req.Token, req.MoveContent)
+		if err != nil {
+			respondOrgError(w, err, "accept invite")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: org})
+	}
+}
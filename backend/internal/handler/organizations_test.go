@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/cache"
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+const orgDocID = "10000000-0000-0000-0000-000000000001"
+
+// orgRequest builds a request for userID in orgID with role, targeting orgDocID.
+func orgRequest(method, userID, orgID string, role model.UserRole) *http.Request {
+	req := httptest.NewRequest(method, "/api/documents/"+orgDocID, nil)
+	ctx := middleware.WithUserID(req.Context(), userID)
+	if orgID != "" {
+		ctx = middleware.WithOrg(ctx, orgID, role)
+	}
+	return withChiParam(req.WithContext(ctx), "id", orgDocID)
+}
+
+func TestDocumentAccess_OrgIsolation(t *testing.T) {
+	doc := &model.Document{ID: orgDocID, UserID: "owner", OrgID: "org-a", Filename: "brief.pdf"}
+
+	tests := []struct {
+		name    string
+		handler func(DocCRUDDeps) http.HandlerFunc
+		method  string
+		userID  string
+		orgID   string
+		role    model.UserRole
+		want    int
+	}{
+		{"owner reads", GetDocument, http.MethodGet, "owner", "org-a", model.UserRolePartner, http.StatusOK},
+		{"same-org associate reads", GetDocument, http.MethodGet, "assoc", "org-a", model.UserRoleAssociate, http.StatusOK},
+		{"same-org auditor reads", GetDocument, http.MethodGet, "aud", "org-a", model.UserRoleAuditor, http.StatusOK},
+		{"other org denied", GetDocument, http.MethodGet, "intruder", "org-b", model.UserRolePartner, http.StatusForbidden},
+		{"personal scope denied", GetDocument, http.MethodGet, "solo", model.PersonalOrgID("solo"), "", http.StatusForbidden},
+		{"no org context denied", GetDocument, http.MethodGet, "solo", "", "", http.StatusForbidden},
+		{"same-org associate cannot delete", DeleteDocument, http.MethodDelete, "assoc", "org-a", model.UserRoleAssociate, http.StatusNotFound},
+		{"same-org partner deletes", DeleteDocument, http.MethodDelete, "partner", "org-a", model.UserRolePartner, http.StatusOK},
+		{"other-org partner cannot delete", DeleteDocument, http.MethodDelete, "intruder", "org-b", model.UserRolePartner, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := DocCRUDDeps{DocRepo: &crudDocRepo{singleDoc: doc}}
+			rec := httptest.NewRecorder()
+			tt.handler(deps).ServeHTTP(rec, orgRequest(tt.method, tt.userID, tt.orgID, tt.role))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestDocumentAccess_PersonalDocsNotShared(t *testing.T) {
+	// A personal scope is never shared, even when the caller's context
+	// carries the same scope string.
+	doc := &model.Document{ID: orgDocID, UserID: "u1", OrgID: model.PersonalOrgID("u1")}
+	ctx := middleware.WithOrg(context.Background(), model.PersonalOrgID("u1"), "")
+	if canReadDocument(ctx, nil, doc, "u2", false) {
+		t.Error("personal document readable by another user")
+	}
+}
+
+func TestDocumentAccess_PrivilegedDocs(t *testing.T) {
+	doc := &model.Document{ID: orgDocID, UserID: "owner", OrgID: "org-a", Filename: "brief.pdf", IsPrivileged: true}
+	state := NewPrivilegeState()
+	state.modes["partner-on"] = true
+
+	tests := []struct {
+		name    string
+		handler func(DocCRUDDeps) http.HandlerFunc
+		userID  string
+		role    model.UserRole
+		want    int
+	}{
+		{"owner reads own privileged doc", GetDocument, "owner", model.UserRoleAssociate, http.StatusOK},
+		{"member outside Privileged Mode denied", GetDocument, "assoc", model.UserRoleAssociate, http.StatusForbidden},
+		{"partner outside Privileged Mode denied", GetDocument, "partner-off", model.UserRolePartner, http.StatusForbidden},
+		{"partner in Privileged Mode reads", GetDocument, "partner-on", model.UserRolePartner, http.StatusOK},
+		{"chunks denied outside Privileged Mode", ListChunks, "assoc", model.UserRoleAssociate, http.StatusForbidden},
+		{"download denied outside Privileged Mode", DownloadDocument, "assoc", model.UserRoleAssociate, http.StatusNotFound},
+		{"integrity denied outside Privileged Mode", VerifyIntegrity, "assoc", model.UserRoleAssociate, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := DocCRUDDeps{DocRepo: &crudDocRepo{singleDoc: doc}, PrivilegeState: state}
+			rec := httptest.NewRecorder()
+			tt.handler(deps).ServeHTTP(rec, orgRequest(http.MethodGet, tt.userID, "org-a", tt.role))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestListDocuments_PrivilegeModeFromServerState(t *testing.T) {
+	state := NewPrivilegeState()
+	state.modes["partner-on"] = true
+
+	for _, tt := range []struct {
+		userID string
+		want   bool
+	}{
+		{"assoc", false},
+		{"partner-on", true},
+	} {
+		repo := &crudDocRepo{}
+		req := httptest.NewRequest(http.MethodGet, "/api/documents?privilegeMode=true", nil)
+		req = req.WithContext(middleware.WithUserID(req.Context(), tt.userID))
+		rec := httptest.NewRecorder()
+		ListDocuments(DocCRUDDeps{DocRepo: repo, PrivilegeState: state}).ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", tt.userID, rec.Code)
+		}
+		if repo.listOpts.PrivilegeMode != tt.want {
+			t.Errorf("%s: PrivilegeMode = %v, want %v (query param must be ignored)", tt.userID, repo.listOpts.PrivilegeMode, tt.want)
+		}
+	}
+}
+
+func TestRespondOrgError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{service.ErrNotOrgMember, http.StatusNotFound},
+		{service.ErrMemberNotFound, http.StatusNotFound},
+		{service.ErrOrgForbidden, http.StatusForbidden},
+		{service.ErrInviteWrongEmail, http.StatusForbidden},
+		{service.ErrAlreadyInOrg, http.StatusConflict},
+		{service.ErrLastPartner, http.StatusConflict},
+		{service.ErrInviteInvalid, http.StatusGone},
+		{fmt.Errorf("%w: name", service.ErrInvalidOrgRequest), http.StatusBadRequest},
+		{service.ErrInvalidOrgRole, http.StatusBadRequest},
+		{errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		respondOrgError(rec, tt.err, "test")
+		if rec.Code != tt.want {
+			t.Errorf("%v: status = %d, want %d", tt.err, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestOrgHandlers_Unauthorized(t *testing.T) {
+	deps := OrgDeps{}
+	for name, h := range map[string]http.HandlerFunc{
+		"get":    GetOrganization(deps),
+		"create": CreateOrganization(deps),
+		"invite": CreateOrgInvite(deps),
+		"accept": AcceptOrgInvite(deps),
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/org", nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, rec.Code)
+		}
+	}
+}
+
+// memOrgRepo implements service.OrganizationRepository over a member map.
+type memOrgRepo struct {
+	members map[string]model.OrgMember
+}
+
+func (m *memOrgRepo) Create(ctx context.Context, org *model.Organization, creatorID string) error {
+	return nil
+}
+
+func (m *memOrgRepo) GetByID(ctx context.Context, id string) (*model.Organization, error) {
+	return &model.Organization{ID: id}, nil
+}
+
+func (m *memOrgRepo) Rename(ctx context.Context, id, name string) error { return nil }
+
+func (m *memOrgRepo) Membership(ctx context.Context, userID string) (*model.OrgMember, error) {
+	if mem, ok := m.members[userID]; ok {
+		return &mem, nil
+	}
+	return nil, nil
+}
+
+func (m *memOrgRepo) ListMembers(ctx context.Context, orgID string) ([]model.OrgMember, error) {
+	var out []model.OrgMember
+	for _, mem := range m.members {
+		if mem.OrgID == orgID {
+			out = append(out, mem)
+		}
+	}
+	return out, nil
+}
+
+func (m *memOrgRepo) SetMemberRole(ctx context.Context, orgID, userID string, role model.UserRole) error {
+	mem := m.members[userID]
+	mem.Role = role
+	m.members[userID] = mem
+	return nil
+}
+
+func (m *memOrgRepo) RemoveMember(ctx context.Context, orgID, userID string) error {
+	delete(m.members, userID)
+	return nil
+}
+
+func (m *memOrgRepo) CreateInvite(ctx context.Context, inv *model.OrgInvite, tokenHash string) error {
+	return nil
+}
+
+func (m *memOrgRepo) ListInvites(ctx context.Context, orgID string) ([]model.OrgInvite, error) {
+	return nil, nil
+}
+
+func (m *memOrgRepo) RevokeInvite(ctx context.Context, orgID, inviteID string) error { return nil }
+
+func (m *memOrgRepo) InviteByTokenHash(ctx context.Context, tokenHash string) (*model.OrgInvite, error) {
+	return nil, nil
+}
+
+func (m *memOrgRepo) AcceptInvite(ctx context.Context, inviteID, userID string, moveContent bool) error {
+	return nil
+}
+
+func (m *memOrgRepo) UsageAccount(ctx context.Context, userID string) (string, error) {
+	return userID, nil
+}
+
+func TestOrgMemberChanges_InvalidateMemberCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(OrgDeps) http.HandlerFunc
+		method  string
+		body    string
+	}{
+		{"remove", RemoveOrgMember, http.MethodDelete, ""},
+		{"demote", UpdateOrgMember, http.MethodPatch, `{"role":"Associate"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qc := cache.New(time.Hour)
+			defer qc.Stop()
+			qc.Set("p2", "q", false, &service.RetrievalResult{})
+			qc.Set("p1", "q", false, &service.RetrievalResult{})
+
+			repo := &memOrgRepo{members: map[string]model.OrgMember{
+				"p1": {OrgID: "org-a", UserID: "p1", Role: model.UserRolePartner},
+				"p2": {OrgID: "org-a", UserID: "p2", Role: model.UserRolePartner},
+			}}
+			deps := OrgDeps{
+				Svc:         service.NewOrganizationService(repo, nil),
+				Invalidator: cache.NewInvalidator(qc, nil, nil),
+			}
+
+			req := httptest.NewRequest(tt.method, "/api/org/members/p2", strings.NewReader(tt.body))
+			req = withChiParam(req.WithContext(middleware.WithUserID(req.Context(), "p1")), "userId", "p2")
+			rec := httptest.NewRecorder()
+			tt.handler(deps).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+			}
+			if _, ok := qc.Get("p2", "q", false); ok {
+				t.Error("target member's cached results should be dropped")
+			}
+			if _, ok := qc.Get("p1", "q", false); !ok {
+				t.Error("acting partner's cached results must survive")
+			}
+		})
+	}
+}
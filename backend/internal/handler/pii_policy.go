@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/cache"
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// PIIPolicyDeps bundles dependencies for vault PII redaction policy handlers.
+type PIIPolicyDeps struct {
+	VaultRepo   service.VaultRepository
+	Svc         *service.PIIRedactionService
+	Invalidator *cache.Invalidator // optional — drops answers cached under the old policy
+}
+
+func (deps PIIPolicyDeps) vaultDeps() VaultDeps {
+	return VaultDeps{VaultRepo: deps.VaultRepo, Invalidator: deps.Invalidator}
+}
+
+// SetPIIPolicyRequest is the request body for setting a vault's PII
+// redaction policy. An empty infoTypes turns redaction off for the vault.
+type SetPIIPolicyRequest struct {
+	InfoTypes   []string         `json:"infoTypes"`
+	ExemptRoles []model.UserRole `json:"exemptRoles"`
+}
+
+// piiPolicyResponse is the policy that applies to a vault's documents.
+type piiPolicyResponse struct {
+	Policy model.PIIRedactionPolicy `json:"policy"`
+	Source model.PIIPolicySource    `json:"source"`
+}
+
+// GetPIIPolicy handles GET /api/vaults/{id}/pii-policy: the vault's policy,
+// or the server default when the vault has none.
+func GetPIIPolicy(deps PIIPolicyDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		vaultID := chi.URLParam(r, "id")
+		if !validateVaultID(vaultID) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid vault ID format"})
+			return
+		}
+		if _, err := deps.VaultRepo.GetForUser(r.Context(), vaultID, userID); err != nil {
+			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "vault not found"})
+			return
+		}
+
+		policy, source, err := deps.Svc.VaultPolicy(r.Context(), vaultID)
+		if err != nil {
+			slog.Error("[PIIPolicy] get failed", "vault_id", vaultID, "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to get PII policy"})
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: piiPolicyResponse{Policy: policy, Source: source}})
+	}
+}
+
+// SetPIIPolicy handles PUT /api/vaults/{id}/pii-policy. Owner or Partner only.
+func SetPIIPolicy(deps PIIPolicyDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		vault, ok := writableVault(w, r, deps.vaultDeps(), userID)
+		if !ok {
+			return
+		}
+
+		var req SetPIIPolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		policy, err := deps.Svc.SetVaultPolicy(r.Context(), userID, vault.ID, req.InfoTypes, req.ExemptRoles)
+		if errors.Is(err, service.ErrInvalidPIIPolicy) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: err.Error()})
+			return
+		}
+		if err != nil {
+			slog.Error("[PIIPolicy] set failed", "vault_id", vault.ID, "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to set PII policy"})
+			return
+		}
+
+		// Cached answers were redacted under the old policy.
+		invalidateVaultDocuments(r, deps.vaultDeps(), userID, vault.ID)
+
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: piiPolicyResponse{Policy: *policy, Source: model.PIIPolicyFromVault}})
+	}
+}
+
+// DeletePIIPolicy handles DELETE /api/vaults/{id}/pii-policy, returning the
+// vault to the server default. Owner or Partner only.
+func DeletePIIPolicy(deps PIIPolicyDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		vault, ok := writableVault(w, r, deps.vaultDeps(), userID)
+		if !ok {
+			return
+		}
+
+		deleted, err := deps.Svc.DeleteVaultPolicy(r.Context(), userID, vault.ID)
+		if err != nil {
+			slog.Error("[PIIPolicy] delete failed", "vault_id", vault.ID, "error", err)
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to delete PII policy"})
+			return
+		}
+		if !deleted {
+			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "vault has no PII policy"})
+			return
+		}
+
+		invalidateVaultDocuments(r, deps.vaultDeps(), userID, vault.ID)
+
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: piiPolicyResponse{Policy: deps.Svc.DefaultPolicy(), Source: model.PIIPolicyFromDefault}})
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+type memPIIPolicyRepo struct {
+	policies map[string]model.PIIRedactionPolicy
+}
+
+func (m *memPIIPolicyRepo) VaultPolicies(ctx context.Context, vaultIDs []string) (map[string]model.PIIRedactionPolicy, error) {
+	out := make(map[string]model.PIIRedactionPolicy)
+	for _, id := range vaultIDs {
+		if p, ok := m.policies[id]; ok {
+			out[id] = p
+		}
+	}
+	return out, nil
+}
+
+func (m *memPIIPolicyRepo) UpsertPolicy(ctx context.Context, p *model.PIIRedactionPolicy) error {
+	m.policies[p.VaultID] = *p
+	return nil
+}
+
+func (m *memPIIPolicyRepo) DeletePolicy(ctx context.Context, vaultID string) (bool, error) {
+	_, ok := m.policies[vaultID]
+	delete(m.policies, vaultID)
+	return ok, nil
+}
+
+func newPIIPolicyDeps() (PIIPolicyDeps, *memPIIPolicyRepo) {
+	repo := &memPIIPolicyRepo{policies: map[string]model.PIIRedactionPolicy{}}
+	defaults := model.PIIRedactionPolicy{InfoTypes: []string{"US_SOCIAL_SECURITY_NUMBER"}}
+	svc := service.NewPIIRedactionService(repo, nil, nil, nil, defaults)
+	return PIIPolicyDeps{VaultRepo: newMemVaultRepo(), Svc: svc}, repo
+}
+
+func decodePIIPolicy(t *testing.T, rec *httptest.ResponseRecorder) piiPolicyResponse {
+	t.Helper()
+	var resp struct {
+		Data piiPolicyResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Data
+}
+
+func TestPIIPolicy_SetGetDelete(t *testing.T) {
+	deps, repo := newPIIPolicyDeps()
+
+	rec := httptest.NewRecorder()
+	GetPIIPolicy(deps).ServeHTTP(rec, vaultRequest(http.MethodGet, "v-open", "assoc", model.UserRoleAssociate, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get: status = %d, want 200", rec.Code)
+	}
+	if got := decodePIIPolicy(t, rec); got.Source != model.PIIPolicyFromDefault || len(got.Policy.InfoTypes) != 1 {
+		t.Errorf("get before set = %+v, want the default policy", got)
+	}
+
+	rec = httptest.NewRecorder()
+	SetPIIPolicy(deps).ServeHTTP(rec, vaultRequest(http.MethodPut, "v-open", "owner", model.UserRoleAssociate,
+		SetPIIPolicyRequest{InfoTypes: []string{"EMAIL_ADDRESS"}, ExemptRoles: []model.UserRole{model.UserRolePartner}}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("set: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if p := repo.policies["v-open"]; len(p.InfoTypes) != 1 || p.InfoTypes[0] != "EMAIL_ADDRESS" || p.UpdatedBy != "owner" {
+		t.Errorf("stored policy = %+v", p)
+	}
+
+	rec = httptest.NewRecorder()
+	GetPIIPolicy(deps).ServeHTTP(rec, vaultRequest(http.MethodGet, "v-open", "assoc", model.UserRoleAssociate, nil))
+	if got := decodePIIPolicy(t, rec); got.Source != model.PIIPolicyFromVault || got.Policy.InfoTypes[0] != "EMAIL_ADDRESS" {
+		t.Errorf("get after set = %+v, want the vault policy", got)
+	}
+
+	rec = httptest.NewRecorder()
+	DeletePIIPolicy(deps).ServeHTTP(rec, vaultRequest(http.MethodDelete, "v-open", "owner", model.UserRoleAssociate, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete: status = %d, want 200", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	DeletePIIPolicy(deps).ServeHTTP(rec, vaultRequest(http.MethodDelete, "v-open", "owner", model.UserRoleAssociate, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("second delete: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestPIIPolicy_Access(t *testing.T) {
+	deps, repo := newPIIPolicyDeps()
+	body := SetPIIPolicyRequest{InfoTypes: []string{"EMAIL_ADDRESS"}}
+
+	rec := httptest.NewRecorder()
+	SetPIIPolicy(deps).ServeHTTP(rec, vaultRequest(http.MethodPut, "v-open", "assoc", model.UserRoleAssociate, body))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("associate set: status = %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	GetPIIPolicy(deps).ServeHTTP(rec, vaultRequest(http.MethodGet, "v-secure", "partner", model.UserRolePartner, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("hidden vault get: status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	SetPIIPolicy(deps).ServeHTTP(rec, vaultRequest(http.MethodPut, "v-open", "partner", model.UserRolePartner,
+		SetPIIPolicyRequest{InfoTypes: []string{"SHOE_SIZE"}}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown info type: status = %d, want 400", rec.Code)
+	}
+	if len(repo.policies) != 0 {
+		t.Errorf("policies stored after rejected requests: %+v", repo.policies)
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"sync"
 
 	"github.com/connexus-ai/ragbox-backend/internal/middleware"
-	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/rbac"
 )
 
 // PrivilegeStore abstracts DB read/write for privilege mode (STORY-S04).
@@ -37,7 +37,11 @@ func NewPrivilegeStateWithStore(store PrivilegeStore) *PrivilegeState {
 // IsPrivileged returns the privilege state for a user (thread-safe).
 // Reads from cache first; on cache miss, reads from DB and populates cache.
 // Used by the chat handler to derive privilege from server state (STORY-S01 Gap 3).
+// A nil state reports false for everyone.
 func (ps *PrivilegeState) IsPrivileged(userID string) bool {
+	if ps == nil {
+		return false
+	}
 	ps.mu.RLock()
 	mode, cached := ps.modes[userID]
 	ps.mu.RUnlock()
@@ -71,17 +75,13 @@ type RoleChecker func(ctx context.Context, userID string) (string, error)
 
 // PrivilegeDeps bundles dependencies for privilege handlers (STORY-S01).
 type PrivilegeDeps struct {
-	State       *PrivilegeState
-	RoleChecker RoleChecker          // required — returns user role from DB
+	State *PrivilegeState
+	// RoleChecker returns the user role from the DB. Only consulted when the
+	// RBAC Policy middleware has not already resolved the role.
+	RoleChecker RoleChecker
 	AuditLogger PrivilegeAuditLogger // optional — nil disables audit logging
 }
 
-// privilegeAllowedRoles lists roles that may activate Privileged Mode (STORY-S01 Gap 1).
-var privilegeAllowedRoles = map[string]bool{
-	string(model.UserRolePartner): true,
-	"admin":                       true,
-}
-
 // GetPrivilege handles GET /api/privilege.
 func GetPrivilege(state *PrivilegeState) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -111,14 +111,19 @@ func TogglePrivilege(deps PrivilegeDeps) http.HandlerFunc {
 		}
 
 		// STORY-S01 Gap 1: RBAC — only Partner or admin may toggle Privileged Mode.
-		if deps.RoleChecker != nil {
-			role, err := deps.RoleChecker(r.Context(), userID)
+		// The Policy middleware normally enforces this and caches the role.
+		role := middleware.RoleFromContext(r.Context())
+		if role == "" && deps.RoleChecker != nil {
+			var err error
+			role, err = deps.RoleChecker(r.Context(), userID)
 			if err != nil {
 				slog.Error("[Privilege] role check failed", "user_id", userID, "error", err)
 				respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to verify permissions"})
 				return
 			}
-			if !privilegeAllowedRoles[role] {
+		}
+		if role != "" || deps.RoleChecker != nil {
+			if !rbac.Can(role, rbac.PermPrivilegeToggle) {
 				slog.Warn("[Privilege] RBAC denied — insufficient role",
 					"user_id", userID, "role", role)
 				respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "Insufficient permissions"})
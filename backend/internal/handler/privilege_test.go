@@ -152,6 +152,30 @@ func TestTogglePrivilege_AssociateDenied(t *testing.T) {
 	}
 }
 
+func TestTogglePrivilege_UsesRoleFromContext(t *testing.T) {
+	calls := 0
+	deps := PrivilegeDeps{
+		State: NewPrivilegeState(),
+		RoleChecker: func(context.Context, string) (string, error) {
+			calls++
+			return "Partner", nil
+		},
+	}
+
+	// The role resolved by the Policy middleware wins over the checker.
+	req := httptest.NewRequest(http.MethodPost, "/api/privilege", nil)
+	ctx := middleware.WithRole(middleware.WithUserID(req.Context(), "user-auditor"), "Auditor")
+	rec := httptest.NewRecorder()
+	TogglePrivilege(deps).ServeHTTP(rec, req.WithContext(ctx))
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403 for Auditor role", rec.Code)
+	}
+	if calls != 0 {
+		t.Errorf("RoleChecker called %d times, want 0 (role cached in context)", calls)
+	}
+}
+
 func TestGetPrivilege_Unauthorized(t *testing.T) {
 	state := NewPrivilegeState()
 	handler := GetPrivilege(state)
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// stubQuota implements service.QuotaEnforcer.
+type stubQuota struct {
+	decision service.QuotaDecision
+	err      error
+}
+
+func (s stubQuota) Enforce(context.Context, string, string, int64) (service.QuotaDecision, error) {
+	return s.decision, s.err
+}
+
+// memUsageRepo implements service.UsageRepository in memory.
+type memUsageRepo map[string]int64
+
+func (m memUsageRepo) Increment(ctx context.Context, userID, metric string) error {
+	return m.IncrementBy(ctx, userID, metric, 1)
+}
+
+func (m memUsageRepo) IncrementBy(_ context.Context, userID, metric string, amount int64) error {
+	m[userID+":"+metric] += amount
+	return nil
+}
+
+func (m memUsageRepo) GetUsage(_ context.Context, userID, metric string) (int64, error) {
+	return m[userID+":"+metric], nil
+}
+
+func (m memUsageRepo) GetAllUsage(context.Context, string) ([]service.UsageRecord, error) {
+	return nil, nil
+}
+
+var docQuotaErr = &service.QuotaError{
+	Metric: service.MetricDocumentsStored, Tier: "free", Used: 5, Limit: 5, Requested: 1,
+}
+
+func decodeQuotaBody(t *testing.T, rec *httptest.ResponseRecorder) service.QuotaErrorBody {
+	t.Helper()
+	if rec.Code != http.StatusPaymentRequired {
+		t.Fatalf("status = %d, want 402. body: %s", rec.Code, rec.Body.String())
+	}
+	var body service.QuotaErrorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return body
+}
+
+func TestUploadDocument_QuotaExceeded(t *testing.T) {
+	repo := &mockDocRepo{}
+	docSvc := service.NewDocumentService(&mockStorage{url: "https://signed"}, repo, "bucket", 15*time.Minute)
+	docSvc.SetQuota(stubQuota{err: docQuotaErr})
+
+	body := `{"filename":"report.pdf","contentType":"application/pdf","sizeBytes":1024}`
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/extract", bytes.NewBufferString(body))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "user-1"))
+	rec := httptest.NewRecorder()
+	UploadDocument(docSvc).ServeHTTP(rec, req)
+
+	got := decodeQuotaBody(t, rec)
+	if got.Error != "quota_exceeded" || got.Metric != service.MetricDocumentsStored || got.Limit != 5 {
+		t.Errorf("body = %+v", got)
+	}
+	if repo.created != nil {
+		t.Error("no document should be created over quota")
+	}
+}
+
+func TestUploadDocument_QuotaGraceWarns(t *testing.T) {
+	docSvc := service.NewDocumentService(&mockStorage{url: "https://signed"}, &mockDocRepo{}, "bucket", 15*time.Minute)
+	docSvc.SetQuota(stubQuota{decision: service.QuotaDecision{
+		Metric: service.MetricDocumentsStored, Tier: "starter", Used: 10, Limit: 10, Grace: true,
+	}})
+
+	body := `{"filename":"report.pdf","contentType":"application/pdf","sizeBytes":1024}`
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/extract", bytes.NewBufferString(body))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "user-1"))
+	rec := httptest.NewRecorder()
+	UploadDocument(docSvc).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if !bytes.Contains(rec.Body.Bytes(), []byte("quotaWarning")) {
+		t.Errorf("expected quotaWarning in body: %s", rec.Body.String())
+	}
+}
+
+func TestIngestDocument_QuotaExceeded(t *testing.T) {
+	repo := &crudDocRepo{
+		singleDoc: &model.Document{
+			ID:          "10000000-0000-0000-0000-000000000001",
+			UserID:      "user-1",
+			IndexStatus: model.IndexPending,
+		},
+	}
+	pipeline := &mockIngester{}
+	h := IngestDocument(IngestDeps{DocRepo: repo, Pipeline: pipeline, Quota: stubQuota{err: docQuotaErr}})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/doc-1/ingest", nil)
+	req = req.WithContext(middleware.WithUserID(req.Context(), "user-1"))
+	req = withChiParam(req, "id", "10000000-0000-0000-0000-000000000001")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	decodeQuotaBody(t, rec)
+	if pipeline.called {
+		t.Error("pipeline should not run over quota")
+	}
+}
+
+func TestTranscribe_MetersVoiceSeconds(t *testing.T) {
+	dg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"metadata":{"duration":61.2},"results":{"channels":[{"alternatives":[{"transcript":"hello","confidence":0.9}]}]}}`))
+	}))
+	defer dg.Close()
+
+	usage := memUsageRepo{}
+	svc := service.NewUsageService(usage)
+	svc.SetTierResolver(func(context.Context, string) string { return "starter" })
+	h := Transcribe(TranscribeDeps{DeepgramAPIKey: "k", DeepgramURL: dg.URL, UsageSvc: svc})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/voice/transcribe", bytes.NewReader([]byte("audio")))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "user-1"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200. body: %s", rec.Code, rec.Body.String())
+	}
+	if got := usage["user-1:"+service.MetricVoiceSeconds]; got != 62 {
+		t.Errorf("voice seconds recorded = %d, want 62", got)
+	}
+}
+
+func TestTranscribe_OverQuotaSkipsDeepgram(t *testing.T) {
+	var calls atomic.Int32
+	dg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer dg.Close()
+
+	// Free tier has no voice minutes.
+	svc := service.NewUsageService(memUsageRepo{})
+	h := Transcribe(TranscribeDeps{DeepgramAPIKey: "k", DeepgramURL: dg.URL, UsageSvc: svc})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/voice/transcribe", bytes.NewReader([]byte("audio")))
+	req = req.WithContext(middleware.WithUserID(req.Context(), "user-1"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := decodeQuotaBody(t, rec); got.Metric != service.MetricVoiceMinutes {
+		t.Errorf("metric = %q", got.Metric)
+	}
+	if calls.Load() != 0 {
+		t.Error("Deepgram should not be called over quota")
+	}
+}
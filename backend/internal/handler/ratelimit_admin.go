@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+)
+
+// RateLimitAdminDeps holds dependencies for the rate limit policy admin handlers.
+type RateLimitAdminDeps struct {
+	Policy     *middleware.PolicyLimiter
+	PolicyFile string // JSON policy path; empty = built-in default table
+}
+
+// GetRateLimitPolicy returns the active tier × route group policy.
+// GET /api/admin/ratelimits
+func GetRateLimitPolicy(deps RateLimitAdminDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if deps.Policy == nil {
+			respondJSON(w, http.StatusServiceUnavailable, envelope{Success: false, Error: "rate limit policy not configured"})
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: map[string]interface{}{
+			"policy":     deps.Policy.Policy(),
+			"policyFile": deps.PolicyFile,
+		}})
+	}
+}
+
+// ReloadRateLimitPolicy re-reads the policy file on this instance. Other
+// instances pick up the change on their next file poll.
+// POST /api/admin/ratelimits/reload
+func ReloadRateLimitPolicy(deps RateLimitAdminDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if deps.Policy == nil {
+			respondJSON(w, http.StatusServiceUnavailable, envelope{Success: false, Error: "rate limit policy not configured"})
+			return
+		}
+		if deps.PolicyFile == "" {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "no policy file configured (RATE_LIMIT_POLICY_FILE)"})
+			return
+		}
+
+		policy, err := middleware.LoadRateLimitPolicy(deps.PolicyFile)
+		if err == nil {
+			err = deps.Policy.Reload(policy)
+		}
+		if err != nil {
+			slog.Error("[RATELIMIT] admin reload failed", "path", deps.PolicyFile, "error", err)
+			respondJSON(w, http.StatusUnprocessableEntity, envelope{Success: false, Error: err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: map[string]interface{}{
+			"policy": deps.Policy.Policy(),
+		}})
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+)
+
+func TestReloadRateLimitPolicy(t *testing.T) {
+	p, err := middleware.NewPolicyLimiter(middleware.DefaultRateLimitPolicy(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	os.WriteFile(path, []byte(`{"defaultTier":"free","tiers":{"free":{"chat":{"requests":3,"window":"1m"}}}}`), 0o600)
+
+	h := ReloadRateLimitPolicy(RateLimitAdminDeps{Policy: p, PolicyFile: path})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/ratelimits/reload", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if got := p.Policy().Tiers["free"]["chat"].Requests; got != 3 {
+		t.Errorf("free chat requests = %d, want 3", got)
+	}
+
+	os.WriteFile(path, []byte(`{"defaultTier":"gold","tiers":{}}`), 0o600)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/admin/ratelimits/reload", nil))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid policy status = %d, want 422", rec.Code)
+	}
+	if p.Policy().DefaultTier != "free" {
+		t.Error("invalid policy must not replace the active one")
+	}
+}
+
+func TestReloadRateLimitPolicy_NoFile(t *testing.T) {
+	p, err := middleware.NewPolicyLimiter(middleware.DefaultRateLimitPolicy(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	rec := httptest.NewRecorder()
+	ReloadRateLimitPolicy(RateLimitAdminDeps{Policy: p}).ServeHTTP(rec,
+		httptest.NewRequest(http.MethodPost, "/api/admin/ratelimits/reload", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
@@ -12,8 +12,9 @@ import (
 
 // RelatedDocsDeps bundles dependencies for the related documents handler.
 type RelatedDocsDeps struct {
-	DocRepo  service.DocumentRepository
-	Searcher service.RelatedDocSearcher
+	DocRepo        service.DocumentRepository
+	Searcher       service.RelatedDocSearcher
+	PrivilegeState *PrivilegeState // server-side Privileged Mode; nil = never privileged
 }
 
 // RelatedDocuments handles GET /api/documents/{id}/related.
@@ -33,8 +34,9 @@ func RelatedDocuments(deps RelatedDocsDeps) http.HandlerFunc {
 			return
 		}
 
+		privileged := deps.PrivilegeState.IsPrivileged(userID)
 		doc, err := deps.DocRepo.GetByID(r.Context(), docID)
-		if err != nil || doc.UserID != userID {
+		if err != nil || !canReadDocument(r.Context(), deps.DocRepo, doc, userID, privileged) {
 			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found"})
 			return
 		}
@@ -44,7 +46,7 @@ func RelatedDocuments(deps RelatedDocsDeps) http.HandlerFunc {
 			limit = l
 		}
 
-		related, err := deps.Searcher.FindRelatedDocuments(r.Context(), docID, userID, limit)
+		related, err := deps.Searcher.FindRelatedDocuments(r.Context(), docID, userID, limit, !privileged)
 		if err != nil {
 			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to find related documents"})
 			return
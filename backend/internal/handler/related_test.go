@@ -16,11 +16,13 @@ import (
 
 // mockRelatedSearcher implements service.RelatedDocSearcher for testing.
 type mockRelatedSearcher struct {
-	results []service.RelatedDocument
-	err     error
+	results         []service.RelatedDocument
+	err             error
+	capturedExclude *bool
 }
 
-func (m *mockRelatedSearcher) FindRelatedDocuments(ctx context.Context, documentID, userID string, limit int) ([]service.RelatedDocument, error) {
+func (m *mockRelatedSearcher) FindRelatedDocuments(ctx context.Context, documentID, userID string, limit int, excludePrivileged bool) ([]service.RelatedDocument, error) {
+	m.capturedExclude = &excludePrivileged
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -222,3 +224,36 @@ func TestRelatedDocuments_CustomLimit(t *testing.T) {
 		t.Fatalf("status = %d, want 200", rec.Code)
 	}
 }
+
+func TestRelatedDocuments_PrivilegeModeFromServerState(t *testing.T) {
+	state := NewPrivilegeState()
+	state.modes["partner-on"] = true
+	for _, tt := range []struct {
+		userID      string
+		wantExclude bool
+	}{
+		{"assoc", true},
+		{"partner-on", false},
+	} {
+		// The query parameter is ignored; only the server-side state counts.
+		searcher := &mockRelatedSearcher{}
+		sourceDoc := &model.Document{ID: "10000000-0000-0000-0000-000000000001", UserID: tt.userID}
+		deps := RelatedDocsDeps{
+			DocRepo:        &crudDocRepo{singleDoc: sourceDoc},
+			Searcher:       searcher,
+			PrivilegeState: state,
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/10000000-0000-0000-0000-000000000001/related?privilegeMode=true", nil)
+		req = req.WithContext(middleware.WithUserID(req.Context(), tt.userID))
+		req = withChiParam(req, "id", "10000000-0000-0000-0000-000000000001")
+		rec := httptest.NewRecorder()
+		RelatedDocuments(deps).ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200. body: %s", tt.userID, rec.Code, rec.Body.String())
+		}
+		if searcher.capturedExclude == nil || *searcher.capturedExclude != tt.wantExclude {
+			t.Errorf("%s: excludePrivileged = %v, want %v", tt.userID, searcher.capturedExclude, tt.wantExclude)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// RetentionDeps bundles dependencies for retention handlers.
+type RetentionDeps struct {
+	Svc *service.RetentionService
+}
+
+// SetRetentionPolicyRequest is the request body for setting a retention
+// policy on a vault or a document type.
+type SetRetentionPolicyRequest struct {
+	VaultID       string `json:"vaultId,omitempty"`
+	DocumentType  string `json:"documentType,omitempty"`
+	RetentionDays *int   `json:"retentionDays"`
+}
+
+// respondRetentionError maps retention service errors to HTTP statuses.
+func respondRetentionError(w http.ResponseWriter, err error, op string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrRetentionPolicyNotFound), errors.Is(err, service.ErrNotOrgMember):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrOrgForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidRetentionPolicy):
+		status = http.StatusBadRequest
+	}
+	if status == http.StatusInternalServerError {
+		slog.Error("[Retention] "+op+" failed", "error", err)
+		respondJSON(w, status, envelope{Success: false, Error: op + " failed"})
+		return
+	}
+	respondJSON(w, status, envelope{Success: false, Error: err.Error()})
+}
+
+// ListRetentionPolicies handles GET /api/retention/policies.
+func ListRetentionPolicies(deps RetentionDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		policies, err := deps.Svc.ListPolicies(r.Context(), userID)
+		if err != nil {
+			respondRetentionError(w, err, "list retention policies")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: map[string]interface{}{
+			"policies":             policies,
+			"defaultRetentionDays": deps.Svc.DefaultDays(),
+		}})
+	}
+}
+
+// SetRetentionPolicy handles PUT /api/retention/policies.
+func SetRetentionPolicy(deps RetentionDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req SetRetentionPolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+		if req.RetentionDays == nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "retentionDays is required"})
+			return
+		}
+		if req.VaultID != "" && !validateVaultID(req.VaultID) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid vault ID format"})
+			return
+		}
+
+		policy, err := deps.Svc.SetPolicy(r.Context(), userID, service.RetentionPolicyRequest{
+			VaultID:       req.VaultID,
+			DocumentType:  req.DocumentType,
+			RetentionDays: *req.RetentionDays,
+		})
+		if err != nil {
+			respondRetentionError(w, err, "set retention policy")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: policy})
+	}
+}
+
+// DeleteRetentionPolicy handles DELETE /api/retention/policies/{id}.
+func DeleteRetentionPolicy(deps RetentionDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		policyID := chi.URLParam(r, "id")
+		if !validateUUID(policyID) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid retention policy ID format"})
+			return
+		}
+
+		if err := deps.Svc.DeletePolicy(r.Context(), userID, policyID); err != nil {
+			respondRetentionError(w, err, "delete retention policy")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// PurgePreview handles GET /api/retention/purge-preview?asOf=<RFC 3339>:
+// a dry run of the purge job for the caller's organization, listing the
+// expired documents it would hard-delete and those legal holds keep.
+func PurgePreview(deps RetentionDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var asOf time.Time
+		if v := r.URL.Query().Get("asOf"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "asOf must be an RFC 3339 timestamp"})
+				return
+			}
+			asOf = t
+		}
+
+		report, err := deps.Svc.Preview(r.Context(), userID, asOf)
+		if err != nil {
+			respondRetentionError(w, err, "purge preview")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: report})
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func TestRetentionHandlers_ValidateInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		target  string
+		body    string
+	}{
+		{"missing retentionDays", SetRetentionPolicy(RetentionDeps{}), http.MethodPut, "/api/retention/policies", `{"documentType":"memo"}`},
+		{"bad vault id", SetRetentionPolicy(RetentionDeps{}), http.MethodPut, "/api/retention/policies", `{"vaultId":"../x","retentionDays":7}`},
+		{"bad asOf", PurgePreview(RetentionDeps{}), http.MethodGet, "/api/retention/purge-preview?asOf=tomorrow", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req = req.WithContext(middleware.WithUserID(req.Context(), "user-1"))
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+}
+
+func TestRespondRetentionError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{service.ErrRetentionPolicyNotFound, http.StatusNotFound},
+		{service.ErrNotOrgMember, http.StatusNotFound},
+		{service.ErrOrgForbidden, http.StatusForbidden},
+		{fmt.Errorf("%w: retentionDays must be 0-36500", service.ErrInvalidRetentionPolicy), http.StatusBadRequest},
+		{fmt.Errorf("db down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		respondRetentionError(rec, tt.err, "set retention policy")
+		if rec.Code != tt.want {
+			t.Errorf("%v: status = %d, want %d", tt.err, rec.Code, tt.want)
+		}
+	}
+}
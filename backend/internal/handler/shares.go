@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/cache"
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// ShareDeps bundles dependencies for sharing handlers.
+type ShareDeps struct {
+	Svc            *service.SharingService
+	DocRepo        service.DocumentRepository
+	FolderRepo     service.FolderRepository
+	Invalidator    *cache.Invalidator // optional — drops grantees' cached results when access changes
+	PrivilegeState *PrivilegeState    // server-side Privileged Mode; nil = never privileged
+}
+
+// ShareRequest is the request body for sharing a document or folder.
+type ShareRequest struct {
+	GranteeType model.ShareGranteeType `json:"granteeType"`
+	GranteeID   string                 `json:"granteeId"`
+	Role        model.ShareRole        `json:"role"`                // default viewer
+	ExpiresAt   *time.Time             `json:"expiresAt,omitempty"` // nil = never expires
+}
+
+// ShareGroupRequest is the request body for creating a share group.
+type ShareGroupRequest struct {
+	Name string `json:"name"`
+}
+
+// ShareGroupMemberRequest is the request body for adding a group member.
+type ShareGroupMemberRequest struct {
+	UserID string `json:"userId"`
+}
+
+// respondShareError maps sharing service errors to HTTP statuses.
+func respondShareError(w http.ResponseWriter, err error, op string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrShareNotFound), errors.Is(err, service.ErrGranteeNotFound),
+		errors.Is(err, service.ErrShareGroupMissing), errors.Is(err, service.ErrNotOrgMember),
+		errors.Is(err, service.ErrMemberNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrOrgForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrShareGroupExists):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidShare):
+		status = http.StatusBadRequest
+	}
+	if status == http.StatusInternalServerError {
+		slog.Error("[Sharing] "+op+" failed", "error", err)
+		respondJSON(w, status, envelope{Success: false, Error: op + " failed"})
+		return
+	}
+	respondJSON(w, status, envelope{Success: false, Error: err.Error()})
+}
+
+// ListShares handles GET /api/documents/{id}/shares and GET /api/folders/{id}/shares.
+func ListShares(deps ShareDeps, kind model.ShareResourceType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		res, ok := managedShareResource(w, r, deps, kind, userID)
+		if !ok {
+			return
+		}
+
+		grants, err := deps.Svc.ListGrants(r.Context(), res)
+		if err != nil {
+			respondShareError(w, err, "list shares")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: grants})
+	}
+}
+
+// CreateShare handles POST /api/documents/{id}/shares and POST /api/folders/{id}/shares.
+// Re-sharing with the same grantee updates the role and expiry.
+func CreateShare(deps ShareDeps, kind model.ShareResourceType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		res, ok := managedShareResource(w, r, deps, kind, userID)
+		if !ok {
+			return
+		}
+
+		var req ShareRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		grant, err := deps.Svc.Grant(r.Context(), userID, res, service.GrantRequest{
+			GranteeType: req.GranteeType,
+			GranteeID:   req.GranteeID,
+			Role:        req.Role,
+			ExpiresAt:   req.ExpiresAt,
+		})
+		if err != nil {
+			respondShareError(w, err, "share")
+			return
+		}
+
+		// The grantees' corpus grew; their cached answers may be incomplete.
+		invalidateGrantees(r, deps, grant)
+		respondJSON(w, http.StatusCreated, envelope{Success: true, Data: grant})
+	}
+}
+
+// RevokeShare handles DELETE /api/documents/{id}/shares/{grantId} and
+// DELETE /api/folders/{id}/shares/{grantId}.
+func RevokeShare(deps ShareDeps, kind model.ShareResourceType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		res, ok := managedShareResource(w, r, deps, kind, userID)
+		if !ok {
+			return
+		}
+
+		grant, err := deps.Svc.Revoke(r.Context(), userID, res, chi.URLParam(r, "grantId"))
+		if err != nil {
+			respondShareError(w, err, "revoke share")
+			return
+		}
+
+		// Cached answers must not keep serving content the grantees lost.
+		invalidateGrantees(r, deps, grant)
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// ListShareGroups handles GET /api/share-groups.
+func ListShareGroups(deps ShareDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		groups, err := deps.Svc.ListGroups(r.Context(), userID)
+		if err != nil {
+			respondShareError(w, err, "list share groups")
+			return
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: groups})
+	}
+}
+
+// CreateShareGroup handles POST /api/share-groups. Partner only.
+func CreateShareGroup(deps ShareDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req ShareGroupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		group, err := deps.Svc.CreateGroup(r.Context(), userID, req.Name)
+		if err != nil {
+			respondShareError(w, err, "create share group")
+			return
+		}
+		respondJSON(w, http.StatusCreated, envelope{Success: true, Data: group})
+	}
+}
+
+// DeleteShareGroup handles DELETE /api/share-groups/{id}. Partner only.
+func DeleteShareGroup(deps ShareDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		group, err := deps.Svc.DeleteGroup(r.Context(), userID, chi.URLParam(r, "id"))
+		if err != nil {
+			respondShareError(w, err, "delete share group")
+			return
+		}
+		for _, memberID := range group.MemberIDs {
+			deps.Invalidator.InvalidateUser(r.Context(), memberID)
+		}
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// AddShareGroupMember handles POST /api/share-groups/{id}/members. Partner only.
+func AddShareGroupMember(deps ShareDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req ShareGroupMemberRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "userId is required"})
+			return
+		}
+
+		if err := deps.Svc.AddGroupMember(r.Context(), userID, chi.URLParam(r, "id"), req.UserID); err != nil {
+			respondShareError(w, err, "add share group member")
+			return
+		}
+		deps.Invalidator.InvalidateUser(r.Context(), req.UserID)
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// RemoveShareGroupMember handles DELETE /api/share-groups/{id}/members/{userId}. Partner only.
+func RemoveShareGroupMember(deps ShareDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		memberID := chi.URLParam(r, "userId")
+		if err := deps.Svc.RemoveGroupMember(r.Context(), userID, chi.URLParam(r, "id"), memberID); err != nil {
+			respondShareError(w, err, "remove share group member")
+			return
+		}
+		deps.Invalidator.InvalidateUser(r.Context(), memberID)
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// managedShareResource loads the {id} document or folder and checks the
+// caller may manage its sharing (owner or Partner), writing the error
+// response when not. Callers who cannot see the resource get 404.
+func managedShareResource(w http.ResponseWriter, r *http.Request, deps ShareDeps, kind model.ShareResourceType, userID string) (service.ShareResource, bool) {
+	id := chi.URLParam(r, "id")
+	if !validateUUID(id) {
+		respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid ID format"})
+		return service.ShareResource{}, false
+	}
+
+	var orgID string
+	var readable, manageable bool
+	switch kind {
+	case model.ShareResourceFolder:
+		folder, err := deps.FolderRepo.GetByID(r.Context(), id)
+		if err == nil {
+			orgID = folder.OrgID
+			manageable = canManageFolder(r.Context(), folder, userID)
+			readable = manageable || canRead(r.Context(), userID, folder.UserID, folder.OrgID)
+		}
+	default:
+		doc, err := deps.DocRepo.GetByID(r.Context(), id)
+		if err == nil && doc.DeletionStatus == model.DeletionActive {
+			orgID = doc.OrgID
+			manageable = canManageDocument(r.Context(), doc, userID)
+			readable = manageable || canReadDocument(r.Context(), deps.DocRepo, doc, userID, deps.PrivilegeState.IsPrivileged(userID))
+		}
+	}
+
+	if !readable {
+		respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: string(kind) + " not found"})
+		return service.ShareResource{}, false
+	}
+	if !manageable {
+		respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "only the owner or a Partner can manage sharing"})
+		return service.ShareResource{}, false
+	}
+	return service.ShareResource{Type: kind, ID: id, OrgID: orgID}, true
+}
+
+// invalidateGrantees drops the cached results of every user the grant reaches.
+func invalidateGrantees(r *http.Request, deps ShareDeps, grant *model.ShareGrant) {
+	if deps.Invalidator == nil {
+		return
+	}
+	for _, uid := range deps.Svc.GranteeUserIDs(r.Context(), grant) {
+		deps.Invalidator.InvalidateUser(r.Context(), uid)
+	}
+}
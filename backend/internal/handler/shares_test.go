@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// sharedDocRepo is a crudDocRepo that resolves share grants from a map of
+// user ID to role.
+type sharedDocRepo struct {
+	crudDocRepo
+	roles map[string]model.ShareRole
+}
+
+func (m *sharedDocRepo) DocumentShareRole(_ context.Context, _, userID string) (model.ShareRole, error) {
+	return m.roles[userID], nil
+}
+
+func TestDocumentAccess_ShareGrants(t *testing.T) {
+	doc := &model.Document{ID: orgDocID, UserID: "owner", OrgID: "org-a", Filename: "contract.pdf",
+		DeletionStatus: model.DeletionActive}
+	roles := map[string]model.ShareRole{"viewer": model.ShareViewer, "editor": model.ShareEditor}
+
+	tests := []struct {
+		name    string
+		handler func(DocCRUDDeps) http.HandlerFunc
+		method  string
+		userID  string
+		want    int
+	}{
+		{"viewer reads", GetDocument, http.MethodGet, "viewer", http.StatusOK},
+		{"viewer cannot delete", DeleteDocument, http.MethodDelete, "viewer", http.StatusNotFound},
+		{"editor deletes", DeleteDocument, http.MethodDelete, "editor", http.StatusOK},
+		{"no grant denied", GetDocument, http.MethodGet, "stranger", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Grantees are outside the document's organization.
+			deps := DocCRUDDeps{DocRepo: &sharedDocRepo{crudDocRepo: crudDocRepo{singleDoc: doc}, roles: roles}}
+			rec := httptest.NewRecorder()
+			tt.handler(deps).ServeHTTP(rec, orgRequest(tt.method, tt.userID, "org-b", model.UserRolePartner))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestManageShares_OwnerOrPartnerOnly(t *testing.T) {
+	doc := &model.Document{ID: orgDocID, UserID: "owner", OrgID: "org-a", DeletionStatus: model.DeletionActive}
+	repo := &sharedDocRepo{crudDocRepo: crudDocRepo{singleDoc: doc},
+		roles: map[string]model.ShareRole{"editor": model.ShareEditor}}
+
+	tests := []struct {
+		name   string
+		userID string
+		orgID  string
+		role   model.UserRole
+		want   int
+	}{
+		{"editor grantee cannot manage", "editor", "org-b", model.UserRolePartner, http.StatusForbidden},
+		{"same-org associate cannot manage", "assoc", "org-a", model.UserRoleAssociate, http.StatusForbidden},
+		{"stranger sees nothing", "stranger", "org-b", model.UserRolePartner, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := ShareDeps{DocRepo: repo}
+			rec := httptest.NewRecorder()
+			ListShares(deps, model.ShareResourceDocument).ServeHTTP(rec, orgRequest(http.MethodGet, tt.userID, tt.orgID, tt.role))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRespondShareError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{service.ErrShareNotFound, http.StatusNotFound},
+		{service.ErrGranteeNotFound, http.StatusNotFound},
+		{service.ErrShareGroupMissing, http.StatusNotFound},
+		{service.ErrOrgForbidden, http.StatusForbidden},
+		{service.ErrShareGroupExists, http.StatusConflict},
+		{service.ErrInvalidShare, http.StatusBadRequest},
+		{context.DeadlineExceeded, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		respondShareError(rec, tt.err, "share")
+		if rec.Code != tt.want {
+			t.Errorf("%v: status = %d, want %d", tt.err, rec.Code, tt.want)
+		}
+	}
+}
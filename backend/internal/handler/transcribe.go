@@ -6,16 +6,32 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
 
+// deepgramListenURL is the Deepgram Nova pre-recorded STT endpoint.
+const deepgramListenURL = "https://api.deepgram.com/v1/listen?model=nova-2&smart_format=true&language=en"
+
+// opusBytesPerSecond estimates audio length (32 kbps Opus) when Deepgram
+// does not report a duration.
+const opusBytesPerSecond = 4000
+
 // TranscribeDeps holds dependencies for the transcribe handler.
 type TranscribeDeps struct {
 	DeepgramAPIKey string
+	DeepgramURL    string                // optional — defaults to deepgramListenURL
+	UsageSvc       *service.UsageService // optional — nil disables voice minute metering
 }
 
 // transcribeResponse is what Deepgram Nova returns (simplified).
 type deepgramResponse struct {
+	Metadata struct {
+		Duration float64 `json:"duration"` // seconds of audio
+	} `json:"metadata"`
 	Results struct {
 		Channels []struct {
 			Alternatives []struct {
@@ -29,7 +45,12 @@ type deepgramResponse struct {
 // Transcribe handles POST /api/voice/transcribe.
 // Accepts an audio blob (webm/opus from browser MediaRecorder),
 // sends it to Deepgram Nova STT, and returns the transcript.
+// Audio duration is metered against the voice_minutes quota.
 func Transcribe(deps TranscribeDeps) http.HandlerFunc {
+	listenURL := deps.DeepgramURL
+	if listenURL == "" {
+		listenURL = deepgramListenURL
+	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		if deps.DeepgramAPIKey == "" {
 			respondJSON(w, http.StatusNotImplemented, envelope{
@@ -58,6 +79,14 @@ func Transcribe(deps TranscribeDeps) http.HandlerFunc {
 			return
 		}
 
+		// Refuse before calling Deepgram when the user has no voice minutes
+		// left; any audio consumes at least part of a minute.
+		userID := middleware.UserIDFromContext(r.Context())
+		if deps.UsageSvc != nil && userID != "" &&
+			!enforceQuota(w, r, deps.UsageSvc, userID, service.MetricVoiceMinutes, 1) {
+			return
+		}
+
 		// Detect content type from request header, default to webm
 		contentType := r.Header.Get("Content-Type")
 		if contentType == "" {
@@ -66,8 +95,7 @@ func Transcribe(deps TranscribeDeps) http.HandlerFunc {
 
 		// Call Deepgram Nova REST API
 		dgReq, err := http.NewRequestWithContext(r.Context(), http.MethodPost,
-			"https://api.deepgram.com/v1/listen?model=nova-2&smart_format=true&language=en",
-			bytes.NewReader(audio))
+			listenURL, bytes.NewReader(audio))
 		if err != nil {
 			respondJSON(w, http.StatusInternalServerError, envelope{
 				Success: false,
@@ -119,11 +147,20 @@ func Transcribe(deps TranscribeDeps) http.HandlerFunc {
 			confidence = best.Confidence
 		}
 
+		seconds := int64(math.Ceil(dgResp.Metadata.Duration))
+		if seconds <= 0 {
+			seconds = int64(len(audio)/opusBytesPerSecond) + 1
+		}
+		if deps.UsageSvc != nil && userID != "" {
+			deps.UsageSvc.IncrementUsageBy(r.Context(), userID, service.MetricVoiceSeconds, seconds)
+		}
+
 		respondJSON(w, http.StatusOK, envelope{
 			Success: true,
 			Data: map[string]interface{}{
-				"transcript": transcript,
-				"confidence": confidence,
+				"transcript":      transcript,
+				"confidence":      confidence,
+				"durationSeconds": seconds,
 			},
 		})
 	}
@@ -3,6 +3,8 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/connexus-ai/ragbox-backend/internal/middleware"
@@ -41,3 +43,42 @@ func GetUsage(deps UsageDeps) http.HandlerFunc {
 		json.NewEncoder(w).Encode(report)
 	}
 }
+
+// respondQuotaError writes a 402 with the structured quota error body when
+// err is a *service.QuotaError, and reports whether it did.
+func respondQuotaError(w http.ResponseWriter, err error) bool {
+	var qe *service.QuotaError
+	if !errors.As(err, &qe) {
+		return false
+	}
+	respondJSON(w, http.StatusPaymentRequired, qe.Body())
+	return true
+}
+
+// setQuotaWarning adds an X-Quota-Warning header for requests served under
+// the grace policy.
+func setQuotaWarning(w http.ResponseWriter, d service.QuotaDecision) {
+	if msg := d.Warning(); msg != "" {
+		w.Header().Set("X-Quota-Warning", msg)
+	}
+}
+
+// enforceQuota checks that userID may consume amount of metric, writing a 402
+// when not. It reports whether the request may proceed; a nil enforcer or a
+// metering failure allows it, matching the chat handler's fail-open policy.
+func enforceQuota(w http.ResponseWriter, r *http.Request, q service.QuotaEnforcer, userID, metric string, amount int64) bool {
+	if q == nil {
+		return true
+	}
+	d, err := q.Enforce(r.Context(), userID, metric, amount)
+	if respondQuotaError(w, err) {
+		slog.Warn("quota exceeded", "user_id", userID, "metric", metric, "error", err)
+		return false
+	}
+	if err != nil {
+		slog.Error("quota check failed", "user_id", userID, "metric", metric, "error", err)
+		return true
+	}
+	setQuotaWarning(w, d)
+	return true
+}
@@ -16,6 +16,21 @@ func validateUUID(id string) bool {
 	return err == nil
 }
 
+// validateVaultID checks a vault ID. Vaults created by the web app use cuids
+// and vaults created by the backend use UUIDs, so any short identifier of
+// letters, digits, '-' and '_' is accepted.
+func validateVaultID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
 // sanitizeString trims whitespace and removes control characters.
 func sanitizeString(s string, maxLen int) string {
 	s = strings.TrimSpace(s)
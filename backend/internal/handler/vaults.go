@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/cache"
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+const (
+	maxVaultNameLength = 100
+	maxVaultMoveBatch  = 100
+)
+
+// VaultDeps bundles dependencies for vault handlers.
+type VaultDeps struct {
+	VaultRepo   service.VaultRepository
+	DocRepo     service.DocumentRepository
+	Invalidator *cache.Invalidator // optional — drops cached results for moved or restricted docs
+	Holds       LegalHoldGuard     // optional — refuses moving documents under legal hold
+}
+
+// CreateVaultRequest is the request body for creating a vault.
+type CreateVaultRequest struct {
+	Name   string            `json:"name"`
+	Status model.VaultStatus `json:"status,omitempty"` // default open
+}
+
+// UpdateVaultRequest is the request body for renaming a vault or changing its status.
+type UpdateVaultRequest struct {
+	Name   *string            `json:"name,omitempty"`
+	Status *model.VaultStatus `json:"status,omitempty"`
+}
+
+// MoveDocumentsRequest is the request body for moving documents into a vault.
+type MoveDocumentsRequest struct {
+	DocumentIDs []string `json:"documentIds"`
+}
+
+// ListVaults handles GET /api/vaults. ?archived=true includes archived vaults.
+func ListVaults(deps VaultDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		vaults, err := deps.VaultRepo.ListByUser(r.Context(), userID, r.URL.Query().Get("archived") == "true")
+		if err != nil {
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to list vaults"})
+			return
+		}
+
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: vaults})
+	}
+}
+
+// CreateVault handles POST /api/vaults.
+func CreateVault(deps VaultDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		var req CreateVaultRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "vault name is required"})
+			return
+		}
+		if len(req.Name) > maxVaultNameLength {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "vault name exceeds 100 character limit"})
+			return
+		}
+		if req.Status == "" {
+			req.Status = model.VaultOpen
+		}
+		if !model.ValidVaultStatus(req.Status) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "status must be open, closed or secure"})
+			return
+		}
+
+		now := time.Now().UTC()
+		vault := &model.Vault{
+			ID:        uuid.New().String(),
+			Name:      req.Name,
+			UserID:    userID,
+			Status:    req.Status,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		if err := deps.VaultRepo.Create(r.Context(), vault); err != nil {
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to create vault"})
+			return
+		}
+
+		respondJSON(w, http.StatusCreated, envelope{Success: true, Data: vault})
+	}
+}
+
+// GetVault handles GET /api/vaults/{id}.
+func GetVault(deps VaultDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		vaultID := chi.URLParam(r, "id")
+		if !validateVaultID(vaultID) {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid vault ID format"})
+			return
+		}
+
+		vault, err := deps.VaultRepo.GetForUser(r.Context(), vaultID, userID)
+		if err != nil {
+			respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "vault not found"})
+			return
+		}
+
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: vault})
+	}
+}
+
+// UpdateVault handles PATCH /api/vaults/{id}. Owner or Partner only.
+func UpdateVault(deps VaultDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		vault, ok := writableVault(w, r, deps, userID)
+		if !ok {
+			return
+		}
+
+		var req UpdateVaultRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+		if req.Name == nil && req.Status == nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "name or status is required"})
+			return
+		}
+
+		name, status := vault.Name, vault.Status
+		if req.Name != nil {
+			name = strings.TrimSpace(*req.Name)
+			if name == "" {
+				respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "name cannot be empty"})
+				return
+			}
+			if len(name) > maxVaultNameLength {
+				respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "vault name exceeds 100 character limit"})
+				return
+			}
+		}
+		if req.Status != nil {
+			if !model.ValidVaultStatus(*req.Status) {
+				respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "status must be open, closed or secure"})
+				return
+			}
+			status = *req.Status
+		}
+
+		if err := deps.VaultRepo.Update(r.Context(), vault.ID, name, status); err != nil {
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to update vault"})
+			return
+		}
+
+		// A stricter status must not leave the vault's documents in other
+		// members' cached answers.
+		if status != vault.Status {
+			invalidateVaultDocuments(r, deps, userID, vault.ID)
+		}
+
+		vault.Name, vault.Status = name, status
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: vault})
+	}
+}
+
+// ArchiveVault handles DELETE /api/vaults/{id}. The vault and its documents
+// are kept but drop out of listings and retrieval. Owner or Partner only.
+func ArchiveVault(deps VaultDeps) http.HandlerFunc {
+	return setVaultArchived(deps, true)
+}
+
+// RestoreVault handles POST /api/vaults/{id}/restore. Owner or Partner only.
+func RestoreVault(deps VaultDeps) http.HandlerFunc {
+	return setVaultArchived(deps, false)
+}
+
+func setVaultArchived(deps VaultDeps, archived bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		vault, ok := writableVault(w, r, deps, userID)
+		if !ok {
+			return
+		}
+
+		if err := deps.VaultRepo.SetArchived(r.Context(), vault.ID, archived); err != nil {
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to update vault"})
+			return
+		}
+
+		if archived {
+			invalidateVaultDocuments(r, deps, userID, vault.ID)
+		} else {
+			deps.Invalidator.InvalidateUser(r.Context(), userID)
+		}
+
+		respondJSON(w, http.StatusOK, envelope{Success: true})
+	}
+}
+
+// MoveDocumentsToVault handles POST /api/vaults/{id}/documents. The caller
+// must be able to modify every document and the target vault.
+func MoveDocumentsToVault(deps VaultDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.UserIDFromContext(r.Context())
+		if userID == "" {
+			respondJSON(w, http.StatusUnauthorized, envelope{Success: false, Error: "unauthorized"})
+			return
+		}
+
+		vault, ok := writableVault(w, r, deps, userID)
+		if !ok {
+			return
+		}
+		if vault.ArchivedAt != nil {
+			respondJSON(w, http.StatusConflict, envelope{Success: false, Error: "vault is archived"})
+			return
+		}
+
+		var req MoveDocumentsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid request body"})
+			return
+		}
+		if len(req.DocumentIDs) == 0 || len(req.DocumentIDs) > maxVaultMoveBatch {
+			respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "documentIds must contain 1-100 IDs"})
+			return
+		}
+
+		for _, docID := range req.DocumentIDs {
+			if !validateUUID(docID) {
+				respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid document ID format"})
+				return
+			}
+			doc, err := deps.DocRepo.GetByID(r.Context(), docID)
+			if err != nil || !canWriteDocument(r.Context(), deps.DocRepo, doc, userID) {
+				respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "document not found: " + docID})
+				return
+			}
+			if doc.OrgID != vault.OrgID {
+				respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "document and vault belong to different organizations"})
+				return
+			}
+		}
+
+		if !guardDocuments(w, r, deps.Holds, userID, service.LegalHoldOpMove, req.DocumentIDs...) {
+			return
+		}
+
+		moved, err := deps.VaultRepo.MoveDocuments(r.Context(), vault.ID, req.DocumentIDs)
+		if err != nil {
+			if respondLegalHoldError(w, err) {
+				return
+			}
+			respondJSON(w, http.StatusInternalServerError, envelope{Success: false, Error: "failed to move documents"})
+			return
+		}
+
+		// Moving changes which vault-scoped queries see the documents.
+		deps.Invalidator.InvalidateDocuments(r.Context(), userID, req.DocumentIDs...)
+		deps.Invalidator.InvalidateUser(r.Context(), userID)
+
+		respondJSON(w, http.StatusOK, envelope{Success: true, Data: map[string]int{"moved": moved}})
+	}
+}
+
+// writableVault loads the {id} vault and checks the caller may modify it,
+// writing the error response when not.
+func writableVault(w http.ResponseWriter, r *http.Request, deps VaultDeps, userID string) (*model.Vault, bool) {
+	vaultID := chi.URLParam(r, "id")
+	if !validateVaultID(vaultID) {
+		respondJSON(w, http.StatusBadRequest, envelope{Success: false, Error: "invalid vault ID format"})
+		return nil, false
+	}
+
+	vault, err := deps.VaultRepo.GetForUser(r.Context(), vaultID, userID)
+	if err != nil {
+		respondJSON(w, http.StatusNotFound, envelope{Success: false, Error: "vault not found"})
+		return nil, false
+	}
+	if !canWriteVault(r.Context(), vault, userID) {
+		respondJSON(w, http.StatusForbidden, envelope{Success: false, Error: "access denied"})
+		return nil, false
+	}
+	return vault, true
+}
+
+// invalidateVaultDocuments drops cached results built from the vault's documents.
+func invalidateVaultDocuments(r *http.Request, deps VaultDeps, userID, vaultID string) {
+	if deps.Invalidator == nil {
+		return
+	}
+	ids, err := deps.VaultRepo.DocumentIDs(r.Context(), vaultID)
+	if err != nil {
+		slog.Warn("[Vault] listing documents for cache invalidation failed", "vault_id", vaultID, "error", err)
+		deps.Invalidator.InvalidateUser(r.Context(), userID)
+		return
+	}
+	deps.Invalidator.InvalidateDocuments(r.Context(), userID, ids...)
+}
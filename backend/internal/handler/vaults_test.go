@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// memVaultRepo is an in-memory service.VaultRepository. Visibility follows
+// the migration 022 rules for a single organization.
+type memVaultRepo struct {
+	vaults   map[string]*model.Vault
+	partners map[string]bool
+	moved    []string
+	created  *model.Vault
+}
+
+func (m *memVaultRepo) visible(v *model.Vault, userID string) bool {
+	switch {
+	case v.UserID == userID:
+		return true
+	case v.Status == model.VaultOpen:
+		return true
+	case v.Status == model.VaultClosed:
+		return m.partners[userID]
+	}
+	return false
+}
+
+func (m *memVaultRepo) Create(ctx context.Context, vault *model.Vault) error {
+	m.created = vault
+	return nil
+}
+
+func (m *memVaultRepo) GetForUser(ctx context.Context, id, userID string) (*model.Vault, error) {
+	v, ok := m.vaults[id]
+	if !ok || !m.visible(v, userID) {
+		return nil, errors.New("no rows in result set")
+	}
+	cp := *v
+	return &cp, nil
+}
+
+func (m *memVaultRepo) ListByUser(ctx context.Context, userID string, includeArchived bool) ([]model.Vault, error) {
+	var out []model.Vault
+	for _, v := range m.vaults {
+		if m.visible(v, userID) && (includeArchived || v.ArchivedAt == nil) {
+			out = append(out, *v)
+		}
+	}
+	return out, nil
+}
+
+func (m *memVaultRepo) Update(ctx context.Context, id, name string, status model.VaultStatus) error {
+	m.vaults[id].Name, m.vaults[id].Status = name, status
+	return nil
+}
+
+func (m *memVaultRepo) SetArchived(ctx context.Context, id string, archived bool) error {
+	if archived {
+		now := time.Now()
+		m.vaults[id].ArchivedAt = &now
+	} else {
+		m.vaults[id].ArchivedAt = nil
+	}
+	return nil
+}
+
+func (m *memVaultRepo) MoveDocuments(ctx context.Context, vaultID string, docIDs []string) (int, error) {
+	m.moved = append(m.moved, docIDs...)
+	return len(docIDs), nil
+}
+
+func (m *memVaultRepo) DocumentIDs(ctx context.Context, vaultID string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *memVaultRepo) CanQuery(ctx context.Context, vaultID, userID string) (bool, error) {
+	v, ok := m.vaults[vaultID]
+	return ok && v.ArchivedAt == nil && m.visible(v, userID), nil
+}
+
+func newMemVaultRepo() *memVaultRepo {
+	return &memVaultRepo{
+		vaults: map[string]*model.Vault{
+			"v-open":   {ID: "v-open", Name: "Open", UserID: "owner", OrgID: "org-a", Status: model.VaultOpen},
+			"v-closed": {ID: "v-closed", Name: "Closed", UserID: "owner", OrgID: "org-a", Status: model.VaultClosed},
+			"v-secure": {ID: "v-secure", Name: "Secure", UserID: "owner", OrgID: "org-a", Status: model.VaultSecure},
+		},
+		partners: map[string]bool{"partner": true},
+	}
+}
+
+func vaultRequest(method, vaultID, userID string, role model.UserRole, body interface{}) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, "/api/vaults/id", &buf)
+	ctx := middleware.WithOrg(middleware.WithUserID(req.Context(), userID), "org-a", role)
+	return withChiParam(req.WithContext(ctx), "id", vaultID)
+}
+
+func TestGetVault_StatusVisibility(t *testing.T) {
+	tests := []struct {
+		vault  string
+		userID string
+		role   model.UserRole
+		want   int
+	}{
+		{"v-open", "assoc", model.UserRoleAssociate, http.StatusOK},
+		{"v-closed", "assoc", model.UserRoleAssociate, http.StatusNotFound},
+		{"v-closed", "partner", model.UserRolePartner, http.StatusOK},
+		{"v-secure", "partner", model.UserRolePartner, http.StatusNotFound},
+		{"v-secure", "owner", model.UserRoleAssociate, http.StatusOK},
+		{"bad id!", "owner", model.UserRoleAssociate, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vault+"/"+tt.userID, func(t *testing.T) {
+			deps := VaultDeps{VaultRepo: newMemVaultRepo()}
+			rec := httptest.NewRecorder()
+			GetVault(deps).ServeHTTP(rec, vaultRequest(http.MethodGet, tt.vault, tt.userID, tt.role, nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateVault_DefaultsAndValidation(t *testing.T) {
+	repo := newMemVaultRepo()
+	deps := VaultDeps{VaultRepo: repo}
+
+	rec := httptest.NewRecorder()
+	CreateVault(deps).ServeHTTP(rec, vaultRequest(http.MethodPost, "", "owner", model.UserRolePartner,
+		map[string]string{"name": "  Matters  "}))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201", rec.Code)
+	}
+	if repo.created.Name != "Matters" || repo.created.Status != model.VaultOpen {
+		t.Errorf("created = %+v, want trimmed name and open status", repo.created)
+	}
+
+	rec = httptest.NewRecorder()
+	CreateVault(deps).ServeHTTP(rec, vaultRequest(http.MethodPost, "", "owner", model.UserRolePartner,
+		map[string]string{"name": "X", "status": "hidden"}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid status: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestUpdateVault_RequiresOwnerOrPartner(t *testing.T) {
+	repo := newMemVaultRepo()
+	deps := VaultDeps{VaultRepo: repo}
+
+	rec := httptest.NewRecorder()
+	UpdateVault(deps).ServeHTTP(rec, vaultRequest(http.MethodPatch, "v-open", "assoc", model.UserRoleAssociate,
+		map[string]string{"status": "secure"}))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("associate: status = %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	UpdateVault(deps).ServeHTTP(rec, vaultRequest(http.MethodPatch, "v-open", "partner", model.UserRolePartner,
+		map[string]string{"status": "closed"}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("partner: status = %d, want 200", rec.Code)
+	}
+	if repo.vaults["v-open"].Status != model.VaultClosed {
+		t.Errorf("status = %s, want closed", repo.vaults["v-open"].Status)
+	}
+}
+
+func TestArchiveAndRestoreVault(t *testing.T) {
+	repo := newMemVaultRepo()
+	deps := VaultDeps{VaultRepo: repo}
+
+	rec := httptest.NewRecorder()
+	ArchiveVault(deps).ServeHTTP(rec, vaultRequest(http.MethodDelete, "v-open", "owner", model.UserRoleAssociate, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("archive: status = %d, want 200", rec.Code)
+	}
+	if ok, _ := repo.CanQuery(context.Background(), "v-open", "owner"); ok {
+		t.Error("archived vault still queryable")
+	}
+	if vaults, _ := repo.ListByUser(context.Background(), "owner", false); len(vaults) != 2 {
+		t.Errorf("listed %d active vaults, want 2", len(vaults))
+	}
+
+	rec = httptest.NewRecorder()
+	RestoreVault(deps).ServeHTTP(rec, vaultRequest(http.MethodPost, "v-open", "owner", model.UserRoleAssociate, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore: status = %d, want 200", rec.Code)
+	}
+	if ok, _ := repo.CanQuery(context.Background(), "v-open", "owner"); !ok {
+		t.Error("restored vault not queryable")
+	}
+}
+
+func TestMoveDocumentsToVault(t *testing.T) {
+	tests := []struct {
+		name    string
+		vault   string
+		userID  string
+		role    model.UserRole
+		doc     *model.Document
+		archive bool
+		want    int
+	}{
+		{"owner moves", "v-open", "owner", model.UserRoleAssociate,
+			&model.Document{ID: orgDocID, UserID: "owner", OrgID: "org-a"}, false, http.StatusOK},
+		{"associate cannot move another's doc", "v-open", "assoc", model.UserRoleAssociate,
+			&model.Document{ID: orgDocID, UserID: "owner", OrgID: "org-a"}, false, http.StatusForbidden},
+		{"cross-org document rejected", "v-open", "owner", model.UserRoleAssociate,
+			&model.Document{ID: orgDocID, UserID: "owner", OrgID: "org-b"}, false, http.StatusForbidden},
+		{"archived target rejected", "v-open", "owner", model.UserRoleAssociate,
+			&model.Document{ID: orgDocID, UserID: "owner", OrgID: "org-a"}, true, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemVaultRepo()
+			if tt.archive {
+				repo.SetArchived(context.Background(), tt.vault, true)
+			}
+			deps := VaultDeps{VaultRepo: repo, DocRepo: &crudDocRepo{singleDoc: tt.doc}}
+			rec := httptest.NewRecorder()
+			MoveDocumentsToVault(deps).ServeHTTP(rec, vaultRequest(http.MethodPost, tt.vault, tt.userID, tt.role,
+				MoveDocumentsRequest{DocumentIDs: []string{orgDocID}}))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if moved := len(repo.moved) > 0; moved != (tt.want == http.StatusOK) {
+				t.Errorf("moved = %v, want %v", moved, tt.want == http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestValidateVaultID(t *testing.T) {
+	for _, id := range []string{"clx1abc2def", "10000000-0000-0000-0000-000000000001", "v_1"} {
+		if !validateVaultID(id) {
+			t.Errorf("validateVaultID(%q) = false, want true", id)
+		}
+	}
+	for _, id := range []string{"", "a b", "../etc", string(make([]byte, 65))} {
+		if validateVaultID(id) {
+			t.Errorf("validateVaultID(%q) = true, want false", id)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// stubTokens implements APITokenAuthenticator from a map of plaintext tokens.
+type stubTokens struct {
+	tokens map[string]*model.APIToken
+	err    error
+	used   chan string
+}
+
+func (s *stubTokens) Authenticate(_ context.Context, token string) (*model.APIToken, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if t, ok := s.tokens[token]; ok {
+		return t, nil
+	}
+	return nil, service.ErrAPITokenUnauthorized
+}
+
+func (s *stubTokens) RecordUse(_ context.Context, id string) error {
+	s.used <- id
+	return nil
+}
+
+func newStubTokens() *stubTokens {
+	return &stubTokens{
+		tokens: map[string]*model.APIToken{
+			model.APITokenPrefix + "good": {ID: "tok-1", UserID: "token-owner", Scopes: []model.APITokenScope{model.APIScopeRead}},
+		},
+		used: make(chan string, 1),
+	}
+}
+
+func TestInternalOrFirebaseAuth_APIToken(t *testing.T) {
+	// Firebase must never see a personal access token.
+	authSvc := service.NewAuthService(&mockAuthClient{err: fmt.Errorf("firebase should not be called")})
+	tokens := newStubTokens()
+
+	var gotToken *model.APIToken
+	var gotUser string
+	var apiCaller bool
+	h := InternalOrFirebaseAuth(authSvc, "", nil, tokens)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = UserIDFromContext(r.Context())
+		gotToken = APITokenFromContext(r.Context())
+		apiCaller = IsAPICaller(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/documents", nil)
+	req.Header.Set("Authorization", "Bearer "+model.APITokenPrefix+"good")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if gotUser != "token-owner" || gotToken == nil || gotToken.ID != "tok-1" || !apiCaller {
+		t.Errorf("context: user %q, token %v, api caller %v", gotUser, gotToken, apiCaller)
+	}
+	select {
+	case id := <-tokens.used:
+		if id != "tok-1" {
+			t.Errorf("RecordUse(%q), want tok-1", id)
+		}
+	case <-time.After(time.Second):
+		t.Error("token use was not recorded")
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+		want  int
+	}{
+		{"unknown token", model.APITokenPrefix + "bad", nil, http.StatusUnauthorized},
+		{"lookup failure", model.APITokenPrefix + "good", fmt.Errorf("db down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := newStubTokens()
+			tokens.err = tt.err
+			h := InternalOrFirebaseAuth(authSvc, "", nil, tokens)(newTestHandler())
+			req := httptest.NewRequest(http.MethodGet, "/api/documents", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireTokenScope(t *testing.T) {
+	h := RequireTokenScope(func(r *http.Request) model.APITokenScope {
+		if r.Method == http.MethodGet {
+			return model.APIScopeRead
+		}
+		return model.APIScopeUpload
+	})(okHandler())
+
+	readOnly := &model.APIToken{ID: "t1", Scopes: []model.APITokenScope{model.APIScopeRead}}
+	admin := &model.APIToken{ID: "t2", Scopes: []model.APITokenScope{model.APIScopeAdmin}}
+	tests := []struct {
+		name   string
+		method string
+		token  *model.APIToken
+		want   int
+	}{
+		{"session request", http.MethodPost, nil, http.StatusOK},
+		{"read token reads", http.MethodGet, readOnly, http.StatusOK},
+		{"read token cannot upload", http.MethodPost, readOnly, http.StatusForbidden},
+		{"admin token uploads", http.MethodPost, admin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/documents", nil)
+			ctx := WithUserID(req.Context(), "u1")
+			if tt.token != nil {
+				ctx = WithAPIToken(ctx, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req.WithContext(ctx))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimit_PerTokenIsolation(t *testing.T) {
+	rl := newTestRateLimiter(1, time.Minute)
+	defer rl.Stop()
+	handler := RateLimit(rl)(okHandler())
+
+	send := func(token *model.APIToken) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+		ctx := WithUserID(req.Context(), "user-A")
+		if token != nil {
+			ctx = WithAPIToken(ctx, token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(ctx))
+		return rec.Code
+	}
+
+	// The user's session and each of their tokens get separate budgets.
+	if code := send(nil); code != http.StatusOK {
+		t.Errorf("session: status = %d, want 200", code)
+	}
+	if code := send(&model.APIToken{ID: "tok-1"}); code != http.StatusOK {
+		t.Errorf("tok-1: status = %d, want 200", code)
+	}
+	if code := send(&model.APIToken{ID: "tok-1"}); code != http.StatusTooManyRequests {
+		t.Errorf("tok-1 again: status = %d, want 429", code)
+	}
+	if code := send(&model.APIToken{ID: "tok-2"}); code != http.StatusOK {
+		t.Errorf("tok-2: status = %d, want 200", code)
+	}
+}
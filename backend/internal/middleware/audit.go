@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// AuditLogger records audit entries. Implemented by *service.AuditService.
+type AuditLogger interface {
+	LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error
+}
+
+// Headers the Next.js proxy sets to the end user's address and user agent.
+// Its own requests would otherwise record the proxy's egress IP and the
+// Node fetch user agent.
+const (
+	clientIPHeader        = "X-Client-IP"
+	clientUserAgentHeader = "X-Client-User-Agent"
+)
+
+// AuditContext returns middleware that puts the caller's IP address and user
+// agent into the request context, where AuditService.LogWithDetails records
+// them on every entry logged while serving the request. Requests carrying
+// the internal auth secret come from the proxy, and the X-Client-IP and
+// X-Client-User-Agent headers it forwards take precedence; from anyone else
+// they are ignored, so a client cannot forge its recorded address.
+func AuditContext(internalSecret string) func(http.Handler) http.Handler {
+	secretBytes := []byte(internalSecret)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			meta := service.RequestMeta{
+				IPAddress: clientIP(r),
+				UserAgent: r.UserAgent(),
+			}
+			internal := r.Header.Get("X-Internal-Auth")
+			if len(secretBytes) > 0 && internal != "" && subtle.ConstantTimeCompare([]byte(internal), secretBytes) == 1 {
+				if ip := net.ParseIP(strings.TrimSpace(r.Header.Get(clientIPHeader))); ip != nil {
+					meta.IPAddress = ip.String()
+				}
+				if ua := r.Header.Get(clientUserAgentHeader); ua != "" {
+					meta.UserAgent = ua
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(service.WithRequestMeta(r.Context(), meta)))
+		})
+	}
+}
+
+// clientIP returns the caller's address. Behind Cloud Run the last
+// X-Forwarded-For entry is the one its front end appended; entries to its
+// left are supplied by the client and cannot be trusted.
+func clientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		parts := strings.Split(xff, ",")
+		if ip := net.ParseIP(strings.TrimSpace(parts[len(parts)-1])); ip != nil {
+			return ip.String()
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// AuditAction is the audit entry a route writes when it succeeds.
+type AuditAction struct {
+	Action       string
+	ResourceType string
+	// IDParam is the URL parameter holding the resource ID, if any.
+	IDParam string
+}
+
+// Audit writes an audit entry for every request that actionFor maps to an
+// action and that the handler completes with a non-error status. Entries
+// are written after the response, under a context that outlives the
+// request, so a client that disconnects cannot skip them. Must run after
+// auth so the entry carries the caller.
+func Audit(logger AuditLogger, actionFor func(r *http.Request) (AuditAction, bool)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			act, ok := actionFor(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			if sw.status >= http.StatusBadRequest {
+				return
+			}
+
+			resourceID := ""
+			if act.IDParam != "" {
+				resourceID = chi.URLParam(r, act.IDParam)
+			}
+			details := map[string]interface{}{
+				"method": r.Method,
+				"route":  chi.RouteContext(r.Context()).RoutePattern(),
+				"status": sw.status,
+			}
+			if t := APITokenFromContext(r.Context()); t != nil {
+				details["apiTokenId"] = t.ID
+			}
+			userID := UserIDFromContext(r.Context())
+			ctx := context.WithoutCancel(r.Context())
+			if err := logger.LogWithDetails(ctx, act.Action, userID, resourceID, act.ResourceType, details); err != nil {
+				slog.Error("[Audit] failed to record action", "action", act.Action, "user_id", userID, "error", err)
+			}
+		})
+	}
+}
+
+// SessionTracker records user activity. Implemented by *repository.UserRepo.
+type SessionTracker interface {
+	// TouchSession records activity by userID and reports whether it starts
+	// a session: the user's first activity, or the first after idle.
+	TouchSession(ctx context.Context, userID string, idle time.Duration) (bool, error)
+}
+
+// AuditLogin writes a USER_LOGIN entry for the first interactive request of
+// each session, a session ending after idle without requests. ID tokens are
+// refreshed silently, so a new token is not a login; personal access token
+// calls are API use, not logins, and are skipped. Must run after auth and
+// AuditContext.
+func AuditLogin(tracker SessionTracker, logger AuditLogger, idle time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			userID := UserIDFromContext(ctx)
+			if userID != "" && APITokenFromContext(ctx) == nil {
+				started, err := tracker.TouchSession(ctx, userID, idle)
+				if err != nil {
+					slog.Error("[Audit] session tracking failed", "user_id", userID, "error", err)
+				} else if started {
+					if err := logger.LogWithDetails(ctx, model.AuditUserLogin, userID, "", "session", nil); err != nil {
+						slog.Error("[Audit] failed to record login", "user_id", userID, "error", err)
+					}
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+type loggedAction struct {
+	action, userID, resourceID, resourceType string
+	details                                  map[string]interface{}
+	meta                                     service.RequestMeta
+}
+
+// stubAuditLogger implements AuditLogger, recording entries.
+type stubAuditLogger struct {
+	logged []loggedAction
+}
+
+func (s *stubAuditLogger) LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error {
+	meta, _ := service.RequestMetaFromContext(ctx)
+	s.logged = append(s.logged, loggedAction{action, userID, resourceID, resourceType, details, meta})
+	return nil
+}
+
+func TestAuditContext_ClientIP(t *testing.T) {
+	tests := []struct {
+		name, remoteAddr, xff, want string
+	}{
+		{"remote address", "198.51.100.4:5123", "", "198.51.100.4"},
+		{"front end appended", "169.254.1.1:443", "203.0.113.7", "203.0.113.7"},
+		{"spoofed entries ignored", "169.254.1.1:443", "10.0.0.1, 203.0.113.7", "203.0.113.7"},
+		{"garbage falls back", "198.51.100.4:5123", "not-an-ip", "198.51.100.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got service.RequestMeta
+			h := AuditContext("")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = service.RequestMetaFromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("User-Agent", "ragbox-test/1.0")
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got.IPAddress != tt.want || got.UserAgent != "ragbox-test/1.0" {
+				t.Errorf("meta = %+v, want IP %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditContext_ProxyHeaders(t *testing.T) {
+	const secret = "internal-secret"
+	tests := []struct {
+		name, auth, clientIP, clientUA string
+		wantIP, wantUA                 string
+	}{
+		{"proxy forwards the user", secret, "203.0.113.7", "Mozilla/5.0", "203.0.113.7", "Mozilla/5.0"},
+		{"without internal auth ignored", "", "203.0.113.7", "Mozilla/5.0", "198.51.100.4", "node"},
+		{"wrong secret ignored", "guess", "203.0.113.7", "Mozilla/5.0", "198.51.100.4", "node"},
+		{"invalid address ignored", secret, "not-an-ip", "", "198.51.100.4", "node"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got service.RequestMeta
+			h := AuditContext(secret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = service.RequestMetaFromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "198.51.100.4:5123"
+			req.Header.Set("User-Agent", "node")
+			if tt.auth != "" {
+				req.Header.Set("X-Internal-Auth", tt.auth)
+			}
+			req.Header.Set("X-Client-IP", tt.clientIP)
+			if tt.clientUA != "" {
+				req.Header.Set("X-Client-User-Agent", tt.clientUA)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got.IPAddress != tt.wantIP || got.UserAgent != tt.wantUA {
+				t.Errorf("meta = %+v, want IP %s and UA %s", got, tt.wantIP, tt.wantUA)
+			}
+		})
+	}
+}
+
+// auditRouter serves route with Audit, mapping it to act.
+func auditRouter(logger AuditLogger, method, route string, act AuditAction, status int) http.Handler {
+	r := chi.NewRouter()
+	r.Use(AuditContext(""))
+	r.Use(Audit(logger, func(req *http.Request) (AuditAction, bool) {
+		return act, act.Action != ""
+	}))
+	r.MethodFunc(method, route, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+	return r
+}
+
+func TestAudit(t *testing.T) {
+	download := AuditAction{Action: model.AuditDocumentDownload, ResourceType: "document", IDParam: "id"}
+	tests := []struct {
+		name   string
+		act    AuditAction
+		status int
+		token  *model.APIToken
+		want   bool
+	}{
+		{"success is logged", download, http.StatusOK, nil, true},
+		{"redirect is logged", download, http.StatusFound, nil, true},
+		{"token call records the token", download, http.StatusOK, &model.APIToken{ID: "tok-1"}, true},
+		{"failure is not logged", download, http.StatusNotFound, nil, false},
+		{"unmapped route is not logged", AuditAction{}, http.StatusOK, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &stubAuditLogger{}
+			h := auditRouter(logger, http.MethodGet, "/api/documents/{id}/download", tt.act, tt.status)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/documents/doc-9/download", nil)
+			req.RemoteAddr = "198.51.100.4:5123"
+			ctx := WithUserID(req.Context(), "u1")
+			if tt.token != nil {
+				ctx = WithAPIToken(ctx, tt.token)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+			if !tt.want {
+				if len(logger.logged) != 0 {
+					t.Errorf("logged %+v", logger.logged)
+				}
+				return
+			}
+			if len(logger.logged) != 1 {
+				t.Fatalf("logged %d entries, want 1", len(logger.logged))
+			}
+			e := logger.logged[0]
+			if e.action != model.AuditDocumentDownload || e.userID != "u1" || e.resourceID != "doc-9" || e.resourceType != "document" {
+				t.Errorf("entry = %+v", e)
+			}
+			if e.details["route"] != "/api/documents/{id}/download" || e.details["status"] != tt.status {
+				t.Errorf("details = %v", e.details)
+			}
+			if e.meta.IPAddress != "198.51.100.4" {
+				t.Errorf("entry logged without request metadata: %+v", e.meta)
+			}
+			if tt.token != nil && e.details["apiTokenId"] != tt.token.ID {
+				t.Errorf("details = %v, want apiTokenId", e.details)
+			}
+		})
+	}
+}
+
+// stubSessions implements SessionTracker.
+type stubSessions struct {
+	started bool
+	err     error
+	calls   int
+}
+
+func (s *stubSessions) TouchSession(context.Context, string, time.Duration) (bool, error) {
+	s.calls++
+	return s.started, s.err
+}
+
+func TestAuditLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		sessions  *stubSessions
+		token     *model.APIToken
+		wantCalls int
+		wantLogin bool
+	}{
+		{"new session", &stubSessions{started: true}, nil, 1, true},
+		{"ongoing session", &stubSessions{}, nil, 1, false},
+		{"tracking failure still serves", &stubSessions{err: errors.New("db down")}, nil, 1, false},
+		{"API token call", &stubSessions{started: true}, &model.APIToken{ID: "tok-1"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &stubAuditLogger{}
+			served := false
+			h := AuditLogin(tt.sessions, logger, 30*time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served = true
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/api/documents", nil)
+			ctx := WithUserID(req.Context(), "u1")
+			if tt.token != nil {
+				ctx = WithAPIToken(ctx, tt.token)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+			if !served {
+				t.Error("request not served")
+			}
+			if tt.sessions.calls != tt.wantCalls {
+				t.Errorf("TouchSession calls = %d, want %d", tt.sessions.calls, tt.wantCalls)
+			}
+			gotLogin := len(logger.logged) == 1 && logger.logged[0].action == model.AuditUserLogin
+			if gotLogin != tt.wantLogin {
+				t.Errorf("logged %+v, want login = %v", logger.logged, tt.wantLogin)
+			}
+		})
+	}
+}
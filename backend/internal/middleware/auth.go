@@ -4,16 +4,22 @@ import (
 	"context"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 	"unicode"
 
+	"github.com/connexus-ai/ragbox-backend/internal/model"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
 
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey    contextKey = "userID"
+	apiCallerKey contextKey = "apiCaller"
+)
 
 // UserEnsurer provisions a user record if it doesn't already exist.
 // Called by auth middleware after successful authentication.
@@ -21,6 +27,12 @@ type UserEnsurer interface {
 	EnsureUser(ctx context.Context, userID string) error
 }
 
+// IdentityEnsurer is implemented by user ensurers that also record the
+// email and role claims of an ID token (e.g. roles mapped from Okta groups).
+type IdentityEnsurer interface {
+	EnsureIdentity(ctx context.Context, id service.Identity) error
+}
+
 // UserIDFromContext retrieves the authenticated user ID from the request context.
 func UserIDFromContext(ctx context.Context) string {
 	uid, _ := ctx.Value(userIDKey).(string)
@@ -33,11 +45,32 @@ func WithUserID(ctx context.Context, uid string) context.Context {
 	return context.WithValue(ctx, userIDKey, uid)
 }
 
+// IsAPICaller reports whether the request came through the public API
+// (internal auth with X-Client-Type: api, or a personal access token)
+// rather than the web app.
+func IsAPICaller(ctx context.Context) bool {
+	v, _ := ctx.Value(apiCallerKey).(bool)
+	return v
+}
+
+// WithAPICaller marks ctx as a public API request.
+func WithAPICaller(ctx context.Context) context.Context {
+	return context.WithValue(ctx, apiCallerKey, true)
+}
+
 // InternalOrFirebaseAuth returns middleware that first checks for an internal
 // service-to-service token (X-Internal-Auth header + X-User-ID), falling back
 // to Firebase ID token verification. The internal path is used by the Next.js
 // proxy routes that have already validated the user session.
-func InternalOrFirebaseAuth(authService *service.AuthService, secret string, ensurer UserEnsurer) func(http.Handler) http.Handler {
+//
+// ID tokens are verified by authService, which routes them to Firebase or
+// to the OIDC provider matching their issuer.
+//
+// Bearer tokens starting with model.APITokenPrefix are personal access
+// tokens and are resolved by tokens (nil disables them). Token requests are
+// public API calls: they count against the api_calls quota and carry the
+// token in the context for scope checks and per-token rate limiting.
+func InternalOrFirebaseAuth(authService *service.AuthService, secret string, ensurer UserEnsurer, tokens APITokenAuthenticator) func(http.Handler) http.Handler {
 	secretBytes := []byte(secret)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -58,6 +91,9 @@ func InternalOrFirebaseAuth(authService *service.AuthService, secret string, ens
 						}
 					}
 					ctx := context.WithValue(r.Context(), userIDKey, userID)
+					if strings.EqualFold(r.Header.Get("X-Client-Type"), "api") {
+						ctx = WithAPICaller(ctx)
+					}
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
@@ -72,24 +108,55 @@ func InternalOrFirebaseAuth(authService *service.AuthService, secret string, ens
 				return
 			}
 
-			uid, err := authService.VerifyToken(r.Context(), token)
+			if tokens != nil && strings.HasPrefix(token, model.APITokenPrefix) {
+				t, err := tokens.Authenticate(r.Context(), token)
+				if errors.Is(err, service.ErrAPITokenUnauthorized) {
+					respondError(w, http.StatusUnauthorized, "invalid, expired or revoked API token")
+					return
+				}
+				if err != nil {
+					slog.Error("[Auth] API token lookup failed", "error", err)
+					respondError(w, http.StatusInternalServerError, "failed to verify API token")
+					return
+				}
+				// Track usage off the request path; a failed update is logged
+				// by the service and never fails the request.
+				go tokens.RecordUse(context.WithoutCancel(r.Context()), t.ID)
+
+				ctx := context.WithValue(r.Context(), userIDKey, t.UserID)
+				ctx = WithAPIToken(WithAPICaller(ctx), t)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			id, err := authService.VerifyIdentity(r.Context(), token)
 			if err != nil {
 				respondError(w, http.StatusUnauthorized, "invalid or expired token")
 				return
 			}
 
-			if ensurer != nil {
-				if err := ensurer.EnsureUser(r.Context(), uid); err != nil {
-					respondError(w, http.StatusInternalServerError, "failed to provision user")
-					return
-				}
+			if err := ensureIdentity(r.Context(), ensurer, id); err != nil {
+				respondError(w, http.StatusInternalServerError, "failed to provision user")
+				return
 			}
-			ctx := context.WithValue(r.Context(), userIDKey, uid)
+			ctx := context.WithValue(r.Context(), userIDKey, id.UserID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// ensureIdentity provisions the verified caller, syncing the email and role
+// from their ID token when the ensurer supports it.
+func ensureIdentity(ctx context.Context, ensurer UserEnsurer, id *service.Identity) error {
+	if ensurer == nil {
+		return nil
+	}
+	if ie, ok := ensurer.(IdentityEnsurer); ok && (id.Email != "" || id.Role != "") {
+		return ie.EnsureIdentity(ctx, *id)
+	}
+	return ensurer.EnsureUser(ctx, id.UserID)
+}
+
 // FirebaseAuth returns middleware that verifies Firebase ID tokens.
 // Requests without a valid token receive a 401 JSON response.
 // Used by: unit tests. Production uses InternalOrFirebaseAuth instead.
@@ -134,3 +134,44 @@ func TestExtractBearerToken(t *testing.T) {
 		}
 	}
 }
+
+// recordingEnsurer implements UserEnsurer and IdentityEnsurer.
+type recordingEnsurer struct {
+	users      []string
+	identities []service.Identity
+}
+
+func (e *recordingEnsurer) EnsureUser(_ context.Context, userID string) error {
+	e.users = append(e.users, userID)
+	return nil
+}
+
+func (e *recordingEnsurer) EnsureIdentity(_ context.Context, id service.Identity) error {
+	e.identities = append(e.identities, id)
+	return nil
+}
+
+func TestInternalOrFirebaseAuth_SyncsIdentityClaims(t *testing.T) {
+	authSvc := service.NewAuthService(&mockAuthClient{uid: "user123"})
+	ensurer := &recordingEnsurer{}
+	handler := InternalOrFirebaseAuth(authSvc, "", ensurer, nil)(newTestHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	// The mock token has no email or role claim, so plain provisioning runs.
+	if len(ensurer.users) != 1 || ensurer.users[0] != "user123" || len(ensurer.identities) != 0 {
+		t.Errorf("users = %v, identities = %v", ensurer.users, ensurer.identities)
+	}
+
+	ensurer = &recordingEnsurer{}
+	err := ensureIdentity(context.Background(), ensurer, &service.Identity{UserID: "okta-1", Email: "ada@acme.com", Role: "Partner"})
+	if err != nil || len(ensurer.identities) != 1 || ensurer.identities[0].Role != "Partner" {
+		t.Errorf("ensureIdentity: err = %v, identities = %v", err, ensurer.identities)
+	}
+}
@@ -16,6 +16,14 @@ type Metrics struct {
 	ErrorsTotal      *prometheus.CounterVec
 	SilenceTriggers  prometheus.Counter
 	ActiveRequests   prometheus.Gauge
+	CacheLookups     *prometheus.CounterVec
+	CacheEvictions   *prometheus.CounterVec
+	CacheLatency     *prometheus.HistogramVec
+	AuditDelivered   *prometheus.CounterVec
+	AuditFailures    *prometheus.CounterVec
+	AuditDelivery    *prometheus.HistogramVec
+	AuditPending     *prometheus.GaugeVec
+	AuditLag         *prometheus.GaugeVec
 }
 
 // NewMetrics creates and registers Prometheus metrics.
@@ -55,9 +63,69 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 				Help: "Number of currently active HTTP requests.",
 			},
 		),
+		CacheLookups: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cache_lookups_total",
+				Help: "Total number of cache lookups by layer and result (hit/miss).",
+			},
+			[]string{"layer", "result"},
+		),
+		CacheEvictions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cache_evictions_total",
+				Help: "Total number of cache evictions by layer and reason.",
+			},
+			[]string{"layer", "reason"},
+		),
+		CacheLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "cache_lookup_duration_seconds",
+				Help:    "Cache lookup latency in seconds.",
+				Buckets: []float64{0.000001, 0.00001, 0.0001, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5},
+			},
+			[]string{"layer"},
+		),
+		AuditDelivered: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "audit_sink_delivered_total",
+				Help: "Total number of audit entries delivered by sink.",
+			},
+			[]string{"sink"},
+		),
+		AuditFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "audit_sink_failures_total",
+				Help: "Total number of failed audit sink batch deliveries by sink.",
+			},
+			[]string{"sink"},
+		),
+		AuditDelivery: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "audit_sink_delivery_duration_seconds",
+				Help:    "Audit sink batch delivery latency in seconds.",
+				Buckets: []float64{0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 30},
+			},
+			[]string{"sink"},
+		),
+		AuditPending: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "audit_sink_pending_entries",
+				Help: "Audit entries queued in the outbox by sink.",
+			},
+			[]string{"sink"},
+		),
+		AuditLag: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "audit_sink_lag_seconds",
+				Help: "Age of the oldest undelivered audit entry by sink (0 when caught up).",
+			},
+			[]string{"sink"},
+		),
 	}
 
-	reg.MustRegister(m.RequestsTotal, m.RequestDuration, m.ErrorsTotal, m.SilenceTriggers, m.ActiveRequests)
+	reg.MustRegister(m.RequestsTotal, m.RequestDuration, m.ErrorsTotal, m.SilenceTriggers, m.ActiveRequests,
+		m.CacheLookups, m.CacheEvictions, m.CacheLatency,
+		m.AuditDelivered, m.AuditFailures, m.AuditDelivery, m.AuditPending, m.AuditLag)
 	return m
 }
 
@@ -96,6 +164,37 @@ func (m *Metrics) IncrementSilenceTrigger() {
 	m.SilenceTriggers.Inc()
 }
 
+// ObserveCacheLookup records a cache hit or miss and its latency for layer.
+func (m *Metrics) ObserveCacheLookup(layer string, hit bool, d time.Duration) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.CacheLookups.WithLabelValues(layer, result).Inc()
+	m.CacheLatency.WithLabelValues(layer).Observe(d.Seconds())
+}
+
+// ObserveCacheEviction records an entry leaving a cache layer for reason.
+func (m *Metrics) ObserveCacheEviction(layer, reason string) {
+	m.CacheEvictions.WithLabelValues(layer, reason).Inc()
+}
+
+// ObserveAuditDelivery records one audit sink batch delivery.
+func (m *Metrics) ObserveAuditDelivery(sink string, entries int, err error, d time.Duration) {
+	m.AuditDelivery.WithLabelValues(sink).Observe(d.Seconds())
+	if err != nil {
+		m.AuditFailures.WithLabelValues(sink).Inc()
+		return
+	}
+	m.AuditDelivered.WithLabelValues(sink).Add(float64(entries))
+}
+
+// ObserveAuditLag records an audit sink's outbox backlog.
+func (m *Metrics) ObserveAuditLag(sink string, pending int64, lag time.Duration) {
+	m.AuditPending.WithLabelValues(sink).Set(float64(pending))
+	m.AuditLag.WithLabelValues(sink).Set(lag.Seconds())
+}
+
 type metricsWriter struct {
 	http.ResponseWriter
 	status      int
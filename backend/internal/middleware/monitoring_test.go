@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	io_prometheus "github.com/prometheus/client_model/go"
@@ -156,6 +158,55 @@ func TestSilenceTrigger(t *testing.T) {
 	}
 }
 
+func TestCacheObserver(t *testing.T) {
+	m, _ := newTestMetrics(t)
+
+	m.ObserveCacheLookup("query", true, time.Millisecond)
+	m.ObserveCacheLookup("query", false, time.Millisecond)
+	m.ObserveCacheLookup("query", false, time.Millisecond)
+	m.ObserveCacheEviction("embedding", "capacity")
+
+	var metric io_prometheus.Metric
+	m.CacheLookups.WithLabelValues("query", "miss").Write(&metric)
+	if got := metric.GetCounter().GetValue(); got != 2 {
+		t.Errorf("cache misses = %f, want 2", got)
+	}
+	m.CacheEvictions.WithLabelValues("embedding", "capacity").Write(&metric)
+	if got := metric.GetCounter().GetValue(); got != 1 {
+		t.Errorf("cache evictions = %f, want 1", got)
+	}
+	m.CacheLatency.WithLabelValues("query").(prometheus.Metric).Write(&metric)
+	if got := metric.GetHistogram().GetSampleCount(); got != 3 {
+		t.Errorf("cache latency samples = %d, want 3", got)
+	}
+}
+
+func TestAuditSinkObserver(t *testing.T) {
+	m, _ := newTestMetrics(t)
+
+	m.ObserveAuditDelivery("webhook", 5, nil, time.Millisecond)
+	m.ObserveAuditDelivery("webhook", 3, errors.New("503"), time.Millisecond)
+	m.ObserveAuditLag("webhook", 3, 90*time.Second)
+
+	var metric io_prometheus.Metric
+	m.AuditDelivered.WithLabelValues("webhook").Write(&metric)
+	if got := metric.GetCounter().GetValue(); got != 5 {
+		t.Errorf("delivered = %f, want 5", got)
+	}
+	m.AuditFailures.WithLabelValues("webhook").Write(&metric)
+	if got := metric.GetCounter().GetValue(); got != 1 {
+		t.Errorf("failures = %f, want 1", got)
+	}
+	m.AuditPending.WithLabelValues("webhook").Write(&metric)
+	if got := metric.GetGauge().GetValue(); got != 3 {
+		t.Errorf("pending = %f, want 3", got)
+	}
+	m.AuditLag.WithLabelValues("webhook").Write(&metric)
+	if got := metric.GetGauge().GetValue(); got != 90 {
+		t.Errorf("lag = %f, want 90", got)
+	}
+}
+
 func TestMetricsHandler_ServesPrometheusFormat(t *testing.T) {
 	m, reg := newTestMetrics(t)
 
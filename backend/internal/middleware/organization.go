@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+const (
+	orgIDKey   contextKey = "orgID"
+	orgRoleKey contextKey = "orgRole"
+)
+
+// OrgResolver looks up a user's organization membership (nil if none).
+// Implemented by *repository.OrgRepo.
+type OrgResolver interface {
+	Membership(ctx context.Context, userID string) (*model.OrgMember, error)
+}
+
+// OrgIDFromContext returns the caller's organization ID, or their personal
+// scope (model.PersonalOrgID) when they belong to no organization. Empty if
+// the Organization middleware did not run.
+func OrgIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(orgIDKey).(string)
+	return id
+}
+
+// OrgRoleFromContext returns the caller's role in their organization, or ""
+// when they have none.
+func OrgRoleFromContext(ctx context.Context) model.UserRole {
+	role, _ := ctx.Value(orgRoleKey).(model.UserRole)
+	return role
+}
+
+// WithOrg returns a context carrying the caller's org ID and role.
+// Useful for testing handlers that depend on the Organization middleware.
+func WithOrg(ctx context.Context, orgID string, role model.UserRole) context.Context {
+	ctx = context.WithValue(ctx, orgIDKey, orgID)
+	return context.WithValue(ctx, orgRoleKey, role)
+}
+
+// Organization resolves the authenticated user's organization and stores it
+// in the request context. Must run after auth. A lookup failure leaves the
+// user in their personal scope, so they can never see another org's content.
+func Organization(resolver OrgResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := UserIDFromContext(r.Context())
+			if resolver == nil || userID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			orgID, role := model.PersonalOrgID(userID), model.UserRole("")
+			m, err := resolver.Membership(r.Context(), userID)
+			if err != nil {
+				slog.Error("[Org] membership lookup failed", "user_id", userID, "error", err)
+			} else if m != nil {
+				orgID, role = m.OrgID, m.Role
+			}
+			next.ServeHTTP(w, r.WithContext(WithOrg(r.Context(), orgID, role)))
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+type stubOrgResolver struct {
+	m   *model.OrgMember
+	err error
+}
+
+func (s stubOrgResolver) Membership(context.Context, string) (*model.OrgMember, error) {
+	return s.m, s.err
+}
+
+func TestOrganization_SetsContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver stubOrgResolver
+		wantOrg  string
+		wantRole model.UserRole
+	}{
+		{"member", stubOrgResolver{m: &model.OrgMember{OrgID: "org-1", Role: model.UserRoleAuditor}}, "org-1", model.UserRoleAuditor},
+		{"no membership", stubOrgResolver{}, "personal:u1", ""},
+		{"lookup error", stubOrgResolver{err: errors.New("db down")}, "personal:u1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOrg string
+			var gotRole model.UserRole
+			h := Organization(tt.resolver)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotOrg, gotRole = OrgIDFromContext(r.Context()), OrgRoleFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/documents", nil)
+			req = req.WithContext(WithUserID(req.Context(), "u1"))
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if gotOrg != tt.wantOrg || gotRole != tt.wantRole {
+				t.Errorf("org = %q role = %q, want %q %q", gotOrg, gotRole, tt.wantOrg, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestOrganization_SkipsUnauthenticated(t *testing.T) {
+	called := false
+	h := Organization(stubOrgResolver{m: &model.OrgMember{OrgID: "org-1"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if OrgIDFromContext(r.Context()) != "" {
+			t.Error("org set without a user")
+		}
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !called {
+		t.Error("next handler not called")
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// APIUsageMeter enforces and records the api_calls quota.
+// Implemented by *service.UsageService.
+type APIUsageMeter interface {
+	Enforce(ctx context.Context, userID, metric string, amount int64) (service.QuotaDecision, error)
+	IncrementUsage(ctx context.Context, userID, metric string) error
+}
+
+// APIUsage counts each public API request (see IsAPICaller) against the
+// caller's api_calls quota, refusing with 402 once it is exhausted.
+// Web app requests pass through uncounted. Metering failures allow the
+// request.
+func APIUsage(meter APIUsageMeter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := UserIDFromContext(r.Context())
+			if meter == nil || userID == "" || !IsAPICaller(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			d, err := meter.Enforce(r.Context(), userID, service.MetricAPICalls, 1)
+			var qe *service.QuotaError
+			if errors.As(err, &qe) {
+				slog.Warn("[Usage] API call quota exceeded", "user_id", userID, "used", qe.Used, "limit", qe.Limit)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusPaymentRequired)
+				json.NewEncoder(w).Encode(qe.Body())
+				return
+			}
+			if err != nil {
+				slog.Error("[Usage] API call quota check failed", "user_id", userID, "error", err)
+			} else if msg := d.Warning(); msg != "" {
+				w.Header().Set("X-Quota-Warning", msg)
+			}
+
+			// Count the call off the request path; a failed increment is logged
+			// by the meter and never fails the request.
+			go meter.IncrementUsage(context.WithoutCancel(r.Context()), userID, service.MetricAPICalls)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// stubAPIMeter implements APIUsageMeter with a fixed api_calls limit.
+type stubAPIMeter struct {
+	mu    sync.Mutex
+	used  int64
+	limit int64
+	incr  chan struct{}
+}
+
+func (m *stubAPIMeter) Enforce(_ context.Context, _, metric string, amount int64) (service.QuotaDecision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.used+amount > m.limit {
+		return service.QuotaDecision{}, &service.QuotaError{Metric: metric, Tier: "starter", Used: m.used, Limit: m.limit, Requested: amount}
+	}
+	return service.QuotaDecision{Metric: metric, Used: m.used, Limit: m.limit}, nil
+}
+
+func (m *stubAPIMeter) IncrementUsage(context.Context, string, string) error {
+	m.mu.Lock()
+	m.used++
+	m.mu.Unlock()
+	m.incr <- struct{}{}
+	return nil
+}
+
+func TestAPIUsage_CountsAndRefusesAPICallers(t *testing.T) {
+	meter := &stubAPIMeter{limit: 1, incr: make(chan struct{}, 4)}
+	h := APIUsage(meter)(okHandler())
+
+	send := func(api bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/chat", nil)
+		ctx := WithUserID(req.Context(), "u1")
+		if api {
+			ctx = WithAPICaller(ctx)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	if rec := send(true); rec.Code != http.StatusOK {
+		t.Fatalf("first API call status = %d, want 200", rec.Code)
+	}
+	select {
+	case <-meter.incr:
+	case <-time.After(time.Second):
+		t.Fatal("API call was not counted")
+	}
+
+	rec := send(true)
+	if rec.Code != http.StatusPaymentRequired {
+		t.Fatalf("second API call status = %d, want 402", rec.Code)
+	}
+	var body service.QuotaErrorBody
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Metric != service.MetricAPICalls || body.Used != 1 || body.Limit != 1 {
+		t.Errorf("body = %+v", body)
+	}
+
+	if rec := send(false); rec.Code != http.StatusOK {
+		t.Errorf("web app request status = %d, want 200 (not metered)", rec.Code)
+	}
+	if len(meter.incr) != 0 {
+		t.Error("only API callers should be counted")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -81,9 +82,34 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// Limiter is implemented by RateLimiter (per process) and RedisRateLimiter
+// (shared across instances).
+type Limiter interface {
+	// Allow checks whether key is within the rate limit.
+	// Returns (allowed, retryAfterSeconds).
+	Allow(key string) (bool, int)
+	// Decide is Allow with the quota state needed for RateLimit-* headers.
+	Decide(key string) RateLimitDecision
+}
+
+// RateLimitDecision is the outcome of one rate limit check.
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      int // seconds until the oldest request leaves the window
+	RetryAfter int // seconds; set only when not allowed
+}
+
 // Allow checks whether the given key (user ID) is within the rate limit.
 // Returns (allowed, retryAfterSeconds).
 func (rl *RateLimiter) Allow(key string) (bool, int) {
+	d := rl.Decide(key)
+	return d.Allowed, d.RetryAfter
+}
+
+// Decide checks and records a request for key, returning the quota state.
+func (rl *RateLimiter) Decide(key string) RateLimitDecision {
 	now := rl.nowFunc()
 	cutoff := now.Add(-rl.config.Window)
 
@@ -96,6 +122,7 @@ func (rl *RateLimiter) Allow(key string) (bool, int) {
 	// Prune expired timestamps
 	uw.timestamps = pruneExpired(uw.timestamps, cutoff)
 
+	d := RateLimitDecision{Limit: rl.config.MaxRequests}
 	if len(uw.timestamps) >= rl.config.MaxRequests {
 		// Calculate when the oldest request in the window expires
 		oldest := uw.timestamps[0]
@@ -103,11 +130,24 @@ func (rl *RateLimiter) Allow(key string) (bool, int) {
 		if retryAfter < 1 {
 			retryAfter = 1
 		}
-		return false, retryAfter
+		d.Reset, d.RetryAfter = retryAfter, retryAfter
+		return d
 	}
 
 	uw.timestamps = append(uw.timestamps, now)
-	return true, 0
+	d.Allowed = true
+	d.Remaining = rl.config.MaxRequests - len(uw.timestamps)
+	d.Reset = ceilSeconds(uw.timestamps[0].Add(rl.config.Window).Sub(now))
+	return d
+}
+
+// ceilSeconds rounds d up to whole seconds, with a minimum of 1.
+func ceilSeconds(d time.Duration) int {
+	secs := int((d + time.Second - 1) / time.Second)
+	if secs < 1 {
+		return 1
+	}
+	return secs
 }
 
 // pruneExpired removes timestamps that are before the cutoff.
@@ -124,24 +164,17 @@ func pruneExpired(timestamps []time.Time, cutoff time.Time) []time.Time {
 
 // RateLimit returns Chi middleware that enforces per-user rate limiting.
 // It requires that auth middleware has already set the user ID in context.
-// If no user ID is found, the client's remote address is used as fallback.
-func RateLimit(rl *RateLimiter) func(http.Handler) http.Handler {
+// Requests made with an API token are limited per token. If no user ID is
+// found, the client's remote address is used as fallback.
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset are set on every
+// response; when limiters are stacked, the most restrictive one is reported.
+func RateLimit(rl Limiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			key := UserIDFromContext(r.Context())
-			if key == "" {
-				key = r.RemoteAddr
-			}
-
-			allowed, retryAfter := rl.Allow(key)
-			if !allowed {
-				w.Header().Set("Content-Type", "application/json")
-				w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
-				w.WriteHeader(http.StatusTooManyRequests)
-				json.NewEncoder(w).Encode(map[string]interface{}{
-					"success": false,
-					"error":   "rate limit exceeded",
-				})
+			d := rl.Decide(rateLimitKey(r))
+			setRateLimitHeaders(w.Header(), d)
+			if !d.Allowed {
+				rejectRateLimited(w, d.RetryAfter, "rate limit exceeded")
 				return
 			}
 
@@ -149,3 +182,27 @@ func RateLimit(rl *RateLimiter) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// rejectRateLimited writes a 429 with Retry-After and a JSON error body.
+func rejectRateLimited(w http.ResponseWriter, retryAfter int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   msg,
+	})
+}
+
+// setRateLimitHeaders writes the RateLimit-* headers for d unless an outer
+// limiter already reported fewer remaining requests.
+func setRateLimitHeaders(h http.Header, d RateLimitDecision) {
+	if prev := h.Get("RateLimit-Remaining"); prev != "" {
+		if n, err := strconv.Atoi(prev); err == nil && n < d.Remaining {
+			return
+		}
+	}
+	h.Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(d.Reset))
+}
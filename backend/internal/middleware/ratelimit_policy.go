@@ -0,0 +1,424 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Route groups used as columns of the rate limit policy table.
+const (
+	RouteGroupGeneral     = "general"      // every authenticated endpoint
+	RouteGroupChat        = "chat"         // POST /api/chat
+	RouteGroupForge       = "forge"        // POST /api/forge
+	RouteGroupInsightScan = "insight_scan" // POST /api/v1/insights/scan
+)
+
+// burstWindow is the span over which RateLimitRule.Burst is enforced.
+const burstWindow = time.Second
+
+// tierCacheTTL bounds how long a resolved subscription tier is reused, so
+// tier lookups do not hit the database on every request.
+const tierCacheTTL = time.Minute
+
+// RateLimitRule limits one route group for one tier or tenant.
+type RateLimitRule struct {
+	// Requests allowed per Window (sliding).
+	Requests int
+	Window   time.Duration
+	// Burst caps requests in any one-second span. 0 = no burst cap.
+	Burst int
+	// Concurrency caps in-flight requests per user on this instance.
+	// 0 = unlimited.
+	Concurrency int
+}
+
+type rateLimitRuleJSON struct {
+	Requests    int    `json:"requests"`
+	Window      string `json:"window"`
+	Burst       int    `json:"burst,omitempty"`
+	Concurrency int    `json:"concurrency,omitempty"`
+}
+
+// MarshalJSON encodes Window as a Go duration string ("1m", "5m").
+func (r RateLimitRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rateLimitRuleJSON{
+		Requests:    r.Requests,
+		Window:      r.Window.String(),
+		Burst:       r.Burst,
+		Concurrency: r.Concurrency,
+	})
+}
+
+// UnmarshalJSON decodes Window from a Go duration string.
+func (r *RateLimitRule) UnmarshalJSON(data []byte) error {
+	var raw rateLimitRuleJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	window, err := time.ParseDuration(raw.Window)
+	if err != nil {
+		return fmt.Errorf("window %q: %w", raw.Window, err)
+	}
+	*r = RateLimitRule{Requests: raw.Requests, Window: window, Burst: raw.Burst, Concurrency: raw.Concurrency}
+	return nil
+}
+
+// RateLimitPolicy is the declarative tier × route group limit table.
+// A route group missing from a tier is not rate limited for that tier.
+type RateLimitPolicy struct {
+	// DefaultTier is used for unknown tiers and unauthenticated callers.
+	DefaultTier string `json:"defaultTier"`
+	// Tiers maps subscription tier → route group → rule.
+	Tiers map[string]map[string]RateLimitRule `json:"tiers"`
+	// Tenants maps user ID → route group → rule, overriding the tier rule
+	// for that group only.
+	Tenants map[string]map[string]RateLimitRule `json:"tenants,omitempty"`
+}
+
+// DefaultRateLimitPolicy returns the built-in table. The free tier keeps the
+// original fixed limits (60 general, 10 chat, 5 forge per minute; 1 insight
+// scan per 5 minutes); paid tiers scale up from there.
+func DefaultRateLimitPolicy() RateLimitPolicy {
+	tier := func(general, chat, chatConcurrency, forge, scans int) map[string]RateLimitRule {
+		return map[string]RateLimitRule{
+			RouteGroupGeneral:     {Requests: general, Window: time.Minute},
+			RouteGroupChat:        {Requests: chat, Window: time.Minute, Concurrency: chatConcurrency},
+			RouteGroupForge:       {Requests: forge, Window: time.Minute},
+			RouteGroupInsightScan: {Requests: scans, Window: 5 * time.Minute},
+		}
+	}
+	p := RateLimitPolicy{
+		DefaultTier: "free",
+		Tiers: map[string]map[string]RateLimitRule{
+			"free":         tier(60, 10, 2, 5, 1),
+			"starter":      tier(120, 20, 3, 10, 1),
+			"professional": tier(300, 60, 5, 20, 2),
+			"enterprise":   tier(600, 120, 10, 60, 5),
+			"sovereign":    tier(600, 120, 10, 60, 5),
+		},
+	}
+	// Legacy tier names still present in unmigrated users rows.
+	p.Tiers["mercury"] = p.Tiers["starter"]
+	p.Tiers["syndicate"] = p.Tiers["enterprise"]
+	return p
+}
+
+// Validate reports the first malformed rule or a missing default tier.
+func (p RateLimitPolicy) Validate() error {
+	if _, ok := p.Tiers[p.DefaultTier]; !ok {
+		return fmt.Errorf("default tier %q is not in the tier table", p.DefaultTier)
+	}
+	check := func(kind, name string, rules map[string]RateLimitRule) error {
+		for group, r := range rules {
+			if r.Requests <= 0 || r.Window <= 0 || r.Burst < 0 || r.Concurrency < 0 {
+				return fmt.Errorf("%s %q group %q: requests and window must be positive, burst and concurrency non-negative", kind, name, group)
+			}
+		}
+		return nil
+	}
+	for name, rules := range p.Tiers {
+		if err := check("tier", name, rules); err != nil {
+			return err
+		}
+	}
+	for name, rules := range p.Tenants {
+		if err := check("tenant", name, rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadRateLimitPolicy reads and validates a JSON policy file.
+func LoadRateLimitPolicy(path string) (RateLimitPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RateLimitPolicy{}, fmt.Errorf("middleware.LoadRateLimitPolicy: %w", err)
+	}
+	var p RateLimitPolicy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return RateLimitPolicy{}, fmt.Errorf("middleware.LoadRateLimitPolicy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return RateLimitPolicy{}, fmt.Errorf("middleware.LoadRateLimitPolicy: %w", err)
+	}
+	return p, nil
+}
+
+// ruleLimiter enforces one rule. The same instance serves every user the rule
+// applies to, since the underlying limiters are keyed by user.
+type ruleLimiter struct {
+	rule   RateLimitRule
+	window Limiter
+	burst  Limiter // nil when the rule has no burst cap
+	stop   []func()
+}
+
+// PolicyLimiter applies a RateLimitPolicy per request, resolving the caller's
+// tier through the same lookup the chat and usage handlers use. The policy
+// can be replaced at runtime with Reload; limiters for unchanged rules keep
+// their windows across reloads.
+type PolicyLimiter struct {
+	tierFunc func(ctx context.Context, userID string) string
+	redis    *redis.Client // nil = per-instance windows only
+
+	mu       sync.RWMutex
+	policy   RateLimitPolicy
+	limiters map[string]*ruleLimiter // "tier:{tier}:{group}" or "tenant:{user}:{group}"
+
+	tierMu sync.Mutex
+	tiers  map[string]cachedTier
+
+	inflightMu sync.Mutex
+	inflight   map[string]int // "{group}:{key}" → in-flight requests
+}
+
+type cachedTier struct {
+	tier      string
+	expiresAt time.Time
+}
+
+// NewPolicyLimiter validates policy and returns a limiter for it. tierFunc
+// may be nil, in which case every caller gets the default tier. When client
+// is non-nil, request windows are shared across instances through Redis.
+func NewPolicyLimiter(policy RateLimitPolicy, tierFunc func(ctx context.Context, userID string) string, client *redis.Client) (*PolicyLimiter, error) {
+	p := &PolicyLimiter{
+		tierFunc: tierFunc,
+		redis:    client,
+		limiters: make(map[string]*ruleLimiter),
+		tiers:    make(map[string]cachedTier),
+		inflight: make(map[string]int),
+	}
+	if err := p.Reload(policy); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Policy returns the active policy.
+func (p *PolicyLimiter) Policy() RateLimitPolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.policy
+}
+
+// Reload validates and activates policy. Limiters whose rule is unchanged are
+// kept, so in-progress windows survive; the rest are rebuilt empty.
+func (p *PolicyLimiter) Reload(policy RateLimitPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("middleware.PolicyLimiter.Reload: %w", err)
+	}
+
+	next := make(map[string]*ruleLimiter)
+	p.mu.Lock()
+	build := func(prefix string, rules map[string]RateLimitRule) {
+		for group, rule := range rules {
+			name := prefix + ":" + group
+			if old, ok := p.limiters[name]; ok && old.rule == rule {
+				next[name] = old
+				continue
+			}
+			next[name] = p.newRuleLimiter(name, rule)
+		}
+	}
+	for tier, rules := range policy.Tiers {
+		build("tier:"+tier, rules)
+	}
+	for tenant, rules := range policy.Tenants {
+		build("tenant:"+tenant, rules)
+	}
+	for name, old := range p.limiters {
+		if next[name] != old {
+			for _, stop := range old.stop {
+				stop()
+			}
+		}
+	}
+	p.policy = policy
+	p.limiters = next
+	p.mu.Unlock()
+
+	slog.Info("[RATELIMIT] policy loaded", "tiers", len(policy.Tiers), "tenant_overrides", len(policy.Tenants))
+	return nil
+}
+
+func (p *PolicyLimiter) newRuleLimiter(name string, rule RateLimitRule) *ruleLimiter {
+	rl := &ruleLimiter{rule: rule}
+	window := NewRateLimiter(RateLimiterConfig{MaxRequests: rule.Requests, Window: rule.Window})
+	rl.window = Distributed(p.redis, name, window)
+	rl.stop = append(rl.stop, window.Stop)
+	if rule.Burst > 0 {
+		burst := NewRateLimiter(RateLimiterConfig{MaxRequests: rule.Burst, Window: burstWindow, CleanupInterval: time.Minute})
+		rl.burst = Distributed(p.redis, name+":burst", burst)
+		rl.stop = append(rl.stop, burst.Stop)
+	}
+	return rl
+}
+
+// Stop halts the cleanup goroutines of every in-memory window.
+func (p *PolicyLimiter) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, rl := range p.limiters {
+		for _, stop := range rl.stop {
+			stop()
+		}
+	}
+	p.limiters = map[string]*ruleLimiter{}
+}
+
+// limiterFor returns the limiter for userID's tenant override or tier in
+// group, or nil when the group is not limited.
+func (p *PolicyLimiter) limiterFor(ctx context.Context, userID, group string) *ruleLimiter {
+	tier := p.resolveTier(ctx, userID)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if userID != "" {
+		if _, ok := p.policy.Tenants[userID][group]; ok {
+			return p.limiters["tenant:"+userID+":"+group]
+		}
+	}
+	if _, ok := p.policy.Tiers[tier]; !ok {
+		tier = p.policy.DefaultTier
+	}
+	return p.limiters["tier:"+tier+":"+group]
+}
+
+// resolveTier returns userID's subscription tier, cached for tierCacheTTL.
+func (p *PolicyLimiter) resolveTier(ctx context.Context, userID string) string {
+	if userID == "" || p.tierFunc == nil {
+		return ""
+	}
+	now := time.Now()
+	p.tierMu.Lock()
+	if c, ok := p.tiers[userID]; ok && now.Before(c.expiresAt) {
+		p.tierMu.Unlock()
+		return c.tier
+	}
+	p.tierMu.Unlock()
+
+	tier := p.tierFunc(ctx, userID)
+	p.tierMu.Lock()
+	if len(p.tiers) > 10000 {
+		for k, c := range p.tiers {
+			if !now.Before(c.expiresAt) {
+				delete(p.tiers, k)
+			}
+		}
+	}
+	p.tiers[userID] = cachedTier{tier: tier, expiresAt: now.Add(tierCacheTTL)}
+	p.tierMu.Unlock()
+	return tier
+}
+
+// Decide checks and records a request for userID (or key, for anonymous
+// callers) in group.
+func (p *PolicyLimiter) Decide(ctx context.Context, userID, key, group string) (RateLimitDecision, bool) {
+	rl := p.limiterFor(ctx, userID, group)
+	if rl == nil {
+		return RateLimitDecision{Allowed: true}, false
+	}
+	d := rl.window.Decide(key)
+	if d.Allowed && rl.burst != nil {
+		if b := rl.burst.Decide(key); !b.Allowed {
+			d.Allowed = false
+			d.RetryAfter = b.RetryAfter
+		}
+	}
+	return d, true
+}
+
+// acquire reserves an in-flight slot, returning a release func, or false when
+// the rule's concurrency cap is reached.
+func (p *PolicyLimiter) acquire(ctx context.Context, userID, key, group string) (func(), bool) {
+	rl := p.limiterFor(ctx, userID, group)
+	if rl == nil || rl.rule.Concurrency <= 0 {
+		return func() {}, true
+	}
+	slot := group + ":" + key
+	p.inflightMu.Lock()
+	defer p.inflightMu.Unlock()
+	if p.inflight[slot] >= rl.rule.Concurrency {
+		return nil, false
+	}
+	p.inflight[slot]++
+	return func() {
+		p.inflightMu.Lock()
+		if p.inflight[slot]--; p.inflight[slot] <= 0 {
+			delete(p.inflight, slot)
+		}
+		p.inflightMu.Unlock()
+	}, true
+}
+
+// WatchFile reloads the policy from path whenever its modification time
+// changes, until ctx is cancelled. A file that fails to load is logged and
+// the active policy is kept.
+func (p *PolicyLimiter) WatchFile(ctx context.Context, path string, interval time.Duration) {
+	var lastMod time.Time
+	if fi, err := os.Stat(path); err == nil {
+		lastMod = fi.ModTime()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fi, err := os.Stat(path)
+			if err != nil || fi.ModTime().Equal(lastMod) {
+				continue
+			}
+			lastMod = fi.ModTime()
+			policy, err := LoadRateLimitPolicy(path)
+			if err != nil {
+				slog.Error("[RATELIMIT] policy reload failed, keeping active policy", "path", path, "error", err)
+				continue
+			}
+			if err := p.Reload(policy); err != nil {
+				slog.Error("[RATELIMIT] policy reload failed, keeping active policy", "path", path, "error", err)
+			}
+		}
+	}
+}
+
+// PolicyRateLimit returns Chi middleware enforcing the policy for group.
+// It must run after auth so the user ID (and so the tier) is known; callers
+// without one are limited by remote address under the default tier. API
+// token requests use the owner's tier but a separate budget per token.
+func PolicyRateLimit(p *PolicyLimiter, group string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := UserIDFromContext(r.Context())
+			key := rateLimitKey(r)
+
+			d, limited := p.Decide(r.Context(), userID, key, group)
+			if limited {
+				setRateLimitHeaders(w.Header(), d)
+			}
+			if !d.Allowed {
+				rejectRateLimited(w, d.RetryAfter, "rate limit exceeded")
+				return
+			}
+
+			release, ok := p.acquire(r.Context(), userID, key, group)
+			if !ok {
+				rejectRateLimited(w, 1, "too many concurrent requests")
+				return
+			}
+			defer release()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
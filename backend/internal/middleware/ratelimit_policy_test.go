@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testPolicy() RateLimitPolicy {
+	return RateLimitPolicy{
+		DefaultTier: "free",
+		Tiers: map[string]map[string]RateLimitRule{
+			"free":       {RouteGroupChat: {Requests: 2, Window: time.Minute}},
+			"enterprise": {RouteGroupChat: {Requests: 5, Window: time.Minute}},
+		},
+	}
+}
+
+func newTestPolicyLimiter(t *testing.T, policy RateLimitPolicy, tiers map[string]string) *PolicyLimiter {
+	t.Helper()
+	p, err := NewPolicyLimiter(policy, func(_ context.Context, userID string) string {
+		return tiers[userID]
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewPolicyLimiter: %v", err)
+	}
+	t.Cleanup(p.Stop)
+	return p
+}
+
+// countAllowed sends n requests as userID through the group's middleware and
+// returns how many were allowed.
+func countAllowed(h http.Handler, userID string, n int) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/chat", nil)
+		req = req.WithContext(WithUserID(req.Context(), userID))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code == http.StatusOK {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestPolicyRateLimit_ByTier(t *testing.T) {
+	p := newTestPolicyLimiter(t, testPolicy(), map[string]string{"ent": "enterprise", "free": "free", "odd": "legacy"})
+	h := PolicyRateLimit(p, RouteGroupChat)(okHandler())
+
+	if got := countAllowed(h, "free", 10); got != 2 {
+		t.Errorf("free allowed = %d, want 2", got)
+	}
+	if got := countAllowed(h, "ent", 10); got != 5 {
+		t.Errorf("enterprise allowed = %d, want 5", got)
+	}
+	if got := countAllowed(h, "odd", 10); got != 2 {
+		t.Errorf("unknown tier allowed = %d, want default tier's 2", got)
+	}
+}
+
+func TestPolicyRateLimit_UnlistedGroupIsUnlimited(t *testing.T) {
+	p := newTestPolicyLimiter(t, testPolicy(), nil)
+	h := PolicyRateLimit(p, RouteGroupForge)(okHandler())
+
+	if got := countAllowed(h, "u1", 20); got != 20 {
+		t.Errorf("allowed = %d, want 20", got)
+	}
+}
+
+func TestPolicyRateLimit_TenantOverride(t *testing.T) {
+	policy := testPolicy()
+	policy.Tenants = map[string]map[string]RateLimitRule{
+		"vip": {RouteGroupChat: {Requests: 7, Window: time.Minute}},
+	}
+	p := newTestPolicyLimiter(t, policy, map[string]string{"vip": "free"})
+	h := PolicyRateLimit(p, RouteGroupChat)(okHandler())
+
+	if got := countAllowed(h, "vip", 10); got != 7 {
+		t.Errorf("override allowed = %d, want 7", got)
+	}
+}
+
+func TestPolicyRateLimit_Burst(t *testing.T) {
+	policy := testPolicy()
+	policy.Tiers["free"][RouteGroupChat] = RateLimitRule{Requests: 100, Window: time.Minute, Burst: 3}
+	p := newTestPolicyLimiter(t, policy, nil)
+	h := PolicyRateLimit(p, RouteGroupChat)(okHandler())
+
+	if got := countAllowed(h, "u1", 10); got != 3 {
+		t.Errorf("allowed within one second = %d, want burst of 3", got)
+	}
+}
+
+func TestPolicyRateLimit_Concurrency(t *testing.T) {
+	policy := testPolicy()
+	policy.Tiers["free"][RouteGroupChat] = RateLimitRule{Requests: 100, Window: time.Minute, Concurrency: 1}
+	p := newTestPolicyLimiter(t, policy, nil)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	h := PolicyRateLimit(p, RouteGroupChat)(slow)
+
+	done := make(chan int)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/api/chat", nil)
+		req = req.WithContext(WithUserID(req.Context(), "u1"))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		done <- rec.Code
+	}()
+	<-entered
+
+	req := httptest.NewRequest(http.MethodPost, "/api/chat", nil)
+	req = req.WithContext(WithUserID(req.Context(), "u1"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("second concurrent request status = %d, want 429", rec.Code)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("first request status = %d, want 200", code)
+	}
+	if _, ok := p.acquire(context.Background(), "u1", "u1", RouteGroupChat); !ok {
+		t.Error("slot should be released after the request completes")
+	}
+}
+
+func TestPolicyLimiter_TierLookupIsCached(t *testing.T) {
+	var lookups atomic.Int32
+	p, err := NewPolicyLimiter(testPolicy(), func(context.Context, string) string {
+		lookups.Add(1)
+		return "enterprise"
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+	h := PolicyRateLimit(p, RouteGroupChat)(okHandler())
+
+	countAllowed(h, "u1", 4)
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("tier lookups = %d, want 1", n)
+	}
+}
+
+func TestPolicyLimiter_ReloadKeepsUnchangedWindows(t *testing.T) {
+	p := newTestPolicyLimiter(t, testPolicy(), map[string]string{"ent": "enterprise"})
+	h := PolicyRateLimit(p, RouteGroupChat)(okHandler())
+
+	countAllowed(h, "free", 2) // exhausts free
+
+	next := testPolicy()
+	next.Tiers["enterprise"][RouteGroupChat] = RateLimitRule{Requests: 1, Window: time.Minute}
+	if err := p.Reload(next); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := countAllowed(h, "free", 1); got != 0 {
+		t.Error("unchanged free rule should keep its exhausted window")
+	}
+	if got := countAllowed(h, "ent", 3); got != 1 {
+		t.Errorf("enterprise allowed after reload = %d, want 1", got)
+	}
+
+	bad := testPolicy()
+	bad.DefaultTier = "missing"
+	if err := p.Reload(bad); err == nil {
+		t.Error("Reload should reject a policy without its default tier")
+	}
+	if p.Policy().DefaultTier != "free" {
+		t.Error("a rejected reload must keep the active policy")
+	}
+}
+
+func TestLoadRateLimitPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	os.WriteFile(path, []byte(`{
+		"defaultTier": "free",
+		"tiers": {"free": {"chat": {"requests": 10, "window": "1m", "burst": 2, "concurrency": 1}}},
+		"tenants": {"user-1": {"chat": {"requests": 100, "window": "1m"}}}
+	}`), 0o600)
+
+	p, err := LoadRateLimitPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadRateLimitPolicy: %v", err)
+	}
+	want := RateLimitRule{Requests: 10, Window: time.Minute, Burst: 2, Concurrency: 1}
+	if got := p.Tiers["free"][RouteGroupChat]; got != want {
+		t.Errorf("free chat rule = %+v, want %+v", got, want)
+	}
+	if p.Tenants["user-1"][RouteGroupChat].Requests != 100 {
+		t.Errorf("tenant override = %+v", p.Tenants["user-1"])
+	}
+
+	os.WriteFile(path, []byte(`{"defaultTier":"free","tiers":{"free":{"chat":{"requests":0,"window":"1m"}}}}`), 0o600)
+	if _, err := LoadRateLimitPolicy(path); err == nil {
+		t.Error("expected validation error for zero requests")
+	}
+}
+
+func TestDefaultRateLimitPolicy_KeepsFreeLimits(t *testing.T) {
+	p := DefaultRateLimitPolicy()
+	if err := p.Validate(); err != nil {
+		t.Fatalf("default policy invalid: %v", err)
+	}
+	free := p.Tiers["free"]
+	if free[RouteGroupGeneral].Requests != 60 || free[RouteGroupChat].Requests != 10 ||
+		free[RouteGroupForge].Requests != 5 || free[RouteGroupInsightScan].Window != 5*time.Minute {
+		t.Errorf("free tier = %+v", free)
+	}
+	if p.Tiers["enterprise"][RouteGroupChat].Requests <= free[RouteGroupChat].Requests {
+		t.Error("enterprise chat limit should exceed free")
+	}
+}
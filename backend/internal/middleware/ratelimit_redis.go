@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript atomically prunes, counts and records a request in a
+// per-key sorted set scored by Redis server time in microseconds, so every
+// instance shares one window regardless of local clock skew.
+//
+// KEYS[1] = window key. ARGV = window_us, limit, member.
+// Returns {allowed, remaining, reset_us, retry_after_us}.
+var slidingWindowScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', KEYS[1], now, ARGV[3])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+  reset = tonumber(oldest[2]) + window - now
+end
+local retry = 0
+if allowed == 0 then
+  retry = reset
+end
+return {allowed, limit - count, reset, retry}
+`)
+
+const (
+	// redisLimiterTimeout bounds each Redis round trip so a slow Redis
+	// degrades to local limiting instead of stalling requests.
+	redisLimiterTimeout = 250 * time.Millisecond
+	// redisLimiterBackoff is how long to use the fallback after a Redis error
+	// before trying Redis again.
+	redisLimiterBackoff = 5 * time.Second
+)
+
+// RedisRateLimiter is a sliding window rate limiter shared by all server
+// instances through Redis. While Redis is unreachable it delegates to the
+// in-memory fallback, so limits become per-instance rather than disappearing.
+type RedisRateLimiter struct {
+	client    *redis.Client
+	prefix    string
+	config    RateLimiterConfig
+	fallback  *RateLimiter
+	downUntil atomic.Int64 // unix nanos; skip Redis until then
+}
+
+// NewRedisRateLimiter creates a limiter that stores windows under
+// "rl:{name}:{key}", using the fallback's MaxRequests and Window.
+func NewRedisRateLimiter(client *redis.Client, name string, fallback *RateLimiter) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client:   client,
+		prefix:   "rl:" + name + ":",
+		config:   fallback.config,
+		fallback: fallback,
+	}
+}
+
+// Distributed returns a Redis-backed limiter over fallback, or fallback
+// itself when client is nil (Redis not configured).
+func Distributed(client *redis.Client, name string, fallback *RateLimiter) Limiter {
+	if client == nil {
+		return fallback
+	}
+	return NewRedisRateLimiter(client, name, fallback)
+}
+
+// Allow checks whether key is within the rate limit.
+// Returns (allowed, retryAfterSeconds).
+func (rl *RedisRateLimiter) Allow(key string) (bool, int) {
+	d := rl.Decide(key)
+	return d.Allowed, d.RetryAfter
+}
+
+// Decide checks and records a request for key in Redis, falling back to the
+// in-memory limiter on error.
+func (rl *RedisRateLimiter) Decide(key string) RateLimitDecision {
+	if time.Now().UnixNano() < rl.downUntil.Load() {
+		return rl.fallback.Decide(key)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisLimiterTimeout)
+	defer cancel()
+	res, err := slidingWindowScript.Run(ctx, rl.client,
+		[]string{rl.prefix + key},
+		rl.config.Window.Microseconds(), rl.config.MaxRequests, uuid.NewString(),
+	).Int64Slice()
+	if err != nil || len(res) != 4 {
+		if rl.downUntil.Swap(time.Now().Add(redisLimiterBackoff).UnixNano()) == 0 {
+			slog.Warn("[RATELIMIT] redis unavailable, using in-memory limiter", "prefix", rl.prefix, "error", err)
+		}
+		return rl.fallback.Decide(key)
+	}
+	if rl.downUntil.Swap(0) != 0 {
+		slog.Info("[RATELIMIT] redis recovered", "prefix", rl.prefix)
+	}
+
+	d := RateLimitDecision{
+		Allowed:   res[0] == 1,
+		Limit:     rl.config.MaxRequests,
+		Remaining: int(res[1]),
+		Reset:     ceilSeconds(time.Duration(res[2]) * time.Microsecond),
+	}
+	if !d.Allowed {
+		d.RetryAfter = ceilSeconds(time.Duration(res[3]) * time.Microsecond)
+	}
+	return d
+}
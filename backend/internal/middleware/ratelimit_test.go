@@ -7,6 +7,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // newTestRateLimiter creates a RateLimiter suitable for testing (no background cleanup).
@@ -347,3 +349,81 @@ func TestPruneExpired(t *testing.T) {
 		t.Errorf("pruneExpired returned %d entries, want 2", len(result))
 	}
 }
+
+func TestRateLimit_HeadersOnEveryResponse(t *testing.T) {
+	rl := newTestRateLimiter(2, 1*time.Minute)
+	defer rl.Stop()
+	handler := RateLimit(rl)(okHandler())
+
+	wantRemaining := []string{"1", "0", "0"}
+	for i, want := range wantRemaining {
+		req := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+		req = req.WithContext(WithUserID(req.Context(), "user-1"))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: RateLimit-Limit = %q, want 2", i+1, got)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != want {
+			t.Errorf("request %d: RateLimit-Remaining = %q, want %s", i+1, got, want)
+		}
+		if got := rec.Header().Get("RateLimit-Reset"); got != "60" {
+			t.Errorf("request %d: RateLimit-Reset = %q, want 60", i+1, got)
+		}
+	}
+}
+
+func TestRateLimit_StackedReportsMostRestrictive(t *testing.T) {
+	outer := newTestRateLimiter(60, 1*time.Minute)
+	inner := newTestRateLimiter(10, 1*time.Minute)
+	defer outer.Stop()
+	defer inner.Stop()
+
+	// Inner runs after outer: its lower remaining count wins.
+	handler := RateLimit(outer)(RateLimit(inner)(okHandler()))
+	req := httptest.NewRequest(http.MethodGet, "/api/chat", nil)
+	req = req.WithContext(WithUserID(req.Context(), "user-1"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("RateLimit-Remaining"); got != "9" {
+		t.Errorf("RateLimit-Remaining = %q, want 9", got)
+	}
+
+	// Reversed nesting: the outer header is kept.
+	handler = RateLimit(inner)(RateLimit(outer)(okHandler()))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("RateLimit-Limit"); got != "10" {
+		t.Errorf("RateLimit-Limit = %q, want 10", got)
+	}
+}
+
+func TestRedisRateLimiter_FallsBackWhenRedisDown(t *testing.T) {
+	fallback := newTestRateLimiter(2, 1*time.Minute)
+	defer fallback.Stop()
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 50 * time.Millisecond, MaxRetries: -1})
+	defer client.Close()
+
+	rl := NewRedisRateLimiter(client, "test", fallback)
+	for i := 0; i < 2; i++ {
+		if allowed, _ := rl.Allow("user-1"); !allowed {
+			t.Fatalf("request %d should be allowed by fallback", i+1)
+		}
+	}
+	d := rl.Decide("user-1")
+	if d.Allowed || d.RetryAfter < 1 || d.Limit != 2 {
+		t.Errorf("decision = %+v, want denied by fallback", d)
+	}
+	if rl.downUntil.Load() == 0 {
+		t.Error("limiter should back off from Redis after an error")
+	}
+}
+
+func TestDistributed_NilClientUsesFallback(t *testing.T) {
+	fallback := newTestRateLimiter(1, 1*time.Minute)
+	defer fallback.Stop()
+	if got := Distributed(nil, "chat", fallback); got != Limiter(fallback) {
+		t.Errorf("Distributed(nil) = %T, want the in-memory limiter", got)
+	}
+}
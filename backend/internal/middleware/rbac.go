@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/connexus-ai/ragbox-backend/internal/rbac"
+)
+
+const roleKey contextKey = "role"
+
+// RoleResolver looks up a user's firm role ("Partner", "Associate",
+// "Auditor"). Implemented by *repository.UserRepo.
+type RoleResolver interface {
+	GetUserRole(ctx context.Context, userID string) (string, error)
+}
+
+// RoleFromContext returns the caller's role as resolved by Policy, or ""
+// when Policy did not run.
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey).(string)
+	return role
+}
+
+// WithRole returns a context carrying the caller's role.
+// Useful for testing handlers that depend on the Policy middleware.
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey, role)
+}
+
+// Policy enforces route-level RBAC. It resolves the caller's role once per
+// request — from their organization membership when the Organization
+// middleware already found one, else from resolver — caches it in the
+// context, and refuses the request unless the role has the permission
+// permFor returns. Routes permFor does not know are refused, so a new
+// route cannot ship without a policy. Must run after auth.
+func Policy(resolver RoleResolver, permFor func(r *http.Request) (rbac.Permission, bool)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			userID := UserIDFromContext(ctx)
+
+			role := RoleFromContext(ctx)
+			if role == "" {
+				role = string(OrgRoleFromContext(ctx))
+			}
+			if role == "" {
+				var err error
+				role, err = resolver.GetUserRole(ctx, userID)
+				if err != nil {
+					slog.Error("[RBAC] role lookup failed", "user_id", userID, "error", err)
+					respondError(w, http.StatusInternalServerError, "failed to verify permissions")
+					return
+				}
+			}
+
+			perm, ok := permFor(r)
+			if !ok {
+				slog.Error("[RBAC] route has no policy", "method", r.Method, "path", r.URL.Path)
+				respondError(w, http.StatusForbidden, "insufficient permissions")
+				return
+			}
+			if !rbac.Can(role, perm) {
+				slog.Warn("[RBAC] permission denied", "user_id", userID, "role", role, "permission", perm)
+				respondError(w, http.StatusForbidden, "insufficient permissions")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithRole(ctx, role)))
+		})
+	}
+}
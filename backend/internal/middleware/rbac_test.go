@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/rbac"
+)
+
+// stubRoles implements RoleResolver, counting lookups.
+type stubRoles struct {
+	role  string
+	err   error
+	calls int
+}
+
+func (s *stubRoles) GetUserRole(context.Context, string) (string, error) {
+	s.calls++
+	return s.role, s.err
+}
+
+func permFor(perm rbac.Permission) func(*http.Request) (rbac.Permission, bool) {
+	return func(*http.Request) (rbac.Permission, bool) { return perm, perm != "" }
+}
+
+func TestPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolver   *stubRoles
+		orgRole    model.UserRole
+		perm       rbac.Permission
+		wantStatus int
+		wantRole   string
+		wantCalls  int
+	}{
+		{"partner toggles privilege", &stubRoles{role: "Partner"}, "", rbac.PermPrivilegeToggle, http.StatusOK, "Partner", 1},
+		{"associate denied privilege", &stubRoles{role: "Associate"}, "", rbac.PermPrivilegeToggle, http.StatusForbidden, "", 1},
+		{"auditor reads audit", &stubRoles{role: "Auditor"}, "", rbac.PermAuditRead, http.StatusOK, "Auditor", 1},
+		{"auditor denied documents", &stubRoles{role: "Auditor"}, "", rbac.PermDocumentsRead, http.StatusForbidden, "", 1},
+		{"org role used without lookup", &stubRoles{role: "Partner"}, model.UserRoleAuditor, rbac.PermDocumentsRead, http.StatusForbidden, "", 0},
+		{"unknown route denied", &stubRoles{role: "Partner"}, "", "", http.StatusForbidden, "", 1},
+		{"lookup error", &stubRoles{err: errors.New("db down")}, "", rbac.PermDocumentsRead, http.StatusInternalServerError, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRole string
+			h := Policy(tt.resolver, permFor(tt.perm))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRole = RoleFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/documents", nil)
+			ctx := WithUserID(req.Context(), "u1")
+			if tt.orgRole != "" {
+				ctx = WithOrg(ctx, "org-1", tt.orgRole)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req.WithContext(ctx))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotRole != tt.wantRole {
+				t.Errorf("role in context = %q, want %q", gotRole, tt.wantRole)
+			}
+			if tt.resolver.calls != tt.wantCalls {
+				t.Errorf("role lookups = %d, want %d", tt.resolver.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPolicy_ReusesCachedRole(t *testing.T) {
+	roles := &stubRoles{role: "Auditor"}
+	h := Policy(roles, permFor(rbac.PermDocumentsRead))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/documents", nil)
+	ctx := WithRole(WithUserID(req.Context(), "u1"), "Partner")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req.WithContext(ctx))
+
+	if rec.Code != http.StatusOK || roles.calls != 0 {
+		t.Errorf("status = %d, lookups = %d; want 200 with no lookup", rec.Code, roles.calls)
+	}
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// APITokenPrefix starts every personal access token, so auth middleware can
+// tell them apart from Firebase ID tokens.
+const APITokenPrefix = "rbx_pat_"
+
+// APITokenScope limits what a personal access token can do.
+type APITokenScope string
+
+const (
+	// APIScopeRead allows reading documents, folders, audit and usage.
+	APIScopeRead APITokenScope = "read"
+	// APIScopeQuery allows chat, forge and insight scans.
+	APIScopeQuery APITokenScope = "query"
+	// APIScopeUpload allows uploading, ingesting and editing documents.
+	APIScopeUpload APITokenScope = "upload"
+	// APIScopeAdmin allows everything, including managing tokens.
+	APIScopeAdmin APITokenScope = "admin"
+)
+
+// ValidAPITokenScope reports whether s is a known scope.
+func ValidAPITokenScope(s APITokenScope) bool {
+	switch s {
+	case APIScopeRead, APIScopeQuery, APIScopeUpload, APIScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// APIToken is a user-created credential for programmatic API access. Only a
+// hash of the token is stored; the plaintext is returned once, at creation.
+type APIToken struct {
+	ID           string          `json:"id"`
+	UserID       string          `json:"userId"`
+	Name         string          `json:"name"`
+	Prefix       string          `json:"prefix"` // first characters of the token, for display
+	Scopes       []APITokenScope `json:"scopes"`
+	ExpiresAt    *time.Time      `json:"expiresAt,omitempty"`
+	LastUsedAt   *time.Time      `json:"lastUsedAt,omitempty"`
+	RequestCount int64           `json:"requestCount"`
+	RevokedAt    *time.Time      `json:"revokedAt,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// HasScope reports whether the token grants scope. Admin grants every scope.
+func (t *APIToken) HasScope(scope APITokenScope) bool {
+	for _, s := range t.Scopes {
+		if s == scope || s == APIScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the token can still authenticate at now.
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
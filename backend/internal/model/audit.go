@@ -16,11 +16,90 @@ const (
 	AuditDataExport       = "DATA_EXPORT"
 	AuditForgeGenerate    = "FORGE_GENERATE"
 	AuditUserLogin        = "USER_LOGIN"
+	AuditShareGrant       = "SHARE_GRANT"
+	AuditShareRevoke      = "SHARE_REVOKE"
+	AuditShareGroupAdd    = "SHARE_GROUP_MEMBER_ADD"
+	AuditShareGroupRemove = "SHARE_GROUP_MEMBER_REMOVE"
+	AuditAPITokenCreate   = "API_TOKEN_CREATE"
+	AuditAPITokenRevoke   = "API_TOKEN_REVOKE"
+
+	// Document content access and changes
+	AuditDocumentView            = "DOCUMENT_VIEW"
+	AuditDocumentDownload        = "DOCUMENT_DOWNLOAD"
+	AuditChunkPreview            = "CHUNK_PREVIEW"
+	AuditDocumentUploadRequest   = "DOCUMENT_UPLOAD_REQUEST"
+	AuditDocumentIngest          = "DOCUMENT_INGEST"
+	AuditDocumentUpdate          = "DOCUMENT_UPDATE"
+	AuditDocumentTierChange      = "DOCUMENT_TIER_CHANGE"
+	AuditDocumentPrivilegeChange = "DOCUMENT_PRIVILEGE_CHANGE"
+	AuditDocumentChunksDelete    = "DOCUMENT_CHUNKS_DELETE"
+	AuditDocumentVerify          = "DOCUMENT_VERIFY"
+	AuditDocumentStar            = "DOCUMENT_STAR"
+
+	// Folders and vaults
+	AuditFolderCreate        = "FOLDER_CREATE"
+	AuditFolderDelete        = "FOLDER_DELETE"
+	AuditVaultCreate         = "VAULT_CREATE"
+	AuditVaultUpdate         = "VAULT_UPDATE"
+	AuditVaultArchive        = "VAULT_ARCHIVE"
+	AuditVaultRestore        = "VAULT_RESTORE"
+	AuditVaultDocumentsMove  = "VAULT_DOCUMENTS_MOVE"
+	AuditVaultHealthCheck    = "VAULT_HEALTH_CHECK"
+	AuditShareGroupCreate    = "SHARE_GROUP_CREATE"
+	AuditShareGroupDelete    = "SHARE_GROUP_DELETE"
+	AuditContentGapUpdate    = "CONTENT_GAP_UPDATE"
+	AuditInsightAcknowledge  = "INSIGHT_ACKNOWLEDGE"
+	AuditInsightScan         = "INSIGHT_SCAN"
+	AuditVoiceTranscribe     = "VOICE_TRANSCRIBE"
+	AuditPersonaConfigUpdate = "PERSONA_CONFIG_UPDATE"
+	AuditAuditExport         = "AUDIT_EXPORT"
+
+	// Organization membership
+	AuditOrgCreate       = "ORG_CREATE"
+	AuditOrgRename       = "ORG_RENAME"
+	AuditOrgMemberUpdate = "ORG_MEMBER_UPDATE"
+	AuditOrgMemberRemove = "ORG_MEMBER_REMOVE"
+	AuditOrgInviteCreate = "ORG_INVITE_CREATE"
+	AuditOrgInviteAccept = "ORG_INVITE_ACCEPT"
+	AuditOrgInviteRevoke = "ORG_INVITE_REVOKE"
+
+	// Legal holds
+	AuditLegalHoldCreate  = "LEGAL_HOLD_CREATE"
+	AuditLegalHoldRelease = "LEGAL_HOLD_RELEASE"
+	AuditLegalHoldBlocked = "LEGAL_HOLD_BLOCKED"
+	AuditLegalHoldReport  = "LEGAL_HOLD_REPORT"
+
+	// Retention. DOCUMENT_PURGE is the tombstone the purge job leaves for a
+	// hard-deleted document, recorded against the document's owner.
+	AuditRetentionPolicySet    = "RETENTION_POLICY_SET"
+	AuditRetentionPolicyDelete = "RETENTION_POLICY_DELETE"
+	AuditRetentionPreview      = "RETENTION_PURGE_PREVIEW"
+	AuditDocumentPurge         = "DOCUMENT_PURGE"
+
+	// PII redaction policies
+	AuditPIIPolicySet    = "PII_POLICY_SET"
+	AuditPIIPolicyDelete = "PII_POLICY_DELETE"
+
+	// Operator actions on internal admin routes (no user)
+	AuditAdminMigrate         = "ADMIN_MIGRATE"
+	AuditAdminCacheFlush      = "ADMIN_CACHE_FLUSH"
+	AuditAdminRateLimitReload = "ADMIN_RATE_LIMIT_RELOAD"
 )
 
+// AuditSystemTenant is the hash chain for audit entries without a user.
+// Entries with a user are chained per organization (or personal scope).
+const AuditSystemTenant = "system"
+
 // AuditLog represents an immutable audit trail entry.
+//
+// Entries form one SHA-256 hash chain per tenant: Seq numbers a tenant's
+// entries from 1 without gaps, and PrevHash is the DetailsHash of the entry
+// before (empty for the first).
 type AuditLog struct {
 	ID           string          `json:"id"`
+	TenantID     string          `json:"tenantId,omitempty"`
+	Seq          int64           `json:"seq,omitempty"`
+	PrevHash     *string         `json:"prevHash,omitempty"`
 	UserID       *string         `json:"userId,omitempty"`
 	Action       string          `json:"action"`
 	ResourceID   *string         `json:"resourceId,omitempty"`
@@ -32,3 +111,25 @@ type AuditLog struct {
 	UserAgent    *string         `json:"userAgent,omitempty"`
 	CreatedAt    time.Time       `json:"createdAt"`
 }
+
+// AuditCheckpoint is a signed Merkle root over a run of one tenant's audit
+// chain. Checkpoints of a tenant are contiguous: each starts at the seq
+// after the previous one ended.
+type AuditCheckpoint struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenantId"`
+	FromSeq   int64     `json:"fromSeq"`
+	ToSeq     int64     `json:"toSeq"`
+	RootHash  string    `json:"rootHash"`  // hex
+	Signature string    `json:"signature"` // base64 Ed25519 signature
+	KeyID     string    `json:"keyId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AuditOutboxItem is an audit entry queued for delivery to one sink.
+type AuditOutboxItem struct {
+	ID       int64
+	Sink     string
+	Attempts int // failed deliveries so far
+	Entry    AuditLog
+}
@@ -8,15 +8,16 @@ import (
 
 // CortexEntry represents a working memory entry (conversation context or instruction).
 type CortexEntry struct {
-	ID              string          `json:"id"`
-	TenantID        string          `json:"tenantId"`
-	Content         string          `json:"content"`
-	Embedding       pgvector.Vector `json:"-"`
-	SourceChannel   string          `json:"sourceChannel"`
-	SourceMessageID *string         `json:"sourceMessageId,omitempty"`
-	CapturedAt      time.Time       `json:"capturedAt"`
-	Topic           *string         `json:"topic,omitempty"`
-	IsInstruction   bool            `json:"isInstruction"`
-	AutoSummary     *string         `json:"autoSummary,omitempty"`
-	ExpiresAt       *time.Time      `json:"expiresAt,omitempty"`
+	ID                string          `json:"id"`
+	TenantID          string          `json:"tenantId"`
+	Content           string          `json:"content"`
+	Embedding         pgvector.Vector `json:"-"`
+	SourceChannel     string          `json:"sourceChannel"`
+	SourceMessageID   *string         `json:"sourceMessageId,omitempty"`
+	CapturedAt        time.Time       `json:"capturedAt"`
+	Topic             *string         `json:"topic,omitempty"`
+	IsInstruction     bool            `json:"isInstruction"`
+	AutoSummary       *string         `json:"autoSummary,omitempty"`
+	ExpiresAt         *time.Time      `json:"expiresAt,omitempty"`
+	SourceDocumentIDs []string        `json:"sourceDocumentIds,omitempty"` // documents the content was drawn from
 }
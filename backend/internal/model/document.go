@@ -27,6 +27,7 @@ type Document struct {
 	ID             string          `json:"id"`
 	VaultID        *string         `json:"vaultId,omitempty"`
 	UserID         string          `json:"userId"`
+	OrgID          string          `json:"orgId,omitempty"` // owning organization (or personal scope)
 	Filename       string          `json:"filename"`
 	OriginalName   string          `json:"originalName"`
 	MimeType       string          `json:"mimeType"`
@@ -61,6 +62,9 @@ type DocumentChunk struct {
 	TokenCount  int       `json:"tokenCount"`
 	Embedding   []float32 `json:"-"`
 	CreatedAt   time.Time `json:"createdAt"`
+
+	// PIIFindings are set at ingest; nil means the chunk was not scanned.
+	PIIFindings []PIIFinding `json:"piiFindings,omitempty"`
 }
 
 // AllowedMimeTypes lists the mime types accepted for upload.
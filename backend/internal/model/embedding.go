@@ -0,0 +1,47 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// EmbeddingSpace identifies the model generation that produced a set of vectors.
+// Vectors are only comparable within the same space.
+type EmbeddingSpace struct {
+	Model      string `json:"model"`
+	Version    int    `json:"version"`
+	Dimensions int    `json:"dimensions"`
+}
+
+// IsZero reports whether the space is unset.
+func (s EmbeddingSpace) IsZero() bool {
+	return s.Model == "" && s.Version == 0
+}
+
+// Key returns a stable identifier for the space, e.g. "text-embedding-004@1".
+func (s EmbeddingSpace) Key() string {
+	return fmt.Sprintf("%s@%d", s.Model, s.Version)
+}
+
+type ReembedJobStatus string
+
+const (
+	ReembedRunning   ReembedJobStatus = "running"
+	ReembedCompleted ReembedJobStatus = "completed"
+	ReembedFailed    ReembedJobStatus = "failed"
+	ReembedCancelled ReembedJobStatus = "cancelled"
+)
+
+// ReembedJob tracks an online re-embedding of all chunks into a new embedding space.
+type ReembedJob struct {
+	ID              string           `json:"id"`
+	Target          EmbeddingSpace   `json:"target"`
+	Status          ReembedJobStatus `json:"status"`
+	TotalChunks     int              `json:"totalChunks"`
+	ProcessedChunks int              `json:"processedChunks"`
+	LastChunkID     string           `json:"lastChunkId"`
+	Error           *string          `json:"error,omitempty"`
+	CreatedAt       time.Time        `json:"createdAt"`
+	UpdatedAt       time.Time        `json:"updatedAt"`
+	CompletedAt     *time.Time       `json:"completedAt,omitempty"`
+}
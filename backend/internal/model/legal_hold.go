@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// LegalHoldResourceType is the kind of resource a legal hold covers. Folder
+// holds reach every document and subfolder beneath the folder; vault holds
+// reach every document in the vault.
+type LegalHoldResourceType string
+
+const (
+	LegalHoldDocument LegalHoldResourceType = "document"
+	LegalHoldFolder   LegalHoldResourceType = "folder"
+	LegalHoldVault    LegalHoldResourceType = "vault"
+)
+
+// ValidLegalHoldResourceType reports whether t is a known hold resource type.
+func ValidLegalHoldResourceType(t LegalHoldResourceType) bool {
+	return t == LegalHoldDocument || t == LegalHoldFolder || t == LegalHoldVault
+}
+
+// LegalHoldScope is one resource a legal hold covers.
+type LegalHoldScope struct {
+	ResourceType LegalHoldResourceType `json:"resourceType"`
+	ResourceID   string                `json:"resourceId"`
+}
+
+// LegalHold freezes the documents under its scopes for a litigation
+// matter: while active they cannot be deleted, renamed, moved, re-ingested
+// or purged. Released holds are kept as a record.
+type LegalHold struct {
+	ID            string           `json:"id"`
+	OrgID         string           `json:"orgId"`
+	Name          string           `json:"name"`
+	MatterID      string           `json:"matterId"`
+	Custodian     string           `json:"custodian"`
+	Scopes        []LegalHoldScope `json:"scopes"`
+	CreatedBy     string           `json:"createdBy"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	ReleasedBy    *string          `json:"releasedBy,omitempty"`
+	ReleasedAt    *time.Time       `json:"releasedAt,omitempty"`
+	ReleaseReason *string          `json:"releaseReason,omitempty"`
+}
+
+// Active reports whether the hold has not been released.
+func (h *LegalHold) Active() bool {
+	return h.ReleasedAt == nil
+}
+
+// HeldDocument is a document under a legal hold, as listed in the hold report.
+type HeldDocument struct {
+	ID             string         `json:"id"`
+	OriginalName   string         `json:"originalName"`
+	UserID         string         `json:"userId"`
+	FolderID       *string        `json:"folderId,omitempty"`
+	VaultID        *string        `json:"vaultId,omitempty"`
+	DeletionStatus DeletionStatus `json:"deletionStatus"`
+	SizeBytes      int            `json:"sizeBytes"`
+	ChunkCount     int            `json:"chunkCount"`
+	Checksum       *string        `json:"checksum,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+}
+
+// LegalHoldReport lists everything a legal hold covers.
+type LegalHoldReport struct {
+	Hold       LegalHold      `json:"hold"`
+	Documents  []HeldDocument `json:"documents"`
+	TotalBytes int64          `json:"totalBytes"`
+}
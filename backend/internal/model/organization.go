@@ -0,0 +1,65 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// personalOrgPrefix marks the implicit scope of a user with no organization.
+const personalOrgPrefix = "personal:"
+
+// PersonalOrgID returns the org ID that scopes a user who is not a member of
+// any organization. It matches the SQL function user_org_id().
+func PersonalOrgID(userID string) string {
+	return personalOrgPrefix + userID
+}
+
+// IsPersonalOrg reports whether orgID is a personal scope rather than an
+// organization.
+func IsPersonalOrg(orgID string) bool {
+	return strings.HasPrefix(orgID, personalOrgPrefix)
+}
+
+// Organization is a firm whose members share vaults, documents and usage limits.
+type Organization struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	SubscriptionTier string    `json:"subscriptionTier"`
+	CreatedBy        string    `json:"createdBy"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// OrgMember is a user's membership in an organization. Role is the firm role:
+// Partners administer the organization, Associates work in it, Auditors
+// have read-only access.
+type OrgMember struct {
+	OrgID    string    `json:"orgId"`
+	UserID   string    `json:"userId"`
+	Email    string    `json:"email,omitempty"`
+	Name     *string   `json:"name,omitempty"`
+	Role     UserRole  `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// OrgInvite is a pending invitation to join an organization.
+type OrgInvite struct {
+	ID         string     `json:"id"`
+	OrgID      string     `json:"orgId"`
+	Email      string     `json:"email"`
+	Role       UserRole   `json:"role"`
+	InvitedBy  string     `json:"invitedBy"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	AcceptedBy *string    `json:"acceptedBy,omitempty"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// ValidUserRole reports whether r is a known firm role.
+func ValidUserRole(r UserRole) bool {
+	switch r {
+	case UserRolePartner, UserRoleAssociate, UserRoleAuditor:
+		return true
+	}
+	return false
+}
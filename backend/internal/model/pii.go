@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// PIIFinding locates detected PII/PHI in a chunk's content by byte offsets
+// into the plaintext. The matched text itself is never stored.
+type PIIFinding struct {
+	InfoType string  `json:"infoType"`
+	Start    int     `json:"start"`
+	End      int     `json:"end"`
+	Score    float64 `json:"score"`
+}
+
+// PIIRedactionPolicy decides which PII/PHI info types are redacted from a
+// vault's citations, snippets, previews and answers. Callers in Privileged
+// Mode, and callers with one of ExemptRoles, see the text unredacted. An
+// empty InfoTypes turns redaction off. The default policy has no VaultID.
+type PIIRedactionPolicy struct {
+	VaultID     string     `json:"vaultId,omitempty"`
+	InfoTypes   []string   `json:"infoTypes"`
+	ExemptRoles []UserRole `json:"exemptRoles"`
+	UpdatedBy   string     `json:"updatedBy,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+// PIIPolicySource names where a PIIRedactionPolicy came from.
+type PIIPolicySource string
+
+const (
+	PIIPolicyFromVault   PIIPolicySource = "vault"
+	PIIPolicyFromDefault PIIPolicySource = "default"
+)
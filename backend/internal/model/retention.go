@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// RetentionPolicy sets how long soft-deleted documents in a vault, or of a
+// document type, are kept before the purge job hard-deletes them. Exactly
+// one of VaultID and DocumentType is set; a vault policy takes precedence
+// over a document type policy.
+type RetentionPolicy struct {
+	ID            string    `json:"id"`
+	OrgID         string    `json:"orgId"`
+	VaultID       *string   `json:"vaultId,omitempty"`
+	DocumentType  *string   `json:"documentType,omitempty"`
+	RetentionDays int       `json:"retentionDays"`
+	CreatedBy     string    `json:"createdBy"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// RetentionSource names what set a document's retention period.
+type RetentionSource string
+
+const (
+	RetentionFromVault        RetentionSource = "vault"
+	RetentionFromDocumentType RetentionSource = "document_type"
+	RetentionFromDefault      RetentionSource = "default"
+)
+
+// PurgeCandidate is a soft-deleted document whose retention period has
+// expired. HoldIDs and MatterIDs list the active legal holds keeping it.
+type PurgeCandidate struct {
+	ID              string          `json:"id"`
+	UserID          string          `json:"userId"`
+	OrgID           string          `json:"orgId"`
+	OriginalName    string          `json:"originalName"`
+	VaultID         *string         `json:"vaultId,omitempty"`
+	DocumentType    *string         `json:"documentType,omitempty"`
+	SizeBytes       int             `json:"sizeBytes"`
+	ChunkCount      int             `json:"chunkCount"`
+	Checksum        *string         `json:"checksum,omitempty"`
+	StoragePath     *string         `json:"-"`
+	StorageURI      *string         `json:"-"`
+	GraphCleaned    bool            `json:"-"` // set by PendingCleanup
+	DeletedAt       time.Time       `json:"deletedAt"`
+	RetentionDays   int             `json:"retentionDays"`
+	RetentionSource RetentionSource `json:"retentionSource"`
+	PurgeAfter      time.Time       `json:"purgeAfter"`
+	HoldIDs         []string        `json:"holdIds,omitempty"`
+	MatterIDs       []string        `json:"matterIds,omitempty"`
+}
+
+// Held reports whether an active legal hold keeps the document.
+func (c *PurgeCandidate) Held() bool {
+	return len(c.HoldIDs) > 0
+}
+
+// PurgeReport describes a purge run, or in a dry run what a run at AsOf
+// would purge. TotalBytes counts the documents not held.
+type PurgeReport struct {
+	DryRun               bool             `json:"dryRun"`
+	AsOf                 time.Time        `json:"asOf"`
+	DefaultRetentionDays int              `json:"defaultRetentionDays"`
+	Documents            []PurgeCandidate `json:"documents"`
+	Truncated            bool             `json:"truncated,omitempty"`
+	Purged               int              `json:"purged"`
+	Held                 int              `json:"held"`
+	Failed               int              `json:"failed"`
+	TotalBytes           int64            `json:"totalBytes"`
+}
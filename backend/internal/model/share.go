@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// ShareRole is the access a share grant gives: viewers read, editors also modify.
+type ShareRole string
+
+const (
+	ShareViewer ShareRole = "viewer"
+	ShareEditor ShareRole = "editor"
+)
+
+// ShareResourceType is the kind of resource a grant applies to. Folder
+// grants reach every document and subfolder beneath the folder.
+type ShareResourceType string
+
+const (
+	ShareResourceDocument ShareResourceType = "document"
+	ShareResourceFolder   ShareResourceType = "folder"
+)
+
+// ShareGranteeType is who a grant is for: a single user or a share group.
+type ShareGranteeType string
+
+const (
+	ShareGranteeUser  ShareGranteeType = "user"
+	ShareGranteeGroup ShareGranteeType = "group"
+)
+
+// ShareGrant gives a user or group access to a document or folder they do
+// not otherwise have. A grant with a past ExpiresAt no longer applies.
+type ShareGrant struct {
+	ID           string            `json:"id"`
+	ResourceType ShareResourceType `json:"resourceType"`
+	ResourceID   string            `json:"resourceId"`
+	GranteeType  ShareGranteeType  `json:"granteeType"`
+	GranteeID    string            `json:"granteeId"`
+	Role         ShareRole         `json:"role"`
+	GrantedBy    string            `json:"grantedBy"`
+	ExpiresAt    *time.Time        `json:"expiresAt,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt"`
+}
+
+// ShareGroup is a named set of organization members that grants can target.
+type ShareGroup struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"orgId"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"createdBy"`
+	MemberIDs []string  `json:"memberIds"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ValidShareRole reports whether r is a known share role.
+func ValidShareRole(r ShareRole) bool {
+	return r == ShareViewer || r == ShareEditor
+}
+
+// ValidShareGranteeType reports whether t is a known grantee type.
+func ValidShareGranteeType(t ShareGranteeType) bool {
+	return t == ShareGranteeUser || t == ShareGranteeGroup
+}
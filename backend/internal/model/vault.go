@@ -15,18 +15,30 @@ type Vault struct {
 	ID               string      `json:"id"`
 	Name             string      `json:"name"`
 	UserID           string      `json:"userId"`
+	OrgID            string      `json:"orgId,omitempty"`
 	Status           VaultStatus `json:"status"`
 	DocumentCount    int         `json:"documentCount"`
 	StorageUsedBytes int64       `json:"storageUsedBytes"`
+	ArchivedAt       *time.Time  `json:"archivedAt,omitempty"`
 	CreatedAt        time.Time   `json:"createdAt"`
 	UpdatedAt        time.Time   `json:"updatedAt"`
 }
 
+// ValidVaultStatus reports whether s is a known vault status.
+func ValidVaultStatus(s VaultStatus) bool {
+	switch s {
+	case VaultOpen, VaultClosed, VaultSecure:
+		return true
+	}
+	return false
+}
+
 // Folder represents a file organization folder.
 type Folder struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
 	UserID    string    `json:"userId"`
+	OrgID     string    `json:"orgId,omitempty"`
 	ParentID  *string   `json:"parentId,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
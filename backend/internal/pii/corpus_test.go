@@ -0,0 +1,259 @@
+package pii
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// label marks one expected finding: the exact text of the span, which must
+// occur once in the document.
+type label struct {
+	infoType string
+	value    string
+}
+
+// labelledDoc is one corpus entry. Documents without labels are hard
+// negatives: text that looks like PII but is not.
+type labelledDoc struct {
+	text   string
+	labels []label
+}
+
+// corpus is a labelled set of snippets in the style of the legal, medical
+// and financial documents RAGbox ingests. Values are synthetic: card
+// numbers are network test numbers and phone numbers use fictional ranges.
+var corpus = []labelledDoc{
+	// ─── Legal ───
+	{
+		text: "Plaintiff Maria Lopez (SSN 536-22-1847) resides at 14 Elm Street and may be reached at (415) 555-0132 or maria.lopez@lopezfamily.net.",
+		labels: []label{
+			{InfoTypePersonName, "Maria Lopez"}, {InfoTypeSSN, "536-22-1847"},
+			{InfoTypePhone, "(415) 555-0132"}, {InfoTypeEmail, "maria.lopez@lopezfamily.net"},
+		},
+	},
+	{
+		text: "UNITED STATES DISTRICT COURT, Northern District of California. Case No. 3:21-cv-04417-JD. Filed 2021-06-14.",
+	},
+	{
+		text:   "Before the Honorable Judge Sarah Kim. Counsel for the defendant, David Nguyen, appeared by phone.",
+		labels: []label{{InfoTypePersonName, "Sarah Kim"}, {InfoTypePersonName, "David Nguyen"}},
+	},
+	{
+		text:   "The deposition of Dr. Patel is scheduled for March 3 at the offices of Morgan Stanley in New York.",
+		labels: []label{{InfoTypePersonName, "Patel"}},
+	},
+	{
+		text: "Pursuant to Section 1983 and Article III, the Court of Appeals for the Ninth Circuit affirmed on April 2.",
+	},
+	{
+		text:   "Witness statement signed by John Q. Smith on behalf of Acme Holdings LLC, EIN 94-1234567.",
+		labels: []label{{InfoTypePersonName, "John Q. Smith"}},
+	},
+	{
+		text: "Exhibit 12 lists invoice numbers 2024-0031, 2024-0032 and purchase order PO-778812.",
+	},
+	{
+		text:   "Dear Jennifer Wu, please find enclosed the settlement agreement. Contact our office at 212-555-0198 with any questions.",
+		labels: []label{{InfoTypePersonName, "Jennifer Wu"}, {InfoTypePhone, "212-555-0198"}},
+	},
+	{
+		text: "The Washington State Supreme Court and the Virginia Beach Circuit Court both cite 42 U.S.C. 1983.",
+	},
+	{
+		text:   "Ms. Garcia testified that her social security number, 401 88 2931, was used without consent.",
+		labels: []label{{InfoTypePersonName, "Garcia"}, {InfoTypeSSN, "401 88 2931"}},
+	},
+
+	// ─── Tax and finance ───
+	{
+		text:   "Taxpayer ITIN: 912-78-3456. Refund to be mailed to the address on file.",
+		labels: []label{{InfoTypeITIN, "912-78-3456"}},
+	},
+	{
+		text:   "Form W-7 approved. ITIN 900-70-1234 issued to the applicant, Wei Zhang.",
+		labels: []label{{InfoTypeITIN, "900-70-1234"}, {InfoTypePersonName, "Wei Zhang"}},
+	},
+	{
+		text: "Sample numbers from the instructions (000-12-3456, 666-45-1234, 078-05-1120, 912-45-3456) are never valid.",
+	},
+	{
+		text:   "Payment was charged to Visa card 4111 1111 1111 1111, exp 09/27, and refunded to Mastercard 5555-5555-5555-4444.",
+		labels: []label{{InfoTypeCreditCard, "4111 1111 1111 1111"}, {InfoTypeCreditCard, "5555-5555-5555-4444"}},
+	},
+	{
+		text:   "Corporate Amex 3714 496353 98431 and Discover 6011111111111117 were both closed in May.",
+		labels: []label{{InfoTypeCreditCard, "3714 496353 98431"}, {InfoTypeCreditCard, "6011111111111117"}},
+	},
+	{
+		text: "Tracking number 9400 1000 0000 0000 0000 00 and reference 1234 5678 9012 3456 do not identify anyone.",
+	},
+	{
+		text: "The disputed card ending 4111 1111 1111 1112 failed the bank's checksum, so the charge was void.",
+	},
+	{
+		text:   "Wire instructions: account holder Robert Johnson, card on file 4539578763621486.",
+		labels: []label{{InfoTypePersonName, "Robert Johnson"}, {InfoTypeCreditCard, "4539578763621486"}},
+	},
+	{
+		text: "Revenue grew 12.5% to $4,200,000 in fiscal 2023; headcount rose from 1,250 to 1,410.",
+	},
+	{
+		text:   "Please remit to billing@acme-corp.com. Questions: +1 212 555 0147.",
+		labels: []label{{InfoTypeEmail, "billing@acme-corp.com"}, {InfoTypePhone, "+1 212 555 0147"}},
+	},
+
+	// ─── Medical ───
+	{
+		text: "Patient: Emily Carter. MRN: 00482913. DOB 04/12/1981. Insurance member ID: XGH492817364.",
+		labels: []label{
+			{InfoTypePersonName, "Emily Carter"}, {InfoTypeMedicalRecord, "00482913"},
+			{InfoTypeHealthInsurance, "XGH492817364"},
+		},
+	},
+	{
+		text:   "Medical Record Number 7734521 was merged with chart no. 7734522 after the duplicate registration.",
+		labels: []label{{InfoTypeMedicalRecord, "7734521"}, {InfoTypeMedicalRecord, "7734522"}},
+	},
+	{
+		text:   "Medicare MBI 1EG4-TE5-MK73 verified; secondary coverage policy number HMO-4477821.",
+		labels: []label{{InfoTypeHealthInsurance, "1EG4-TE5-MK73"}, {InfoTypeHealthInsurance, "HMO-4477821"}},
+	},
+	{
+		text:   "Patient ID: PT-448812 was discharged by Nurse Thompson to the care of her daughter.",
+		labels: []label{{InfoTypeMedicalRecord, "PT-448812"}, {InfoTypePersonName, "Thompson"}},
+	},
+	{
+		text: "MRN pending registration. Policy number to be confirmed by the insurer. Room 4417, bed 2.",
+	},
+	{
+		text: "Administer 500 mg amoxicillin every 8 hours for 10 days; recheck BP 120/80 in 2 weeks.",
+	},
+	{
+		text:   "Referring physician: Dr. Michael Chen, tel. 650.253.0000, fax 650.253.0001.",
+		labels: []label{{InfoTypePersonName, "Michael Chen"}, {InfoTypePhone, "650.253.0000"}, {InfoTypePhone, "650.253.0001"}},
+	},
+	{
+		text: "The St. Mary Hospital oncology center in Los Angeles treated 1,204 patients in 2022.",
+	},
+
+	// ─── International and messaging ───
+	{
+		text:   "London office: +44 20 7946 0958. Paris office: +33 1 42 68 53 00.",
+		labels: []label{{InfoTypePhone, "+44 20 7946 0958"}, {InfoTypePhone, "+33 1 42 68 53 00"}},
+	},
+	{
+		text:   "WhatsApp +12125550147 or email priya.shah+intake@clinic.example.org to reschedule.",
+		labels: []label{{InfoTypePhone, "+12125550147"}, {InfoTypeEmail, "priya.shah+intake@clinic.example.org"}},
+	},
+	{
+		text: "Dial 911 in an emergency or 411 for directory assistance. Extension 5550.",
+	},
+	{
+		text: "Version 2.14.3 released on 2024-03-15; build 20240315.1 fixes CVE-2024-21626.",
+	},
+	{
+		text: "Reach us on social media at @ragbox or visit ragbox.co/contact.",
+	},
+	{
+		text:   "Employee Kevin Brown (SSN: 212345678) was enrolled in the plan on 01/01/2024.",
+		labels: []label{{InfoTypePersonName, "Kevin Brown"}, {InfoTypeSSN, "212345678"}},
+	},
+	{
+		text: "The invoice total was 5,205,551,234 yen, paid in three installments of 1,735,183,744 yen.",
+	},
+	{
+		text: "Call center hours are 9-5. Order 123-456-7890 shipped from warehouse 3.",
+	},
+}
+
+type typeScore struct{ tp, fp, fn int }
+
+func (s typeScore) precision() float64 {
+	if s.tp+s.fp == 0 {
+		return 1
+	}
+	return float64(s.tp) / float64(s.tp+s.fp)
+}
+
+func (s typeScore) recall() float64 {
+	if s.tp+s.fn == 0 {
+		return 1
+	}
+	return float64(s.tp) / float64(s.tp+s.fn)
+}
+
+// TestDetector_CorpusPrecisionRecall scores the detector on the labelled
+// corpus, counting a finding as correct only with the exact type and span.
+func TestDetector_CorpusPrecisionRecall(t *testing.T) {
+	d := NewDetector(nil)
+	scores := make(map[string]*typeScore)
+	score := func(infoType string) *typeScore {
+		if scores[infoType] == nil {
+			scores[infoType] = &typeScore{}
+		}
+		return scores[infoType]
+	}
+
+	for i, doc := range corpus {
+		type span struct {
+			infoType   string
+			start, end int
+		}
+		want := make(map[span]bool)
+		for _, l := range doc.labels {
+			if n := strings.Count(doc.text, l.value); n != 1 {
+				t.Fatalf("corpus[%d]: label %q occurs %d times, want once", i, l.value, n)
+			}
+			start := strings.Index(doc.text, l.value)
+			want[span{l.infoType, start, start + len(l.value)}] = true
+		}
+
+		findings, err := d.InspectContent(context.Background(), "", doc.text, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range findings {
+			s := span{f.InfoType, f.StartIndex, f.EndIndex}
+			if want[s] {
+				score(f.InfoType).tp++
+				delete(want, s)
+				continue
+			}
+			score(f.InfoType).fp++
+			t.Logf("corpus[%d]: false positive %s %q (%.2f)", i, f.InfoType, f.Content, f.Score)
+		}
+		for s := range want {
+			score(s.infoType).fn++
+			t.Logf("corpus[%d]: missed %s %q", i, s.infoType, doc.text[s.start:s.end])
+		}
+	}
+
+	var total typeScore
+	types := make([]string, 0, len(scores))
+	for infoType := range scores {
+		types = append(types, infoType)
+	}
+	sort.Strings(types)
+	for _, infoType := range types {
+		s := scores[infoType]
+		total.tp += s.tp
+		total.fp += s.fp
+		total.fn += s.fn
+		t.Logf("%-45s precision %.2f recall %.2f (tp %d fp %d fn %d)",
+			infoType, s.precision(), s.recall(), s.tp, s.fp, s.fn)
+		if s.precision() < 0.8 || s.recall() < 0.8 {
+			t.Errorf("%s: precision %.2f, recall %.2f, want both >= 0.80", infoType, s.precision(), s.recall())
+		}
+	}
+	t.Logf("overall precision %.2f recall %.2f", total.precision(), total.recall())
+	if total.precision() < 0.95 || total.recall() < 0.9 {
+		t.Errorf("overall precision %.2f, recall %.2f, want >= 0.95 and >= 0.90", total.precision(), total.recall())
+	}
+	for _, infoType := range NewDetector(nil).InfoTypes() {
+		if s := scores[infoType]; s == nil || s.tp == 0 {
+			t.Errorf("corpus has no detected %s; add labelled examples", infoType)
+		}
+	}
+}
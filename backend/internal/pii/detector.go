@@ -0,0 +1,482 @@
+package pii
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Info types, named as in Cloud DLP so findings are interchangeable.
+const (
+	InfoTypePersonName      = "PERSON_NAME"
+	InfoTypeEmail           = "EMAIL_ADDRESS"
+	InfoTypePhone           = "PHONE_NUMBER"
+	InfoTypeSSN             = "US_SOCIAL_SECURITY_NUMBER"
+	InfoTypeCreditCard      = "CREDIT_CARD_NUMBER"
+	InfoTypeITIN            = "US_INDIVIDUAL_TAXPAYER_IDENTIFICATION_NUMBER"
+	InfoTypeMedicalRecord   = "MEDICAL_RECORD_NUMBER"
+	InfoTypeHealthInsurance = "HEALTH_INSURANCE_ID"
+)
+
+// DefaultMinScore keeps findings of POSSIBLE likelihood and above, the
+// Cloud DLP default.
+const DefaultMinScore = 0.4
+
+// contextWindow is how many bytes before a match are searched for context
+// keywords.
+const contextWindow = 48
+
+// contextBoost is added to a finding's score when a context keyword for its
+// info type precedes it.
+const contextBoost = 0.25
+
+// Likelihood returns the Cloud DLP likelihood name for a score in [0, 1].
+func Likelihood(score float64) string {
+	switch {
+	case score >= 0.8:
+		return "VERY_LIKELY"
+	case score >= 0.6:
+		return "LIKELY"
+	case score >= 0.4:
+		return "POSSIBLE"
+	case score >= 0.2:
+		return "UNLIKELY"
+	default:
+		return "VERY_UNLIKELY"
+	}
+}
+
+// Detector finds PII/PHI in text without network calls.
+type Detector struct {
+	names    Gazetteer
+	minScore float64
+	rules    map[string]func(text string) []service.Finding
+}
+
+// Compile-time check.
+var _ service.DLPClient = (*Detector)(nil)
+
+// NewDetector creates a Detector that recognises person names with names,
+// or with DefaultGazetteer when names is nil.
+func NewDetector(names Gazetteer) *Detector {
+	if names == nil {
+		names = DefaultGazetteer()
+	}
+	d := &Detector{names: names, minScore: DefaultMinScore}
+	d.rules = map[string]func(string) []service.Finding{
+		InfoTypePersonName:      d.findNames,
+		InfoTypeEmail:           findEmails,
+		InfoTypePhone:           findPhones,
+		InfoTypeSSN:             findSSNs,
+		InfoTypeCreditCard:      findCards,
+		InfoTypeITIN:            findITINs,
+		InfoTypeMedicalRecord:   findMRNs,
+		InfoTypeHealthInsurance: findInsuranceIDs,
+	}
+	return d
+}
+
+// SetMinScore sets the score below which findings are dropped.
+func (d *Detector) SetMinScore(score float64) {
+	d.minScore = score
+}
+
+// InfoTypes returns the info types the detector supports, sorted.
+func (d *Detector) InfoTypes() []string {
+	types := make([]string, 0, len(d.rules))
+	for t := range d.rules {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// InspectContent implements service.DLPClient. project is ignored; an
+// empty infoTypes runs every rule, and unsupported info types are skipped.
+// Findings are ordered by StartIndex and never overlap.
+func (d *Detector) InspectContent(ctx context.Context, project string, text string, infoTypes []string) ([]service.Finding, error) {
+	if len(infoTypes) == 0 {
+		infoTypes = d.InfoTypes()
+	}
+
+	var all []service.Finding
+	for _, t := range infoTypes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		rule, ok := d.rules[t]
+		if !ok {
+			continue
+		}
+		for _, f := range rule(text) {
+			if f.Score > 0.99 {
+				f.Score = 0.99
+			}
+			if f.Score < d.minScore {
+				continue
+			}
+			f.Likelihood = Likelihood(f.Score)
+			f.Content = text[f.StartIndex:f.EndIndex]
+			all = append(all, f)
+		}
+	}
+	return resolveOverlaps(all), nil
+}
+
+// resolveOverlaps keeps, among overlapping findings, the highest-scoring
+// one (then the longest).
+func resolveOverlaps(findings []service.Finding) []service.Finding {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.EndIndex-a.StartIndex > b.EndIndex-b.StartIndex
+	})
+	kept := make([]service.Finding, 0, len(findings))
+	for _, f := range findings {
+		overlaps := false
+		for _, k := range kept {
+			if f.StartIndex < k.EndIndex && k.StartIndex < f.EndIndex {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			kept = append(kept, f)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].StartIndex < kept[j].StartIndex })
+	return kept
+}
+
+// finding builds a finding for text[start:end]; Content and Likelihood are
+// filled in by InspectContent.
+func finding(infoType string, start, end int, score float64) service.Finding {
+	return service.Finding{InfoType: infoType, StartIndex: start, EndIndex: end, Score: score}
+}
+
+// hasContext reports whether any keyword occurs in the contextWindow bytes
+// before start. Keywords are lower case.
+func hasContext(text string, start int, keywords []string) bool {
+	from := start - contextWindow
+	if from < 0 {
+		from = 0
+	}
+	window := strings.ToLower(text[from:start])
+	for _, kw := range keywords {
+		if strings.Contains(window, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// boundedByDigits reports whether the match at [start, end) continues into
+// a neighbouring digit or digit group (e.g. "123-45-6789-01"), which makes
+// it part of a longer number.
+func boundedByDigits(text string, start, end int) bool {
+	if start > 0 && isDigit(text[start-1]) {
+		return true
+	}
+	if start > 1 && (text[start-1] == '-' || text[start-1] == '.') && isDigit(text[start-2]) {
+		return true
+	}
+	if end < len(text) && isDigit(text[end]) {
+		return true
+	}
+	if end+1 < len(text) && (text[end] == '-' || text[end] == '.') && isDigit(text[end+1]) {
+		return true
+	}
+	return false
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+func isLetter(b byte) bool { return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') }
+
+func digitsOf(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// ─── Email ──────────────────────────────────────────────────────────────
+
+var emailRe = regexp.MustCompile(`\b[A-Za-z0-9][A-Za-z0-9._%+-]{0,63}@[A-Za-z0-9](?:[A-Za-z0-9-]{0,62}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,62}[A-Za-z0-9])?)*\.[A-Za-z]{2,24}\b`)
+
+func findEmails(text string) []service.Finding {
+	var out []service.Finding
+	for _, m := range emailRe.FindAllStringIndex(text, -1) {
+		out = append(out, finding(InfoTypeEmail, m[0], m[1], 0.9))
+	}
+	return out
+}
+
+// ─── SSN and ITIN ───────────────────────────────────────────────────────
+
+var (
+	ssnRe         = regexp.MustCompile(`\b(\d{3})([- ]?)(\d{2})([- ]?)(\d{4})\b`)
+	ssnContext    = []string{"ssn", "social security", "soc. sec", "ss#", "ss #"}
+	itinContext   = []string{"itin", "taxpayer identification", "tax id", "tin:", "tin #", "tin no"}
+	advertisedSSN = map[string]bool{"078051120": true, "219099999": true, "123456789": true}
+)
+
+// ssnCandidates yields 9-digit groups with consistent separators (all
+// dashes, all spaces or none) that are not part of a longer number.
+func ssnCandidates(text string, yield func(start, end int, digits string, separated bool)) {
+	for _, m := range ssnRe.FindAllStringSubmatchIndex(text, -1) {
+		sep1, sep2 := text[m[4]:m[5]], text[m[8]:m[9]]
+		if sep1 != sep2 || boundedByDigits(text, m[0], m[1]) {
+			continue
+		}
+		yield(m[0], m[1], text[m[2]:m[3]]+text[m[6]:m[7]]+text[m[10]:m[11]], sep1 != "")
+	}
+}
+
+// validSSN applies the SSA's assignment rules: no 000, 666 or 9xx area
+// number, no 00 group, no 0000 serial, and none of the numbers widely
+// published as examples.
+func validSSN(d string) bool {
+	area, group, serial := d[:3], d[3:5], d[5:]
+	if area == "000" || area == "666" || area[0] == '9' || group == "00" || serial == "0000" {
+		return false
+	}
+	return !advertisedSSN[d]
+}
+
+func findSSNs(text string) []service.Finding {
+	var out []service.Finding
+	ssnCandidates(text, func(start, end int, d string, separated bool) {
+		if !validSSN(d) {
+			return
+		}
+		score := 0.3
+		if separated {
+			score = 0.65
+		}
+		if hasContext(text, start, ssnContext) {
+			score += contextBoost + 0.05
+		}
+		out = append(out, finding(InfoTypeSSN, start, end, score))
+	})
+	return out
+}
+
+// validITIN checks the IRS format: a 9 area number and a group number in
+// 50-65, 70-88, 90-92 or 94-99.
+func validITIN(d string) bool {
+	if d[0] != '9' {
+		return false
+	}
+	g := int(d[3]-'0')*10 + int(d[4]-'0')
+	return (g >= 50 && g <= 65) || (g >= 70 && g <= 88) || (g >= 90 && g <= 92) || (g >= 94 && g <= 99)
+}
+
+func findITINs(text string) []service.Finding {
+	var out []service.Finding
+	ssnCandidates(text, func(start, end int, d string, separated bool) {
+		if !validITIN(d) {
+			return
+		}
+		score := 0.3
+		if separated {
+			score = 0.65
+		}
+		if hasContext(text, start, itinContext) {
+			score += contextBoost + 0.05
+		}
+		out = append(out, finding(InfoTypeITIN, start, end, score))
+	})
+	return out
+}
+
+// ─── Credit cards ───────────────────────────────────────────────────────
+
+var (
+	cardRe      = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	cardContext = []string{"card", "visa", "mastercard", "amex", "american express", "discover", "cc#", "acct", "account"}
+)
+
+// luhn reports whether digits pass the Luhn checksum.
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		n := int(digits[i] - '0')
+		if double {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// cardBrandMatches checks the issuer prefix against the number's length
+// for the major networks.
+func cardBrandMatches(d string) bool {
+	n := len(d)
+	prefix := func(k int) int {
+		v := 0
+		for i := 0; i < k && i < n; i++ {
+			v = v*10 + int(d[i]-'0')
+		}
+		return v
+	}
+	switch {
+	case d[0] == '4':
+		return n == 13 || n == 16 || n == 19 // Visa
+	case prefix(2) >= 51 && prefix(2) <= 55, prefix(4) >= 2221 && prefix(4) <= 2720:
+		return n == 16 // Mastercard
+	case prefix(2) == 34 || prefix(2) == 37:
+		return n == 15 // American Express
+	case prefix(4) == 6011, prefix(2) == 65, prefix(3) >= 644 && prefix(3) <= 649:
+		return n >= 16 && n <= 19 // Discover
+	case prefix(4) >= 3528 && prefix(4) <= 3589:
+		return n >= 16 && n <= 19 // JCB
+	case prefix(2) == 36, prefix(2) == 38, prefix(3) >= 300 && prefix(3) <= 305:
+		return n >= 14 && n <= 19 // Diners Club
+	}
+	return false
+}
+
+// consistentGrouping rejects runs that mix dashes and spaces.
+func consistentGrouping(s string) bool {
+	return !(strings.Contains(s, "-") && strings.Contains(s, " "))
+}
+
+func findCards(text string) []service.Finding {
+	var out []service.Finding
+	for _, m := range cardRe.FindAllStringIndex(text, -1) {
+		raw := text[m[0]:m[1]]
+		d := digitsOf(raw)
+		if !consistentGrouping(raw) || !cardBrandMatches(d) || !luhn(d) {
+			continue
+		}
+		score := 0.8
+		if hasContext(text, m[0], cardContext) {
+			score += contextBoost
+		}
+		out = append(out, finding(InfoTypeCreditCard, m[0], m[1], score))
+	}
+	return out
+}
+
+// ─── Phones ─────────────────────────────────────────────────────────────
+
+var (
+	// NANP: optional +1/1, area code [2-9][0-8]d, exchange [2-9]dd.
+	// Neighbouring digits are rejected in code (boundedByDigits), not by \b,
+	// so "+12125550147" matches.
+	nanpRe = regexp.MustCompile(`(?:\+1[ .-]?|\b1[ .-])?(?:\(([2-9][0-8]\d)\)[ .-]?|([2-9][0-8]\d)([ .-]?))([2-9]\d{2})([ .-]?)(\d{4})\b`)
+	// E.164 international numbers other than +1, with optional grouping.
+	e164Re       = regexp.MustCompile(`\+[2-9]\d{0,2}(?:[ .-]?\d){6,13}\b`)
+	phoneContext = []string{"phone", "tel", "cell", "mobile", "call", "fax", "contact", "text", "whatsapp", "sms"}
+)
+
+func findPhones(text string) []service.Finding {
+	var out []service.Finding
+	for _, m := range nanpRe.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[0], m[1]
+		if boundedByDigits(text, start, end) || (start > 0 && isLetter(text[start-1])) {
+			continue
+		}
+		exchange := text[m[8]:m[9]]
+		if exchange[1] == '1' && exchange[2] == '1' { // N11 service codes
+			continue
+		}
+		parens := m[2] >= 0
+		var sep1 string
+		if !parens {
+			sep1 = text[m[6]:m[7]]
+		}
+		sep2 := text[m[10]:m[11]]
+		score := 0.3 // bare ten digits
+		switch {
+		case parens:
+			score = 0.65
+		case sep1 != "" && sep1 == sep2:
+			score = 0.6
+		case sep1 != sep2:
+			continue // "555-1234567"
+		}
+		if text[start] == '+' {
+			score = 0.7 // E.164 form of a NANP number
+		}
+		if hasContext(text, start, phoneContext) {
+			score += contextBoost
+		}
+		out = append(out, finding(InfoTypePhone, start, end, score))
+	}
+	for _, m := range e164Re.FindAllStringIndex(text, -1) {
+		if m[1] < len(text) && isDigit(text[m[1]]) {
+			continue
+		}
+		n := len(digitsOf(text[m[0]:m[1]]))
+		if n < 8 || n > 15 {
+			continue
+		}
+		score := 0.65
+		if hasContext(text, m[0], phoneContext) {
+			score += contextBoost
+		}
+		out = append(out, finding(InfoTypePhone, m[0], m[1], score))
+	}
+	return out
+}
+
+// ─── Keyword-anchored identifiers ───────────────────────────────────────
+
+var (
+	// The identifier is group 1; the keyword must directly precede it.
+	mrnRe = regexp.MustCompile(`(?i)\b(?:mrn|medical\s+record\s+(?:number|no\.?|#)|medical\s+record|med\.?\s+rec\.?\s+(?:no\.?|#)|patient\s+(?:id|number|no\.?|#)|chart\s+(?:number|no\.?|#))\s*(?:is\s+|:\s*|#\s*)?([A-Z]{0,3}-?\d{5,10})\b`)
+
+	insuranceRe = regexp.MustCompile(`(?i)\b(?:member\s+(?:id|number|no\.?|#)|subscriber\s+(?:id|number|no\.?|#)|insurance\s+(?:id|number|no\.?|#)|policy\s+(?:id|number|no\.?|#)|medicaid\s+(?:id|number|no\.?|#)|medicare\s+(?:id|number|no\.?|#)|health\s+plan\s+(?:id|number|no\.?)|mbi)\s*(?:is\s+|:\s*|#\s*)?([A-Z0-9]{1,4}(?:-?[A-Z0-9]){4,16})\b`)
+
+	// Medicare Beneficiary Identifier: 11 characters, no S, L, O, I, B or Z.
+	mbiRe          = regexp.MustCompile(`\b[1-9][AC-HJKMNP-RT-Y][AC-HJKMNP-RT-Y0-9]\d-?[AC-HJKMNP-RT-Y][AC-HJKMNP-RT-Y0-9]\d-?[AC-HJKMNP-RT-Y]{2}\d{2}\b`)
+	mbiContext     = []string{"medicare", "mbi", "beneficiary"}
+	minIdentDigits = 4
+)
+
+func countDigits(s string) int {
+	return len(digitsOf(s))
+}
+
+func findMRNs(text string) []service.Finding {
+	var out []service.Finding
+	for _, m := range mrnRe.FindAllStringSubmatchIndex(text, -1) {
+		out = append(out, finding(InfoTypeMedicalRecord, m[2], m[3], 0.85))
+	}
+	return out
+}
+
+func findInsuranceIDs(text string) []service.Finding {
+	var out []service.Finding
+	for _, m := range insuranceRe.FindAllStringSubmatchIndex(text, -1) {
+		if countDigits(text[m[2]:m[3]]) < minIdentDigits {
+			continue
+		}
+		out = append(out, finding(InfoTypeHealthInsurance, m[2], m[3], 0.8))
+	}
+	for _, m := range mbiRe.FindAllStringIndex(text, -1) {
+		score := 0.6
+		if hasContext(text, m[0], mbiContext) {
+			score += contextBoost
+		}
+		out = append(out, finding(InfoTypeHealthInsurance, m[0], m[1], score))
+	}
+	return out
+}
@@ -0,0 +1,210 @@
+package pii
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func inspect(t *testing.T, d *Detector, text string, infoTypes ...string) []string {
+	t.Helper()
+	findings, err := d.InspectContent(context.Background(), "", text, infoTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.InfoType+":"+f.Content)
+	}
+	return got
+}
+
+func TestDetector_SSNAreaRules(t *testing.T) {
+	d := NewDetector(nil)
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"SSN 536-22-1847", true},
+		{"SSN 000-22-1847", false},
+		{"SSN 666-22-1847", false},
+		{"SSN 936-22-1847", false},
+		{"SSN 536-00-1847", false},
+		{"SSN 536-22-0000", false},
+		{"SSN 078-05-1120", false},
+		{"SSN 123-45-6789", false},
+		{"SSN 536-22 1847", false}, // mixed separators
+		{"SSN: 536221847", true},
+		{"ref 536221847", false}, // bare nine digits need context
+	}
+	for _, tt := range tests {
+		got := inspect(t, d, tt.text, InfoTypeSSN)
+		if (len(got) == 1) != tt.want {
+			t.Errorf("%q: got %v, want found=%v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDetector_CreditCardLuhn(t *testing.T) {
+	d := NewDetector(nil)
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"card 4111111111111111", true},
+		{"card 4111-1111-1111-1111", true},
+		{"card 4111111111111112", false}, // fails Luhn
+		{"card 4111-1111 1111-1111", false},
+		{"card 378282246310005", true},
+		{"card 30569309025904", true},
+		{"card 1234567812345670", false}, // passes Luhn, no issuer
+	}
+	for _, tt := range tests {
+		got := inspect(t, d, tt.text, InfoTypeCreditCard)
+		if (len(got) == 1) != tt.want {
+			t.Errorf("%q: got %v, want found=%v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDetector_Phones(t *testing.T) {
+	d := NewDetector(nil)
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"call (415) 555-0132 today", "(415) 555-0132"},
+		{"call 1-800-555-0199 today", "1-800-555-0199"},
+		{"call +1 212 555 0147 today", "+1 212 555 0147"},
+		{"call +44 20 7946 0958 today", "+44 20 7946 0958"},
+		{"order 123-456-7890", ""}, // area code cannot start with 1
+		{"call 212-911-0147", ""},  // N11 exchange
+		{"call 212-555.0147", ""},  // mixed separators
+		{"id A2125550147", ""},     // part of a longer token
+		{"dial +12 34", ""},        // too short for E.164
+	}
+	for _, tt := range tests {
+		got := inspect(t, d, tt.text, InfoTypePhone)
+		switch {
+		case tt.want == "" && len(got) != 0:
+			t.Errorf("%q: got %v, want none", tt.text, got)
+		case tt.want != "" && (len(got) != 1 || got[0] != InfoTypePhone+":"+tt.want):
+			t.Errorf("%q: got %v, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDetector_ExactOffsets(t *testing.T) {
+	d := NewDetector(nil)
+	text := "Résumé of Maria Lopez — email maria@example.com, SSN 536-22-1847."
+	findings, err := d.InspectContent(context.Background(), "", text, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Maria Lopez", "maria@example.com", "536-22-1847"}
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for i, f := range findings {
+		if f.Content != want[i] {
+			t.Errorf("finding %d: Content = %q, want %q", i, f.Content, want[i])
+		}
+		if text[f.StartIndex:f.EndIndex] != want[i] {
+			t.Errorf("finding %d: offsets [%d,%d) select %q", i, f.StartIndex, f.EndIndex, text[f.StartIndex:f.EndIndex])
+		}
+		if f.Likelihood != Likelihood(f.Score) {
+			t.Errorf("finding %d: Likelihood = %s for score %.2f", i, f.Likelihood, f.Score)
+		}
+	}
+}
+
+func TestDetector_InfoTypeFilter(t *testing.T) {
+	d := NewDetector(nil)
+	text := "Contact maria@example.com or (415) 555-0132."
+	got := inspect(t, d, text, InfoTypeEmail, "UNSUPPORTED_TYPE")
+	if len(got) != 1 || got[0] != InfoTypeEmail+":maria@example.com" {
+		t.Errorf("got %v, want only the email", got)
+	}
+}
+
+func TestDetector_ContextRaisesScore(t *testing.T) {
+	d := NewDetector(nil)
+	score := func(text string) float64 {
+		findings, err := d.InspectContent(context.Background(), "", text, []string{InfoTypeCreditCard})
+		if err != nil || len(findings) != 1 {
+			t.Fatalf("%q: findings %v, err %v", text, findings, err)
+		}
+		return findings[0].Score
+	}
+	if plain, ctx := score("ref 4111111111111111"), score("credit card 4111111111111111"); ctx <= plain {
+		t.Errorf("context score %.2f, want more than %.2f", ctx, plain)
+	}
+}
+
+func TestDetector_MinScore(t *testing.T) {
+	d := NewDetector(nil)
+	text := "ref 536221847"
+	if got := inspect(t, d, text, InfoTypeSSN); len(got) != 0 {
+		t.Fatalf("default min score: got %v, want none", got)
+	}
+	d.SetMinScore(0.2)
+	if got := inspect(t, d, text, InfoTypeSSN); len(got) != 1 {
+		t.Errorf("min score 0.2: got %v, want the bare SSN", got)
+	}
+}
+
+func TestResolveOverlaps(t *testing.T) {
+	d := NewDetector(nil)
+	// A valid ITIN also matches the SSN pattern shape; only the ITIN is kept.
+	got := inspect(t, d, "ITIN 912-78-3456")
+	if len(got) != 1 || got[0] != InfoTypeITIN+":912-78-3456" {
+		t.Errorf("got %v, want only the ITIN", got)
+	}
+}
+
+func TestLikelihood(t *testing.T) {
+	tests := []struct {
+		score float64
+		want  string
+	}{
+		{0.99, "VERY_LIKELY"},
+		{0.8, "VERY_LIKELY"},
+		{0.65, "LIKELY"},
+		{0.4, "POSSIBLE"},
+		{0.3, "UNLIKELY"},
+		{0.1, "VERY_UNLIKELY"},
+	}
+	for _, tt := range tests {
+		if got := Likelihood(tt.score); got != tt.want {
+			t.Errorf("Likelihood(%.2f) = %s, want %s", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestParseNameList(t *testing.T) {
+	l, err := ParseNameList(strings.NewReader("# staff\nfirst Ngozi\n\nlast Okonjo-Iweala\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.IsFirstName("NGOZI") || !l.IsLastName("okonjo-iweala") || l.IsFirstName("Okonjo-Iweala") {
+		t.Errorf("unexpected lookups for parsed list %+v", l)
+	}
+
+	for _, bad := range []string{"Ngozi\n", "middle Ngozi\n", "first \n"} {
+		if _, err := ParseNameList(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseNameList(%q): want error", bad)
+		}
+	}
+}
+
+func TestDetector_CustomGazetteer(t *testing.T) {
+	text := "Signed by Ngozi Okonjo-Iweala on behalf of the board."
+	if got := inspect(t, NewDetector(nil), text, InfoTypePersonName); len(got) != 0 {
+		t.Fatalf("default gazetteer: got %v, want none", got)
+	}
+	d := NewDetector(NewNameList([]string{"Ngozi"}, []string{"Okonjo-Iweala"}))
+	got := inspect(t, d, text, InfoTypePersonName)
+	if len(got) != 1 || got[0] != InfoTypePersonName+":Ngozi Okonjo-Iweala" {
+		t.Errorf("custom gazetteer: got %v", got)
+	}
+}
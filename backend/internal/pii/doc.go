@@ -0,0 +1,14 @@
+// Package pii is an offline PII/PHI detector implementing service.DLPClient,
+// so the ingest pipeline can scan documents without sending their text to a
+// cloud DLP service.
+//
+// Each info type has its own rule: validated patterns for identifiers with
+// structure (SSN area numbers, Luhn-checked card numbers with issuer
+// prefixes, ITIN group ranges, NANP and E.164 phones, Medicare MBIs),
+// keyword-anchored patterns for identifiers without one (medical record and
+// insurance member numbers) and a pluggable gazetteer for person names.
+// Nearby context keywords raise a finding's score; findings below the
+// detector's minimum score are dropped, and overlapping findings keep the
+// highest-scoring one. Offsets are byte offsets into the scanned text, as
+// service.RedactorService.Redact expects.
+package pii
@@ -0,0 +1,207 @@
+package pii
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Gazetteer tells the name rule which words are given names and surnames.
+// Lookups receive the word as written (e.g. "O'Brien", "Garcia").
+type Gazetteer interface {
+	IsFirstName(word string) bool
+	IsLastName(word string) bool
+}
+
+// NameList is an in-memory Gazetteer. Lookups are case-insensitive.
+type NameList struct {
+	first map[string]bool
+	last  map[string]bool
+}
+
+// NewNameList creates a NameList from given names and surnames.
+func NewNameList(first, last []string) *NameList {
+	l := &NameList{first: make(map[string]bool, len(first)), last: make(map[string]bool, len(last))}
+	for _, n := range first {
+		l.first[strings.ToLower(n)] = true
+	}
+	for _, n := range last {
+		l.last[strings.ToLower(n)] = true
+	}
+	return l
+}
+
+// ParseNameList reads a gazetteer file: one "first <name>" or "last <name>"
+// entry per line; blank lines and lines starting with # are ignored.
+func ParseNameList(r io.Reader) (*NameList, error) {
+	var first, last []string
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		kind, name, ok := strings.Cut(text, " ")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("pii.ParseNameList: line %d: want \"first <name>\" or \"last <name>\"", line)
+		}
+		switch strings.ToLower(kind) {
+		case "first":
+			first = append(first, name)
+		case "last":
+			last = append(last, name)
+		default:
+			return nil, fmt.Errorf("pii.ParseNameList: line %d: unknown kind %q", line, kind)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("pii.ParseNameList: %w", err)
+	}
+	return NewNameList(first, last), nil
+}
+
+// IsFirstName implements Gazetteer.
+func (l *NameList) IsFirstName(word string) bool { return l.first[strings.ToLower(word)] }
+
+// IsLastName implements Gazetteer.
+func (l *NameList) IsLastName(word string) bool { return l.last[strings.ToLower(word)] }
+
+// DefaultGazetteer returns the built-in list of common US given names and
+// surnames (see names.go).
+func DefaultGazetteer() *NameList {
+	return NewNameList(defaultFirstNames, defaultLastNames)
+}
+
+var (
+	// nameTokenRe matches capitalised words and middle initials.
+	nameTokenRe = regexp.MustCompile(`\b(?:[A-Z][a-z]+(?:['-][A-Z]?[a-z]+)*|[A-Z]\.)`)
+
+	honorifics = map[string]bool{
+		"mr": true, "mrs": true, "ms": true, "miss": true, "dr": true, "prof": true,
+		"judge": true, "justice": true, "hon": true, "attorney": true, "nurse": true,
+	}
+
+	// notNames are capitalised words that end or break a name, mostly from
+	// legal and medical documents.
+	notNames = map[string]bool{
+		"the": true, "a": true, "an": true, "this": true, "that": true, "and": true, "or": true, "of": true,
+		"in": true, "on": true, "at": true, "by": true, "for": true, "to": true, "from": true, "with": true,
+		"court": true, "county": true, "state": true, "states": true, "united": true, "district": true,
+		"city": true, "street": true, "avenue": true, "road": true, "suite": true, "inc": true, "llc": true,
+		"llp": true, "corp": true, "company": true, "bank": true, "hospital": true, "clinic": true,
+		"center": true, "university": true, "college": true, "school": true, "department": true,
+		"plaintiff": true, "defendant": true, "patient": true, "client": true, "exhibit": true,
+		"section": true, "article": true, "agreement": true, "contract": true, "policy": true,
+		"january": true, "february": true, "march": true, "april": true, "june": true, "july": true,
+		"august": true, "september": true, "october": true, "november": true, "december": true,
+		"monday": true, "tuesday": true, "wednesday": true, "thursday": true, "friday": true,
+		"saturday": true, "sunday": true, "beach": true, "park": true, "valley": true, "river": true,
+		"north": true, "south": true, "east": true, "west": true, "new": true, "york": true,
+		"dear": true, "re": true, "mr": true, "mrs": true, "ms": true, "dr": true,
+	}
+
+	nameContext = []string{"patient", "plaintiff", "defendant", "client", "name", "witness",
+		"signed", "employee", "tenant", "borrower", "insured", "member", "contact", "dear", "attn"}
+)
+
+type nameToken struct {
+	start, end int
+	word       string // without a trailing "."
+	initial    bool
+}
+
+// findNames scores runs of capitalised words. A run counts as a name when
+// it starts with a known given name, follows an honorific, or both, and is
+// stronger when it ends in a known surname.
+func (d *Detector) findNames(text string) []service.Finding {
+	var out []service.Finding
+	for _, run := range nameRuns(text) {
+		// Start at the first honorific or given name ("Dear Maria Lopez").
+		start := -1
+		for i, t := range run {
+			if honorifics[strings.ToLower(t.word)] || d.names.IsFirstName(t.word) {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			continue
+		}
+		run = run[start:]
+		honorific := false
+		if honorifics[strings.ToLower(run[0].word)] {
+			honorific = true
+			run = run[1:]
+		}
+		// Stop at words that are never part of a name.
+		for i, t := range run {
+			if notNames[strings.ToLower(t.word)] {
+				run = run[:i]
+				break
+			}
+		}
+		for len(run) > 0 && run[len(run)-1].initial {
+			run = run[:len(run)-1]
+		}
+		if len(run) == 0 || run[0].initial || len(run) > 4 {
+			continue
+		}
+
+		first, last := run[0], run[len(run)-1]
+		knownFirst := d.names.IsFirstName(first.word)
+		knownLast := len(run) > 1 && d.names.IsLastName(last.word)
+
+		var score float64
+		switch {
+		case len(run) > 1 && knownFirst && knownLast:
+			score = 0.85
+		case honorific && (knownLast || d.names.IsLastName(first.word)):
+			score = 0.75
+		case len(run) > 1 && knownFirst:
+			score = 0.5
+		case honorific:
+			score = 0.55
+		default:
+			continue
+		}
+		if hasContext(text, first.start, nameContext) {
+			score += 0.1
+		}
+		out = append(out, finding(InfoTypePersonName, first.start, last.end, score))
+	}
+	return out
+}
+
+// nameRuns groups name tokens separated only by single spaces, or by ". "
+// after an honorific ("Dr. Jane Smith").
+func nameRuns(text string) [][]nameToken {
+	var runs [][]nameToken
+	var cur []nameToken
+	for _, m := range nameTokenRe.FindAllStringIndex(text, -1) {
+		t := nameToken{start: m[0], end: m[1], word: text[m[0]:m[1]]}
+		if strings.HasSuffix(t.word, ".") {
+			t.initial = true
+			t.word = strings.TrimSuffix(t.word, ".")
+		}
+		if len(cur) > 0 {
+			prev := cur[len(cur)-1]
+			gap := text[prev.end:t.start]
+			joined := gap == " " ||
+				(gap == ". " && len(cur) == 1 && honorifics[strings.ToLower(prev.word)])
+			if !joined {
+				runs = append(runs, cur)
+				cur = nil
+			}
+		}
+		cur = append(cur, t)
+	}
+	if len(cur) > 0 {
+		runs = append(runs, cur)
+	}
+	return runs
+}
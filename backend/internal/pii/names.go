@@ -0,0 +1,50 @@
+package pii
+
+// defaultFirstNames are common US given names. Names that are also common
+// capitalised English words (Will, May, Mark, Grant, Rose...) are left out:
+// they would turn sentence openings into false positives, and an honorific
+// or a known surname still catches them.
+var defaultFirstNames = []string{
+	"aaron", "abigail", "adam", "adrian", "aisha", "alan", "albert", "alejandro", "alex", "alexander",
+	"alexandra", "alexis", "alice", "alicia", "alison", "allison", "amanda", "amber", "amy", "ana",
+	"andrea", "andrew", "angela", "anna", "anne", "anthony", "antonio", "arthur", "ashley", "barbara",
+	"benjamin", "beth", "betty", "beverly", "brandon", "brenda", "brian", "brittany", "bruce", "bryan",
+	"carl", "carlos", "carmen", "carol", "caroline", "catherine", "charles", "charlotte", "chen", "cheryl",
+	"christian", "christina", "christine", "christopher", "cynthia", "daniel", "danielle", "david", "deborah", "debra",
+	"denise", "dennis", "diana", "diane", "donald", "donna", "dorothy", "douglas", "dylan", "edward",
+	"elena", "elizabeth", "emily", "emma", "eric", "ethan", "eugene", "evelyn", "fatima", "frances",
+	"francisco", "frank", "gabriel", "gary", "george", "gerald", "gloria", "gregory", "hannah", "harold",
+	"heather", "helen", "henry", "hiroshi", "isabella", "jack", "jacob", "jacqueline", "james", "jamal",
+	"jane", "janet", "janice", "jason", "javier", "jeffrey", "jennifer", "jeremy", "jerry", "jesse",
+	"jessica", "joan", "joe", "john", "jonathan", "jorge", "jose", "joseph", "joshua", "juan",
+	"judith", "julia", "julie", "justin", "karen", "katherine", "kathleen", "kathryn", "keith", "kelly",
+	"kenneth", "kevin", "kimberly", "kyle", "larry", "laura", "lauren", "lawrence", "linda", "lisa",
+	"logan", "louis", "luis", "madison", "manuel", "margaret", "maria", "marie", "marilyn", "martha",
+	"mary", "matthew", "megan", "melissa", "michael", "michelle", "miguel", "mohammed", "nancy", "natalie",
+	"nathan", "nicholas", "nicole", "noah", "olivia", "pamela", "patricia", "patrick", "paul", "peter",
+	"philip", "priya", "rachel", "rahul", "ralph", "randy", "raymond", "rebecca", "richard", "robert",
+	"roger", "ronald", "russell", "ruth", "ryan", "samantha", "samuel", "sandra", "sara", "sarah",
+	"scott", "sean", "sharon", "shirley", "sofia", "sophia", "stephanie", "stephen", "steven", "susan",
+	"teresa", "terry", "thomas", "timothy", "tyler", "victoria", "vincent", "virginia", "walter", "wei",
+	"william", "zachary",
+}
+
+// defaultLastNames are common US surnames. Surnames that are also common
+// words (Young, King, Hill, Green, Wood...) are included because they only
+// count after a given name or an honorific.
+var defaultLastNames = []string{
+	"adams", "ahmed", "alexander", "allen", "alvarez", "anderson", "bailey", "baker", "barnes", "bell",
+	"bennett", "brooks", "brown", "bryant", "butler", "campbell", "carter", "castillo", "chavez", "chen",
+	"clark", "coleman", "collins", "cook", "cooper", "cox", "cruz", "davis", "diaz", "edwards",
+	"evans", "fisher", "flores", "foster", "garcia", "gomez", "gonzales", "gonzalez", "gray", "green",
+	"griffin", "gupta", "gutierrez", "hall", "harris", "hayes", "henderson", "hernandez", "hill", "howard",
+	"hughes", "jackson", "james", "jenkins", "jimenez", "johnson", "jones", "kelly", "khan", "kim",
+	"king", "kumar", "lee", "lewis", "li", "long", "lopez", "martin", "martinez", "mendoza",
+	"miller", "mitchell", "moore", "morales", "morgan", "morris", "murphy", "myers", "nelson", "nguyen",
+	"o'brien", "ortiz", "parker", "patel", "perez", "perry", "peterson", "phillips", "powell", "price",
+	"ramirez", "ramos", "reed", "reyes", "richardson", "rivera", "roberts", "robinson", "rodriguez", "rogers",
+	"ross", "russell", "sanchez", "sanders", "scott", "shah", "simmons", "singh", "smith", "stewart",
+	"sullivan", "tanaka", "taylor", "thomas", "thompson", "torres", "turner", "wagner", "walker", "wang",
+	"ward", "washington", "watson", "white", "williams", "wilson", "wood", "wright", "wu", "yang",
+	"young", "zhang",
+}
@@ -0,0 +1,73 @@
+package rbac
+
+import "github.com/connexus-ai/ragbox-backend/internal/model"
+
+// Permission is an action a firm role may take through the API. Routes
+// declare the permission they need; roles are granted sets of them.
+type Permission string
+
+const (
+	// PermDocumentsRead lists and reads documents, folders, vaults and
+	// their derived data (related documents, content gaps, insights).
+	PermDocumentsRead Permission = "documents:read"
+	// PermDocumentsWrite uploads, ingests, edits and deletes documents and folders.
+	PermDocumentsWrite Permission = "documents:write"
+	// PermDocumentContent sees document text and storage locations. It is
+	// not a route permission: handlers that return document metadata to
+	// other roles (e.g. export) strip content without it.
+	PermDocumentContent Permission = "documents:content"
+	// PermQuery runs retrieval and generation: chat, forge, insight scans.
+	PermQuery Permission = "query"
+	// PermVaultsManage creates, edits and archives vaults.
+	PermVaultsManage Permission = "vaults:manage"
+	// PermShare manages share grants and share groups.
+	PermShare Permission = "share:manage"
+	// PermLegalHold places, releases and reports on legal holds.
+	PermLegalHold Permission = "legal_hold:manage"
+	// PermRetention sets retention policies and previews the purge job.
+	PermRetention Permission = "retention:manage"
+	// PermAuditRead reads and exports the audit trail.
+	PermAuditRead Permission = "audit:read"
+	// PermExport downloads the data export.
+	PermExport Permission = "export"
+	// PermPrivilegeToggle turns Privileged Mode on and off.
+	PermPrivilegeToggle Permission = "privilege:toggle"
+	// PermAccount manages the caller's own account: organization
+	// membership, API tokens, usage and voice settings.
+	PermAccount Permission = "account"
+)
+
+// AllPermissions lists every permission, for exhaustive checks.
+var AllPermissions = []Permission{
+	PermDocumentsRead, PermDocumentsWrite, PermDocumentContent, PermQuery,
+	PermVaultsManage, PermShare, PermLegalHold, PermRetention, PermAuditRead,
+	PermExport, PermPrivilegeToggle, PermAccount,
+}
+
+// RolePermissions maps firm roles to their permissions. System roles (see
+// IsSystemRole) have every permission.
+var RolePermissions = map[string][]Permission{
+	string(model.UserRolePartner): AllPermissions,
+	string(model.UserRoleAssociate): {
+		PermDocumentsRead, PermDocumentsWrite, PermDocumentContent, PermQuery,
+		PermVaultsManage, PermShare, PermAuditRead, PermExport, PermAccount,
+	},
+	// Auditors review the audit trail and export metadata, but never see
+	// document content.
+	string(model.UserRoleAuditor): {
+		PermAuditRead, PermExport, PermAccount,
+	},
+}
+
+// Can reports whether role has perm.
+func Can(role string, perm Permission) bool {
+	if IsSystemRole(role) {
+		return true
+	}
+	for _, p := range RolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package rbac
+
+import "testing"
+
+func TestCan_RolePermissionMatrix(t *testing.T) {
+	all := AllPermissions
+	want := map[string][]Permission{
+		"Partner": all,
+		"Associate": {
+			PermDocumentsRead, PermDocumentsWrite, PermDocumentContent, PermQuery,
+			PermVaultsManage, PermShare, PermAuditRead, PermExport, PermAccount,
+		},
+		"Auditor": {PermAuditRead, PermExport, PermAccount},
+		"admin":   all,
+		"system":  all,
+		"user":    nil,
+		"":        nil,
+	}
+
+	for role, granted := range want {
+		allowed := map[Permission]bool{}
+		for _, p := range granted {
+			allowed[p] = true
+		}
+		for _, perm := range all {
+			if got := Can(role, perm); got != allowed[perm] {
+				t.Errorf("Can(%q, %q) = %v, want %v", role, perm, got, allowed[perm])
+			}
+		}
+	}
+}
+
+func TestCan_AuditorNeverSeesDocumentContent(t *testing.T) {
+	for _, perm := range []Permission{PermDocumentsRead, PermDocumentContent, PermQuery} {
+		if Can("Auditor", perm) {
+			t.Errorf("Auditor has %q", perm)
+		}
+	}
+}
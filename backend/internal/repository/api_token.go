@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// APITokenRepo implements service.APITokenRepository with pgx.
+type APITokenRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewAPITokenRepo creates an APITokenRepo.
+func NewAPITokenRepo(pool *pgxpool.Pool) *APITokenRepo {
+	return &APITokenRepo{pool: pool}
+}
+
+// Compile-time check.
+var _ service.APITokenRepository = (*APITokenRepo)(nil)
+
+const apiTokenColumns = `id, user_id, name, prefix, scopes, expires_at, last_used_at,
+	request_count, revoked_at, created_at`
+
+func (r *APITokenRepo) Create(ctx context.Context, t *model.APIToken, tokenHash string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO api_tokens (id, user_id, name, prefix, token_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, t.ID, t.UserID, t.Name, t.Prefix, tokenHash, scopeStrings(t.Scopes), t.ExpiresAt, t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("repository.APITokenCreate: %w", err)
+	}
+	return nil
+}
+
+func (r *APITokenRepo) ListByUser(ctx context.Context, userID string) ([]model.APIToken, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+apiTokenColumns+` FROM api_tokens WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.APITokenListByUser: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []model.APIToken
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.APITokenListByUser: scan: %w", err)
+		}
+		tokens = append(tokens, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.APITokenListByUser: %w", err)
+	}
+	return tokens, nil
+}
+
+func (r *APITokenRepo) GetByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	t, err := scanAPIToken(r.pool.QueryRow(ctx,
+		`SELECT `+apiTokenColumns+` FROM api_tokens WHERE token_hash = $1`, tokenHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository.APITokenGetByHash: %w", err)
+	}
+	return t, nil
+}
+
+func (r *APITokenRepo) Revoke(ctx context.Context, userID, id string, at time.Time) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE api_tokens SET revoked_at = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, id, userID, at)
+	if err != nil {
+		return fmt.Errorf("repository.APITokenRevoke: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return service.ErrAPITokenNotFound
+	}
+	return nil
+}
+
+func (r *APITokenRepo) CountActive(ctx context.Context, userID string, now time.Time) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM api_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+	`, userID, now).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("repository.APITokenCountActive: %w", err)
+	}
+	return n, nil
+}
+
+func (r *APITokenRepo) RecordUse(ctx context.Context, id string, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE api_tokens SET last_used_at = GREATEST(last_used_at, $2), request_count = request_count + 1
+		WHERE id = $1
+	`, id, at)
+	if err != nil {
+		return fmt.Errorf("repository.APITokenRecordUse: %w", err)
+	}
+	return nil
+}
+
+func scanAPIToken(row pgx.Row) (*model.APIToken, error) {
+	var t model.APIToken
+	var scopes []string
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &scopes, &t.ExpiresAt, &t.LastUsedAt,
+		&t.RequestCount, &t.RevokedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.Scopes = make([]model.APITokenScope, len(scopes))
+	for i, s := range scopes {
+		t.Scopes[i] = model.APITokenScope(s)
+	}
+	return &t, nil
+}
+
+func scopeStrings(scopes []model.APITokenScope) []string {
+	out := make([]string, len(scopes))
+	for i, s := range scopes {
+		out[i] = string(s)
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func TestAPITokenRepo_Lifecycle(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewAPITokenRepo(docRepo.pool)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	tok := &model.APIToken{
+		ID:        uuid.New().String(),
+		UserID:    "test-user-doc",
+		Name:      "ci",
+		Prefix:    "rbx_pat_abcd",
+		Scopes:    []model.APITokenScope{model.APIScopeRead, model.APIScopeQuery},
+		CreatedAt: now,
+	}
+	hash := "hash-" + tok.ID
+	if err := repo.Create(ctx, tok, hash); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := repo.GetByHash(ctx, hash)
+	if err != nil || got == nil {
+		t.Fatalf("GetByHash = %v, %v", got, err)
+	}
+	if !got.HasScope(model.APIScopeQuery) || got.HasScope(model.APIScopeUpload) {
+		t.Errorf("scopes = %v, want read+query", got.Scopes)
+	}
+	if missing, _ := repo.GetByHash(ctx, "no-such-hash"); missing != nil {
+		t.Error("GetByHash returned a token for an unknown hash")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := repo.RecordUse(ctx, tok.ID, now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("RecordUse: %v", err)
+		}
+	}
+	got, _ = repo.GetByHash(ctx, hash)
+	if got.RequestCount != 2 || got.LastUsedAt == nil || !got.LastUsedAt.Equal(now.Add(time.Second)) {
+		t.Errorf("after use: count = %d, lastUsed = %v", got.RequestCount, got.LastUsedAt)
+	}
+
+	before, err := repo.CountActive(ctx, tok.UserID, now)
+	if err != nil {
+		t.Fatalf("CountActive: %v", err)
+	}
+	if err := repo.Revoke(ctx, "someone-else", tok.ID, now); !errors.Is(err, service.ErrAPITokenNotFound) {
+		t.Errorf("Revoke by another user: err = %v, want ErrAPITokenNotFound", err)
+	}
+	if err := repo.Revoke(ctx, tok.UserID, tok.ID, now); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	after, _ := repo.CountActive(ctx, tok.UserID, now)
+	if after != before-1 {
+		t.Errorf("CountActive after revoke = %d, want %d", after, before-1)
+	}
+	got, _ = repo.GetByHash(ctx, hash)
+	if got.Active(now) {
+		t.Error("revoked token still active")
+	}
+}
@@ -4,14 +4,17 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
 
 // AuditRepo provides database operations for audit logs.
 type AuditRepo struct {
-	pool *pgxpool.Pool
+	pool        *pgxpool.Pool
+	outboxSinks []string // sinks every appended entry is queued for
 }
 
 // NewAuditRepo creates an AuditRepo.
@@ -19,23 +22,106 @@ func NewAuditRepo(pool *pgxpool.Pool) *AuditRepo {
 	return &AuditRepo{pool: pool}
 }
 
-// Create inserts a new audit log entry.
-func (r *AuditRepo) Create(ctx context.Context, entry *model.AuditLog) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO audit_logs (id, user_id, action, resource_id, resource_type, severity, details, details_hash, ip_address, user_agent, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
-		entry.ID, entry.UserID, entry.Action, entry.ResourceID, entry.ResourceType,
-		entry.Severity, entry.Details, entry.DetailsHash,
-		entry.IPAddress, entry.UserAgent, entry.CreatedAt,
-	)
+// WithOutbox returns a copy of r that queues every entry it appends for
+// delivery to sinks, in the appending transaction.
+func (r *AuditRepo) WithOutbox(sinks []string) *AuditRepo {
+	return &AuditRepo{pool: r.pool, outboxSinks: append([]string(nil), sinks...)}
+}
+
+// Compile-time check.
+var _ service.AuditRepository = (*AuditRepo)(nil)
+
+const auditColumns = `id, tenant_id, seq, prev_hash, user_id, action, resource_id, resource_type,
+	severity, details, details_hash, ip_address, user_agent, created_at`
+
+func scanAuditLog(row pgx.Row) (*model.AuditLog, error) {
+	var e model.AuditLog
+	var tenantID *string
+	var seq *int64
+	err := row.Scan(&e.ID, &tenantID, &seq, &e.PrevHash, &e.UserID, &e.Action, &e.ResourceID, &e.ResourceType,
+		&e.Severity, &e.Details, &e.DetailsHash, &e.IPAddress, &e.UserAgent, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if tenantID != nil {
+		e.TenantID = *tenantID
+	}
+	if seq != nil {
+		e.Seq = *seq
+	}
+	return &e, nil
+}
+
+// Append adds entry to its tenant's hash chain in one transaction. The
+// tenant's chain head row is locked for the duration, so appends from any
+// number of instances are serialised per tenant; the hash is computed by
+// audit_entry_hash() on the stored row.
+func (r *AuditRepo) Append(ctx context.Context, entry *model.AuditLog) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.AuditAppend: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	entry.TenantID = model.AuditSystemTenant
+	if entry.UserID != nil {
+		if err := tx.QueryRow(ctx, `SELECT user_org_id($1)`, *entry.UserID).Scan(&entry.TenantID); err != nil {
+			return fmt.Errorf("repository.AuditAppend: tenant: %w", err)
+		}
+	}
+
+	// The upsert creates the head on a tenant's first entry and, either
+	// way, row-locks it until commit.
+	var head int64
+	var prevHash string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO audit_chain_heads (tenant_id) VALUES ($1)
+		ON CONFLICT (tenant_id) DO UPDATE SET tenant_id = EXCLUDED.tenant_id
+		RETURNING seq, hash`, entry.TenantID).Scan(&head, &prevHash)
+	if err != nil {
+		return fmt.Errorf("repository.AuditAppend: lock head: %w", err)
+	}
+	entry.Seq = head + 1
+	entry.PrevHash = &prevHash
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO audit_logs (id, tenant_id, seq, prev_hash, user_id, action, resource_id, resource_type,
+			severity, details, details_hash, ip_address, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+			audit_entry_hash($4, $1, $2, $3, $5, $6, $7, $8, $9, $11, $12, $13, $10), $11, $12, $13)
+		RETURNING details, details_hash`,
+		entry.ID, entry.TenantID, entry.Seq, prevHash, entry.UserID, entry.Action, entry.ResourceID,
+		entry.ResourceType, entry.Severity, entry.Details, entry.IPAddress, entry.UserAgent, entry.CreatedAt,
+	).Scan(&entry.Details, &entry.DetailsHash)
 	if err != nil {
-		return fmt.Errorf("repository.AuditCreate: %w", err)
+		return fmt.Errorf("repository.AuditAppend: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE audit_chain_heads SET seq = $2, hash = $3, updated_at = NOW()
+		WHERE tenant_id = $1`, entry.TenantID, entry.Seq, *entry.DetailsHash)
+	if err != nil {
+		return fmt.Errorf("repository.AuditAppend: advance head: %w", err)
+	}
+
+	if len(r.outboxSinks) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO audit_outbox (entry_id, sink)
+			SELECT $1, unnest($2::text[])`, entry.ID, r.outboxSinks)
+		if err != nil {
+			return fmt.Errorf("repository.AuditAppend: outbox: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.AuditAppend: commit: %w", err)
 	}
 	return nil
 }
 
 // ListFilter defines filters for listing audit logs.
 type ListFilter struct {
+	TenantID   string // Audit chain tenant (user_org_id of its members)
 	UserID     string
 	Action     string
 	Severity   string
@@ -52,11 +138,18 @@ func (r *AuditRepo) List(ctx context.Context, f ListFilter) ([]model.AuditLog, i
 		f.Limit = 50
 	}
 
-	query := `SELECT id, user_id, action, resource_id, resource_type, severity, details, details_hash, ip_address, user_agent, created_at FROM audit_logs WHERE 1=1`
+	query := `SELECT ` + auditColumns + ` FROM audit_logs WHERE 1=1`
 	countQuery := `SELECT count(*) FROM audit_logs WHERE 1=1`
 	var args []interface{}
 	argIdx := 1
 
+	if f.TenantID != "" {
+		clause := fmt.Sprintf(` AND tenant_id = $%d`, argIdx)
+		query += clause
+		countQuery += clause
+		args = append(args, f.TenantID)
+		argIdx++
+	}
 	if f.UserID != "" {
 		clause := fmt.Sprintf(` AND user_id = $%d`, argIdx)
 		query += clause
@@ -107,7 +200,7 @@ func (r *AuditRepo) List(ctx context.Context, f ListFilter) ([]model.AuditLog, i
 		return nil, 0, fmt.Errorf("repository.AuditList count: %w", err)
 	}
 
-	query += ` ORDER BY created_at DESC`
+	query += ` ORDER BY created_at DESC, seq DESC`
 	query += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, argIdx, argIdx+1)
 	args = append(args, f.Limit, f.Offset)
 
@@ -119,56 +212,12 @@ func (r *AuditRepo) List(ctx context.Context, f ListFilter) ([]model.AuditLog, i
 
 	var entries []model.AuditLog
 	for rows.Next() {
-		var e model.AuditLog
-		err := rows.Scan(&e.ID, &e.UserID, &e.Action, &e.ResourceID, &e.ResourceType,
-			&e.Severity, &e.Details, &e.DetailsHash, &e.IPAddress, &e.UserAgent, &e.CreatedAt)
+		e, err := scanAuditLog(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("repository.AuditList scan: %w", err)
 		}
-		entries = append(entries, e)
+		entries = append(entries, *e)
 	}
 
 	return entries, total, nil
 }
-
-// GetRange returns audit entries between two IDs (inclusive) ordered by created_at.
-func (r *AuditRepo) GetRange(ctx context.Context, startID, endID string) ([]model.AuditLog, error) {
-	rows, err := r.pool.Query(ctx, `
-		SELECT id, user_id, action, resource_id, resource_type, severity, details, details_hash, ip_address, user_agent, created_at
-		FROM audit_logs
-		WHERE created_at >= (SELECT created_at FROM audit_logs WHERE id = $1)
-		  AND created_at <= (SELECT created_at FROM audit_logs WHERE id = $2)
-		ORDER BY created_at ASC`,
-		startID, endID)
-	if err != nil {
-		return nil, fmt.Errorf("repository.AuditGetRange: %w", err)
-	}
-	defer rows.Close()
-
-	var entries []model.AuditLog
-	for rows.Next() {
-		var e model.AuditLog
-		err := rows.Scan(&e.ID, &e.UserID, &e.Action, &e.ResourceID, &e.ResourceType,
-			&e.Severity, &e.Details, &e.DetailsHash, &e.IPAddress, &e.UserAgent, &e.CreatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("repository.AuditGetRange scan: %w", err)
-		}
-		entries = append(entries, e)
-	}
-
-	return entries, nil
-}
-
-// GetLatestHash returns the details_hash of the most recent audit entry.
-func (r *AuditRepo) GetLatestHash(ctx context.Context) (string, error) {
-	var hash *string
-	err := r.pool.QueryRow(ctx, `SELECT details_hash FROM audit_logs ORDER BY created_at DESC LIMIT 1`).Scan(&hash)
-	if err != nil {
-		// No entries yet — return empty string (genesis)
-		return "", nil
-	}
-	if hash == nil {
-		return "", nil
-	}
-	return *hash, nil
-}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Compile-time check.
+var _ service.AuditCheckpointRepository = (*AuditRepo)(nil)
+
+const auditCheckpointColumns = `id, tenant_id, from_seq, to_seq, root_hash, signature, key_id, created_at`
+
+// PendingCheckpoints returns the next checkpoint range of every tenant
+// whose chain head is past its last checkpoint.
+func (r *AuditRepo) PendingCheckpoints(ctx context.Context, maxEntries int64) ([]model.AuditCheckpoint, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT h.tenant_id, COALESCE(c.to_seq, 0) + 1, LEAST(h.seq, COALESCE(c.to_seq, 0) + $1)
+		FROM audit_chain_heads h
+		LEFT JOIN (
+			SELECT tenant_id, MAX(to_seq) AS to_seq FROM audit_checkpoints GROUP BY tenant_id
+		) c ON c.tenant_id = h.tenant_id
+		WHERE h.seq > COALESCE(c.to_seq, 0)
+		ORDER BY h.tenant_id`, maxEntries)
+	if err != nil {
+		return nil, fmt.Errorf("repository.PendingCheckpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []model.AuditCheckpoint
+	for rows.Next() {
+		var cp model.AuditCheckpoint
+		if err := rows.Scan(&cp.TenantID, &cp.FromSeq, &cp.ToSeq); err != nil {
+			return nil, fmt.Errorf("repository.PendingCheckpoints scan: %w", err)
+		}
+		pending = append(pending, cp)
+	}
+	return pending, rows.Err()
+}
+
+// LeafHashes returns the details hashes of a tenant's entries with seq
+// fromSeq..toSeq, in seq order.
+func (r *AuditRepo) LeafHashes(ctx context.Context, tenantID string, fromSeq, toSeq int64) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT details_hash FROM audit_logs
+		WHERE tenant_id = $1 AND seq BETWEEN $2 AND $3
+		ORDER BY seq ASC`, tenantID, fromSeq, toSeq)
+	if err != nil {
+		return nil, fmt.Errorf("repository.LeafHashes: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			return nil, fmt.Errorf("repository.LeafHashes scan: %w", err)
+		}
+		hashes = append(hashes, h)
+	}
+	return hashes, rows.Err()
+}
+
+// CreateCheckpoint stores cp unless its tenant already has a checkpoint
+// starting at cp.FromSeq, and reports whether it was stored.
+func (r *AuditRepo) CreateCheckpoint(ctx context.Context, cp *model.AuditCheckpoint) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO audit_checkpoints (`+auditCheckpointColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, from_seq) DO NOTHING`,
+		cp.ID, cp.TenantID, cp.FromSeq, cp.ToSeq, cp.RootHash, cp.Signature, cp.KeyID, cp.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("repository.CreateCheckpoint: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Checkpoints returns a tenant's checkpoints overlapping seq
+// fromSeq..toSeq, in seq order.
+func (r *AuditRepo) Checkpoints(ctx context.Context, tenantID string, fromSeq, toSeq int64) ([]model.AuditCheckpoint, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+auditCheckpointColumns+` FROM audit_checkpoints
+		WHERE tenant_id = $1 AND to_seq >= $2 AND from_seq <= $3
+		ORDER BY from_seq ASC`, tenantID, fromSeq, toSeq)
+	if err != nil {
+		return nil, fmt.Errorf("repository.Checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []model.AuditCheckpoint
+	for rows.Next() {
+		var cp model.AuditCheckpoint
+		err := rows.Scan(&cp.ID, &cp.TenantID, &cp.FromSeq, &cp.ToSeq, &cp.RootHash, &cp.Signature, &cp.KeyID, &cp.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("repository.Checkpoints scan: %w", err)
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, rows.Err()
+}
+
+// GetEntry returns an audit entry by ID, or nil if there is none.
+func (r *AuditRepo) GetEntry(ctx context.Context, id string) (*model.AuditLog, error) {
+	e, err := scanAuditLog(r.pool.QueryRow(ctx, `SELECT `+auditColumns+` FROM audit_logs WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository.AuditGetEntry: %w", err)
+	}
+	return e, nil
+}
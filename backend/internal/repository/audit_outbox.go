@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Compile-time check.
+var _ service.AuditOutboxRepository = (*AuditRepo)(nil)
+
+// ClaimOutbox returns up to limit of sink's due outbox items with their
+// entries, oldest first. Claimed rows are skipped by concurrent claims and
+// become due again after lease unless acked or retried first.
+func (r *AuditRepo) ClaimOutbox(ctx context.Context, sink string, limit int, lease time.Duration) ([]model.AuditOutboxItem, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM audit_outbox
+			WHERE sink = $1 AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE audit_outbox o SET next_attempt_at = NOW() + make_interval(secs => $3)
+		FROM due WHERE o.id = due.id
+		RETURNING o.id, o.attempts, o.entry_id`, sink, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("repository.AuditClaimOutbox: %w", err)
+	}
+	var items []model.AuditOutboxItem
+	var entryIDs []string
+	for rows.Next() {
+		var it model.AuditOutboxItem
+		if err := rows.Scan(&it.ID, &it.Attempts, &it.Entry.ID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("repository.AuditClaimOutbox scan: %w", err)
+		}
+		it.Sink = sink
+		items = append(items, it)
+		entryIDs = append(entryIDs, it.Entry.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.AuditClaimOutbox: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	rows, err = r.pool.Query(ctx, `SELECT `+auditColumns+` FROM audit_logs WHERE id = ANY($1)`, entryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("repository.AuditClaimOutbox: entries: %w", err)
+	}
+	defer rows.Close()
+	entries := make(map[string]*model.AuditLog, len(entryIDs))
+	for rows.Next() {
+		e, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.AuditClaimOutbox: entries scan: %w", err)
+		}
+		entries[e.ID] = e
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository.AuditClaimOutbox: entries: %w", err)
+	}
+	for i := range items {
+		e, ok := entries[items[i].Entry.ID]
+		if !ok {
+			return nil, fmt.Errorf("repository.AuditClaimOutbox: entry %s missing", items[i].Entry.ID)
+		}
+		items[i].Entry = *e
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+// AckOutbox deletes delivered outbox items.
+func (r *AuditRepo) AckOutbox(ctx context.Context, ids []int64) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM audit_outbox WHERE id = ANY($1)`, ids); err != nil {
+		return fmt.Errorf("repository.AuditAckOutbox: %w", err)
+	}
+	return nil
+}
+
+// RetryOutbox records a failed delivery of outbox items and makes them due
+// again after delay.
+func (r *AuditRepo) RetryOutbox(ctx context.Context, ids []int64, delay time.Duration, lastErr string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE audit_outbox
+		SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2), last_error = $3
+		WHERE id = ANY($1)`, ids, delay.Seconds(), lastErr)
+	if err != nil {
+		return fmt.Errorf("repository.AuditRetryOutbox: %w", err)
+	}
+	return nil
+}
+
+// OutboxLag returns the number of outbox items queued for sink and when the
+// oldest was queued.
+func (r *AuditRepo) OutboxLag(ctx context.Context, sink string) (int64, time.Time, error) {
+	var pending int64
+	var oldest *time.Time
+	err := r.pool.QueryRow(ctx, `
+		SELECT count(*), min(created_at) FROM audit_outbox WHERE sink = $1`, sink).Scan(&pending, &oldest)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("repository.AuditOutboxLag: %w", err)
+	}
+	if oldest == nil {
+		return pending, time.Time{}, nil
+	}
+	return pending, *oldest, nil
+}
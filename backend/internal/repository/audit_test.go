@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func TestAuditRepo_ConcurrentInstancesShareOneChain(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Two services stand in for two instances: neither holds chain state,
+	// so only the chain head lock keeps them from forking.
+	instances := []*service.AuditService{
+		service.NewAuditService(NewAuditRepo(docRepo.pool), nil),
+		service.NewAuditService(NewAuditRepo(docRepo.pool), nil),
+	}
+
+	const perInstance = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*perInstance)
+	for _, svc := range instances {
+		for i := 0; i < perInstance; i++ {
+			wg.Add(1)
+			go func(svc *service.AuditService, i int) {
+				defer wg.Done()
+				// Details with unsorted keys and nesting exercise JSONB
+				// normalisation of the hashed form.
+				details := map[string]interface{}{"zeta": i, "alpha": map[string]interface{}{"b": "x", "a": []int{1, 2}}}
+				errs <- svc.LogWithDetails(ctx, model.AuditDocumentUpload, "test-user-doc", "doc", "document", details)
+			}(svc, i)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("LogWithDetails: %v", err)
+		}
+	}
+
+	entries, _, err := NewAuditRepo(docRepo.pool).List(ctx, ListFilter{UserID: "test-user-doc", Limit: 2 * perInstance})
+	if err != nil || len(entries) != 2*perInstance {
+		t.Fatalf("List = %d entries, %v", len(entries), err)
+	}
+	first, last := entries[0], entries[0]
+	for _, e := range entries {
+		if e.Seq < first.Seq {
+			first = e
+		}
+		if e.Seq > last.Seq {
+			last = e
+		}
+	}
+
+	report, err := service.NewAuditVerifier(NewAuditRepo(docRepo.pool)).
+		VerifyRange(ctx, first.TenantID, service.ChainRange{FromSeq: first.Seq, ToSeq: last.Seq})
+	if err != nil {
+		t.Fatalf("VerifyRange: %v", err)
+	}
+	if !report.Valid || int64(report.EntriesChecked) != last.Seq-first.Seq+1 {
+		t.Errorf("report = %+v, want valid over seq %d..%d", report, first.Seq, last.Seq)
+	}
+
+	tenantEntries, _, err := NewAuditRepo(docRepo.pool).List(ctx, ListFilter{TenantID: first.TenantID, Limit: 1000})
+	if err != nil || len(tenantEntries) < 2*perInstance {
+		t.Fatalf("List by tenant = %d entries, %v", len(tenantEntries), err)
+	}
+	for _, e := range tenantEntries {
+		if e.TenantID != first.TenantID {
+			t.Errorf("entry %s in tenant %q, want %q", e.ID, e.TenantID, first.TenantID)
+		}
+	}
+}
+
+func TestAuditRepo_CheckpointedEntriesVerifyOffline(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+	repo := NewAuditRepo(docRepo.pool)
+	svc := service.NewAuditService(repo, nil)
+
+	for i := 0; i < 5; i++ {
+		details := map[string]interface{}{"zeta": i, "note": "<a&b>", "alpha": []int{1, 2}}
+		if err := svc.LogWithDetails(ctx, model.AuditQueryExecuted, "test-user-doc", "", "", details); err != nil {
+			t.Fatalf("LogWithDetails: %v", err)
+		}
+	}
+
+	// Two instances race to checkpoint; only one checkpoint per range lands.
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	checkpointers := []*service.AuditCheckpointer{
+		service.NewAuditCheckpointer(repo, key),
+		service.NewAuditCheckpointer(NewAuditRepo(docRepo.pool), key),
+	}
+	var wg sync.WaitGroup
+	for _, c := range checkpointers {
+		wg.Add(1)
+		go func(c *service.AuditCheckpointer) {
+			defer wg.Done()
+			if _, err := c.CheckpointOnce(ctx); err != nil {
+				t.Errorf("CheckpointOnce: %v", err)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	entries, _, err := repo.List(ctx, ListFilter{UserID: "test-user-doc", Limit: 5})
+	if err != nil || len(entries) != 5 {
+		t.Fatalf("List = %d entries, %v", len(entries), err)
+	}
+	bundle, err := checkpointers[0].Bundle(ctx, entries)
+	if err != nil {
+		t.Fatalf("Bundle: %v", err)
+	}
+
+	// Stored JSONB details and timestamps must survive export byte for byte.
+	raw, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exported auditchain.Bundle
+	if err := json.Unmarshal(raw, &exported); err != nil {
+		t.Fatal(err)
+	}
+	rep := auditchain.VerifyBundle(&exported, checkpointers[0].PublicKey())
+	if !rep.Valid || rep.Anchored != 5 {
+		t.Errorf("report = %+v", rep)
+	}
+}
+
+func TestAuditRepo_OutboxDeliversAtLeastOnce(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+	sink := "test-" + uuid.New().String()
+	repo := NewAuditRepo(docRepo.pool).WithOutbox([]string{sink})
+	svc := service.NewAuditService(repo, nil)
+
+	for i := 0; i < 3; i++ {
+		if err := svc.LogWithDetails(ctx, model.AuditDocumentView, "test-user-doc", "doc", "document", nil); err != nil {
+			t.Fatalf("LogWithDetails: %v", err)
+		}
+	}
+	if pending, _, err := repo.OutboxLag(ctx, sink); err != nil || pending != 3 {
+		t.Fatalf("OutboxLag = %d, %v; want 3 queued", pending, err)
+	}
+
+	// Claimed items are hidden from a second claim until acked or retried.
+	items, err := repo.ClaimOutbox(ctx, sink, 2, time.Minute)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("ClaimOutbox = %d, %v", len(items), err)
+	}
+	if items[0].Entry.Action != model.AuditDocumentView || items[0].Entry.DetailsHash == nil {
+		t.Errorf("claimed entry = %+v", items[0].Entry)
+	}
+	rest, err := repo.ClaimOutbox(ctx, sink, 10, time.Minute)
+	if err != nil || len(rest) != 1 {
+		t.Fatalf("second ClaimOutbox = %d, %v; want the 1 unclaimed", len(rest), err)
+	}
+
+	if err := repo.AckOutbox(ctx, []int64{items[0].ID, items[1].ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RetryOutbox(ctx, []int64{rest[0].ID}, 0, "boom"); err != nil {
+		t.Fatal(err)
+	}
+	retried, err := repo.ClaimOutbox(ctx, sink, 10, time.Minute)
+	if err != nil || len(retried) != 1 || retried[0].Attempts != 1 {
+		t.Fatalf("ClaimOutbox after retry = %+v, %v", retried, err)
+	}
+	repo.AckOutbox(ctx, []int64{retried[0].ID})
+	if pending, _, _ := repo.OutboxLag(ctx, sink); pending != 0 {
+		t.Errorf("pending = %d after acks, want 0", pending)
+	}
+}
+
+func TestAuditRepo_VerifyRangeFindsTampering(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+	repo := NewAuditRepo(docRepo.pool)
+	svc := service.NewAuditService(repo, nil)
+
+	// Each edit is made to a different entry; every stored column is covered.
+	edits := []string{
+		`action = 'DOCUMENT_DELETE'`,
+		`user_id = NULL`,
+		`resource_id = 'other-doc'`,
+		`resource_type = 'folder'`,
+		`severity = 'CRITICAL'`,
+		`ip_address = '198.51.100.1'`,
+		`user_agent = 'forged'`,
+		`created_at = created_at + interval '1 second'`,
+		`details = '{"i": -1}'`,
+	}
+	reqCtx := service.WithRequestMeta(ctx, service.RequestMeta{IPAddress: "203.0.113.7", UserAgent: "Mozilla/5.0"})
+	for i := 0; i <= len(edits); i++ {
+		details := map[string]interface{}{"i": i, "note": "<a&b>"}
+		if err := svc.LogWithDetails(reqCtx, model.AuditDocumentView, "test-user-doc", "doc", "document", details); err != nil {
+			t.Fatalf("LogWithDetails: %v", err)
+		}
+	}
+	verifier := service.NewAuditVerifier(repo)
+	tenant, err := verifier.Tenant(ctx, "test-user-doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := verifier.VerifyRange(ctx, tenant, service.ChainRange{})
+	if err != nil || !report.Valid || report.EntriesChecked != int(report.HeadSeq) {
+		t.Fatalf("VerifyRange = %+v, %v; want the whole chain valid", report, err)
+	}
+
+	// Editing a stored entry breaks its hash, whatever range covers it.
+	head := report.HeadSeq
+	for i, edit := range edits {
+		seq := head - int64(len(edits)) + int64(i)
+		if _, err := docRepo.pool.Exec(ctx, `
+			UPDATE audit_logs SET `+edit+` WHERE tenant_id = $1 AND seq = $2`, tenant, seq); err != nil {
+			t.Fatalf("%s: %v", edit, err)
+		}
+		report, err = verifier.VerifyRange(ctx, tenant, service.ChainRange{FromSeq: seq, ToSeq: seq})
+		if err != nil || report.Valid || report.Breaks[0].Seq != seq || report.Breaks[0].Reason != auditchain.BreakHash {
+			t.Errorf("VerifyRange after %s = %+v, %v", edit, report, err)
+		}
+	}
+}
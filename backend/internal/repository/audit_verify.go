@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// Compile-time check.
+var _ service.AuditChainRepository = (*AuditRepo)(nil)
+
+// ChainTenant returns the chain userID's entries are appended to.
+func (r *AuditRepo) ChainTenant(ctx context.Context, userID string) (string, error) {
+	var tenantID string
+	if err := r.pool.QueryRow(ctx, `SELECT user_org_id($1)`, userID).Scan(&tenantID); err != nil {
+		return "", fmt.Errorf("repository.ChainTenant: %w", err)
+	}
+	return tenantID, nil
+}
+
+// ChainHead returns the seq and hash recorded on a tenant's chain head, or
+// 0 and "" if the tenant has no entries.
+func (r *AuditRepo) ChainHead(ctx context.Context, tenantID string) (int64, string, error) {
+	var seq int64
+	var hash string
+	err := r.pool.QueryRow(ctx, `
+		SELECT seq, hash FROM audit_chain_heads WHERE tenant_id = $1`, tenantID).Scan(&seq, &hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("repository.ChainHead: %w", err)
+	}
+	return seq, hash, nil
+}
+
+// ChainSeqBounds returns the lowest and highest seq of a tenant's entries
+// created within [start, end]; zero times are unbounded.
+func (r *AuditRepo) ChainSeqBounds(ctx context.Context, tenantID string, start, end time.Time) (int64, int64, bool, error) {
+	var lo, hi *int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT min(seq), max(seq) FROM audit_logs
+		WHERE tenant_id = $1
+			AND ($2::timestamptz IS NULL OR created_at >= $2)
+			AND ($3::timestamptz IS NULL OR created_at <= $3)`,
+		tenantID, optionalTime(start), optionalTime(end)).Scan(&lo, &hi)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("repository.ChainSeqBounds: %w", err)
+	}
+	if lo == nil || hi == nil {
+		return 0, 0, false, nil
+	}
+	return *lo, *hi, true, nil
+}
+
+// ChainPage returns up to limit of a tenant's entries with seq
+// fromSeq..toSeq, in seq order.
+func (r *AuditRepo) ChainPage(ctx context.Context, tenantID string, fromSeq, toSeq int64, limit int) ([]model.AuditLog, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+auditColumns+` FROM audit_logs
+		WHERE tenant_id = $1 AND seq BETWEEN $2 AND $3
+		ORDER BY seq ASC
+		LIMIT $4`, tenantID, fromSeq, toSeq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ChainPage: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []model.AuditLog
+	for rows.Next() {
+		e, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.ChainPage scan: %w", err)
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -7,13 +7,17 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/connexus-ai/ragbox-backend/internal/fieldcrypt"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
 
 // BM25Repository implements service.BM25Searcher using PostgreSQL ts_vector.
 // Relies on the GIN index on document_chunks.content_tsv created in STORY-161.
+// Only content_tsv is searched, so sealed content (migration 031) is matched
+// through the lexemes ChunkRepo.BulkInsert stores alongside it.
 type BM25Repository struct {
 	pool *pgxpool.Pool
+	keys *fieldcrypt.Keyring // opens sealed content in results
 }
 
 // NewBM25Repository creates a BM25Repository.
@@ -21,26 +25,46 @@ func NewBM25Repository(pool *pgxpool.Pool) *BM25Repository {
 	return &BM25Repository{pool: pool}
 }
 
+// WithKeyring returns a copy of r that decrypts sealed chunk content.
+func (r *BM25Repository) WithKeyring(keys *fieldcrypt.Keyring) *BM25Repository {
+	return &BM25Repository{pool: r.pool, keys: keys}
+}
+
 // Compile-time check.
-var _ service.BM25Searcher = (*BM25Repository)(nil)
+var (
+	_ service.BM25Searcher      = (*BM25Repository)(nil)
+	_ service.VaultBM25Searcher = (*BM25Repository)(nil)
+)
 
 // FullTextSearch finds chunks matching the query via PostgreSQL full-text search,
-// scoped to documents owned by userID. Uses the GIN index on content_tsv.
-func (r *BM25Repository) FullTextSearch(ctx context.Context, query string, topK int, userID string) ([]service.VectorSearchResult, error) {
+// scoped to documents in userID's organization that sit outside any vault or
+// in a vault the user may query. When excludePrivileged is true, chunks from
+// other users' privileged documents are excluded. Uses the GIN index on
+// content_tsv.
+func (r *BM25Repository) FullTextSearch(ctx context.Context, query string, topK int, userID string, excludePrivileged bool) ([]service.VectorSearchResult, error) {
+	return r.FullTextSearchInVault(ctx, query, topK, userID, "", excludePrivileged)
+}
+
+// FullTextSearchInVault is FullTextSearch restricted to one vault. An empty
+// vaultID searches every vault the user may query.
+func (r *BM25Repository) FullTextSearchInVault(ctx context.Context, query string, topK int, userID, vaultID string, excludePrivileged bool) ([]service.VectorSearchResult, error) {
 	rows, err := r.pool.Query(ctx, `
 		SELECT c.id, c.document_id, c.chunk_index, c.content, c.content_hash,
-		       c.token_count, c.created_at,
+		       c.token_count, c.created_at, c.pii_findings,
 		       ts_rank_cd(c.content_tsv, plainto_tsquery('english', $1)) AS rank,
 		       d.id, d.user_id, d.filename, d.original_name, d.mime_type, d.file_type,
-		       d.is_privileged, d.security_tier, d.chunk_count, d.created_at
+		       d.is_privileged, d.security_tier, d.chunk_count, d.created_at, d.vault_id
 		FROM document_chunks c
 		JOIN documents d ON c.document_id = d.id
-		WHERE d.user_id = $2
+		WHERE ((d.org_id = user_org_id($2) AND vault_queryable(d.vault_id, $2))
+		       OR d.id IN (SELECT document_id FROM shared_documents($2)))
+		  AND ($4 = '' OR d.vault_id = $4)
+		  AND (NOT $5 OR d.is_privileged = false OR d.user_id = $2)
 		  AND d.deletion_status = 'Active'
 		  AND c.content_tsv @@ plainto_tsquery('english', $1)
 		ORDER BY rank DESC
 		LIMIT $3
-	`, query, userID, topK)
+	`, query, userID, topK, vaultID, excludePrivileged)
 	if err != nil {
 		return nil, fmt.Errorf("repository.FullTextSearch: %w", err)
 	}
@@ -49,18 +73,25 @@ func (r *BM25Repository) FullTextSearch(ctx context.Context, query string, topK
 	var results []service.VectorSearchResult
 	for rows.Next() {
 		var cr service.VectorSearchResult
+		var pii []byte
 		err := rows.Scan(
 			&cr.Chunk.ID, &cr.Chunk.DocumentID, &cr.Chunk.ChunkIndex,
 			&cr.Chunk.Content, &cr.Chunk.ContentHash, &cr.Chunk.TokenCount,
-			&cr.Chunk.CreatedAt, &cr.Similarity,
+			&cr.Chunk.CreatedAt, &pii, &cr.Similarity,
 			&cr.Document.ID, &cr.Document.UserID, &cr.Document.Filename,
 			&cr.Document.OriginalName, &cr.Document.MimeType, &cr.Document.FileType,
 			&cr.Document.IsPrivileged, &cr.Document.SecurityTier,
-			&cr.Document.ChunkCount, &cr.Document.CreatedAt,
+			&cr.Document.ChunkCount, &cr.Document.CreatedAt, &cr.Document.VaultID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("repository.FullTextSearch: scan: %w", err)
 		}
+		if cr.Chunk.PIIFindings, err = piiFindingsFromJSON(pii); err != nil {
+			return nil, fmt.Errorf("repository.FullTextSearch: chunk %s: pii findings: %w", cr.Chunk.ID, err)
+		}
+		if cr.Chunk.Content, err = r.keys.Open(ctx, cr.Chunk.Content); err != nil {
+			return nil, fmt.Errorf("repository.FullTextSearch: chunk %s: %w", cr.Chunk.ID, err)
+		}
 		results = append(results, cr)
 	}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	pgvector "github.com/pgvector/pgvector-go"
 
+	"github.com/connexus-ai/ragbox-backend/internal/fieldcrypt"
 	"github.com/connexus-ai/ragbox-backend/internal/model"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
@@ -19,6 +20,7 @@ import (
 // ChunkRepo implements service.ChunkStore and service.VectorSearcher.
 type ChunkRepo struct {
 	pool *pgxpool.Pool
+	keys *fieldcrypt.Keyring // seals content; nil stores plaintext
 }
 
 // NewChunkRepo creates a ChunkRepo.
@@ -26,16 +28,27 @@ func NewChunkRepo(pool *pgxpool.Pool) *ChunkRepo {
 	return &ChunkRepo{pool: pool}
 }
 
+// WithKeyring returns a copy of r that encrypts chunk content on write with
+// the document tenant's data key and decrypts it on read (migration 031).
+func (r *ChunkRepo) WithKeyring(keys *fieldcrypt.Keyring) *ChunkRepo {
+	return &ChunkRepo{pool: r.pool, keys: keys}
+}
+
 // Compile-time checks.
 var (
-	_ service.ChunkStore        = (*ChunkRepo)(nil)
-	_ service.VectorSearcher    = (*ChunkRepo)(nil)
-	_ service.RelatedDocSearcher = (*ChunkRepo)(nil)
-	_ service.ThreadSearcher    = (*ChunkRepo)(nil)
-	_ service.ChunkScanner      = (*ChunkRepo)(nil)
+	_ service.ChunkStore          = (*ChunkRepo)(nil)
+	_ service.VectorSearcher      = (*ChunkRepo)(nil)
+	_ service.VaultVectorSearcher = (*ChunkRepo)(nil)
+	_ service.RelatedDocSearcher  = (*ChunkRepo)(nil)
+	_ service.ThreadSearcher      = (*ChunkRepo)(nil)
+	_ service.ChunkScanner        = (*ChunkRepo)(nil)
+	_ service.VectorReuseStore    = (*ChunkRepo)(nil)
 )
 
 // BulkInsert stores chunks with their embedding vectors using pgx batching.
+// content_tsv is computed from the plaintext, since content may be sealed.
+// With a keyring, contextual_text and entities are sealed along with
+// content, and content_hash and embedding_input_hash are keyed per tenant.
 func (r *ChunkRepo) BulkInsert(ctx context.Context, chunks []service.Chunk, vectors [][]float32) error {
 	if len(chunks) == 0 {
 		return nil
@@ -44,26 +57,71 @@ func (r *ChunkRepo) BulkInsert(ctx context.Context, chunks []service.Chunk, vect
 		return fmt.Errorf("repository.BulkInsert: chunk count (%d) != vector count (%d)", len(chunks), len(vectors))
 	}
 
+	var tenants map[string]string
+	if r.keys != nil {
+		var docIDs []string
+		seen := make(map[string]bool)
+		for _, c := range chunks {
+			if !seen[c.DocumentID] {
+				seen[c.DocumentID] = true
+				docIDs = append(docIDs, c.DocumentID)
+			}
+		}
+		var err error
+		if tenants, err = documentTenants(ctx, r.pool, docIDs); err != nil {
+			return fmt.Errorf("repository.BulkInsert: tenant: %w", err)
+		}
+	}
+
 	batch := &pgx.Batch{}
 	now := time.Now().UTC()
 
 	for i, c := range chunks {
 		id := uuid.New().String()
 		embedding := pgvector.NewVector(vectors[i])
+		tenant := tenants[c.DocumentID]
+		content, err := r.keys.Seal(ctx, tenant, c.Content)
+		if err != nil {
+			return fmt.Errorf("repository.BulkInsert: chunk %d: %w", i, err)
+		}
+		contextual, err := r.keys.Seal(ctx, tenant, c.ContextualText)
+		if err != nil {
+			return fmt.Errorf("repository.BulkInsert: chunk %d: contextual text: %w", i, err)
+		}
+		entities, err := r.sealEntities(ctx, tenant, c.Entities)
+		if err != nil {
+			return fmt.Errorf("repository.BulkInsert: chunk %d: entities: %w", i, err)
+		}
+		contentHash, inputHash := c.ContentHash, service.EmbeddingInputHash(c.Content)
+		if fieldcrypt.IsSealed(content) {
+			if contentHash, err = r.keys.Hash(ctx, tenant, contentHash); err != nil {
+				return fmt.Errorf("repository.BulkInsert: chunk %d: %w", i, err)
+			}
+			if inputHash, err = r.keys.Hash(ctx, tenant, inputHash); err != nil {
+				return fmt.Errorf("repository.BulkInsert: chunk %d: %w", i, err)
+			}
+		}
 
 		batch.Queue(`
-			INSERT INTO document_chunks (id, document_id, chunk_index, content, content_hash, token_count, embedding, contextual_text, entities, created_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			INSERT INTO document_chunks (id, document_id, chunk_index, content, content_hash, token_count, embedding, contextual_text, entities, created_at, embedding_model, embedding_version, embedding_input_hash, content_tsv, pii_findings)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, to_tsvector('english', $14::text), $15)
 			ON CONFLICT (document_id, chunk_index) DO UPDATE SET
 				content = EXCLUDED.content,
+				content_tsv = EXCLUDED.content_tsv,
 				content_hash = EXCLUDED.content_hash,
 				token_count = EXCLUDED.token_count,
 				embedding = EXCLUDED.embedding,
 				contextual_text = EXCLUDED.contextual_text,
 				entities = EXCLUDED.entities,
-				created_at = EXCLUDED.created_at`,
-			id, c.DocumentID, c.Index, c.Content, c.ContentHash, c.TokenCount, embedding,
-			nullableString(c.ContextualText), entitiesToJSON(c.Entities), now,
+				created_at = EXCLUDED.created_at,
+				embedding_model = EXCLUDED.embedding_model,
+				embedding_version = EXCLUDED.embedding_version,
+				embedding_input_hash = EXCLUDED.embedding_input_hash,
+				pii_findings = EXCLUDED.pii_findings`,
+			id, c.DocumentID, c.Index, content, contentHash, c.TokenCount, embedding,
+			nullableString(contextual), entities, now,
+			spaceModelParam(c.EmbeddingSpace), spaceVersionParam(c.EmbeddingSpace),
+			inputHash, c.Content, piiFindingsToJSON(c.PIIFindings),
 		)
 	}
 
@@ -80,6 +138,69 @@ func (r *ChunkRepo) BulkInsert(ctx context.Context, chunks []service.Chunk, vect
 	return nil
 }
 
+// VectorsByInputHash returns stored vectors keyed by embedding_input_hash for
+// chunks embedded in space. Vectors are deterministic for a given input and
+// model, so any matching chunk's vector can be reused. Only chunks of documents
+// in documentID's tenant are considered, so a hit reveals nothing about
+// another tenant's content. Sealed chunks store their hash keyed with the
+// tenant's hash key, so each hash is looked up in both forms.
+func (r *ChunkRepo) VectorsByInputHash(ctx context.Context, space model.EmbeddingSpace, documentID string, hashes []string) (map[string][]float32, error) {
+	if len(hashes) == 0 || space.IsZero() {
+		return nil, nil
+	}
+
+	stored := make(map[string]string, 2*len(hashes))
+	for _, h := range hashes {
+		stored[h] = h
+	}
+	if r.keys != nil {
+		tenants, err := documentTenants(ctx, r.pool, []string{documentID})
+		if err != nil {
+			return nil, fmt.Errorf("repository.VectorsByInputHash: tenant: %w", err)
+		}
+		if tenant := tenants[documentID]; tenant != "" {
+			for _, h := range hashes {
+				keyed, err := r.keys.Hash(ctx, tenant, h)
+				if err != nil {
+					return nil, fmt.Errorf("repository.VectorsByInputHash: %w", err)
+				}
+				stored[keyed] = h
+			}
+		}
+	}
+	lookup := make([]string, 0, len(stored))
+	for h := range stored {
+		lookup = append(lookup, h)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT ON (embedding_input_hash) embedding_input_hash, embedding
+		FROM document_chunks
+		WHERE embedding_input_hash = ANY($1)
+			AND embedding_model = $2
+			AND embedding_version = $3
+			AND embedding IS NOT NULL
+			AND document_id IN (
+				SELECT id FROM documents
+				WHERE `+documentTenantSQL+` = (SELECT `+documentTenantSQL+` FROM documents WHERE id = $4)
+			)`, lookup, space.Model, space.Version, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.VectorsByInputHash: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string][]float32)
+	for rows.Next() {
+		var hash string
+		var vec pgvector.Vector
+		if err := rows.Scan(&hash, &vec); err != nil {
+			return nil, fmt.Errorf("repository.VectorsByInputHash: scan: %w", err)
+		}
+		out[stored[hash]] = vec.Slice()
+	}
+	return out, rows.Err()
+}
+
 func nullableString(s string) interface{} {
 	if s == "" {
 		return nil
@@ -87,7 +208,37 @@ func nullableString(s string) interface{} {
 	return s
 }
 
-func entitiesToJSON(entities []service.EntityExtracted) interface{} {
+// spaceModelParam returns the embedding_model column value for space (NULL when unversioned).
+func spaceModelParam(space model.EmbeddingSpace) interface{} {
+	if space.IsZero() {
+		return nil
+	}
+	return space.Model
+}
+
+// spaceVersionParam returns the embedding_version column value for space (NULL when unversioned).
+func spaceVersionParam(space model.EmbeddingSpace) interface{} {
+	if space.IsZero() {
+		return nil
+	}
+	return space.Version
+}
+
+// sealEntities returns the entities column value. Sealed entities are stored
+// as a JSON string holding the sealed JSON array.
+func (r *ChunkRepo) sealEntities(ctx context.Context, tenantID string, entities []service.EntityExtracted) ([]byte, error) {
+	data := entitiesToJSON(entities)
+	if len(entities) == 0 {
+		return data, nil
+	}
+	sealed, err := r.keys.Seal(ctx, tenantID, string(data))
+	if err != nil || !fieldcrypt.IsSealed(sealed) {
+		return data, err
+	}
+	return json.Marshal(sealed)
+}
+
+func entitiesToJSON(entities []service.EntityExtracted) []byte {
 	if len(entities) == 0 {
 		return []byte("[]")
 	}
@@ -98,29 +249,75 @@ func entitiesToJSON(entities []service.EntityExtracted) interface{} {
 	return data
 }
 
+// piiFindingsToJSON returns the pii_findings column value (NULL when the
+// chunk was not scanned).
+func piiFindingsToJSON(findings []model.PIIFinding) interface{} {
+	if findings == nil {
+		return nil
+	}
+	data, err := json.Marshal(findings)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// piiFindingsFromJSON decodes a pii_findings column; NULL yields nil so the
+// chunk is scanned when read.
+func piiFindingsFromJSON(data []byte) ([]model.PIIFinding, error) {
+	if data == nil {
+		return nil, nil
+	}
+	findings := []model.PIIFinding{}
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
+
 // SimilaritySearch finds the top-K chunks most similar to queryVec using cosine distance,
-// scoped to documents owned by userID. When excludePrivileged is true, chunks from
-// privileged documents are excluded.
-func (r *ChunkRepo) SimilaritySearch(ctx context.Context, queryVec []float32, topK int, threshold float64, userID string, excludePrivileged bool) ([]service.VectorSearchResult, error) {
+// scoped to documents in userID's organization that sit outside any vault or
+// in a vault the user may query. When excludePrivileged is true, chunks from
+// privileged documents are excluded. When space is set, only chunks embedded in
+// that space are compared, so vectors from different models never mix.
+func (r *ChunkRepo) SimilaritySearch(ctx context.Context, queryVec []float32, space model.EmbeddingSpace, topK int, threshold float64, userID string, excludePrivileged bool) ([]service.VectorSearchResult, error) {
+	return r.SimilaritySearchInVault(ctx, queryVec, space, topK, threshold, userID, "", excludePrivileged)
+}
+
+// SimilaritySearchInVault is SimilaritySearch restricted to one vault. An
+// empty vaultID searches every vault the user may query.
+func (r *ChunkRepo) SimilaritySearchInVault(ctx context.Context, queryVec []float32, space model.EmbeddingSpace, topK int, threshold float64, userID, vaultID string, excludePrivileged bool) ([]service.VectorSearchResult, error) {
 	embedding := pgvector.NewVector(queryVec)
+	args := []interface{}{embedding, threshold, userID, topK}
 
 	query := `
 		SELECT
 			dc.id, dc.document_id, dc.chunk_index, dc.content, dc.content_hash,
-			dc.token_count, dc.created_at,
+			dc.token_count, dc.created_at, dc.pii_findings,
 			1 - (dc.embedding <=> $1::vector) AS similarity,
 			d.id, d.user_id, d.filename, d.original_name, d.mime_type, d.file_type,
-			d.is_privileged, d.security_tier, d.chunk_count, d.created_at
+			d.is_privileged, d.security_tier, d.chunk_count, d.created_at, d.vault_id
 		FROM document_chunks dc
 		JOIN documents d ON dc.document_id = d.id
 		WHERE d.deletion_status = 'Active'
-			AND d.user_id = $3
+			AND ((d.org_id = user_org_id($3) AND vault_queryable(d.vault_id, $3))
+				OR d.id IN (SELECT document_id FROM shared_documents($3)))
 			AND (1 - (dc.embedding <=> $1::vector)) > $2`
 
 	if excludePrivileged {
 		query += ` AND d.is_privileged = false`
 	}
 
+	if !space.IsZero() {
+		query += fmt.Sprintf(` AND dc.embedding_model = $%d AND dc.embedding_version = $%d`, len(args)+1, len(args)+2)
+		args = append(args, space.Model, space.Version)
+	}
+
+	if vaultID != "" {
+		query += fmt.Sprintf(` AND d.vault_id = $%d`, len(args)+1)
+		args = append(args, vaultID)
+	}
+
 	query += `
 		ORDER BY dc.embedding <=> $1::vector
 		LIMIT $4`
@@ -130,9 +327,11 @@ func (r *ChunkRepo) SimilaritySearch(ctx context.Context, queryVec []float32, to
 		"threshold", threshold,
 		"user_id", userID,
 		"exclude_privileged", excludePrivileged,
+		"embedding_space", space.Key(),
+		"vault_id", vaultID,
 	)
 
-	rows, err := r.pool.Query(ctx, query, embedding, threshold, userID, topK)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		slog.Error("[DEBUG-REPO] similarity search query failed", "error", err)
 		return nil, fmt.Errorf("repository.SimilaritySearch: %w", err)
@@ -142,18 +341,25 @@ func (r *ChunkRepo) SimilaritySearch(ctx context.Context, queryVec []float32, to
 	var results []service.VectorSearchResult
 	for rows.Next() {
 		var cr service.VectorSearchResult
+		var pii []byte
 		err := rows.Scan(
 			&cr.Chunk.ID, &cr.Chunk.DocumentID, &cr.Chunk.ChunkIndex,
 			&cr.Chunk.Content, &cr.Chunk.ContentHash, &cr.Chunk.TokenCount,
-			&cr.Chunk.CreatedAt, &cr.Similarity,
+			&cr.Chunk.CreatedAt, &pii, &cr.Similarity,
 			&cr.Document.ID, &cr.Document.UserID, &cr.Document.Filename,
 			&cr.Document.OriginalName, &cr.Document.MimeType, &cr.Document.FileType,
 			&cr.Document.IsPrivileged, &cr.Document.SecurityTier,
-			&cr.Document.ChunkCount, &cr.Document.CreatedAt,
+			&cr.Document.ChunkCount, &cr.Document.CreatedAt, &cr.Document.VaultID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("repository.SimilaritySearch: scan: %w", err)
 		}
+		if cr.Chunk.PIIFindings, err = piiFindingsFromJSON(pii); err != nil {
+			return nil, fmt.Errorf("repository.SimilaritySearch: chunk %s: pii findings: %w", cr.Chunk.ID, err)
+		}
+		if cr.Chunk.Content, err = r.keys.Open(ctx, cr.Chunk.Content); err != nil {
+			return nil, fmt.Errorf("repository.SimilaritySearch: chunk %s: %w", cr.Chunk.ID, err)
+		}
 		results = append(results, cr)
 	}
 
@@ -171,11 +377,38 @@ func (r *ChunkRepo) SimilaritySearch(ctx context.Context, queryVec []float32, to
 func (r *ChunkRepo) DeleteByDocumentID(ctx context.Context, documentID string) error {
 	_, err := r.pool.Exec(ctx, `DELETE FROM document_chunks WHERE document_id = $1`, documentID)
 	if err != nil {
-		return fmt.Errorf("repository.DeleteByDocumentID: %w", err)
+		return fmt.Errorf("repository.DeleteByDocumentID: %w", legalHoldErr(err))
 	}
 	return nil
 }
 
+// ListByDocumentID returns a document's chunks in order, with content
+// decrypted.
+func (r *ChunkRepo) ListByDocumentID(ctx context.Context, documentID string) ([]model.DocumentChunk, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, document_id, chunk_index, content, content_hash, token_count, created_at
+		FROM document_chunks
+		WHERE document_id = $1
+		ORDER BY chunk_index`, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ListByDocumentID: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []model.DocumentChunk
+	for rows.Next() {
+		var c model.DocumentChunk
+		if err := rows.Scan(&c.ID, &c.DocumentID, &c.ChunkIndex, &c.Content, &c.ContentHash, &c.TokenCount, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repository.ListByDocumentID: scan: %w", err)
+		}
+		if c.Content, err = r.keys.Open(ctx, c.Content); err != nil {
+			return nil, fmt.Errorf("repository.ListByDocumentID: chunk %s: %w", c.ID, err)
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks, rows.Err()
+}
+
 // CountByDocumentID returns the number of chunks for a document.
 // Used by: document detail endpoint (planned), integration tests.
 func (r *ChunkRepo) CountByDocumentID(ctx context.Context, documentID string) (int, error) {
@@ -196,7 +429,7 @@ func (r *ChunkRepo) GetChunkWithNeighbors(ctx context.Context, documentID, chunk
 			WHERE id = $1 AND document_id = $2
 		)
 		SELECT dc.id, dc.document_id, dc.chunk_index, dc.content,
-		       dc.content_hash, dc.token_count, dc.created_at
+		       dc.content_hash, dc.token_count, dc.created_at, dc.pii_findings
 		FROM document_chunks dc, target t
 		WHERE dc.document_id = $2
 		  AND dc.chunk_index BETWEEN t.chunk_index - 1 AND t.chunk_index + 1
@@ -211,9 +444,20 @@ func (r *ChunkRepo) GetChunkWithNeighbors(ctx context.Context, documentID, chunk
 	var chunks []model.DocumentChunk
 	for rows.Next() {
 		var c model.DocumentChunk
-		if err := rows.Scan(&c.ID, &c.DocumentID, &c.ChunkIndex, &c.Content, &c.ContentHash, &c.TokenCount, &c.CreatedAt); err != nil {
+		var pii []byte
+		if err := rows.Scan(&c.ID, &c.DocumentID, &c.ChunkIndex, &c.Content, &c.ContentHash, &c.TokenCount, &c.CreatedAt, &pii); err != nil {
 			return nil, fmt.Errorf("repository.GetChunkWithNeighbors: scan: %w", err)
 		}
+		findings, err := piiFindingsFromJSON(pii)
+		if err != nil {
+			return nil, fmt.Errorf("repository.GetChunkWithNeighbors: chunk %s: pii findings: %w", c.ID, err)
+		}
+		c.PIIFindings = findings
+		content, err := r.keys.Open(ctx, c.Content)
+		if err != nil {
+			return nil, fmt.Errorf("repository.GetChunkWithNeighbors: chunk %s: %w", c.ID, err)
+		}
+		c.Content = content
 		chunks = append(chunks, c)
 	}
 
@@ -222,22 +466,36 @@ func (r *ChunkRepo) GetChunkWithNeighbors(ctx context.Context, documentID, chunk
 
 // FindRelatedDocuments computes the embedding centroid of a source document
 // (average of all chunk embeddings) and finds the most similar documents
-// belonging to the same user, ordered by cosine similarity.
-func (r *ChunkRepo) FindRelatedDocuments(ctx context.Context, documentID string, userID string, limit int) ([]service.RelatedDocument, error) {
+// userID may read, ordered by cosine similarity. When excludePrivileged is
+// true, other users' privileged documents are skipped.
+// Only chunks in the active embedding space contribute to either centroid.
+func (r *ChunkRepo) FindRelatedDocuments(ctx context.Context, documentID string, userID string, limit int, excludePrivileged bool) ([]service.RelatedDocument, error) {
 	query := `
-		WITH source_centroid AS (
+		WITH active_space AS (
+			SELECT model, version FROM embedding_spaces WHERE status = 'active'
+		),
+		space_chunks AS (
+			SELECT c.document_id, c.embedding
+			FROM document_chunks c
+			LEFT JOIN active_space s ON true
+			WHERE c.embedding IS NOT NULL
+				AND (s.model IS NULL OR (c.embedding_model = s.model AND c.embedding_version = s.version))
+		),
+		source_centroid AS (
 			SELECT AVG(embedding)::vector AS centroid
-			FROM document_chunks
+			FROM space_chunks
 			WHERE document_id = $1
 		)
 		SELECT
 			d.id, d.user_id, d.filename, d.original_name, d.mime_type, d.file_type,
 			d.is_privileged, d.security_tier, d.chunk_count, d.created_at,
 			1 - (AVG(dc.embedding)::vector <=> sc.centroid) AS similarity
-		FROM document_chunks dc
+		FROM space_chunks dc
 		JOIN documents d ON dc.document_id = d.id
 		CROSS JOIN source_centroid sc
-		WHERE d.user_id = $2
+		WHERE ((d.org_id = user_org_id($2) AND vault_queryable(d.vault_id, $2))
+				OR d.id IN (SELECT document_id FROM shared_documents($2)))
+			AND (NOT $4 OR d.is_privileged = false OR d.user_id = $2)
 			AND d.deletion_status = 'Active'
 			AND dc.document_id != $1
 			AND sc.centroid IS NOT NULL
@@ -247,7 +505,7 @@ func (r *ChunkRepo) FindRelatedDocuments(ctx context.Context, documentID string,
 		ORDER BY AVG(dc.embedding)::vector <=> sc.centroid
 		LIMIT $3`
 
-	rows, err := r.pool.Query(ctx, query, documentID, userID, limit)
+	rows, err := r.pool.Query(ctx, query, documentID, userID, limit, excludePrivileged)
 	if err != nil {
 		return nil, fmt.Errorf("repository.FindRelatedDocuments: %w", err)
 	}
@@ -344,6 +602,11 @@ func (r *ChunkRepo) RecentChunksByUser(ctx context.Context, userID string, limit
 		if err := rows.Scan(&sc.ChunkID, &sc.DocumentID, &sc.Content); err != nil {
 			return nil, fmt.Errorf("repository.RecentChunksByUser: scan: %w", err)
 		}
+		content, err := r.keys.Open(ctx, sc.Content)
+		if err != nil {
+			return nil, fmt.Errorf("repository.RecentChunksByUser: chunk %s: %w", sc.ChunkID, err)
+		}
+		sc.Content = content
 		chunks = append(chunks, sc)
 	}
 
@@ -1,13 +1,16 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/connexus-ai/ragbox-backend/internal/fieldcrypt"
 	"github.com/connexus-ai/ragbox-backend/internal/model"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
@@ -34,11 +37,67 @@ func setupChunkRepo(t *testing.T) (*ChunkRepo, *DocumentRepo, func()) {
 		pool.Close()
 		t.Fatalf("read migration: %v", err)
 	}
+	versioningSQL, err := os.ReadFile("../../migrations/018_embedding_versioning.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	inputHashSQL, err := os.ReadFile("../../migrations/019_chunk_embedding_input_hash.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	orgSQL, err := os.ReadFile("../../migrations/021_organizations.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	vaultSQL, err := os.ReadFile("../../migrations/022_vaults.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	sharingSQL, err := os.ReadFile("../../migrations/023_sharing.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	encryptionSQL, err := os.ReadFile("../../migrations/031_data_encryption.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	piiSQL, err := os.ReadFile("../../migrations/032_pii_redaction.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
 
 	ensureSchema := func() error {
 		if _, err := pool.Exec(ctx, string(migrationSQL)); err != nil {
 			return err
 		}
+		if _, err := pool.Exec(ctx, string(versioningSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(inputHashSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(orgSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(vaultSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(sharingSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(encryptionSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(piiSQL)); err != nil {
+			return err
+		}
 		_, err := pool.Exec(ctx, `
 			INSERT INTO users (id, email, role, status, created_at)
 			VALUES ('test-user-chunk', 'chunktest@ragbox.co', 'Associate', 'Active', now())
@@ -211,7 +270,7 @@ func TestChunkRepo_SimilaritySearch(t *testing.T) {
 	queryVec := make([]float32, 768)
 	queryVec[100] = 1.0
 
-	results, err := repo.SimilaritySearch(ctx, queryVec, 5, 0.9, "test-user-chunk", false)
+	results, err := repo.SimilaritySearch(ctx, queryVec, model.EmbeddingSpace{}, 5, 0.9, "test-user-chunk", false)
 	if err != nil {
 		t.Fatalf("SimilaritySearch() error: %v", err)
 	}
@@ -260,7 +319,7 @@ func TestChunkRepo_SimilaritySearch_ExcludePrivileged(t *testing.T) {
 	queryVec[300] = 1.0
 
 	// Search WITHOUT excluding privileged — should find both
-	allResults, err := repo.SimilaritySearch(ctx, queryVec, 100, 0.9, "test-user-chunk", false)
+	allResults, err := repo.SimilaritySearch(ctx, queryVec, model.EmbeddingSpace{}, 100, 0.9, "test-user-chunk", false)
 	if err != nil {
 		t.Fatalf("SimilaritySearch(all) error: %v", err)
 	}
@@ -283,7 +342,7 @@ func TestChunkRepo_SimilaritySearch_ExcludePrivileged(t *testing.T) {
 	}
 
 	// Search WITH excluding privileged — privileged doc should not appear
-	filteredResults, err := repo.SimilaritySearch(ctx, queryVec, 100, 0.9, "test-user-chunk", true)
+	filteredResults, err := repo.SimilaritySearch(ctx, queryVec, model.EmbeddingSpace{}, 100, 0.9, "test-user-chunk", true)
 	if err != nil {
 		t.Fatalf("SimilaritySearch(exclude) error: %v", err)
 	}
@@ -324,7 +383,7 @@ func TestChunkRepo_SimilaritySearch_ThresholdFilters(t *testing.T) {
 	orthogonalVec := make([]float32, 768)
 	orthogonalVec[600] = 1.0
 
-	results, err := repo.SimilaritySearch(ctx, orthogonalVec, 10, 0.5, "test-user-chunk", false)
+	results, err := repo.SimilaritySearch(ctx, orthogonalVec, model.EmbeddingSpace{}, 10, 0.5, "test-user-chunk", false)
 	if err != nil {
 		t.Fatalf("SimilaritySearch() error: %v", err)
 	}
@@ -336,3 +395,170 @@ func TestChunkRepo_SimilaritySearch_ThresholdFilters(t *testing.T) {
 		}
 	}
 }
+
+func TestChunkRepo_SimilaritySearch_EmbeddingSpaceFilters(t *testing.T) {
+	repo, docRepo, cleanup := setupChunkRepo(t)
+	defer cleanup()
+
+	doc := createTestDocForChunks(t, docRepo, false)
+	ctx := context.Background()
+
+	v1 := model.EmbeddingSpace{Model: "text-embedding-004", Version: 1, Dimensions: 768}
+	v2 := model.EmbeddingSpace{Model: "text-embedding-005", Version: 2, Dimensions: 768}
+
+	vec := make([]float32, 768)
+	vec[500] = 1.0
+	if err := repo.BulkInsert(ctx, []service.Chunk{
+		{Content: "Space one " + doc.ID, ContentHash: "spacehash1-" + doc.ID, TokenCount: 3, Index: 0, DocumentID: doc.ID, EmbeddingSpace: v1},
+		{Content: "Space two " + doc.ID, ContentHash: "spacehash2-" + doc.ID, TokenCount: 3, Index: 1, DocumentID: doc.ID, EmbeddingSpace: v2},
+	}, [][]float32{vec, vec}); err != nil {
+		t.Fatalf("BulkInsert() error: %v", err)
+	}
+
+	results, err := repo.SimilaritySearch(ctx, vec, v1, 100, 0.9, "test-user-chunk", false)
+	if err != nil {
+		t.Fatalf("SimilaritySearch() error: %v", err)
+	}
+
+	found := 0
+	for _, r := range results {
+		if r.Document.ID != doc.ID {
+			continue
+		}
+		found++
+		if r.Chunk.ChunkIndex != 0 {
+			t.Errorf("chunk %d from another embedding space was compared", r.Chunk.ChunkIndex)
+		}
+	}
+	if found != 1 {
+		t.Errorf("expected 1 chunk in space %s, got %d", v1.Key(), found)
+	}
+}
+
+func TestChunkRepo_VectorsByInputHash(t *testing.T) {
+	repo, docRepo, cleanup := setupChunkRepo(t)
+	defer cleanup()
+
+	doc := createTestDocForChunks(t, docRepo, false)
+	ctx := context.Background()
+	space := model.EmbeddingSpace{Model: "text-embedding-004", Version: 1, Dimensions: 768}
+
+	vec := make([]float32, 768)
+	vec[700] = 1.0
+	content := "Reusable chunk " + doc.ID
+	if err := repo.BulkInsert(ctx, []service.Chunk{
+		{Content: content, ContentHash: "reuse-" + doc.ID, TokenCount: 3, Index: 0, DocumentID: doc.ID, EmbeddingSpace: space},
+	}, [][]float32{vec}); err != nil {
+		t.Fatalf("BulkInsert() error: %v", err)
+	}
+
+	hash := service.EmbeddingInputHash(content)
+	got, err := repo.VectorsByInputHash(ctx, space, doc.ID, []string{hash, service.EmbeddingInputHash("unseen " + doc.ID)})
+	if err != nil {
+		t.Fatalf("VectorsByInputHash() error: %v", err)
+	}
+	if len(got) != 1 || got[hash][700] != 1.0 {
+		t.Errorf("expected stored vector for %s, got %d entries", hash, len(got))
+	}
+
+	other := model.EmbeddingSpace{Model: "text-embedding-005", Version: 2, Dimensions: 768}
+	got, err = repo.VectorsByInputHash(ctx, other, doc.ID, []string{hash})
+	if err != nil {
+		t.Fatalf("VectorsByInputHash(other) error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Error("vectors from another embedding space must not be reused")
+	}
+
+	// Another tenant's document must not see this tenant's vectors.
+	if _, err := repo.pool.Exec(ctx, `
+		INSERT INTO users (id, email, role, status, created_at)
+		VALUES ('test-user-chunk-other', 'chunktest-other@ragbox.co', 'Associate', 'Active', now())
+		ON CONFLICT (id) DO NOTHING`); err != nil {
+		t.Fatal(err)
+	}
+	foreign := newTestDoc("test-user-chunk-other")
+	if err := docRepo.Create(ctx, foreign); err != nil {
+		t.Fatalf("create foreign doc: %v", err)
+	}
+	got, err = repo.VectorsByInputHash(ctx, space, foreign.ID, []string{hash})
+	if err != nil {
+		t.Fatalf("VectorsByInputHash(foreign) error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Error("vectors must not be reused across tenants")
+	}
+}
+
+func TestChunkRepo_BulkInsert_SealsContent(t *testing.T) {
+	plainRepo, docRepo, cleanup := setupChunkRepo(t)
+	defer cleanup()
+
+	wrapper, err := fieldcrypt.NewLocalWrapper(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := NewDataKeyring(plainRepo.pool, wrapper)
+	repo := plainRepo.WithKeyring(keys)
+	docs := docRepo.WithKeyring(keys)
+
+	doc := createTestDocForChunks(t, docRepo, false)
+	ctx := context.Background()
+
+	if err := docs.UpdateText(ctx, doc.ID, "Confidential settlement terms", 1); err != nil {
+		t.Fatalf("UpdateText() error: %v", err)
+	}
+	vec := make([]float32, 768)
+	vec[0] = 1
+	space := model.EmbeddingSpace{Model: "text-embedding-004", Version: 1, Dimensions: 768}
+	chunks := []service.Chunk{{
+		Content: "Confidential settlement terms", ContentHash: "sealhash", TokenCount: 3, DocumentID: doc.ID,
+		ContextualText: "Settlement between Acme and Globex", EmbeddingSpace: space,
+		Entities: []service.EntityExtracted{{Name: "Acme", Type: "organization"}},
+	}}
+	if err := repo.BulkInsert(ctx, chunks, [][]float32{vec}); err != nil {
+		t.Fatalf("BulkInsert() error: %v", err)
+	}
+
+	var storedText, storedContent, storedContextual, storedEntities, contentHash, inputHash string
+	var lexemeMatch bool
+	err = repo.pool.QueryRow(ctx, `
+		SELECT d.extracted_text, c.content, c.content_tsv @@ plainto_tsquery('english', 'settlement'),
+		       c.contextual_text, c.entities::text, c.content_hash, c.embedding_input_hash
+		FROM document_chunks c JOIN documents d ON d.id = c.document_id
+		WHERE c.document_id = $1`, doc.ID).Scan(&storedText, &storedContent, &lexemeMatch,
+		&storedContextual, &storedEntities, &contentHash, &inputHash)
+	if err != nil {
+		t.Fatalf("read stored row: %v", err)
+	}
+	if !fieldcrypt.IsSealed(storedText) || !fieldcrypt.IsSealed(storedContent) || !fieldcrypt.IsSealed(storedContextual) {
+		t.Errorf("stored text not sealed: %q / %q / %q", storedText, storedContent, storedContextual)
+	}
+	if strings.Contains(storedEntities, "Acme") {
+		t.Errorf("stored entities not sealed: %s", storedEntities)
+	}
+	// Hashes of sealed chunks are keyed, so they cannot confirm a guessed text.
+	if contentHash == "sealhash" || inputHash == service.EmbeddingInputHash("Confidential settlement terms") {
+		t.Errorf("stored hashes are not keyed: %q / %q", contentHash, inputHash)
+	}
+	reused, err := repo.VectorsByInputHash(ctx, space, doc.ID, []string{service.EmbeddingInputHash("Confidential settlement terms")})
+	if err != nil || len(reused) != 1 {
+		t.Errorf("VectorsByInputHash over a keyed hash = %d vectors, %v; want 1", len(reused), err)
+	}
+	if !lexemeMatch {
+		t.Error("content_tsv does not match the plaintext, BM25 would miss the chunk")
+	}
+
+	got, err := docs.GetByID(ctx, doc.ID)
+	if err != nil || got.ExtractedText == nil || *got.ExtractedText != "Confidential settlement terms" {
+		t.Errorf("GetByID extracted text = %v, %v", got, err)
+	}
+	var chunkID string
+	if err := repo.pool.QueryRow(ctx, `SELECT id FROM document_chunks WHERE document_id = $1`, doc.ID).Scan(&chunkID); err != nil {
+		t.Fatal(err)
+	}
+	neighbors, err := repo.GetChunkWithNeighbors(ctx, doc.ID, chunkID)
+	if err != nil || len(neighbors) != 1 || neighbors[0].Content != "Confidential settlement terms" {
+		t.Errorf("GetChunkWithNeighbors = %+v, %v", neighbors, err)
+	}
+}
@@ -30,12 +30,13 @@ func (r *CortexRepo) Insert(ctx context.Context, entry *model.CortexEntry) error
 
 	_, err := r.pool.Exec(ctx, `
 		INSERT INTO cortex_entries (id, tenant_id, content, embedding, source_channel,
-			source_message_id, captured_at, topic, is_instruction, auto_summary, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			source_message_id, captured_at, topic, is_instruction, auto_summary, expires_at, created_at,
+			source_document_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13::text[], '{}'))
 	`,
 		entry.ID, entry.TenantID, entry.Content, entry.Embedding, entry.SourceChannel,
 		entry.SourceMessageID, entry.CapturedAt, entry.Topic, entry.IsInstruction,
-		entry.AutoSummary, entry.ExpiresAt, time.Now().UTC(),
+		entry.AutoSummary, entry.ExpiresAt, time.Now().UTC(), entry.SourceDocumentIDs,
 	)
 	return err
 }
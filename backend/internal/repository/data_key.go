@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/fieldcrypt"
+)
+
+// DataKeyRepo implements fieldcrypt.KeyStore with pgx (migration 031).
+type DataKeyRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewDataKeyRepo creates a DataKeyRepo.
+func NewDataKeyRepo(pool *pgxpool.Pool) *DataKeyRepo {
+	return &DataKeyRepo{pool: pool}
+}
+
+// Compile-time check.
+var _ fieldcrypt.KeyStore = (*DataKeyRepo)(nil)
+
+// NewDataKeyring returns a keyring over the tenant_data_keys table, or nil
+// (encryption disabled) when wrapper is nil.
+func NewDataKeyring(pool *pgxpool.Pool, wrapper fieldcrypt.KeyWrapper) *fieldcrypt.Keyring {
+	if wrapper == nil {
+		return nil
+	}
+	return fieldcrypt.NewKeyring(NewDataKeyRepo(pool), wrapper)
+}
+
+func scanDataKey(row pgx.Row) (*fieldcrypt.DataKey, error) {
+	var k fieldcrypt.DataKey
+	err := row.Scan(&k.TenantID, &k.Version, &k.WrappedKey, &k.KEKID, &k.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *DataKeyRepo) ActiveKey(ctx context.Context, tenantID string) (*fieldcrypt.DataKey, error) {
+	k, err := scanDataKey(r.pool.QueryRow(ctx, `
+		SELECT tenant_id, version, wrapped_key, kek_id, created_at FROM tenant_data_keys
+		WHERE tenant_id = $1 ORDER BY version DESC LIMIT 1`, tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("repository.DataKeyActive: %w", err)
+	}
+	return k, nil
+}
+
+func (r *DataKeyRepo) Key(ctx context.Context, tenantID string, version int) (*fieldcrypt.DataKey, error) {
+	k, err := scanDataKey(r.pool.QueryRow(ctx, `
+		SELECT tenant_id, version, wrapped_key, kek_id, created_at FROM tenant_data_keys
+		WHERE tenant_id = $1 AND version = $2`, tenantID, version))
+	if err != nil {
+		return nil, fmt.Errorf("repository.DataKey: %w", err)
+	}
+	return k, nil
+}
+
+// CreateKey inserts k; if another process created the version first, its
+// key is returned instead.
+func (r *DataKeyRepo) CreateKey(ctx context.Context, k *fieldcrypt.DataKey) (*fieldcrypt.DataKey, error) {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO tenant_data_keys (tenant_id, version, wrapped_key, kek_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, version) DO NOTHING`,
+		k.TenantID, k.Version, k.WrappedKey, k.KEKID, k.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("repository.DataKeyCreate: %w", err)
+	}
+	stored, err := r.Key(ctx, k.TenantID, k.Version)
+	if err == nil && stored == nil {
+		err = fmt.Errorf("repository.DataKeyCreate: key for tenant %s v%d vanished", k.TenantID, k.Version)
+	}
+	return stored, err
+}
+
+// documentTenantSQL is the encryption tenant of a document row: its
+// organization, or its owner when it has none.
+const documentTenantSQL = `COALESCE(NULLIF(org_id, ''), user_id)`
+
+// documentTenants returns the encryption tenant of each of ids.
+func documentTenants(ctx context.Context, pool *pgxpool.Pool, ids []string) (map[string]string, error) {
+	rows, err := pool.Query(ctx,
+		`SELECT id, `+documentTenantSQL+` FROM documents WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := make(map[string]string, len(ids))
+	for rows.Next() {
+		var id, tenant string
+		if err := rows.Scan(&id, &tenant); err != nil {
+			return nil, err
+		}
+		tenants[id] = tenant
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if _, ok := tenants[id]; !ok {
+			return nil, fmt.Errorf("document %s not found", id)
+		}
+	}
+	return tenants, nil
+}
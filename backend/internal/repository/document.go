@@ -9,6 +9,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/connexus-ai/ragbox-backend/internal/fieldcrypt"
 	"github.com/connexus-ai/ragbox-backend/internal/model"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
@@ -16,6 +17,7 @@ import (
 // DocumentRepo implements service.DocumentRepository with pgx.
 type DocumentRepo struct {
 	pool *pgxpool.Pool
+	keys *fieldcrypt.Keyring // seals extracted_text; nil stores plaintext
 }
 
 // NewDocumentRepo creates a DocumentRepo.
@@ -23,8 +25,23 @@ func NewDocumentRepo(pool *pgxpool.Pool) *DocumentRepo {
 	return &DocumentRepo{pool: pool}
 }
 
-// Compile-time check that DocumentRepo implements service.DocumentRepository.
-var _ service.DocumentRepository = (*DocumentRepo)(nil)
+// WithKeyring returns a copy of r that encrypts extracted_text on write
+// with the document tenant's data key (migration 031).
+func (r *DocumentRepo) WithKeyring(keys *fieldcrypt.Keyring) *DocumentRepo {
+	return &DocumentRepo{pool: r.pool, keys: keys}
+}
+
+// Compile-time checks.
+var (
+	_ service.DocumentRepository    = (*DocumentRepo)(nil)
+	_ service.DocumentShareResolver = (*DocumentRepo)(nil)
+)
+
+// docVisibleSQL restricts documents to those $1 may read: its organization's
+// documents outside vaults closed to it, plus documents shared with it
+// directly or through a folder (migrations 022 and 023).
+const docVisibleSQL = `((org_id = user_org_id($1) AND vault_queryable(vault_id, $1))
+		OR id IN (SELECT document_id FROM shared_documents($1)))`
 
 func (r *DocumentRepo) Create(ctx context.Context, doc *model.Document) error {
 	metaJSON, err := marshalMeta(doc.Metadata)
@@ -32,22 +49,38 @@ func (r *DocumentRepo) Create(ctx context.Context, doc *model.Document) error {
 		return fmt.Errorf("repository.Create: marshal metadata: %w", err)
 	}
 
+	extractedText := doc.ExtractedText
+	if r.keys != nil && extractedText != nil && *extractedText != "" {
+		var tenantID string
+		err := r.pool.QueryRow(ctx,
+			`SELECT COALESCE(NULLIF($1, ''), user_org_id($2), $2)`, doc.OrgID, doc.UserID,
+		).Scan(&tenantID)
+		if err != nil {
+			return fmt.Errorf("repository.Create: tenant: %w", err)
+		}
+		sealed, err := r.keys.Seal(ctx, tenantID, *extractedText)
+		if err != nil {
+			return fmt.Errorf("repository.Create: %w", err)
+		}
+		extractedText = &sealed
+	}
+
 	_, err = r.pool.Exec(ctx, `
 		INSERT INTO documents (
 			id, vault_id, user_id, filename, original_name, mime_type, file_type,
 			size_bytes, storage_uri, storage_path, extracted_text, index_status,
 			deletion_status, is_privileged, security_tier, is_starred, chunk_count, checksum,
-			folder_id, metadata, deleted_at, hard_delete_at, created_at, updated_at
+			folder_id, metadata, deleted_at, hard_delete_at, created_at, updated_at, org_id
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
 			$8, $9, $10, $11, $12,
 			$13, $14, $15, $16, $17, $18,
-			$19, $20, $21, $22, $23, $24
+			$19, $20, $21, $22, $23, $24, COALESCE(NULLIF($25, ''), user_org_id($3))
 		)`,
 		doc.ID, doc.VaultID, doc.UserID, doc.Filename, doc.OriginalName, doc.MimeType, doc.FileType,
-		doc.SizeBytes, doc.StorageURI, doc.StoragePath, doc.ExtractedText, string(doc.IndexStatus),
+		doc.SizeBytes, doc.StorageURI, doc.StoragePath, extractedText, string(doc.IndexStatus),
 		string(doc.DeletionStatus), doc.IsPrivileged, doc.SecurityTier, doc.IsStarred, doc.ChunkCount, doc.Checksum,
-		doc.FolderID, metaJSON, doc.DeletedAt, doc.HardDeleteAt, doc.CreatedAt, doc.UpdatedAt,
+		doc.FolderID, metaJSON, doc.DeletedAt, doc.HardDeleteAt, doc.CreatedAt, doc.UpdatedAt, doc.OrgID,
 	)
 	if err != nil {
 		return fmt.Errorf("repository.Create: %w", err)
@@ -64,17 +97,22 @@ func (r *DocumentRepo) GetByID(ctx context.Context, id string) (*model.Document,
 		SELECT id, vault_id, user_id, filename, original_name, mime_type, file_type,
 			size_bytes, storage_uri, storage_path, extracted_text, index_status,
 			deletion_status, is_privileged, security_tier, is_starred, chunk_count, checksum,
-			folder_id, metadata, deleted_at, hard_delete_at, created_at, updated_at
+			folder_id, metadata, deleted_at, hard_delete_at, created_at, updated_at,
+			COALESCE(org_id, '')
 		FROM documents WHERE id = $1`, id,
 	).Scan(
 		&doc.ID, &doc.VaultID, &doc.UserID, &doc.Filename, &doc.OriginalName, &doc.MimeType, &doc.FileType,
 		&doc.SizeBytes, &doc.StorageURI, &doc.StoragePath, &doc.ExtractedText, &indexStatus,
 		&deletionStatus, &doc.IsPrivileged, &doc.SecurityTier, &doc.IsStarred, &doc.ChunkCount, &doc.Checksum,
 		&doc.FolderID, &metaJSON, &doc.DeletedAt, &doc.HardDeleteAt, &doc.CreatedAt, &doc.UpdatedAt,
+		&doc.OrgID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("repository.GetByID: %w", err)
 	}
+	if err := r.keys.OpenPtr(ctx, doc.ExtractedText); err != nil {
+		return nil, fmt.Errorf("repository.GetByID: %w", err)
+	}
 
 	doc.IndexStatus = model.IndexStatus(indexStatus)
 	doc.DeletionStatus = model.DeletionStatus(deletionStatus)
@@ -83,10 +121,39 @@ func (r *DocumentRepo) GetByID(ctx context.Context, id string) (*model.Document,
 	return doc, nil
 }
 
+// DocumentShareRole returns the strongest unexpired share role that reaches
+// userID for the document, directly or through a folder, or "" if none.
+func (r *DocumentRepo) DocumentShareRole(ctx context.Context, docID, userID string) (model.ShareRole, error) {
+	var role string
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE((SELECT role FROM shared_documents($2) WHERE document_id = $1), '')`,
+		docID, userID,
+	).Scan(&role)
+	if err != nil {
+		return "", fmt.Errorf("repository.DocumentShareRole: %w", err)
+	}
+	return model.ShareRole(role), nil
+}
+
+// CountActiveByUser returns the number of non-deleted documents in the user's
+// organization, including privileged ones. Used for the documents_stored
+// quota, which members of an organization share.
+func (r *DocumentRepo) CountActiveByUser(ctx context.Context, userID string) (int64, error) {
+	var n int64
+	err := r.pool.QueryRow(ctx,
+		`SELECT count(*) FROM documents WHERE org_id = user_org_id($1) AND deletion_status = 'Active'`, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("repository.CountActiveByUser: %w", err)
+	}
+	return n, nil
+}
+
+// ListByUser lists the active documents the user may read: its organization's
+// documents and documents shared with it.
 func (r *DocumentRepo) ListByUser(ctx context.Context, userID string, opts service.ListOpts) ([]model.Document, int, error) {
 	// Count total
 	var total int
-	countQuery := `SELECT count(*) FROM documents WHERE user_id = $1 AND deletion_status = 'Active'`
+	countQuery := `SELECT count(*) FROM documents WHERE ` + docVisibleSQL + ` AND deletion_status = 'Active'`
 	args := []interface{}{userID}
 	argIdx := 2 // next placeholder index
 
@@ -100,6 +167,12 @@ func (r *DocumentRepo) ListByUser(ctx context.Context, userID string, opts servi
 		argIdx++
 	}
 
+	if opts.VaultID != "" {
+		countQuery += fmt.Sprintf(` AND vault_id = $%d AND vault_queryable(vault_id, $1)`, argIdx)
+		args = append(args, opts.VaultID)
+		argIdx++
+	}
+
 	err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("repository.ListByUser: count: %w", err)
@@ -126,8 +199,8 @@ func (r *DocumentRepo) ListByUser(ctx context.Context, userID string, opts servi
 		SELECT id, vault_id, user_id, filename, original_name, mime_type, file_type,
 			size_bytes, storage_uri, storage_path, index_status,
 			deletion_status, is_privileged, security_tier, is_starred, chunk_count,
-			folder_id, created_at, updated_at, %s
-		FROM documents WHERE user_id = $1 AND deletion_status = 'Active'`, matchFieldExpr)
+			folder_id, created_at, updated_at, COALESCE(org_id, ''), %s
+		FROM documents WHERE %s AND deletion_status = 'Active'`, matchFieldExpr, docVisibleSQL)
 
 	listArgs := []interface{}{userID}
 	listArgIdx := 2
@@ -144,6 +217,12 @@ func (r *DocumentRepo) ListByUser(ctx context.Context, userID string, opts servi
 		listArgIdx++
 	}
 
+	if opts.VaultID != "" {
+		listQuery += fmt.Sprintf(` AND vault_id = $%d AND vault_queryable(vault_id, $1)`, listArgIdx)
+		listArgs = append(listArgs, opts.VaultID)
+		listArgIdx++
+	}
+
 	listQuery += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, listArgIdx, listArgIdx+1)
 	listArgs = append(listArgs, limit, opts.Offset)
 
@@ -162,7 +241,7 @@ func (r *DocumentRepo) ListByUser(ctx context.Context, userID string, opts servi
 			&d.ID, &d.VaultID, &d.UserID, &d.Filename, &d.OriginalName, &d.MimeType, &d.FileType,
 			&d.SizeBytes, &d.StorageURI, &d.StoragePath, &indexStatus,
 			&deletionStatus, &d.IsPrivileged, &d.SecurityTier, &d.IsStarred, &d.ChunkCount,
-			&d.FolderID, &d.CreatedAt, &d.UpdatedAt, &matchField,
+			&d.FolderID, &d.CreatedAt, &d.UpdatedAt, &d.OrgID, &matchField,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("repository.ListByUser: scan: %w", err)
@@ -187,13 +266,24 @@ func (r *DocumentRepo) UpdateStatus(ctx context.Context, id string, status model
 	return nil
 }
 
+// UpdateText stores the extracted text, sealed with the tenant's data key
+// when a keyring is configured.
 func (r *DocumentRepo) UpdateText(ctx context.Context, id string, text string, pageCount int) error {
+	if r.keys != nil && text != "" {
+		tenants, err := documentTenants(ctx, r.pool, []string{id})
+		if err != nil {
+			return fmt.Errorf("repository.UpdateText: tenant: %w", err)
+		}
+		if text, err = r.keys.Seal(ctx, tenants[id], text); err != nil {
+			return fmt.Errorf("repository.UpdateText: %w", err)
+		}
+	}
 	_, err := r.pool.Exec(ctx,
 		`UPDATE documents SET extracted_text = $1, metadata = jsonb_set(COALESCE(metadata, '{}'), '{page_count}', to_jsonb($2::int)), updated_at = $3 WHERE id = $4`,
 		text, pageCount, time.Now().UTC(), id,
 	)
 	if err != nil {
-		return fmt.Errorf("repository.UpdateText: %w", err)
+		return fmt.Errorf("repository.UpdateText: %w", legalHoldErr(err))
 	}
 	return nil
 }
@@ -228,14 +318,15 @@ func (r *DocumentRepo) SoftDelete(ctx context.Context, id string) error {
 		now, now, id,
 	)
 	if err != nil {
-		return fmt.Errorf("repository.SoftDelete: %w", err)
+		return fmt.Errorf("repository.SoftDelete: %w", legalHoldErr(err))
 	}
 	return nil
 }
 
 func (r *DocumentRepo) Recover(ctx context.Context, id string) error {
 	_, err := r.pool.Exec(ctx,
-		`UPDATE documents SET deletion_status = 'Active', deleted_at = NULL, hard_delete_at = NULL, updated_at = $1 WHERE id = $2`,
+		`UPDATE documents SET deletion_status = 'Active', deleted_at = NULL, hard_delete_at = NULL, updated_at = $1
+		 WHERE id = $2 AND deletion_status = 'SoftDeleted'`,
 		time.Now().UTC(), id,
 	)
 	if err != nil {
@@ -283,7 +374,7 @@ func (r *DocumentRepo) Update(ctx context.Context, id string, name string) error
 		name, time.Now().UTC(), id,
 	)
 	if err != nil {
-		return fmt.Errorf("repository.Update: %w", err)
+		return fmt.Errorf("repository.Update: %w", legalHoldErr(err))
 	}
 	return nil
 }
@@ -294,7 +385,7 @@ func (r *DocumentRepo) UpdateFolder(ctx context.Context, id string, folderID *st
 		folderID, time.Now().UTC(), id,
 	)
 	if err != nil {
-		return fmt.Errorf("repository.UpdateFolder: %w", err)
+		return fmt.Errorf("repository.UpdateFolder: %w", legalHoldErr(err))
 	}
 	return nil
 }
@@ -343,13 +434,13 @@ func (r *DocumentRepo) ListByVault(ctx context.Context, vaultID string) ([]model
 	return docs, nil
 }
 
-// HasProcessingDocuments returns true if the user has any documents with
-// index_status 'Pending' or 'Processing'. (STORY-172)
+// HasProcessingDocuments returns true if the user's organization has any
+// documents with index_status 'Pending' or 'Processing'. (STORY-172)
 func (r *DocumentRepo) HasProcessingDocuments(ctx context.Context, userID string) (bool, error) {
 	var count int
 	err := r.pool.QueryRow(ctx, `
 		SELECT COUNT(*) FROM documents
-		WHERE user_id = $1
+		WHERE org_id = user_org_id($1)
 			AND deletion_status = 'Active'
 			AND index_status IN ('Pending', 'Processing')
 	`, userID).Scan(&count)
@@ -360,12 +451,12 @@ func (r *DocumentRepo) HasProcessingDocuments(ctx context.Context, userID string
 }
 
 // ListUserDocumentSummaries returns lightweight summaries of all active documents
-// for a user. Used by "summarize my documents" queries. (STORY-172)
+// in the user's organization. Used by "summarize my documents" queries. (STORY-172)
 func (r *DocumentRepo) ListUserDocumentSummaries(ctx context.Context, userID string) ([]service.DocSummary, error) {
 	rows, err := r.pool.Query(ctx, `
 		SELECT id, original_name, index_status, created_at
 		FROM documents
-		WHERE user_id = $1 AND deletion_status = 'Active'
+		WHERE org_id = user_org_id($1) AND deletion_status = 'Active'
 		ORDER BY created_at DESC
 		LIMIT 50
 	`, userID)
@@ -34,11 +34,91 @@ func setupDocRepo(t *testing.T) (*DocumentRepo, func()) {
 		pool.Close()
 		t.Fatalf("read migration: %v", err)
 	}
+	orgSQL, err := os.ReadFile("../../migrations/021_organizations.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	vaultSQL, err := os.ReadFile("../../migrations/022_vaults.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	sharingSQL, err := os.ReadFile("../../migrations/023_sharing.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	tokenSQL, err := os.ReadFile("../../migrations/024_api_tokens.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	auditChainSQL, err := os.ReadFile("../../migrations/025_audit_chain.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	checkpointSQL, err := os.ReadFile("../../migrations/026_audit_checkpoints.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	activitySQL, err := os.ReadFile("../../migrations/027_user_activity.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	outboxSQL, err := os.ReadFile("../../migrations/028_audit_outbox.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	legalHoldSQL, err := os.ReadFile("../../migrations/029_legal_holds.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
+	auditHashSQL, err := os.ReadFile("../../migrations/036_audit_hash_all_columns.up.sql")
+	if err != nil {
+		pool.Close()
+		t.Fatalf("read migration: %v", err)
+	}
 
 	ensureSchema := func() error {
 		if _, err := pool.Exec(ctx, string(migrationSQL)); err != nil {
 			return err
 		}
+		if _, err := pool.Exec(ctx, string(orgSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(vaultSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(sharingSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(tokenSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(auditChainSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(checkpointSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(activitySQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(outboxSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(legalHoldSQL)); err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(auditHashSQL)); err != nil {
+			return err
+		}
 		_, err := pool.Exec(ctx, `
 			INSERT INTO users (id, email, role, status, created_at)
 			VALUES ('test-user-doc', 'doctest@ragbox.co', 'Associate', 'Active', now())
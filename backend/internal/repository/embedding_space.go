@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgvector "github.com/pgvector/pgvector-go"
+
+	"github.com/connexus-ai/ragbox-backend/internal/fieldcrypt"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// EmbeddingSpaceRepo implements service.ReembedStore.
+// Shadow rows carry the hash of the chunk they were computed from
+// (embedding_input_hash, computed in Go since content may be sealed, and keyed
+// per tenant when it is), so a chunk re-ingested mid-job is detected as
+// pending and re-embedded before cutover.
+type EmbeddingSpaceRepo struct {
+	pool *pgxpool.Pool
+	keys *fieldcrypt.Keyring // opens sealed content for re-embedding
+}
+
+// NewEmbeddingSpaceRepo creates an EmbeddingSpaceRepo.
+func NewEmbeddingSpaceRepo(pool *pgxpool.Pool) *EmbeddingSpaceRepo {
+	return &EmbeddingSpaceRepo{pool: pool}
+}
+
+// WithKeyring returns a copy of r that decrypts sealed chunk content.
+func (r *EmbeddingSpaceRepo) WithKeyring(keys *fieldcrypt.Keyring) *EmbeddingSpaceRepo {
+	return &EmbeddingSpaceRepo{pool: r.pool, keys: keys}
+}
+
+// Compile-time check.
+var _ service.ReembedStore = (*EmbeddingSpaceRepo)(nil)
+
+// ActiveSpace returns the embedding space used for retrieval.
+// Returns a zero space when none is recorded (pre-018 schema).
+func (r *EmbeddingSpaceRepo) ActiveSpace(ctx context.Context) (model.EmbeddingSpace, error) {
+	var s model.EmbeddingSpace
+	err := r.pool.QueryRow(ctx, `
+		SELECT model, version, dimensions FROM embedding_spaces WHERE status = 'active'`,
+	).Scan(&s.Model, &s.Version, &s.Dimensions)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return model.EmbeddingSpace{}, nil
+		}
+		return model.EmbeddingSpace{}, fmt.Errorf("repository.ActiveSpace: %w", err)
+	}
+	return s, nil
+}
+
+// CreateReembedJob registers a new building space for modelName with the next
+// global version and opens a job to fill it. The live embedding column has a
+// fixed dimension, so a model with different dimensions needs a schema change first.
+func (r *EmbeddingSpaceRepo) CreateReembedJob(ctx context.Context, modelName string, dimensions int) (*model.ReembedJob, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateReembedJob: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var columnDims int
+	err = tx.QueryRow(ctx, `
+		SELECT atttypmod FROM pg_attribute
+		WHERE attrelid = 'document_chunks'::regclass AND attname = 'embedding'`,
+	).Scan(&columnDims)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateReembedJob: column dims: %w", err)
+	}
+	if columnDims > 0 && columnDims != dimensions {
+		return nil, fmt.Errorf("repository.CreateReembedJob: target has %d dimensions but document_chunks.embedding is vector(%d)", dimensions, columnDims)
+	}
+
+	var running int
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM reembed_jobs WHERE status = 'running'`).Scan(&running); err != nil {
+		return nil, fmt.Errorf("repository.CreateReembedJob: running check: %w", err)
+	}
+	if running > 0 {
+		return nil, fmt.Errorf("repository.CreateReembedJob: another re-embed job is already running")
+	}
+
+	job := &model.ReembedJob{
+		ID:     uuid.New().String(),
+		Status: model.ReembedRunning,
+		Target: model.EmbeddingSpace{Model: modelName, Dimensions: dimensions},
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO embedding_spaces (model, version, dimensions, status)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, 'building' FROM embedding_spaces
+		RETURNING version`, modelName, dimensions,
+	).Scan(&job.Target.Version)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateReembedJob: space: %w", err)
+	}
+
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM document_chunks`).Scan(&job.TotalChunks); err != nil {
+		return nil, fmt.Errorf("repository.CreateReembedJob: count: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO reembed_jobs (id, target_model, target_version, dimensions, status, total_chunks)
+		VALUES ($1, $2, $3, $4, 'running', $5)
+		RETURNING created_at, updated_at`,
+		job.ID, modelName, job.Target.Version, dimensions, job.TotalChunks,
+	).Scan(&job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("repository.CreateReembedJob: insert: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.CreateReembedJob: commit: %w", err)
+	}
+	return job, nil
+}
+
+// GetReembedJob returns a job with its current progress.
+func (r *EmbeddingSpaceRepo) GetReembedJob(ctx context.Context, id string) (*model.ReembedJob, error) {
+	j := &model.ReembedJob{}
+	var status string
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, target_model, target_version, dimensions, status, total_chunks,
+		       processed_chunks, last_chunk_id, error, created_at, updated_at, completed_at
+		FROM reembed_jobs WHERE id = $1`, id,
+	).Scan(&j.ID, &j.Target.Model, &j.Target.Version, &j.Target.Dimensions, &status, &j.TotalChunks,
+		&j.ProcessedChunks, &j.LastChunkID, &j.Error, &j.CreatedAt, &j.UpdatedAt, &j.CompletedAt)
+	if err != nil {
+		return nil, fmt.Errorf("repository.GetReembedJob: %w", err)
+	}
+	j.Status = model.ReembedJobStatus(status)
+	return j, nil
+}
+
+// ResumeReembedJob moves a failed job back to running so it continues from its cursor.
+func (r *EmbeddingSpaceRepo) ResumeReembedJob(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE reembed_jobs SET status = 'running', error = NULL, updated_at = now()
+		WHERE id = $1 AND status = 'failed'`, id)
+	if err != nil {
+		return fmt.Errorf("repository.ResumeReembedJob: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("repository.ResumeReembedJob: job %s is not failed", id)
+	}
+	return nil
+}
+
+// NextReembedBatch returns up to limit chunks with id > afterChunkID, in id order.
+func (r *EmbeddingSpaceRepo) NextReembedBatch(ctx context.Context, afterChunkID string, limit int) ([]service.ReembedChunk, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, content
+		FROM document_chunks
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`, afterChunkID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository.NextReembedBatch: %w", err)
+	}
+	return r.scanReembedChunks(ctx, rows, "repository.NextReembedBatch")
+}
+
+// SaveReembedBatch upserts shadow vectors and advances the job's progress.
+func (r *EmbeddingSpaceRepo) SaveReembedBatch(ctx context.Context, jobID string, chunks []service.ReembedChunk, vectors [][]float32) error {
+	if len(chunks) != len(vectors) {
+		return fmt.Errorf("repository.SaveReembedBatch: chunk count (%d) != vector count (%d)", len(chunks), len(vectors))
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.SaveReembedBatch: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	maxID := ""
+	for i, c := range chunks {
+		batch.Queue(`
+			INSERT INTO document_chunk_embeddings_shadow (job_id, chunk_id, content_hash, embedding)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (job_id, chunk_id) DO UPDATE SET
+				content_hash = EXCLUDED.content_hash,
+				embedding = EXCLUDED.embedding,
+				created_at = now()`,
+			jobID, c.ID, c.ContentHash, pgvector.NewVector(vectors[i]),
+		)
+		if c.ID > maxID {
+			maxID = c.ID
+		}
+	}
+	br := tx.SendBatch(ctx, batch)
+	for i := range chunks {
+		if _, err := br.Exec(); err != nil {
+			br.Close()
+			return fmt.Errorf("repository.SaveReembedBatch: chunk %d: %w", i, err)
+		}
+	}
+	if err := br.Close(); err != nil {
+		return fmt.Errorf("repository.SaveReembedBatch: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE reembed_jobs SET
+			processed_chunks = (SELECT count(*) FROM document_chunk_embeddings_shadow WHERE job_id = $1),
+			last_chunk_id = GREATEST(last_chunk_id, $2),
+			updated_at = now()
+		WHERE id = $1`, jobID, maxID)
+	if err != nil {
+		return fmt.Errorf("repository.SaveReembedBatch: progress: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.SaveReembedBatch: commit: %w", err)
+	}
+	return nil
+}
+
+// pendingReembedSQL selects chunks lacking a current shadow vector for job $1.
+const pendingReembedSQL = `
+	FROM document_chunks dc
+	LEFT JOIN document_chunk_embeddings_shadow s
+		ON s.job_id = $1 AND s.chunk_id = dc.id
+	WHERE s.chunk_id IS NULL OR s.content_hash IS DISTINCT FROM dc.embedding_input_hash`
+
+// PendingReembedChunks returns chunks with no shadow vector or one computed from old content.
+func (r *EmbeddingSpaceRepo) PendingReembedChunks(ctx context.Context, jobID string, limit int) ([]service.ReembedChunk, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT dc.id, dc.content`+pendingReembedSQL+`
+		ORDER BY dc.id
+		LIMIT $2`, jobID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository.PendingReembedChunks: %w", err)
+	}
+	return r.scanReembedChunks(ctx, rows, "repository.PendingReembedChunks")
+}
+
+// CutoverReembedJob swaps shadow vectors into document_chunks and activates the
+// job's space in one transaction. Writers to document_chunks are blocked for the
+// duration (readers are not), so no chunk can change between the completeness
+// check and the swap. Returns service.ErrReembedIncomplete if any chunk is pending.
+func (r *EmbeddingSpaceRepo) CutoverReembedJob(ctx context.Context, jobID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.CutoverReembedJob: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status, targetModel string
+	var targetVersion int
+	err = tx.QueryRow(ctx, `
+		SELECT status, target_model, target_version FROM reembed_jobs WHERE id = $1 FOR UPDATE`, jobID,
+	).Scan(&status, &targetModel, &targetVersion)
+	if err != nil {
+		return fmt.Errorf("repository.CutoverReembedJob: %w", err)
+	}
+	if status != string(model.ReembedRunning) {
+		return fmt.Errorf("repository.CutoverReembedJob: job %s is %s", jobID, status)
+	}
+
+	if _, err := tx.Exec(ctx, `LOCK TABLE document_chunks IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("repository.CutoverReembedJob: lock: %w", err)
+	}
+
+	var pending int
+	if err := tx.QueryRow(ctx, `SELECT count(*)`+pendingReembedSQL, jobID).Scan(&pending); err != nil {
+		return fmt.Errorf("repository.CutoverReembedJob: pending: %w", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("repository.CutoverReembedJob: %d chunks: %w", pending, service.ErrReembedIncomplete)
+	}
+
+	steps := []struct {
+		name string
+		sql  string
+		args []interface{}
+	}{
+		{"swap", `
+			UPDATE document_chunks dc SET
+				embedding = s.embedding,
+				embedding_model = $2,
+				embedding_version = $3
+			FROM document_chunk_embeddings_shadow s
+			WHERE s.job_id = $1 AND s.chunk_id = dc.id`, []interface{}{jobID, targetModel, targetVersion}},
+		{"retire", `UPDATE embedding_spaces SET status = 'retired' WHERE status = 'active'`, nil},
+		{"activate", `
+			UPDATE embedding_spaces SET status = 'active', activated_at = now()
+			WHERE model = $1 AND version = $2`, []interface{}{targetModel, targetVersion}},
+		{"shadow", `DELETE FROM document_chunk_embeddings_shadow WHERE job_id = $1`, []interface{}{jobID}},
+		{"complete", `
+			UPDATE reembed_jobs SET status = 'completed', completed_at = now(), updated_at = now()
+			WHERE id = $1`, []interface{}{jobID}},
+	}
+	for _, st := range steps {
+		if _, err := tx.Exec(ctx, st.sql, st.args...); err != nil {
+			return fmt.Errorf("repository.CutoverReembedJob: %s: %w", st.name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.CutoverReembedJob: commit: %w", err)
+	}
+	return nil
+}
+
+// FailReembedJob marks a job failed. Shadow vectors are kept for ResumeReembedJob.
+func (r *EmbeddingSpaceRepo) FailReembedJob(ctx context.Context, jobID, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE reembed_jobs SET status = 'failed', error = $2, updated_at = now()
+		WHERE id = $1 AND status = 'running'`, jobID, reason)
+	if err != nil {
+		return fmt.Errorf("repository.FailReembedJob: %w", err)
+	}
+	return nil
+}
+
+// StaleSpaceChunks returns embedded chunks stamped with a space other than space.
+func (r *EmbeddingSpaceRepo) StaleSpaceChunks(ctx context.Context, space model.EmbeddingSpace, limit int) ([]service.ReembedChunk, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, content
+		FROM document_chunks
+		WHERE embedding IS NOT NULL
+			AND (embedding_model IS DISTINCT FROM $1 OR embedding_version IS DISTINCT FROM $2)
+		ORDER BY id
+		LIMIT $3`, space.Model, space.Version, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository.StaleSpaceChunks: %w", err)
+	}
+	return r.scanReembedChunks(ctx, rows, "repository.StaleSpaceChunks")
+}
+
+// UpdateChunkEmbeddings overwrites chunk vectors in place and stamps them with space.
+// Chunks whose content changed since they were read are left for their writer.
+func (r *EmbeddingSpaceRepo) UpdateChunkEmbeddings(ctx context.Context, space model.EmbeddingSpace, chunks []service.ReembedChunk, vectors [][]float32) error {
+	if len(chunks) != len(vectors) {
+		return fmt.Errorf("repository.UpdateChunkEmbeddings: chunk count (%d) != vector count (%d)", len(chunks), len(vectors))
+	}
+
+	batch := &pgx.Batch{}
+	for i, c := range chunks {
+		batch.Queue(`
+			UPDATE document_chunks SET embedding = $1, embedding_model = $2, embedding_version = $3
+			WHERE id = $4 AND embedding_input_hash = $5`,
+			pgvector.NewVector(vectors[i]), space.Model, space.Version, c.ID, c.ContentHash,
+		)
+	}
+
+	br := r.pool.SendBatch(ctx, batch)
+	defer br.Close()
+	for i := range chunks {
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("repository.UpdateChunkEmbeddings: chunk %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (r *EmbeddingSpaceRepo) scanReembedChunks(ctx context.Context, rows pgx.Rows, op string) ([]service.ReembedChunk, error) {
+	defer rows.Close()
+	var chunks []service.ReembedChunk
+	for rows.Next() {
+		var c service.ReembedChunk
+		if err := rows.Scan(&c.ID, &c.Content); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		content, err := r.keys.Open(ctx, c.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: chunk %s: %w", op, c.ID, err)
+		}
+		c.ContentHash = service.EmbeddingInputHash(content)
+		// Match BulkInsert: sealed chunks store the tenant-keyed hash.
+		if tenant := fieldcrypt.SealedTenant(c.Content); tenant != "" {
+			if c.ContentHash, err = r.keys.Hash(ctx, tenant, c.ContentHash); err != nil {
+				return nil, fmt.Errorf("%s: chunk %s: %w", op, c.ID, err)
+			}
+		}
+		c.Content = content
+		chunks = append(chunks, c)
+	}
+	return chunks, rows.Err()
+}
@@ -25,8 +25,9 @@ var _ service.FolderRepository = (*FolderRepo)(nil)
 
 func (r *FolderRepo) Create(ctx context.Context, folder *model.Folder) error {
 	_, err := r.pool.Exec(ctx,
-		`INSERT INTO folders (id, name, user_id, parent_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`,
-		folder.ID, folder.Name, folder.UserID, folder.ParentID, folder.CreatedAt, folder.UpdatedAt,
+		`INSERT INTO folders (id, name, user_id, parent_id, created_at, updated_at, org_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), user_org_id($3)))`,
+		folder.ID, folder.Name, folder.UserID, folder.ParentID, folder.CreatedAt, folder.UpdatedAt, folder.OrgID,
 	)
 	if err != nil {
 		return fmt.Errorf("repository.FolderCreate: %w", err)
@@ -34,9 +35,14 @@ func (r *FolderRepo) Create(ctx context.Context, folder *model.Folder) error {
 	return nil
 }
 
+// ListByUser lists the folders of the user's organization and the folders
+// shared with the user.
 func (r *FolderRepo) ListByUser(ctx context.Context, userID string) ([]model.Folder, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, name, user_id, parent_id, created_at, updated_at FROM folders WHERE user_id = $1 ORDER BY name`,
+		`SELECT id, name, user_id, COALESCE(org_id, ''), parent_id, created_at, updated_at
+		 FROM folders
+		 WHERE org_id = user_org_id($1) OR id IN (SELECT folder_id FROM shared_folders($1))
+		 ORDER BY name`,
 		userID,
 	)
 	if err != nil {
@@ -47,7 +53,7 @@ func (r *FolderRepo) ListByUser(ctx context.Context, userID string) ([]model.Fol
 	var folders []model.Folder
 	for rows.Next() {
 		var f model.Folder
-		if err := rows.Scan(&f.ID, &f.Name, &f.UserID, &f.ParentID, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		if err := rows.Scan(&f.ID, &f.Name, &f.UserID, &f.OrgID, &f.ParentID, &f.CreatedAt, &f.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("repository.FolderListByUser scan: %w", err)
 		}
 		folders = append(folders, f)
@@ -58,9 +64,9 @@ func (r *FolderRepo) ListByUser(ctx context.Context, userID string) ([]model.Fol
 func (r *FolderRepo) GetByID(ctx context.Context, id string) (*model.Folder, error) {
 	var f model.Folder
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, name, user_id, parent_id, created_at, updated_at FROM folders WHERE id = $1`,
+		`SELECT id, name, user_id, COALESCE(org_id, ''), parent_id, created_at, updated_at FROM folders WHERE id = $1`,
 		id,
-	).Scan(&f.ID, &f.Name, &f.UserID, &f.ParentID, &f.CreatedAt, &f.UpdatedAt)
+	).Scan(&f.ID, &f.Name, &f.UserID, &f.OrgID, &f.ParentID, &f.CreatedAt, &f.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("repository.FolderGetByID: %w", err)
 	}
@@ -70,7 +76,7 @@ func (r *FolderRepo) GetByID(ctx context.Context, id string) (*model.Folder, err
 func (r *FolderRepo) Delete(ctx context.Context, id string) error {
 	_, err := r.pool.Exec(ctx, `DELETE FROM folders WHERE id = $1`, id)
 	if err != nil {
-		return fmt.Errorf("repository.FolderDelete: %w", err)
+		return fmt.Errorf("repository.FolderDelete: %w", legalHoldErr(err))
 	}
 	return nil
 }
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// legalHoldSQLState is raised by the legal hold triggers (migration 029).
+const legalHoldSQLState = "LH001"
+
+// legalHoldErr maps a change the legal hold triggers refused to
+// service.ErrUnderLegalHold, keeping the trigger's message.
+func legalHoldErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == legalHoldSQLState {
+		return fmt.Errorf("%w: %s", service.ErrUnderLegalHold, pgErr.Message)
+	}
+	return err
+}
+
+// LegalHoldRepo implements service.LegalHoldRepository with pgx.
+type LegalHoldRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewLegalHoldRepo creates a LegalHoldRepo.
+func NewLegalHoldRepo(pool *pgxpool.Pool) *LegalHoldRepo {
+	return &LegalHoldRepo{pool: pool}
+}
+
+// Compile-time check.
+var _ service.LegalHoldRepository = (*LegalHoldRepo)(nil)
+
+// legalHoldSelect selects holds with their scopes as parallel arrays.
+const legalHoldSelect = `
+	SELECT h.id, h.org_id, h.name, h.matter_id, h.custodian, h.created_by, h.created_at,
+		h.released_by, h.released_at, h.release_reason,
+		COALESCE(array_agg(s.resource_type ORDER BY s.resource_type, s.resource_id) FILTER (WHERE s.hold_id IS NOT NULL), '{}'),
+		COALESCE(array_agg(s.resource_id ORDER BY s.resource_type, s.resource_id) FILTER (WHERE s.hold_id IS NOT NULL), '{}')
+	FROM legal_holds h
+	LEFT JOIN legal_hold_scopes s ON s.hold_id = h.id`
+
+func scanLegalHold(row pgx.Row) (*model.LegalHold, error) {
+	var h model.LegalHold
+	var types, ids []string
+	err := row.Scan(&h.ID, &h.OrgID, &h.Name, &h.MatterID, &h.Custodian, &h.CreatedBy, &h.CreatedAt,
+		&h.ReleasedBy, &h.ReleasedAt, &h.ReleaseReason, &types, &ids)
+	if err != nil {
+		return nil, err
+	}
+	h.Scopes = make([]model.LegalHoldScope, len(types))
+	for i := range types {
+		h.Scopes[i] = model.LegalHoldScope{ResourceType: model.LegalHoldResourceType(types[i]), ResourceID: ids[i]}
+	}
+	return &h, nil
+}
+
+// CreateHold inserts the hold and its scopes in one transaction.
+func (r *LegalHoldRepo) CreateHold(ctx context.Context, h *model.LegalHold) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.LegalHoldCreate: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO legal_holds (id, org_id, name, matter_id, custodian, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, h.ID, h.OrgID, h.Name, h.MatterID, h.Custodian, h.CreatedBy, h.CreatedAt); err != nil {
+		return fmt.Errorf("repository.LegalHoldCreate: insert: %w", err)
+	}
+
+	types := make([]string, len(h.Scopes))
+	ids := make([]string, len(h.Scopes))
+	for i, sc := range h.Scopes {
+		types[i], ids[i] = string(sc.ResourceType), sc.ResourceID
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO legal_hold_scopes (hold_id, resource_type, resource_id)
+		SELECT $1, t, id FROM unnest($2::text[], $3::text[]) AS s(t, id)
+		ON CONFLICT DO NOTHING
+	`, h.ID, types, ids); err != nil {
+		return fmt.Errorf("repository.LegalHoldCreate: scopes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.LegalHoldCreate: commit: %w", err)
+	}
+	return nil
+}
+
+func (r *LegalHoldRepo) GetHold(ctx context.Context, id string) (*model.LegalHold, error) {
+	h, err := scanLegalHold(r.pool.QueryRow(ctx, legalHoldSelect+`
+		WHERE h.id = $1
+		GROUP BY h.id`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository.LegalHoldGet: %w", err)
+	}
+	return h, nil
+}
+
+func (r *LegalHoldRepo) ListHolds(ctx context.Context, orgID string) ([]model.LegalHold, error) {
+	rows, err := r.pool.Query(ctx, legalHoldSelect+`
+		WHERE h.org_id = $1
+		GROUP BY h.id
+		ORDER BY h.created_at DESC`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.LegalHoldList: %w", err)
+	}
+	defer rows.Close()
+
+	holds := []model.LegalHold{}
+	for rows.Next() {
+		h, err := scanLegalHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.LegalHoldList: scan: %w", err)
+		}
+		holds = append(holds, *h)
+	}
+	return holds, rows.Err()
+}
+
+func (r *LegalHoldRepo) ReleaseHold(ctx context.Context, id, releasedBy, reason string, at time.Time) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE legal_holds SET released_by = $2, released_at = $3, release_reason = $4
+		WHERE id = $1 AND released_at IS NULL
+	`, id, releasedBy, at, reason)
+	if err != nil {
+		return false, fmt.Errorf("repository.LegalHoldRelease: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *LegalHoldRepo) ResourceOrg(ctx context.Context, resourceType model.LegalHoldResourceType, id string) (string, error) {
+	var table string
+	switch resourceType {
+	case model.LegalHoldDocument:
+		table = "documents"
+	case model.LegalHoldFolder:
+		table = "folders"
+	case model.LegalHoldVault:
+		table = "vaults"
+	default:
+		return "", fmt.Errorf("repository.LegalHoldResourceOrg: unknown resource type %q", resourceType)
+	}
+	var orgID *string
+	err := r.pool.QueryRow(ctx, `SELECT org_id FROM `+table+` WHERE id = $1`, id).Scan(&orgID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && orgID == nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("repository.LegalHoldResourceOrg: %w", err)
+	}
+	return *orgID, nil
+}
+
+// heldByColumns selects the holds returned by DocumentHolds and FolderHolds.
+const heldByColumns = `h.id, h.org_id, h.name, h.matter_id, h.custodian, h.created_by, h.created_at`
+
+func (r *LegalHoldRepo) DocumentHolds(ctx context.Context, docIDs []string) (map[string][]model.LegalHold, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT d.id, `+heldByColumns+`
+		FROM unnest($1::text[]) AS d(id)
+		CROSS JOIN LATERAL document_legal_holds(d.id) dh
+		JOIN legal_holds h ON h.id = dh.hold_id
+		ORDER BY h.created_at`, docIDs)
+	if err != nil {
+		return nil, fmt.Errorf("repository.LegalHoldDocumentHolds: %w", err)
+	}
+	defer rows.Close()
+
+	held := map[string][]model.LegalHold{}
+	for rows.Next() {
+		var docID string
+		var h model.LegalHold
+		if err := rows.Scan(&docID, &h.ID, &h.OrgID, &h.Name, &h.MatterID, &h.Custodian, &h.CreatedBy, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repository.LegalHoldDocumentHolds: scan: %w", err)
+		}
+		held[docID] = append(held[docID], h)
+	}
+	return held, rows.Err()
+}
+
+func (r *LegalHoldRepo) FolderHolds(ctx context.Context, folderID string) ([]model.LegalHold, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+heldByColumns+`
+		FROM legal_holds h
+		WHERE h.id IN (SELECT hold_id FROM folder_legal_holds($1))
+		ORDER BY h.created_at`, folderID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.LegalHoldFolderHolds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []model.LegalHold
+	for rows.Next() {
+		var h model.LegalHold
+		if err := rows.Scan(&h.ID, &h.OrgID, &h.Name, &h.MatterID, &h.Custodian, &h.CreatedBy, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repository.LegalHoldFolderHolds: scan: %w", err)
+		}
+		holds = append(holds, h)
+	}
+	return holds, rows.Err()
+}
+
+// HeldDocuments lists the documents the hold's scopes cover, including
+// soft-deleted ones, whether or not the hold is still active.
+func (r *LegalHoldRepo) HeldDocuments(ctx context.Context, holdID string) ([]model.HeldDocument, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH RECURSIVE scopes AS (
+			SELECT resource_type, resource_id FROM legal_hold_scopes WHERE hold_id = $1
+		), held_folders AS (
+			SELECT f.id FROM folders f JOIN scopes s ON s.resource_type = 'folder' AND s.resource_id = f.id
+			UNION
+			SELECT f.id FROM folders f JOIN held_folders hf ON f.parent_id = hf.id
+		)
+		SELECT d.id, d.original_name, d.user_id, d.folder_id, d.vault_id, d.deletion_status,
+			d.size_bytes, d.chunk_count, d.checksum, d.created_at
+		FROM documents d
+		WHERE d.id IN (SELECT resource_id FROM scopes WHERE resource_type = 'document')
+			OR d.folder_id IN (SELECT id FROM held_folders)
+			OR d.vault_id IN (SELECT resource_id FROM scopes WHERE resource_type = 'vault')
+		ORDER BY d.created_at, d.id`, holdID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.LegalHoldHeldDocuments: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []model.HeldDocument
+	for rows.Next() {
+		var d model.HeldDocument
+		if err := rows.Scan(&d.ID, &d.OriginalName, &d.UserID, &d.FolderID, &d.VaultID, &d.DeletionStatus,
+			&d.SizeBytes, &d.ChunkCount, &d.Checksum, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repository.LegalHoldHeldDocuments: scan: %w", err)
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
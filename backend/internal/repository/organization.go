@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// OrgRepo implements service.OrganizationRepository with pgx.
+type OrgRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewOrgRepo creates an OrgRepo.
+func NewOrgRepo(pool *pgxpool.Pool) *OrgRepo {
+	return &OrgRepo{pool: pool}
+}
+
+// Compile-time check.
+var _ service.OrganizationRepository = (*OrgRepo)(nil)
+
+// Create inserts the organization and its founding Partner, and moves the
+// creator's personal documents, vaults and folders into it, in one transaction.
+func (r *OrgRepo) Create(ctx context.Context, org *model.Organization, creatorID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.OrgCreate: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO organizations (id, name, subscription_tier, created_by, created_at, updated_at)
+		SELECT $1, $2, COALESCE(subscription_tier::text, 'free'), $3, $4, $4
+		FROM users WHERE id = $3
+		RETURNING subscription_tier
+	`, org.ID, org.Name, creatorID, org.CreatedAt).Scan(&org.SubscriptionTier)
+	if err != nil {
+		return fmt.Errorf("repository.OrgCreate: insert: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO organization_members (org_id, user_id, role, joined_at)
+		VALUES ($1, $2, 'Partner', $3)
+	`, org.ID, creatorID, org.CreatedAt); err != nil {
+		return fmt.Errorf("repository.OrgCreate: member: %w", err)
+	}
+
+	if err := moveScope(ctx, tx, model.PersonalOrgID(creatorID), org.ID); err != nil {
+		return fmt.Errorf("repository.OrgCreate: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.OrgCreate: commit: %w", err)
+	}
+	return nil
+}
+
+func (r *OrgRepo) GetByID(ctx context.Context, id string) (*model.Organization, error) {
+	var o model.Organization
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, name, subscription_tier, created_by, created_at, updated_at
+		FROM organizations WHERE id = $1
+	`, id).Scan(&o.ID, &o.Name, &o.SubscriptionTier, &o.CreatedBy, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("repository.OrgGetByID: %w", err)
+	}
+	return &o, nil
+}
+
+func (r *OrgRepo) Rename(ctx context.Context, id, name string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE organizations SET name = $2, updated_at = NOW() WHERE id = $1`, id, name)
+	if err != nil {
+		return fmt.Errorf("repository.OrgRename: %w", err)
+	}
+	return nil
+}
+
+// Membership returns the user's membership, or nil if they have none.
+func (r *OrgRepo) Membership(ctx context.Context, userID string) (*model.OrgMember, error) {
+	var m model.OrgMember
+	var role string
+	err := r.pool.QueryRow(ctx, `
+		SELECT m.org_id, m.user_id, COALESCE(u.email, ''), u.name, m.role, m.joined_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.user_id = $1
+	`, userID).Scan(&m.OrgID, &m.UserID, &m.Email, &m.Name, &role, &m.JoinedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository.OrgMembership: %w", err)
+	}
+	m.Role = model.UserRole(role)
+	return &m, nil
+}
+
+func (r *OrgRepo) ListMembers(ctx context.Context, orgID string) ([]model.OrgMember, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT m.org_id, m.user_id, COALESCE(u.email, ''), u.name, m.role, m.joined_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = $1
+		ORDER BY m.joined_at
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.OrgListMembers: %w", err)
+	}
+	defer rows.Close()
+
+	var members []model.OrgMember
+	for rows.Next() {
+		var m model.OrgMember
+		var role string
+		if err := rows.Scan(&m.OrgID, &m.UserID, &m.Email, &m.Name, &role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("repository.OrgListMembers: scan: %w", err)
+		}
+		m.Role = model.UserRole(role)
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (r *OrgRepo) SetMemberRole(ctx context.Context, orgID, userID string, role model.UserRole) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE organization_members SET role = $3::"UserRole" WHERE org_id = $1 AND user_id = $2
+	`, orgID, userID, string(role))
+	if err != nil {
+		return fmt.Errorf("repository.OrgSetMemberRole: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember deletes the membership and, in the same transaction, hands the
+// member's documents, vaults and folders in the organization to its earliest
+// remaining Partner. The content stays with the organization; without the
+// transfer it would be owned by someone who can no longer reach it.
+func (r *OrgRepo) RemoveMember(ctx context.Context, orgID, userID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.OrgRemoveMember: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM organization_members WHERE org_id = $1 AND user_id = $2`, orgID, userID); err != nil {
+		return fmt.Errorf("repository.OrgRemoveMember: %w", err)
+	}
+
+	var successor string
+	err = tx.QueryRow(ctx, `
+		SELECT user_id FROM organization_members
+		WHERE org_id = $1 AND role = 'Partner'
+		ORDER BY joined_at
+		LIMIT 1
+	`, orgID).Scan(&successor)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("repository.OrgRemoveMember: successor: %w", err)
+	}
+	if successor != "" {
+		for _, table := range scopedTables {
+			if _, err := tx.Exec(ctx,
+				`UPDATE `+table+` SET user_id = $3 WHERE org_id = $1 AND user_id = $2`,
+				orgID, userID, successor); err != nil {
+				return fmt.Errorf("repository.OrgRemoveMember: transfer %s: %w", table, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.OrgRemoveMember: commit: %w", err)
+	}
+	return nil
+}
+
+func (r *OrgRepo) CreateInvite(ctx context.Context, inv *model.OrgInvite, tokenHash string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO organization_invites (id, org_id, email, role, token_hash, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4::"UserRole", $5, $6, $7, $8)
+	`, inv.ID, inv.OrgID, inv.Email, string(inv.Role), tokenHash, inv.InvitedBy, inv.ExpiresAt, inv.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("repository.OrgCreateInvite: %w", err)
+	}
+	return nil
+}
+
+// ListInvites returns the organization's pending invites, newest first.
+func (r *OrgRepo) ListInvites(ctx context.Context, orgID string) ([]model.OrgInvite, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, org_id, email, role, invited_by, expires_at, accepted_by, accepted_at, created_at
+		FROM organization_invites
+		WHERE org_id = $1 AND accepted_at IS NULL
+		ORDER BY created_at DESC
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.OrgListInvites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []model.OrgInvite
+	for rows.Next() {
+		inv, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.OrgListInvites: scan: %w", err)
+		}
+		invites = append(invites, *inv)
+	}
+	return invites, rows.Err()
+}
+
+func (r *OrgRepo) RevokeInvite(ctx context.Context, orgID, inviteID string) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM organization_invites WHERE id = $1::uuid AND org_id = $2 AND accepted_at IS NULL
+	`, inviteID, orgID)
+	if err != nil {
+		return fmt.Errorf("repository.OrgRevokeInvite: %w", err)
+	}
+	return nil
+}
+
+// InviteByTokenHash returns the pending invite with the token hash, or nil.
+func (r *OrgRepo) InviteByTokenHash(ctx context.Context, tokenHash string) (*model.OrgInvite, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT id, org_id, email, role, invited_by, expires_at, accepted_by, accepted_at, created_at
+		FROM organization_invites
+		WHERE token_hash = $1 AND accepted_at IS NULL
+	`, tokenHash)
+	inv, err := scanInvite(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository.OrgInviteByTokenHash: %w", err)
+	}
+	return inv, nil
+}
+
+// AcceptInvite marks the invite used and adds the member in one transaction.
+// With moveContent their personal documents, vaults and folders move into the
+// organization too, as Create does for the founder; otherwise they stay in the
+// personal scope. The conditional update makes a concurrent second accept fail.
+func (r *OrgRepo) AcceptInvite(ctx context.Context, inviteID, userID string, moveContent bool) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.OrgAcceptInvite: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var orgID, role string
+	err = tx.QueryRow(ctx, `
+		UPDATE organization_invites
+		SET accepted_by = $2, accepted_at = NOW()
+		WHERE id = $1::uuid AND accepted_at IS NULL AND expires_at > NOW()
+		RETURNING org_id, role
+	`, inviteID, userID).Scan(&orgID, &role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return service.ErrInviteInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("repository.OrgAcceptInvite: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, $3::"UserRole")
+	`, orgID, userID, role); err != nil {
+		return fmt.Errorf("repository.OrgAcceptInvite: member: %w", err)
+	}
+	if moveContent {
+		if err := moveScope(ctx, tx, model.PersonalOrgID(userID), orgID); err != nil {
+			return fmt.Errorf("repository.OrgAcceptInvite: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.OrgAcceptInvite: commit: %w", err)
+	}
+	return nil
+}
+
+// UsageAccount returns the org ID for members, else the user ID.
+func (r *OrgRepo) UsageAccount(ctx context.Context, userID string) (string, error) {
+	var account string
+	err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE((SELECT org_id FROM organization_members WHERE user_id = $1), $1)
+	`, userID).Scan(&account)
+	if err != nil {
+		return "", fmt.Errorf("repository.OrgUsageAccount: %w", err)
+	}
+	return account, nil
+}
+
+// BillingEmail returns where usage alerts for a usage account go: the user's
+// email, or for an organization the email of its earliest Partner.
+func (r *OrgRepo) BillingEmail(ctx context.Context, accountID string) (string, error) {
+	var email string
+	err := r.pool.QueryRow(ctx, `
+		SELECT email FROM users WHERE id = $1
+		UNION ALL
+		(SELECT u.email FROM organization_members m
+		 JOIN users u ON u.id = m.user_id
+		 WHERE m.org_id = $1 AND m.role = 'Partner'
+		 ORDER BY m.joined_at
+		 LIMIT 1)
+		LIMIT 1
+	`, accountID).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("repository.OrgBillingEmail: %w", err)
+	}
+	return email, nil
+}
+
+// scopedTables hold content scoped by org_id and owned by user_id.
+var scopedTables = []string{"documents", "vaults", "folders"}
+
+// moveScope re-scopes every document, vault and folder from one org_id to
+// another within tx.
+func moveScope(ctx context.Context, tx pgx.Tx, from, to string) error {
+	for _, table := range scopedTables {
+		if _, err := tx.Exec(ctx,
+			`UPDATE `+table+` SET org_id = $1 WHERE org_id = $2`, to, from); err != nil {
+			return fmt.Errorf("move %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func scanInvite(row pgx.Row) (*model.OrgInvite, error) {
+	var inv model.OrgInvite
+	var role string
+	err := row.Scan(&inv.ID, &inv.OrgID, &inv.Email, &role, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.AcceptedBy, &inv.AcceptedAt, &inv.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	inv.Role = model.UserRole(role)
+	return &inv, nil
+}
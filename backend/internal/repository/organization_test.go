@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func TestOrgRepo_ContentIsolation(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	pool := docRepo.pool
+	orgRepo := NewOrgRepo(pool)
+
+	suffix := uuid.New().String()[:8]
+	partner, member, outsider := "org-p-"+suffix, "org-m-"+suffix, "org-x-"+suffix
+	for _, id := range []string{partner, member, outsider} {
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO users (id, email, role, status, created_at)
+			VALUES ($1, $1 || '@ragbox.co', 'Associate', 'Active', now())
+		`, id); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+	}
+
+	// A personal document moves into the organization when it is founded.
+	doc := newTestDoc(partner)
+	if err := docRepo.Create(ctx, doc); err != nil {
+		t.Fatalf("Create doc: %v", err)
+	}
+	org := &model.Organization{ID: uuid.New().String(), Name: "Acme " + suffix, CreatedAt: time.Now().UTC()}
+	if err := orgRepo.Create(ctx, org, partner); err != nil {
+		t.Fatalf("Create org: %v", err)
+	}
+	got, err := docRepo.GetByID(ctx, doc.ID)
+	if err != nil || got.OrgID != org.ID {
+		t.Fatalf("doc org = %q, %v; want %q", got.OrgID, err, org.ID)
+	}
+
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, 'Associate')
+	`, org.ID, member); err != nil {
+		t.Fatalf("insert member: %v", err)
+	}
+
+	opts := service.ListOpts{Limit: 50, PrivilegeMode: true}
+	memberDocs, _, err := docRepo.ListByUser(ctx, member, opts)
+	if err != nil {
+		t.Fatalf("ListByUser(member): %v", err)
+	}
+	if len(memberDocs) != 1 || memberDocs[0].ID != doc.ID {
+		t.Errorf("member sees %d docs, want the org document", len(memberDocs))
+	}
+
+	// The web app inserts through Prisma without org_id; the row still
+	// lands in the owner's organization.
+	prismaDocID := uuid.New().String()
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO documents (id, user_id, filename, original_name, mime_type, file_type, size_bytes)
+		VALUES ($1, $2, 'ingest.txt', 'ingest.txt', 'text/plain', 'txt', 1)
+	`, prismaDocID, member); err != nil {
+		t.Fatalf("insert document without org_id: %v", err)
+	}
+	prismaDoc, err := docRepo.GetByID(ctx, prismaDocID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if prismaDoc.OrgID != org.ID {
+		t.Errorf("document inserted without org_id has org %q, want %q", prismaDoc.OrgID, org.ID)
+	}
+
+	outsiderDocs, _, err := docRepo.ListByUser(ctx, outsider, opts)
+	if err != nil {
+		t.Fatalf("ListByUser(outsider): %v", err)
+	}
+	if len(outsiderDocs) != 0 {
+		t.Errorf("outsider sees %d org documents, want 0", len(outsiderDocs))
+	}
+
+	account, err := orgRepo.UsageAccount(ctx, member)
+	if err != nil || account != org.ID {
+		t.Errorf("UsageAccount(member) = %q, %v; want %q", account, err, org.ID)
+	}
+	if account, _ := orgRepo.UsageAccount(ctx, outsider); account != outsider {
+		t.Errorf("UsageAccount(outsider) = %q, want %q", account, outsider)
+	}
+}
+
+func TestOrgRepo_AcceptInviteAndRemoveMemberMoveContent(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	pool := docRepo.pool
+	orgRepo := NewOrgRepo(pool)
+	folderRepo := NewFolderRepo(pool)
+
+	suffix := uuid.New().String()[:8]
+	partner, invitee, keeper := "org-p-"+suffix, "org-i-"+suffix, "org-k-"+suffix
+	for _, id := range []string{partner, invitee, keeper} {
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO users (id, email, role, status, created_at)
+			VALUES ($1, $1 || '@ragbox.co', 'Associate', 'Active', now())
+		`, id); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+	}
+	org := &model.Organization{ID: uuid.New().String(), Name: "Acme " + suffix, CreatedAt: time.Now().UTC()}
+	if err := orgRepo.Create(ctx, org, partner); err != nil {
+		t.Fatalf("Create org: %v", err)
+	}
+
+	// Without opting in, an invitee's personal content stays personal.
+	kept := newTestDoc(keeper)
+	if err := docRepo.Create(ctx, kept); err != nil {
+		t.Fatalf("Create doc: %v", err)
+	}
+	keeperInv := &model.OrgInvite{
+		ID: uuid.New().String(), OrgID: org.ID, Email: keeper + "@ragbox.co", Role: model.UserRoleAssociate,
+		InvitedBy: partner, ExpiresAt: time.Now().UTC().Add(time.Hour), CreatedAt: time.Now().UTC(),
+	}
+	if err := orgRepo.CreateInvite(ctx, keeperInv, "hash-k-"+suffix); err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+	if err := orgRepo.AcceptInvite(ctx, keeperInv.ID, keeper, false); err != nil {
+		t.Fatalf("AcceptInvite: %v", err)
+	}
+	if got, err := docRepo.GetByID(ctx, kept.ID); err != nil || got.OrgID != model.PersonalOrgID(keeper) {
+		t.Fatalf("doc org after accept = %v, %v; want personal scope", got, err)
+	}
+
+	// An invitee who opts in brings their personal content into the organization.
+	doc := newTestDoc(invitee)
+	if err := docRepo.Create(ctx, doc); err != nil {
+		t.Fatalf("Create doc: %v", err)
+	}
+	now := time.Now().UTC()
+	folder := &model.Folder{ID: uuid.New().String(), Name: "Matters", UserID: invitee, CreatedAt: now, UpdatedAt: now}
+	if err := folderRepo.Create(ctx, folder); err != nil {
+		t.Fatalf("Create folder: %v", err)
+	}
+	inv := &model.OrgInvite{
+		ID: uuid.New().String(), OrgID: org.ID, Email: invitee + "@ragbox.co", Role: model.UserRoleAssociate,
+		InvitedBy: partner, ExpiresAt: now.Add(time.Hour), CreatedAt: now,
+	}
+	if err := orgRepo.CreateInvite(ctx, inv, "hash-"+suffix); err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+	if err := orgRepo.AcceptInvite(ctx, inv.ID, invitee, true); err != nil {
+		t.Fatalf("AcceptInvite: %v", err)
+	}
+	if got, err := docRepo.GetByID(ctx, doc.ID); err != nil || got.OrgID != org.ID {
+		t.Fatalf("doc org after accept = %v, %v; want %q", got, err, org.ID)
+	}
+	if got, err := folderRepo.GetByID(ctx, folder.ID); err != nil || got.OrgID != org.ID {
+		t.Fatalf("folder org after accept = %v, %v; want %q", got, err, org.ID)
+	}
+
+	// On removal the content stays in the organization under a Partner.
+	if err := orgRepo.RemoveMember(ctx, org.ID, invitee); err != nil {
+		t.Fatalf("RemoveMember: %v", err)
+	}
+	got, err := docRepo.GetByID(ctx, doc.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.OrgID != org.ID || got.UserID != partner {
+		t.Errorf("doc after removal = org %q owner %q, want org %q owner %q", got.OrgID, got.UserID, org.ID, partner)
+	}
+	if f, err := folderRepo.GetByID(ctx, folder.ID); err != nil || f.UserID != partner {
+		t.Errorf("folder owner after removal = %v, %v; want %q", f, err, partner)
+	}
+	if m, _ := orgRepo.Membership(ctx, invitee); m != nil {
+		t.Errorf("membership after removal = %+v, want nil", m)
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// PIIPolicyRepo implements service.PIIPolicyRepository with pgx.
+type PIIPolicyRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPIIPolicyRepo creates a PIIPolicyRepo.
+func NewPIIPolicyRepo(pool *pgxpool.Pool) *PIIPolicyRepo {
+	return &PIIPolicyRepo{pool: pool}
+}
+
+// Compile-time check.
+var _ service.PIIPolicyRepository = (*PIIPolicyRepo)(nil)
+
+func (r *PIIPolicyRepo) VaultPolicies(ctx context.Context, vaultIDs []string) (map[string]model.PIIRedactionPolicy, error) {
+	policies := make(map[string]model.PIIRedactionPolicy)
+	if len(vaultIDs) == 0 {
+		return policies, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT vault_id, info_types, exempt_roles, updated_by, updated_at
+		FROM vault_pii_policies
+		WHERE vault_id = ANY($1)`, vaultIDs)
+	if err != nil {
+		return nil, fmt.Errorf("repository.PIIVaultPolicies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p model.PIIRedactionPolicy
+		var roles []string
+		if err := rows.Scan(&p.VaultID, &p.InfoTypes, &roles, &p.UpdatedBy, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("repository.PIIVaultPolicies: scan: %w", err)
+		}
+		p.ExemptRoles = make([]model.UserRole, len(roles))
+		for i, role := range roles {
+			p.ExemptRoles[i] = model.UserRole(role)
+		}
+		policies[p.VaultID] = p
+	}
+	return policies, rows.Err()
+}
+
+func (r *PIIPolicyRepo) UpsertPolicy(ctx context.Context, p *model.PIIRedactionPolicy) error {
+	roles := make([]string, len(p.ExemptRoles))
+	for i, role := range p.ExemptRoles {
+		roles[i] = string(role)
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO vault_pii_policies (vault_id, info_types, exempt_roles, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (vault_id) DO UPDATE
+			SET info_types = EXCLUDED.info_types,
+			    exempt_roles = EXCLUDED.exempt_roles,
+			    updated_by = EXCLUDED.updated_by,
+			    updated_at = EXCLUDED.updated_at`,
+		p.VaultID, p.InfoTypes, roles, p.UpdatedBy, p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("repository.PIIUpsertPolicy: %w", err)
+	}
+	return nil
+}
+
+func (r *PIIPolicyRepo) DeletePolicy(ctx context.Context, vaultID string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM vault_pii_policies WHERE vault_id = $1`, vaultID)
+	if err != nil {
+		return false, fmt.Errorf("repository.PIIDeletePolicy: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
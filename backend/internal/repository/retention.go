@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// RetentionRepo implements service.RetentionRepository with pgx.
+type RetentionRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewRetentionRepo creates a RetentionRepo.
+func NewRetentionRepo(pool *pgxpool.Pool) *RetentionRepo {
+	return &RetentionRepo{pool: pool}
+}
+
+// Compile-time check.
+var _ service.RetentionRepository = (*RetentionRepo)(nil)
+
+const retentionPolicyColumns = `id, org_id, vault_id, document_type, retention_days, created_by, created_at, updated_at`
+
+func scanRetentionPolicy(row pgx.Row) (*model.RetentionPolicy, error) {
+	var p model.RetentionPolicy
+	err := row.Scan(&p.ID, &p.OrgID, &p.VaultID, &p.DocumentType, &p.RetentionDays, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *RetentionRepo) ListPolicies(ctx context.Context, orgID string) ([]model.RetentionPolicy, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+retentionPolicyColumns+` FROM retention_policies
+		WHERE org_id = $1
+		ORDER BY vault_id IS NULL, vault_id, document_type`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.RetentionListPolicies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []model.RetentionPolicy{}
+	for rows.Next() {
+		p, err := scanRetentionPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.RetentionListPolicies: scan: %w", err)
+		}
+		policies = append(policies, *p)
+	}
+	return policies, rows.Err()
+}
+
+func (r *RetentionRepo) GetPolicy(ctx context.Context, id string) (*model.RetentionPolicy, error) {
+	p, err := scanRetentionPolicy(r.pool.QueryRow(ctx,
+		`SELECT `+retentionPolicyColumns+` FROM retention_policies WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository.RetentionGetPolicy: %w", err)
+	}
+	return p, nil
+}
+
+// UpsertPolicy keys on the partial unique index for the policy's scope.
+func (r *RetentionRepo) UpsertPolicy(ctx context.Context, p *model.RetentionPolicy) error {
+	conflict := `(org_id, document_type) WHERE document_type IS NOT NULL`
+	if p.VaultID != nil {
+		conflict = `(org_id, vault_id) WHERE vault_id IS NOT NULL`
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO retention_policies (id, org_id, vault_id, document_type, retention_days, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT `+conflict+` DO UPDATE
+			SET retention_days = EXCLUDED.retention_days, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_by, created_at, updated_at`,
+		p.ID, p.OrgID, p.VaultID, p.DocumentType, p.RetentionDays, p.CreatedBy, p.CreatedAt, p.UpdatedAt,
+	).Scan(&p.ID, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("repository.RetentionUpsertPolicy: %w", err)
+	}
+	return nil
+}
+
+func (r *RetentionRepo) DeletePolicy(ctx context.Context, id string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM retention_policies WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("repository.RetentionDeletePolicy: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *RetentionRepo) VaultOrg(ctx context.Context, vaultID string) (string, error) {
+	var orgID *string
+	err := r.pool.QueryRow(ctx, `SELECT org_id FROM vaults WHERE id = $1`, vaultID).Scan(&orgID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && orgID == nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("repository.RetentionVaultOrg: %w", err)
+	}
+	return *orgID, nil
+}
+
+// ExpiredDocuments resolves each soft-deleted document's retention period
+// (vault policy, then document type policy, then defaultDays). An explicit
+// hard_delete_at overrides the period. Held documents are filtered before
+// the limit, so a backlog of them cannot fill every batch.
+func (r *RetentionRepo) ExpiredDocuments(ctx context.Context, orgID string, asOf time.Time, defaultDays, limit int, excludeHeld bool) ([]model.PurgeCandidate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, org_id, original_name, vault_id, document_type, size_bytes, chunk_count,
+			checksum, storage_path, storage_uri, deleted_at, days, source, purge_after
+		FROM (
+			SELECT d.id, d.user_id, COALESCE(d.org_id, '') AS org_id, d.original_name, d.vault_id, d.document_type,
+				d.size_bytes, d.chunk_count, d.checksum, d.storage_path, d.storage_uri, d.deleted_at,
+				COALESCE(vp.retention_days, tp.retention_days, $3) AS days,
+				CASE WHEN vp.id IS NOT NULL THEN 'vault'
+					WHEN tp.id IS NOT NULL THEN 'document_type'
+					ELSE 'default' END AS source,
+				COALESCE(d.hard_delete_at,
+					d.deleted_at + make_interval(days => COALESCE(vp.retention_days, tp.retention_days, $3))) AS purge_after
+			FROM documents d
+			LEFT JOIN retention_policies vp ON vp.org_id = d.org_id AND vp.vault_id = d.vault_id
+			LEFT JOIN retention_policies tp ON tp.org_id = d.org_id AND tp.document_type = lower(d.document_type)
+			WHERE d.deletion_status = 'SoftDeleted' AND d.deleted_at IS NOT NULL
+				AND ($1 = '' OR d.org_id = $1)
+		) expired
+		WHERE purge_after <= $2
+			AND NOT ($5 AND EXISTS (SELECT 1 FROM document_legal_holds(expired.id)))
+		ORDER BY purge_after, id
+		LIMIT $4`, orgID, asOf, defaultDays, limit, excludeHeld)
+	if err != nil {
+		return nil, fmt.Errorf("repository.RetentionExpiredDocuments: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []model.PurgeCandidate
+	for rows.Next() {
+		var c model.PurgeCandidate
+		if err := rows.Scan(&c.ID, &c.UserID, &c.OrgID, &c.OriginalName, &c.VaultID, &c.DocumentType,
+			&c.SizeBytes, &c.ChunkCount, &c.Checksum, &c.StoragePath, &c.StorageURI, &c.DeletedAt,
+			&c.RetentionDays, &c.RetentionSource, &c.PurgeAfter); err != nil {
+			return nil, fmt.Errorf("repository.RetentionExpiredDocuments: scan: %w", err)
+		}
+		docs = append(docs, c)
+	}
+	return docs, rows.Err()
+}
+
+// PurgeDocument runs as one transaction so a legal hold trigger (LH001)
+// on any step leaves the document untouched.
+func (r *RetentionRepo) PurgeDocument(ctx context.Context, id string, at time.Time) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("repository.RetentionPurge: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE documents
+		SET deletion_status = 'HardDeleted', hard_delete_at = $2, extracted_text = NULL,
+			metadata = NULL, chunk_count = 0, updated_at = $2
+		WHERE id = $1 AND deletion_status = 'SoftDeleted'`, id, at)
+	if err != nil {
+		return false, fmt.Errorf("repository.RetentionPurge: %w", legalHoldErr(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	// Citations keep the answer's record that the document was cited, but
+	// not its text; chunk_id has no cascade.
+	steps := []struct{ name, sql string }{
+		{"citations", `UPDATE citations SET chunk_id = NULL, excerpt = NULL WHERE document_id = $1`},
+		{"chunks", `DELETE FROM document_chunks WHERE document_id = $1`}, // embeddings cascade
+		{"cortex", `DELETE FROM cortex_entries WHERE source_document_ids @> ARRAY[$1::text]`},
+		{"insights", `DELETE FROM mercury_proactive_insights WHERE document_id::text = $1 OR $1 = ANY(documents)`},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(ctx, step.sql, id); err != nil {
+			return false, fmt.Errorf("repository.RetentionPurge: %s: %w", step.name, legalHoldErr(err))
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("repository.RetentionPurge: commit: %w", err)
+	}
+	return true, nil
+}
+
+func (r *RetentionRepo) PendingCleanup(ctx context.Context, limit int) ([]model.PurgeCandidate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, storage_path, storage_uri, graph_cleaned_at IS NOT NULL FROM documents
+		WHERE deletion_status = 'HardDeleted'
+			AND (storage_path IS NOT NULL OR storage_uri IS NOT NULL OR graph_cleaned_at IS NULL)
+		ORDER BY hard_delete_at NULLS FIRST, id
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository.RetentionPendingCleanup: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []model.PurgeCandidate
+	for rows.Next() {
+		var c model.PurgeCandidate
+		if err := rows.Scan(&c.ID, &c.UserID, &c.StoragePath, &c.StorageURI, &c.GraphCleaned); err != nil {
+			return nil, fmt.Errorf("repository.RetentionPendingCleanup: scan: %w", err)
+		}
+		docs = append(docs, c)
+	}
+	return docs, rows.Err()
+}
+
+func (r *RetentionRepo) ClearStorage(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE documents SET storage_path = NULL, storage_uri = NULL
+		WHERE id = $1 AND deletion_status = 'HardDeleted'`, id)
+	if err != nil {
+		return fmt.Errorf("repository.RetentionClearStorage: %w", err)
+	}
+	return nil
+}
+
+func (r *RetentionRepo) MarkGraphCleaned(ctx context.Context, id string, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE documents SET graph_cleaned_at = $2
+		WHERE id = $1 AND deletion_status = 'HardDeleted'`, id, at)
+	if err != nil {
+		return fmt.Errorf("repository.RetentionMarkGraphCleaned: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// setupRetentionRepo applies the retention migrations on top of the
+// document schema.
+func setupRetentionRepo(t *testing.T) (*RetentionRepo, *DocumentRepo, func()) {
+	t.Helper()
+	docRepo, cleanup := setupDocRepo(t)
+	ctx := context.Background()
+	for _, name := range []string{"005_cortex_entries", "030_retention", "033_purge_graph_cleanup"} {
+		sql, err := os.ReadFile("../../migrations/" + name + ".up.sql")
+		if err != nil {
+			cleanup()
+			t.Fatalf("read migration: %v", err)
+		}
+		if _, err := docRepo.pool.Exec(ctx, string(sql)); err != nil {
+			cleanup()
+			t.Fatalf("apply %s: %v", name, err)
+		}
+	}
+	return NewRetentionRepo(docRepo.pool), docRepo, cleanup
+}
+
+func TestRetentionRepo_HeldDocumentsDoNotFillTheBatch(t *testing.T) {
+	repo, docRepo, cleanup := setupRetentionRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+	pool := docRepo.pool
+
+	suffix := uuid.New().String()[:8]
+	owner := "ret-p-" + suffix
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO users (id, email, role, status, created_at)
+		VALUES ($1, $1 || '@ragbox.co', 'Associate', 'Active', now())
+	`, owner); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	org := &model.Organization{ID: uuid.New().String(), Name: "Retain " + suffix, CreatedAt: time.Now().UTC()}
+	if err := NewOrgRepo(pool).Create(ctx, org, owner); err != nil {
+		t.Fatalf("Create org: %v", err)
+	}
+
+	holdID := uuid.New().String()
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO legal_holds (id, org_id, name, matter_id, custodian, created_by)
+		VALUES ($1, $2, 'Hold', 'M-1', 'custodian', $3)
+	`, holdID, org.ID, owner); err != nil {
+		t.Fatalf("insert hold: %v", err)
+	}
+
+	// More held documents than the batch size, all older than the free one.
+	const limit = 3
+	softDelete := func(daysAgo int) string {
+		doc := newTestDoc(owner)
+		if err := docRepo.Create(ctx, doc); err != nil {
+			t.Fatalf("Create doc: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `
+			UPDATE documents SET deletion_status = 'SoftDeleted', deleted_at = now() - make_interval(days => $2)
+			WHERE id = $1
+		`, doc.ID, daysAgo); err != nil {
+			t.Fatalf("soft delete: %v", err)
+		}
+		return doc.ID
+	}
+	for i := 0; i < limit+1; i++ {
+		id := softDelete(400 + i)
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO legal_hold_scopes (hold_id, resource_type, resource_id) VALUES ($1, 'document', $2)
+		`, holdID, id); err != nil {
+			t.Fatalf("insert scope: %v", err)
+		}
+	}
+	free := softDelete(100)
+
+	now := time.Now()
+	docs, err := repo.ExpiredDocuments(ctx, org.ID, now, 30, limit, true)
+	if err != nil {
+		t.Fatalf("ExpiredDocuments: %v", err)
+	}
+	if len(docs) != 1 || docs[0].ID != free {
+		t.Errorf("purge batch = %d documents, want only the unheld %s", len(docs), free)
+	}
+
+	// A dry run still lists held documents, oldest first.
+	docs, err = repo.ExpiredDocuments(ctx, org.ID, now, 30, limit, false)
+	if err != nil || len(docs) != limit {
+		t.Errorf("preview = %d documents, %v; want %d held", len(docs), err, limit)
+	}
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// ShareRepo implements service.ShareRepository with pgx.
+type ShareRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewShareRepo creates a ShareRepo.
+func NewShareRepo(pool *pgxpool.Pool) *ShareRepo {
+	return &ShareRepo{pool: pool}
+}
+
+// Compile-time check.
+var _ service.ShareRepository = (*ShareRepo)(nil)
+
+const shareGrantColumns = `id, resource_type, resource_id, grantee_type, grantee_id, role,
+	granted_by, expires_at, created_at`
+
+func (r *ShareRepo) UpsertGrant(ctx context.Context, g *model.ShareGrant) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO share_grants (id, resource_type, resource_id, grantee_type, grantee_id, role,
+			granted_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (resource_type, resource_id, grantee_type, grantee_id) DO UPDATE
+		SET role = EXCLUDED.role, expires_at = EXCLUDED.expires_at, granted_by = EXCLUDED.granted_by
+		RETURNING id, created_at
+	`, g.ID, string(g.ResourceType), g.ResourceID, string(g.GranteeType), g.GranteeID, string(g.Role),
+		g.GrantedBy, g.ExpiresAt, g.CreatedAt,
+	).Scan(&g.ID, &g.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("repository.ShareUpsertGrant: %w", err)
+	}
+	return nil
+}
+
+func (r *ShareRepo) GetGrant(ctx context.Context, id string) (*model.ShareGrant, error) {
+	g, err := scanShareGrant(r.pool.QueryRow(ctx,
+		`SELECT `+shareGrantColumns+` FROM share_grants WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository.ShareGetGrant: %w", err)
+	}
+	return g, nil
+}
+
+func (r *ShareRepo) ListGrants(ctx context.Context, resourceType model.ShareResourceType, resourceID string) ([]model.ShareGrant, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+shareGrantColumns+` FROM share_grants
+		 WHERE resource_type = $1 AND resource_id = $2 ORDER BY created_at`,
+		string(resourceType), resourceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ShareListGrants: %w", err)
+	}
+	return collectShareGrants(rows, "repository.ShareListGrants")
+}
+
+func (r *ShareRepo) DeleteGrant(ctx context.Context, id string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM share_grants WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("repository.ShareDeleteGrant: %w", err)
+	}
+	return nil
+}
+
+func (r *ShareRepo) CreateGroup(ctx context.Context, g *model.ShareGroup) error {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO share_groups (id, org_id, name, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (org_id, name) DO NOTHING
+	`, g.ID, g.OrgID, g.Name, g.CreatedBy, g.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("repository.ShareCreateGroup: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return service.ErrShareGroupExists
+	}
+	return nil
+}
+
+func (r *ShareRepo) GetGroup(ctx context.Context, id string) (*model.ShareGroup, error) {
+	var g model.ShareGroup
+	err := r.pool.QueryRow(ctx, `
+		SELECT g.id, g.org_id, g.name, g.created_by, g.created_at,
+			COALESCE(array_agg(m.user_id ORDER BY m.user_id) FILTER (WHERE m.user_id IS NOT NULL), '{}')
+		FROM share_groups g
+		LEFT JOIN share_group_members m ON m.group_id = g.id
+		WHERE g.id = $1
+		GROUP BY g.id
+	`, id).Scan(&g.ID, &g.OrgID, &g.Name, &g.CreatedBy, &g.CreatedAt, &g.MemberIDs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository.ShareGetGroup: %w", err)
+	}
+	return &g, nil
+}
+
+func (r *ShareRepo) ListGroups(ctx context.Context, orgID string) ([]model.ShareGroup, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT g.id, g.org_id, g.name, g.created_by, g.created_at,
+			COALESCE(array_agg(m.user_id ORDER BY m.user_id) FILTER (WHERE m.user_id IS NOT NULL), '{}')
+		FROM share_groups g
+		LEFT JOIN share_group_members m ON m.group_id = g.id
+		WHERE g.org_id = $1
+		GROUP BY g.id
+		ORDER BY g.name
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ShareListGroups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []model.ShareGroup
+	for rows.Next() {
+		var g model.ShareGroup
+		if err := rows.Scan(&g.ID, &g.OrgID, &g.Name, &g.CreatedBy, &g.CreatedAt, &g.MemberIDs); err != nil {
+			return nil, fmt.Errorf("repository.ShareListGroups: scan: %w", err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// DeleteGroup deletes the group's grants and the group in one transaction.
+func (r *ShareRepo) DeleteGroup(ctx context.Context, id string) ([]model.ShareGrant, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ShareDeleteGroup: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`DELETE FROM share_grants WHERE grantee_type = 'group' AND grantee_id = $1
+		 RETURNING `+shareGrantColumns, id)
+	if err != nil {
+		return nil, fmt.Errorf("repository.ShareDeleteGroup: grants: %w", err)
+	}
+	revoked, err := collectShareGrants(rows, "repository.ShareDeleteGroup")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM share_groups WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("repository.ShareDeleteGroup: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository.ShareDeleteGroup: commit: %w", err)
+	}
+	return revoked, nil
+}
+
+func (r *ShareRepo) AddGroupMember(ctx context.Context, groupID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO share_group_members (group_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		groupID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("repository.ShareAddGroupMember: %w", err)
+	}
+	return nil
+}
+
+func (r *ShareRepo) RemoveGroupMember(ctx context.Context, groupID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM share_group_members WHERE group_id = $1 AND user_id = $2`,
+		groupID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("repository.ShareRemoveGroupMember: %w", err)
+	}
+	return nil
+}
+
+func (r *ShareRepo) UserExists(ctx context.Context, userID string) (bool, error) {
+	var ok bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("repository.ShareUserExists: %w", err)
+	}
+	return ok, nil
+}
+
+func scanShareGrant(row pgx.Row) (*model.ShareGrant, error) {
+	var g model.ShareGrant
+	var resourceType, granteeType, role string
+	err := row.Scan(&g.ID, &resourceType, &g.ResourceID, &granteeType, &g.GranteeID, &role,
+		&g.GrantedBy, &g.ExpiresAt, &g.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	g.ResourceType = model.ShareResourceType(resourceType)
+	g.GranteeType = model.ShareGranteeType(granteeType)
+	g.Role = model.ShareRole(role)
+	return &g, nil
+}
+
+func collectShareGrants(rows pgx.Rows, op string) ([]model.ShareGrant, error) {
+	defer rows.Close()
+	var grants []model.ShareGrant
+	for rows.Next() {
+		g, err := scanShareGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		grants = append(grants, *g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return grants, nil
+}
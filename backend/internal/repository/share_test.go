@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func TestShareRepo_GrantsReachDocuments(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	pool := docRepo.pool
+	shareRepo := NewShareRepo(pool)
+	folderRepo := NewFolderRepo(pool)
+	orgRepo := NewOrgRepo(pool)
+
+	suffix := uuid.New().String()[:8]
+	owner, colleague, outsider := "share-o-"+suffix, "share-c-"+suffix, "share-x-"+suffix
+	for _, id := range []string{owner, colleague, outsider} {
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO users (id, email, role, status, created_at)
+			VALUES ($1, $1 || '@ragbox.co', 'Associate', 'Active', now())
+		`, id); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+	}
+
+	// A document two folders deep; the grant is on the top folder.
+	now := time.Now().UTC()
+	top := &model.Folder{ID: uuid.New().String(), Name: "Matters", UserID: owner, CreatedAt: now, UpdatedAt: now}
+	child := &model.Folder{ID: uuid.New().String(), Name: "Acme", UserID: owner, ParentID: &top.ID, CreatedAt: now, UpdatedAt: now}
+	for _, f := range []*model.Folder{top, child} {
+		if err := folderRepo.Create(ctx, f); err != nil {
+			t.Fatalf("Create folder: %v", err)
+		}
+	}
+	doc := newTestDoc(owner)
+	doc.FolderID = &child.ID
+	if err := docRepo.Create(ctx, doc); err != nil {
+		t.Fatalf("Create doc: %v", err)
+	}
+
+	opts := service.ListOpts{Limit: 50, PrivilegeMode: true}
+	if docs, _, _ := docRepo.ListByUser(ctx, colleague, opts); len(docs) != 0 {
+		t.Fatalf("colleague sees %d docs before any grant, want 0", len(docs))
+	}
+
+	grant := &model.ShareGrant{ID: uuid.New().String(), ResourceType: model.ShareResourceFolder, ResourceID: top.ID,
+		GranteeType: model.ShareGranteeUser, GranteeID: colleague, Role: model.ShareEditor, GrantedBy: owner, CreatedAt: now}
+	if err := shareRepo.UpsertGrant(ctx, grant); err != nil {
+		t.Fatalf("UpsertGrant: %v", err)
+	}
+
+	if role, err := docRepo.DocumentShareRole(ctx, doc.ID, colleague); err != nil || role != model.ShareEditor {
+		t.Errorf("DocumentShareRole = %q, %v; want editor inherited from the folder", role, err)
+	}
+	if docs, _, _ := docRepo.ListByUser(ctx, colleague, opts); len(docs) != 1 || docs[0].ID != doc.ID {
+		t.Errorf("colleague sees %d docs after folder grant, want the shared document", len(docs))
+	}
+	if docs, _, _ := docRepo.ListByUser(ctx, outsider, opts); len(docs) != 0 {
+		t.Errorf("outsider sees %d docs, want 0", len(docs))
+	}
+
+	// An expired grant no longer applies.
+	past := now.Add(-time.Minute)
+	grant.ExpiresAt = &past
+	if err := shareRepo.UpsertGrant(ctx, grant); err != nil {
+		t.Fatalf("UpsertGrant (expire): %v", err)
+	}
+	if role, _ := docRepo.DocumentShareRole(ctx, doc.ID, colleague); role != "" {
+		t.Errorf("expired grant still gives %q", role)
+	}
+
+	// Groups reach their members only while they share the group's org.
+	org := &model.Organization{ID: uuid.New().String(), Name: "Share " + suffix, CreatedAt: now}
+	if err := orgRepo.Create(ctx, org, owner); err != nil {
+		t.Fatalf("Create org: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, 'Associate')
+	`, org.ID, outsider); err != nil {
+		t.Fatalf("insert member: %v", err)
+	}
+	group := &model.ShareGroup{ID: uuid.New().String(), OrgID: org.ID, Name: "Deal team", CreatedBy: owner, CreatedAt: now}
+	if err := shareRepo.CreateGroup(ctx, group); err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if err := shareRepo.CreateGroup(ctx, &model.ShareGroup{ID: uuid.New().String(), OrgID: org.ID, Name: "Deal team", CreatedBy: owner, CreatedAt: now}); err != service.ErrShareGroupExists {
+		t.Errorf("duplicate group: err = %v, want ErrShareGroupExists", err)
+	}
+	if err := shareRepo.AddGroupMember(ctx, group.ID, outsider); err != nil {
+		t.Fatalf("AddGroupMember: %v", err)
+	}
+	groupGrant := &model.ShareGrant{ID: uuid.New().String(), ResourceType: model.ShareResourceDocument, ResourceID: doc.ID,
+		GranteeType: model.ShareGranteeGroup, GranteeID: group.ID, Role: model.ShareViewer, GrantedBy: owner, CreatedAt: now}
+	if err := shareRepo.UpsertGrant(ctx, groupGrant); err != nil {
+		t.Fatalf("UpsertGrant (group): %v", err)
+	}
+	if role, _ := docRepo.DocumentShareRole(ctx, doc.ID, outsider); role != model.ShareViewer {
+		t.Errorf("group member role = %q, want viewer", role)
+	}
+
+	revoked, err := shareRepo.DeleteGroup(ctx, group.ID)
+	if err != nil || len(revoked) != 1 || revoked[0].ID != groupGrant.ID {
+		t.Fatalf("DeleteGroup = %v, %v; want the group's grant", revoked, err)
+	}
+	if g, _ := shareRepo.GetGrant(ctx, groupGrant.ID); g != nil {
+		t.Error("group grant survived group deletion")
+	}
+}
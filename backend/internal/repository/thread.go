@@ -41,7 +41,7 @@ func (r *ThreadRepo) GetOrCreateThread(ctx context.Context, userID string) (stri
 	threadID = uuid.New().String()
 	_, err = r.pool.Exec(ctx, `
 		INSERT INTO mercury_threads (id, tenant_id, user_id, title, created_at, updated_at)
-		VALUES ($1, 'default', $2, 'Mercury Thread', $3, $3)
+		VALUES ($1, user_org_id($2), $2, 'Mercury Thread', $3, $3)
 	`, threadID, userID, time.Now().UTC())
 	if err != nil {
 		return "", fmt.Errorf("repository.ThreadRepo.GetOrCreateThread: create: %w", err)
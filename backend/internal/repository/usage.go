@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -97,3 +98,112 @@ func (r *UsageRepo) GetAllUsage(ctx context.Context, userID string) ([]service.U
 	}
 	return records, rows.Err()
 }
+
+// IncrementWithEvents increments a metric and stores the usage events the
+// increment raises in one transaction (transactional outbox). Events that
+// already fired this period are skipped by the usage_events dedup index.
+func (r *UsageRepo) IncrementWithEvents(ctx context.Context, userID, metric string, amount int64,
+	crossed func(before, after int64) []service.UsageEvent) error {
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository.IncrementWithEvents: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var after int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO usage_tracking (user_id, metric, count, period_start, period_end)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, metric, period_start)
+		DO UPDATE SET count = usage_tracking.count + $3, updated_at = NOW()
+		RETURNING count
+	`, userID, metric, amount, periodStart, periodEnd).Scan(&after)
+	if err != nil {
+		return fmt.Errorf("repository.IncrementWithEvents: increment: %w", err)
+	}
+
+	for _, ev := range crossed(after-amount, after) {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO usage_events (user_id, event_type, metric, threshold, tier, used, usage_limit, period_start)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (user_id, metric, event_type, threshold, period_start) DO NOTHING
+		`, ev.UserID, ev.Type, ev.Metric, ev.Threshold, ev.Tier, ev.Used, ev.Limit, periodStart)
+		if err != nil {
+			return fmt.Errorf("repository.IncrementWithEvents: insert event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository.IncrementWithEvents: commit: %w", err)
+	}
+	return nil
+}
+
+// ClaimUsageEvents leases up to limit pending events whose next attempt is
+// due, counting the attempt. SKIP LOCKED plus the lease keep concurrent
+// dispatchers on different instances from claiming the same event.
+func (r *UsageRepo) ClaimUsageEvents(ctx context.Context, limit int, lease time.Duration) ([]service.UsageEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM usage_events
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE usage_events e
+		SET attempts = e.attempts + 1,
+		    next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due
+		WHERE e.id = due.id
+		RETURNING e.id, e.event_type, e.user_id, e.metric, e.tier, e.threshold,
+		          e.used, e.usage_limit, e.period_start, e.created_at, e.attempts, e.delivered_to
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("repository.ClaimUsageEvents: %w", err)
+	}
+	defer rows.Close()
+
+	var events []service.UsageEvent
+	for rows.Next() {
+		var ev service.UsageEvent
+		if err := rows.Scan(&ev.ID, &ev.Type, &ev.UserID, &ev.Metric, &ev.Tier, &ev.Threshold,
+			&ev.Used, &ev.Limit, &ev.PeriodStart, &ev.CreatedAt, &ev.Attempts, &ev.DeliveredTo); err != nil {
+			return nil, fmt.Errorf("repository.ClaimUsageEvents: scan: %w", err)
+		}
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// MarkUsageEventDelivered records that every sink accepted the event.
+func (r *UsageRepo) MarkUsageEventDelivered(ctx context.Context, id string, sinks []string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE usage_events
+		SET status = 'delivered', delivered_to = $2, last_error = NULL, delivered_at = NOW()
+		WHERE id = $1
+	`, id, sinks)
+	if err != nil {
+		return fmt.Errorf("repository.MarkUsageEventDelivered: %w", err)
+	}
+	return nil
+}
+
+// MarkUsageEventFailed records a failed delivery attempt and when to retry;
+// dead events are parked as 'failed' and no longer claimed.
+func (r *UsageRepo) MarkUsageEventFailed(ctx context.Context, id string, sinks []string, lastErr string, retryAt time.Time, dead bool) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE usage_events
+		SET delivered_to = $2, last_error = $3, next_attempt_at = $4,
+		    status = CASE WHEN $5 THEN 'failed' ELSE 'pending' END
+		WHERE id = $1
+	`, id, sinks, lastErr, retryAt, dead)
+	if err != nil {
+		return fmt.Errorf("repository.MarkUsageEventFailed: %w", err)
+	}
+	return nil
+}
@@ -2,8 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
 
 // UserRepo handles user persistence.
@@ -28,12 +33,59 @@ func (r *UserRepo) EnsureUser(ctx context.Context, userID string) error {
 	return err
 }
 
+// EnsureIdentity creates or updates a user from a verified ID token. The
+// token's email is recorded unless another user already has it, and its
+// role (mapped from the identity provider) replaces users.role when set.
+func (r *UserRepo) EnsureIdentity(ctx context.Context, id service.Identity) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO users (id, email, role, status, created_at, last_login_at)
+		VALUES (
+			$1,
+			CASE WHEN $2 = '' OR EXISTS (SELECT 1 FROM users WHERE email = $2) THEN $1 ELSE $2 END,
+			COALESCE(NULLIF($3, '')::"UserRole", 'Associate'),
+			'Active', now(), now()
+		)
+		ON CONFLICT (id) DO UPDATE SET
+			last_login_at = now(),
+			role = COALESCE(NULLIF($3, '')::"UserRole", users.role),
+			email = CASE
+				WHEN $2 <> '' AND NOT EXISTS (SELECT 1 FROM users o WHERE o.email = $2 AND o.id <> users.id) THEN $2
+				ELSE users.email
+			END
+	`, id.UserID, id.Email, string(id.Role))
+	return err
+}
+
+// TouchSession records activity by userID and reports whether it starts a
+// session: the user's first recorded activity, or the first after idle.
+// Activity is written at most once a minute per user, so idle should be
+// longer than that. The row lock makes concurrent requests on any instance
+// agree on a single session start.
+func (r *UserRepo) TouchSession(ctx context.Context, userID string, idle time.Duration) (bool, error) {
+	var started bool
+	err := r.pool.QueryRow(ctx, `
+		UPDATE users u SET last_active_at = now()
+		FROM (SELECT id, last_active_at FROM users WHERE id = $1 FOR UPDATE) prev
+		WHERE u.id = prev.id
+			AND (prev.last_active_at IS NULL OR prev.last_active_at < now() - interval '1 minute')
+		RETURNING prev.last_active_at IS NULL OR prev.last_active_at < now() - make_interval(secs => $2)`,
+		userID, idle.Seconds()).Scan(&started)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil // active within the last minute
+	}
+	return started, err
+}
+
 // GetUserRole returns the role for a user (e.g. "Partner", "Associate", "Auditor").
+// A member's organization role takes precedence over users.role.
 // Returns "Associate" if the user is not found (STORY-S01).
 func (r *UserRepo) GetUserRole(ctx context.Context, userID string) (string, error) {
 	var role string
 	err := r.pool.QueryRow(ctx, `
-		SELECT COALESCE(role, 'Associate') FROM users WHERE id = $1
+		SELECT COALESCE(m.role, u.role, 'Associate')
+		FROM users u
+		LEFT JOIN organization_members m ON m.user_id = u.id
+		WHERE u.id = $1
 	`, userID).Scan(&role)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -44,12 +96,17 @@ func (r *UserRepo) GetUserRole(ctx context.Context, userID string) (string, erro
 	return role, nil
 }
 
-// GetSubscriptionTier returns the subscription tier for a user.
+// GetSubscriptionTier returns the subscription tier for a user: their
+// organization's tier when they are a member, else their own.
 // Returns "free" if the user is not found or has no tier set (STORY-199).
 func (r *UserRepo) GetSubscriptionTier(ctx context.Context, userID string) (string, error) {
 	var tier string
 	err := r.pool.QueryRow(ctx, `
-		SELECT COALESCE(subscription_tier, 'free') FROM users WHERE id = $1
+		SELECT COALESCE(o.subscription_tier, u.subscription_tier::text, 'free')
+		FROM users u
+		LEFT JOIN organization_members m ON m.user_id = u.id
+		LEFT JOIN organizations o ON o.id = m.org_id
+		WHERE u.id = $1
 	`, userID).Scan(&tier)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -60,6 +117,21 @@ func (r *UserRepo) GetSubscriptionTier(ctx context.Context, userID string) (stri
 	return tier, nil
 }
 
+// GetEmail returns a user's email address, or "" if the user is not found.
+func (r *UserRepo) GetEmail(ctx context.Context, userID string) (string, error) {
+	var email string
+	err := r.pool.QueryRow(ctx, `
+		SELECT email FROM users WHERE id = $1
+	`, userID).Scan(&email)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return "", nil
+		}
+		return "", err
+	}
+	return email, nil
+}
+
 // GetPrivilegeMode returns the privilege mode for a user.
 // Returns false if the user is not found (STORY-S04).
 func (r *UserRepo) GetPrivilegeMode(ctx context.Context, userID string) (bool, error) {
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUserRepo_TouchSession(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+	repo := NewUserRepo(docRepo.pool)
+	const user = "test-user-doc"
+
+	setLastActive := func(expr string) {
+		t.Helper()
+		if _, err := docRepo.pool.Exec(ctx, `UPDATE users SET last_active_at = `+expr+` WHERE id = $1`, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch := func() bool {
+		t.Helper()
+		started, err := repo.TouchSession(ctx, user, 30*time.Minute)
+		if err != nil {
+			t.Fatalf("TouchSession: %v", err)
+		}
+		return started
+	}
+
+	setLastActive("NULL")
+	if !touch() {
+		t.Error("first activity did not start a session")
+	}
+	if touch() {
+		t.Error("activity within the minute started a session")
+	}
+	setLastActive("now() - interval '5 minutes'")
+	if touch() {
+		t.Error("activity after 5 idle minutes started a session")
+	}
+	setLastActive("now() - interval '2 hours'")
+	if !touch() {
+		t.Error("activity after 2 idle hours did not start a session")
+	}
+
+	// Concurrent requests, as from several instances, agree on one start.
+	setLastActive("NULL")
+	var starts atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if started, err := repo.TouchSession(ctx, user, 30*time.Minute); err == nil && started {
+				starts.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := starts.Load(); n != 1 {
+		t.Errorf("%d concurrent requests started a session, want 1", n)
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+// VaultRepo implements service.VaultRepository with pgx. Access rules live
+// in the vault_accessible / vault_queryable SQL functions (migration 022).
+type VaultRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewVaultRepo creates a VaultRepo.
+func NewVaultRepo(pool *pgxpool.Pool) *VaultRepo {
+	return &VaultRepo{pool: pool}
+}
+
+// Compile-time check.
+var _ service.VaultRepository = (*VaultRepo)(nil)
+
+const vaultColumns = `id, name, user_id, COALESCE(org_id, ''), status, document_count,
+	storage_used_bytes, archived_at, created_at, updated_at`
+
+func (r *VaultRepo) Create(ctx context.Context, vault *model.Vault) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO vaults (id, name, user_id, status, created_at, updated_at, org_id)
+		 VALUES ($1, $2, $3, $4::"VaultStatus", $5, $6, COALESCE(NULLIF($7, ''), user_org_id($3)))
+		 RETURNING org_id`,
+		vault.ID, vault.Name, vault.UserID, string(vault.Status), vault.CreatedAt, vault.UpdatedAt, vault.OrgID,
+	).Scan(&vault.OrgID)
+	if err != nil {
+		return fmt.Errorf("repository.VaultCreate: %w", err)
+	}
+	return nil
+}
+
+func (r *VaultRepo) GetForUser(ctx context.Context, id, userID string) (*model.Vault, error) {
+	row := r.pool.QueryRow(ctx,
+		`SELECT `+vaultColumns+` FROM vaults WHERE id = $1 AND vault_accessible(id, $2)`,
+		id, userID,
+	)
+	v, err := scanVault(row)
+	if err != nil {
+		return nil, fmt.Errorf("repository.VaultGetForUser: %w", err)
+	}
+	return v, nil
+}
+
+// ListByUser lists the vaults of the user's organization that the user may see.
+func (r *VaultRepo) ListByUser(ctx context.Context, userID string, includeArchived bool) ([]model.Vault, error) {
+	query := `SELECT ` + vaultColumns + ` FROM vaults
+		WHERE org_id = user_org_id($1) AND vault_accessible(id, $1)`
+	if !includeArchived {
+		query += ` AND archived_at IS NULL`
+	}
+	query += ` ORDER BY name`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repository.VaultListByUser: %w", err)
+	}
+	defer rows.Close()
+
+	var vaults []model.Vault
+	for rows.Next() {
+		v, err := scanVault(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository.VaultListByUser: scan: %w", err)
+		}
+		vaults = append(vaults, *v)
+	}
+	return vaults, rows.Err()
+}
+
+func (r *VaultRepo) Update(ctx context.Context, id, name string, status model.VaultStatus) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE vaults SET name = $1, status = $2::"VaultStatus", updated_at = $3 WHERE id = $4`,
+		name, string(status), time.Now().UTC(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("repository.VaultUpdate: %w", err)
+	}
+	return nil
+}
+
+func (r *VaultRepo) SetArchived(ctx context.Context, id string, archived bool) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE vaults SET archived_at = CASE WHEN $1 THEN COALESCE(archived_at, $2) END, updated_at = $2
+		 WHERE id = $3`,
+		archived, time.Now().UTC(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("repository.VaultSetArchived: %w", err)
+	}
+	return nil
+}
+
+// MoveDocuments moves active documents into the vault. The documents_vault_stats
+// trigger updates the counters of both the source and target vaults.
+func (r *VaultRepo) MoveDocuments(ctx context.Context, vaultID string, docIDs []string) (int, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE documents SET vault_id = $1, updated_at = $2
+		 WHERE id = ANY($3) AND deletion_status = 'Active' AND vault_id IS DISTINCT FROM $1`,
+		vaultID, time.Now().UTC(), docIDs,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("repository.VaultMoveDocuments: %w", legalHoldErr(err))
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *VaultRepo) DocumentIDs(ctx context.Context, vaultID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id FROM documents WHERE vault_id = $1 AND deletion_status = 'Active'`,
+		vaultID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("repository.VaultDocumentIDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("repository.VaultDocumentIDs: scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *VaultRepo) CanQuery(ctx context.Context, vaultID, userID string) (bool, error) {
+	var ok bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM vaults WHERE id = $1) AND vault_queryable($1, $2)`,
+		vaultID, userID,
+	).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("repository.VaultCanQuery: %w", err)
+	}
+	return ok, nil
+}
+
+func scanVault(row pgx.Row) (*model.Vault, error) {
+	var v model.Vault
+	var status string
+	err := row.Scan(&v.ID, &v.Name, &v.UserID, &v.OrgID, &status, &v.DocumentCount,
+		&v.StorageUsedBytes, &v.ArchivedAt, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	v.Status = model.VaultStatus(status)
+	return &v, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/service"
+)
+
+func TestVaultRepo_StatsAndStatusAccess(t *testing.T) {
+	docRepo, cleanup := setupDocRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	pool := docRepo.pool
+	orgRepo := NewOrgRepo(pool)
+	vaultRepo := NewVaultRepo(pool)
+
+	suffix := uuid.New().String()[:8]
+	owner, member := "vault-o-"+suffix, "vault-m-"+suffix
+	for _, id := range []string{owner, member} {
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO users (id, email, role, status, created_at)
+			VALUES ($1, $1 || '@ragbox.co', 'Associate', 'Active', now())
+		`, id); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+	}
+	org := &model.Organization{ID: uuid.New().String(), Name: "Vaults " + suffix, CreatedAt: time.Now().UTC()}
+	if err := orgRepo.Create(ctx, org, owner); err != nil {
+		t.Fatalf("Create org: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, 'Associate')
+	`, org.ID, member); err != nil {
+		t.Fatalf("insert member: %v", err)
+	}
+
+	now := time.Now().UTC()
+	vault := &model.Vault{ID: uuid.New().String(), Name: "Deal room", UserID: owner,
+		Status: model.VaultOpen, CreatedAt: now, UpdatedAt: now}
+	if err := vaultRepo.Create(ctx, vault); err != nil {
+		t.Fatalf("Create vault: %v", err)
+	}
+	if vault.OrgID != org.ID {
+		t.Fatalf("vault org = %q, want %q", vault.OrgID, org.ID)
+	}
+
+	doc := newTestDoc(owner)
+	if err := docRepo.Create(ctx, doc); err != nil {
+		t.Fatalf("Create doc: %v", err)
+	}
+	if moved, err := vaultRepo.MoveDocuments(ctx, vault.ID, []string{doc.ID}); err != nil || moved != 1 {
+		t.Fatalf("MoveDocuments = %d, %v; want 1", moved, err)
+	}
+
+	got, err := vaultRepo.GetForUser(ctx, vault.ID, owner)
+	if err != nil {
+		t.Fatalf("GetForUser: %v", err)
+	}
+	if got.DocumentCount != 1 || got.StorageUsedBytes != int64(doc.SizeBytes) {
+		t.Errorf("stats = %d docs / %d bytes, want 1 / %d", got.DocumentCount, got.StorageUsedBytes, doc.SizeBytes)
+	}
+
+	opts := service.ListOpts{Limit: 50, PrivilegeMode: true, VaultID: vault.ID}
+	if docs, _, _ := docRepo.ListByUser(ctx, member, opts); len(docs) != 1 {
+		t.Errorf("member sees %d docs in open vault, want 1", len(docs))
+	}
+
+	// A secure vault is visible to its owner only.
+	if err := vaultRepo.Update(ctx, vault.ID, vault.Name, model.VaultSecure); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if ok, _ := vaultRepo.CanQuery(ctx, vault.ID, member); ok {
+		t.Error("member can query a secure vault")
+	}
+	if docs, _, _ := docRepo.ListByUser(ctx, member, opts); len(docs) != 0 {
+		t.Errorf("member sees %d docs in secure vault, want 0", len(docs))
+	}
+
+	// Archived vaults are not queryable, even by the owner, until restored.
+	if err := vaultRepo.SetArchived(ctx, vault.ID, true); err != nil {
+		t.Fatalf("SetArchived: %v", err)
+	}
+	if ok, _ := vaultRepo.CanQuery(ctx, vault.ID, owner); ok {
+		t.Error("owner can query an archived vault")
+	}
+	if err := vaultRepo.SetArchived(ctx, vault.ID, false); err != nil {
+		t.Fatalf("SetArchived(false): %v", err)
+	}
+	if ok, _ := vaultRepo.CanQuery(ctx, vault.ID, owner); !ok {
+		t.Error("owner cannot query a restored vault")
+	}
+
+	if err := docRepo.SoftDelete(ctx, doc.ID); err != nil {
+		t.Fatalf("SoftDelete: %v", err)
+	}
+	if got, _ := vaultRepo.GetForUser(ctx, vault.ID, owner); got == nil || got.DocumentCount != 0 {
+		t.Errorf("document count after delete = %+v, want 0", got)
+	}
+}
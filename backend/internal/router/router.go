@@ -11,6 +11,8 @@ import (
 	"github.com/connexus-ai/ragbox-backend/internal/cache"
 	"github.com/connexus-ai/ragbox-backend/internal/handler"
 	"github.com/connexus-ai/ragbox-backend/internal/middleware"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/rbac"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
 
@@ -30,6 +32,7 @@ type Dependencies struct {
 
 	// Chunk
 	ChunkDeleter handler.ChunkDeleter
+	ChunkLister  handler.ChunkLister
 
 	// Storage (download, verify)
 	Storage          handler.StorageSigner
@@ -92,13 +95,59 @@ type Dependencies struct {
 	// User auto-provisioning
 	UserEnsurer middleware.UserEnsurer
 
+	// Tier × route group rate limit policy. When set, it replaces the fixed
+	// limiters below.
+	RateLimitPolicy *middleware.PolicyLimiter
+	RateLimitAdmin  handler.RateLimitAdminDeps
+
+	// Public API call metering (nil = API calls are not counted)
+	APIUsage middleware.APIUsageMeter
+
+	// Organizations (nil OrgResolver = everyone stays in their personal scope)
+	OrgResolver middleware.OrgResolver
+	OrgDeps     *handler.OrgDeps
+
+	// Vaults (nil = vault management routes are not mounted)
+	VaultDeps *handler.VaultDeps
+
+	// Sharing (nil = share grant and share group routes are not mounted)
+	ShareDeps *handler.ShareDeps
+
+	// Legal holds (nil LegalHolds = changes are only refused by the database
+	// triggers; nil LegalHoldDeps = hold routes are not mounted)
+	LegalHolds    handler.LegalHoldGuard
+	LegalHoldDeps *handler.LegalHoldDeps
+
+	// Retention policies and the purge dry run (nil = routes not mounted)
+	RetentionDeps *handler.RetentionDeps
+
+	// Per-vault PII redaction policies (nil = routes not mounted)
+	PIIPolicyDeps *handler.PIIPolicyDeps
+
+	// Personal access tokens (nil APITokens = bearer tokens are only
+	// verified with Firebase; nil APITokenDeps = token routes not mounted)
+	APITokens    middleware.APITokenAuthenticator
+	APITokenDeps *handler.APITokenDeps
+
+	// Route-level RBAC (nil = roles are not enforced, development only)
+	RoleResolver middleware.RoleResolver
+
+	// Route auditing (nil AuditLogger = routes are not audited; nil
+	// SessionTracker = logins are not audited)
+	AuditLogger    middleware.AuditLogger
+	SessionTracker middleware.SessionTracker
+	LoginIdle      time.Duration
+
 	// Rate limiters (nil = no rate limiting)
-	GeneralRateLimiter *middleware.RateLimiter
-	ChatRateLimiter    *middleware.RateLimiter
-	ForgeRateLimiter   *middleware.RateLimiter
+	GeneralRateLimiter middleware.Limiter
+	ChatRateLimiter    middleware.Limiter
+	ForgeRateLimiter   middleware.Limiter
+
+	// Cache invalidation on document changes (nil = no caching)
+	CacheInvalidator *cache.Invalidator
 
-	// Query cache (nil = no caching)
-	QueryCache *cache.QueryCache
+	// Cache admin (stats + per-user flush)
+	CacheAdminDeps handler.CacheAdminDeps
 
 	// Mercury config (voice agent persona)
 	MercuryConfigDeps handler.MercuryConfigDeps
@@ -107,8 +156,8 @@ type Dependencies struct {
 	UsageDeps *handler.UsageDeps
 
 	// Proactive insights (EPIC-028 Phase 4)
-	InsightDeps       handler.InsightDeps
-	InsightRateLimiter *middleware.RateLimiter
+	InsightDeps        handler.InsightDeps
+	InsightRateLimiter middleware.Limiter
 }
 
 // internalAuthOnly wraps a handler with a simple internal auth check.
@@ -129,6 +178,271 @@ func internalAuthOnly(secret string, next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// rateLimitFor returns the rate limit middleware for a route group: the
+// tier-aware policy when configured, else the fixed limiter, else none.
+func rateLimitFor(deps *Dependencies, group string, fixed middleware.Limiter) []func(http.Handler) http.Handler {
+	switch {
+	case deps.RateLimitPolicy != nil:
+		return []func(http.Handler) http.Handler{middleware.PolicyRateLimit(deps.RateLimitPolicy, group)}
+	case fixed != nil:
+		return []func(http.Handler) http.Handler{middleware.RateLimit(fixed)}
+	}
+	return nil
+}
+
+// apiTokenScopes lists the routes a personal access token needs a scope
+// other than the default for: read for GET, admin for anything else.
+var apiTokenScopes = map[string]model.APITokenScope{
+	"POST /api/chat":                       model.APIScopeQuery,
+	"POST /api/forge":                      model.APIScopeQuery,
+	"POST /api/v1/insights/scan":           model.APIScopeQuery,
+	"POST /api/vaults/{id}/health-check":   model.APIScopeQuery,
+	"POST /api/voice/transcribe":           model.APIScopeQuery,
+	"POST /api/documents/extract":          model.APIScopeUpload,
+	"POST /api/documents/{id}/ingest":      model.APIScopeUpload,
+	"POST /api/documents/{id}/ingest-text": model.APIScopeUpload,
+	"PUT /api/documents/{id}/text":         model.APIScopeUpload,
+	"PATCH /api/documents/{id}":            model.APIScopeUpload,
+	"DELETE /api/documents/{id}":           model.APIScopeUpload,
+	"POST /api/documents/{id}/recover":     model.APIScopeUpload,
+	"PATCH /api/documents/{id}/tier":       model.APIScopeUpload,
+	"DELETE /api/documents/{id}/chunks":    model.APIScopeUpload,
+	"POST /api/documents/{id}/verify":      model.APIScopeUpload,
+	"POST /api/documents/{id}/star":        model.APIScopeUpload,
+	"POST /api/documents/folders":          model.APIScopeUpload,
+	"DELETE /api/documents/folders/{id}":   model.APIScopeUpload,
+	"POST /api/vaults/{id}/documents":      model.APIScopeUpload,
+	"GET /api/tokens":                      model.APIScopeAdmin,
+	"GET /api/export":                      model.APIScopeAdmin,
+	"GET /api/audit/export":                model.APIScopeAdmin,
+}
+
+// apiTokenScope returns the scope a token needs for the matched route.
+func apiTokenScope(r *http.Request) model.APITokenScope {
+	pattern := chi.RouteContext(r.Context()).RoutePattern()
+	if scope, ok := apiTokenScopes[r.Method+" "+pattern]; ok {
+		return scope
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return model.APIScopeRead
+	}
+	return model.APIScopeAdmin
+}
+
+// routePermissions is the RBAC policy: the permission each protected route
+// requires. Every route in the protected group must be listed; the Policy
+// middleware refuses routes that are not.
+var routePermissions = map[string]rbac.Permission{
+	// Documents
+	"GET /api/documents":                               rbac.PermDocumentsRead,
+	"POST /api/documents/extract":                      rbac.PermDocumentsWrite,
+	"GET /api/documents/{id}":                          rbac.PermDocumentsRead,
+	"PATCH /api/documents/{id}":                        rbac.PermDocumentsWrite,
+	"DELETE /api/documents/{id}":                       rbac.PermDocumentsWrite,
+	"POST /api/documents/{id}/recover":                 rbac.PermDocumentsWrite,
+	"PATCH /api/documents/{id}/tier":                   rbac.PermDocumentsWrite,
+	"PATCH /api/documents/{id}/privilege":              rbac.PermDocumentsWrite,
+	"GET /api/documents/{id}/chunks":                   rbac.PermDocumentsRead,
+	"DELETE /api/documents/{id}/chunks":                rbac.PermDocumentsWrite,
+	"GET /api/documents/{id}/download":                 rbac.PermDocumentsRead,
+	"POST /api/documents/{id}/verify":                  rbac.PermDocumentsWrite,
+	"POST /api/documents/{id}/star":                    rbac.PermDocumentsWrite,
+	"GET /api/documents/{id}/related":                  rbac.PermDocumentsRead,
+	"GET /api/documents/{id}/chunks/{chunkId}/preview": rbac.PermDocumentsRead,
+	"POST /api/documents/{id}/ingest":                  rbac.PermDocumentsWrite,
+	"POST /api/documents/{id}/ingest-text":             rbac.PermDocumentsWrite,
+	"PUT /api/documents/{id}/text":                     rbac.PermDocumentsWrite,
+	"GET /api/documents/folders":                       rbac.PermDocumentsRead,
+	"POST /api/documents/folders":                      rbac.PermDocumentsWrite,
+	"DELETE /api/documents/folders/{id}":               rbac.PermDocumentsWrite,
+	"GET /api/content-gaps":                            rbac.PermDocumentsRead,
+	"GET /api/content-gaps/summary":                    rbac.PermDocumentsRead,
+	"PATCH /api/content-gaps/{id}":                     rbac.PermDocumentsWrite,
+	"GET /api/v1/insights":                             rbac.PermDocumentsRead,
+	"PATCH /api/v1/insights/{id}/acknowledge":          rbac.PermDocumentsWrite,
+	"GET /api/vaults/{id}/health-checks":               rbac.PermDocumentsRead,
+
+	// Vaults
+	"GET /api/vaults":                    rbac.PermDocumentsRead,
+	"GET /api/vaults/{id}":               rbac.PermDocumentsRead,
+	"POST /api/vaults":                   rbac.PermVaultsManage,
+	"PATCH /api/vaults/{id}":             rbac.PermVaultsManage,
+	"DELETE /api/vaults/{id}":            rbac.PermVaultsManage,
+	"POST /api/vaults/{id}/restore":      rbac.PermVaultsManage,
+	"POST /api/vaults/{id}/documents":    rbac.PermDocumentsWrite,
+	"GET /api/vaults/{id}/pii-policy":    rbac.PermDocumentsRead,
+	"PUT /api/vaults/{id}/pii-policy":    rbac.PermVaultsManage,
+	"DELETE /api/vaults/{id}/pii-policy": rbac.PermVaultsManage,
+
+	// Retrieval and generation
+	"POST /api/chat":                     rbac.PermQuery,
+	"POST /api/forge":                    rbac.PermQuery,
+	"POST /api/v1/insights/scan":         rbac.PermQuery,
+	"POST /api/vaults/{id}/health-check": rbac.PermQuery,
+	"POST /api/voice/transcribe":         rbac.PermQuery,
+
+	// Sharing
+	"GET /api/documents/{id}/shares":                 rbac.PermShare,
+	"POST /api/documents/{id}/shares":                rbac.PermShare,
+	"DELETE /api/documents/{id}/shares/{grantId}":    rbac.PermShare,
+	"GET /api/folders/{id}/shares":                   rbac.PermShare,
+	"POST /api/folders/{id}/shares":                  rbac.PermShare,
+	"DELETE /api/folders/{id}/shares/{grantId}":      rbac.PermShare,
+	"GET /api/share-groups":                          rbac.PermShare,
+	"POST /api/share-groups":                         rbac.PermShare,
+	"DELETE /api/share-groups/{id}":                  rbac.PermShare,
+	"POST /api/share-groups/{id}/members":            rbac.PermShare,
+	"DELETE /api/share-groups/{id}/members/{userId}": rbac.PermShare,
+
+	// Legal holds
+	"GET /api/legal-holds":               rbac.PermLegalHold,
+	"POST /api/legal-holds":              rbac.PermLegalHold,
+	"POST /api/legal-holds/{id}/release": rbac.PermLegalHold,
+	"GET /api/legal-holds/{id}/report":   rbac.PermLegalHold,
+
+	// Retention
+	"GET /api/retention/policies":         rbac.PermRetention,
+	"PUT /api/retention/policies":         rbac.PermRetention,
+	"DELETE /api/retention/policies/{id}": rbac.PermRetention,
+	"GET /api/retention/purge-preview":    rbac.PermRetention,
+
+	// Audit and export
+	"GET /api/audit":            rbac.PermAuditRead,
+	"GET /api/audit/export":     rbac.PermAuditRead,
+	"GET /api/audit/verify":     rbac.PermAuditRead,
+	"GET /api/audit/{id}/proof": rbac.PermAuditRead,
+	"GET /api/export":           rbac.PermExport,
+
+	// Privilege
+	"GET /api/privilege":  rbac.PermAccount,
+	"POST /api/privilege": rbac.PermPrivilegeToggle,
+
+	// Account
+	"GET /api/tokens":                  rbac.PermAccount,
+	"POST /api/tokens":                 rbac.PermAccount,
+	"DELETE /api/tokens/{id}":          rbac.PermAccount,
+	"GET /api/mercury/config":          rbac.PermAccount,
+	"POST /api/mercury/config":         rbac.PermAccount,
+	"GET /api/v1/usage":                rbac.PermAccount,
+	"GET /api/org":                     rbac.PermAccount,
+	"POST /api/org":                    rbac.PermAccount,
+	"PATCH /api/org":                   rbac.PermAccount,
+	"GET /api/org/members":             rbac.PermAccount,
+	"PATCH /api/org/members/{userId}":  rbac.PermAccount,
+	"DELETE /api/org/members/{userId}": rbac.PermAccount,
+	"GET /api/org/invites":             rbac.PermAccount,
+	"POST /api/org/invites":            rbac.PermAccount,
+	"POST /api/org/invites/accept":     rbac.PermAccount,
+	"DELETE /api/org/invites/{id}":     rbac.PermAccount,
+}
+
+// routePermission returns the permission the matched route requires.
+func routePermission(r *http.Request) (rbac.Permission, bool) {
+	perm, ok := routePermissions[r.Method+" "+chi.RouteContext(r.Context()).RoutePattern()]
+	return perm, ok
+}
+
+// routeAudits lists the audit entry each mutating or content-reading route
+// writes when it succeeds, keyed like routePermissions. Routes whose
+// service or handler already writes a richer entry are in
+// auditedElsewhere instead.
+var routeAudits = map[string]middleware.AuditAction{
+	// Documents
+	"POST /api/documents/extract":                      {Action: model.AuditDocumentUploadRequest, ResourceType: "document"},
+	"GET /api/documents/{id}":                          {Action: model.AuditDocumentView, ResourceType: "document", IDParam: "id"},
+	"PATCH /api/documents/{id}":                        {Action: model.AuditDocumentUpdate, ResourceType: "document", IDParam: "id"},
+	"DELETE /api/documents/{id}":                       {Action: model.AuditDocumentDelete, ResourceType: "document", IDParam: "id"},
+	"POST /api/documents/{id}/recover":                 {Action: model.AuditDocumentRecover, ResourceType: "document", IDParam: "id"},
+	"PATCH /api/documents/{id}/tier":                   {Action: model.AuditDocumentTierChange, ResourceType: "document", IDParam: "id"},
+	"PATCH /api/documents/{id}/privilege":              {Action: model.AuditDocumentPrivilegeChange, ResourceType: "document", IDParam: "id"},
+	"GET /api/documents/{id}/chunks":                   {Action: model.AuditDocumentView, ResourceType: "document", IDParam: "id"},
+	"DELETE /api/documents/{id}/chunks":                {Action: model.AuditDocumentChunksDelete, ResourceType: "document", IDParam: "id"},
+	"GET /api/documents/{id}/download":                 {Action: model.AuditDocumentDownload, ResourceType: "document", IDParam: "id"},
+	"POST /api/documents/{id}/verify":                  {Action: model.AuditDocumentVerify, ResourceType: "document", IDParam: "id"},
+	"POST /api/documents/{id}/star":                    {Action: model.AuditDocumentStar, ResourceType: "document", IDParam: "id"},
+	"GET /api/documents/{id}/chunks/{chunkId}/preview": {Action: model.AuditChunkPreview, ResourceType: "document", IDParam: "id"},
+	"POST /api/documents/{id}/ingest":                  {Action: model.AuditDocumentIngest, ResourceType: "document", IDParam: "id"},
+	"POST /api/documents/{id}/ingest-text":             {Action: model.AuditDocumentIngest, ResourceType: "document", IDParam: "id"},
+	"PUT /api/documents/{id}/text":                     {Action: model.AuditDocumentUpdate, ResourceType: "document", IDParam: "id"},
+
+	// Folders
+	"POST /api/documents/folders":        {Action: model.AuditFolderCreate, ResourceType: "folder"},
+	"DELETE /api/documents/folders/{id}": {Action: model.AuditFolderDelete, ResourceType: "folder", IDParam: "id"},
+
+	// Chat, export and generation
+	"GET /api/export":       {Action: model.AuditDataExport, ResourceType: "export"},
+	"GET /api/audit/export": {Action: model.AuditAuditExport, ResourceType: "audit"},
+	"POST /api/forge":       {Action: model.AuditForgeGenerate, ResourceType: "forge"},
+
+	// Content gaps
+	"PATCH /api/content-gaps/{id}": {Action: model.AuditContentGapUpdate, ResourceType: "content_gap", IDParam: "id"},
+
+	// Vaults
+	"POST /api/vaults":                   {Action: model.AuditVaultCreate, ResourceType: "vault"},
+	"PATCH /api/vaults/{id}":             {Action: model.AuditVaultUpdate, ResourceType: "vault", IDParam: "id"},
+	"DELETE /api/vaults/{id}":            {Action: model.AuditVaultArchive, ResourceType: "vault", IDParam: "id"},
+	"POST /api/vaults/{id}/restore":      {Action: model.AuditVaultRestore, ResourceType: "vault", IDParam: "id"},
+	"POST /api/vaults/{id}/documents":    {Action: model.AuditVaultDocumentsMove, ResourceType: "vault", IDParam: "id"},
+	"POST /api/vaults/{id}/health-check": {Action: model.AuditVaultHealthCheck, ResourceType: "vault", IDParam: "id"},
+
+	// Share groups
+	"POST /api/share-groups":        {Action: model.AuditShareGroupCreate, ResourceType: "share_group"},
+	"DELETE /api/share-groups/{id}": {Action: model.AuditShareGroupDelete, ResourceType: "share_group", IDParam: "id"},
+
+	// Legal holds
+	"GET /api/legal-holds/{id}/report": {Action: model.AuditLegalHoldReport, ResourceType: "legal_hold", IDParam: "id"},
+
+	// Retention
+	"GET /api/retention/purge-preview": {Action: model.AuditRetentionPreview, ResourceType: "retention"},
+
+	// Voice, persona and insights
+	"POST /api/voice/transcribe":              {Action: model.AuditVoiceTranscribe, ResourceType: "voice"},
+	"POST /api/mercury/config":                {Action: model.AuditPersonaConfigUpdate, ResourceType: "persona"},
+	"PATCH /api/v1/insights/{id}/acknowledge": {Action: model.AuditInsightAcknowledge, ResourceType: "insight", IDParam: "id"},
+	"POST /api/v1/insights/scan":              {Action: model.AuditInsightScan, ResourceType: "vault"},
+
+	// Organizations
+	"POST /api/org":                    {Action: model.AuditOrgCreate, ResourceType: "organization"},
+	"PATCH /api/org":                   {Action: model.AuditOrgRename, ResourceType: "organization"},
+	"PATCH /api/org/members/{userId}":  {Action: model.AuditOrgMemberUpdate, ResourceType: "user", IDParam: "userId"},
+	"DELETE /api/org/members/{userId}": {Action: model.AuditOrgMemberRemove, ResourceType: "user", IDParam: "userId"},
+	"POST /api/org/invites":            {Action: model.AuditOrgInviteCreate, ResourceType: "org_invite"},
+	"POST /api/org/invites/accept":     {Action: model.AuditOrgInviteAccept, ResourceType: "org_invite"},
+	"DELETE /api/org/invites/{id}":     {Action: model.AuditOrgInviteRevoke, ResourceType: "org_invite", IDParam: "id"},
+
+	// Internal admin routes
+	"POST /api/admin/migrate":                {Action: model.AuditAdminMigrate, ResourceType: "system"},
+	"DELETE /api/admin/cache/users/{userId}": {Action: model.AuditAdminCacheFlush, ResourceType: "user", IDParam: "userId"},
+	"POST /api/admin/ratelimits/reload":      {Action: model.AuditAdminRateLimitReload, ResourceType: "system"},
+}
+
+// auditedElsewhere lists mutating routes that are not in routeAudits
+// because their service or handler writes the audit entry itself.
+var auditedElsewhere = map[string]bool{
+	"POST /api/privilege":                            true, // handler.TogglePrivilege
+	"POST /api/chat":                                 true, // handler.Chat, once the query has run
+	"POST /api/documents/{id}/shares":                true, // service.SharingService
+	"DELETE /api/documents/{id}/shares/{grantId}":    true,
+	"POST /api/folders/{id}/shares":                  true,
+	"DELETE /api/folders/{id}/shares/{grantId}":      true,
+	"POST /api/share-groups/{id}/members":            true,
+	"DELETE /api/share-groups/{id}/members/{userId}": true,
+	"POST /api/tokens":                               true, // service.APITokenService
+	"DELETE /api/tokens/{id}":                        true,
+	"POST /api/legal-holds":                          true, // service.LegalHoldService
+	"POST /api/legal-holds/{id}/release":             true,
+	"PUT /api/retention/policies":                    true, // service.RetentionService
+	"DELETE /api/retention/policies/{id}":            true,
+	"PUT /api/vaults/{id}/pii-policy":                true, // service.PIIRedactionService
+	"DELETE /api/vaults/{id}/pii-policy":             true,
+}
+
+// routeAudit returns the audit entry the matched route writes, if any.
+func routeAudit(r *http.Request) (middleware.AuditAction, bool) {
+	act, ok := routeAudits[r.Method+" "+chi.RouteContext(r.Context()).RoutePattern()]
+	return act, ok
+}
+
 // New creates and configures the Chi router with all routes.
 func New(deps *Dependencies) *chi.Mux {
 	r := chi.NewRouter()
@@ -136,6 +450,7 @@ func New(deps *Dependencies) *chi.Mux {
 	// Global middleware
 	r.Use(middleware.SecurityHeaders)
 	r.Use(middleware.Logging)
+	r.Use(middleware.AuditContext(deps.InternalAuthSecret))
 	r.Use(middleware.CORS(deps.FrontendURL))
 	if deps.Metrics != nil {
 		r.Use(middleware.Monitoring(deps.Metrics))
@@ -147,9 +462,22 @@ func New(deps *Dependencies) *chi.Mux {
 		r.Handle("/metrics", middleware.MetricsHandler(deps.MetricsReg))
 	}
 
-	// Admin routes (internal auth only — called by Cloud Build)
-	r.Post("/api/admin/migrate", internalAuthOnly(deps.InternalAuthSecret,
+	// Admin routes (internal auth only — called by Cloud Build). Audited
+	// without a user, in the system chain.
+	admin := r.With()
+	if deps.AuditLogger != nil {
+		admin = r.With(middleware.Audit(deps.AuditLogger, routeAudit))
+	}
+	admin.Post("/api/admin/migrate", internalAuthOnly(deps.InternalAuthSecret,
 		handler.AdminMigrate(deps.AdminMigrateDeps)))
+	admin.Get("/api/admin/cache/stats", internalAuthOnly(deps.InternalAuthSecret,
+		handler.CacheStats(deps.CacheAdminDeps)))
+	admin.Delete("/api/admin/cache/users/{userId}", internalAuthOnly(deps.InternalAuthSecret,
+		handler.FlushUserCache(deps.CacheAdminDeps)))
+	admin.Get("/api/admin/ratelimits", internalAuthOnly(deps.InternalAuthSecret,
+		handler.GetRateLimitPolicy(deps.RateLimitAdmin)))
+	admin.Post("/api/admin/ratelimits/reload", internalAuthOnly(deps.InternalAuthSecret,
+		handler.ReloadRateLimitPolicy(deps.RateLimitAdmin)))
 
 	// Vonage webhook routes (public — called by Vonage, no Firebase auth)
 	if deps.VonageDeps.APIKey != "" {
@@ -161,20 +489,48 @@ func New(deps *Dependencies) *chi.Mux {
 	docCRUD := handler.DocCRUDDeps{
 		DocRepo:          deps.DocRepo,
 		ChunkDeleter:     deps.ChunkDeleter,
+		ChunkLister:      deps.ChunkLister,
 		Storage:          deps.Storage,
 		ObjectDownloader: deps.ObjectDownloader,
 		BucketName:       deps.BucketName,
-		QueryCache:       deps.QueryCache,
+		Invalidator:      deps.CacheInvalidator,
+		Holds:            deps.LegalHolds,
+		PrivilegeState:   deps.PrivilegeState,
 	}
-	folderDeps := handler.FolderDeps{FolderRepo: deps.FolderRepo}
+	folderDeps := handler.FolderDeps{FolderRepo: deps.FolderRepo, Holds: deps.LegalHolds}
 
 	// Protected routes (require internal service auth or Firebase auth)
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.InternalOrFirebaseAuth(deps.AuthService, deps.InternalAuthSecret, deps.UserEnsurer))
+		r.Use(middleware.InternalOrFirebaseAuth(deps.AuthService, deps.InternalAuthSecret, deps.UserEnsurer, deps.APITokens))
+
+		// Personal access tokens only reach routes their scopes allow
+		r.Use(middleware.RequireTokenScope(apiTokenScope))
+
+		// Resolve the caller's organization for shared-content access checks
+		if deps.OrgResolver != nil {
+			r.Use(middleware.Organization(deps.OrgResolver))
+		}
+
+		// Route-level RBAC; the resolved role is cached in the context
+		if deps.RoleResolver != nil {
+			r.Use(middleware.Policy(deps.RoleResolver, routePermission))
+		}
+
+		// Audit successful mutating and content-reading routes, and the
+		// first request of each session as a login
+		if deps.AuditLogger != nil {
+			r.Use(middleware.Audit(deps.AuditLogger, routeAudit))
+			if deps.SessionTracker != nil {
+				r.Use(middleware.AuditLogin(deps.SessionTracker, deps.AuditLogger, deps.LoginIdle))
+			}
+		}
 
 		// General rate limit for all authenticated endpoints
-		if deps.GeneralRateLimiter != nil {
-			r.Use(middleware.RateLimit(deps.GeneralRateLimiter))
+		r.Use(rateLimitFor(deps, middleware.RouteGroupGeneral, deps.GeneralRateLimiter)...)
+
+		// Count public API requests against the api_calls quota
+		if deps.APIUsage != nil {
+			r.Use(middleware.APIUsage(deps.APIUsage))
 		}
 
 		// Non-SSE routes get a 30s write timeout to prevent slow-read attacks.
@@ -191,6 +547,7 @@ func New(deps *Dependencies) *chi.Mux {
 		r.With(timeout30s).Patch("/api/documents/{id}/tier", handler.UpdateDocumentTier(docCRUD))
 		// Note: GET /documents/{id}/privilege is not needed — privilege status is included in GET /documents/{id}
 		r.With(timeout30s).Patch("/api/documents/{id}/privilege", handler.ToggleDocPrivilege(docCRUD))
+		r.With(timeout30s).Get("/api/documents/{id}/chunks", handler.ListChunks(docCRUD))
 		r.With(timeout30s).Delete("/api/documents/{id}/chunks", handler.DeleteChunks(docCRUD))
 		r.With(timeout30s).Get("/api/documents/{id}/download", handler.DownloadDocument(docCRUD))
 		r.With(timeout30s).Post("/api/documents/{id}/verify", handler.VerifyIntegrity(docCRUD))
@@ -198,8 +555,9 @@ func New(deps *Dependencies) *chi.Mux {
 		r.With(timeout30s).Get("/api/documents/{id}/related", handler.RelatedDocuments(deps.RelatedDocsDeps))
 		r.With(timeout30s).Get("/api/documents/{id}/chunks/{chunkId}/preview", handler.ChunkPreview(deps.ChunkPreviewDeps))
 		// Ingest may take longer (pipeline processing)
-		r.With(middleware.Timeout(120 * time.Second)).Post("/api/documents/{id}/ingest", handler.IngestDocument(deps.IngestDeps))
-		r.With(middleware.Timeout(120 * time.Second)).Post("/api/documents/{id}/ingest-text", handler.IngestText(deps.IngestTextDeps))
+		r.With(middleware.Timeout(120*time.Second)).Post("/api/documents/{id}/ingest", handler.IngestDocument(deps.IngestDeps))
+		r.With(middleware.Timeout(120*time.Second)).Post("/api/documents/{id}/ingest-text", handler.IngestText(deps.IngestTextDeps))
+		r.With(timeout30s).Put("/api/documents/{id}/text", handler.StoreText(deps.IngestTextDeps))
 
 		// Folders
 		r.With(timeout30s).Get("/api/documents/folders", handler.ListFolders(folderDeps))
@@ -216,28 +574,84 @@ func New(deps *Dependencies) *chi.Mux {
 			r.With(timeout30s).Post("/api/privilege", handler.TogglePrivilege(handler.PrivilegeDeps{State: deps.PrivilegeState}))
 		}
 
-		// Chat — SSE streaming, NO write timeout. Stricter rate limit (10/min).
-		if deps.ChatRateLimiter != nil {
-			r.With(middleware.RateLimit(deps.ChatRateLimiter)).Post("/api/chat", handler.Chat(deps.ChatDeps))
-		} else {
-			r.Post("/api/chat", handler.Chat(deps.ChatDeps))
-		}
+		// Chat — SSE streaming, NO write timeout. Stricter rate limit (10/min on free).
+		r.With(rateLimitFor(deps, middleware.RouteGroupChat, deps.ChatRateLimiter)...).
+			Post("/api/chat", handler.Chat(deps.ChatDeps))
 
 		// Audit
 		r.With(timeout30s).Get("/api/audit", handler.ListAudit(deps.AuditDeps))
 		r.With(timeout30s).Get("/api/audit/export", handler.ExportAudit(deps.AuditDeps))
+		r.With(middleware.Timeout(120*time.Second)).Get("/api/audit/verify", handler.VerifyAudit(deps.AuditDeps))
+		r.With(timeout30s).Get("/api/audit/{id}/proof", handler.AuditProof(deps.AuditDeps))
 
 		// Content Gaps
 		r.With(timeout30s).Get("/api/content-gaps", handler.ListContentGaps(deps.ContentGapDeps))
 		r.With(timeout30s).Get("/api/content-gaps/summary", handler.ContentGapSummary(deps.ContentGapDeps))
 		r.With(timeout30s).Patch("/api/content-gaps/{id}", handler.UpdateContentGapStatus(deps.ContentGapDeps))
 
+		// Vaults
+		if deps.VaultDeps != nil {
+			r.With(timeout30s).Get("/api/vaults", handler.ListVaults(*deps.VaultDeps))
+			r.With(timeout30s).Post("/api/vaults", handler.CreateVault(*deps.VaultDeps))
+			r.With(timeout30s).Get("/api/vaults/{id}", handler.GetVault(*deps.VaultDeps))
+			r.With(timeout30s).Patch("/api/vaults/{id}", handler.UpdateVault(*deps.VaultDeps))
+			r.With(timeout30s).Delete("/api/vaults/{id}", handler.ArchiveVault(*deps.VaultDeps))
+			r.With(timeout30s).Post("/api/vaults/{id}/restore", handler.RestoreVault(*deps.VaultDeps))
+			r.With(timeout30s).Post("/api/vaults/{id}/documents", handler.MoveDocumentsToVault(*deps.VaultDeps))
+		}
+
+		// Per-vault PII redaction policies
+		if deps.PIIPolicyDeps != nil {
+			r.With(timeout30s).Get("/api/vaults/{id}/pii-policy", handler.GetPIIPolicy(*deps.PIIPolicyDeps))
+			r.With(timeout30s).Put("/api/vaults/{id}/pii-policy", handler.SetPIIPolicy(*deps.PIIPolicyDeps))
+			r.With(timeout30s).Delete("/api/vaults/{id}/pii-policy", handler.DeletePIIPolicy(*deps.PIIPolicyDeps))
+		}
+
+		// Sharing
+		if deps.ShareDeps != nil {
+			docShares, folderShares := model.ShareResourceDocument, model.ShareResourceFolder
+			r.With(timeout30s).Get("/api/documents/{id}/shares", handler.ListShares(*deps.ShareDeps, docShares))
+			r.With(timeout30s).Post("/api/documents/{id}/shares", handler.CreateShare(*deps.ShareDeps, docShares))
+			r.With(timeout30s).Delete("/api/documents/{id}/shares/{grantId}", handler.RevokeShare(*deps.ShareDeps, docShares))
+			r.With(timeout30s).Get("/api/folders/{id}/shares", handler.ListShares(*deps.ShareDeps, folderShares))
+			r.With(timeout30s).Post("/api/folders/{id}/shares", handler.CreateShare(*deps.ShareDeps, folderShares))
+			r.With(timeout30s).Delete("/api/folders/{id}/shares/{grantId}", handler.RevokeShare(*deps.ShareDeps, folderShares))
+			r.With(timeout30s).Get("/api/share-groups", handler.ListShareGroups(*deps.ShareDeps))
+			r.With(timeout30s).Post("/api/share-groups", handler.CreateShareGroup(*deps.ShareDeps))
+			r.With(timeout30s).Delete("/api/share-groups/{id}", handler.DeleteShareGroup(*deps.ShareDeps))
+			r.With(timeout30s).Post("/api/share-groups/{id}/members", handler.AddShareGroupMember(*deps.ShareDeps))
+			r.With(timeout30s).Delete("/api/share-groups/{id}/members/{userId}", handler.RemoveShareGroupMember(*deps.ShareDeps))
+		}
+
+		// Legal holds (report walks every document under the hold)
+		if deps.LegalHoldDeps != nil {
+			r.With(timeout30s).Get("/api/legal-holds", handler.ListLegalHolds(*deps.LegalHoldDeps))
+			r.With(timeout30s).Post("/api/legal-holds", handler.CreateLegalHold(*deps.LegalHoldDeps))
+			r.With(timeout30s).Post("/api/legal-holds/{id}/release", handler.ReleaseLegalHold(*deps.LegalHoldDeps))
+			r.With(middleware.Timeout(60*time.Second)).Get("/api/legal-holds/{id}/report", handler.LegalHoldReport(*deps.LegalHoldDeps))
+		}
+
+		// Retention policies and the purge dry run
+		if deps.RetentionDeps != nil {
+			r.With(timeout30s).Get("/api/retention/policies", handler.ListRetentionPolicies(*deps.RetentionDeps))
+			r.With(timeout30s).Put("/api/retention/policies", handler.SetRetentionPolicy(*deps.RetentionDeps))
+			r.With(timeout30s).Delete("/api/retention/policies/{id}", handler.DeleteRetentionPolicy(*deps.RetentionDeps))
+			r.With(middleware.Timeout(60*time.Second)).Get("/api/retention/purge-preview", handler.PurgePreview(*deps.RetentionDeps))
+		}
+
+		// Personal access tokens
+		if deps.APITokenDeps != nil {
+			r.With(timeout30s).Get("/api/tokens", handler.ListAPITokens(*deps.APITokenDeps))
+			r.With(timeout30s).Post("/api/tokens", handler.CreateAPIToken(*deps.APITokenDeps))
+			r.With(timeout30s).Delete("/api/tokens/{id}", handler.RevokeAPIToken(*deps.APITokenDeps))
+		}
+
 		// KB Health
 		r.With(timeout30s).Post("/api/vaults/{id}/health-check", handler.RunHealthCheck(deps.KBHealthDeps))
 		r.With(timeout30s).Get("/api/vaults/{id}/health-checks", handler.GetHealthHistory(deps.KBHealthDeps))
 
 		// Export (ZIP generation can take a while)
-		r.With(middleware.Timeout(60 * time.Second)).Get("/api/export", handler.ExportData(deps.ExportDeps))
+		r.With(middleware.Timeout(60*time.Second)).Get("/api/export", handler.ExportData(deps.ExportDeps))
 
 		// Voice transcription — single utterance STT via Deepgram
 		r.With(timeout30s).Post("/api/voice/transcribe", handler.Transcribe(deps.TranscribeDeps))
@@ -251,22 +665,30 @@ func New(deps *Dependencies) *chi.Mux {
 			r.With(timeout30s).Get("/api/v1/usage", handler.GetUsage(*deps.UsageDeps))
 		}
 
+		// Organizations — membership, roles and invitations
+		if deps.OrgDeps != nil {
+			r.With(timeout30s).Get("/api/org", handler.GetOrganization(*deps.OrgDeps))
+			r.With(timeout30s).Post("/api/org", handler.CreateOrganization(*deps.OrgDeps))
+			r.With(timeout30s).Patch("/api/org", handler.RenameOrganization(*deps.OrgDeps))
+			r.With(timeout30s).Get("/api/org/members", handler.ListOrgMembers(*deps.OrgDeps))
+			r.With(timeout30s).Patch("/api/org/members/{userId}", handler.UpdateOrgMember(*deps.OrgDeps))
+			r.With(timeout30s).Delete("/api/org/members/{userId}", handler.RemoveOrgMember(*deps.OrgDeps))
+			r.With(timeout30s).Get("/api/org/invites", handler.ListOrgInvites(*deps.OrgDeps))
+			r.With(timeout30s).Post("/api/org/invites", handler.CreateOrgInvite(*deps.OrgDeps))
+			r.With(timeout30s).Post("/api/org/invites/accept", handler.AcceptOrgInvite(*deps.OrgDeps))
+			r.With(timeout30s).Delete("/api/org/invites/{id}", handler.RevokeOrgInvite(*deps.OrgDeps))
+		}
+
 		// Proactive Insights (EPIC-028 Phase 4)
 		r.With(timeout30s).Get("/api/v1/insights", handler.ListInsights(deps.InsightDeps))
 		r.With(timeout30s).Patch("/api/v1/insights/{id}/acknowledge", handler.AcknowledgeInsight(deps.InsightDeps))
-		if deps.InsightRateLimiter != nil {
-			r.With(middleware.Timeout(60*time.Second), middleware.RateLimit(deps.InsightRateLimiter)).
-				Post("/api/v1/insights/scan", handler.ScanVault(deps.InsightDeps))
-		} else {
-			r.With(middleware.Timeout(60*time.Second)).
-				Post("/api/v1/insights/scan", handler.ScanVault(deps.InsightDeps))
-		}
+		insightMiddleware := append([]func(http.Handler) http.Handler{middleware.Timeout(60 * time.Second)},
+			rateLimitFor(deps, middleware.RouteGroupInsightScan, deps.InsightRateLimiter)...)
+		r.With(insightMiddleware...).Post("/api/v1/insights/scan", handler.ScanVault(deps.InsightDeps))
 
-		// Forge — AI generation, 60s timeout. Strictest rate limit (5/min).
-		forgeMiddleware := []func(http.Handler) http.Handler{middleware.Timeout(60 * time.Second)}
-		if deps.ForgeRateLimiter != nil {
-			forgeMiddleware = append(forgeMiddleware, middleware.RateLimit(deps.ForgeRateLimiter))
-		}
+		// Forge — AI generation, 60s timeout. Strictest rate limit (5/min on free).
+		forgeMiddleware := append([]func(http.Handler) http.Handler{middleware.Timeout(60 * time.Second)},
+			rateLimitFor(deps, middleware.RouteGroupForge, deps.ForgeRateLimiter)...)
 		r.With(forgeMiddleware...).Post("/api/forge", handler.ForgeHandler(deps.ForgeSvc))
 	})
 
@@ -6,11 +6,17 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"firebase.google.com/go/v4/auth"
+	"github.com/go-chi/chi/v5"
 	"github.com/connexus-ai/ragbox-backend/internal/handler"
+	"github.com/connexus-ai/ragbox-backend/internal/middleware"
 	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"github.com/connexus-ai/ragbox-backend/internal/rbac"
 	"github.com/connexus-ai/ragbox-backend/internal/repository"
 	"github.com/connexus-ai/ragbox-backend/internal/service"
 )
@@ -274,3 +280,373 @@ func TestInternalAuth_BadSecret_Returns401(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
 	}
 }
+
+func TestRateLimitPolicy_AppliesPerTier(t *testing.T) {
+	policy := middleware.RateLimitPolicy{
+		DefaultTier: "free",
+		Tiers: map[string]map[string]middleware.RateLimitRule{
+			"free":       {middleware.RouteGroupGeneral: {Requests: 1, Window: time.Minute}},
+			"enterprise": {middleware.RouteGroupGeneral: {Requests: 3, Window: time.Minute}},
+		},
+	}
+	tiers := map[string]string{"ent-user": "enterprise"}
+	limiter, err := middleware.NewPolicyLimiter(policy, func(_ context.Context, userID string) string {
+		return tiers[userID]
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer limiter.Stop()
+
+	client := &mockAuthClient{uid: "test-user"}
+	r := New(&Dependencies{
+		DB:                 &mockDB{},
+		AuthService:        service.NewAuthService(client),
+		FrontendURL:        "http://localhost:3000",
+		InternalAuthSecret: "test-secret-123",
+		DocRepo:            &mockDocRepo{},
+		FolderRepo:         &mockFolderRepo{},
+		PrivilegeState:     handler.NewPrivilegeState(),
+		RateLimitPolicy:    limiter,
+	})
+
+	allowed := func(userID string) int {
+		n := 0
+		for i := 0; i < 5; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/api/documents", nil)
+			req.Header.Set("X-Internal-Auth", "test-secret-123")
+			req.Header.Set("X-User-ID", userID)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code == http.StatusOK {
+				n++
+			}
+			if rec.Header().Get("RateLimit-Limit") == "" {
+				t.Errorf("%s: missing RateLimit-Limit header", userID)
+			}
+		}
+		return n
+	}
+	if got := allowed("free-user"); got != 1 {
+		t.Errorf("free allowed = %d, want 1", got)
+	}
+	if got := allowed("ent-user"); got != 3 {
+		t.Errorf("enterprise allowed = %d, want 3", got)
+	}
+}
+
+// stubTokens resolves a single read-only personal access token.
+type stubTokens struct{}
+
+func (stubTokens) Authenticate(_ context.Context, token string) (*model.APIToken, error) {
+	if token != model.APITokenPrefix+"read-only" {
+		return nil, service.ErrAPITokenUnauthorized
+	}
+	return &model.APIToken{ID: "tok-1", UserID: "token-user", Scopes: []model.APITokenScope{model.APIScopeRead}}, nil
+}
+
+func (stubTokens) RecordUse(context.Context, string) error { return nil }
+
+func TestAPIToken_ScopesEnforcedPerRoute(t *testing.T) {
+	client := &mockAuthClient{err: fmt.Errorf("firebase should not be called")}
+	r := New(&Dependencies{
+		DB:             &mockDB{},
+		AuthService:    service.NewAuthService(client),
+		FrontendURL:    "http://localhost:3000",
+		DocRepo:        &mockDocRepo{},
+		FolderRepo:     &mockFolderRepo{},
+		PrivilegeState: handler.NewPrivilegeState(),
+		APITokens:      stubTokens{},
+	})
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/documents", http.StatusOK},
+		{http.MethodPost, "/api/chat", http.StatusForbidden},
+		{http.MethodPost, "/api/documents/folders", http.StatusForbidden},
+		{http.MethodPost, "/api/privilege", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer "+model.APITokenPrefix+"read-only")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestAPITokenScope_RouteDefaults(t *testing.T) {
+	mux := chi.NewRouter()
+	var got model.APITokenScope
+	capture := func(w http.ResponseWriter, r *http.Request) { got = apiTokenScope(r) }
+	mux.Post("/api/chat", capture)
+	mux.Get("/api/documents/{id}", capture)
+	mux.Delete("/api/documents/{id}", capture)
+	mux.Get("/api/tokens", capture)
+	mux.Post("/api/org", capture)
+
+	tests := []struct {
+		method, path string
+		want         model.APITokenScope
+	}{
+		{http.MethodPost, "/api/chat", model.APIScopeQuery},
+		{http.MethodGet, "/api/documents/abc", model.APIScopeRead},
+		{http.MethodDelete, "/api/documents/abc", model.APIScopeUpload},
+		{http.MethodGet, "/api/tokens", model.APIScopeAdmin},
+		{http.MethodPost, "/api/org", model.APIScopeAdmin},
+	}
+	for _, tt := range tests {
+		got = ""
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+		if got != tt.want {
+			t.Errorf("%s %s: scope = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+// roleIsUserID resolves each user's role to their user ID, so tests pick a
+// role with the X-User-ID header.
+type roleIsUserID struct{}
+
+func (roleIsUserID) GetUserRole(_ context.Context, userID string) (string, error) {
+	return userID, nil
+}
+
+// newRBACTestRouter mounts every optional route group with RBAC enforced.
+func newRBACTestRouter() *chi.Mux {
+	return New(&Dependencies{
+		DB:                 &mockDB{},
+		AuthService:        service.NewAuthService(&mockAuthClient{uid: "test-user"}),
+		FrontendURL:        "http://localhost:3000",
+		InternalAuthSecret: "test-secret-123",
+		DocRepo:            &mockDocRepo{},
+		FolderRepo:         &mockFolderRepo{},
+		PrivilegeState:     handler.NewPrivilegeState(),
+		PrivilegeDeps:      &handler.PrivilegeDeps{State: handler.NewPrivilegeState()},
+		VaultDeps:          &handler.VaultDeps{},
+		ShareDeps:          &handler.ShareDeps{},
+		LegalHoldDeps:      &handler.LegalHoldDeps{},
+		RetentionDeps:      &handler.RetentionDeps{},
+		PIIPolicyDeps:      &handler.PIIPolicyDeps{},
+		APITokenDeps:       &handler.APITokenDeps{},
+		UsageDeps:          &handler.UsageDeps{},
+		OrgDeps:            &handler.OrgDeps{},
+		RoleResolver:       roleIsUserID{},
+	})
+}
+
+// publicRoutes are mounted outside the protected group and have no RBAC policy.
+var publicRoutes = map[string]bool{
+	"GET /api/health":                        true,
+	"POST /api/admin/migrate":                true,
+	"GET /api/admin/cache/stats":             true,
+	"DELETE /api/admin/cache/users/{userId}": true,
+	"GET /api/admin/ratelimits":              true,
+	"POST /api/admin/ratelimits/reload":      true,
+}
+
+// protectedRoutes lists every "METHOD pattern" behind auth.
+func protectedRoutes(t *testing.T, r *chi.Mux) []string {
+	t.Helper()
+	var routes []string
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if key := method + " " + route; !publicRoutes[key] {
+			routes = append(routes, key)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return routes
+}
+
+func TestRoutePermissions_CoverEveryRoute(t *testing.T) {
+	routes := protectedRoutes(t, newRBACTestRouter())
+	seen := map[string]bool{}
+	for _, route := range routes {
+		seen[route] = true
+		if _, ok := routePermissions[route]; !ok {
+			t.Errorf("%s has no RBAC policy", route)
+		}
+	}
+	for route := range routePermissions {
+		if !seen[route] {
+			t.Errorf("routePermissions lists %s, which is not mounted", route)
+		}
+	}
+}
+
+// policyAllows reports whether a request as role got past the Policy
+// middleware: the handler ran, or panicked on its empty test dependencies,
+// instead of the policy refusing it.
+func policyAllows(h http.Handler, method, path, role string) (allowed bool) {
+	defer func() {
+		if recover() != nil {
+			allowed = true
+		}
+	}()
+	req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+	req.Header.Set("X-Internal-Auth", "test-secret-123")
+	req.Header.Set("X-User-ID", role)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), `"insufficient permissions"`)
+}
+
+func TestPolicy_EveryRouteEveryRole(t *testing.T) {
+	r := newRBACTestRouter()
+	param := regexp.MustCompile(`\{[^}]+\}`)
+	roles := []string{"Partner", "Associate", "Auditor", "admin", "Intern"}
+
+	for _, route := range protectedRoutes(t, r) {
+		method, pattern, _ := strings.Cut(route, " ")
+		path := param.ReplaceAllString(pattern, "00000000-0000-0000-0000-000000000001")
+		for _, role := range roles {
+			want := rbac.Can(role, routePermissions[route])
+			if got := policyAllows(r, method, path, role); got != want {
+				t.Errorf("%s as %s: allowed = %v, want %v", route, role, got, want)
+			}
+		}
+	}
+}
+
+func TestPolicy_RoleExpectations(t *testing.T) {
+	r := newRBACTestRouter()
+	tests := []struct {
+		role, method, path string
+		want               bool
+	}{
+		{"Partner", http.MethodPost, "/api/privilege", true},
+		{"Associate", http.MethodPost, "/api/privilege", false},
+		{"Associate", http.MethodGet, "/api/documents", true},
+		{"Associate", http.MethodPost, "/api/chat", true},
+		{"Partner", http.MethodPost, "/api/legal-holds", true},
+		{"Associate", http.MethodPost, "/api/legal-holds", false},
+		{"Associate", http.MethodGet, "/api/legal-holds", false},
+		{"Partner", http.MethodPut, "/api/retention/policies", true},
+		{"Associate", http.MethodPut, "/api/retention/policies", false},
+		{"Associate", http.MethodGet, "/api/retention/purge-preview", false},
+		{"Auditor", http.MethodGet, "/api/audit", true},
+		{"Auditor", http.MethodGet, "/api/audit/export", true},
+		{"Auditor", http.MethodGet, "/api/audit/entry-1/proof", true},
+		{"Auditor", http.MethodGet, "/api/export", true},
+		{"Auditor", http.MethodGet, "/api/documents", false},
+		{"Auditor", http.MethodGet, "/api/documents/00000000-0000-0000-0000-000000000001/download", false},
+		{"Auditor", http.MethodPost, "/api/chat", false},
+		{"Auditor", http.MethodPost, "/api/documents/extract", false},
+		{"Auditor", http.MethodPost, "/api/privilege", false},
+		{"admin", http.MethodPost, "/api/privilege", true},
+	}
+	for _, tt := range tests {
+		if got := policyAllows(r, tt.method, tt.path, tt.role); got != tt.want {
+			t.Errorf("%s %s as %s: allowed = %v, want %v", tt.method, tt.path, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestRouteAudits_CoverEveryMutatingRoute(t *testing.T) {
+	seen := map[string]bool{}
+	err := chi.Walk(newRBACTestRouter(), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		key := method + " " + route
+		seen[key] = true
+		_, audited := routeAudits[key]
+		if audited && auditedElsewhere[key] {
+			t.Errorf("%s is audited twice", key)
+		}
+		if method != http.MethodGet && !audited && !auditedElsewhere[key] {
+			t.Errorf("%s is not audited", key)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for route := range routeAudits {
+		if !seen[route] {
+			t.Errorf("routeAudits lists %s, which is not mounted", route)
+		}
+	}
+	for route := range auditedElsewhere {
+		if !seen[route] {
+			t.Errorf("auditedElsewhere lists %s, which is not mounted", route)
+		}
+	}
+
+	// Reading document content is audited as well as changing it.
+	for _, route := range []string{
+		"GET /api/documents/{id}",
+		"GET /api/documents/{id}/download",
+		"GET /api/documents/{id}/chunks",
+		"GET /api/documents/{id}/chunks/{chunkId}/preview",
+		"GET /api/export",
+		"GET /api/audit/export",
+	} {
+		if _, ok := routeAudits[route]; !ok {
+			t.Errorf("%s is not audited", route)
+		}
+	}
+}
+
+type recordedAudit struct {
+	action, userID, resourceID, resourceType string
+	meta                                     service.RequestMeta
+}
+
+type recordingAuditLogger struct {
+	entries []recordedAudit
+}
+
+func (l *recordingAuditLogger) LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, _ map[string]interface{}) error {
+	meta, _ := service.RequestMetaFromContext(ctx)
+	l.entries = append(l.entries, recordedAudit{action, userID, resourceID, resourceType, meta})
+	return nil
+}
+
+func TestAudit_RecordsSuccessfulRoutesWithRequestMeta(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	r := New(&Dependencies{
+		DB:                 &mockDB{},
+		AuthService:        service.NewAuthService(&mockAuthClient{uid: "test-user"}),
+		FrontendURL:        "http://localhost:3000",
+		InternalAuthSecret: "test-secret-123",
+		DocRepo:            &mockDocRepo{},
+		FolderRepo:         &mockFolderRepo{},
+		PrivilegeState:     handler.NewPrivilegeState(),
+		AuditLogger:        logger,
+	})
+
+	do := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Internal-Auth", "test-secret-123")
+		req.Header.Set("X-User-ID", "test-user")
+		req.Header.Set("User-Agent", "ragbox-test/1.0")
+		req.Header.Set("X-Forwarded-For", "198.51.100.7")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := do(http.MethodGet, "/api/documents", ""); code != http.StatusOK {
+		t.Fatalf("list: status = %d", code)
+	}
+	if code := do(http.MethodPost, "/api/documents/folders", `{"name":""}`); code != http.StatusBadRequest {
+		t.Fatalf("invalid create: status = %d", code)
+	}
+	if len(logger.entries) != 0 {
+		t.Fatalf("unaudited and failed requests logged %+v", logger.entries)
+	}
+
+	if code := do(http.MethodDelete, "/api/documents/folders/00000000-0000-0000-0000-000000000001", ""); code != http.StatusOK {
+		t.Fatalf("delete: status = %d", code)
+	}
+	want := recordedAudit{model.AuditFolderDelete, "test-user", "00000000-0000-0000-0000-000000000001", "folder",
+		service.RequestMeta{IPAddress: "198.51.100.7", UserAgent: "ragbox-test/1.0"}}
+	if len(logger.entries) != 1 || logger.entries[0] != want {
+		t.Errorf("entries = %+v, want [%+v]", logger.entries, want)
+	}
+}
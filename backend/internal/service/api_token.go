@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// API token errors. Handlers map them to HTTP statuses.
+var (
+	ErrInvalidAPIToken      = errors.New("invalid API token request")
+	ErrAPITokenNotFound     = errors.New("API token not found")
+	ErrAPITokenLimit        = errors.New("API token limit reached")
+	ErrAPITokenUnauthorized = errors.New("API token is invalid, expired or revoked")
+)
+
+// MaxAPITokensPerUser caps the active tokens a user can hold.
+const MaxAPITokensPerUser = 25
+
+// apiTokenDisplayLen is how much of a token is kept in clear for display.
+const apiTokenDisplayLen = len(model.APITokenPrefix) + 4
+
+// APITokenRepository persists personal access tokens by hash.
+// Implemented by repository.APITokenRepo.
+type APITokenRepository interface {
+	Create(ctx context.Context, t *model.APIToken, tokenHash string) error
+	ListByUser(ctx context.Context, userID string) ([]model.APIToken, error)
+	// GetByHash returns the token with tokenHash, or nil. Revoked and
+	// expired tokens are returned; callers check Active.
+	GetByHash(ctx context.Context, tokenHash string) (*model.APIToken, error)
+	// Revoke marks userID's token revoked. Returns ErrAPITokenNotFound if
+	// the user has no such unrevoked token.
+	Revoke(ctx context.Context, userID, id string, at time.Time) error
+	// CountActive counts userID's unrevoked, unexpired tokens.
+	CountActive(ctx context.Context, userID string, now time.Time) (int, error)
+	// RecordUse sets last_used_at and bumps the token's request count.
+	RecordUse(ctx context.Context, id string, at time.Time) error
+}
+
+// TokenAuditLogger records token creation and revocation.
+type TokenAuditLogger interface {
+	LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error
+}
+
+// CreateAPITokenRequest describes a new token. ExpiresAt nil means the
+// token never expires.
+type CreateAPITokenRequest struct {
+	Name      string
+	Scopes    []model.APITokenScope
+	ExpiresAt *time.Time
+}
+
+// APITokenService issues, authenticates and revokes personal access tokens.
+type APITokenService struct {
+	repo  APITokenRepository
+	audit TokenAuditLogger
+	now   func() time.Time
+}
+
+// NewAPITokenService creates an APITokenService. audit may be nil.
+func NewAPITokenService(repo APITokenRepository, audit TokenAuditLogger) *APITokenService {
+	return &APITokenService{repo: repo, audit: audit, now: time.Now}
+}
+
+// Create issues a token for userID and returns it with its plaintext, which
+// is not stored and cannot be retrieved again.
+func (s *APITokenService) Create(ctx context.Context, userID string, req CreateAPITokenRequest) (*model.APIToken, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, "", fmt.Errorf("%w: name must be 1-100 characters", ErrInvalidAPIToken)
+	}
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	now := s.now().UTC()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, "", fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidAPIToken)
+	}
+
+	n, err := s.repo.CountActive(ctx, userID, now)
+	if err != nil {
+		return nil, "", fmt.Errorf("service.APIToken.Create: %w", err)
+	}
+	if n >= MaxAPITokensPerUser {
+		return nil, "", ErrAPITokenLimit
+	}
+
+	plaintext, err := newAPIToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("service.APIToken.Create: %w", err)
+	}
+	t := &model.APIToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plaintext[:apiTokenDisplayLen],
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+	if err := s.repo.Create(ctx, t, hashAPIToken(plaintext)); err != nil {
+		return nil, "", fmt.Errorf("service.APIToken.Create: %w", err)
+	}
+
+	details := map[string]interface{}{"name": t.Name, "scopes": t.Scopes}
+	if t.ExpiresAt != nil {
+		details["expiresAt"] = t.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	s.log(ctx, model.AuditAPITokenCreate, userID, t.ID, details)
+	return t, plaintext, nil
+}
+
+// List returns userID's tokens, including revoked and expired ones.
+func (s *APITokenService) List(ctx context.Context, userID string) ([]model.APIToken, error) {
+	tokens, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.APIToken.List: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke revokes one of userID's tokens. It stops authenticating at once.
+func (s *APITokenService) Revoke(ctx context.Context, userID, id string) error {
+	if err := s.repo.Revoke(ctx, userID, id, s.now().UTC()); err != nil {
+		if errors.Is(err, ErrAPITokenNotFound) {
+			return err
+		}
+		return fmt.Errorf("service.APIToken.Revoke: %w", err)
+	}
+	s.log(ctx, model.AuditAPITokenRevoke, userID, id, nil)
+	return nil
+}
+
+// Authenticate resolves a plaintext token to its record. It returns
+// ErrAPITokenUnauthorized for unknown, revoked and expired tokens.
+func (s *APITokenService) Authenticate(ctx context.Context, plaintext string) (*model.APIToken, error) {
+	if !strings.HasPrefix(plaintext, model.APITokenPrefix) {
+		return nil, ErrAPITokenUnauthorized
+	}
+	t, err := s.repo.GetByHash(ctx, hashAPIToken(plaintext))
+	if err != nil {
+		return nil, fmt.Errorf("service.APIToken.Authenticate: %w", err)
+	}
+	if t == nil || !t.Active(s.now()) {
+		return nil, ErrAPITokenUnauthorized
+	}
+	return t, nil
+}
+
+// RecordUse updates the token's last-used time and request count.
+func (s *APITokenService) RecordUse(ctx context.Context, id string) error {
+	if err := s.repo.RecordUse(ctx, id, s.now().UTC()); err != nil {
+		slog.Warn("[APIToken] record use failed", "token_id", id, "error", err)
+		return fmt.Errorf("service.APIToken.RecordUse: %w", err)
+	}
+	return nil
+}
+
+func (s *APITokenService) log(ctx context.Context, action, userID, tokenID string, details map[string]interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogWithDetails(ctx, action, userID, tokenID, "api_token", details); err != nil {
+		// Don't fail the change on audit error — log and continue
+		slog.Error("[APIToken] audit log failed", "action", action, "user_id", userID, "token_id", tokenID, "error", err)
+	}
+}
+
+// normalizeScopes validates scopes and drops duplicates. At least one is required.
+func normalizeScopes(scopes []model.APITokenScope) ([]model.APITokenScope, error) {
+	seen := make(map[model.APITokenScope]bool, len(scopes))
+	out := make([]model.APITokenScope, 0, len(scopes))
+	for _, sc := range scopes {
+		if !model.ValidAPITokenScope(sc) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIToken, sc)
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			out = append(out, sc)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIToken)
+	}
+	return out, nil
+}
+
+func newAPIToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return model.APITokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
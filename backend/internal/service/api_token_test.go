@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// memAPITokenRepo implements APITokenRepository in memory.
+type memAPITokenRepo struct {
+	tokens map[string]*model.APIToken // by hash
+}
+
+func newMemAPITokenRepo() *memAPITokenRepo {
+	return &memAPITokenRepo{tokens: map[string]*model.APIToken{}}
+}
+
+func (m *memAPITokenRepo) Create(_ context.Context, t *model.APIToken, tokenHash string) error {
+	cp := *t
+	m.tokens[tokenHash] = &cp
+	return nil
+}
+
+func (m *memAPITokenRepo) ListByUser(_ context.Context, userID string) ([]model.APIToken, error) {
+	var out []model.APIToken
+	for _, t := range m.tokens {
+		if t.UserID == userID {
+			out = append(out, *t)
+		}
+	}
+	return out, nil
+}
+
+func (m *memAPITokenRepo) GetByHash(_ context.Context, tokenHash string) (*model.APIToken, error) {
+	if t, ok := m.tokens[tokenHash]; ok {
+		cp := *t
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *memAPITokenRepo) Revoke(_ context.Context, userID, id string, at time.Time) error {
+	for _, t := range m.tokens {
+		if t.ID == id && t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &at
+			return nil
+		}
+	}
+	return ErrAPITokenNotFound
+}
+
+func (m *memAPITokenRepo) CountActive(_ context.Context, userID string, now time.Time) (int, error) {
+	n := 0
+	for _, t := range m.tokens {
+		if t.UserID == userID && t.Active(now) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memAPITokenRepo) RecordUse(_ context.Context, id string, at time.Time) error {
+	for _, t := range m.tokens {
+		if t.ID == id {
+			t.LastUsedAt = &at
+			t.RequestCount++
+		}
+	}
+	return nil
+}
+
+func TestAPIToken_CreateAuthenticateRevoke(t *testing.T) {
+	repo := newMemAPITokenRepo()
+	audit := &recordingAudit{}
+	svc := NewAPITokenService(repo, audit)
+	ctx := context.Background()
+
+	tok, secret, err := svc.Create(ctx, "u1", CreateAPITokenRequest{
+		Name:   " ci ",
+		Scopes: []model.APITokenScope{model.APIScopeRead, model.APIScopeRead, model.APIScopeQuery},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(secret, model.APITokenPrefix) || !strings.HasPrefix(secret, tok.Prefix) {
+		t.Errorf("secret %q does not start with prefix %q", secret, tok.Prefix)
+	}
+	if tok.Name != "ci" || len(tok.Scopes) != 2 {
+		t.Errorf("token = %+v, want trimmed name and deduplicated scopes", tok)
+	}
+	for h := range repo.tokens {
+		if strings.Contains(h, secret) || h == secret {
+			t.Fatal("plaintext token stored")
+		}
+	}
+
+	got, err := svc.Authenticate(ctx, secret)
+	if err != nil || got.ID != tok.ID || got.UserID != "u1" {
+		t.Fatalf("Authenticate = %+v, %v", got, err)
+	}
+	if _, err := svc.Authenticate(ctx, secret+"x"); !errors.Is(err, ErrAPITokenUnauthorized) {
+		t.Errorf("wrong token: err = %v, want ErrAPITokenUnauthorized", err)
+	}
+
+	if err := svc.Revoke(ctx, "u2", tok.ID); !errors.Is(err, ErrAPITokenNotFound) {
+		t.Errorf("revoke by another user: err = %v, want ErrAPITokenNotFound", err)
+	}
+	if err := svc.Revoke(ctx, "u1", tok.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, secret); !errors.Is(err, ErrAPITokenUnauthorized) {
+		t.Errorf("revoked token: err = %v, want ErrAPITokenUnauthorized", err)
+	}
+
+	want := []string{model.AuditAPITokenCreate, model.AuditAPITokenRevoke}
+	if len(audit.actions) != len(want) || audit.actions[0] != want[0] || audit.actions[1] != want[1] {
+		t.Errorf("audit = %v, want %v", audit.actions, want)
+	}
+}
+
+func TestAPIToken_Expiry(t *testing.T) {
+	svc := NewAPITokenService(newMemAPITokenRepo(), nil)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	exp := now.Add(time.Hour)
+	_, secret, err := svc.Create(ctx, "u1", CreateAPITokenRequest{Name: "short", Scopes: []model.APITokenScope{model.APIScopeRead}, ExpiresAt: &exp})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, secret); err != nil {
+		t.Fatalf("Authenticate before expiry: %v", err)
+	}
+	now = exp
+	if _, err := svc.Authenticate(ctx, secret); !errors.Is(err, ErrAPITokenUnauthorized) {
+		t.Errorf("Authenticate at expiry: err = %v, want ErrAPITokenUnauthorized", err)
+	}
+}
+
+func TestAPIToken_CreateValidation(t *testing.T) {
+	svc := NewAPITokenService(newMemAPITokenRepo(), nil)
+	past := time.Now().Add(-time.Minute)
+	read := []model.APITokenScope{model.APIScopeRead}
+
+	tests := []struct {
+		name string
+		req  CreateAPITokenRequest
+	}{
+		{"no name", CreateAPITokenRequest{Scopes: read}},
+		{"no scopes", CreateAPITokenRequest{Name: "t"}},
+		{"unknown scope", CreateAPITokenRequest{Name: "t", Scopes: []model.APITokenScope{"write"}}},
+		{"expired", CreateAPITokenRequest{Name: "t", Scopes: read, ExpiresAt: &past}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := svc.Create(context.Background(), "u1", tt.req); !errors.Is(err, ErrInvalidAPIToken) {
+				t.Errorf("err = %v, want ErrInvalidAPIToken", err)
+			}
+		})
+	}
+}
+
+func TestAPIToken_Limit(t *testing.T) {
+	svc := NewAPITokenService(newMemAPITokenRepo(), nil)
+	ctx := context.Background()
+	req := CreateAPITokenRequest{Name: "t", Scopes: []model.APITokenScope{model.APIScopeRead}}
+	for i := 0; i < MaxAPITokensPerUser; i++ {
+		if _, _, err := svc.Create(ctx, "u1", req); err != nil {
+			t.Fatalf("Create %d: %v", i, err)
+		}
+	}
+	if _, _, err := svc.Create(ctx, "u1", req); !errors.Is(err, ErrAPITokenLimit) {
+		t.Errorf("over limit: err = %v, want ErrAPITokenLimit", err)
+	}
+}
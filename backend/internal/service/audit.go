@@ -2,11 +2,10 @@ package service
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,17 +15,34 @@ import (
 
 // AuditRepository abstracts PostgreSQL audit log storage.
 type AuditRepository interface {
-	Create(ctx context.Context, entry *model.AuditLog) error
-	GetLatestHash(ctx context.Context) (string, error)
-	GetRange(ctx context.Context, startID, endID string) ([]model.AuditLog, error)
+	// Append adds entry to the end of its tenant's hash chain. Under a lock
+	// on the chain head it sets TenantID, Seq, PrevHash and DetailsHash, and
+	// replaces Details with its stored form, so concurrent writers on any
+	// number of instances extend the chain one at a time.
+	Append(ctx context.Context, entry *model.AuditLog) error
 }
 
-// VerificationResult reports the outcome of a hash-chain verification.
-type VerificationResult struct {
-	Valid          bool   `json:"valid"`
-	EntriesChecked int    `json:"entriesChecked"`
-	BrokenAt       string `json:"brokenAt,omitempty"` // ID of the first invalid entry
-	BrokenIndex    int    `json:"brokenIndex,omitempty"`
+// RequestMeta is the request metadata recorded with audit entries.
+type RequestMeta struct {
+	IPAddress string
+	UserAgent string
+}
+
+type requestMetaKey struct{}
+
+// maxUserAgentLen caps the stored user agent; clients control the header.
+const maxUserAgentLen = 512
+
+// WithRequestMeta returns a context carrying request metadata, which
+// LogWithDetails records on every entry logged with it.
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext returns the request metadata carried by ctx.
+func RequestMetaFromContext(ctx context.Context) (RequestMeta, bool) {
+	meta, ok := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta, ok
 }
 
 // BigQueryWriter abstracts async writes to BigQuery for WORM archival.
@@ -36,28 +52,20 @@ type BigQueryWriter interface {
 
 // AuditService provides audit logging with SHA-256 hash-chain integrity.
 // Writes to PostgreSQL (immediate) and BigQuery (async WORM archive).
+// The chain is linked by the repository, so it holds no chain state itself.
 type AuditService struct {
-	repo     AuditRepository
-	bq       BigQueryWriter // nil means BQ disabled
-	mu       sync.Mutex     // protects lastHash for concurrent writes
-	lastHash string
+	repo AuditRepository
+	bq   BigQueryWriter // nil means BQ disabled
 }
 
 // Compile-time check that AuditService implements AuditLogger.
 var _ AuditLogger = (*AuditService)(nil)
 
-// NewAuditService creates an AuditService. It fetches the latest hash from the
-// repo to continue the chain. bqWriter may be nil to disable BigQuery writes.
-func NewAuditService(repo AuditRepository, bqWriter BigQueryWriter) (*AuditService, error) {
-	lastHash, err := repo.GetLatestHash(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("audit: failed to fetch latest hash: %w", err)
-	}
-	return &AuditService{
-		repo:     repo,
-		bq:       bqWriter,
-		lastHash: lastHash,
-	}, nil
+// NewAuditService creates an AuditService. bqWriter may be nil to disable
+// BigQuery writes; those are best effort, so for a durable archive pass nil
+// and deliver through the outbox with BigQuerySink instead.
+func NewAuditService(repo AuditRepository, bqWriter BigQueryWriter) *AuditService {
+	return &AuditService{repo: repo, bq: bqWriter}
 }
 
 // Log implements AuditLogger for pipeline integration (simple signature).
@@ -65,15 +73,16 @@ func (s *AuditService) Log(ctx context.Context, action, userID, resourceID, reso
 	return s.LogWithDetails(ctx, action, userID, resourceID, resourceType, nil)
 }
 
-// LogWithDetails creates an audit entry with optional JSON details.
-// It computes the SHA-256 hash chain, writes to PG immediately,
+// LogWithDetails creates an audit entry with optional JSON details, and the
+// caller's IP address and user agent when ctx carries RequestMeta.
+// It appends to the tenant's hash chain in PG immediately,
 // and writes to BigQuery asynchronously.
 func (s *AuditService) LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error {
 	entry := &model.AuditLog{
 		ID:           uuid.New().String(),
 		Action:       action,
 		Severity:     severityForAction(action),
-		CreatedAt:    time.Now().UTC(),
+		CreatedAt:    time.Now().UTC().Truncate(time.Microsecond), // PG precision
 	}
 
 	if userID != "" {
@@ -85,6 +94,15 @@ func (s *AuditService) LogWithDetails(ctx context.Context, action, userID, resou
 	if resourceType != "" {
 		entry.ResourceType = &resourceType
 	}
+	if meta, ok := RequestMetaFromContext(ctx); ok {
+		if meta.IPAddress != "" {
+			entry.IPAddress = &meta.IPAddress
+		}
+		// Headers are client bytes; PG text must be valid UTF-8.
+		if ua := strings.ToValidUTF8(truncateStr(meta.UserAgent, maxUserAgentLen), ""); ua != "" {
+			entry.UserAgent = &ua
+		}
+	}
 
 	if details != nil {
 		detailsJSON, err := json.Marshal(details)
@@ -94,15 +112,8 @@ func (s *AuditService) LogWithDetails(ctx context.Context, action, userID, resou
 		entry.Details = detailsJSON
 	}
 
-	// Compute hash chain: SHA-256(previousHash + action + timestamp + details)
-	s.mu.Lock()
-	hash := computeHash(s.lastHash, entry)
-	entry.DetailsHash = &hash
-	s.lastHash = hash
-	s.mu.Unlock()
-
-	// Write to PostgreSQL (immediate)
-	if err := s.repo.Create(ctx, entry); err != nil {
+	// Append to the hash chain in PostgreSQL (immediate)
+	if err := s.repo.Append(ctx, entry); err != nil {
 		return fmt.Errorf("audit: pg write: %w", err)
 	}
 
@@ -118,59 +129,6 @@ func (s *AuditService) LogWithDetails(ctx context.Context, action, userID, resou
 	return nil
 }
 
-// VerifyChain validates the hash-chain integrity for a range of audit entries.
-// It walks from startID to endID and verifies each entry's hash links correctly.
-func (s *AuditService) VerifyChain(ctx context.Context, startID, endID string) (*VerificationResult, error) {
-	entries, err := s.repo.GetRange(ctx, startID, endID)
-	if err != nil {
-		return nil, fmt.Errorf("audit: verify chain: %w", err)
-	}
-
-	if len(entries) == 0 {
-		return &VerificationResult{Valid: true, EntriesChecked: 0}, nil
-	}
-
-	// For the first entry in the range, we cannot verify its link to the previous
-	// entry outside the range. We start verification from the second entry.
-	var prevHash string
-	if entries[0].DetailsHash != nil {
-		prevHash = *entries[0].DetailsHash
-	}
-
-	for i := 1; i < len(entries); i++ {
-		expected := computeHash(prevHash, &entries[i])
-		actual := ""
-		if entries[i].DetailsHash != nil {
-			actual = *entries[i].DetailsHash
-		}
-
-		if actual != expected {
-			return &VerificationResult{
-				Valid:          false,
-				EntriesChecked: i + 1,
-				BrokenAt:       entries[i].ID,
-				BrokenIndex:    i,
-			}, nil
-		}
-		prevHash = actual
-	}
-
-	return &VerificationResult{Valid: true, EntriesChecked: len(entries)}, nil
-}
-
-// computeHash produces a SHA-256 hash linking to the previous entry.
-// Formula: SHA-256(previousHash + action + createdAt(RFC3339Nano) + details)
-func computeHash(previousHash string, entry *model.AuditLog) string {
-	h := sha256.New()
-	h.Write([]byte(previousHash))
-	h.Write([]byte(entry.Action))
-	h.Write([]byte(entry.CreatedAt.Format(time.RFC3339Nano)))
-	if entry.Details != nil {
-		h.Write(entry.Details)
-	}
-	return fmt.Sprintf("%x", h.Sum(nil))
-}
-
 // severityForAction maps audit actions to severity levels.
 func severityForAction(action string) string {
 	switch action {
@@ -178,9 +136,33 @@ func severityForAction(action string) string {
 		return "HIGH"
 	case model.AuditPrivilegeToggle:
 		return "HIGH"
+	case model.AuditDocumentPrivilegeChange, model.AuditDocumentChunksDelete:
+		return "HIGH"
+	case model.AuditOrgMemberUpdate, model.AuditOrgMemberRemove:
+		return "HIGH"
+	case model.AuditAdminMigrate:
+		return "HIGH"
+	case model.AuditLegalHoldCreate, model.AuditLegalHoldRelease, model.AuditLegalHoldBlocked:
+		return "HIGH"
+	case model.AuditDocumentPurge, model.AuditRetentionPolicySet, model.AuditRetentionPolicyDelete:
+		return "HIGH"
+	case model.AuditPIIPolicySet, model.AuditPIIPolicyDelete:
+		return "HIGH"
 	case model.AuditSilenceTriggered:
 		return "MEDIUM"
-	case model.AuditDataExport:
+	case model.AuditDataExport, model.AuditAuditExport, model.AuditDocumentDownload:
+		return "MEDIUM"
+	case model.AuditShareGrant, model.AuditShareRevoke, model.AuditShareGroupAdd, model.AuditShareGroupRemove:
+		return "MEDIUM"
+	case model.AuditAPITokenCreate, model.AuditAPITokenRevoke:
+		return "MEDIUM"
+	case model.AuditFolderDelete, model.AuditVaultArchive, model.AuditPersonaConfigUpdate:
+		return "MEDIUM"
+	case model.AuditOrgInviteCreate, model.AuditOrgInviteAccept:
+		return "MEDIUM"
+	case model.AuditAdminCacheFlush, model.AuditAdminRateLimitReload:
+		return "MEDIUM"
+	case model.AuditLegalHoldReport, model.AuditRetentionPreview:
 		return "MEDIUM"
 	case model.AuditDocumentUpload:
 		return "LOW"
@@ -192,6 +174,16 @@ func severityForAction(action string) string {
 		return "LOW"
 	case model.AuditUserLogin:
 		return "LOW"
+	case model.AuditDocumentView, model.AuditChunkPreview, model.AuditDocumentUploadRequest, model.AuditDocumentIngest,
+		model.AuditDocumentUpdate, model.AuditDocumentTierChange:
+		return "LOW"
+	case model.AuditFolderCreate, model.AuditVaultCreate, model.AuditVaultUpdate, model.AuditVaultRestore,
+		model.AuditVaultDocumentsMove, model.AuditShareGroupCreate, model.AuditShareGroupDelete:
+		return "LOW"
+	case model.AuditInsightScan, model.AuditVoiceTranscribe:
+		return "LOW"
+	case model.AuditOrgCreate, model.AuditOrgRename, model.AuditOrgInviteRevoke:
+		return "LOW"
 	default:
 		return "INFO"
 	}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// Audit checkpoint errors.
+var (
+	ErrAuditEntryNotFound = errors.New("audit entry not found")
+	ErrAuditNotAnchored   = errors.New("audit entry is not covered by a checkpoint yet")
+)
+
+// DefaultMaxCheckpointEntries caps the entries one checkpoint covers, so a
+// long backlog is anchored over several runs rather than loaded at once.
+const DefaultMaxCheckpointEntries = 100000
+
+// AuditCheckpointRepository abstracts storage for signed audit checkpoints.
+type AuditCheckpointRepository interface {
+	// PendingCheckpoints returns, for every tenant whose chain has grown
+	// past its last checkpoint, the range the next checkpoint covers (at
+	// most maxEntries entries). Only TenantID, FromSeq and ToSeq are set.
+	PendingCheckpoints(ctx context.Context, maxEntries int64) ([]model.AuditCheckpoint, error)
+	// LeafHashes returns the details hashes of a tenant's entries with seq
+	// fromSeq..toSeq, in seq order.
+	LeafHashes(ctx context.Context, tenantID string, fromSeq, toSeq int64) ([]string, error)
+	// CreateCheckpoint stores cp unless the tenant already has a checkpoint
+	// starting at cp.FromSeq, and reports whether it was stored.
+	CreateCheckpoint(ctx context.Context, cp *model.AuditCheckpoint) (bool, error)
+	// Checkpoints returns a tenant's checkpoints overlapping seq
+	// fromSeq..toSeq, in seq order.
+	Checkpoints(ctx context.Context, tenantID string, fromSeq, toSeq int64) ([]model.AuditCheckpoint, error)
+	// GetEntry returns an audit entry by ID, or nil if there is none.
+	GetEntry(ctx context.Context, id string) (*model.AuditLog, error)
+}
+
+// AuditCheckpointer periodically anchors every tenant's audit chain in a
+// Merkle tree whose root it signs with an Ed25519 key, and serves inclusion
+// proofs against those checkpoints. Checkpoints are contiguous per tenant,
+// and concurrent instances cannot write overlapping ones.
+type AuditCheckpointer struct {
+	repo AuditCheckpointRepository
+	key  ed25519.PrivateKey
+
+	MaxEntries int64
+	now        func() time.Time
+}
+
+// NewAuditCheckpointer creates an AuditCheckpointer signing with key.
+func NewAuditCheckpointer(repo AuditCheckpointRepository, key ed25519.PrivateKey) *AuditCheckpointer {
+	return &AuditCheckpointer{
+		repo:       repo,
+		key:        key,
+		MaxEntries: DefaultMaxCheckpointEntries,
+		now:        time.Now,
+	}
+}
+
+// PublicKey returns the key checkpoints are verified with.
+func (c *AuditCheckpointer) PublicKey() ed25519.PublicKey {
+	return c.key.Public().(ed25519.PublicKey)
+}
+
+// Run writes pending checkpoints every interval until ctx is cancelled.
+func (c *AuditCheckpointer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.CheckpointOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("[AuditCheckpoint] checkpoint failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckpointOnce signs and stores the next checkpoint of every tenant with
+// unanchored entries, and returns how many it stored. A checkpoint another
+// instance stored first is skipped.
+func (c *AuditCheckpointer) CheckpointOnce(ctx context.Context) (int, error) {
+	pending, err := c.repo.PendingCheckpoints(ctx, c.MaxEntries)
+	if err != nil {
+		return 0, fmt.Errorf("service.CheckpointOnce: %w", err)
+	}
+
+	stored := 0
+	for i := range pending {
+		cp := &pending[i]
+		hashes, err := c.repo.LeafHashes(ctx, cp.TenantID, cp.FromSeq, cp.ToSeq)
+		if err != nil {
+			return stored, fmt.Errorf("service.CheckpointOnce: leaves: %w", err)
+		}
+		if int64(len(hashes)) != cp.ToSeq-cp.FromSeq+1 {
+			// The chain has a gap; anchoring it would hide the gap.
+			slog.Error("[AuditCheckpoint] chain has missing entries, not anchoring",
+				"tenant_id", cp.TenantID, "from_seq", cp.FromSeq, "to_seq", cp.ToSeq, "found", len(hashes))
+			continue
+		}
+
+		cp.ID = uuid.New().String()
+		cp.RootHash = auditchain.NewTree(hashes).Root()
+		cp.CreatedAt = c.now().UTC().Truncate(time.Microsecond) // PG precision
+		auditchain.SignCheckpoint(cp, c.key)
+
+		ok, err := c.repo.CreateCheckpoint(ctx, cp)
+		if err != nil {
+			return stored, fmt.Errorf("service.CheckpointOnce: store: %w", err)
+		}
+		if ok {
+			stored++
+			slog.Info("[AuditCheckpoint] checkpoint stored",
+				"tenant_id", cp.TenantID, "from_seq", cp.FromSeq, "to_seq", cp.ToSeq, "root", cp.RootHash)
+		}
+	}
+	return stored, nil
+}
+
+// Proof returns a one-entry bundle proving that the audit entry entryID,
+// which must belong to userID, is anchored in a signed checkpoint. The
+// bundle verifies offline like any export.
+func (c *AuditCheckpointer) Proof(ctx context.Context, entryID, userID string) (*auditchain.Bundle, error) {
+	entry, err := c.repo.GetEntry(ctx, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("service.AuditProof: %w", err)
+	}
+	if entry == nil || entry.UserID == nil || *entry.UserID != userID {
+		return nil, ErrAuditEntryNotFound
+	}
+
+	bundle, err := c.Bundle(ctx, []model.AuditLog{*entry})
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Entries[0].Proof == nil {
+		return nil, ErrAuditNotAnchored
+	}
+	return bundle, nil
+}
+
+// Bundle packages entries, in chain order, with an inclusion proof for
+// every entry a checkpoint covers and the checkpoints those proofs use.
+// Each checkpoint's tree is built once however many entries it proves.
+func (c *AuditCheckpointer) Bundle(ctx context.Context, entries []model.AuditLog) (*auditchain.Bundle, error) {
+	sorted := append([]model.AuditLog(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].TenantID != sorted[j].TenantID {
+			return sorted[i].TenantID < sorted[j].TenantID
+		}
+		return sorted[i].Seq < sorted[j].Seq
+	})
+
+	bundle := &auditchain.Bundle{
+		Version:     auditchain.BundleVersion,
+		PublicKey:   auditchain.EncodePublicKey(c.PublicKey()),
+		Entries:     make([]auditchain.BundleEntry, 0, len(sorted)),
+		Checkpoints: []model.AuditCheckpoint{},
+	}
+
+	// Entries are grouped by tenant; each group's checkpoints are loaded
+	// in one query and each checkpoint's tree at most once.
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].TenantID == sorted[start].TenantID {
+			end++
+		}
+		group := sorted[start:end]
+		start = end
+
+		tenantID := group[0].TenantID
+		checkpoints, err := c.repo.Checkpoints(ctx, tenantID, group[0].Seq, group[len(group)-1].Seq)
+		if err != nil {
+			return nil, fmt.Errorf("service.AuditBundle: checkpoints: %w", err)
+		}
+		trees := make(map[int]*auditchain.Tree)
+
+		for _, e := range group {
+			i := sort.Search(len(checkpoints), func(i int) bool { return checkpoints[i].ToSeq >= e.Seq })
+			if i == len(checkpoints) || checkpoints[i].FromSeq > e.Seq {
+				bundle.Entries = append(bundle.Entries, auditchain.NewBundleEntry(e, nil))
+				continue
+			}
+			cp := &checkpoints[i]
+			tree, ok := trees[i]
+			if !ok {
+				hashes, err := c.repo.LeafHashes(ctx, tenantID, cp.FromSeq, cp.ToSeq)
+				if err != nil {
+					return nil, fmt.Errorf("service.AuditBundle: leaves: %w", err)
+				}
+				tree = auditchain.NewTree(hashes)
+				trees[i] = tree
+				bundle.Checkpoints = append(bundle.Checkpoints, *cp)
+			}
+
+			index := e.Seq - cp.FromSeq
+			path, err := tree.Proof(index)
+			if err != nil {
+				return nil, fmt.Errorf("service.AuditBundle: proof: %w", err)
+			}
+			bundle.Entries = append(bundle.Entries, auditchain.NewBundleEntry(e, &auditchain.InclusionProof{
+				CheckpointID: cp.ID, LeafIndex: index, TreeSize: tree.Size(), Path: path,
+			}))
+		}
+	}
+	return bundle, nil
+}
+
+// SignedBundle is Bundle with a detached signature by the checkpoint key
+// over the whole encoded bundle.
+func (c *AuditCheckpointer) SignedBundle(ctx context.Context, entries []model.AuditLog) (*auditchain.SignedBundle, error) {
+	bundle, err := c.Bundle(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := auditchain.SignBundle(bundle, c.key)
+	if err != nil {
+		return nil, fmt.Errorf("service.AuditSignedBundle: %w", err)
+	}
+	return signed, nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// mockCheckpointRepo implements AuditCheckpointRepository over the entries
+// a mockAuditRepo has appended.
+type mockCheckpointRepo struct {
+	audit       *mockAuditRepo
+	checkpoints []model.AuditCheckpoint
+	lost        bool // CreateCheckpoint loses every race
+}
+
+func (m *mockCheckpointRepo) PendingCheckpoints(ctx context.Context, maxEntries int64) ([]model.AuditCheckpoint, error) {
+	var pending []model.AuditCheckpoint
+	for tenant, head := range m.audit.heads {
+		from := int64(1)
+		for _, cp := range m.checkpoints {
+			if cp.TenantID == tenant && cp.ToSeq >= from {
+				from = cp.ToSeq + 1
+			}
+		}
+		to := head.Seq
+		if to > from+maxEntries-1 {
+			to = from + maxEntries - 1
+		}
+		if to >= from {
+			pending = append(pending, model.AuditCheckpoint{TenantID: tenant, FromSeq: from, ToSeq: to})
+		}
+	}
+	return pending, nil
+}
+
+func (m *mockCheckpointRepo) LeafHashes(ctx context.Context, tenantID string, fromSeq, toSeq int64) ([]string, error) {
+	var hashes []string
+	for _, e := range m.audit.entries {
+		if e.TenantID == tenantID && e.Seq >= fromSeq && e.Seq <= toSeq {
+			hashes = append(hashes, *e.DetailsHash)
+		}
+	}
+	return hashes, nil
+}
+
+func (m *mockCheckpointRepo) CreateCheckpoint(ctx context.Context, cp *model.AuditCheckpoint) (bool, error) {
+	if m.lost {
+		return false, nil
+	}
+	m.checkpoints = append(m.checkpoints, *cp)
+	return true, nil
+}
+
+func (m *mockCheckpointRepo) Checkpoints(ctx context.Context, tenantID string, fromSeq, toSeq int64) ([]model.AuditCheckpoint, error) {
+	var out []model.AuditCheckpoint
+	for _, cp := range m.checkpoints {
+		if cp.TenantID == tenantID && cp.ToSeq >= fromSeq && cp.FromSeq <= toSeq {
+			out = append(out, cp)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockCheckpointRepo) GetEntry(ctx context.Context, id string) (*model.AuditLog, error) {
+	for _, e := range m.audit.entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func newTestCheckpointer(t *testing.T) (*AuditService, *mockCheckpointRepo, *AuditCheckpointer) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit := &mockAuditRepo{}
+	repo := &mockCheckpointRepo{audit: audit}
+	return NewAuditService(audit, nil), repo, NewAuditCheckpointer(repo, key)
+}
+
+func logN(t *testing.T, svc *AuditService, userID string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := svc.LogWithDetails(context.Background(), model.AuditQueryExecuted, userID, "", "", map[string]interface{}{"i": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckpointOnce_AnchorsEachTenantContiguously(t *testing.T) {
+	svc, repo, cpr := newTestCheckpointer(t)
+	cpr.MaxEntries = 4
+	ctx := context.Background()
+
+	logN(t, svc, "alice", 6)
+	logN(t, svc, "bob", 2)
+
+	if n, err := cpr.CheckpointOnce(ctx); err != nil || n != 2 {
+		t.Fatalf("first run stored %d, %v; want 2", n, err)
+	}
+	if n, _ := cpr.CheckpointOnce(ctx); n != 1 {
+		t.Fatalf("second run stored %d; want alice's remaining 2 entries", n)
+	}
+	if n, _ := cpr.CheckpointOnce(ctx); n != 0 {
+		t.Fatalf("third run stored %d; want 0", n)
+	}
+
+	alice := model.PersonalOrgID("alice")
+	var ranges [][2]int64
+	for _, cp := range repo.checkpoints {
+		if !auditchain.VerifyCheckpoint(&cp, cpr.PublicKey()) {
+			t.Errorf("checkpoint %s..%d has a bad signature", cp.TenantID, cp.ToSeq)
+		}
+		if cp.CreatedAt.Nanosecond()%1000 != 0 {
+			t.Error("CreatedAt exceeds stored precision, so the signature cannot survive a round trip")
+		}
+		if cp.TenantID == alice {
+			ranges = append(ranges, [2]int64{cp.FromSeq, cp.ToSeq})
+		}
+	}
+	if len(ranges) != 2 || ranges[0] != [2]int64{1, 4} || ranges[1] != [2]int64{5, 6} {
+		t.Errorf("alice's checkpoints cover %v, want [1 4] [5 6]", ranges)
+	}
+}
+
+func TestCheckpointOnce_CountsOnlyCheckpointsItStored(t *testing.T) {
+	svc, repo, cpr := newTestCheckpointer(t)
+	logN(t, svc, "alice", 3)
+	repo.lost = true
+
+	if n, err := cpr.CheckpointOnce(context.Background()); err != nil || n != 0 {
+		t.Errorf("stored %d, %v; want 0 when another instance won", n, err)
+	}
+}
+
+func TestCheckpointOnce_RefusesToAnchorAGap(t *testing.T) {
+	svc, repo, cpr := newTestCheckpointer(t)
+	logN(t, svc, "alice", 3)
+	repo.audit.entries = append(repo.audit.entries[:1], repo.audit.entries[2:]...)
+
+	if n, err := cpr.CheckpointOnce(context.Background()); err != nil || n != 0 {
+		t.Errorf("stored %d, %v; want 0 over a chain with a missing entry", n, err)
+	}
+}
+
+func TestBundle_ProvesAnchoredEntries(t *testing.T) {
+	svc, repo, cpr := newTestCheckpointer(t)
+	cpr.MaxEntries = 3
+	ctx := context.Background()
+
+	logN(t, svc, "alice", 5)
+	logN(t, svc, "bob", 2)
+	cpr.CheckpointOnce(ctx)
+	cpr.CheckpointOnce(ctx)
+	logN(t, svc, "alice", 1) // not yet anchored
+
+	var entries []model.AuditLog
+	for i := len(repo.audit.entries) - 1; i >= 0; i-- { // newest first, as List returns them
+		entries = append(entries, *repo.audit.entries[i])
+	}
+	bundle, err := cpr.Bundle(ctx, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rep := auditchain.VerifyBundle(bundle, cpr.PublicKey())
+	if !rep.Valid || rep.Anchored != 7 || len(rep.Unanchored) != 1 || rep.Checkpoints != 3 {
+		t.Errorf("report = %+v", rep)
+	}
+}
+
+func TestSignedBundle_CoversUnanchoredEntries(t *testing.T) {
+	svc, repo, cpr := newTestCheckpointer(t)
+	ctx := context.Background()
+	logN(t, svc, "alice", 2) // no checkpoint yet
+
+	entries := []model.AuditLog{*repo.audit.entries[0], *repo.audit.entries[1]}
+	signed, err := cpr.SignedBundle(ctx, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := auditchain.VerifySignedBundle(signed, cpr.PublicKey())
+	if err != nil || !rep.Valid || !rep.Signed || len(rep.Unanchored) != 2 {
+		t.Errorf("report = %+v, %v", rep, err)
+	}
+}
+
+func TestProof(t *testing.T) {
+	svc, repo, cpr := newTestCheckpointer(t)
+	ctx := context.Background()
+
+	logN(t, svc, "alice", 3)
+	logN(t, svc, "bob", 1)
+	cpr.CheckpointOnce(ctx)
+	logN(t, svc, "alice", 1)
+	anchored, pending, bobs := repo.audit.entries[1].ID, repo.audit.entries[4].ID, repo.audit.entries[3].ID
+
+	bundle, err := cpr.Proof(ctx, anchored, "alice")
+	if err != nil {
+		t.Fatalf("Proof: %v", err)
+	}
+	if rep := auditchain.VerifyBundle(bundle, cpr.PublicKey()); !rep.Valid || rep.Anchored != 1 {
+		t.Errorf("report = %+v", rep)
+	}
+
+	if _, err := cpr.Proof(ctx, pending, "alice"); !errors.Is(err, ErrAuditNotAnchored) {
+		t.Errorf("pending entry: err = %v, want ErrAuditNotAnchored", err)
+	}
+	for _, id := range []string{bobs, "missing"} {
+		if _, err := cpr.Proof(ctx, id, "alice"); !errors.Is(err, ErrAuditEntryNotFound) {
+			t.Errorf("Proof(%s): err = %v, want ErrAuditEntryNotFound", id, err)
+		}
+	}
+}
+
+func TestRun_CheckpointsBeforeWaiting(t *testing.T) {
+	svc, repo, cpr := newTestCheckpointer(t)
+	logN(t, svc, "alice", 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cpr.Run(ctx, time.Hour) // returns once cancelled, after one run
+
+	if len(repo.checkpoints) != 1 {
+		t.Errorf("stored %d checkpoints, want 1", len(repo.checkpoints))
+	}
+}
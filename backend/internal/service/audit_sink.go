@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// Audit dispatcher defaults.
+const (
+	DefaultAuditSinkBatch      = 100
+	DefaultAuditSinkLease      = 2 * time.Minute
+	DefaultAuditSinkMinBackoff = 5 * time.Second
+	DefaultAuditSinkMaxBackoff = 15 * time.Minute
+)
+
+// AuditSink is an external destination for audit entries. Delivery is at
+// least once: after a failure or a crash mid-write the same entries are
+// written again, so a sink must tolerate duplicates (every entry carries a
+// unique ID and its tenant's chain seq).
+type AuditSink interface {
+	// Name identifies the sink in the outbox; it must not change between
+	// releases or queued entries are orphaned.
+	Name() string
+	// Write delivers entries, in chain order per tenant, and returns nil
+	// only once the sink has durably accepted all of them.
+	Write(ctx context.Context, entries []model.AuditLog) error
+}
+
+// AuditOutboxRepository abstracts the audit outbox. Entries are queued in
+// the transaction that appends them to the chain (see AuditRepository).
+type AuditOutboxRepository interface {
+	// ClaimOutbox returns up to limit of sink's due items, oldest first,
+	// and hides them from other claims for lease.
+	ClaimOutbox(ctx context.Context, sink string, limit int, lease time.Duration) ([]model.AuditOutboxItem, error)
+	// AckOutbox removes delivered items.
+	AckOutbox(ctx context.Context, ids []int64) error
+	// RetryOutbox counts a failed attempt on items and makes them due again
+	// after delay.
+	RetryOutbox(ctx context.Context, ids []int64, delay time.Duration, lastErr string) error
+	// OutboxLag returns how many items are queued for sink and when the
+	// oldest was queued (zero if none).
+	OutboxLag(ctx context.Context, sink string) (int64, time.Time, error)
+}
+
+// AuditSinkObserver receives delivery events. Implemented by middleware.Metrics.
+type AuditSinkObserver interface {
+	ObserveAuditDelivery(sink string, entries int, err error, d time.Duration)
+	ObserveAuditLag(sink string, pending int64, lag time.Duration)
+}
+
+// AuditDispatcher drains the audit outbox into its sinks. Each sink is
+// drained independently, so a sink that is down only delays itself, and
+// any number of instances can dispatch at once.
+type AuditDispatcher struct {
+	repo     AuditOutboxRepository
+	sinks    []AuditSink
+	observer AuditSinkObserver
+
+	BatchSize  int
+	Lease      time.Duration // must exceed the slowest sink's write timeout
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	now        func() time.Time
+}
+
+// NewAuditDispatcher creates an AuditDispatcher for sinks.
+func NewAuditDispatcher(repo AuditOutboxRepository, sinks ...AuditSink) *AuditDispatcher {
+	return &AuditDispatcher{
+		repo:       repo,
+		sinks:      sinks,
+		BatchSize:  DefaultAuditSinkBatch,
+		Lease:      DefaultAuditSinkLease,
+		MinBackoff: DefaultAuditSinkMinBackoff,
+		MaxBackoff: DefaultAuditSinkMaxBackoff,
+		now:        time.Now,
+	}
+}
+
+// SetObserver attaches an observer for delivery and lag metrics. Call it
+// before Run.
+func (d *AuditDispatcher) SetObserver(o AuditSinkObserver) {
+	d.observer = o
+}
+
+// SinkNames returns the names of the dispatcher's sinks, which the audit
+// repository queues every entry for.
+func (d *AuditDispatcher) SinkNames() []string {
+	names := make([]string, len(d.sinks))
+	for i, s := range d.sinks {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// Run drains every sink each interval until ctx is cancelled.
+func (d *AuditDispatcher) Run(ctx context.Context, interval time.Duration) {
+	var wg sync.WaitGroup
+	for _, sink := range d.sinks {
+		wg.Add(1)
+		go func(sink AuditSink) {
+			defer wg.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				if _, err := d.drain(ctx, sink); err != nil && ctx.Err() == nil {
+					slog.Error("[AuditSink] dispatch failed", "sink", sink.Name(), "error", err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(sink)
+	}
+	wg.Wait()
+}
+
+// DispatchOnce drains every sink once and returns how many entries were
+// delivered. A sink that rejects a batch is retried later, not reported
+// as an error; errors are outbox failures.
+func (d *AuditDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	delivered := 0
+	for _, sink := range d.sinks {
+		n, err := d.drain(ctx, sink)
+		delivered += n
+		if err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// drain delivers sink's due items batch by batch until none are left or
+// the sink fails, then reports the sink's lag.
+func (d *AuditDispatcher) drain(ctx context.Context, sink AuditSink) (int, error) {
+	delivered := 0
+	for {
+		n, more, err := d.deliverBatch(ctx, sink)
+		delivered += n
+		if err != nil {
+			return delivered, err
+		}
+		if !more {
+			break
+		}
+	}
+
+	if d.observer != nil {
+		pending, oldest, err := d.repo.OutboxLag(ctx, sink.Name())
+		if err != nil {
+			return delivered, fmt.Errorf("service.AuditDispatch: lag: %w", err)
+		}
+		var lag time.Duration
+		if pending > 0 {
+			lag = d.now().Sub(oldest)
+		}
+		d.observer.ObserveAuditLag(sink.Name(), pending, lag)
+	}
+	return delivered, nil
+}
+
+// deliverBatch claims and delivers one batch, and reports whether another
+// batch may be due.
+func (d *AuditDispatcher) deliverBatch(ctx context.Context, sink AuditSink) (int, bool, error) {
+	items, err := d.repo.ClaimOutbox(ctx, sink.Name(), d.BatchSize, d.Lease)
+	if err != nil {
+		return 0, false, fmt.Errorf("service.AuditDispatch: claim: %w", err)
+	}
+	if len(items) == 0 {
+		return 0, false, nil
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		a, b := &items[i].Entry, &items[j].Entry
+		if a.TenantID != b.TenantID {
+			return a.TenantID < b.TenantID
+		}
+		return a.Seq < b.Seq
+	})
+	ids := make([]int64, len(items))
+	entries := make([]model.AuditLog, len(items))
+	attempts := 0
+	for i, it := range items {
+		ids[i] = it.ID
+		entries[i] = it.Entry
+		if it.Attempts > attempts {
+			attempts = it.Attempts
+		}
+	}
+
+	start := d.now()
+	writeErr := sink.Write(ctx, entries)
+	if d.observer != nil {
+		d.observer.ObserveAuditDelivery(sink.Name(), len(entries), writeErr, d.now().Sub(start))
+	}
+
+	// Acks and retries must land even if ctx was cancelled mid-write, or
+	// the items wait out the lease for nothing.
+	bg := context.WithoutCancel(ctx)
+	if writeErr != nil {
+		delay := d.Backoff(attempts + 1)
+		slog.Warn("[AuditSink] delivery failed, will retry",
+			"sink", sink.Name(), "entries", len(entries), "attempt", attempts+1, "retry_in", delay, "error", writeErr)
+		if err := d.repo.RetryOutbox(bg, ids, delay, truncateStr(writeErr.Error(), 1000)); err != nil {
+			return 0, false, fmt.Errorf("service.AuditDispatch: retry: %w", err)
+		}
+		return 0, false, nil
+	}
+	if err := d.repo.AckOutbox(bg, ids); err != nil {
+		return 0, false, fmt.Errorf("service.AuditDispatch: ack: %w", err)
+	}
+	return len(entries), len(items) == d.BatchSize && ctx.Err() == nil, nil
+}
+
+// Backoff returns the delay before the next delivery after the given
+// number of consecutive failures: MinBackoff doubling per failure, capped
+// at MaxBackoff.
+func (d *AuditDispatcher) Backoff(failures int) time.Duration {
+	delay := d.MinBackoff
+	for i := 1; i < failures && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxBackoff {
+		return d.MaxBackoff
+	}
+	return delay
+}
+
+// BigQuerySink delivers audit entries to BigQuery through the outbox, in
+// place of AuditService's fire-and-forget BigQueryWriter.
+type BigQuerySink struct {
+	W BigQueryWriter
+}
+
+// Name implements AuditSink.
+func (BigQuerySink) Name() string { return "bigquery" }
+
+// Write implements AuditSink.
+func (s BigQuerySink) Write(ctx context.Context, entries []model.AuditLog) error {
+	for i := range entries {
+		if err := s.W.WriteAuditEntry(ctx, &entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
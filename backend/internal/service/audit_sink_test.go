@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// mockOutbox is an in-memory AuditOutboxRepository.
+type mockOutbox struct {
+	mu     sync.Mutex
+	now    time.Time
+	nextID int64
+	items  map[int64]*outboxRow
+}
+
+type outboxRow struct {
+	item    model.AuditOutboxItem
+	due     time.Time
+	queued  time.Time
+	lastErr string
+}
+
+func newMockOutbox(now time.Time) *mockOutbox {
+	return &mockOutbox{now: now, items: map[int64]*outboxRow{}}
+}
+
+func (m *mockOutbox) enqueue(sink string, entries ...model.AuditLog) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range entries {
+		m.nextID++
+		m.items[m.nextID] = &outboxRow{
+			item: model.AuditOutboxItem{ID: m.nextID, Sink: sink, Entry: e},
+			due:  m.now, queued: m.now,
+		}
+	}
+}
+
+func (m *mockOutbox) sorted(sink string) []*outboxRow {
+	var rows []*outboxRow
+	for _, r := range m.items {
+		if r.item.Sink == sink {
+			rows = append(rows, r)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].item.ID < rows[j].item.ID })
+	return rows
+}
+
+func (m *mockOutbox) ClaimOutbox(_ context.Context, sink string, limit int, lease time.Duration) ([]model.AuditOutboxItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []model.AuditOutboxItem
+	for _, r := range m.sorted(sink) {
+		if len(out) == limit {
+			break
+		}
+		if !r.due.After(m.now) {
+			r.due = m.now.Add(lease)
+			out = append(out, r.item)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockOutbox) AckOutbox(_ context.Context, ids []int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.items, id)
+	}
+	return nil
+}
+
+func (m *mockOutbox) RetryOutbox(_ context.Context, ids []int64, delay time.Duration, lastErr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		r := m.items[id]
+		r.item.Attempts++
+		r.due = m.now.Add(delay)
+		r.lastErr = lastErr
+	}
+	return nil
+}
+
+func (m *mockOutbox) OutboxLag(_ context.Context, sink string) (int64, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rows := m.sorted(sink)
+	if len(rows) == 0 {
+		return 0, time.Time{}, nil
+	}
+	return int64(len(rows)), rows[0].queued, nil
+}
+
+func (m *mockOutbox) advance(d time.Duration) {
+	m.mu.Lock()
+	m.now = m.now.Add(d)
+	m.mu.Unlock()
+}
+
+// mockSink records delivered entries and fails while failing is set.
+type mockSink struct {
+	name    string
+	failing bool
+	batches [][]model.AuditLog
+}
+
+func (s *mockSink) Name() string { return s.name }
+
+func (s *mockSink) Write(_ context.Context, entries []model.AuditLog) error {
+	if s.failing {
+		return errors.New("collector unavailable")
+	}
+	s.batches = append(s.batches, append([]model.AuditLog(nil), entries...))
+	return nil
+}
+
+func (s *mockSink) delivered() []model.AuditLog {
+	var all []model.AuditLog
+	for _, b := range s.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+type mockSinkObserver struct {
+	delivered, failures map[string]int
+	pending             map[string]int64
+	lag                 map[string]time.Duration
+}
+
+func newMockSinkObserver() *mockSinkObserver {
+	return &mockSinkObserver{
+		delivered: map[string]int{}, failures: map[string]int{},
+		pending: map[string]int64{}, lag: map[string]time.Duration{},
+	}
+}
+
+func (o *mockSinkObserver) ObserveAuditDelivery(sink string, entries int, err error, _ time.Duration) {
+	if err != nil {
+		o.failures[sink]++
+		return
+	}
+	o.delivered[sink] += entries
+}
+
+func (o *mockSinkObserver) ObserveAuditLag(sink string, pending int64, lag time.Duration) {
+	o.pending[sink], o.lag[sink] = pending, lag
+}
+
+func outboxEntries(tenant string, seqs ...int64) []model.AuditLog {
+	entries := make([]model.AuditLog, len(seqs))
+	for i, seq := range seqs {
+		entries[i] = model.AuditLog{ID: tenant + "-" + string(rune('a'+seq)), TenantID: tenant, Seq: seq}
+	}
+	return entries
+}
+
+func newTestDispatcher(outbox *mockOutbox, sinks ...AuditSink) *AuditDispatcher {
+	d := NewAuditDispatcher(outbox, sinks...)
+	d.now = func() time.Time {
+		outbox.mu.Lock()
+		defer outbox.mu.Unlock()
+		return outbox.now
+	}
+	return d
+}
+
+func TestAuditDispatcher_DeliversInBatchesInChainOrder(t *testing.T) {
+	outbox := newMockOutbox(time.Unix(1700000000, 0))
+	// Queued out of chain order across two tenants.
+	outbox.enqueue("file", outboxEntries("t2", 2, 1)...)
+	outbox.enqueue("file", outboxEntries("t1", 3, 1, 2)...)
+	sink := &mockSink{name: "file"}
+	d := newTestDispatcher(outbox, sink)
+	d.BatchSize = 3
+
+	n, err := d.DispatchOnce(context.Background())
+	if err != nil || n != 5 {
+		t.Fatalf("DispatchOnce = %d, %v; want 5", n, err)
+	}
+	if len(sink.batches) != 2 {
+		t.Fatalf("batches = %d, want 2", len(sink.batches))
+	}
+	// Each batch is in chain order per tenant.
+	for _, b := range sink.batches {
+		for i := 1; i < len(b); i++ {
+			if b[i].TenantID == b[i-1].TenantID && b[i].Seq < b[i-1].Seq {
+				t.Errorf("batch out of chain order: %+v", b)
+			}
+		}
+	}
+	if pending, _, _ := outbox.OutboxLag(context.Background(), "file"); pending != 0 {
+		t.Errorf("pending = %d, want 0", pending)
+	}
+}
+
+func TestAuditDispatcher_RetriesWithBackoffUntilDelivered(t *testing.T) {
+	ctx := context.Background()
+	outbox := newMockOutbox(time.Unix(1700000000, 0))
+	outbox.enqueue("webhook", outboxEntries("t1", 1, 2)...)
+	outbox.enqueue("file", outboxEntries("t1", 1, 2)...)
+	webhook := &mockSink{name: "webhook", failing: true}
+	file := &mockSink{name: "file"}
+	obs := newMockSinkObserver()
+	d := newTestDispatcher(outbox, webhook, file)
+	d.SetObserver(obs)
+
+	// A failing sink does not hold back the others.
+	if n, err := d.DispatchOnce(ctx); err != nil || n != 2 {
+		t.Fatalf("DispatchOnce = %d, %v; want 2", n, err)
+	}
+	if len(file.delivered()) != 2 || len(webhook.delivered()) != 0 {
+		t.Fatalf("file = %d, webhook = %d", len(file.delivered()), len(webhook.delivered()))
+	}
+	if obs.failures["webhook"] != 1 || obs.pending["webhook"] != 2 || obs.pending["file"] != 0 {
+		t.Errorf("observer = %+v", obs)
+	}
+
+	// Not due again until the backoff has passed; each failure doubles it.
+	for attempt, wait := range []time.Duration{DefaultAuditSinkMinBackoff, 2 * DefaultAuditSinkMinBackoff} {
+		outbox.advance(wait - time.Second)
+		d.DispatchOnce(ctx)
+		if obs.failures["webhook"] != attempt+1 {
+			t.Fatalf("retried before backoff %v elapsed", wait)
+		}
+		outbox.advance(time.Second)
+		d.DispatchOnce(ctx)
+		if obs.failures["webhook"] != attempt+2 {
+			t.Fatalf("not retried after backoff %v", wait)
+		}
+	}
+	for _, r := range outbox.sorted("webhook") {
+		if r.item.Attempts != 3 || r.lastErr != "collector unavailable" {
+			t.Errorf("row = %+v, want 3 attempts with last error", r)
+		}
+	}
+	if obs.lag["webhook"] != 3*DefaultAuditSinkMinBackoff {
+		t.Errorf("lag = %v, want %v", obs.lag["webhook"], 3*DefaultAuditSinkMinBackoff)
+	}
+
+	// Once the sink recovers every entry is delivered exactly once more.
+	webhook.failing = false
+	outbox.advance(d.Backoff(3))
+	if n, err := d.DispatchOnce(ctx); err != nil || n != 2 {
+		t.Fatalf("DispatchOnce = %d, %v; want 2", n, err)
+	}
+	if len(webhook.delivered()) != 2 || obs.pending["webhook"] != 0 || obs.lag["webhook"] != 0 {
+		t.Errorf("webhook = %d delivered, observer = %+v", len(webhook.delivered()), obs)
+	}
+}
+
+func TestAuditDispatcher_ClaimedItemsAreLeased(t *testing.T) {
+	ctx := context.Background()
+	outbox := newMockOutbox(time.Unix(1700000000, 0))
+	outbox.enqueue("file", outboxEntries("t1", 1)...)
+
+	// An instance that crashes after claiming leaves the items leased: no
+	// other instance delivers them until the lease runs out.
+	if items, _ := outbox.ClaimOutbox(ctx, "file", 10, DefaultAuditSinkLease); len(items) != 1 {
+		t.Fatalf("claimed %d, want 1", len(items))
+	}
+	sink := &mockSink{name: "file"}
+	d := newTestDispatcher(outbox, sink)
+	if n, _ := d.DispatchOnce(ctx); n != 0 {
+		t.Errorf("delivered %d leased items", n)
+	}
+	outbox.advance(DefaultAuditSinkLease)
+	if n, _ := d.DispatchOnce(ctx); n != 1 {
+		t.Errorf("delivered %d after lease expiry, want 1", n)
+	}
+}
+
+func TestAuditDispatcher_Backoff(t *testing.T) {
+	d := NewAuditDispatcher(nil)
+	d.MinBackoff, d.MaxBackoff = time.Second, 10*time.Second
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{1000, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := d.Backoff(tt.failures); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestAuditDispatcher_Run_StopsOnCancel(t *testing.T) {
+	outbox := newMockOutbox(time.Unix(1700000000, 0))
+	outbox.enqueue("file", outboxEntries("t1", 1)...)
+	sink := &mockSink{name: "file"}
+	d := newTestDispatcher(outbox, sink)
+
+	// Run drains once up front, then returns once ctx is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.Run(ctx, time.Hour)
+	if len(sink.delivered()) != 1 {
+		t.Errorf("delivered %d, want 1", len(sink.delivered()))
+	}
+}
+
+func TestBigQuerySink_WritesEveryEntry(t *testing.T) {
+	bq := &mockBQWriter{}
+	sink := BigQuerySink{W: bq}
+	if err := sink.Write(context.Background(), outboxEntries("t1", 1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if len(bq.entries) != 2 || sink.Name() != "bigquery" {
+		t.Errorf("entries = %d, name = %q", len(bq.entries), sink.Name())
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// ErrInvalidChainRange is returned for a negative or inverted seq range.
+var ErrInvalidChainRange = errors.New("invalid audit chain range")
+
+// DefaultAuditVerifyPage is how many entries VerifyRange loads at a time.
+const DefaultAuditVerifyPage = 2000
+
+// AuditChainRepository abstracts read access to whole audit chains.
+type AuditChainRepository interface {
+	// ChainTenant returns the chain userID's entries are appended to.
+	ChainTenant(ctx context.Context, userID string) (string, error)
+	// ChainHead returns the seq and hash its chain head records for the
+	// tenant's last entry; 0 and "" for an empty chain.
+	ChainHead(ctx context.Context, tenantID string) (int64, string, error)
+	// ChainSeqBounds returns the lowest and highest seq of the tenant's
+	// entries created within [start, end] (zero times are unbounded), and
+	// false if there are none.
+	ChainSeqBounds(ctx context.Context, tenantID string, start, end time.Time) (int64, int64, bool, error)
+	// ChainPage returns up to limit of the tenant's entries with seq in
+	// fromSeq..toSeq, in seq order.
+	ChainPage(ctx context.Context, tenantID string, fromSeq, toSeq int64, limit int) ([]model.AuditLog, error)
+}
+
+// ChainRange selects part of a chain. Zero values are unbounded; when both
+// seqs and times are given the range is their intersection.
+type ChainRange struct {
+	FromSeq int64
+	ToSeq   int64
+	Start   time.Time
+	End     time.Time
+}
+
+// ChainReport is the outcome of verifying part of a chain.
+type ChainReport struct {
+	TenantID       string                  `json:"tenantId"`
+	FromSeq        int64                   `json:"fromSeq"`
+	ToSeq          int64                   `json:"toSeq"`
+	HeadSeq        int64                   `json:"headSeq"`
+	EntriesChecked int                     `json:"entriesChecked"`
+	Valid          bool                    `json:"valid"`
+	BreakCount     int                     `json:"breakCount"`
+	Breaks         []auditchain.ChainBreak `json:"breaks"` // the first auditchain.DefaultMaxReportedBreaks
+}
+
+// AuditVerifier verifies arbitrary ranges of a tenant's audit chain. A
+// range is always verified over every entry in it, whoever wrote them, so
+// a filter can never make an intact chain look broken or hide a deletion.
+type AuditVerifier struct {
+	repo     AuditChainRepository
+	PageSize int
+}
+
+// NewAuditVerifier creates an AuditVerifier.
+func NewAuditVerifier(repo AuditChainRepository) *AuditVerifier {
+	return &AuditVerifier{repo: repo, PageSize: DefaultAuditVerifyPage}
+}
+
+// Tenant returns the chain userID's entries are appended to.
+func (v *AuditVerifier) Tenant(ctx context.Context, userID string) (string, error) {
+	tenantID, err := v.repo.ChainTenant(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("service.AuditTenant: %w", err)
+	}
+	return tenantID, nil
+}
+
+// VerifyRange checks every entry of the tenant's chain in r: each entry's
+// hash, its link to the entry before (including the one just before the
+// range), missing seqs, and, when the range reaches the end of the chain,
+// the chain head. Every break is counted, not just the first.
+func (v *AuditVerifier) VerifyRange(ctx context.Context, tenantID string, r ChainRange) (*ChainReport, error) {
+	if r.FromSeq < 0 || r.ToSeq < 0 || (r.ToSeq > 0 && r.ToSeq < r.FromSeq) {
+		return nil, ErrInvalidChainRange
+	}
+
+	headSeq, headHash, err := v.repo.ChainHead(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.AuditVerifyRange: head: %w", err)
+	}
+	from, to := r.FromSeq, r.ToSeq
+	if from < 1 {
+		from = 1
+	}
+	if to == 0 || to > headSeq {
+		to = headSeq
+	}
+	if !r.Start.IsZero() || !r.End.IsZero() {
+		lo, hi, found, err := v.repo.ChainSeqBounds(ctx, tenantID, r.Start, r.End)
+		if err != nil {
+			return nil, fmt.Errorf("service.AuditVerifyRange: bounds: %w", err)
+		}
+		if !found {
+			lo, hi = from, from-1 // empty
+		}
+		if lo > from {
+			from = lo
+		}
+		if hi < to {
+			to = hi
+		}
+	}
+
+	report := &ChainReport{TenantID: tenantID, FromSeq: from, ToSeq: to, HeadSeq: headSeq,
+		Breaks: []auditchain.ChainBreak{}}
+	if to < from {
+		report.Valid = true
+		return report, nil
+	}
+
+	// The first entry must link to the one before the range.
+	prevHash := new(string)
+	if from > 1 {
+		prev, err := v.repo.ChainPage(ctx, tenantID, from-1, from-1, 1)
+		if err != nil {
+			return nil, fmt.Errorf("service.AuditVerifyRange: anchor: %w", err)
+		}
+		prevHash = nil
+		if len(prev) == 1 {
+			prevHash = prev[0].DetailsHash
+		}
+	}
+
+	chain := auditchain.NewChainVerifier(from, prevHash)
+	for next := from; next <= to; {
+		page, err := v.repo.ChainPage(ctx, tenantID, next, to, v.PageSize)
+		if err != nil {
+			return nil, fmt.Errorf("service.AuditVerifyRange: %w", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for i := range page {
+			chain.Add(&page[i])
+		}
+		next = page[len(page)-1].Seq + 1
+	}
+	if to == headSeq {
+		chain.FinishAtHead(headSeq, headHash)
+	} else {
+		chain.Finish(to)
+	}
+
+	report.EntriesChecked = chain.Checked
+	report.Valid = chain.Valid()
+	report.BreakCount = chain.BreakCount
+	report.Breaks = append(report.Breaks, chain.Breaks...)
+	return report, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/auditchain"
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// mockChainRepo serves one tenant's chain, with the head recorded from the
+// chain as built (before any tampering).
+type mockChainRepo struct {
+	entries  []model.AuditLog
+	headSeq  int64
+	headHash string
+	pages    int
+	err      error
+}
+
+func newMockChainRepo(entries []model.AuditLog) *mockChainRepo {
+	last := entries[len(entries)-1]
+	return &mockChainRepo{entries: entries, headSeq: last.Seq, headHash: *last.DetailsHash}
+}
+
+func (m *mockChainRepo) ChainTenant(ctx context.Context, userID string) (string, error) {
+	return "org-1", nil
+}
+
+func (m *mockChainRepo) ChainHead(ctx context.Context, tenantID string) (int64, string, error) {
+	return m.headSeq, m.headHash, m.err
+}
+
+func (m *mockChainRepo) ChainSeqBounds(ctx context.Context, tenantID string, start, end time.Time) (int64, int64, bool, error) {
+	var lo, hi int64
+	for _, e := range m.entries {
+		if (!start.IsZero() && e.CreatedAt.Before(start)) || (!end.IsZero() && e.CreatedAt.After(end)) {
+			continue
+		}
+		if lo == 0 || e.Seq < lo {
+			lo = e.Seq
+		}
+		if e.Seq > hi {
+			hi = e.Seq
+		}
+	}
+	return lo, hi, hi > 0, nil
+}
+
+func (m *mockChainRepo) ChainPage(ctx context.Context, tenantID string, fromSeq, toSeq int64, limit int) ([]model.AuditLog, error) {
+	m.pages++
+	var out []model.AuditLog
+	for _, e := range m.entries {
+		if e.Seq >= fromSeq && e.Seq <= toSeq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func TestVerifyRange_IntactChainInPages(t *testing.T) {
+	repo := newMockChainRepo(buildValidChain(10))
+	v := NewAuditVerifier(repo)
+	v.PageSize = 3
+
+	report, err := v.VerifyRange(context.Background(), "org-1", ChainRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Valid || report.EntriesChecked != 10 || report.FromSeq != 1 || report.ToSeq != 10 {
+		t.Errorf("report = %+v, want valid over 1..10", report)
+	}
+	if repo.pages != 4 {
+		t.Errorf("pages = %d, want 4", repo.pages)
+	}
+}
+
+func TestVerifyRange_ReportsEveryBreak(t *testing.T) {
+	entries := buildValidChain(10)
+	entries[1].Action = model.AuditDocumentDelete // hash break at seq 2
+	entries[5].Action = model.AuditDocumentDelete
+	rehash(entries, 5)                            // link break at seq 7
+	entries = append(entries[:7], entries[8:]...) // seq 8 missing
+
+	report, err := NewAuditVerifier(newMockChainRepo(entries)).VerifyRange(context.Background(), "org-1", ChainRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []auditchain.ChainBreak{
+		{Seq: 2, EntryID: "entry-1", Reason: auditchain.BreakHash},
+		{Seq: 7, EntryID: "entry-6", Reason: auditchain.BreakLink},
+		{Seq: 8, ToSeq: 8, Reason: auditchain.BreakMissing},
+	}
+	if report.Valid || report.BreakCount != len(want) || len(report.Breaks) != len(want) {
+		t.Fatalf("report = %+v, want %d breaks", report, len(want))
+	}
+	for i, b := range want {
+		if report.Breaks[i] != b {
+			t.Errorf("break %d = %+v, want %+v", i, report.Breaks[i], b)
+		}
+	}
+}
+
+func TestVerifyRange_AnchorsToEntryBeforeRange(t *testing.T) {
+	entries := buildValidChain(10)
+	// Entry 4 is forged and rehashed, outside the range: only the link from
+	// seq 5 back to it exposes the forgery.
+	entries[3].Action = model.AuditDocumentDelete
+	rehash(entries, 3)
+
+	report, err := NewAuditVerifier(newMockChainRepo(entries)).VerifyRange(context.Background(), "org-1", ChainRange{FromSeq: 5, ToSeq: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Valid || report.EntriesChecked != 4 || report.Breaks[0].Seq != 5 || report.Breaks[0].Reason != auditchain.BreakLink {
+		t.Errorf("report = %+v, want link break at seq 5", report)
+	}
+}
+
+func TestVerifyRange_DetectsTruncatedTail(t *testing.T) {
+	repo := newMockChainRepo(buildValidChain(6))
+	repo.entries = repo.entries[:4]
+
+	report, err := NewAuditVerifier(repo).VerifyRange(context.Background(), "org-1", ChainRange{FromSeq: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Valid || report.Breaks[0] != (auditchain.ChainBreak{Seq: 5, ToSeq: 6, Reason: auditchain.BreakMissing}) {
+		t.Errorf("report = %+v, want seq 5..6 missing", report)
+	}
+}
+
+func TestVerifyRange_DateRange(t *testing.T) {
+	entries := buildValidChain(10) // entry i is created at i ms past 2025-01-01
+	base := entries[0].CreatedAt
+	v := NewAuditVerifier(newMockChainRepo(entries))
+
+	report, err := v.VerifyRange(context.Background(), "org-1", ChainRange{
+		Start: base.Add(3 * time.Millisecond), End: base.Add(6 * time.Millisecond),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Valid || report.FromSeq != 4 || report.ToSeq != 7 || report.EntriesChecked != 4 {
+		t.Errorf("report = %+v, want valid over 4..7", report)
+	}
+
+	report, err = v.VerifyRange(context.Background(), "org-1", ChainRange{Start: base.Add(time.Hour)})
+	if err != nil || !report.Valid || report.EntriesChecked != 0 {
+		t.Errorf("empty range = %+v, %v", report, err)
+	}
+}
+
+func TestVerifyRange_Errors(t *testing.T) {
+	repo := newMockChainRepo(buildValidChain(3))
+	v := NewAuditVerifier(repo)
+	for _, r := range []ChainRange{{FromSeq: -1}, {FromSeq: 5, ToSeq: 2}} {
+		if _, err := v.VerifyRange(context.Background(), "org-1", r); !errors.Is(err, ErrInvalidChainRange) {
+			t.Errorf("VerifyRange(%+v) error = %v, want ErrInvalidChainRange", r, err)
+		}
+	}
+	repo.err = errors.New("connection refused")
+	if _, err := v.VerifyRange(context.Background(), "org-1", ChainRange{}); err == nil {
+		t.Error("expected repo error")
+	}
+}
@@ -7,9 +7,12 @@ import (
 	"firebase.google.com/go/v4/auth"
 )
 
-// AuthService verifies Firebase ID tokens.
+// AuthService verifies ID tokens: Firebase by default, and tokens from any
+// configured OIDC provider (Okta, Azure AD, ...) side by side. A token is
+// routed to an OIDC verifier when its issuer matches one, else to Firebase.
 type AuthService struct {
 	client AuthClient
+	oidc   map[string]*OIDCVerifier // by issuer
 }
 
 // AuthClient is the interface for Firebase token verification.
@@ -18,21 +21,50 @@ type AuthClient interface {
 	VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error)
 }
 
-// NewAuthService creates an AuthService with the given Firebase auth client.
-func NewAuthService(client AuthClient) *AuthService {
-	return &AuthService{client: client}
+// NewAuthService creates an AuthService with the given Firebase auth client
+// and optional OIDC providers. client may be nil when only OIDC is used.
+func NewAuthService(client AuthClient, providers ...*OIDCVerifier) *AuthService {
+	s := &AuthService{client: client, oidc: make(map[string]*OIDCVerifier, len(providers))}
+	for _, p := range providers {
+		s.oidc[p.Issuer()] = p
+	}
+	return s
 }
 
-// VerifyToken validates a Firebase ID token and returns the user ID (UID).
+// VerifyToken validates an ID token and returns the user ID.
 func (s *AuthService) VerifyToken(ctx context.Context, idToken string) (string, error) {
+	id, err := s.VerifyIdentity(ctx, idToken)
+	if err != nil {
+		return "", err
+	}
+	return id.UserID, nil
+}
+
+// VerifyIdentity validates an ID token and returns the caller's identity,
+// including the email and role claims when the provider supplies them.
+func (s *AuthService) VerifyIdentity(ctx context.Context, idToken string) (*Identity, error) {
 	if idToken == "" {
-		return "", fmt.Errorf("service.VerifyToken: token is empty")
+		return nil, fmt.Errorf("service.VerifyToken: token is empty")
+	}
+
+	if len(s.oidc) > 0 {
+		if v, ok := s.oidc[tokenIssuer(idToken)]; ok {
+			id, err := v.Verify(ctx, idToken)
+			if err != nil {
+				return nil, fmt.Errorf("service.VerifyToken: %w", err)
+			}
+			return id, nil
+		}
 	}
 
+	if s.client == nil {
+		return nil, fmt.Errorf("service.VerifyToken: no verifier for token issuer")
+	}
 	token, err := s.client.VerifyIDToken(ctx, idToken)
 	if err != nil {
-		return "", fmt.Errorf("service.VerifyToken: %w", err)
+		return nil, fmt.Errorf("service.VerifyToken: %w", err)
 	}
 
-	return token.UID, nil
+	email, _ := token.Claims["email"].(string)
+	return &Identity{UserID: token.UID, Email: email, Issuer: token.Issuer}, nil
 }
@@ -46,8 +46,9 @@ func NewCortexService(repo CortexStore, embedder CortexEmbedder) *CortexService
 
 // Ingest stores a piece of context or instruction in working memory.
 // Instructions (detected by keyword patterns or explicit flag) never expire.
-// Regular context expires after 90 days.
-func (s *CortexService) Ingest(ctx context.Context, tenantID, content, sourceChannel string, sourceMessageID *string, isInstruction bool) error {
+// Regular context expires after 90 days. sourceDocumentIDs lists the
+// documents the content was drawn from, so purging one removes the entry.
+func (s *CortexService) Ingest(ctx context.Context, tenantID, content, sourceChannel string, sourceMessageID *string, isInstruction bool, sourceDocumentIDs ...string) error {
 	if content == "" {
 		return nil
 	}
@@ -72,13 +73,14 @@ func (s *CortexService) Ingest(ctx context.Context, tenantID, content, sourceCha
 	embedding := pgvector.NewVector(vectors[0])
 
 	entry := &model.CortexEntry{
-		TenantID:        tenantID,
-		Content:         content,
-		Embedding:       embedding,
-		SourceChannel:   sourceChannel,
-		SourceMessageID: sourceMessageID,
-		CapturedAt:      time.Now().UTC(),
-		IsInstruction:   isInstruction,
+		TenantID:          tenantID,
+		Content:           content,
+		Embedding:         embedding,
+		SourceChannel:     sourceChannel,
+		SourceMessageID:   sourceMessageID,
+		CapturedAt:        time.Now().UTC(),
+		IsInstruction:     isInstruction,
+		SourceDocumentIDs: sourceDocumentIDs,
 	}
 
 	// Instructions never expire; regular context expires in 90 days
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"path/filepath"
 	"strings"
 	"time"
@@ -50,6 +52,23 @@ type FolderRepository interface {
 	Delete(ctx context.Context, id string) error
 }
 
+// VaultRepository defines persistence operations for vaults. Visibility and
+// query access follow the vault status (see migration 022).
+type VaultRepository interface {
+	Create(ctx context.Context, vault *model.Vault) error
+	// GetForUser returns the vault if userID may see it, archived or not.
+	GetForUser(ctx context.Context, id, userID string) (*model.Vault, error)
+	ListByUser(ctx context.Context, userID string, includeArchived bool) ([]model.Vault, error)
+	Update(ctx context.Context, id, name string, status model.VaultStatus) error
+	SetArchived(ctx context.Context, id string, archived bool) error
+	// MoveDocuments moves active documents into the vault and returns how many moved.
+	MoveDocuments(ctx context.Context, vaultID string, docIDs []string) (int, error)
+	// DocumentIDs lists the IDs of the vault's active documents.
+	DocumentIDs(ctx context.Context, vaultID string) ([]string, error)
+	// CanQuery reports whether userID may query the vault's documents.
+	CanQuery(ctx context.Context, vaultID, userID string) (bool, error)
+}
+
 // DocSummary is a lightweight document summary for chat-handler queries like
 // "summarize my documents". (STORY-172)
 type DocSummary struct {
@@ -65,6 +84,7 @@ type ListOpts struct {
 	Offset        int
 	PrivilegeMode bool
 	Search        string
+	VaultID       string // when set, only documents in this vault the user may query
 }
 
 // SignedURLResponse is returned to the client with the upload URL.
@@ -72,6 +92,8 @@ type SignedURLResponse struct {
 	URL        string `json:"url"`
 	DocumentID string `json:"documentId"`
 	ObjectName string `json:"objectName"`
+	// QuotaWarning is set when the upload is allowed under the grace policy.
+	QuotaWarning string `json:"quotaWarning,omitempty"`
 }
 
 // DocumentService handles document upload orchestration.
@@ -80,6 +102,7 @@ type DocumentService struct {
 	docRepo    DocumentRepository
 	bucketName string
 	urlExpiry  time.Duration
+	quota      QuotaEnforcer // optional — documents_stored enforcement
 }
 
 // NewDocumentService creates a DocumentService.
@@ -92,6 +115,11 @@ func NewDocumentService(storage StorageClient, docRepo DocumentRepository, bucke
 	}
 }
 
+// SetQuota enables documents_stored enforcement on upload.
+func (s *DocumentService) SetQuota(q QuotaEnforcer) {
+	s.quota = q
+}
+
 // GenerateUploadURL creates a signed PUT URL for direct client upload to Cloud Storage
 // and creates a pending document record in the database.
 func (s *DocumentService) GenerateUploadURL(ctx context.Context, userID, filename, contentType string, sizeBytes int, folderID string) (*SignedURLResponse, error) {
@@ -107,6 +135,18 @@ func (s *DocumentService) GenerateUploadURL(ctx context.Context, userID, filenam
 		return nil, fmt.Errorf("service.GenerateUploadURL: file size must be positive")
 	}
 
+	var quotaWarning string
+	if s.quota != nil {
+		decision, err := s.quota.Enforce(ctx, userID, MetricDocumentsStored, 1)
+		if errors.Is(err, ErrQuotaExceeded) {
+			return nil, fmt.Errorf("service.GenerateUploadURL: %w", err)
+		} else if err != nil {
+			// Don't block uploads on a metering failure.
+			slog.Error("document quota check failed", "user_id", userID, "error", err)
+		}
+		quotaWarning = decision.Warning()
+	}
+
 	docID := uuid.New().String()
 	objectName := fmt.Sprintf("uploads/%s/%s/%s", userID, docID, filename)
 
@@ -147,8 +187,9 @@ func (s *DocumentService) GenerateUploadURL(ctx context.Context, userID, filenam
 	}
 
 	return &SignedURLResponse{
-		URL:        url,
-		DocumentID: docID,
-		ObjectName: objectName,
+		URL:          url,
+		DocumentID:   docID,
+		ObjectName:   objectName,
+		QuotaWarning: quotaWarning,
 	}, nil
 }
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// maxBatchTokens is the estimated token budget per embedding request.
+	// Vertex AI rejects requests above 20,000 input tokens; the margin absorbs
+	// estimation error.
+	maxBatchTokens = 18000
+	// maxInstanceTokens is the per-text token count Vertex AI truncates to.
+	maxInstanceTokens = 2048
+	// defaultEmbedConcurrency is the number of in-flight embedding requests per service.
+	defaultEmbedConcurrency = 4
+)
+
+// ErrRateLimited is returned when the embedding or generation API keeps
+// answering 429 after retries. gcpclient.ErrRateLimited is the same value.
+var ErrRateLimited = errors.New("the system is experiencing high demand. Please try again in a few seconds")
+
+// EmbedOptions tunes how EmbedderService batches and paces embedding requests.
+type EmbedOptions struct {
+	MaxBatchTexts  int           // texts per request (Vertex AI limit: 250)
+	MaxBatchTokens int           // estimated tokens per request (Vertex AI limit: 20,000)
+	Concurrency    int           // in-flight requests shared by all callers of one service
+	MaxRetries     int           // retries of a batch after ErrRateLimited
+	BaseBackoff    time.Duration // first shared pause after a 429
+	MaxBackoff     time.Duration // ceiling for the shared pause
+}
+
+// DefaultEmbedOptions returns limits matching the Vertex AI text embedding quotas.
+func DefaultEmbedOptions() EmbedOptions {
+	return EmbedOptions{
+		MaxBatchTexts:  maxBatchSize,
+		MaxBatchTokens: maxBatchTokens,
+		Concurrency:    defaultEmbedConcurrency,
+		MaxRetries:     4,
+		BaseBackoff:    2 * time.Second,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// VectorReuseStore looks up vectors already stored for identical embedding
+// input in the same space and the same tenant as documentID, so reprocessing
+// a document skips unchanged chunks. Implemented by repository.ChunkRepo.
+type VectorReuseStore interface {
+	VectorsByInputHash(ctx context.Context, space model.EmbeddingSpace, documentID string, hashes []string) (map[string][]float32, error)
+}
+
+// EmbeddingInputHash returns the hex SHA-256 of the exact text sent to the
+// embedding model. Stored per chunk as embedding_input_hash.
+func EmbeddingInputHash(text string) string {
+	h := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(h[:])
+}
+
+// embeddingTokenEstimate conservatively approximates the embedding model's token
+// count for text (about 3 characters per token), capped at the per-instance
+// truncation limit. Deliberately higher than the chunker's word-based estimate.
+func embeddingTokenEstimate(text string) int {
+	n := len(text)/3 + 1
+	if n > maxInstanceTokens {
+		n = maxInstanceTokens
+	}
+	return n
+}
+
+// planBatches splits texts into contiguous [start, end) ranges that respect both
+// the per-request text count and token budget. A single oversized text still
+// gets its own batch.
+func planBatches(texts []string, maxTexts, maxTokens int) [][2]int {
+	var batches [][2]int
+	start, tokens := 0, 0
+	for i, t := range texts {
+		est := embeddingTokenEstimate(t)
+		if i > start && (i-start >= maxTexts || tokens+est > maxTokens) {
+			batches = append(batches, [2]int{start, i})
+			start, tokens = i, 0
+		}
+		tokens += est
+	}
+	if start < len(texts) {
+		batches = append(batches, [2]int{start, len(texts)})
+	}
+	return batches
+}
+
+// isBatchTooLarge reports whether the API rejected a request for exceeding its token limit.
+func isBatchTooLarge(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "status 400") && strings.Contains(strings.ToLower(msg), "token")
+}
+
+// rateGate is a backoff shared by every goroutine using one embedder: a 429 seen
+// by any request pauses all of them, so concurrent batches stop hammering an
+// exhausted quota instead of each retrying on its own schedule.
+type rateGate struct {
+	mu         sync.Mutex
+	pauseUntil time.Time
+	streak     int
+	base, max  time.Duration
+}
+
+// wait blocks until the shared pause (if any) has elapsed.
+func (g *rateGate) wait(ctx context.Context) error {
+	g.mu.Lock()
+	d := time.Until(g.pauseUntil)
+	g.mu.Unlock()
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// penalize extends the shared pause exponentially with jitter.
+func (g *rateGate) penalize() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.streak++
+	d := g.base << (g.streak - 1)
+	if d > g.max || d <= 0 {
+		d = g.max
+	}
+	d += time.Duration(rand.Int63n(int64(d)/4 + 1))
+	if until := time.Now().Add(d); until.After(g.pauseUntil) {
+		g.pauseUntil = until
+	}
+	return d
+}
+
+// reset clears the streak after a successful request.
+func (g *rateGate) reset() {
+	g.mu.Lock()
+	g.streak = 0
+	g.mu.Unlock()
+}
+
+// batchEmbedder runs token-aware batches with bounded parallelism and a shared
+// 429 backoff. One instance is shared by all callers of its owning service.
+type batchEmbedder struct {
+	opts EmbedOptions
+	sem  chan struct{}
+	gate *rateGate
+}
+
+func newBatchEmbedder(opts EmbedOptions) *batchEmbedder {
+	def := DefaultEmbedOptions()
+	if opts.MaxBatchTexts <= 0 {
+		opts.MaxBatchTexts = def.MaxBatchTexts
+	}
+	if opts.MaxBatchTokens <= 0 {
+		opts.MaxBatchTokens = def.MaxBatchTokens
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = def.Concurrency
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = def.BaseBackoff
+	}
+	if opts.MaxBackoff < opts.BaseBackoff {
+		opts.MaxBackoff = opts.BaseBackoff
+	}
+	return &batchEmbedder{
+		opts: opts,
+		sem:  make(chan struct{}, opts.Concurrency),
+		gate: &rateGate{base: opts.BaseBackoff, max: opts.MaxBackoff},
+	}
+}
+
+// embed returns one L2-normalized vector per text, validated against space
+// (768 dimensions when the space is unversioned).
+func (b *batchEmbedder) embed(ctx context.Context, client EmbeddingClient, space model.EmbeddingSpace, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("service.Embed: no texts provided")
+	}
+
+	dims := space.Dimensions
+	if dims == 0 {
+		dims = embeddingDimensions
+	}
+
+	out := make([][]float32, len(texts))
+	g, gCtx := errgroup.WithContext(ctx)
+	for _, r := range planBatches(texts, b.opts.MaxBatchTexts, b.opts.MaxBatchTokens) {
+		start, end := r[0], r[1]
+		g.Go(func() error {
+			vectors, err := b.embedBatch(gCtx, client, texts[start:end])
+			if err != nil {
+				return fmt.Errorf("service.Embed: batch %d-%d: %w", start, end, err)
+			}
+			if len(vectors) != end-start {
+				return fmt.Errorf("service.Embed: got %d vectors for %d texts", len(vectors), end-start)
+			}
+			for j, vec := range vectors {
+				if len(vec) != dims {
+					return fmt.Errorf("service.Embed: vector %d has %d dimensions, want %d", start+j, len(vec), dims)
+				}
+				out[start+j] = l2Normalize(vec)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// embedBatch sends one batch, honouring the concurrency limit and the shared
+// backoff. A batch the API rejects as too large is split in half and retried.
+func (b *batchEmbedder) embedBatch(ctx context.Context, client EmbeddingClient, texts []string) ([][]float32, error) {
+	for attempt := 0; ; attempt++ {
+		if err := b.gate.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		select {
+		case b.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		vectors, err := client.EmbedTexts(ctx, texts)
+		<-b.sem
+
+		switch {
+		case err == nil:
+			b.gate.reset()
+			return vectors, nil
+		case errors.Is(err, ErrRateLimited) && attempt < b.opts.MaxRetries:
+			d := b.gate.penalize()
+			slog.Warn("[EMBED] rate limited, pausing all batches",
+				"batch", len(texts), "attempt", attempt+1, "pause_ms", d.Milliseconds())
+		case isBatchTooLarge(err) && len(texts) > 1:
+			mid := len(texts) / 2
+			slog.Info("[EMBED] batch over token limit, splitting", "batch", len(texts))
+			left, err := b.embedBatch(ctx, client, texts[:mid])
+			if err != nil {
+				return nil, err
+			}
+			right, err := b.embedBatch(ctx, client, texts[mid:])
+			if err != nil {
+				return nil, err
+			}
+			return append(left, right...), nil
+		default:
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// scriptedEmbeddingClient fails the first failures calls with failErr and
+// records concurrency and call timing.
+type scriptedEmbeddingClient struct {
+	mu          sync.Mutex
+	failures    int
+	failErr     error
+	maxTexts    int // reject batches larger than this with a 400 token error (0 = no limit)
+	delay       time.Duration
+	inFlight    int32
+	maxInFlight int32
+	calls       []time.Time
+	batchSizes  []int
+}
+
+func (c *scriptedEmbeddingClient) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	n := atomic.AddInt32(&c.inFlight, 1)
+	defer atomic.AddInt32(&c.inFlight, -1)
+	for {
+		m := atomic.LoadInt32(&c.maxInFlight)
+		if n <= m || atomic.CompareAndSwapInt32(&c.maxInFlight, m, n) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+
+	c.mu.Lock()
+	c.calls = append(c.calls, time.Now())
+	c.batchSizes = append(c.batchSizes, len(texts))
+	fail := c.failures > 0
+	if fail {
+		c.failures--
+	}
+	c.mu.Unlock()
+
+	if fail {
+		return nil, c.failErr
+	}
+	if c.maxTexts > 0 && len(texts) > c.maxTexts {
+		return nil, fmt.Errorf("gcpclient.EmbedTexts: status 400: input token count exceeds the limit")
+	}
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		vec := make([]float32, 768)
+		vec[0] = 1
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func TestPlanBatches_RespectsTokenBudget(t *testing.T) {
+	long := strings.Repeat("x", 3000) // ~1001 estimated tokens
+	texts := make([]string, 40)
+	for i := range texts {
+		texts[i] = long
+	}
+
+	batches := planBatches(texts, 250, 18000)
+	if len(batches) != 3 {
+		t.Fatalf("batches = %d, want 3 (17 + 17 + 6)", len(batches))
+	}
+	for _, b := range batches {
+		tokens := 0
+		for _, t := range texts[b[0]:b[1]] {
+			tokens += embeddingTokenEstimate(t)
+		}
+		if tokens > 18000 {
+			t.Errorf("batch %v has %d estimated tokens", b, tokens)
+		}
+	}
+	if batches[len(batches)-1][1] != len(texts) {
+		t.Error("batches must cover every text")
+	}
+}
+
+func TestPlanBatches_OversizedTextGetsOwnBatch(t *testing.T) {
+	texts := []string{"a", strings.Repeat("y", 100000), "b"}
+	batches := planBatches(texts, 250, 1000)
+	if len(batches) != 3 {
+		t.Errorf("batches = %v, want each text alone", batches)
+	}
+}
+
+func TestEmbed_BoundedConcurrency(t *testing.T) {
+	client := &scriptedEmbeddingClient{delay: 20 * time.Millisecond}
+	svc := NewEmbedderService(client, nil)
+	svc.SetOptions(EmbedOptions{MaxBatchTexts: 5, Concurrency: 3})
+
+	texts := make([]string, 60)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+	vectors, err := svc.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	if len(vectors) != 60 {
+		t.Fatalf("vectors = %d, want 60", len(vectors))
+	}
+	if client.maxInFlight > 3 {
+		t.Errorf("max in-flight = %d, want <= 3", client.maxInFlight)
+	}
+	if client.maxInFlight < 2 {
+		t.Errorf("max in-flight = %d, expected batches to run in parallel", client.maxInFlight)
+	}
+}
+
+func TestEmbed_RateLimitPausesAllBatches(t *testing.T) {
+	client := &scriptedEmbeddingClient{failures: 1, failErr: ErrRateLimited}
+	svc := NewEmbedderService(client, nil)
+	svc.SetOptions(EmbedOptions{
+		MaxBatchTexts: 1,
+		Concurrency:   1,
+		MaxRetries:    2,
+		BaseBackoff:   50 * time.Millisecond,
+		MaxBackoff:    50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	if _, err := svc.Embed(context.Background(), []string{"a", "b", "c"}); err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+
+	// The first call fails; every later call must wait out the shared pause.
+	if len(client.calls) != 4 {
+		t.Fatalf("calls = %d, want 4 (1 rate-limited + 3 successful)", len(client.calls))
+	}
+	for i, at := range client.calls[1:] {
+		if at.Sub(start) < 50*time.Millisecond {
+			t.Errorf("call %d ran %v after start, before the shared pause elapsed", i+1, at.Sub(start))
+		}
+	}
+}
+
+func TestEmbed_RateLimitRetriesExhausted(t *testing.T) {
+	client := &scriptedEmbeddingClient{failures: 10, failErr: ErrRateLimited}
+	svc := NewEmbedderService(client, nil)
+	svc.SetOptions(EmbedOptions{MaxRetries: 2, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	_, err := svc.Embed(context.Background(), []string{"a"})
+	if err == nil {
+		t.Fatal("expected error after retries")
+	}
+	if len(client.calls) != 3 {
+		t.Errorf("calls = %d, want 3 (initial + 2 retries)", len(client.calls))
+	}
+}
+
+func TestEmbed_SplitsBatchRejectedForTokens(t *testing.T) {
+	client := &scriptedEmbeddingClient{maxTexts: 2}
+	svc := NewEmbedderService(client, nil)
+
+	vectors, err := svc.Embed(context.Background(), []string{"a", "b", "c", "d", "e"})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	if len(vectors) != 5 {
+		t.Errorf("vectors = %d, want 5", len(vectors))
+	}
+}
+
+// mockVectorReuse implements VectorReuseStore for testing.
+type mockVectorReuse struct {
+	vectors   map[string][]float32
+	err       error
+	documents []string
+}
+
+func (m *mockVectorReuse) VectorsByInputHash(ctx context.Context, space model.EmbeddingSpace, documentID string, hashes []string) (map[string][]float32, error) {
+	m.documents = append(m.documents, documentID)
+	if m.err != nil {
+		return nil, m.err
+	}
+	out := make(map[string][]float32)
+	for _, h := range hashes {
+		if v, ok := m.vectors[h]; ok {
+			out[h] = v
+		}
+	}
+	return out, nil
+}
+
+func TestEmbedAndStore_ReusesUnchangedChunks(t *testing.T) {
+	stored := make([]float32, 768)
+	stored[5] = 1
+	client := &scriptedEmbeddingClient{}
+	store := &mockChunkStore{}
+	svc := NewEmbedderService(client, store)
+	space := model.EmbeddingSpace{Model: "text-embedding-004", Version: 1, Dimensions: 768}
+	svc.SetEmbedding(space, client)
+	reuse := &mockVectorReuse{vectors: map[string][]float32{
+		EmbeddingInputHash("unchanged"): stored,
+	}}
+	svc.SetVectorReuse(reuse)
+
+	chunks := []Chunk{{Content: "unchanged", Index: 0, DocumentID: "doc-1"}, {Content: "edited", Index: 1, DocumentID: "doc-1"}}
+	if err := svc.EmbedAndStore(context.Background(), chunks); err != nil {
+		t.Fatalf("EmbedAndStore() error: %v", err)
+	}
+
+	if len(client.batchSizes) != 1 || client.batchSizes[0] != 1 {
+		t.Errorf("embedding calls = %v, want one call for the edited chunk", client.batchSizes)
+	}
+	if store.insertedVectors[0][5] != 1 {
+		t.Error("unchanged chunk should keep its stored vector")
+	}
+	if len(store.insertedVectors) != 2 || store.insertedVectors[1] == nil {
+		t.Error("edited chunk should be embedded")
+	}
+	if len(reuse.documents) != 1 || reuse.documents[0] != "doc-1" {
+		t.Errorf("reuse lookups = %v, want one scoped to doc-1", reuse.documents)
+	}
+}
+
+func TestEmbedAndStore_ReuseSkippedWhenUnversioned(t *testing.T) {
+	client := &scriptedEmbeddingClient{}
+	svc := NewEmbedderService(client, &mockChunkStore{})
+	svc.SetVectorReuse(&mockVectorReuse{vectors: map[string][]float32{
+		EmbeddingInputHash("x"): make([]float32, 768),
+	}})
+
+	if err := svc.EmbedAndStore(context.Background(), []Chunk{{Content: "x"}}); err != nil {
+		t.Fatalf("EmbedAndStore() error: %v", err)
+	}
+	if len(client.calls) != 1 {
+		t.Error("vectors must not be reused without a versioned embedding space")
+	}
+}
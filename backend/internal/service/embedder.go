@@ -3,7 +3,11 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math"
+	"sync"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
 )
 
 const (
@@ -25,8 +29,13 @@ type ChunkStore interface {
 
 // EmbedderService generates vector embeddings and stores them with chunks.
 type EmbedderService struct {
+	mu         sync.RWMutex
 	client     EmbeddingClient
+	space      model.EmbeddingSpace // zero = unversioned (legacy)
 	chunkStore ChunkStore
+	batcher    *batchEmbedder
+	reuse      VectorReuseStore // nil = always embed
+	pii        Redactor         // nil = chunks are stored unscanned
 }
 
 // NewEmbedderService creates an EmbedderService.
@@ -34,46 +43,58 @@ func NewEmbedderService(client EmbeddingClient, chunkStore ChunkStore) *Embedder
 	return &EmbedderService{
 		client:     client,
 		chunkStore: chunkStore,
+		batcher:    newBatchEmbedder(DefaultEmbedOptions()),
 	}
 }
 
-// Embed generates embeddings for a slice of texts, batching as needed.
-// Returns one 768-dim L2-normalized vector per input text.
-func (s *EmbedderService) Embed(ctx context.Context, texts []string) ([][]float32, error) {
-	if len(texts) == 0 {
-		return nil, fmt.Errorf("service.Embed: no texts provided")
-	}
-
-	allVectors := make([][]float32, 0, len(texts))
+// SetOptions replaces the batching and concurrency limits. Call before use.
+func (s *EmbedderService) SetOptions(opts EmbedOptions) {
+	s.batcher = newBatchEmbedder(opts)
+}
 
-	for i := 0; i < len(texts); i += maxBatchSize {
-		end := i + maxBatchSize
-		if end > len(texts) {
-			end = len(texts)
-		}
-		batch := texts[i:end]
+// SetVectorReuse attaches a VectorReuseStore so chunks whose embedding input is
+// unchanged since a previous ingest reuse the stored vector instead of calling
+// the API. Reuse only happens within the same versioned embedding space.
+func (s *EmbedderService) SetVectorReuse(store VectorReuseStore) {
+	s.reuse = store
+}
 
-		vectors, err := s.client.EmbedTexts(ctx, batch)
-		if err != nil {
-			return nil, fmt.Errorf("service.Embed: batch %d-%d: %w", i, end, err)
-		}
+// SetPIIScanner attaches a Redactor that scans each chunk's content before it
+// is stored, so its PII/PHI findings are persisted with it for query-time
+// redaction.
+func (s *EmbedderService) SetPIIScanner(scanner Redactor) {
+	s.pii = scanner
+}
 
-		// Validate dimensions and L2-normalize
-		for j, vec := range vectors {
-			if len(vec) != embeddingDimensions {
-				return nil, fmt.Errorf("service.Embed: vector %d has %d dimensions, want %d", i+j, len(vec), embeddingDimensions)
-			}
-			vectors[j] = l2Normalize(vec)
-		}
+// SetEmbedding swaps the embedding client and the space its vectors belong to.
+// Called at startup and when the active embedding space changes after a
+// re-embed cutover. Chunks stored afterwards are stamped with the new space.
+func (s *EmbedderService) SetEmbedding(space model.EmbeddingSpace, client EmbeddingClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.space = space
+	s.client = client
+}
 
-		allVectors = append(allVectors, vectors...)
-	}
+// Space returns the embedding space new vectors are produced in.
+func (s *EmbedderService) Space() model.EmbeddingSpace {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.space
+}
 
-	if len(allVectors) != len(texts) {
-		return nil, fmt.Errorf("service.Embed: got %d vectors for %d texts", len(allVectors), len(texts))
-	}
+func (s *EmbedderService) binding() (EmbeddingClient, model.EmbeddingSpace) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client, s.space
+}
 
-	return allVectors, nil
+// Embed generates embeddings for a slice of texts in token-aware batches,
+// running up to EmbedOptions.Concurrency requests at once.
+// Returns one L2-normalized vector per input text.
+func (s *EmbedderService) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	client, space := s.binding()
+	return s.batcher.embed(ctx, client, space, texts)
 }
 
 // EmbedAndStore generates embeddings for chunks and persists them via ChunkStore.
@@ -83,17 +104,74 @@ func (s *EmbedderService) EmbedAndStore(ctx context.Context, chunks []Chunk) err
 		return nil
 	}
 
-	texts := make([]string, len(chunks))
-	for i, c := range chunks {
-		texts[i] = c.Content
+	// Snapshot the binding once so every vector and its stamp agree.
+	client, space := s.binding()
+
+	vectors := make([][]float32, len(chunks))
+	var missing []int
+	if s.reuse != nil && !space.IsZero() {
+		// Reuse is scoped per document tenant, so look up each document's hashes.
+		hashes := make([]string, len(chunks))
+		byDoc := make(map[string][]string)
+		for i, c := range chunks {
+			hashes[i] = EmbeddingInputHash(c.Content)
+			byDoc[c.DocumentID] = append(byDoc[c.DocumentID], hashes[i])
+		}
+		cached := make(map[string]map[string][]float32, len(byDoc))
+		for docID, docHashes := range byDoc {
+			vecs, err := s.reuse.VectorsByInputHash(ctx, space, docID, docHashes)
+			if err != nil {
+				// Non-fatal: fall back to embedding this document's chunks.
+				slog.Warn("[EMBED] vector reuse lookup failed", "document_id", docID, "error", err)
+				continue
+			}
+			cached[docID] = vecs
+		}
+		for i, h := range hashes {
+			if vec, ok := cached[chunks[i].DocumentID][h]; ok {
+				vectors[i] = vec
+			} else {
+				missing = append(missing, i)
+			}
+		}
+		if reused := len(chunks) - len(missing); reused > 0 {
+			slog.Info("[EMBED] reused unchanged chunk vectors", "reused", reused, "total", len(chunks))
+		}
+	} else {
+		missing = make([]int, len(chunks))
+		for i := range chunks {
+			missing[i] = i
+		}
 	}
 
-	vectors, err := s.Embed(ctx, texts)
-	if err != nil {
-		return fmt.Errorf("service.EmbedAndStore: %w", err)
+	if len(missing) > 0 {
+		texts := make([]string, len(missing))
+		for j, i := range missing {
+			texts[j] = chunks[i].Content
+		}
+		embedded, err := s.batcher.embed(ctx, client, space, texts)
+		if err != nil {
+			return fmt.Errorf("service.EmbedAndStore: %w", err)
+		}
+		for j, i := range missing {
+			vectors[i] = embedded[j]
+		}
+	}
+
+	stamped := make([]Chunk, len(chunks))
+	for i, c := range chunks {
+		c.EmbeddingSpace = space
+		if s.pii != nil {
+			findings, err := ScanPIIFindings(ctx, s.pii, c.Content)
+			if err != nil {
+				return fmt.Errorf("service.EmbedAndStore: chunk %d: %w", c.Index, err)
+			}
+			c.PIIFindings = findings
+		}
+		stamped[i] = c
 	}
 
-	if err := s.chunkStore.BulkInsert(ctx, chunks, vectors); err != nil {
+	if err := s.chunkStore.BulkInsert(ctx, stamped, vectors); err != nil {
 		return fmt.Errorf("service.EmbedAndStore: store: %w", err)
 	}
 
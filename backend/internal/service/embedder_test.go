@@ -4,17 +4,23 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sync"
 	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
 )
 
 // mockEmbeddingClient implements EmbeddingClient for testing.
 type mockEmbeddingClient struct {
+	mu      sync.Mutex
 	vectors [][]float32
 	err     error
 	calls   int
 }
 
 func (m *mockEmbeddingClient) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.calls++
 	if m.err != nil {
 		return nil, m.err
@@ -240,3 +246,53 @@ func TestEmbed_ExactBatchBoundary(t *testing.T) {
 		t.Errorf("expected 1 API call for 250 texts, got %d", client.calls)
 	}
 }
+
+func TestEmbedAndStore_StampsEmbeddingSpace(t *testing.T) {
+	store := &mockChunkStore{}
+	svc := NewEmbedderService(&mockEmbeddingClient{}, store)
+	space := model.EmbeddingSpace{Model: "text-embedding-005", Version: 2, Dimensions: 768}
+	svc.SetEmbedding(space, &mockEmbeddingClient{})
+
+	chunks := []Chunk{{Content: "a", Index: 0}, {Content: "b", Index: 1}}
+	if err := svc.EmbedAndStore(context.Background(), chunks); err != nil {
+		t.Fatalf("EmbedAndStore() error: %v", err)
+	}
+
+	for i, c := range store.insertedChunks {
+		if c.EmbeddingSpace != space {
+			t.Errorf("chunk %d space = %+v, want %+v", i, c.EmbeddingSpace, space)
+		}
+	}
+	if !chunks[0].EmbeddingSpace.IsZero() {
+		t.Error("EmbedAndStore should not mutate the caller's chunks")
+	}
+}
+
+func TestEmbed_UsesSpaceDimensions(t *testing.T) {
+	svc := NewEmbedderService(&mockEmbeddingClient{}, nil)
+	svc.SetEmbedding(model.EmbeddingSpace{Model: "m", Version: 2, Dimensions: 1024}, &mockEmbeddingClient{})
+
+	// The mock returns 768-dim vectors, which do not fit a 1024-dim space.
+	if _, err := svc.Embed(context.Background(), []string{"x"}); err == nil {
+		t.Fatal("expected dimension mismatch error")
+	}
+}
+
+func TestEmbedAndStore_StoresPIIFindings(t *testing.T) {
+	store := &mockChunkStore{}
+	svc := NewEmbedderService(&mockEmbeddingClient{}, store)
+	svc.SetPIIScanner(NewRedactorService(&valueDLPClient{values: map[string]string{"536-22-1847": "US_SOCIAL_SECURITY_NUMBER"}}, ""))
+
+	chunks := []Chunk{{Content: "SSN 536-22-1847", Index: 0}, {Content: "no PII", Index: 1}}
+	if err := svc.EmbedAndStore(context.Background(), chunks); err != nil {
+		t.Fatalf("EmbedAndStore() error: %v", err)
+	}
+
+	got := store.insertedChunks
+	if len(got[0].PIIFindings) != 1 || got[0].PIIFindings[0] != (model.PIIFinding{InfoType: "US_SOCIAL_SECURITY_NUMBER", Start: 4, End: 15, Score: 0.9}) {
+		t.Errorf("chunk 0 findings = %+v", got[0].PIIFindings)
+	}
+	if got[1].PIIFindings == nil || len(got[1].PIIFindings) != 0 {
+		t.Errorf("chunk 1 findings = %#v, want scanned with none", got[1].PIIFindings)
+	}
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// Legal hold errors. Handlers map them to HTTP statuses.
+var (
+	// ErrUnderLegalHold is returned (wrapped in a *LegalHoldError, or by the
+	// repository when the database triggers refuse a change) when an active
+	// legal hold covers the resource.
+	ErrUnderLegalHold    = errors.New("under legal hold")
+	ErrInvalidLegalHold  = errors.New("invalid legal hold")
+	ErrLegalHoldNotFound = errors.New("legal hold not found")
+	ErrLegalHoldReleased = errors.New("legal hold already released")
+)
+
+// maxLegalHoldScopes caps the resources one hold request may list.
+const maxLegalHoldScopes = 500
+
+// LegalHoldOp is an operation a legal hold blocks.
+type LegalHoldOp string
+
+const (
+	LegalHoldOpDelete       LegalHoldOp = "delete"
+	LegalHoldOpDeleteChunks LegalHoldOp = "delete chunks"
+	LegalHoldOpRename       LegalHoldOp = "rename"
+	LegalHoldOpMove         LegalHoldOp = "move"
+	LegalHoldOpReingest     LegalHoldOp = "re-ingest"
+	LegalHoldOpPurge        LegalHoldOp = "purge"
+)
+
+// LegalHoldError reports an operation refused because active legal holds
+// cover the resource. It wraps ErrUnderLegalHold.
+type LegalHoldError struct {
+	Op           LegalHoldOp
+	ResourceType model.LegalHoldResourceType
+	ResourceID   string
+	Holds        []model.LegalHold
+}
+
+func (e *LegalHoldError) Error() string {
+	labels := make([]string, len(e.Holds))
+	for i, h := range e.Holds {
+		labels[i] = fmt.Sprintf("%q (matter %s)", h.Name, h.MatterID)
+	}
+	return fmt.Sprintf("cannot %s: %s %s is under legal hold %s",
+		e.Op, e.ResourceType, e.ResourceID, strings.Join(labels, ", "))
+}
+
+func (e *LegalHoldError) Unwrap() error { return ErrUnderLegalHold }
+
+// LegalHoldRepository persists legal holds and resolves what they cover.
+// Coverage uses the document_legal_holds and folder_legal_holds SQL
+// functions (migration 029), whose triggers also enforce holds in the
+// database. Implemented by repository.LegalHoldRepo.
+type LegalHoldRepository interface {
+	// CreateHold stores the hold with its scopes.
+	CreateHold(ctx context.Context, h *model.LegalHold) error
+	// GetHold returns the hold with its scopes, or nil if it does not exist.
+	GetHold(ctx context.Context, id string) (*model.LegalHold, error)
+	// ListHolds returns the organization's holds with their scopes, newest first.
+	ListHolds(ctx context.Context, orgID string) ([]model.LegalHold, error)
+	// ReleaseHold releases an active hold; it reports false if the hold was
+	// already released.
+	ReleaseHold(ctx context.Context, id, releasedBy, reason string, at time.Time) (bool, error)
+	// ResourceOrg returns the organization that owns the resource, or "" if
+	// it does not exist.
+	ResourceOrg(ctx context.Context, resourceType model.LegalHoldResourceType, id string) (string, error)
+	// DocumentHolds returns the active holds covering each of docIDs that
+	// is held, without their scopes.
+	DocumentHolds(ctx context.Context, docIDs []string) (map[string][]model.LegalHold, error)
+	// FolderHolds returns the active holds that deleting the folder would
+	// breach, without their scopes.
+	FolderHolds(ctx context.Context, folderID string) ([]model.LegalHold, error)
+	// HeldDocuments lists the documents the hold's scopes cover.
+	HeldDocuments(ctx context.Context, holdID string) ([]model.HeldDocument, error)
+}
+
+// LegalHoldAuditLogger records holds placed and released and the changes
+// they refused.
+type LegalHoldAuditLogger interface {
+	LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error
+}
+
+// LegalHoldRequest describes a legal hold to place.
+type LegalHoldRequest struct {
+	Name      string
+	MatterID  string
+	Custodian string
+	Scopes    []model.LegalHoldScope
+}
+
+// LegalHoldService places and releases legal holds (Partner only) and
+// refuses changes to the resources they cover.
+type LegalHoldService struct {
+	repo    LegalHoldRepository
+	members OrgMembership
+	audit   LegalHoldAuditLogger
+	now     func() time.Time
+}
+
+// NewLegalHoldService creates a LegalHoldService. audit may be nil.
+func NewLegalHoldService(repo LegalHoldRepository, members OrgMembership, audit LegalHoldAuditLogger) *LegalHoldService {
+	return &LegalHoldService{repo: repo, members: members, audit: audit, now: time.Now}
+}
+
+// Create places a hold on documents, folders and vaults of the caller's
+// organization. Partner only.
+func (s *LegalHoldService) Create(ctx context.Context, actorID string, req LegalHoldRequest) (*model.LegalHold, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.MatterID = strings.TrimSpace(req.MatterID)
+	req.Custodian = strings.TrimSpace(req.Custodian)
+	switch {
+	case req.Name == "" || len(req.Name) > 200:
+		return nil, fmt.Errorf("%w: name must be 1-200 characters", ErrInvalidLegalHold)
+	case req.MatterID == "" || len(req.MatterID) > 100:
+		return nil, fmt.Errorf("%w: matterId must be 1-100 characters", ErrInvalidLegalHold)
+	case req.Custodian == "" || len(req.Custodian) > 200:
+		return nil, fmt.Errorf("%w: custodian must be 1-200 characters", ErrInvalidLegalHold)
+	}
+	scopes, err := dedupeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sc := range scopes {
+		orgID, err := s.repo.ResourceOrg(ctx, sc.ResourceType, sc.ResourceID)
+		if err != nil {
+			return nil, fmt.Errorf("service.LegalHold.Create: %w", err)
+		}
+		if orgID != m.OrgID {
+			return nil, fmt.Errorf("%w: %s %s not found", ErrInvalidLegalHold, sc.ResourceType, sc.ResourceID)
+		}
+	}
+
+	h := &model.LegalHold{
+		ID:        uuid.New().String(),
+		OrgID:     m.OrgID,
+		Name:      req.Name,
+		MatterID:  req.MatterID,
+		Custodian: req.Custodian,
+		Scopes:    scopes,
+		CreatedBy: actorID,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.repo.CreateHold(ctx, h); err != nil {
+		return nil, fmt.Errorf("service.LegalHold.Create: %w", err)
+	}
+	s.log(ctx, model.AuditLegalHoldCreate, actorID, h.ID, "legal_hold", map[string]interface{}{
+		"name":      h.Name,
+		"matterId":  h.MatterID,
+		"custodian": h.Custodian,
+		"scopes":    h.Scopes,
+	})
+	return h, nil
+}
+
+// List lists the holds of the caller's organization, active and released.
+// Partner only.
+func (s *LegalHoldService) List(ctx context.Context, actorID string) ([]model.LegalHold, error) {
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	holds, err := s.repo.ListHolds(ctx, m.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("service.LegalHold.List: %w", err)
+	}
+	return holds, nil
+}
+
+// Release releases an active hold, recording who released it and why.
+// Partner only.
+func (s *LegalHoldService) Release(ctx context.Context, actorID, holdID, reason string) (*model.LegalHold, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > 1000 {
+		return nil, fmt.Errorf("%w: reason must be 1-1000 characters", ErrInvalidLegalHold)
+	}
+	h, err := s.managedHold(ctx, actorID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if !h.Active() {
+		return nil, ErrLegalHoldReleased
+	}
+
+	now := s.now().UTC()
+	released, err := s.repo.ReleaseHold(ctx, h.ID, actorID, reason, now)
+	if err != nil {
+		return nil, fmt.Errorf("service.LegalHold.Release: %w", err)
+	}
+	if !released {
+		return nil, ErrLegalHoldReleased
+	}
+	h.ReleasedBy, h.ReleasedAt, h.ReleaseReason = &actorID, &now, &reason
+	s.log(ctx, model.AuditLegalHoldRelease, actorID, h.ID, "legal_hold", map[string]interface{}{
+		"name":     h.Name,
+		"matterId": h.MatterID,
+		"reason":   reason,
+	})
+	return h, nil
+}
+
+// Report lists everything the hold covers. Partner only.
+func (s *LegalHoldService) Report(ctx context.Context, actorID, holdID string) (*model.LegalHoldReport, error) {
+	h, err := s.managedHold(ctx, actorID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.repo.HeldDocuments(ctx, h.ID)
+	if err != nil {
+		return nil, fmt.Errorf("service.LegalHold.Report: %w", err)
+	}
+	report := &model.LegalHoldReport{Hold: *h, Documents: docs}
+	if report.Documents == nil {
+		report.Documents = []model.HeldDocument{}
+	}
+	for _, d := range docs {
+		report.TotalBytes += int64(d.SizeBytes)
+	}
+	return report, nil
+}
+
+// GuardDocuments returns a *LegalHoldError for the first of docIDs an
+// active hold covers, after auditing the refused attempt.
+func (s *LegalHoldService) GuardDocuments(ctx context.Context, actorID string, op LegalHoldOp, docIDs ...string) error {
+	if len(docIDs) == 0 {
+		return nil
+	}
+	held, err := s.repo.DocumentHolds(ctx, docIDs)
+	if err != nil {
+		return fmt.Errorf("service.LegalHold.GuardDocuments: %w", err)
+	}
+	for _, id := range docIDs {
+		if holds := held[id]; len(holds) > 0 {
+			return s.refuse(ctx, actorID, op, model.LegalHoldDocument, id, holds)
+		}
+	}
+	return nil
+}
+
+// GuardFolder returns a *LegalHoldError if an active hold covers the folder
+// or anything beneath it, after auditing the refused attempt.
+func (s *LegalHoldService) GuardFolder(ctx context.Context, actorID, folderID string, op LegalHoldOp) error {
+	holds, err := s.repo.FolderHolds(ctx, folderID)
+	if err != nil {
+		return fmt.Errorf("service.LegalHold.GuardFolder: %w", err)
+	}
+	if len(holds) > 0 {
+		return s.refuse(ctx, actorID, op, model.LegalHoldFolder, folderID, holds)
+	}
+	return nil
+}
+
+func (s *LegalHoldService) refuse(ctx context.Context, actorID string, op LegalHoldOp, resourceType model.LegalHoldResourceType, resourceID string, holds []model.LegalHold) error {
+	holdIDs := make([]string, len(holds))
+	matterIDs := make([]string, len(holds))
+	for i, h := range holds {
+		holdIDs[i], matterIDs[i] = h.ID, h.MatterID
+	}
+	s.log(ctx, model.AuditLegalHoldBlocked, actorID, resourceID, string(resourceType), map[string]interface{}{
+		"operation": string(op),
+		"holdIds":   holdIDs,
+		"matterIds": matterIDs,
+	})
+	return &LegalHoldError{Op: op, ResourceType: resourceType, ResourceID: resourceID, Holds: holds}
+}
+
+func (s *LegalHoldService) managedHold(ctx context.Context, actorID, holdID string) (*model.LegalHold, error) {
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	h, err := s.repo.GetHold(ctx, holdID)
+	if err != nil {
+		return nil, fmt.Errorf("service.LegalHold.Get: %w", err)
+	}
+	if h == nil || h.OrgID != m.OrgID {
+		return nil, ErrLegalHoldNotFound
+	}
+	return h, nil
+}
+
+func (s *LegalHoldService) partner(ctx context.Context, userID string) (*model.OrgMember, error) {
+	m, err := s.members.Membership(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.LegalHold.membership: %w", err)
+	}
+	if m == nil {
+		return nil, ErrNotOrgMember
+	}
+	if m.Role != model.UserRolePartner {
+		return nil, ErrOrgForbidden
+	}
+	return m, nil
+}
+
+func (s *LegalHoldService) log(ctx context.Context, action, actorID, resourceID, resourceType string, details map[string]interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogWithDetails(ctx, action, actorID, resourceID, resourceType, details); err != nil {
+		// Don't fail the change on audit error — log and continue
+		slog.Error("[LegalHold] audit log failed", "action", action, "user_id", actorID, "resource_id", resourceID, "error", err)
+	}
+}
+
+// dedupeScopes validates scopes and drops repeats, keeping the first.
+func dedupeScopes(scopes []model.LegalHoldScope) ([]model.LegalHoldScope, error) {
+	if len(scopes) == 0 || len(scopes) > maxLegalHoldScopes {
+		return nil, fmt.Errorf("%w: a hold must cover 1-%d documents, folders or vaults", ErrInvalidLegalHold, maxLegalHoldScopes)
+	}
+	seen := make(map[model.LegalHoldScope]bool, len(scopes))
+	out := make([]model.LegalHoldScope, 0, len(scopes))
+	for _, sc := range scopes {
+		if !model.ValidLegalHoldResourceType(sc.ResourceType) || sc.ResourceID == "" {
+			return nil, fmt.Errorf("%w: scopes must be documents, folders or vaults with an id", ErrInvalidLegalHold)
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			out = append(out, sc)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// memLegalHoldRepo implements LegalHoldRepository over documents that sit
+// directly in a folder (no nesting) and optionally a vault.
+type memLegalHoldRepo struct {
+	holds   map[string]*model.LegalHold
+	orgs    map[model.LegalHoldScope]string // resource → owning org
+	folders map[string]string               // document → folder
+	vaults  map[string]string               // document → vault
+}
+
+func newMemLegalHoldRepo() *memLegalHoldRepo {
+	return &memLegalHoldRepo{
+		holds: map[string]*model.LegalHold{},
+		orgs: map[model.LegalHoldScope]string{
+			{ResourceType: model.LegalHoldDocument, ResourceID: "doc-1"}:  "org-a",
+			{ResourceType: model.LegalHoldDocument, ResourceID: "doc-2"}:  "org-a",
+			{ResourceType: model.LegalHoldDocument, ResourceID: "doc-b"}:  "org-b",
+			{ResourceType: model.LegalHoldFolder, ResourceID: "folder-1"}: "org-a",
+			{ResourceType: model.LegalHoldVault, ResourceID: "vault-1"}:   "org-a",
+		},
+		folders: map[string]string{"doc-1": "folder-1"},
+		vaults:  map[string]string{"doc-2": "vault-1"},
+	}
+}
+
+func (m *memLegalHoldRepo) CreateHold(_ context.Context, h *model.LegalHold) error {
+	c := *h
+	m.holds[h.ID] = &c
+	return nil
+}
+
+func (m *memLegalHoldRepo) GetHold(_ context.Context, id string) (*model.LegalHold, error) {
+	h, ok := m.holds[id]
+	if !ok {
+		return nil, nil
+	}
+	c := *h
+	return &c, nil
+}
+
+func (m *memLegalHoldRepo) ListHolds(_ context.Context, orgID string) ([]model.LegalHold, error) {
+	var out []model.LegalHold
+	for _, h := range m.holds {
+		if h.OrgID == orgID {
+			out = append(out, *h)
+		}
+	}
+	return out, nil
+}
+
+func (m *memLegalHoldRepo) ReleaseHold(_ context.Context, id, releasedBy, reason string, at time.Time) (bool, error) {
+	h := m.holds[id]
+	if h == nil || h.ReleasedAt != nil {
+		return false, nil
+	}
+	h.ReleasedBy, h.ReleasedAt, h.ReleaseReason = &releasedBy, &at, &reason
+	return true, nil
+}
+
+func (m *memLegalHoldRepo) ResourceOrg(_ context.Context, t model.LegalHoldResourceType, id string) (string, error) {
+	return m.orgs[model.LegalHoldScope{ResourceType: t, ResourceID: id}], nil
+}
+
+func (m *memLegalHoldRepo) covers(h *model.LegalHold, docID string) bool {
+	for _, sc := range h.Scopes {
+		switch {
+		case sc.ResourceType == model.LegalHoldDocument && sc.ResourceID == docID,
+			sc.ResourceType == model.LegalHoldFolder && sc.ResourceID == m.folders[docID],
+			sc.ResourceType == model.LegalHoldVault && sc.ResourceID == m.vaults[docID]:
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memLegalHoldRepo) DocumentHolds(_ context.Context, docIDs []string) (map[string][]model.LegalHold, error) {
+	out := map[string][]model.LegalHold{}
+	for _, id := range docIDs {
+		for _, h := range m.holds {
+			if h.Active() && m.covers(h, id) {
+				out[id] = append(out[id], *h)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *memLegalHoldRepo) FolderHolds(_ context.Context, folderID string) ([]model.LegalHold, error) {
+	var out []model.LegalHold
+	for _, h := range m.holds {
+		for doc, f := range m.folders {
+			if f == folderID && h.Active() && m.covers(h, doc) {
+				out = append(out, *h)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *memLegalHoldRepo) HeldDocuments(_ context.Context, holdID string) ([]model.HeldDocument, error) {
+	var out []model.HeldDocument
+	for _, id := range []string{"doc-1", "doc-2"} {
+		if m.covers(m.holds[holdID], id) {
+			out = append(out, model.HeldDocument{ID: id, SizeBytes: 100})
+		}
+	}
+	return out, nil
+}
+
+// newLegalHoldFixture returns a service for org-a with partner "p" and
+// associate "a", and org-b with partner "x".
+func newLegalHoldFixture() (*LegalHoldService, *memLegalHoldRepo, *recordingAudit) {
+	orgs := newMemOrgRepo()
+	orgs.members["p"] = &model.OrgMember{OrgID: "org-a", UserID: "p", Role: model.UserRolePartner}
+	orgs.members["a"] = &model.OrgMember{OrgID: "org-a", UserID: "a", Role: model.UserRoleAssociate}
+	orgs.members["x"] = &model.OrgMember{OrgID: "org-b", UserID: "x", Role: model.UserRolePartner}
+	repo := newMemLegalHoldRepo()
+	audit := &recordingAudit{}
+	return NewLegalHoldService(repo, orgs, audit), repo, audit
+}
+
+func holdRequest(scopes ...model.LegalHoldScope) LegalHoldRequest {
+	return LegalHoldRequest{Name: "Acme v. Widget", MatterID: "M-1024", Custodian: "J. Doe", Scopes: scopes}
+}
+
+var (
+	heldFolder = model.LegalHoldScope{ResourceType: model.LegalHoldFolder, ResourceID: "folder-1"}
+	heldVault  = model.LegalHoldScope{ResourceType: model.LegalHoldVault, ResourceID: "vault-1"}
+)
+
+func TestLegalHold_GuardsCoveredDocumentsUntilReleased(t *testing.T) {
+	svc, _, audit := newLegalHoldFixture()
+	ctx := context.Background()
+
+	h, err := svc.Create(ctx, "p", holdRequest(heldFolder, heldFolder))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(h.Scopes) != 1 {
+		t.Errorf("scopes = %v, want the repeat dropped", h.Scopes)
+	}
+
+	err = svc.GuardDocuments(ctx, "a", LegalHoldOpDelete, "doc-2", "doc-1")
+	var lhe *LegalHoldError
+	if !errors.As(err, &lhe) || !errors.Is(err, ErrUnderLegalHold) {
+		t.Fatalf("GuardDocuments = %v, want a LegalHoldError", err)
+	}
+	if lhe.ResourceID != "doc-1" || lhe.Op != LegalHoldOpDelete || len(lhe.Holds) != 1 {
+		t.Errorf("error = %+v", lhe)
+	}
+	if msg := err.Error(); !strings.Contains(msg, `"Acme v. Widget" (matter M-1024)`) || !strings.Contains(msg, "cannot delete: document doc-1") {
+		t.Errorf("message = %q", msg)
+	}
+	if err := svc.GuardFolder(ctx, "a", "folder-1", LegalHoldOpDelete); !errors.Is(err, ErrUnderLegalHold) {
+		t.Errorf("GuardFolder = %v, want ErrUnderLegalHold", err)
+	}
+	if err := svc.GuardDocuments(ctx, "a", LegalHoldOpDelete, "doc-2"); err != nil {
+		t.Errorf("uncovered document: %v", err)
+	}
+
+	released, err := svc.Release(ctx, "p", h.ID, "Matter settled")
+	if err != nil || released.Active() || *released.ReleaseReason != "Matter settled" {
+		t.Fatalf("Release = %+v, %v", released, err)
+	}
+	if err := svc.GuardDocuments(ctx, "a", LegalHoldOpDelete, "doc-1"); err != nil {
+		t.Errorf("released hold still guards: %v", err)
+	}
+	if _, err := svc.Release(ctx, "p", h.ID, "again"); !errors.Is(err, ErrLegalHoldReleased) {
+		t.Errorf("second release = %v, want ErrLegalHoldReleased", err)
+	}
+
+	want := []string{model.AuditLegalHoldCreate, model.AuditLegalHoldBlocked, model.AuditLegalHoldBlocked, model.AuditLegalHoldRelease}
+	if strings.Join(audit.actions, ",") != strings.Join(want, ",") {
+		t.Errorf("audit = %v, want %v", audit.actions, want)
+	}
+}
+
+func TestLegalHold_CreateRules(t *testing.T) {
+	svc, _, _ := newLegalHoldFixture()
+	ctx := context.Background()
+	otherOrgDoc := model.LegalHoldScope{ResourceType: model.LegalHoldDocument, ResourceID: "doc-b"}
+
+	tests := []struct {
+		name  string
+		actor string
+		req   LegalHoldRequest
+		want  error
+	}{
+		{"associate", "a", holdRequest(heldFolder), ErrOrgForbidden},
+		{"personal user", "solo", holdRequest(heldFolder), ErrNotOrgMember},
+		{"no scopes", "p", holdRequest(), ErrInvalidLegalHold},
+		{"no matter", "p", LegalHoldRequest{Name: "n", Custodian: "c", Scopes: []model.LegalHoldScope{heldFolder}}, ErrInvalidLegalHold},
+		{"bad type", "p", holdRequest(model.LegalHoldScope{ResourceType: "chunk", ResourceID: "c-1"}), ErrInvalidLegalHold},
+		{"other org", "p", holdRequest(otherOrgDoc), ErrInvalidLegalHold},
+		{"missing", "p", holdRequest(model.LegalHoldScope{ResourceType: model.LegalHoldVault, ResourceID: "ghost"}), ErrInvalidLegalHold},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Create(ctx, tt.actor, tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("Create = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegalHold_ReportAndOrgIsolation(t *testing.T) {
+	svc, _, _ := newLegalHoldFixture()
+	ctx := context.Background()
+	h, err := svc.Create(ctx, "p", holdRequest(heldFolder, heldVault))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := svc.Report(ctx, "p", h.ID)
+	if err != nil || len(report.Documents) != 2 || report.TotalBytes != 200 || report.Hold.ID != h.ID {
+		t.Errorf("Report = %+v, %v", report, err)
+	}
+
+	if _, err := svc.Report(ctx, "x", h.ID); !errors.Is(err, ErrLegalHoldNotFound) {
+		t.Errorf("other org's Partner: %v, want ErrLegalHoldNotFound", err)
+	}
+	if _, err := svc.Release(ctx, "a", h.ID, "done"); !errors.Is(err, ErrOrgForbidden) {
+		t.Errorf("associate release: %v, want ErrOrgForbidden", err)
+	}
+	if _, err := svc.Release(ctx, "p", h.ID, " "); !errors.Is(err, ErrInvalidLegalHold) {
+		t.Errorf("release without reason: %v, want ErrInvalidLegalHold", err)
+	}
+	if holds, err := svc.List(ctx, "x"); err != nil || len(holds) != 0 {
+		t.Errorf("other org lists %d holds, %v", len(holds), err)
+	}
+}
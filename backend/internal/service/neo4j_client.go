@@ -131,6 +131,34 @@ func (c *Neo4jClient) CreateRelatedTo(ctx context.Context, tenantID, name1, type
 	return nil
 }
 
+// DeleteDocument removes a document node, the relationships recorded from
+// it, and entities that appeared in no other document (idempotent).
+func (c *Neo4jClient) DeleteDocument(ctx context.Context, tenantID, documentID string) error {
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: "neo4j"})
+	defer session.Close(ctx)
+
+	_, err := session.Run(ctx, `
+		OPTIONAL MATCH (:Entity {tenant_id: $tenant_id})-[r:RELATED_TO {document_id: $document_id}]->()
+		DELETE r
+		WITH count(*) AS _
+		OPTIONAL MATCH (d:Document {id: $document_id, tenant_id: $tenant_id})
+		OPTIONAL MATCH (e:Entity)-[:APPEARS_IN]->(d)
+		WITH d, collect(DISTINCT e) AS entities
+		DETACH DELETE d
+		WITH entities
+		UNWIND entities AS e
+		WITH e WHERE NOT (e)-[:APPEARS_IN]->()
+		DETACH DELETE e
+	`, map[string]interface{}{
+		"tenant_id":   tenantID,
+		"document_id": documentID,
+	})
+	if err != nil {
+		return fmt.Errorf("neo4j.DeleteDocument: %w", err)
+	}
+	return nil
+}
+
 // ProcessChunkEntities handles the full graph ingestion for one enriched chunk.
 func (c *Neo4jClient) ProcessChunkEntities(ctx context.Context, tenantID, documentID, filename, docType, chunkID string, entities []EntityExtracted) {
 	// Merge document node
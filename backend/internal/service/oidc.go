@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// oidcAlgorithms are the signature algorithms accepted from an identity
+// provider. Symmetric (HS*) and "none" are never accepted.
+var oidcAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+}
+
+// Defaults for OIDCConfig.
+const (
+	DefaultJWKSCacheTTL   = time.Hour
+	defaultJWKSMinRefresh = time.Minute
+	oidcClockLeeway       = time.Minute
+)
+
+// OIDCConfig configures a generic OpenID Connect ID token verifier such as
+// Okta or Azure AD.
+type OIDCConfig struct {
+	Issuer   string // required; tokens must carry exactly this iss
+	Audience string // required; must appear in aud (usually the client ID)
+	// JWKSURL is the provider's key set. Empty discovers it from
+	// {Issuer}/.well-known/openid-configuration.
+	JWKSURL string
+	// ProviderID namespaces user IDs from this provider as
+	// oidc:<ProviderID>:<subject>, so a subject can never collide with a
+	// Firebase UID or another provider's subject. Default: the issuer's host.
+	ProviderID string
+	// Claims holding the user ID (default "sub"), email (default "email")
+	// and role (optional; string or array of strings).
+	UserIDClaim string
+	EmailClaim  string
+	RoleClaim   string
+	// RoleMap maps role claim values (e.g. an Okta group) to firm roles.
+	// Values that already name a role (Partner, Associate, Auditor) map to
+	// themselves.
+	RoleMap  map[string]model.UserRole
+	CacheTTL time.Duration // JWKS cache lifetime (default DefaultJWKSCacheTTL)
+	Client   *http.Client  // default http.DefaultClient with a 10s timeout
+}
+
+// Identity is an authenticated caller as described by their ID token.
+// Email and Role are empty when the provider does not supply them.
+type Identity struct {
+	UserID string
+	Email  string
+	Role   model.UserRole
+	Issuer string
+}
+
+// OIDCVerifier validates ID tokens from one OIDC issuer against its JWKS.
+// Keys are cached for CacheTTL and refetched early when a token names an
+// unknown key ID (key rotation), at most once per minute.
+type OIDCVerifier struct {
+	cfg    OIDCConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+	jwksURL   string
+}
+
+// NewOIDCVerifier creates an OIDCVerifier. No network calls are made until
+// the first token is verified.
+func NewOIDCVerifier(cfg OIDCConfig) (*OIDCVerifier, error) {
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, fmt.Errorf("service.NewOIDCVerifier: issuer and audience are required")
+	}
+	if cfg.ProviderID == "" {
+		cfg.ProviderID = cfg.Issuer
+		if u, err := url.Parse(cfg.Issuer); err == nil && u.Host != "" {
+			cfg.ProviderID = u.Host
+		}
+	}
+	if cfg.UserIDClaim == "" {
+		cfg.UserIDClaim = "sub"
+	}
+	if cfg.EmailClaim == "" {
+		cfg.EmailClaim = "email"
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = DefaultJWKSCacheTTL
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OIDCVerifier{cfg: cfg, client: client, now: time.Now, jwksURL: cfg.JWKSURL}, nil
+}
+
+// Issuer returns the issuer this verifier accepts.
+func (v *OIDCVerifier) Issuer() string {
+	return v.cfg.Issuer
+}
+
+// Verify checks the token's signature, issuer, audience and expiry and
+// maps its claims to an Identity.
+func (v *OIDCVerifier) Verify(ctx context.Context, rawToken string) (*Identity, error) {
+	tok, err := jwt.ParseSigned(rawToken, oidcAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("service.OIDC.Verify: parse: %w", err)
+	}
+	if len(tok.Headers) != 1 {
+		return nil, fmt.Errorf("service.OIDC.Verify: expected one signature")
+	}
+
+	key, err := v.key(ctx, tok.Headers[0].KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("service.OIDC.Verify: %w", err)
+	}
+
+	var std jwt.Claims
+	var raw map[string]interface{}
+	if err := tok.Claims(key.Key, &std, &raw); err != nil {
+		return nil, fmt.Errorf("service.OIDC.Verify: signature: %w", err)
+	}
+	if std.Expiry == nil {
+		return nil, fmt.Errorf("service.OIDC.Verify: token has no expiry")
+	}
+	expected := jwt.Expected{Issuer: v.cfg.Issuer, AnyAudience: jwt.Audience{v.cfg.Audience}, Time: v.now()}
+	if err := std.ValidateWithLeeway(expected, oidcClockLeeway); err != nil {
+		return nil, fmt.Errorf("service.OIDC.Verify: %w", err)
+	}
+
+	subject, _ := raw[v.cfg.UserIDClaim].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("service.OIDC.Verify: claim %q is missing", v.cfg.UserIDClaim)
+	}
+	email, _ := raw[v.cfg.EmailClaim].(string)
+	return &Identity{
+		UserID: OIDCUserID(v.cfg.ProviderID, subject),
+		Email:  email,
+		Role:   v.mapRole(raw[v.cfg.RoleClaim]),
+		Issuer: std.Issuer,
+	}, nil
+}
+
+// OIDCUserID returns the users.id for subject at the given provider.
+func OIDCUserID(providerID, subject string) string {
+	return "oidc:" + providerID + ":" + subject
+}
+
+// mapRole returns the first claim value that maps to a firm role, or "".
+func (v *OIDCVerifier) mapRole(claim interface{}) model.UserRole {
+	if v.cfg.RoleClaim == "" {
+		return ""
+	}
+	var values []string
+	switch c := claim.(type) {
+	case string:
+		values = []string{c}
+	case []interface{}:
+		for _, item := range c {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	for _, val := range values {
+		if role, ok := v.cfg.RoleMap[val]; ok {
+			return role
+		}
+		if model.ValidUserRole(model.UserRole(val)) {
+			return model.UserRole(val)
+		}
+	}
+	return ""
+}
+
+// key returns the signing key with kid, refreshing the cached key set when
+// it is stale or does not contain kid.
+func (v *OIDCVerifier) key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	stale := v.keys == nil || now.Sub(v.fetchedAt) > v.cfg.CacheTTL
+	if !stale {
+		if k := findSigningKey(v.keys, kid); k != nil {
+			return k, nil
+		}
+		// Unknown kid: the provider may have rotated keys. Refetch, but not
+		// on every request carrying a bogus kid.
+		if now.Sub(v.fetchedAt) < defaultJWKSMinRefresh {
+			return nil, fmt.Errorf("no signing key %q", kid)
+		}
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		if v.keys != nil {
+			// Keep serving the old keys while the provider is unreachable.
+			if k := findSigningKey(v.keys, kid); k != nil {
+				return k, nil
+			}
+		}
+		return nil, err
+	}
+	v.keys, v.fetchedAt = keys, now
+	if k := findSigningKey(keys, kid); k != nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("no signing key %q", kid)
+}
+
+// findSigningKey returns the public key with kid, or the only key when the
+// token names none.
+func findSigningKey(set *jose.JSONWebKeySet, kid string) *jose.JSONWebKey {
+	if kid == "" {
+		if len(set.Keys) == 1 && set.Keys[0].IsPublic() {
+			return &set.Keys[0]
+		}
+		return nil
+	}
+	for _, k := range set.Key(kid) {
+		if k.IsPublic() && (k.Use == "" || k.Use == "sig") {
+			return &k
+		}
+	}
+	return nil
+}
+
+func (v *OIDCVerifier) fetchKeys(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	if v.jwksURL == "" {
+		var disc struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		url := strings.TrimSuffix(v.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := v.getJSON(ctx, url, &disc); err != nil {
+			return nil, fmt.Errorf("discovery: %w", err)
+		}
+		if disc.JWKSURI == "" {
+			return nil, errors.New("discovery: jwks_uri missing")
+		}
+		v.jwksURL = disc.JWKSURI
+	}
+	var set jose.JSONWebKeySet
+	if err := v.getJSON(ctx, v.jwksURL, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	return &set, nil
+}
+
+func (v *OIDCVerifier) getJSON(ctx context.Context, url string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+// tokenIssuer returns the unverified iss claim of a JWT, or "" if rawToken
+// is not one. Only used to pick a verifier; the chosen verifier checks it.
+func tokenIssuer(rawToken string) string {
+	tok, err := jwt.ParseSigned(rawToken, oidcAlgorithms)
+	if err != nil {
+		return ""
+	}
+	var c jwt.Claims
+	if err := tok.UnsafeClaimsWithoutVerification(&c); err != nil {
+		return ""
+	}
+	return c.Issuer
+}
+
+// ParseRoleMap parses "claimValue=Role,..." (e.g. "okta-partners=Partner")
+// into a role map. Unknown roles are an error.
+func ParseRoleMap(s string) (map[string]model.UserRole, error) {
+	out := map[string]model.UserRole{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, val, ok := strings.Cut(pair, "=")
+		role := model.UserRole(strings.TrimSpace(val))
+		if !ok || strings.TrimSpace(k) == "" || !model.ValidUserRole(role) {
+			return nil, fmt.Errorf("service.ParseRoleMap: invalid entry %q", pair)
+		}
+		out[strings.TrimSpace(k)] = role
+	}
+	return out, nil
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// testIdP is a local OIDC provider serving discovery and a JWKS.
+type testIdP struct {
+	srv     *httptest.Server
+	keys    atomic.Pointer[jose.JSONWebKeySet]
+	fetches atomic.Int32
+	signers map[string]*rsa.PrivateKey
+}
+
+func newTestIdP(t *testing.T, kids ...string) *testIdP {
+	t.Helper()
+	idp := &testIdP{signers: map[string]*rsa.PrivateKey{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": idp.srv.URL, "jwks_uri": idp.srv.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		idp.fetches.Add(1)
+		json.NewEncoder(w).Encode(idp.keys.Load())
+	})
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	idp.publish(t, kids...)
+	return idp
+}
+
+// publish replaces the served key set with fresh keys for kids.
+func (idp *testIdP) publish(t *testing.T, kids ...string) {
+	t.Helper()
+	set := &jose.JSONWebKeySet{}
+	for _, kid := range kids {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idp.signers[kid] = key
+		set.Keys = append(set.Keys, jose.JSONWebKey{Key: &key.PublicKey, KeyID: kid, Algorithm: string(jose.RS256), Use: "sig"})
+	}
+	idp.keys.Store(set)
+}
+
+func (idp *testIdP) sign(t *testing.T, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: idp.signers[kid]},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", kid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func (idp *testIdP) claims(now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":    idp.srv.URL,
+		"aud":    "ragbox",
+		"sub":    "okta-user-1",
+		"email":  "ada@acme.com",
+		"groups": []string{"everyone", "acme-partners"},
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Hour).Unix(),
+	}
+}
+
+func newTestVerifier(t *testing.T, idp *testIdP) *OIDCVerifier {
+	t.Helper()
+	v, err := NewOIDCVerifier(OIDCConfig{
+		Issuer:    idp.srv.URL,
+		Audience:  "ragbox",
+		RoleClaim: "groups",
+		RoleMap:   map[string]model.UserRole{"acme-partners": model.UserRolePartner},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestOIDC_VerifyMapsClaims(t *testing.T) {
+	idp := newTestIdP(t, "k1")
+	v := newTestVerifier(t, idp)
+
+	id, err := v.Verify(context.Background(), idp.sign(t, "k1", idp.claims(time.Now())))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	host := strings.TrimPrefix(idp.srv.URL, "http://")
+	if id.UserID != "oidc:"+host+":okta-user-1" || id.Email != "ada@acme.com" || id.Role != model.UserRolePartner || id.Issuer != idp.srv.URL {
+		t.Errorf("identity = %+v", id)
+	}
+}
+
+func TestOIDC_RejectsInvalidTokens(t *testing.T) {
+	idp := newTestIdP(t, "k1")
+	other := newTestIdP(t, "k1")
+	v := newTestVerifier(t, idp)
+	now := time.Now()
+
+	with := func(key string, val interface{}) map[string]interface{} {
+		c := idp.claims(now)
+		if val == nil {
+			delete(c, key)
+		} else {
+			c[key] = val
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong issuer", idp.sign(t, "k1", with("iss", "https://evil.example.com"))},
+		{"wrong audience", idp.sign(t, "k1", with("aud", "someone-else"))},
+		{"expired", idp.sign(t, "k1", with("exp", now.Add(-time.Hour).Unix()))},
+		{"no expiry", idp.sign(t, "k1", with("exp", nil))},
+		{"not yet valid", idp.sign(t, "k1", with("nbf", now.Add(time.Hour).Unix()))},
+		{"no subject", idp.sign(t, "k1", with("sub", nil))},
+		{"signed by another key", other.sign(t, "k1", idp.claims(now))},
+		{"malformed", idp.sign(t, "k1", idp.claims(now))[:10] + "garbage"},
+		{"hmac", hmacToken(t, idp.claims(now))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.Verify(context.Background(), tt.token); err == nil {
+				t.Error("Verify accepted an invalid token")
+			}
+		})
+	}
+}
+
+func hmacToken(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte("0123456789abcdef0123456789abcdef")},
+		(&jose.SignerOptions{}).WithHeader("kid", "k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestOIDC_CachesAndRefreshesOnRotation(t *testing.T) {
+	idp := newTestIdP(t, "k1")
+	v := newTestVerifier(t, idp)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(ctx, idp.sign(t, "k1", idp.claims(now))); err != nil {
+			t.Fatalf("Verify %d: %v", i, err)
+		}
+	}
+	if n := idp.fetches.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times, want 1 (cached)", n)
+	}
+
+	// The provider rotates to k2. Within the refresh floor the new kid is
+	// refused without hitting the provider again.
+	idp.publish(t, "k2")
+	if _, err := v.Verify(ctx, idp.sign(t, "k2", idp.claims(now))); err == nil {
+		t.Error("unknown kid accepted before refresh")
+	}
+	if n := idp.fetches.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times, want 1 (refresh floor)", n)
+	}
+
+	now = now.Add(2 * defaultJWKSMinRefresh)
+	if _, err := v.Verify(ctx, idp.sign(t, "k2", idp.claims(now))); err != nil {
+		t.Fatalf("Verify after rotation: %v", err)
+	}
+	if n := idp.fetches.Load(); n != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", n)
+	}
+}
+
+func TestAuthService_RoutesByIssuer(t *testing.T) {
+	idp := newTestIdP(t, "k1")
+	firebase := &stubFirebase{uid: "firebase-user"}
+	svc := NewAuthService(firebase, newTestVerifier(t, idp))
+	ctx := context.Background()
+
+	uid, err := svc.VerifyToken(ctx, idp.sign(t, "k1", idp.claims(time.Now())))
+	if err != nil || uid != OIDCUserID(strings.TrimPrefix(idp.srv.URL, "http://"), "okta-user-1") {
+		t.Errorf("OIDC token: uid = %q, err = %v", uid, err)
+	}
+	if firebase.calls != 0 {
+		t.Error("OIDC token was sent to Firebase")
+	}
+
+	// Tokens from other issuers still go to Firebase.
+	uid, err = svc.VerifyToken(ctx, "firebase-id-token")
+	if err != nil || uid != "firebase-user" || firebase.calls != 1 {
+		t.Errorf("Firebase token: uid = %q, err = %v, calls = %d", uid, err, firebase.calls)
+	}
+
+	// An OIDC token that fails verification is not retried with Firebase.
+	bad := idp.claims(time.Now())
+	bad["aud"] = "someone-else"
+	if _, err := svc.VerifyToken(ctx, idp.sign(t, "k1", bad)); err == nil || firebase.calls != 1 {
+		t.Errorf("bad OIDC token: err = %v, firebase calls = %d", err, firebase.calls)
+	}
+}
+
+func TestOIDC_UserIDsDoNotCollide(t *testing.T) {
+	idp := newTestIdP(t, "k1")
+	// A Firebase account whose UID happens to equal the OIDC subject.
+	firebase := &stubFirebase{uid: "okta-user-1"}
+	svc := NewAuthService(firebase, newTestVerifier(t, idp))
+	ctx := context.Background()
+
+	oidcUID, err := svc.VerifyToken(ctx, idp.sign(t, "k1", idp.claims(time.Now())))
+	if err != nil {
+		t.Fatalf("OIDC token: %v", err)
+	}
+	firebaseUID, err := svc.VerifyToken(ctx, "firebase-id-token")
+	if err != nil {
+		t.Fatalf("Firebase token: %v", err)
+	}
+	if oidcUID == firebaseUID {
+		t.Errorf("OIDC subject and Firebase UID both map to user %q", oidcUID)
+	}
+
+	// The same subject at a second provider is a different user too.
+	other, err := NewOIDCVerifier(OIDCConfig{Issuer: idp.srv.URL, Audience: "ragbox", ProviderID: "azure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := other.Verify(ctx, idp.sign(t, "k1", idp.claims(time.Now())))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if id.UserID != "oidc:azure:okta-user-1" || id.UserID == oidcUID {
+		t.Errorf("second provider user ID = %q, want oidc:azure:okta-user-1", id.UserID)
+	}
+}
+
+// stubFirebase implements AuthClient, counting calls.
+type stubFirebase struct {
+	uid   string
+	calls int
+}
+
+func (s *stubFirebase) VerifyIDToken(context.Context, string) (*auth.Token, error) {
+	s.calls++
+	if s.uid == "" {
+		return nil, errors.New("invalid token")
+	}
+	return &auth.Token{UID: s.uid, Claims: map[string]interface{}{"email": s.uid + "@example.com"}}, nil
+}
+
+func TestParseRoleMap(t *testing.T) {
+	m, err := ParseRoleMap("acme-partners=Partner, acme-staff=Associate,")
+	if err != nil || m["acme-partners"] != model.UserRolePartner || m["acme-staff"] != model.UserRoleAssociate {
+		t.Errorf("ParseRoleMap = %v, %v", m, err)
+	}
+	for _, bad := range []string{"acme=Owner", "=Partner", "acme"} {
+		if _, err := ParseRoleMap(bad); err == nil {
+			t.Errorf("ParseRoleMap(%q) accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// Organization errors. Handlers map them to HTTP statuses.
+var (
+	ErrNotOrgMember      = errors.New("not a member of an organization")
+	ErrOrgForbidden      = errors.New("only a Partner can manage the organization")
+	ErrAlreadyInOrg      = errors.New("already a member of an organization")
+	ErrLastPartner       = errors.New("an organization must keep at least one Partner")
+	ErrInviteInvalid     = errors.New("invitation is invalid, expired or already used")
+	ErrInviteWrongEmail  = errors.New("invitation was sent to a different email address")
+	ErrMemberNotFound    = errors.New("member not found")
+	ErrInvalidOrgRole    = errors.New("invalid role")
+	ErrInvalidOrgRequest = errors.New("invalid organization request")
+)
+
+// DefaultInviteTTL is how long an invitation can be accepted.
+const DefaultInviteTTL = 7 * 24 * time.Hour
+
+// OrganizationRepository persists organizations, memberships and invites.
+// Implemented by repository.OrgRepo.
+type OrganizationRepository interface {
+	// Create inserts org with creatorID as its first Partner and moves the
+	// creator's personal documents, vaults and folders into it.
+	Create(ctx context.Context, org *model.Organization, creatorID string) error
+	GetByID(ctx context.Context, id string) (*model.Organization, error)
+	Rename(ctx context.Context, id, name string) error
+	// Membership returns userID's membership, or nil if they have none.
+	Membership(ctx context.Context, userID string) (*model.OrgMember, error)
+	ListMembers(ctx context.Context, orgID string) ([]model.OrgMember, error)
+	SetMemberRole(ctx context.Context, orgID, userID string, role model.UserRole) error
+	// RemoveMember deletes the membership and transfers the member's org
+	// documents, vaults and folders to a remaining Partner.
+	RemoveMember(ctx context.Context, orgID, userID string) error
+	CreateInvite(ctx context.Context, inv *model.OrgInvite, tokenHash string) error
+	ListInvites(ctx context.Context, orgID string) ([]model.OrgInvite, error)
+	RevokeInvite(ctx context.Context, orgID, inviteID string) error
+	// InviteByTokenHash returns a pending (unaccepted) invite, or nil.
+	InviteByTokenHash(ctx context.Context, tokenHash string) (*model.OrgInvite, error)
+	// AcceptInvite adds userID to the invite's organization and marks the
+	// invite used, atomically. With moveContent the user's personal documents,
+	// vaults and folders move into the org in the same transaction. It returns
+	// ErrInviteInvalid if the invite was used concurrently.
+	AcceptInvite(ctx context.Context, inviteID, userID string, moveContent bool) error
+	// UsageAccount returns the ID usage is pooled under: the org ID for
+	// members, else the user ID.
+	UsageAccount(ctx context.Context, userID string) (string, error)
+}
+
+// OrganizationService manages organizations, their members and invitations.
+// Partners administer an organization; any member can view it.
+type OrganizationService struct {
+	repo      OrganizationRepository
+	userEmail func(ctx context.Context, userID string) (string, error)
+	inviteTTL time.Duration
+	now       func() time.Time
+}
+
+// NewOrganizationService creates an OrganizationService. userEmail resolves
+// a user's email address for invite matching (users.email).
+func NewOrganizationService(repo OrganizationRepository, userEmail func(ctx context.Context, userID string) (string, error)) *OrganizationService {
+	return &OrganizationService{repo: repo, userEmail: userEmail, inviteTTL: DefaultInviteTTL, now: time.Now}
+}
+
+// Current returns the caller's organization and membership.
+// Returns ErrNotOrgMember for users in their personal scope.
+func (s *OrganizationService) Current(ctx context.Context, userID string) (*model.Organization, *model.OrgMember, error) {
+	m, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	org, err := s.repo.GetByID(ctx, m.OrgID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service.Organization.Current: %w", err)
+	}
+	return org, m, nil
+}
+
+// Create founds an organization with userID as Partner. The user's personal
+// content moves into it; the organization inherits their subscription tier.
+func (s *OrganizationService) Create(ctx context.Context, userID, name string) (*model.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 200 {
+		return nil, fmt.Errorf("%w: name must be 1-200 characters", ErrInvalidOrgRequest)
+	}
+	m, err := s.repo.Membership(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Organization.Create: %w", err)
+	}
+	if m != nil {
+		return nil, ErrAlreadyInOrg
+	}
+
+	now := s.now().UTC()
+	org := &model.Organization{
+		ID:        uuid.New().String(),
+		Name:      name,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Create(ctx, org, userID); err != nil {
+		return nil, fmt.Errorf("service.Organization.Create: %w", err)
+	}
+	return org, nil
+}
+
+// Rename changes the organization name. Partner only.
+func (s *OrganizationService) Rename(ctx context.Context, actorID, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 200 {
+		return fmt.Errorf("%w: name must be 1-200 characters", ErrInvalidOrgRequest)
+	}
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Rename(ctx, m.OrgID, name); err != nil {
+		return fmt.Errorf("service.Organization.Rename: %w", err)
+	}
+	return nil
+}
+
+// ListMembers lists the caller's organization members.
+func (s *OrganizationService) ListMembers(ctx context.Context, userID string) ([]model.OrgMember, error) {
+	m, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, m.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Organization.ListMembers: %w", err)
+	}
+	return members, nil
+}
+
+// SetMemberRole changes a member's role. Partner only; the last Partner
+// cannot be demoted.
+func (s *OrganizationService) SetMemberRole(ctx context.Context, actorID, targetID string, role model.UserRole) error {
+	if !model.ValidUserRole(role) {
+		return ErrInvalidOrgRole
+	}
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	target, err := s.member(ctx, m.OrgID, targetID)
+	if err != nil {
+		return err
+	}
+	if target.Role == model.UserRolePartner && role != model.UserRolePartner {
+		if err := s.ensureAnotherPartner(ctx, m.OrgID, targetID); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.SetMemberRole(ctx, m.OrgID, targetID, role); err != nil {
+		return fmt.Errorf("service.Organization.SetMemberRole: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes targetID from the caller's organization. Partners may
+// remove anyone; any member may remove themselves (leave). The removed user
+// returns to their personal scope; content they own in the organization stays
+// there and passes to a remaining Partner.
+func (s *OrganizationService) RemoveMember(ctx context.Context, actorID, targetID string) error {
+	actor, err := s.membership(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if actorID != targetID && actor.Role != model.UserRolePartner {
+		return ErrOrgForbidden
+	}
+	target, err := s.member(ctx, actor.OrgID, targetID)
+	if err != nil {
+		return err
+	}
+	if target.Role == model.UserRolePartner {
+		if err := s.ensureAnotherPartner(ctx, actor.OrgID, targetID); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.RemoveMember(ctx, actor.OrgID, targetID); err != nil {
+		return fmt.Errorf("service.Organization.RemoveMember: %w", err)
+	}
+	return nil
+}
+
+// Invite creates an invitation for email to join the caller's organization
+// with role. Partner only. The returned token is shown once; only its hash
+// is stored.
+func (s *OrganizationService) Invite(ctx context.Context, actorID, email string, role model.UserRole) (*model.OrgInvite, string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") || len(email) > 320 {
+		return nil, "", fmt.Errorf("%w: a valid email is required", ErrInvalidOrgRequest)
+	}
+	if role == "" {
+		role = model.UserRoleAssociate
+	}
+	if !model.ValidUserRole(role) {
+		return nil, "", ErrInvalidOrgRole
+	}
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("service.Organization.Invite: %w", err)
+	}
+	now := s.now().UTC()
+	inv := &model.OrgInvite{
+		ID:        uuid.New().String(),
+		OrgID:     m.OrgID,
+		Email:     email,
+		Role:      role,
+		InvitedBy: actorID,
+		ExpiresAt: now.Add(s.inviteTTL),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateInvite(ctx, inv, hashInviteToken(token)); err != nil {
+		return nil, "", fmt.Errorf("service.Organization.Invite: %w", err)
+	}
+	return inv, token, nil
+}
+
+// ListInvites lists pending invitations. Partner only.
+func (s *OrganizationService) ListInvites(ctx context.Context, actorID string) ([]model.OrgInvite, error) {
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	invites, err := s.repo.ListInvites(ctx, m.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Organization.ListInvites: %w", err)
+	}
+	return invites, nil
+}
+
+// RevokeInvite deletes a pending invitation. Partner only.
+func (s *OrganizationService) RevokeInvite(ctx context.Context, actorID, inviteID string) error {
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RevokeInvite(ctx, m.OrgID, inviteID); err != nil {
+		return fmt.Errorf("service.Organization.RevokeInvite: %w", err)
+	}
+	return nil
+}
+
+// AcceptInvite joins userID to the organization the token invites them to.
+// The invite must be unexpired and addressed to the user's email. Personal
+// content stays in the user's personal scope unless moveContent is set, since
+// unvaulted documents moved into the org are visible to every member.
+func (s *OrganizationService) AcceptInvite(ctx context.Context, userID, token string, moveContent bool) (*model.Organization, error) {
+	if token == "" {
+		return nil, ErrInviteInvalid
+	}
+	inv, err := s.repo.InviteByTokenHash(ctx, hashInviteToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("service.Organization.AcceptInvite: %w", err)
+	}
+	if inv == nil || !s.now().Before(inv.ExpiresAt) {
+		return nil, ErrInviteInvalid
+	}
+
+	email, err := s.userEmail(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Organization.AcceptInvite: email: %w", err)
+	}
+	if !strings.EqualFold(strings.TrimSpace(email), inv.Email) {
+		return nil, ErrInviteWrongEmail
+	}
+
+	m, err := s.repo.Membership(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Organization.AcceptInvite: %w", err)
+	}
+	if m != nil {
+		return nil, ErrAlreadyInOrg
+	}
+
+	if err := s.repo.AcceptInvite(ctx, inv.ID, userID, moveContent); err != nil {
+		if errors.Is(err, ErrInviteInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("service.Organization.AcceptInvite: %w", err)
+	}
+	org, err := s.repo.GetByID(ctx, inv.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Organization.AcceptInvite: %w", err)
+	}
+	return org, nil
+}
+
+// UsageAccount returns the account usage is pooled under for userID. Errors
+// fall back to the user's own account. Pass it to UsageService.SetAccountResolver.
+func (s *OrganizationService) UsageAccount(ctx context.Context, userID string) string {
+	account, err := s.repo.UsageAccount(ctx, userID)
+	if err != nil || account == "" {
+		return userID
+	}
+	return account
+}
+
+func (s *OrganizationService) membership(ctx context.Context, userID string) (*model.OrgMember, error) {
+	m, err := s.repo.Membership(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Organization: membership: %w", err)
+	}
+	if m == nil {
+		return nil, ErrNotOrgMember
+	}
+	return m, nil
+}
+
+func (s *OrganizationService) partner(ctx context.Context, userID string) (*model.OrgMember, error) {
+	m, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Role != model.UserRolePartner {
+		return nil, ErrOrgForbidden
+	}
+	return m, nil
+}
+
+// member returns userID's membership if they belong to orgID.
+func (s *OrganizationService) member(ctx context.Context, orgID, userID string) (*model.OrgMember, error) {
+	m, err := s.repo.Membership(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Organization: membership: %w", err)
+	}
+	if m == nil || m.OrgID != orgID {
+		return nil, ErrMemberNotFound
+	}
+	return m, nil
+}
+
+// ensureAnotherPartner returns ErrLastPartner unless orgID has a Partner
+// other than userID.
+func (s *OrganizationService) ensureAnotherPartner(ctx context.Context, orgID, userID string) error {
+	members, err := s.repo.ListMembers(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("service.Organization: list members: %w", err)
+	}
+	for _, m := range members {
+		if m.UserID != userID && m.Role == model.UserRolePartner {
+			return nil
+		}
+	}
+	return ErrLastPartner
+}
+
+func newInviteToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// memOrgRepo implements OrganizationRepository in memory.
+type memOrgRepo struct {
+	orgs    map[string]*model.Organization
+	members map[string]*model.OrgMember // by user ID
+	invites map[string]*model.OrgInvite // by token hash
+}
+
+func newMemOrgRepo() *memOrgRepo {
+	return &memOrgRepo{
+		orgs:    map[string]*model.Organization{},
+		members: map[string]*model.OrgMember{},
+		invites: map[string]*model.OrgInvite{},
+	}
+}
+
+func (m *memOrgRepo) Create(_ context.Context, org *model.Organization, creatorID string) error {
+	org.SubscriptionTier = "starter"
+	m.orgs[org.ID] = org
+	m.members[creatorID] = &model.OrgMember{OrgID: org.ID, UserID: creatorID, Role: model.UserRolePartner}
+	return nil
+}
+
+func (m *memOrgRepo) GetByID(_ context.Context, id string) (*model.Organization, error) {
+	if o, ok := m.orgs[id]; ok {
+		return o, nil
+	}
+	return nil, errors.New("no rows in result set")
+}
+
+func (m *memOrgRepo) Rename(_ context.Context, id, name string) error {
+	m.orgs[id].Name = name
+	return nil
+}
+
+func (m *memOrgRepo) Membership(_ context.Context, userID string) (*model.OrgMember, error) {
+	if mem, ok := m.members[userID]; ok {
+		cp := *mem
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *memOrgRepo) ListMembers(_ context.Context, orgID string) ([]model.OrgMember, error) {
+	var out []model.OrgMember
+	for _, mem := range m.members {
+		if mem.OrgID == orgID {
+			out = append(out, *mem)
+		}
+	}
+	return out, nil
+}
+
+func (m *memOrgRepo) SetMemberRole(_ context.Context, _, userID string, role model.UserRole) error {
+	m.members[userID].Role = role
+	return nil
+}
+
+func (m *memOrgRepo) RemoveMember(_ context.Context, _, userID string) error {
+	delete(m.members, userID)
+	return nil
+}
+
+func (m *memOrgRepo) CreateInvite(_ context.Context, inv *model.OrgInvite, tokenHash string) error {
+	m.invites[tokenHash] = inv
+	return nil
+}
+
+func (m *memOrgRepo) ListInvites(_ context.Context, orgID string) ([]model.OrgInvite, error) {
+	var out []model.OrgInvite
+	for _, inv := range m.invites {
+		if inv.OrgID == orgID && inv.AcceptedAt == nil {
+			out = append(out, *inv)
+		}
+	}
+	return out, nil
+}
+
+func (m *memOrgRepo) RevokeInvite(_ context.Context, _, inviteID string) error {
+	for h, inv := range m.invites {
+		if inv.ID == inviteID {
+			delete(m.invites, h)
+		}
+	}
+	return nil
+}
+
+func (m *memOrgRepo) InviteByTokenHash(_ context.Context, tokenHash string) (*model.OrgInvite, error) {
+	if inv, ok := m.invites[tokenHash]; ok && inv.AcceptedAt == nil {
+		return inv, nil
+	}
+	return nil, nil
+}
+
+func (m *memOrgRepo) AcceptInvite(_ context.Context, inviteID, userID string, _ bool) error {
+	for _, inv := range m.invites {
+		if inv.ID == inviteID && inv.AcceptedAt == nil {
+			now := time.Now()
+			inv.AcceptedAt, inv.AcceptedBy = &now, &userID
+			m.members[userID] = &model.OrgMember{OrgID: inv.OrgID, UserID: userID, Role: inv.Role}
+			return nil
+		}
+	}
+	return ErrInviteInvalid
+}
+
+func (m *memOrgRepo) UsageAccount(_ context.Context, userID string) (string, error) {
+	if mem, ok := m.members[userID]; ok {
+		return mem.OrgID, nil
+	}
+	return userID, nil
+}
+
+func emailsByUser(emails map[string]string) func(context.Context, string) (string, error) {
+	return func(_ context.Context, userID string) (string, error) { return emails[userID], nil }
+}
+
+// newTestOrg returns a service with an organization founded by "p1".
+func newTestOrg(t *testing.T) (*OrganizationService, *memOrgRepo, *model.Organization) {
+	t.Helper()
+	repo := newMemOrgRepo()
+	svc := NewOrganizationService(repo, emailsByUser(map[string]string{
+		"a1": "a1@firm.com", "a2": "A2@Firm.com", "x1": "x1@other.com",
+	}))
+	org, err := svc.Create(context.Background(), "p1", "Acme LLP")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return svc, repo, org
+}
+
+// join invites userID by email and accepts the invite.
+func join(t *testing.T, svc *OrganizationService, userID, email string, role model.UserRole) {
+	t.Helper()
+	ctx := context.Background()
+	_, token, err := svc.Invite(ctx, "p1", email, role)
+	if err != nil {
+		t.Fatalf("Invite: %v", err)
+	}
+	if _, err := svc.AcceptInvite(ctx, userID, token, false); err != nil {
+		t.Fatalf("AcceptInvite: %v", err)
+	}
+}
+
+func TestOrganization_CreateMakesPartner(t *testing.T) {
+	svc, _, org := newTestOrg(t)
+	ctx := context.Background()
+
+	got, m, err := svc.Current(ctx, "p1")
+	if err != nil || got.ID != org.ID || m.Role != model.UserRolePartner {
+		t.Fatalf("Current = %+v, %+v, %v", got, m, err)
+	}
+	if _, err := svc.Create(ctx, "p1", "Second"); !errors.Is(err, ErrAlreadyInOrg) {
+		t.Errorf("second Create err = %v, want ErrAlreadyInOrg", err)
+	}
+	if _, err := svc.Create(ctx, "u9", "  "); !errors.Is(err, ErrInvalidOrgRequest) {
+		t.Errorf("blank name err = %v, want ErrInvalidOrgRequest", err)
+	}
+	if _, _, err := svc.Current(ctx, "u9"); !errors.Is(err, ErrNotOrgMember) {
+		t.Errorf("non-member Current err = %v, want ErrNotOrgMember", err)
+	}
+}
+
+func TestOrganization_InviteAndAccept(t *testing.T) {
+	svc, repo, org := newTestOrg(t)
+	ctx := context.Background()
+
+	inv, token, err := svc.Invite(ctx, "p1", "a2@firm.com", "")
+	if err != nil {
+		t.Fatalf("Invite: %v", err)
+	}
+	if inv.Role != model.UserRoleAssociate {
+		t.Errorf("default role = %q, want Associate", inv.Role)
+	}
+	if _, stored := repo.invites[token]; stored {
+		t.Error("raw token must not be stored")
+	}
+
+	if _, err := svc.AcceptInvite(ctx, "x1", token, false); !errors.Is(err, ErrInviteWrongEmail) {
+		t.Errorf("wrong user err = %v, want ErrInviteWrongEmail", err)
+	}
+	got, err := svc.AcceptInvite(ctx, "a2", token, false) // email match is case-insensitive
+	if err != nil || got.ID != org.ID {
+		t.Fatalf("AcceptInvite = %+v, %v", got, err)
+	}
+	if _, err := svc.AcceptInvite(ctx, "a2", token, false); !errors.Is(err, ErrInviteInvalid) {
+		t.Errorf("reused token err = %v, want ErrInviteInvalid", err)
+	}
+}
+
+func TestOrganization_InviteRules(t *testing.T) {
+	svc, _, _ := newTestOrg(t)
+	ctx := context.Background()
+	join(t, svc, "a1", "a1@firm.com", model.UserRoleAssociate)
+
+	if _, _, err := svc.Invite(ctx, "a1", "x@firm.com", ""); !errors.Is(err, ErrOrgForbidden) {
+		t.Errorf("associate invite err = %v, want ErrOrgForbidden", err)
+	}
+	if _, _, err := svc.Invite(ctx, "p1", "x@firm.com", "Owner"); !errors.Is(err, ErrInvalidOrgRole) {
+		t.Errorf("bad role err = %v, want ErrInvalidOrgRole", err)
+	}
+	if _, _, err := svc.Invite(ctx, "p1", "not-an-email", ""); !errors.Is(err, ErrInvalidOrgRequest) {
+		t.Errorf("bad email err = %v, want ErrInvalidOrgRequest", err)
+	}
+
+	// Expired invite
+	_, token, _ := svc.Invite(ctx, "p1", "x1@other.com", "")
+	svc.now = func() time.Time { return time.Now().Add(DefaultInviteTTL + time.Hour) }
+	if _, err := svc.AcceptInvite(ctx, "x1", token, false); !errors.Is(err, ErrInviteInvalid) {
+		t.Errorf("expired invite err = %v, want ErrInviteInvalid", err)
+	}
+}
+
+func TestOrganization_AcceptWhileInAnotherOrg(t *testing.T) {
+	svc, _, _ := newTestOrg(t)
+	ctx := context.Background()
+	if _, err := svc.Create(ctx, "x1", "Other LLP"); err != nil {
+		t.Fatal(err)
+	}
+	_, token, _ := svc.Invite(ctx, "p1", "x1@other.com", "")
+	if _, err := svc.AcceptInvite(ctx, "x1", token, false); !errors.Is(err, ErrAlreadyInOrg) {
+		t.Errorf("err = %v, want ErrAlreadyInOrg", err)
+	}
+}
+
+func TestOrganization_LastPartnerGuard(t *testing.T) {
+	svc, _, _ := newTestOrg(t)
+	ctx := context.Background()
+	join(t, svc, "a1", "a1@firm.com", model.UserRoleAssociate)
+
+	if err := svc.SetMemberRole(ctx, "p1", "p1", model.UserRoleAssociate); !errors.Is(err, ErrLastPartner) {
+		t.Errorf("demote last partner err = %v, want ErrLastPartner", err)
+	}
+	if err := svc.RemoveMember(ctx, "p1", "p1"); !errors.Is(err, ErrLastPartner) {
+		t.Errorf("last partner leave err = %v, want ErrLastPartner", err)
+	}
+
+	if err := svc.SetMemberRole(ctx, "p1", "a1", model.UserRolePartner); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	if err := svc.RemoveMember(ctx, "p1", "p1"); err != nil {
+		t.Errorf("leave with another partner: %v", err)
+	}
+}
+
+func TestOrganization_RemoveMemberPermissions(t *testing.T) {
+	svc, _, _ := newTestOrg(t)
+	ctx := context.Background()
+	join(t, svc, "a1", "a1@firm.com", model.UserRoleAssociate)
+	join(t, svc, "a2", "a2@firm.com", model.UserRoleAuditor)
+
+	if err := svc.RemoveMember(ctx, "a1", "a2"); !errors.Is(err, ErrOrgForbidden) {
+		t.Errorf("associate removing other err = %v, want ErrOrgForbidden", err)
+	}
+	if err := svc.RemoveMember(ctx, "p1", "x1"); !errors.Is(err, ErrMemberNotFound) {
+		t.Errorf("removing non-member err = %v, want ErrMemberNotFound", err)
+	}
+	if err := svc.RemoveMember(ctx, "a1", "a1"); err != nil {
+		t.Errorf("self leave: %v", err)
+	}
+	if _, _, err := svc.Current(ctx, "a1"); !errors.Is(err, ErrNotOrgMember) {
+		t.Errorf("after leave err = %v, want ErrNotOrgMember", err)
+	}
+}
+
+func TestOrganization_PoolsUsage(t *testing.T) {
+	svc, _, org := newTestOrg(t)
+	join(t, svc, "a1", "a1@firm.com", model.UserRoleAssociate)
+
+	usage := NewUsageService(newStubUsageRepo())
+	usage.SetAccountResolver(svc.UsageAccount)
+	usage.SetTierResolver(func(context.Context, string) string { return "free" })
+	ctx := context.Background()
+
+	usage.IncrementUsage(ctx, "p1", MetricAegisQueries)
+	usage.IncrementUsage(ctx, "a1", MetricAegisQueries)
+	usage.IncrementUsage(ctx, "solo", MetricAegisQueries)
+
+	d, err := usage.Enforce(ctx, "a1", MetricAegisQueries, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Used != 2 {
+		t.Errorf("pooled used = %d, want 2 (org %s)", d.Used, org.ID)
+	}
+	if d, _ := usage.Enforce(ctx, "solo", MetricAegisQueries, 1); d.Used != 1 {
+		t.Errorf("personal used = %d, want 1", d.Used)
+	}
+}
@@ -0,0 +1,543 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// ErrInvalidPIIPolicy is returned for a policy naming an unknown info type
+// or role. Handlers map it to 400.
+var ErrInvalidPIIPolicy = errors.New("invalid PII redaction policy")
+
+// PIIPolicyRepository persists per-vault PII redaction policies.
+// Implemented by repository.PIIPolicyRepo.
+type PIIPolicyRepository interface {
+	// VaultPolicies returns the policies set on vaultIDs, keyed by vault ID.
+	// Vaults without a policy are absent.
+	VaultPolicies(ctx context.Context, vaultIDs []string) (map[string]model.PIIRedactionPolicy, error)
+	// UpsertPolicy stores p, replacing the vault's policy.
+	UpsertPolicy(ctx context.Context, p *model.PIIRedactionPolicy) error
+	// DeletePolicy deletes the vault's policy and reports whether it existed.
+	DeletePolicy(ctx context.Context, vaultID string) (bool, error)
+}
+
+// UserRoleLookup resolves a user's firm role. Implemented by repository.UserRepo.
+type UserRoleLookup interface {
+	GetUserRole(ctx context.Context, userID string) (string, error)
+}
+
+// PIIPolicyAuditLogger records policy changes.
+type PIIPolicyAuditLogger interface {
+	LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error
+}
+
+// PIIRedactionService decides which PII/PHI findings a caller sees redacted.
+// Findings are stored per chunk at ingest (see EmbedderService.SetPIIScanner);
+// chunks stored before that are scanned when retrieved. Each vault may set a
+// policy; documents in other vaults or in none use the default policy.
+type PIIRedactionService struct {
+	repo     PIIPolicyRepository
+	roles    UserRoleLookup
+	redactor *RedactorService
+	audit    PIIPolicyAuditLogger
+	defaults model.PIIRedactionPolicy
+	now      func() time.Time
+}
+
+// NewPIIRedactionService creates a PIIRedactionService. redactor scans
+// unscanned chunks and answers; defaults applies where no vault policy does.
+// audit may be nil.
+func NewPIIRedactionService(repo PIIPolicyRepository, roles UserRoleLookup, redactor *RedactorService, audit PIIPolicyAuditLogger, defaults model.PIIRedactionPolicy) *PIIRedactionService {
+	defaults.VaultID = ""
+	return &PIIRedactionService{
+		repo:     repo,
+		roles:    roles,
+		redactor: redactor,
+		audit:    audit,
+		defaults: defaults,
+		now:      time.Now,
+	}
+}
+
+// DefaultPolicy returns the policy for documents no vault policy covers.
+func (s *PIIRedactionService) DefaultPolicy() model.PIIRedactionPolicy {
+	return s.defaults
+}
+
+// VaultPolicy returns the policy that applies to a vault's documents and
+// where it came from.
+func (s *PIIRedactionService) VaultPolicy(ctx context.Context, vaultID string) (model.PIIRedactionPolicy, model.PIIPolicySource, error) {
+	policies, err := s.repo.VaultPolicies(ctx, []string{vaultID})
+	if err != nil {
+		return model.PIIRedactionPolicy{}, "", fmt.Errorf("service.PIIRedaction.VaultPolicy: %w", err)
+	}
+	if p, ok := policies[vaultID]; ok {
+		return p, model.PIIPolicyFromVault, nil
+	}
+	return s.defaults, model.PIIPolicyFromDefault, nil
+}
+
+// SetVaultPolicy sets the vault's policy. The caller must already be allowed
+// to manage the vault. Empty infoTypes turns redaction off for the vault.
+func (s *PIIRedactionService) SetVaultPolicy(ctx context.Context, actorID, vaultID string, infoTypes []string, exemptRoles []model.UserRole) (*model.PIIRedactionPolicy, error) {
+	types, err := ValidatePIIInfoTypes(infoTypes)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := validateExemptRoles(exemptRoles)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	p := &model.PIIRedactionPolicy{
+		VaultID:     vaultID,
+		InfoTypes:   types,
+		ExemptRoles: roles,
+		UpdatedBy:   actorID,
+		UpdatedAt:   &now,
+	}
+	if err := s.repo.UpsertPolicy(ctx, p); err != nil {
+		return nil, fmt.Errorf("service.PIIRedaction.SetVaultPolicy: %w", err)
+	}
+	s.log(ctx, model.AuditPIIPolicySet, actorID, vaultID, map[string]interface{}{
+		"infoTypes":   strings.Join(p.InfoTypes, ","),
+		"exemptRoles": joinRoles(p.ExemptRoles),
+	})
+	return p, nil
+}
+
+// DeleteVaultPolicy removes the vault's policy, so the default policy
+// applies to it again. It reports whether a policy existed.
+func (s *PIIRedactionService) DeleteVaultPolicy(ctx context.Context, actorID, vaultID string) (bool, error) {
+	deleted, err := s.repo.DeletePolicy(ctx, vaultID)
+	if err != nil {
+		return false, fmt.Errorf("service.PIIRedaction.DeleteVaultPolicy: %w", err)
+	}
+	if deleted {
+		s.log(ctx, model.AuditPIIPolicyDelete, actorID, vaultID, nil)
+	}
+	return deleted, nil
+}
+
+// ValidatePIIInfoTypes checks info types against the ones the pipeline scans
+// for and returns them sorted without duplicates.
+func ValidatePIIInfoTypes(infoTypes []string) ([]string, error) {
+	known := make(map[string]bool, len(defaultInfoTypes))
+	for _, t := range defaultInfoTypes {
+		known[t] = true
+	}
+	seen := make(map[string]bool, len(infoTypes))
+	out := make([]string, 0, len(infoTypes))
+	for _, t := range infoTypes {
+		t = strings.TrimSpace(t)
+		if !known[t] {
+			return nil, fmt.Errorf("%w: unknown info type %q", ErrInvalidPIIPolicy, t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// PIIDefaultPolicy builds the default policy from configured info types and
+// role names (PII_REDACT_TYPES / PII_REDACT_EXEMPT_ROLES).
+func PIIDefaultPolicy(infoTypes, exemptRoles []string) (model.PIIRedactionPolicy, error) {
+	types, err := ValidatePIIInfoTypes(infoTypes)
+	if err != nil {
+		return model.PIIRedactionPolicy{}, err
+	}
+	roles := make([]model.UserRole, len(exemptRoles))
+	for i, r := range exemptRoles {
+		roles[i] = model.UserRole(r)
+	}
+	if roles, err = validateExemptRoles(roles); err != nil {
+		return model.PIIRedactionPolicy{}, err
+	}
+	return model.PIIRedactionPolicy{InfoTypes: types, ExemptRoles: roles}, nil
+}
+
+func validateExemptRoles(roles []model.UserRole) ([]model.UserRole, error) {
+	seen := make(map[model.UserRole]bool, len(roles))
+	out := make([]model.UserRole, 0, len(roles))
+	for _, r := range roles {
+		if !model.ValidUserRole(r) {
+			return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidPIIPolicy, r)
+		}
+		if !seen[r] {
+			seen[r] = true
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+func joinRoles(roles []model.UserRole) string {
+	s := make([]string, len(roles))
+	for i, r := range roles {
+		s[i] = string(r)
+	}
+	return strings.Join(s, ",")
+}
+
+func (s *PIIRedactionService) log(ctx context.Context, action, actorID, vaultID string, details map[string]interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogWithDetails(ctx, action, actorID, vaultID, "vault", details); err != nil {
+		// Don't fail the change on audit error — log and continue
+		slog.Error("[PIIRedaction] audit log failed", "action", action, "user_id", actorID, "vault_id", vaultID, "error", err)
+	}
+}
+
+// Plan resolves the policies covering vaultIDs ("" for documents outside
+// any vault) for one caller. role is the caller's firm role from the
+// request context; when empty it is looked up, and only if a policy could
+// exempt it. A nil service returns a nil plan, which redacts nothing.
+func (s *PIIRedactionService) Plan(ctx context.Context, userID, role string, privileged bool, vaultIDs []string) (*PIIRedactionPlan, error) {
+	if s == nil {
+		return nil, nil
+	}
+	plan := &PIIRedactionPlan{
+		svc:        s,
+		privileged: privileged,
+		policies:   map[string]planPolicy{"": {policy: s.defaults, source: model.PIIPolicyFromDefault}},
+		values:     make(map[string]string),
+		redacted:   make(map[string]int),
+	}
+
+	var ids []string
+	for _, id := range vaultIDs {
+		if _, ok := plan.policies[id]; !ok && id != "" {
+			plan.policies[id] = planPolicy{policy: s.defaults, source: model.PIIPolicyFromDefault}
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		set, err := s.repo.VaultPolicies(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("service.PIIRedaction.Plan: %w", err)
+		}
+		for id, p := range set {
+			plan.policies[id] = planPolicy{policy: p, source: model.PIIPolicyFromVault}
+		}
+	}
+
+	// Resolve the role only when a policy could exempt it.
+	needRole := false
+	for _, pp := range plan.policies {
+		needRole = needRole || (len(pp.policy.InfoTypes) > 0 && len(pp.policy.ExemptRoles) > 0)
+	}
+	if !privileged && needRole {
+		if role == "" && s.roles != nil {
+			var err error
+			if role, err = s.roles.GetUserRole(ctx, userID); err != nil {
+				// Fail closed: without a role no exemption applies.
+				slog.Warn("[PIIRedaction] role lookup failed", "user_id", userID, "error", err)
+				role = ""
+			}
+		}
+		plan.role = model.UserRole(role)
+	}
+	return plan, nil
+}
+
+type planPolicy struct {
+	policy model.PIIRedactionPolicy
+	source model.PIIPolicySource
+}
+
+// applies reports whether the policy redacts anything for the caller.
+func (pp planPolicy) applies(privileged bool, role model.UserRole) bool {
+	if privileged || len(pp.policy.InfoTypes) == 0 {
+		return false
+	}
+	for _, r := range pp.policy.ExemptRoles {
+		if r == role {
+			return false
+		}
+	}
+	return true
+}
+
+// PIIRedactionPlan redacts one caller's view of retrieved chunks and the
+// answer generated from them. It remembers the values it redacted so the
+// answer can be cleaned of them too. All methods accept a nil plan and then
+// leave text unchanged.
+type PIIRedactionPlan struct {
+	svc        *PIIRedactionService
+	privileged bool
+	role       model.UserRole
+	policies   map[string]planPolicy // by vault ID; "" = outside any vault
+	values     map[string]string     // redacted text → marker
+	redacted   map[string]int        // findings redacted, by info type
+}
+
+// Active reports whether any covered policy redacts for this caller.
+func (p *PIIRedactionPlan) Active() bool {
+	if p == nil {
+		return false
+	}
+	for _, pp := range p.policies {
+		if pp.applies(p.privileged, p.role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprint encodes the redaction the plan applies: for each covered
+// vault, the info types redacted for this caller. Two plans with the same
+// fingerprint redact the same text identically, so an answer cached for one
+// may be served to the other. A nil plan returns "".
+func (p *PIIRedactionPlan) Fingerprint() string {
+	if p == nil {
+		return ""
+	}
+	ids := make([]string, 0, len(p.policies))
+	for id := range p.policies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var b strings.Builder
+	b.WriteString("pii1")
+	for _, id := range ids {
+		pp := p.policies[id]
+		b.WriteString(";" + id + "=")
+		if pp.applies(p.privileged, p.role) {
+			types := append([]string(nil), pp.policy.InfoTypes...)
+			sort.Strings(types)
+			b.WriteString(strings.Join(types, ","))
+		}
+	}
+	return b.String()
+}
+
+func (p *PIIRedactionPlan) policyFor(vaultID *string) planPolicy {
+	id := ""
+	if vaultID != nil {
+		id = *vaultID
+	}
+	if pp, ok := p.policies[id]; ok {
+		return pp
+	}
+	// A vault the plan was not built for gets the default policy.
+	return p.policies[""]
+}
+
+// RedactChunks returns copies of chunks with the findings their vault's
+// policy redacts replaced by [REDACTED-TYPE] markers.
+func (p *PIIRedactionPlan) RedactChunks(ctx context.Context, chunks []RankedChunk) ([]RankedChunk, error) {
+	if !p.Active() {
+		return chunks, nil
+	}
+	out := make([]RankedChunk, len(chunks))
+	for i, rc := range chunks {
+		c, err := p.redactChunk(ctx, rc.Document.VaultID, rc.Chunk)
+		if err != nil {
+			return nil, err
+		}
+		rc.Chunk = c
+		out[i] = rc
+	}
+	return out, nil
+}
+
+// RedactDocumentChunks is RedactChunks for chunks of one document.
+func (p *PIIRedactionPlan) RedactDocumentChunks(ctx context.Context, vaultID *string, chunks []model.DocumentChunk) ([]model.DocumentChunk, error) {
+	if !p.Active() {
+		return chunks, nil
+	}
+	out := make([]model.DocumentChunk, len(chunks))
+	for i, c := range chunks {
+		rc, err := p.redactChunk(ctx, vaultID, c)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = rc
+	}
+	return out, nil
+}
+
+func (p *PIIRedactionPlan) redactChunk(ctx context.Context, vaultID *string, c model.DocumentChunk) (model.DocumentChunk, error) {
+	pp := p.policyFor(vaultID)
+	if !pp.applies(p.privileged, p.role) {
+		return c, nil
+	}
+	if c.PIIFindings == nil {
+		findings, err := ScanPIIFindings(ctx, p.svc.redactor, c.Content)
+		if err != nil {
+			return c, fmt.Errorf("service.PIIRedaction: scan chunk %s: %w", c.ID, err)
+		}
+		c.PIIFindings = findings
+	}
+
+	types := make(map[string]bool, len(pp.policy.InfoTypes))
+	for _, t := range pp.policy.InfoTypes {
+		types[t] = true
+	}
+	var findings []Finding
+	for _, f := range c.PIIFindings {
+		if !types[f.InfoType] || f.Start < 0 || f.End > len(c.Content) || f.Start >= f.End {
+			continue
+		}
+		findings = append(findings, Finding{InfoType: f.InfoType, StartIndex: f.Start, EndIndex: f.End, Score: f.Score})
+		p.values[c.Content[f.Start:f.End]] = redactionMarker(f.InfoType)
+		p.redacted[f.InfoType]++
+	}
+	if len(findings) > 0 {
+		c.Content = p.svc.redactor.Redact(c.Content, findings)
+	}
+	return c, nil
+}
+
+// RedactAnswer removes PII from generated text: values redacted from the
+// chunks it was generated from, and anything the detector finds of the info
+// types the covered policies redact.
+func (p *PIIRedactionPlan) RedactAnswer(ctx context.Context, text string) (string, error) {
+	if !p.Active() || text == "" {
+		return text, nil
+	}
+	text = p.redactValues(text)
+
+	var types []string
+	seen := make(map[string]bool)
+	for _, pp := range p.policies {
+		if !pp.applies(p.privileged, p.role) {
+			continue
+		}
+		for _, t := range pp.policy.InfoTypes {
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+	}
+	result, err := p.svc.redactor.Scan(ctx, text)
+	if err != nil {
+		return "", fmt.Errorf("service.PIIRedaction.RedactAnswer: %w", err)
+	}
+	for _, f := range result.Findings {
+		if seen[f.InfoType] {
+			p.redacted[f.InfoType]++
+		}
+	}
+	return p.svc.redactor.RedactByType(text, result.Findings, types), nil
+}
+
+// redactValues replaces whole-word occurrences of redacted values, longest
+// first so a value inside another is not replaced first.
+func (p *PIIRedactionPlan) redactValues(text string) string {
+	values := make([]string, 0, len(p.values))
+	for v := range p.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	for _, v := range values {
+		text = replaceWord(text, v, p.values[v])
+	}
+	return text
+}
+
+// replaceWord replaces occurrences of old in s that are not part of a
+// longer word or number.
+func replaceWord(s, old, repl string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, old)
+		if i < 0 {
+			break
+		}
+		end := i + len(old)
+		if (i > 0 && isWordByte(s[i-1])) || (end < len(s) && isWordByte(s[end])) {
+			b.WriteString(s[:end])
+		} else {
+			b.WriteString(s[:i])
+			b.WriteString(repl)
+		}
+		s = s[end:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// PIIRedactionEvidence records how PII redaction applied to one answer, for
+// the chat "done" event.
+type PIIRedactionEvidence struct {
+	PrivilegedMode bool                `json:"privilegedMode"`
+	Role           string              `json:"role,omitempty"`
+	Policies       []PIIPolicyEvidence `json:"policies"`
+	Redacted       map[string]int      `json:"redacted"` // findings redacted, by info type
+}
+
+// PIIPolicyEvidence is one policy that covered the retrieved documents.
+// Applied is false when the caller was exempt from it.
+type PIIPolicyEvidence struct {
+	VaultID     string                `json:"vaultId,omitempty"`
+	Source      model.PIIPolicySource `json:"source"`
+	InfoTypes   []string              `json:"infoTypes"`
+	ExemptRoles []model.UserRole      `json:"exemptRoles"`
+	Applied     bool                  `json:"applied"`
+}
+
+// Evidence summarizes the plan and what it redacted so far. Policies are
+// ordered by vault ID, the default policy first. A nil plan returns nil.
+func (p *PIIRedactionPlan) Evidence() *PIIRedactionEvidence {
+	if p == nil {
+		return nil
+	}
+	ev := &PIIRedactionEvidence{
+		PrivilegedMode: p.privileged,
+		Role:           string(p.role),
+		Policies:       make([]PIIPolicyEvidence, 0, len(p.policies)),
+		Redacted:       make(map[string]int, len(p.redacted)),
+	}
+	for id, pp := range p.policies {
+		ev.Policies = append(ev.Policies, PIIPolicyEvidence{
+			VaultID:     id,
+			Source:      pp.source,
+			InfoTypes:   pp.policy.InfoTypes,
+			ExemptRoles: pp.policy.ExemptRoles,
+			Applied:     pp.applies(p.privileged, p.role),
+		})
+	}
+	sort.Slice(ev.Policies, func(i, j int) bool { return ev.Policies[i].VaultID < ev.Policies[j].VaultID })
+	for t, n := range p.redacted {
+		ev.Redacted[t] = n
+	}
+	return ev
+}
+
+// ScanPIIFindings scans text and returns its findings without the matched
+// text, for storing with a chunk. The result is non-nil, so a scanned chunk
+// without findings is told apart from an unscanned one.
+func ScanPIIFindings(ctx context.Context, scanner Redactor, text string) ([]model.PIIFinding, error) {
+	result, err := scanner.Scan(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	findings := make([]model.PIIFinding, 0, len(result.Findings))
+	for _, f := range result.Findings {
+		findings = append(findings, model.PIIFinding{InfoType: f.InfoType, Start: f.StartIndex, End: f.EndIndex, Score: f.Score})
+	}
+	return findings, nil
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// valueDLPClient implements DLPClient by finding fixed values in the text.
+type valueDLPClient struct {
+	values map[string]string // value → info type
+	calls  int
+}
+
+func (c *valueDLPClient) InspectContent(ctx context.Context, project, text string, infoTypes []string) ([]Finding, error) {
+	c.calls++
+	var findings []Finding
+	for v, infoType := range c.values {
+		for off := 0; ; {
+			i := strings.Index(text[off:], v)
+			if i < 0 {
+				break
+			}
+			start := off + i
+			findings = append(findings, Finding{InfoType: infoType, Content: v, StartIndex: start, EndIndex: start + len(v), Score: 0.9})
+			off = start + len(v)
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].StartIndex < findings[j].StartIndex })
+	return findings, nil
+}
+
+// memPIIPolicyRepo is an in-memory PIIPolicyRepository.
+type memPIIPolicyRepo struct {
+	policies map[string]model.PIIRedactionPolicy
+}
+
+func (m *memPIIPolicyRepo) VaultPolicies(ctx context.Context, vaultIDs []string) (map[string]model.PIIRedactionPolicy, error) {
+	out := make(map[string]model.PIIRedactionPolicy)
+	for _, id := range vaultIDs {
+		if p, ok := m.policies[id]; ok {
+			out[id] = p
+		}
+	}
+	return out, nil
+}
+
+func (m *memPIIPolicyRepo) UpsertPolicy(ctx context.Context, p *model.PIIRedactionPolicy) error {
+	m.policies[p.VaultID] = *p
+	return nil
+}
+
+func (m *memPIIPolicyRepo) DeletePolicy(ctx context.Context, vaultID string) (bool, error) {
+	_, ok := m.policies[vaultID]
+	delete(m.policies, vaultID)
+	return ok, nil
+}
+
+type staticRoles map[string]string
+
+func (r staticRoles) GetUserRole(ctx context.Context, userID string) (string, error) {
+	if role, ok := r[userID]; ok {
+		return role, nil
+	}
+	return "", errors.New("no rows in result set")
+}
+
+// countingRoles records how often the repository role lookup runs.
+type countingRoles struct {
+	staticRoles
+	calls int
+}
+
+func (r *countingRoles) GetUserRole(ctx context.Context, userID string) (string, error) {
+	r.calls++
+	return r.staticRoles.GetUserRole(ctx, userID)
+}
+
+type recordingPIIAudit struct {
+	actions []string
+}
+
+func (a *recordingPIIAudit) LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error {
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+const piiChunkText = "Client Maria Lopez, SSN 536-22-1847, email maria@example.com."
+
+func newTestPIIService(defaults model.PIIRedactionPolicy) (*PIIRedactionService, *memPIIPolicyRepo, *valueDLPClient, *recordingPIIAudit) {
+	dlp := &valueDLPClient{values: map[string]string{
+		"Maria Lopez":       "PERSON_NAME",
+		"536-22-1847":       "US_SOCIAL_SECURITY_NUMBER",
+		"maria@example.com": "EMAIL_ADDRESS",
+	}}
+	repo := &memPIIPolicyRepo{policies: map[string]model.PIIRedactionPolicy{
+		"vault-hr": {
+			VaultID:     "vault-hr",
+			InfoTypes:   []string{"US_SOCIAL_SECURITY_NUMBER"},
+			ExemptRoles: []model.UserRole{model.UserRolePartner},
+		},
+	}}
+	roles := staticRoles{"partner": "Partner", "assoc": "Associate"}
+	audit := &recordingPIIAudit{}
+	return NewPIIRedactionService(repo, roles, NewRedactorService(dlp, ""), audit, defaults), repo, dlp, audit
+}
+
+func piiRankedChunk(vaultID *string, findings []model.PIIFinding) RankedChunk {
+	return RankedChunk{
+		Chunk:    model.DocumentChunk{ID: "c1", Content: piiChunkText, PIIFindings: findings},
+		Document: model.Document{ID: "d1", VaultID: vaultID},
+	}
+}
+
+func TestPIIPlan_VaultPolicyRedactsStoredFindings(t *testing.T) {
+	svc, _, dlp, _ := newTestPIIService(model.PIIRedactionPolicy{})
+	ctx := context.Background()
+	stored, err := ScanPIIFindings(ctx, svc.redactor, piiChunkText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dlp.calls = 0
+
+	vault := "vault-hr"
+	plan, err := svc.Plan(ctx, "assoc", "", false, []string{vault})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Active() {
+		t.Fatal("plan inactive for an Associate under the vault policy")
+	}
+	chunks := []RankedChunk{piiRankedChunk(&vault, stored)}
+	got, err := plan.RedactChunks(ctx, chunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Client Maria Lopez, SSN [REDACTED-SSN], email maria@example.com."
+	if got[0].Chunk.Content != want {
+		t.Errorf("content = %q, want %q", got[0].Chunk.Content, want)
+	}
+	if chunks[0].Chunk.Content != piiChunkText {
+		t.Error("RedactChunks mutated the caller's chunks")
+	}
+	if dlp.calls != 0 {
+		t.Errorf("scanned %d times, want stored findings used", dlp.calls)
+	}
+
+	answer, err := plan.RedactAnswer(ctx, "Her SSN is 536-22-1847 [1].")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "Her SSN is [REDACTED-SSN] [1]." {
+		t.Errorf("answer = %q", answer)
+	}
+
+	ev := plan.Evidence()
+	if ev.Redacted["US_SOCIAL_SECURITY_NUMBER"] != 1 || len(ev.Redacted) != 1 {
+		t.Errorf("redacted = %v, want one SSN", ev.Redacted)
+	}
+	if len(ev.Policies) != 2 || ev.Policies[0].Source != model.PIIPolicyFromDefault ||
+		ev.Policies[1].VaultID != vault || ev.Policies[1].Source != model.PIIPolicyFromVault || !ev.Policies[1].Applied {
+		t.Errorf("policies = %+v", ev.Policies)
+	}
+}
+
+func TestPIIPlan_ExemptCallers(t *testing.T) {
+	svc, _, _, _ := newTestPIIService(model.PIIRedactionPolicy{})
+	ctx := context.Background()
+	vault := "vault-hr"
+
+	for _, tt := range []struct {
+		name       string
+		userID     string
+		privileged bool
+	}{
+		{"exempt role", "partner", false},
+		{"privileged mode", "assoc", true},
+	} {
+		plan, err := svc.Plan(ctx, tt.userID, "", tt.privileged, []string{vault})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan.Active() {
+			t.Errorf("%s: plan active, want exempt", tt.name)
+		}
+		got, _ := plan.RedactChunks(ctx, []RankedChunk{piiRankedChunk(&vault, nil)})
+		if got[0].Chunk.Content != piiChunkText {
+			t.Errorf("%s: content = %q, want unredacted", tt.name, got[0].Chunk.Content)
+		}
+		if ev := plan.Evidence(); ev.Policies[1].Applied {
+			t.Errorf("%s: evidence says the vault policy applied", tt.name)
+		}
+	}
+}
+
+func TestPIIPlan_ContextRoleSkipsLookup(t *testing.T) {
+	svc, _, _, _ := newTestPIIService(model.PIIRedactionPolicy{})
+	roles := &countingRoles{staticRoles: staticRoles{"partner": "Partner"}}
+	svc.roles = roles
+	ctx := context.Background()
+
+	plan, err := svc.Plan(ctx, "partner", "Partner", false, []string{"vault-hr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Active() {
+		t.Error("plan active for a Partner from the request context, want exempt")
+	}
+	if roles.calls != 0 {
+		t.Errorf("role looked up %d times, want the context role used", roles.calls)
+	}
+
+	if _, err := svc.Plan(ctx, "partner", "", false, []string{"vault-hr"}); err != nil {
+		t.Fatal(err)
+	}
+	if roles.calls != 1 {
+		t.Errorf("role looked up %d times without a context role, want 1", roles.calls)
+	}
+}
+
+func TestPIIPlan_UnknownRoleIsNotExempt(t *testing.T) {
+	svc, _, _, _ := newTestPIIService(model.PIIRedactionPolicy{})
+	plan, err := svc.Plan(context.Background(), "stranger", "", false, []string{"vault-hr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Active() {
+		t.Error("plan inactive after a failed role lookup, want redaction")
+	}
+}
+
+func TestPIIPlan_DefaultPolicyScansUnscannedChunks(t *testing.T) {
+	svc, _, dlp, _ := newTestPIIService(model.PIIRedactionPolicy{InfoTypes: []string{"EMAIL_ADDRESS", "PERSON_NAME"}})
+	ctx := context.Background()
+
+	plan, err := svc.Plan(ctx, "partner", "", false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := plan.RedactChunks(ctx, []RankedChunk{piiRankedChunk(nil, nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Client [REDACTED-NAME], SSN 536-22-1847, email [REDACTED-EMAIL]."
+	if got[0].Chunk.Content != want {
+		t.Errorf("content = %q, want %q", got[0].Chunk.Content, want)
+	}
+	if dlp.calls != 1 {
+		t.Errorf("scanned %d times, want 1 for the unscanned chunk", dlp.calls)
+	}
+
+	other := "vault-open" // no policy of its own
+	docs, err := plan.RedactDocumentChunks(ctx, &other, []model.DocumentChunk{{ID: "c2", Content: "Ask Maria Lopez."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docs[0].Content != "Ask [REDACTED-NAME]." {
+		t.Errorf("vault without policy: content = %q", docs[0].Content)
+	}
+}
+
+func TestPIIPlan_Fingerprint(t *testing.T) {
+	svc, repo, _, _ := newTestPIIService(model.PIIRedactionPolicy{})
+	ctx := context.Background()
+	fingerprint := func(userID, role string) string {
+		t.Helper()
+		plan, err := svc.Plan(ctx, userID, role, false, []string{"vault-hr"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return plan.Fingerprint()
+	}
+
+	partner, assoc := fingerprint("partner", "Partner"), fingerprint("assoc", "Associate")
+	if partner == assoc {
+		t.Errorf("Partner and Associate share fingerprint %q", partner)
+	}
+	if got := fingerprint("other", "Associate"); got != assoc {
+		t.Errorf("same role fingerprint = %q, want %q", got, assoc)
+	}
+
+	// A policy change changes the fingerprint of the callers it applies to.
+	p := repo.policies["vault-hr"]
+	p.InfoTypes = append(p.InfoTypes, "EMAIL_ADDRESS")
+	repo.policies["vault-hr"] = p
+	if got := fingerprint("assoc", "Associate"); got == assoc {
+		t.Error("fingerprint unchanged after the vault policy changed")
+	}
+
+	var nilPlan *PIIRedactionPlan
+	if nilPlan.Fingerprint() != "" {
+		t.Error("nil plan fingerprint should be empty")
+	}
+}
+
+func TestPIIPlan_NilIsInactive(t *testing.T) {
+	var svc *PIIRedactionService
+	plan, err := svc.Plan(context.Background(), "u", "", false, []string{"v"})
+	if err != nil || plan != nil {
+		t.Fatalf("Plan on nil service = %v, %v", plan, err)
+	}
+	if plan.Active() || plan.Evidence() != nil {
+		t.Error("nil plan should be inactive with no evidence")
+	}
+	answer, err := plan.RedactAnswer(context.Background(), "SSN 536-22-1847")
+	if err != nil || answer != "SSN 536-22-1847" {
+		t.Errorf("RedactAnswer = %q, %v", answer, err)
+	}
+}
+
+func TestSetVaultPolicy_Validation(t *testing.T) {
+	svc, repo, _, audit := newTestPIIService(model.PIIRedactionPolicy{})
+	ctx := context.Background()
+
+	if _, err := svc.SetVaultPolicy(ctx, "owner", "v1", []string{"SHOE_SIZE"}, nil); !errors.Is(err, ErrInvalidPIIPolicy) {
+		t.Errorf("unknown info type: err = %v, want ErrInvalidPIIPolicy", err)
+	}
+	if _, err := svc.SetVaultPolicy(ctx, "owner", "v1", nil, []model.UserRole{"Intern"}); !errors.Is(err, ErrInvalidPIIPolicy) {
+		t.Errorf("unknown role: err = %v, want ErrInvalidPIIPolicy", err)
+	}
+
+	p, err := svc.SetVaultPolicy(ctx, "owner", "v1",
+		[]string{"PHONE_NUMBER", "EMAIL_ADDRESS", "PHONE_NUMBER"},
+		[]model.UserRole{model.UserRolePartner, model.UserRoleAuditor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(p.InfoTypes, ",") != "EMAIL_ADDRESS,PHONE_NUMBER" || len(p.ExemptRoles) != 2 {
+		t.Errorf("policy = %+v, want sorted and deduplicated", p)
+	}
+	if repo.policies["v1"].UpdatedBy != "owner" {
+		t.Errorf("stored policy = %+v", repo.policies["v1"])
+	}
+
+	deleted, err := svc.DeleteVaultPolicy(ctx, "owner", "v1")
+	if err != nil || !deleted {
+		t.Fatalf("DeleteVaultPolicy = %v, %v", deleted, err)
+	}
+	if _, src, _ := svc.VaultPolicy(ctx, "v1"); src != model.PIIPolicyFromDefault {
+		t.Errorf("source after delete = %s, want default", src)
+	}
+	if strings.Join(audit.actions, ",") != model.AuditPIIPolicySet+","+model.AuditPIIPolicyDelete {
+		t.Errorf("audited %v", audit.actions)
+	}
+}
+
+func TestPIIDefaultPolicy(t *testing.T) {
+	p, err := PIIDefaultPolicy([]string{"US_SOCIAL_SECURITY_NUMBER"}, []string{"Partner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.InfoTypes) != 1 || len(p.ExemptRoles) != 1 || p.ExemptRoles[0] != model.UserRolePartner {
+		t.Errorf("policy = %+v", p)
+	}
+	if _, err := PIIDefaultPolicy(nil, []string{"partner"}); !errors.Is(err, ErrInvalidPIIPolicy) {
+		t.Errorf("lower-case role: err = %v, want ErrInvalidPIIPolicy", err)
+	}
+}
+
+func TestReplaceWord(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"SSN 536-22-1847.", "SSN [X]."},
+		{"ref 1536-22-1847", "ref 1536-22-1847"},
+		{"536-22-18470 and 536-22-1847", "536-22-18470 and [X]"},
+	}
+	for _, tt := range tests {
+		if got := replaceWord(tt.s, "536-22-1847", "[X]"); got != tt.want {
+			t.Errorf("replaceWord(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestScanPIIFindings_EmptyIsNotNil(t *testing.T) {
+	findings, err := ScanPIIFindings(context.Background(), NewRedactorService(&mockDLPClient{}, ""), "nothing here")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings == nil || len(findings) != 0 {
+		t.Errorf("findings = %#v, want empty non-nil", findings)
+	}
+}
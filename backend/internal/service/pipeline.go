@@ -42,8 +42,10 @@ type Chunk struct {
 	DocumentID     string
 	PageNumber     int
 	SectionTitle   string
-	ContextualText string            // EPIC-034: enrichment from Gemini
-	Entities       []EntityExtracted // EPIC-034: entities from Gemini
+	ContextualText string               // EPIC-034: enrichment from Gemini
+	Entities       []EntityExtracted    // EPIC-034: entities from Gemini
+	EmbeddingSpace model.EmbeddingSpace // set by EmbedderService; zero = unversioned
+	PIIFindings    []model.PIIFinding   // set by EmbedderService when it has a PII scanner
 }
 
 // Embedder abstracts vector embedding and storage.
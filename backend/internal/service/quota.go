@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Usage metric keys (usage_tracking.metric).
+const (
+	MetricAegisQueries    = "aegis_queries"
+	MetricDocumentsStored = "documents_stored" // live count, not a monthly counter
+	MetricVoiceMinutes    = "voice_minutes"
+	MetricAPICalls        = "api_calls"
+	// MetricVoiceSeconds is what transcription records; voice minute limits
+	// are checked against its total rounded up to whole minutes.
+	MetricVoiceSeconds = "voice_seconds"
+)
+
+// ErrQuotaExceeded is matched (errors.Is) by every *QuotaError.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaError reports a request refused because it would take a metric past
+// the tier limit plus any grace allowance.
+type QuotaError struct {
+	Metric    string `json:"metric"`
+	Tier      string `json:"tier"`
+	Used      int64  `json:"used"`
+	Limit     int64  `json:"limit"`
+	Requested int64  `json:"requested"`
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s quota exceeded: used %d of %d (%s tier)", e.Metric, e.Used, e.Limit, e.Tier)
+}
+
+// Is makes errors.Is(err, ErrQuotaExceeded) true for any QuotaError.
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// Code is the stable error code clients switch on. Query and token codes
+// predate the other metrics and are kept for existing clients.
+func (e *QuotaError) Code() string {
+	switch e.Metric {
+	case MetricAegisQueries:
+		return "monthly_limit_reached"
+	case MetricTokensUsed:
+		return "token_budget_exhausted"
+	}
+	return "quota_exceeded"
+}
+
+// Message is a user-facing explanation.
+func (e *QuotaError) Message() string {
+	return fmt.Sprintf("You've used %d of %d %s. Upgrade your plan to continue.", e.Used, e.Limit, metricLabel(e.Metric))
+}
+
+// metricLabel describes a metric's unit for user-facing messages.
+func metricLabel(metric string) string {
+	switch metric {
+	case MetricAegisQueries:
+		return "AEGIS queries this month"
+	case MetricTokensUsed:
+		return "tokens this month"
+	case MetricDocumentsStored:
+		return "stored documents"
+	case MetricVoiceMinutes:
+		return "voice minutes this month"
+	case MetricAPICalls:
+		return "API calls this month"
+	}
+	return metric
+}
+
+// QuotaErrorBody is the JSON body for a quota refusal, shared by HTTP
+// responses (402) and chat SSE error events.
+type QuotaErrorBody struct {
+	Success   bool   `json:"success"`
+	Error     string `json:"error"`
+	Message   string `json:"message"`
+	Metric    string `json:"metric"`
+	Tier      string `json:"tier"`
+	Used      int64  `json:"used"`
+	Limit     int64  `json:"limit"`
+	Requested int64  `json:"requested"`
+}
+
+// Body returns the structured error body for e.
+func (e *QuotaError) Body() QuotaErrorBody {
+	return QuotaErrorBody{
+		Error:     e.Code(),
+		Message:   e.Message(),
+		Metric:    e.Metric,
+		Tier:      e.Tier,
+		Used:      e.Used,
+		Limit:     e.Limit,
+		Requested: e.Requested,
+	}
+}
+
+// QuotaDecision is the outcome of a quota check that was allowed.
+type QuotaDecision struct {
+	Metric string
+	Tier   string
+	Used   int64
+	Limit  int64 // -1 = unlimited
+	// Grace is true when the request takes usage past Limit but stays within
+	// the grace allowance. Callers should surface a warning.
+	Grace bool
+}
+
+// Warning returns a short notice for responses served in grace, or "".
+func (d QuotaDecision) Warning() string {
+	if !d.Grace {
+		return ""
+	}
+	return fmt.Sprintf("%s over plan limit (%d of %d); grace allowance in use", d.Metric, d.Used, d.Limit)
+}
+
+// GracePolicy lets paid tiers exceed a limit by a percentage before requests
+// are refused, so a user is warned before being cut off mid-period.
+type GracePolicy struct {
+	// Percent of the limit allowed as overage (rounded up, min 1 when > 0).
+	Percent int
+	// ExcludedTiers get no grace.
+	ExcludedTiers map[string]bool
+}
+
+// DefaultGracePolicy allows 10% overage on every tier except free.
+func DefaultGracePolicy() GracePolicy {
+	return GracePolicy{Percent: 10, ExcludedTiers: map[string]bool{"free": true}}
+}
+
+func (g GracePolicy) allowance(tier string, limit int64) int64 {
+	if g.Percent <= 0 || limit <= 0 || g.ExcludedTiers[tier] {
+		return 0
+	}
+	return (limit*int64(g.Percent) + 99) / 100
+}
+
+// QuotaEnforcer checks a user's quota before work that consumes it.
+// Implemented by *UsageService.
+type QuotaEnforcer interface {
+	Enforce(ctx context.Context, userID, metric string, amount int64) (QuotaDecision, error)
+}
+
+// DocumentCounter returns the number of active (not deleted) documents a
+// user owns. Implemented by repository.DocumentRepo.
+type DocumentCounter interface {
+	CountActiveByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// SetGracePolicy replaces the over-quota grace policy.
+func (s *UsageService) SetGracePolicy(g GracePolicy) {
+	s.grace = g
+}
+
+// SetDocumentCounter makes documents_stored reflect live document counts.
+// Without it, documents_stored falls back to the usage_tracking counter.
+func (s *UsageService) SetDocumentCounter(c DocumentCounter) {
+	s.docCounter = c
+}
+
+// SetTierResolver sets the tier lookup used by Enforce. It should be the same
+// function handlers use (users.subscription_tier).
+func (s *UsageService) SetTierResolver(fn func(ctx context.Context, userID string) string) {
+	s.tierFunc = fn
+}
+
+// Enforce resolves userID's tier and checks that consuming amount of metric
+// stays within the limit plus grace. See CheckQuota.
+func (s *UsageService) Enforce(ctx context.Context, userID, metric string, amount int64) (QuotaDecision, error) {
+	tier := "free"
+	if s.tierFunc != nil {
+		tier = s.tierFunc(ctx, userID)
+	}
+	return s.CheckQuota(ctx, userID, tier, metric, amount)
+}
+
+// CheckQuota checks that consuming amount more of metric stays within tier's
+// limit plus the grace allowance. It returns a *QuotaError (errors.Is
+// ErrQuotaExceeded) when it would not; any other error is a metering failure,
+// which callers treat as allowed rather than blocking on infrastructure.
+func (s *UsageService) CheckQuota(ctx context.Context, userID, tier, metric string, amount int64) (QuotaDecision, error) {
+	if _, ok := TierLimitMap[tier]; !ok {
+		tier = "free"
+	}
+	limit, known := tierLimit(tier, metric)
+	d := QuotaDecision{Metric: metric, Tier: tier, Limit: limit}
+	if !known || limit == -1 {
+		d.Limit = -1
+		return d, nil
+	}
+
+	used, err := s.currentUsage(ctx, userID, metric)
+	if err != nil {
+		return d, fmt.Errorf("service.CheckQuota: %w", err)
+	}
+	d.Used = used
+
+	switch {
+	case used+amount <= limit:
+		return d, nil
+	case used+amount <= limit+s.grace.allowance(tier, limit):
+		d.Grace = true
+		slog.Warn("[Usage] over limit, within grace",
+			"user_id", userID, "metric", metric, "tier", tier, "used", used, "limit", limit)
+		return d, nil
+	}
+	return d, &QuotaError{Metric: metric, Tier: tier, Used: used, Limit: limit, Requested: amount}
+}
+
+// currentUsage returns the amount counted against metric's limit.
+func (s *UsageService) currentUsage(ctx context.Context, userID, metric string) (int64, error) {
+	account := s.account(ctx, userID)
+	switch metric {
+	case MetricDocumentsStored:
+		if s.docCounter != nil {
+			return s.docCounter.CountActiveByUser(ctx, userID)
+		}
+	case MetricVoiceMinutes:
+		minutes, err := s.repo.GetUsage(ctx, account, MetricVoiceMinutes)
+		if err != nil {
+			return 0, err
+		}
+		seconds, err := s.repo.GetUsage(ctx, account, MetricVoiceSeconds)
+		if err != nil {
+			return 0, err
+		}
+		return minutes + (seconds+59)/60, nil
+	}
+	return s.repo.GetUsage(ctx, account, metric)
+}
+
+// tierLimit returns the limit for metric in tier; known is false for
+// metrics that have no limit.
+func tierLimit(tier, metric string) (limit int64, known bool) {
+	limits, ok := TierLimitMap[tier]
+	if !ok {
+		limits = TierLimitMap["free"]
+	}
+	switch metric {
+	case MetricAegisQueries:
+		return limits.AegisQueries, true
+	case MetricDocumentsStored:
+		return limits.DocumentsStored, true
+	case MetricVoiceMinutes:
+		return limits.VoiceMinutes, true
+	case MetricAPICalls:
+		return limits.APICalls, true
+	case MetricTokensUsed:
+		return limits.TokenBudget, true
+	}
+	return 0, false
+}
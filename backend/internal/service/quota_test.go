@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubDocCounter int64
+
+func (c stubDocCounter) CountActiveByUser(context.Context, string) (int64, error) {
+	return int64(c), nil
+}
+
+func TestCheckQuota_GraceAllowance(t *testing.T) {
+	repo := newStubUsageRepo()
+	svc := NewUsageService(repo)
+	ctx := context.Background()
+
+	// starter: 100 queries, 10% grace = 110
+	repo.usageMap["u1:"+MetricAegisQueries] = 100
+	d, err := svc.CheckQuota(ctx, "u1", "starter", MetricAegisQueries, 1)
+	if err != nil {
+		t.Fatalf("query 101 should be allowed in grace: %v", err)
+	}
+	if !d.Grace || d.Warning() == "" {
+		t.Errorf("decision = %+v, want grace with a warning", d)
+	}
+
+	repo.usageMap["u1:"+MetricAegisQueries] = 110
+	_, err = svc.CheckQuota(ctx, "u1", "starter", MetricAegisQueries, 1)
+	var qe *QuotaError
+	if !errors.As(err, &qe) {
+		t.Fatalf("query 111 should be refused, got %v", err)
+	}
+	if qe.Used != 110 || qe.Limit != 100 || qe.Code() != "monthly_limit_reached" {
+		t.Errorf("quota error = %+v (code %q)", qe, qe.Code())
+	}
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Error("QuotaError should match ErrQuotaExceeded")
+	}
+}
+
+func TestCheckQuota_FreeTierHasNoGrace(t *testing.T) {
+	repo := newStubUsageRepo()
+	svc := NewUsageService(repo)
+
+	repo.usageMap["u1:"+MetricAegisQueries] = 25
+	_, err := svc.CheckQuota(context.Background(), "u1", "free", MetricAegisQueries, 1)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("free tier at limit should be refused, got %v", err)
+	}
+
+	svc.SetGracePolicy(GracePolicy{})
+	repo.usageMap["u2:"+MetricAegisQueries] = 100
+	if _, err := svc.CheckQuota(context.Background(), "u2", "starter", MetricAegisQueries, 1); err == nil {
+		t.Error("grace disabled: starter at limit should be refused")
+	}
+}
+
+func TestCheckQuota_VoiceSecondsRoundUp(t *testing.T) {
+	repo := newStubUsageRepo()
+	svc := NewUsageService(repo)
+	svc.SetGracePolicy(GracePolicy{})
+
+	// starter: 60 minutes. 59m01s rounds up to 60 minutes used.
+	repo.usageMap["u1:"+MetricVoiceSeconds] = 59*60 + 1
+	_, err := svc.CheckQuota(context.Background(), "u1", "starter", MetricVoiceMinutes, 1)
+	var qe *QuotaError
+	if !errors.As(err, &qe) {
+		t.Fatalf("expected refusal, got %v", err)
+	}
+	if qe.Used != 60 || qe.Code() != "quota_exceeded" {
+		t.Errorf("quota error = %+v", qe)
+	}
+
+	// Free has no voice minutes at all.
+	if _, err := svc.CheckQuota(context.Background(), "u2", "free", MetricVoiceMinutes, 1); err == nil {
+		t.Error("free tier should have no voice minutes")
+	}
+}
+
+func TestEnforce_DocumentsUseLiveCount(t *testing.T) {
+	repo := newStubUsageRepo()
+	svc := NewUsageService(repo)
+	svc.SetDocumentCounter(stubDocCounter(5))
+	svc.SetTierResolver(func(context.Context, string) string { return "free" })
+	repo.usageMap["u1:"+MetricDocumentsStored] = 0 // stale counter is ignored
+
+	_, err := svc.Enforce(context.Background(), "u1", MetricDocumentsStored, 1)
+	var qe *QuotaError
+	if !errors.As(err, &qe) {
+		t.Fatalf("6th document on free should be refused, got %v", err)
+	}
+	if qe.Used != 5 || qe.Limit != 5 || qe.Tier != "free" {
+		t.Errorf("quota error = %+v", qe)
+	}
+	if body := qe.Body(); body.Success || body.Error != "quota_exceeded" || body.Message == "" {
+		t.Errorf("body = %+v", body)
+	}
+
+	svc.SetTierResolver(func(context.Context, string) string { return "enterprise" })
+	d, err := svc.Enforce(context.Background(), "u1", MetricDocumentsStored, 1)
+	if err != nil || d.Limit != -1 {
+		t.Errorf("unlimited tier: decision = %+v, err = %v", d, err)
+	}
+}
@@ -115,17 +115,21 @@ func (s *RedactorService) Redact(text string, findings []Finding) string {
 		if f.StartIndex < 0 || f.EndIndex > len(result) || f.StartIndex >= f.EndIndex {
 			continue
 		}
-		label := infoTypeToRedactLabel[f.InfoType]
-		if label == "" {
-			label = "PII"
-		}
-		replacement := fmt.Sprintf("[REDACTED-%s]", label)
-		result = result[:f.StartIndex] + replacement + result[f.EndIndex:]
+		result = result[:f.StartIndex] + redactionMarker(f.InfoType) + result[f.EndIndex:]
 	}
 
 	return result
 }
 
+// redactionMarker returns the [REDACTED-TYPE] marker for an info type.
+func redactionMarker(infoType string) string {
+	label := infoTypeToRedactLabel[infoType]
+	if label == "" {
+		label = "PII"
+	}
+	return fmt.Sprintf("[REDACTED-%s]", label)
+}
+
 // RedactByType replaces only findings of specified types.
 func (s *RedactorService) RedactByType(text string, findings []Finding, types []string) string {
 	typeSet := make(map[string]bool, len(types))
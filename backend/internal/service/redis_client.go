@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -61,6 +62,40 @@ func (c *RedisClient) InvalidateQueryCache(ctx context.Context, tenantID string)
 	}
 }
 
+// CacheInvalidationChannel is the Redis pub/sub channel on which cache
+// invalidations are broadcast to every server instance.
+const CacheInvalidationChannel = "rc:invalidate"
+
+// CacheInvalidation is a cache invalidation broadcast. When DocumentIDs is set,
+// only entries built from those documents are dropped; otherwise every entry
+// belonging to UserID is.
+type CacheInvalidation struct {
+	ID          string   `json:"id"`
+	Origin      string   `json:"origin"` // publishing instance; it has already applied the invalidation
+	UserID      string   `json:"user_id"`
+	DocumentIDs []string `json:"document_ids,omitempty"`
+	// SharedCleared reports that the publisher already removed the shared
+	// Redis entries, so receivers only need to clear their in-memory caches.
+	SharedCleared bool `json:"shared_cleared"`
+}
+
+// PublishCacheInvalidation broadcasts an invalidation to every server instance.
+// The receiving instances also clear the shared Redis entries, since workers
+// do not own the server's cache layout.
+func (c *RedisClient) PublishCacheInvalidation(ctx context.Context, msg CacheInvalidation) error {
+	if msg.ID == "" {
+		msg.ID = uuid.NewString()
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("redis.PublishCacheInvalidation: %w", err)
+	}
+	if err := c.rdb.Publish(ctx, CacheInvalidationChannel, data).Err(); err != nil {
+		return fmt.Errorf("redis.PublishCacheInvalidation: %w", err)
+	}
+	return nil
+}
+
 // InvalidateDocCache deletes the document metadata cache entry.
 func (c *RedisClient) InvalidateDocCache(ctx context.Context, documentID string) {
 	c.rdb.Del(ctx, fmt.Sprintf("cache:doc:%s", documentID))
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+const (
+	// defaultReembedBatchSize is the number of chunks embedded per API call during re-embedding.
+	defaultReembedBatchSize = 100
+	// maxReembedCatchUpPasses bounds how many times cutover retries after finding
+	// chunks that were re-ingested while the job was running.
+	maxReembedCatchUpPasses = 5
+)
+
+// ErrReembedIncomplete is returned by ReembedStore.CutoverReembedJob when some
+// chunks still lack an up-to-date shadow vector.
+var ErrReembedIncomplete = errors.New("reembed job has chunks without shadow vectors")
+
+// ReembedChunk is a chunk whose content must be embedded in a new space.
+type ReembedChunk struct {
+	ID          string
+	Content     string
+	ContentHash string // embedding_input_hash of Content as stored: keyed per tenant when sealed
+}
+
+// EmbeddingSpaceStore abstracts lookup of the active embedding space.
+type EmbeddingSpaceStore interface {
+	ActiveSpace(ctx context.Context) (model.EmbeddingSpace, error)
+}
+
+// ReembedStore abstracts re-embed job persistence for testability.
+// Implemented by repository.EmbeddingSpaceRepo.
+type ReembedStore interface {
+	EmbeddingSpaceStore
+	CreateReembedJob(ctx context.Context, modelName string, dimensions int) (*model.ReembedJob, error)
+	GetReembedJob(ctx context.Context, id string) (*model.ReembedJob, error)
+	ResumeReembedJob(ctx context.Context, id string) error
+	// NextReembedBatch returns chunks with id > afterChunkID in id order.
+	NextReembedBatch(ctx context.Context, afterChunkID string, limit int) ([]ReembedChunk, error)
+	// SaveReembedBatch writes shadow vectors and advances the job's progress cursor.
+	SaveReembedBatch(ctx context.Context, jobID string, chunks []ReembedChunk, vectors [][]float32) error
+	// PendingReembedChunks returns chunks with no shadow vector, or one computed from stale content.
+	PendingReembedChunks(ctx context.Context, jobID string, limit int) ([]ReembedChunk, error)
+	// CutoverReembedJob atomically swaps shadow vectors into document_chunks and
+	// activates the target space. Returns ErrReembedIncomplete if chunks are pending.
+	CutoverReembedJob(ctx context.Context, jobID string) error
+	FailReembedJob(ctx context.Context, jobID, reason string) error
+	// StaleSpaceChunks returns chunks embedded outside space (written by an
+	// ingest path that had not yet observed the cutover).
+	StaleSpaceChunks(ctx context.Context, space model.EmbeddingSpace, limit int) ([]ReembedChunk, error)
+	// UpdateChunkEmbeddings overwrites chunk vectors in place, stamped with space.
+	UpdateChunkEmbeddings(ctx context.Context, space model.EmbeddingSpace, chunks []ReembedChunk, vectors [][]float32) error
+}
+
+// ReembedService re-embeds every chunk into a new embedding space without
+// interrupting retrieval. Vectors are written to a shadow table; the live
+// column is only replaced at cutover, inside a single transaction.
+type ReembedService struct {
+	store     ReembedStore
+	client    EmbeddingClient // client for the target model
+	embedder  *batchEmbedder
+	batchSize int
+}
+
+// NewReembedService creates a ReembedService. client must embed with the job's target model.
+func NewReembedService(store ReembedStore, client EmbeddingClient) *ReembedService {
+	return &ReembedService{
+		store:     store,
+		client:    client,
+		embedder:  newBatchEmbedder(DefaultEmbedOptions()),
+		batchSize: defaultReembedBatchSize,
+	}
+}
+
+// SetBatchSize overrides the number of chunks embedded per API call.
+func (s *ReembedService) SetBatchSize(n int) {
+	if n > 0 {
+		s.batchSize = n
+	}
+}
+
+// Start creates a new re-embed job targeting modelName.
+func (s *ReembedService) Start(ctx context.Context, modelName string, dimensions int) (*model.ReembedJob, error) {
+	job, err := s.store.CreateReembedJob(ctx, modelName, dimensions)
+	if err != nil {
+		return nil, fmt.Errorf("service.Reembed.Start: %w", err)
+	}
+	slog.Info("[REEMBED] job created",
+		"job_id", job.ID,
+		"target", job.Target.Key(),
+		"total_chunks", job.TotalChunks,
+	)
+	return job, nil
+}
+
+// Run processes a job to completion: a resumable pass over all chunks, then
+// catch-up passes for chunks changed mid-job, then the atomic cutover.
+// On failure the job is marked failed; shadow vectors are kept so a new
+// invocation of Run with the same job resumes from the last saved cursor.
+func (s *ReembedService) Run(ctx context.Context, jobID string) (*model.ReembedJob, error) {
+	job, err := s.store.GetReembedJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Reembed.Run: %w", err)
+	}
+	if job.Status == model.ReembedFailed {
+		if err := s.store.ResumeReembedJob(ctx, jobID); err != nil {
+			return nil, fmt.Errorf("service.Reembed.Run: %w", err)
+		}
+		slog.Info("[REEMBED] resuming failed job", "job_id", jobID, "cursor", job.LastChunkID)
+		job.Status = model.ReembedRunning
+	}
+	if job.Status != model.ReembedRunning {
+		return nil, fmt.Errorf("service.Reembed.Run: job %s is %s", jobID, job.Status)
+	}
+
+	if err := s.run(ctx, job); err != nil {
+		if ferr := s.store.FailReembedJob(context.WithoutCancel(ctx), jobID, err.Error()); ferr != nil {
+			slog.Error("[REEMBED] mark failed", "job_id", jobID, "error", ferr)
+		}
+		return nil, fmt.Errorf("service.Reembed.Run: %w", err)
+	}
+
+	job, err = s.store.GetReembedJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Reembed.Run: %w", err)
+	}
+	return job, nil
+}
+
+func (s *ReembedService) run(ctx context.Context, job *model.ReembedJob) error {
+	cursor := job.LastChunkID
+	for {
+		batch, err := s.store.NextReembedBatch(ctx, cursor, s.batchSize)
+		if err != nil {
+			return fmt.Errorf("next batch: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		if err := s.embedBatch(ctx, job, batch); err != nil {
+			return err
+		}
+		cursor = batch[len(batch)-1].ID
+		slog.Info("[REEMBED] batch saved", "job_id", job.ID, "cursor", cursor, "batch", len(batch))
+	}
+
+	for pass := 0; pass < maxReembedCatchUpPasses; pass++ {
+		if err := s.catchUp(ctx, job); err != nil {
+			return err
+		}
+		err := s.store.CutoverReembedJob(ctx, job.ID)
+		if err == nil {
+			slog.Info("[REEMBED] cutover complete", "job_id", job.ID, "target", job.Target.Key())
+			return nil
+		}
+		if !errors.Is(err, ErrReembedIncomplete) {
+			return fmt.Errorf("cutover: %w", err)
+		}
+		slog.Info("[REEMBED] chunks changed during cutover, catching up", "job_id", job.ID, "pass", pass+1)
+	}
+	return fmt.Errorf("cutover: %w after %d catch-up passes", ErrReembedIncomplete, maxReembedCatchUpPasses)
+}
+
+// catchUp embeds chunks that were inserted or re-ingested after the main pass read them.
+func (s *ReembedService) catchUp(ctx context.Context, job *model.ReembedJob) error {
+	for {
+		pending, err := s.store.PendingReembedChunks(ctx, job.ID, s.batchSize)
+		if err != nil {
+			return fmt.Errorf("pending chunks: %w", err)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if err := s.embedBatch(ctx, job, pending); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *ReembedService) embedBatch(ctx context.Context, job *model.ReembedJob, batch []ReembedChunk) error {
+	vectors, err := s.embedder.embed(ctx, s.client, job.Target, reembedTexts(batch))
+	if err != nil {
+		return fmt.Errorf("embed: %w", err)
+	}
+	if err := s.store.SaveReembedBatch(ctx, job.ID, batch, vectors); err != nil {
+		return fmt.Errorf("save batch: %w", err)
+	}
+	return nil
+}
+
+// Sweep re-embeds chunks that were stored outside the active space after a
+// cutover, by ingest workers that had not yet observed the switch. Run it once
+// every process has had time to pick up the new space (see EmbeddingSpaceWatcher).
+// Returns the number of chunks repaired.
+func (s *ReembedService) Sweep(ctx context.Context) (int, error) {
+	space, err := s.store.ActiveSpace(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("service.Reembed.Sweep: %w", err)
+	}
+
+	repaired := 0
+	for {
+		stale, err := s.store.StaleSpaceChunks(ctx, space, s.batchSize)
+		if err != nil {
+			return repaired, fmt.Errorf("service.Reembed.Sweep: %w", err)
+		}
+		if len(stale) == 0 {
+			return repaired, nil
+		}
+		vectors, err := s.embedder.embed(ctx, s.client, space, reembedTexts(stale))
+		if err != nil {
+			return repaired, fmt.Errorf("service.Reembed.Sweep: embed: %w", err)
+		}
+		if err := s.store.UpdateChunkEmbeddings(ctx, space, stale, vectors); err != nil {
+			return repaired, fmt.Errorf("service.Reembed.Sweep: %w", err)
+		}
+		repaired += len(stale)
+	}
+}
+
+func reembedTexts(chunks []ReembedChunk) []string {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Content
+	}
+	return texts
+}
+
+// EmbeddingSpaceWatcher polls the active embedding space and notifies when it
+// changes, so long-running processes switch models right after a cutover.
+type EmbeddingSpaceWatcher struct {
+	store    EmbeddingSpaceStore
+	interval time.Duration
+	onChange func(model.EmbeddingSpace) error
+	current  model.EmbeddingSpace
+}
+
+// NewEmbeddingSpaceWatcher creates a watcher. current is the space the process
+// started with; onChange is called with each newly activated space. If onChange
+// fails, the change is retried on the next poll.
+func NewEmbeddingSpaceWatcher(store EmbeddingSpaceStore, current model.EmbeddingSpace, interval time.Duration, onChange func(model.EmbeddingSpace) error) *EmbeddingSpaceWatcher {
+	return &EmbeddingSpaceWatcher{
+		store:    store,
+		interval: interval,
+		onChange: onChange,
+		current:  current,
+	}
+}
+
+// Check polls once and fires onChange if the active space differs from the current one.
+func (w *EmbeddingSpaceWatcher) Check(ctx context.Context) error {
+	space, err := w.store.ActiveSpace(ctx)
+	if err != nil {
+		return fmt.Errorf("service.EmbeddingSpaceWatcher.Check: %w", err)
+	}
+	if space.IsZero() || space == w.current {
+		return nil
+	}
+	slog.Info("[EMBED-SPACE] active space changed", "from", w.current.Key(), "to", space.Key())
+	if err := w.onChange(space); err != nil {
+		return fmt.Errorf("service.EmbeddingSpaceWatcher.Check: switch to %s: %w", space.Key(), err)
+	}
+	w.current = space
+	return nil
+}
+
+// Run polls until ctx is cancelled.
+func (w *EmbeddingSpaceWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Check(ctx); err != nil {
+				slog.Warn("[EMBED-SPACE] poll failed", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// memReembedStore is an in-memory ReembedStore for testing.
+type memReembedStore struct {
+	active    model.EmbeddingSpace
+	job       *model.ReembedJob
+	chunks    map[string]string // id → content
+	chunkSpc  map[string]model.EmbeddingSpace
+	shadow    map[string]string // id → content the shadow vector was computed from
+	cutovers  int
+	failCount int
+	// onCutover runs before the completeness check, simulating concurrent ingest.
+	onCutover func(s *memReembedStore)
+}
+
+func newMemReembedStore(n int) *memReembedStore {
+	s := &memReembedStore{
+		active:   model.EmbeddingSpace{Model: "text-embedding-004", Version: 1, Dimensions: 768},
+		chunks:   make(map[string]string),
+		chunkSpc: make(map[string]model.EmbeddingSpace),
+		shadow:   make(map[string]string),
+	}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("c%03d", i)
+		s.chunks[id] = "content " + id
+		s.chunkSpc[id] = s.active
+	}
+	return s
+}
+
+func (s *memReembedStore) sortedIDs() []string {
+	ids := make([]string, 0, len(s.chunks))
+	for id := range s.chunks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *memReembedStore) ActiveSpace(ctx context.Context) (model.EmbeddingSpace, error) {
+	return s.active, nil
+}
+
+func (s *memReembedStore) CreateReembedJob(ctx context.Context, modelName string, dimensions int) (*model.ReembedJob, error) {
+	s.job = &model.ReembedJob{
+		ID:          "job-1",
+		Target:      model.EmbeddingSpace{Model: modelName, Version: s.active.Version + 1, Dimensions: dimensions},
+		Status:      model.ReembedRunning,
+		TotalChunks: len(s.chunks),
+	}
+	return s.job, nil
+}
+
+func (s *memReembedStore) GetReembedJob(ctx context.Context, id string) (*model.ReembedJob, error) {
+	j := *s.job
+	return &j, nil
+}
+
+func (s *memReembedStore) ResumeReembedJob(ctx context.Context, id string) error {
+	s.job.Status = model.ReembedRunning
+	return nil
+}
+
+func (s *memReembedStore) NextReembedBatch(ctx context.Context, after string, limit int) ([]ReembedChunk, error) {
+	var out []ReembedChunk
+	for _, id := range s.sortedIDs() {
+		if id > after && len(out) < limit {
+			out = append(out, ReembedChunk{ID: id, Content: s.chunks[id], ContentHash: s.chunks[id]})
+		}
+	}
+	return out, nil
+}
+
+func (s *memReembedStore) SaveReembedBatch(ctx context.Context, jobID string, chunks []ReembedChunk, vectors [][]float32) error {
+	for _, c := range chunks {
+		s.shadow[c.ID] = c.ContentHash
+		if c.ID > s.job.LastChunkID {
+			s.job.LastChunkID = c.ID
+		}
+	}
+	s.job.ProcessedChunks = len(s.shadow)
+	return nil
+}
+
+func (s *memReembedStore) pending() []ReembedChunk {
+	var out []ReembedChunk
+	for _, id := range s.sortedIDs() {
+		if h, ok := s.shadow[id]; !ok || h != s.chunks[id] {
+			out = append(out, ReembedChunk{ID: id, Content: s.chunks[id], ContentHash: s.chunks[id]})
+		}
+	}
+	return out
+}
+
+func (s *memReembedStore) PendingReembedChunks(ctx context.Context, jobID string, limit int) ([]ReembedChunk, error) {
+	p := s.pending()
+	if len(p) > limit {
+		p = p[:limit]
+	}
+	return p, nil
+}
+
+func (s *memReembedStore) CutoverReembedJob(ctx context.Context, jobID string) error {
+	s.cutovers++
+	if s.onCutover != nil {
+		s.onCutover(s)
+	}
+	if len(s.pending()) > 0 {
+		return ErrReembedIncomplete
+	}
+	for id := range s.shadow {
+		s.chunkSpc[id] = s.job.Target
+	}
+	s.active = s.job.Target
+	s.shadow = make(map[string]string)
+	s.job.Status = model.ReembedCompleted
+	return nil
+}
+
+func (s *memReembedStore) FailReembedJob(ctx context.Context, jobID, reason string) error {
+	s.failCount++
+	s.job.Status = model.ReembedFailed
+	return nil
+}
+
+func (s *memReembedStore) StaleSpaceChunks(ctx context.Context, space model.EmbeddingSpace, limit int) ([]ReembedChunk, error) {
+	var out []ReembedChunk
+	for _, id := range s.sortedIDs() {
+		if s.chunkSpc[id] != space && len(out) < limit {
+			out = append(out, ReembedChunk{ID: id, Content: s.chunks[id], ContentHash: s.chunks[id]})
+		}
+	}
+	return out, nil
+}
+
+func (s *memReembedStore) UpdateChunkEmbeddings(ctx context.Context, space model.EmbeddingSpace, chunks []ReembedChunk, vectors [][]float32) error {
+	for _, c := range chunks {
+		s.chunkSpc[c.ID] = space
+	}
+	return nil
+}
+
+func TestReembed_RunCutsOverAllChunks(t *testing.T) {
+	store := newMemReembedStore(25)
+	svc := NewReembedService(store, &mockEmbeddingClient{})
+	svc.SetBatchSize(10)
+
+	job, err := svc.Start(context.Background(), "text-embedding-005", 768)
+	if err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	done, err := svc.Run(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if done.Status != model.ReembedCompleted {
+		t.Errorf("status = %s, want completed", done.Status)
+	}
+	if store.active != job.Target {
+		t.Errorf("active space = %+v, want %+v", store.active, job.Target)
+	}
+	for id, sp := range store.chunkSpc {
+		if sp != job.Target {
+			t.Errorf("chunk %s left in space %s", id, sp.Key())
+		}
+	}
+}
+
+func TestReembed_CatchesUpChunksChangedDuringCutover(t *testing.T) {
+	store := newMemReembedStore(5)
+	changed := false
+	store.onCutover = func(s *memReembedStore) {
+		if !changed {
+			s.chunks["c002"] = "re-ingested content"
+			s.chunks["c900"] = "new chunk"
+			s.chunkSpc["c900"] = s.active
+			changed = true
+		}
+	}
+	svc := NewReembedService(store, &mockEmbeddingClient{})
+
+	job, _ := svc.Start(context.Background(), "text-embedding-005", 768)
+	if _, err := svc.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if store.cutovers != 2 {
+		t.Errorf("cutovers = %d, want 2 (one incomplete, one after catch-up)", store.cutovers)
+	}
+	if store.chunkSpc["c900"] != job.Target {
+		t.Error("chunk added mid-job was not re-embedded")
+	}
+}
+
+func TestReembed_FailureMarksJobAndResumes(t *testing.T) {
+	store := newMemReembedStore(5)
+	client := &mockEmbeddingClient{err: fmt.Errorf("quota exceeded")}
+	svc := NewReembedService(store, client)
+
+	job, _ := svc.Start(context.Background(), "text-embedding-005", 768)
+	if _, err := svc.Run(context.Background(), job.ID); err == nil {
+		t.Fatal("expected error")
+	}
+	if store.failCount != 1 || store.job.Status != model.ReembedFailed {
+		t.Fatalf("job not marked failed: status=%s", store.job.Status)
+	}
+
+	client.err = nil
+	done, err := svc.Run(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("resume Run() error: %v", err)
+	}
+	if done.Status != model.ReembedCompleted {
+		t.Errorf("status = %s, want completed", done.Status)
+	}
+}
+
+func TestReembed_RejectsDimensionMismatch(t *testing.T) {
+	store := newMemReembedStore(3)
+	svc := NewReembedService(store, &mockEmbeddingClient{}) // mock returns 768-dim vectors
+
+	job, _ := svc.Start(context.Background(), "big-model", 1024)
+	if _, err := svc.Run(context.Background(), job.ID); err == nil {
+		t.Fatal("expected dimension mismatch error")
+	}
+	if store.active.Version != 1 {
+		t.Error("active space must not change when re-embedding fails")
+	}
+}
+
+func TestReembed_SweepRepairsStaleChunks(t *testing.T) {
+	store := newMemReembedStore(3)
+	store.active = model.EmbeddingSpace{Model: "text-embedding-005", Version: 2, Dimensions: 768}
+	store.chunkSpc["c001"] = store.active
+
+	svc := NewReembedService(store, &mockEmbeddingClient{})
+	n, err := svc.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Sweep() error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("repaired = %d, want 2", n)
+	}
+	for id, sp := range store.chunkSpc {
+		if sp != store.active {
+			t.Errorf("chunk %s still in %s", id, sp.Key())
+		}
+	}
+}
+
+func TestEmbeddingSpaceWatcher_FiresOnChange(t *testing.T) {
+	store := newMemReembedStore(0)
+	var got []model.EmbeddingSpace
+	var failNext bool
+	w := NewEmbeddingSpaceWatcher(store, store.active, 0, func(s model.EmbeddingSpace) error {
+		if failNext {
+			failNext = false
+			return fmt.Errorf("adapter init failed")
+		}
+		got = append(got, s)
+		return nil
+	})
+
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("unexpected change notification: %+v", got)
+	}
+
+	store.active = model.EmbeddingSpace{Model: "text-embedding-005", Version: 2, Dimensions: 768}
+	failNext = true
+	if err := w.Check(context.Background()); err == nil {
+		t.Fatal("expected error when switch fails")
+	}
+	w.Check(context.Background()) // retried
+	w.Check(context.Background())
+	if len(got) != 1 || got[0] != store.active {
+		t.Errorf("notifications = %+v, want exactly one for %s", got, store.active.Key())
+	}
+}
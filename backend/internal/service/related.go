@@ -12,7 +12,8 @@ type RelatedDocument struct {
 	Similarity float64        `json:"similarity"`
 }
 
-// RelatedDocSearcher finds documents similar to a given document.
+// RelatedDocSearcher finds documents similar to a given document. When
+// excludePrivileged is true, other users' privileged documents are skipped.
 type RelatedDocSearcher interface {
-	FindRelatedDocuments(ctx context.Context, documentID string, userID string, limit int) ([]RelatedDocument, error)
+	FindRelatedDocuments(ctx context.Context, documentID string, userID string, limit int, excludePrivileged bool) ([]RelatedDocument, error)
 }
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// Retention errors. Handlers map them to HTTP statuses.
+var (
+	ErrInvalidRetentionPolicy  = errors.New("invalid retention policy")
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+)
+
+const (
+	// maxRetentionDays caps a retention period at 100 years.
+	maxRetentionDays = 36500
+	// DefaultPurgeBatch caps the documents one purge run hard-deletes, so a
+	// long backlog is worked off over several runs.
+	DefaultPurgeBatch = 500
+	// maxPurgePreview caps the documents a dry-run report lists.
+	maxPurgePreview = 1000
+)
+
+// RetentionRepository persists retention policies and carries out the
+// database side of a purge. Implemented by repository.RetentionRepo.
+type RetentionRepository interface {
+	// ListPolicies returns the organization's policies, vaults first.
+	ListPolicies(ctx context.Context, orgID string) ([]model.RetentionPolicy, error)
+	// GetPolicy returns a policy, or nil if it does not exist.
+	GetPolicy(ctx context.Context, id string) (*model.RetentionPolicy, error)
+	// UpsertPolicy stores p, replacing the organization's policy for the
+	// same vault or document type, and sets p's ID and timestamps to the
+	// stored row's.
+	UpsertPolicy(ctx context.Context, p *model.RetentionPolicy) error
+	// DeletePolicy deletes a policy and reports whether it existed.
+	DeletePolicy(ctx context.Context, id string) (bool, error)
+	// VaultOrg returns the organization that owns the vault, or "" if it
+	// does not exist.
+	VaultOrg(ctx context.Context, vaultID string) (string, error)
+	// ExpiredDocuments returns up to limit soft-deleted documents whose
+	// retention period ended by asOf, oldest first, with the period that
+	// applies to each. orgID "" covers every organization. With excludeHeld,
+	// documents under an active legal hold are left out before the limit.
+	ExpiredDocuments(ctx context.Context, orgID string, asOf time.Time, defaultDays, limit int, excludeHeld bool) ([]model.PurgeCandidate, error)
+	// PurgeDocument marks a soft-deleted document HardDeleted and removes
+	// its text, chunks, embeddings and the cortex entries and insights
+	// derived from it, in one transaction. It reports false if the document
+	// is no longer soft-deleted, and returns ErrUnderLegalHold if a legal
+	// hold refused the purge. The storage location is kept for Cleanup.
+	PurgeDocument(ctx context.Context, id string, at time.Time) (bool, error)
+	// PendingCleanup returns up to limit purged documents whose storage
+	// object or graph nodes have not been removed yet. Only ID, UserID,
+	// StoragePath, StorageURI and GraphCleaned are set.
+	PendingCleanup(ctx context.Context, limit int) ([]model.PurgeCandidate, error)
+	// ClearStorage forgets a purged document's storage location once the
+	// object is gone.
+	ClearStorage(ctx context.Context, id string) error
+	// MarkGraphCleaned records that a purged document was removed from the
+	// knowledge graph.
+	MarkGraphCleaned(ctx context.Context, id string, at time.Time) error
+}
+
+// LegalHoldLookup resolves the active legal holds covering documents.
+// Implemented by repository.LegalHoldRepo.
+type LegalHoldLookup interface {
+	DocumentHolds(ctx context.Context, docIDs []string) (map[string][]model.LegalHold, error)
+}
+
+// ObjectDeleter removes stored objects. Deleting a missing object succeeds.
+// Implemented by gcpclient.StorageAdapter.
+type ObjectDeleter interface {
+	Delete(ctx context.Context, bucket, object string) error
+}
+
+// GraphPurger removes a document and what only it contributed from the
+// knowledge graph. Implemented by *Neo4jClient.
+type GraphPurger interface {
+	DeleteDocument(ctx context.Context, tenantID, documentID string) error
+}
+
+// DocumentCacheInvalidator drops cached results built from documents.
+// Implemented by cache.Invalidator.
+type DocumentCacheInvalidator interface {
+	InvalidateDocuments(ctx context.Context, userID string, docIDs ...string)
+}
+
+// RetentionAuditLogger records policy changes, dry runs and purge tombstones.
+type RetentionAuditLogger interface {
+	LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error
+}
+
+// RetentionPolicyRequest describes a retention policy to set. Exactly one
+// of VaultID and DocumentType is set.
+type RetentionPolicyRequest struct {
+	VaultID       string
+	DocumentType  string
+	RetentionDays int
+}
+
+// RetentionService manages retention policies (Partner only) and runs the
+// purge job, which hard-deletes soft-deleted documents once their retention
+// period ends: the database row is scrubbed first, then the storage object,
+// graph nodes and cached results are removed. Documents under an active
+// legal hold are skipped until it is released.
+//
+// Storage, Graph and Cache are optional; without Storage, purged objects
+// stay pending cleanup.
+type RetentionService struct {
+	repo        RetentionRepository
+	members     OrgMembership
+	holds       LegalHoldLookup
+	audit       RetentionAuditLogger
+	defaultDays int
+
+	Storage   ObjectDeleter
+	Bucket    string // for documents stored by path rather than gs:// URI
+	Graph     GraphPurger
+	Cache     DocumentCacheInvalidator
+	BatchSize int
+	now       func() time.Time
+}
+
+// NewRetentionService creates a RetentionService that keeps documents no
+// policy covers for defaultDays after deletion. holds and audit may be nil.
+func NewRetentionService(repo RetentionRepository, members OrgMembership, holds LegalHoldLookup, audit RetentionAuditLogger, defaultDays int) *RetentionService {
+	return &RetentionService{
+		repo:        repo,
+		members:     members,
+		holds:       holds,
+		audit:       audit,
+		defaultDays: defaultDays,
+		BatchSize:   DefaultPurgeBatch,
+		now:         time.Now,
+	}
+}
+
+// DefaultDays returns the retention period of documents no policy covers.
+func (s *RetentionService) DefaultDays() int {
+	return s.defaultDays
+}
+
+// ListPolicies lists the policies of the caller's organization. Partner only.
+func (s *RetentionService) ListPolicies(ctx context.Context, actorID string) ([]model.RetentionPolicy, error) {
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := s.repo.ListPolicies(ctx, m.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Retention.ListPolicies: %w", err)
+	}
+	return policies, nil
+}
+
+// SetPolicy sets the retention period for a vault or a document type of
+// the caller's organization, replacing any existing one. Partner only.
+func (s *RetentionService) SetPolicy(ctx context.Context, actorID string, req RetentionPolicyRequest) (*model.RetentionPolicy, error) {
+	req.VaultID = strings.TrimSpace(req.VaultID)
+	req.DocumentType = strings.ToLower(strings.TrimSpace(req.DocumentType))
+	switch {
+	case (req.VaultID == "") == (req.DocumentType == ""):
+		return nil, fmt.Errorf("%w: set exactly one of vaultId and documentType", ErrInvalidRetentionPolicy)
+	case len(req.DocumentType) > 100:
+		return nil, fmt.Errorf("%w: documentType must be at most 100 characters", ErrInvalidRetentionPolicy)
+	case req.RetentionDays < 0 || req.RetentionDays > maxRetentionDays:
+		return nil, fmt.Errorf("%w: retentionDays must be 0-%d", ErrInvalidRetentionPolicy, maxRetentionDays)
+	}
+
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	p := &model.RetentionPolicy{
+		ID:            uuid.New().String(),
+		OrgID:         m.OrgID,
+		RetentionDays: req.RetentionDays,
+		CreatedBy:     actorID,
+		CreatedAt:     s.now().UTC(),
+	}
+	p.UpdatedAt = p.CreatedAt
+	resourceID := req.DocumentType
+	if req.VaultID != "" {
+		orgID, err := s.repo.VaultOrg(ctx, req.VaultID)
+		if err != nil {
+			return nil, fmt.Errorf("service.Retention.SetPolicy: %w", err)
+		}
+		if orgID != m.OrgID {
+			return nil, fmt.Errorf("%w: vault %s not found", ErrInvalidRetentionPolicy, req.VaultID)
+		}
+		p.VaultID, resourceID = &req.VaultID, req.VaultID
+	} else {
+		p.DocumentType = &req.DocumentType
+	}
+
+	if err := s.repo.UpsertPolicy(ctx, p); err != nil {
+		return nil, fmt.Errorf("service.Retention.SetPolicy: %w", err)
+	}
+	s.log(ctx, model.AuditRetentionPolicySet, actorID, p.ID, "retention_policy", map[string]interface{}{
+		"scope":         policyScope(p),
+		"scopeId":       resourceID,
+		"retentionDays": p.RetentionDays,
+	})
+	return p, nil
+}
+
+// DeletePolicy removes a policy, so its documents fall back to the next
+// policy that applies. Partner only.
+func (s *RetentionService) DeletePolicy(ctx context.Context, actorID, policyID string) error {
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	p, err := s.repo.GetPolicy(ctx, policyID)
+	if err != nil {
+		return fmt.Errorf("service.Retention.DeletePolicy: %w", err)
+	}
+	if p == nil || p.OrgID != m.OrgID {
+		return ErrRetentionPolicyNotFound
+	}
+	deleted, err := s.repo.DeletePolicy(ctx, p.ID)
+	if err != nil {
+		return fmt.Errorf("service.Retention.DeletePolicy: %w", err)
+	}
+	if !deleted {
+		return ErrRetentionPolicyNotFound
+	}
+	s.log(ctx, model.AuditRetentionPolicyDelete, actorID, p.ID, "retention_policy", map[string]interface{}{
+		"scope":         policyScope(p),
+		"retentionDays": p.RetentionDays,
+	})
+	return nil
+}
+
+// Preview reports what a purge run at asOf (now if zero) would do to the
+// caller's organization, without changing anything. Partner only.
+func (s *RetentionService) Preview(ctx context.Context, actorID string, asOf time.Time) (*model.PurgeReport, error) {
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if asOf.IsZero() {
+		asOf = s.now()
+	}
+	report, err := s.plan(ctx, m.OrgID, asOf.UTC(), maxPurgePreview, false)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = true
+	report.Truncated = len(report.Documents) == maxPurgePreview
+	return report, nil
+}
+
+// Run purges expired documents every interval until ctx is cancelled.
+func (s *RetentionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if report, err := s.PurgeOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("[Retention] purge failed", "error", err)
+		} else if report != nil && (report.Purged > 0 || report.Failed > 0) {
+			slog.Info("[Retention] purge run", "purged", report.Purged, "held", report.Held,
+				"failed", report.Failed, "bytes", report.TotalBytes)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce finishes the cleanup earlier runs left pending, then purges up
+// to BatchSize expired documents across all organizations. A document that
+// fails is left for the next run.
+func (s *RetentionService) PurgeOnce(ctx context.Context) (*model.PurgeReport, error) {
+	pending, err := s.repo.PendingCleanup(ctx, s.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("service.PurgeOnce: pending: %w", err)
+	}
+	for i := range pending {
+		s.cleanup(ctx, &pending[i])
+	}
+
+	now := s.now().UTC()
+	report, err := s.plan(ctx, "", now, s.BatchSize, true)
+	if err != nil {
+		return nil, err
+	}
+	for i := range report.Documents {
+		c := &report.Documents[i]
+		if c.Held() {
+			continue
+		}
+		purged, err := s.repo.PurgeDocument(ctx, c.ID, now)
+		switch {
+		case errors.Is(err, ErrUnderLegalHold):
+			// Held since it was listed; the trigger refused the purge.
+			report.Held++
+			report.TotalBytes -= int64(c.SizeBytes)
+			continue
+		case err != nil:
+			slog.Error("[Retention] purge failed", "document_id", c.ID, "error", err)
+			report.Failed++
+			report.TotalBytes -= int64(c.SizeBytes)
+			continue
+		case !purged:
+			// Recovered or purged by another instance since it was listed.
+			report.TotalBytes -= int64(c.SizeBytes)
+			continue
+		}
+		report.Purged++
+		s.log(ctx, model.AuditDocumentPurge, c.UserID, c.ID, "document", map[string]interface{}{
+			"originalName":    c.OriginalName,
+			"checksum":        c.Checksum,
+			"sizeBytes":       c.SizeBytes,
+			"chunkCount":      c.ChunkCount,
+			"deletedAt":       c.DeletedAt,
+			"retentionDays":   c.RetentionDays,
+			"retentionSource": c.RetentionSource,
+			"purgedAt":        now,
+			"actor":           "retention",
+		})
+		s.cleanup(ctx, c)
+	}
+	return report, nil
+}
+
+// plan lists expired documents and marks those an active legal hold keeps.
+// A purge run excludes held documents up front so they cannot crowd the
+// purgeable ones out of its batch; a dry run lists them.
+func (s *RetentionService) plan(ctx context.Context, orgID string, asOf time.Time, limit int, excludeHeld bool) (*model.PurgeReport, error) {
+	docs, err := s.repo.ExpiredDocuments(ctx, orgID, asOf, s.defaultDays, limit, excludeHeld)
+	if err != nil {
+		return nil, fmt.Errorf("service.Retention.plan: %w", err)
+	}
+	report := &model.PurgeReport{AsOf: asOf, DefaultRetentionDays: s.defaultDays, Documents: docs}
+	if report.Documents == nil {
+		report.Documents = []model.PurgeCandidate{}
+	}
+	if len(docs) > 0 && s.holds != nil {
+		ids := make([]string, len(docs))
+		for i := range docs {
+			ids[i] = docs[i].ID
+		}
+		held, err := s.holds.DocumentHolds(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("service.Retention.plan: holds: %w", err)
+		}
+		for i := range docs {
+			for _, h := range held[docs[i].ID] {
+				docs[i].HoldIDs = append(docs[i].HoldIDs, h.ID)
+				docs[i].MatterIDs = append(docs[i].MatterIDs, h.MatterID)
+			}
+		}
+	}
+	for i := range docs {
+		if docs[i].Held() {
+			report.Held++
+		} else {
+			report.TotalBytes += int64(docs[i].SizeBytes)
+		}
+	}
+	return report, nil
+}
+
+// cleanup removes what a purged document left outside Postgres. Graph and
+// storage cleanup are recorded separately, each only once it succeeded, so
+// a failure of either is retried by the next run without holding up the
+// other.
+func (s *RetentionService) cleanup(ctx context.Context, c *model.PurgeCandidate) {
+	if !c.GraphCleaned {
+		var err error
+		if s.Graph != nil {
+			err = s.Graph.DeleteDocument(ctx, c.UserID, c.ID)
+		}
+		if err != nil {
+			slog.Error("[Retention] graph cleanup failed", "document_id", c.ID, "error", err)
+		} else if err := s.repo.MarkGraphCleaned(ctx, c.ID, s.now().UTC()); err != nil {
+			slog.Error("[Retention] mark graph cleaned failed", "document_id", c.ID, "error", err)
+		}
+	}
+	if s.Cache != nil {
+		s.Cache.InvalidateDocuments(ctx, c.UserID, c.ID)
+	}
+	if c.StoragePath == nil && c.StorageURI == nil {
+		return
+	}
+	if bucket, object := storageObject(s.Bucket, c.StoragePath, c.StorageURI); object != "" {
+		if s.Storage == nil || bucket == "" {
+			return
+		}
+		if err := s.Storage.Delete(ctx, bucket, object); err != nil {
+			slog.Error("[Retention] storage cleanup failed", "document_id", c.ID, "error", err)
+			return
+		}
+	}
+	if err := s.repo.ClearStorage(ctx, c.ID); err != nil {
+		slog.Error("[Retention] clear storage failed", "document_id", c.ID, "error", err)
+	}
+}
+
+// storageObject resolves a document's stored object, preferring its gs://
+// URI over a path in the default bucket.
+func storageObject(defaultBucket string, path, uri *string) (bucket, object string) {
+	if uri != nil {
+		if rest, ok := strings.CutPrefix(*uri, "gs://"); ok {
+			if b, o, ok := strings.Cut(rest, "/"); ok && b != "" && o != "" {
+				return b, o
+			}
+		}
+	}
+	if path != nil && *path != "" {
+		return defaultBucket, *path
+	}
+	return "", ""
+}
+
+func policyScope(p *model.RetentionPolicy) string {
+	if p.VaultID != nil {
+		return "vault"
+	}
+	return "document_type"
+}
+
+func (s *RetentionService) partner(ctx context.Context, userID string) (*model.OrgMember, error) {
+	m, err := s.members.Membership(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Retention.membership: %w", err)
+	}
+	if m == nil {
+		return nil, ErrNotOrgMember
+	}
+	if m.Role != model.UserRolePartner {
+		return nil, ErrOrgForbidden
+	}
+	return m, nil
+}
+
+func (s *RetentionService) log(ctx context.Context, action, actorID, resourceID, resourceType string, details map[string]interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogWithDetails(ctx, action, actorID, resourceID, resourceType, details); err != nil {
+		// Don't fail the change on audit error — log and continue
+		slog.Error("[Retention] audit log failed", "action", action, "user_id", actorID, "resource_id", resourceID, "error", err)
+	}
+}
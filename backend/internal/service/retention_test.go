@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// memRetentionRepo implements RetentionRepository over soft-deleted
+// documents. Expiry is precomputed in each candidate's PurgeAfter.
+type memRetentionRepo struct {
+	policies map[string]*model.RetentionPolicy
+	vaults   map[string]string // vault → org
+	docs     []model.PurgeCandidate
+	purged   map[string]bool
+	cleared  map[string]bool
+	graphed  map[string]bool // purged documents removed from the graph
+	refuse   map[string]bool // documents the legal hold trigger refuses
+	held     fakeHolds       // documents under a legal hold
+}
+
+func newMemRetentionRepo(docs ...model.PurgeCandidate) *memRetentionRepo {
+	return &memRetentionRepo{
+		policies: map[string]*model.RetentionPolicy{},
+		vaults:   map[string]string{"vault-1": "org-a", "vault-b": "org-b"},
+		docs:     docs,
+		purged:   map[string]bool{},
+		cleared:  map[string]bool{},
+		graphed:  map[string]bool{},
+		refuse:   map[string]bool{},
+	}
+}
+
+func (m *memRetentionRepo) ListPolicies(_ context.Context, orgID string) ([]model.RetentionPolicy, error) {
+	var out []model.RetentionPolicy
+	for _, p := range m.policies {
+		if p.OrgID == orgID {
+			out = append(out, *p)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRetentionRepo) GetPolicy(_ context.Context, id string) (*model.RetentionPolicy, error) {
+	if p, ok := m.policies[id]; ok {
+		c := *p
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (m *memRetentionRepo) UpsertPolicy(_ context.Context, p *model.RetentionPolicy) error {
+	for _, existing := range m.policies {
+		if existing.OrgID == p.OrgID && policyScope(existing) == policyScope(p) &&
+			(p.VaultID != nil && *existing.VaultID == *p.VaultID || p.DocumentType != nil && *existing.DocumentType == *p.DocumentType) {
+			existing.RetentionDays, existing.UpdatedAt = p.RetentionDays, p.UpdatedAt
+			*p = *existing
+			return nil
+		}
+	}
+	c := *p
+	m.policies[p.ID] = &c
+	return nil
+}
+
+func (m *memRetentionRepo) DeletePolicy(_ context.Context, id string) (bool, error) {
+	_, ok := m.policies[id]
+	delete(m.policies, id)
+	return ok, nil
+}
+
+func (m *memRetentionRepo) VaultOrg(_ context.Context, vaultID string) (string, error) {
+	return m.vaults[vaultID], nil
+}
+
+func (m *memRetentionRepo) ExpiredDocuments(_ context.Context, orgID string, asOf time.Time, _, limit int, excludeHeld bool) ([]model.PurgeCandidate, error) {
+	var out []model.PurgeCandidate
+	for _, d := range m.docs {
+		if excludeHeld && m.held[d.ID] {
+			continue
+		}
+		if !m.purged[d.ID] && (orgID == "" || d.OrgID == orgID) && !d.PurgeAfter.After(asOf) && len(out) < limit {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRetentionRepo) PurgeDocument(_ context.Context, id string, _ time.Time) (bool, error) {
+	if m.refuse[id] {
+		return false, ErrUnderLegalHold
+	}
+	if m.purged[id] {
+		return false, nil
+	}
+	m.purged[id] = true
+	return true, nil
+}
+
+func (m *memRetentionRepo) PendingCleanup(_ context.Context, limit int) ([]model.PurgeCandidate, error) {
+	var out []model.PurgeCandidate
+	for _, d := range m.docs {
+		if !m.purged[d.ID] || len(out) == limit {
+			continue
+		}
+		c := model.PurgeCandidate{ID: d.ID, UserID: d.UserID, GraphCleaned: m.graphed[d.ID]}
+		if !m.cleared[d.ID] {
+			c.StoragePath, c.StorageURI = d.StoragePath, d.StorageURI
+		}
+		if c.StoragePath != nil || c.StorageURI != nil || !c.GraphCleaned {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRetentionRepo) ClearStorage(_ context.Context, id string) error {
+	m.cleared[id] = true
+	return nil
+}
+
+func (m *memRetentionRepo) MarkGraphCleaned(_ context.Context, id string, _ time.Time) error {
+	m.graphed[id] = true
+	return nil
+}
+
+// fakeHolds holds the listed documents.
+type fakeHolds map[string]bool
+
+func (f fakeHolds) DocumentHolds(_ context.Context, ids []string) (map[string][]model.LegalHold, error) {
+	out := map[string][]model.LegalHold{}
+	for _, id := range ids {
+		if f[id] {
+			out[id] = []model.LegalHold{{ID: "hold-1", MatterID: "M-1024"}}
+		}
+	}
+	return out, nil
+}
+
+// recordingPurgers records the cleanup outside Postgres; storage and the
+// graph fail while failStorage and failGraph are set.
+type recordingPurgers struct {
+	deleted     []string
+	graph       []string
+	invalidated []string
+	failStorage bool
+	failGraph   bool
+}
+
+func (r *recordingPurgers) Delete(_ context.Context, bucket, object string) error {
+	if r.failStorage {
+		return errors.New("storage unavailable")
+	}
+	r.deleted = append(r.deleted, bucket+"/"+object)
+	return nil
+}
+
+func (r *recordingPurgers) DeleteDocument(_ context.Context, _, documentID string) error {
+	if r.failGraph {
+		return errors.New("graph unavailable")
+	}
+	r.graph = append(r.graph, documentID)
+	return nil
+}
+
+func (r *recordingPurgers) InvalidateDocuments(_ context.Context, _ string, docIDs ...string) {
+	r.invalidated = append(r.invalidated, docIDs...)
+}
+
+var retentionNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func purgeCandidate(id, org string, purgeAfter time.Time, size int) model.PurgeCandidate {
+	path := "uploads/u/" + id + "/file.pdf"
+	return model.PurgeCandidate{ID: id, UserID: "u-" + org, OrgID: org, SizeBytes: size,
+		StoragePath: &path, PurgeAfter: purgeAfter, RetentionSource: model.RetentionFromDefault}
+}
+
+// newRetentionFixture returns a service for org-a with partner "p" and
+// associate "a", and org-b with partner "x".
+func newRetentionFixture(holds fakeHolds, docs ...model.PurgeCandidate) (*RetentionService, *memRetentionRepo, *recordingPurgers, *recordingAudit) {
+	orgs := newMemOrgRepo()
+	orgs.members["p"] = &model.OrgMember{OrgID: "org-a", UserID: "p", Role: model.UserRolePartner}
+	orgs.members["a"] = &model.OrgMember{OrgID: "org-a", UserID: "a", Role: model.UserRoleAssociate}
+	orgs.members["x"] = &model.OrgMember{OrgID: "org-b", UserID: "x", Role: model.UserRolePartner}
+	repo := newMemRetentionRepo(docs...)
+	repo.held = holds
+	purgers := &recordingPurgers{}
+	audit := &recordingAudit{}
+	svc := NewRetentionService(repo, orgs, holds, audit, 30)
+	svc.Storage, svc.Graph, svc.Cache, svc.Bucket = purgers, purgers, purgers, "docs"
+	svc.now = func() time.Time { return retentionNow }
+	return svc, repo, purgers, audit
+}
+
+func TestRetention_PurgeSkipsHeldAndCleansUp(t *testing.T) {
+	uri := "gs://archive/old/doc-uri.pdf"
+	byURI := purgeCandidate("doc-uri", "org-b", retentionNow.Add(-time.Hour), 50)
+	byURI.StorageURI = &uri
+	svc, repo, purgers, audit := newRetentionFixture(fakeHolds{"doc-held": true},
+		purgeCandidate("doc-1", "org-a", retentionNow.Add(-time.Hour), 100),
+		purgeCandidate("doc-held", "org-a", retentionNow.Add(-time.Hour), 200),
+		purgeCandidate("doc-later", "org-a", retentionNow.Add(time.Hour), 400),
+		byURI,
+	)
+
+	report, err := svc.PurgeOnce(context.Background())
+	if err != nil {
+		t.Fatalf("PurgeOnce: %v", err)
+	}
+	// Held documents are excluded from the batch, not listed and skipped.
+	if report.Purged != 2 || report.Held != 0 || report.Failed != 0 || report.TotalBytes != 150 {
+		t.Errorf("report = purged %d held %d failed %d bytes %d", report.Purged, report.Held, report.Failed, report.TotalBytes)
+	}
+	if repo.purged["doc-held"] || repo.purged["doc-later"] {
+		t.Errorf("purged = %v, want held and unexpired documents kept", repo.purged)
+	}
+	want := "docs/uploads/u/doc-1/file.pdf,archive/old/doc-uri.pdf"
+	if got := strings.Join(purgers.deleted, ","); got != want {
+		t.Errorf("storage deletes = %s, want %s", got, want)
+	}
+	if len(purgers.graph) != 2 || len(purgers.invalidated) != 2 || !repo.cleared["doc-1"] || !repo.cleared["doc-uri"] {
+		t.Errorf("graph %v, cache %v, cleared %v", purgers.graph, purgers.invalidated, repo.cleared)
+	}
+	if got := strings.Join(audit.actions, ","); got != model.AuditDocumentPurge+","+model.AuditDocumentPurge {
+		t.Errorf("audit = %s, want a tombstone per purged document", got)
+	}
+}
+
+func TestRetention_StorageFailureIsRetried(t *testing.T) {
+	svc, repo, purgers, _ := newRetentionFixture(nil, purgeCandidate("doc-1", "org-a", retentionNow.Add(-time.Hour), 100))
+	purgers.failStorage = true
+
+	if _, err := svc.PurgeOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.purged["doc-1"] || repo.cleared["doc-1"] {
+		t.Fatalf("purged %v cleared %v, want purged with cleanup pending", repo.purged, repo.cleared)
+	}
+
+	purgers.failStorage = false
+	report, err := svc.PurgeOnce(context.Background())
+	if err != nil || report.Purged != 0 {
+		t.Fatalf("second run = %+v, %v", report, err)
+	}
+	if !repo.cleared["doc-1"] || len(purgers.deleted) != 1 {
+		t.Errorf("pending cleanup not retried: cleared %v, deleted %v", repo.cleared, purgers.deleted)
+	}
+}
+
+func TestRetention_HeldBacklogDoesNotStarvePurge(t *testing.T) {
+	old := retentionNow.Add(-48 * time.Hour)
+	svc, repo, _, _ := newRetentionFixture(fakeHolds{"held-1": true, "held-2": true, "held-3": true},
+		purgeCandidate("held-1", "org-a", old, 10),
+		purgeCandidate("held-2", "org-a", old, 10),
+		purgeCandidate("held-3", "org-a", old, 10),
+		purgeCandidate("doc-1", "org-a", retentionNow.Add(-time.Hour), 100),
+	)
+	svc.BatchSize = 2
+
+	report, err := svc.PurgeOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Purged != 1 || !repo.purged["doc-1"] {
+		t.Errorf("purged %d (%v), want doc-1 purged behind more held documents than the batch size", report.Purged, repo.purged)
+	}
+}
+
+func TestRetention_GraphFailureIsRetriedWithoutStorage(t *testing.T) {
+	doc := purgeCandidate("doc-1", "org-a", retentionNow.Add(-time.Hour), 100)
+	doc.StoragePath = nil // text-only document: no storage object
+	svc, repo, purgers, _ := newRetentionFixture(nil, doc)
+	purgers.failGraph = true
+
+	if _, err := svc.PurgeOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.purged["doc-1"] || repo.graphed["doc-1"] {
+		t.Fatalf("purged %v graphed %v, want purged with graph cleanup pending", repo.purged, repo.graphed)
+	}
+
+	purgers.failGraph = false
+	if _, err := svc.PurgeOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.graphed["doc-1"] || len(purgers.graph) != 1 {
+		t.Errorf("graph cleanup not retried: graphed %v, graph %v", repo.graphed, purgers.graph)
+	}
+	if pending, _ := repo.PendingCleanup(context.Background(), 10); len(pending) != 0 {
+		t.Errorf("pending = %+v, want nothing left to clean up", pending)
+	}
+}
+
+func TestRetention_TriggerRefusalCountsAsHeld(t *testing.T) {
+	svc, repo, _, audit := newRetentionFixture(nil, purgeCandidate("doc-1", "org-a", retentionNow.Add(-time.Hour), 100))
+	repo.refuse["doc-1"] = true
+
+	report, err := svc.PurgeOnce(context.Background())
+	if err != nil || report.Held != 1 || report.Purged != 0 || report.TotalBytes != 0 {
+		t.Fatalf("PurgeOnce = %+v, %v", report, err)
+	}
+	if len(audit.actions) != 0 {
+		t.Errorf("audit = %v, want no tombstone", audit.actions)
+	}
+}
+
+func TestRetention_PreviewIsScopedAndChangesNothing(t *testing.T) {
+	svc, repo, purgers, _ := newRetentionFixture(fakeHolds{"doc-held": true},
+		purgeCandidate("doc-1", "org-a", retentionNow.Add(-time.Hour), 100),
+		purgeCandidate("doc-held", "org-a", retentionNow.Add(-time.Hour), 200),
+		purgeCandidate("doc-later", "org-a", retentionNow.Add(48*time.Hour), 400),
+		purgeCandidate("doc-b", "org-b", retentionNow.Add(-time.Hour), 800),
+	)
+	ctx := context.Background()
+
+	report, err := svc.Preview(ctx, "p", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || len(report.Documents) != 2 || report.Held != 1 || report.TotalBytes != 100 {
+		t.Errorf("preview = %+v", report)
+	}
+	for _, d := range report.Documents {
+		if d.ID == "doc-held" && (!d.Held() || d.MatterIDs[0] != "M-1024") {
+			t.Errorf("held document = %+v", d)
+		}
+	}
+
+	later, err := svc.Preview(ctx, "p", retentionNow.Add(72*time.Hour))
+	if err != nil || len(later.Documents) != 3 {
+		t.Errorf("preview in 3 days lists %d documents, %v", len(later.Documents), err)
+	}
+	if len(repo.purged) != 0 || len(purgers.deleted) != 0 {
+		t.Error("dry run purged documents")
+	}
+	if _, err := svc.Preview(ctx, "a", time.Time{}); !errors.Is(err, ErrOrgForbidden) {
+		t.Errorf("associate preview: %v, want ErrOrgForbidden", err)
+	}
+}
+
+func TestRetention_PolicyRules(t *testing.T) {
+	svc, _, _, audit := newRetentionFixture(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		actor string
+		req   RetentionPolicyRequest
+		want  error
+	}{
+		{"associate", "a", RetentionPolicyRequest{VaultID: "vault-1", RetentionDays: 7}, ErrOrgForbidden},
+		{"personal user", "solo", RetentionPolicyRequest{VaultID: "vault-1", RetentionDays: 7}, ErrNotOrgMember},
+		{"no scope", "p", RetentionPolicyRequest{RetentionDays: 7}, ErrInvalidRetentionPolicy},
+		{"both scopes", "p", RetentionPolicyRequest{VaultID: "vault-1", DocumentType: "memo", RetentionDays: 7}, ErrInvalidRetentionPolicy},
+		{"negative", "p", RetentionPolicyRequest{DocumentType: "memo", RetentionDays: -1}, ErrInvalidRetentionPolicy},
+		{"other org's vault", "p", RetentionPolicyRequest{VaultID: "vault-b", RetentionDays: 7}, ErrInvalidRetentionPolicy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.SetPolicy(ctx, tt.actor, tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("SetPolicy = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	first, err := svc.SetPolicy(ctx, "p", RetentionPolicyRequest{DocumentType: " Contract ", RetentionDays: 365})
+	if err != nil || *first.DocumentType != "contract" {
+		t.Fatalf("SetPolicy = %+v, %v", first, err)
+	}
+	second, err := svc.SetPolicy(ctx, "p", RetentionPolicyRequest{DocumentType: "contract", RetentionDays: 90})
+	if err != nil || second.ID != first.ID || second.RetentionDays != 90 {
+		t.Errorf("replacing policy = %+v, %v, want the same policy updated", second, err)
+	}
+	if err := svc.DeletePolicy(ctx, "x", first.ID); !errors.Is(err, ErrRetentionPolicyNotFound) {
+		t.Errorf("other org's Partner delete: %v, want ErrRetentionPolicyNotFound", err)
+	}
+	if err := svc.DeletePolicy(ctx, "p", first.ID); err != nil {
+		t.Errorf("DeletePolicy: %v", err)
+	}
+	want := []string{model.AuditRetentionPolicySet, model.AuditRetentionPolicySet, model.AuditRetentionPolicyDelete}
+	if strings.Join(audit.actions, ",") != strings.Join(want, ",") {
+		t.Errorf("audit = %v, want %v", audit.actions, want)
+	}
+}
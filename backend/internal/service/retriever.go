@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/connexus-ai/ragbox-backend/internal/model"
@@ -39,8 +41,10 @@ type VectorSearchResult struct {
 }
 
 // VectorSearcher abstracts similarity search for testability.
+// Only chunks embedded in space are compared against queryVec; a zero space
+// disables the filter (unversioned deployments and tests).
 type VectorSearcher interface {
-	SimilaritySearch(ctx context.Context, queryVec []float32, topK int, threshold float64, userID string, excludePrivileged bool) ([]VectorSearchResult, error)
+	SimilaritySearch(ctx context.Context, queryVec []float32, space model.EmbeddingSpace, topK int, threshold float64, userID string, excludePrivileged bool) ([]VectorSearchResult, error)
 }
 
 // QueryEmbedder abstracts query embedding for testability.
@@ -48,11 +52,28 @@ type QueryEmbedder interface {
 	Embed(ctx context.Context, texts []string) ([][]float32, error)
 }
 
-// BM25Searcher abstracts full-text search for testability.
+// BM25Searcher abstracts full-text search for testability. When
+// excludePrivileged is true, other users' privileged documents are skipped.
 type BM25Searcher interface {
-	FullTextSearch(ctx context.Context, query string, topK int, userID string) ([]VectorSearchResult, error)
+	FullTextSearch(ctx context.Context, query string, topK int, userID string, excludePrivileged bool) ([]VectorSearchResult, error)
 }
 
+// VaultVectorSearcher is a VectorSearcher that can restrict results to one
+// vault. Required for vault-scoped retrieval.
+type VaultVectorSearcher interface {
+	SimilaritySearchInVault(ctx context.Context, queryVec []float32, space model.EmbeddingSpace, topK int, threshold float64, userID, vaultID string, excludePrivileged bool) ([]VectorSearchResult, error)
+}
+
+// VaultBM25Searcher is a BM25Searcher that can restrict results to one vault.
+// Vault-scoped retrieval skips BM25 when the searcher does not implement it.
+type VaultBM25Searcher interface {
+	FullTextSearchInVault(ctx context.Context, query string, topK int, userID, vaultID string, excludePrivileged bool) ([]VectorSearchResult, error)
+}
+
+// ErrVaultScopeUnsupported is returned for vault-scoped retrieval when the
+// vector searcher cannot filter by vault.
+var ErrVaultScopeUnsupported = errors.New("vector searcher does not support vault scope")
+
 // ThreadSearchResult represents a conversation message found via similarity search.
 type ThreadSearchResult struct {
 	MessageID  string    `json:"messageId"`
@@ -86,9 +107,19 @@ type RetrievalResult struct {
 	TotalDocumentsFound int                `json:"totalDocumentsFound"`
 }
 
+// EmbeddingBinding pairs a query embedder with the embedding space its vectors
+// belong to. Callers snapshot it once per request so the query vector and the
+// search filter always agree, even across a re-embed cutover.
+type EmbeddingBinding struct {
+	Space    model.EmbeddingSpace
+	Embedder QueryEmbedder
+}
+
 // RetrieverService processes queries and retrieves relevant document chunks.
 type RetrieverService struct {
+	mu       sync.RWMutex
 	embedder QueryEmbedder
+	space    model.EmbeddingSpace // zero = unversioned (no space filter)
 	searcher VectorSearcher
 	bm25     BM25Searcher    // nil = vector-only (backward compatible)
 	threads  ThreadSearcher  // nil = no thread search (S-P1-04)
@@ -104,7 +135,23 @@ func NewRetrieverService(embedder QueryEmbedder, searcher VectorSearcher) *Retri
 
 // Embedder returns the underlying QueryEmbedder for external embedding.
 func (s *RetrieverService) Embedder() QueryEmbedder {
-	return s.embedder
+	return s.Binding().Embedder
+}
+
+// Binding returns the current embedder and embedding space as one snapshot.
+func (s *RetrieverService) Binding() EmbeddingBinding {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return EmbeddingBinding{Space: s.space, Embedder: s.embedder}
+}
+
+// SetEmbedding swaps the query embedder and the space searched against.
+// Called at startup and when a re-embed cutover activates a new space.
+func (s *RetrieverService) SetEmbedding(space model.EmbeddingSpace, embedder QueryEmbedder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.space = space
+	s.embedder = embedder
 }
 
 // SetBM25 attaches a BM25Searcher for hybrid retrieval.
@@ -126,26 +173,52 @@ func (s *RetrieverService) Retrieve(ctx context.Context, userID string, query st
 	}
 
 	// 1. Embed the query
-	queryVecs, err := s.embedder.Embed(ctx, []string{query})
+	b := s.Binding()
+	queryVecs, err := b.Embedder.Embed(ctx, []string{query})
 	if err != nil {
 		return nil, fmt.Errorf("service.Retrieve: embed: %w", err)
 	}
 	queryVec := queryVecs[0]
 
-	return s.retrieveWithVec(ctx, userID, query, queryVec, privilegeMode)
+	return s.retrieveWithVec(ctx, b.Space, userID, "", query, queryVec, privilegeMode)
 }
 
 // RetrieveWithVec performs retrieval using a pre-computed query embedding vector.
 // This skips the embedding step, enabling parallel cache check + embedding.
 // The query string is used for BM25 full-text search when available.
+// The vector is assumed to belong to the current embedding space; callers that
+// embedded against an earlier Binding snapshot should use RetrieveInSpace.
 func (s *RetrieverService) RetrieveWithVec(ctx context.Context, userID, query string, queryVec []float32, privilegeMode bool) (*RetrievalResult, error) {
-	return s.retrieveWithVec(ctx, userID, query, queryVec, privilegeMode)
+	return s.retrieveWithVec(ctx, s.Binding().Space, userID, "", query, queryVec, privilegeMode)
+}
+
+// RetrieveInSpace performs retrieval with a query vector produced in space.
+// Only chunks embedded in the same space are compared against it.
+func (s *RetrieverService) RetrieveInSpace(ctx context.Context, space model.EmbeddingSpace, userID, query string, queryVec []float32, privilegeMode bool) (*RetrievalResult, error) {
+	return s.retrieveWithVec(ctx, space, userID, "", query, queryVec, privilegeMode)
+}
+
+// RetrieveInVault is RetrieveInSpace restricted to documents in vaultID.
+// Callers must check the user may query the vault; the searchers enforce it
+// again. Conversation memory is not vault-scoped and is skipped.
+func (s *RetrieverService) RetrieveInVault(ctx context.Context, space model.EmbeddingSpace, userID, vaultID, query string, queryVec []float32, privilegeMode bool) (*RetrievalResult, error) {
+	return s.retrieveWithVec(ctx, space, userID, vaultID, query, queryVec, privilegeMode)
 }
 
-func (s *RetrieverService) retrieveWithVec(ctx context.Context, userID string, query string, queryVec []float32, privilegeMode bool) (*RetrievalResult, error) {
+func (s *RetrieverService) retrieveWithVec(ctx context.Context, space model.EmbeddingSpace, userID, vaultID, query string, queryVec []float32, privilegeMode bool) (*RetrievalResult, error) {
+	var vaultSearcher VaultVectorSearcher
+	if vaultID != "" {
+		vs, ok := s.searcher.(VaultVectorSearcher)
+		if !ok {
+			return nil, fmt.Errorf("service.Retrieve: %w", ErrVaultScopeUnsupported)
+		}
+		vaultSearcher = vs
+	}
+
 	slog.Info("[DEBUG-RETRIEVER] query embedded",
 		"query", query,
 		"user_id", userID,
+		"vault_id", vaultID,
 		"vec_dim", len(queryVec),
 		"vec_first3", fmt.Sprintf("%.4f, %.4f, %.4f", safeIdx(queryVec, 0), safeIdx(queryVec, 1), safeIdx(queryVec, 2)),
 	)
@@ -159,20 +232,34 @@ func (s *RetrieverService) retrieveWithVec(ctx context.Context, userID string, q
 
 	g.Go(func() error {
 		var err error
-		vectorResults, err = s.searcher.SimilaritySearch(gCtx, queryVec, defaultTopK, defaultThreshold, userID, excludePrivileged)
+		if vaultSearcher != nil {
+			vectorResults, err = vaultSearcher.SimilaritySearchInVault(gCtx, queryVec, space, defaultTopK, defaultThreshold, userID, vaultID, excludePrivileged)
+			return err
+		}
+		vectorResults, err = s.searcher.SimilaritySearch(gCtx, queryVec, space, defaultTopK, defaultThreshold, userID, excludePrivileged)
 		return err
 	})
 
 	if s.bm25 != nil && query != "" {
-		g.Go(func() error {
-			var err error
-			bm25Results, err = s.bm25.FullTextSearch(gCtx, query, defaultTopK, userID)
-			return err
-		})
+		vaultBM25, vaultCapable := s.bm25.(VaultBM25Searcher)
+		switch {
+		case vaultID == "":
+			g.Go(func() error {
+				var err error
+				bm25Results, err = s.bm25.FullTextSearch(gCtx, query, defaultTopK, userID, excludePrivileged)
+				return err
+			})
+		case vaultCapable:
+			g.Go(func() error {
+				var err error
+				bm25Results, err = vaultBM25.FullTextSearchInVault(gCtx, query, defaultTopK, userID, vaultID, excludePrivileged)
+				return err
+			})
+		}
 	}
 
 	// S-P1-04: Thread memory recall — search conversation history
-	if s.threads != nil {
+	if s.threads != nil && vaultID == "" {
 		g.Go(func() error {
 			var err error
 			threadResults, err = s.threads.ThreadSimilaritySearch(gCtx, queryVec, 5, defaultThreshold, userID)
@@ -187,6 +274,12 @@ func (s *RetrieverService) retrieveWithVec(ctx context.Context, userID string, q
 	if err := g.Wait(); err != nil {
 		return nil, fmt.Errorf("service.Retrieve: search: %w", err)
 	}
+	if excludePrivileged {
+		// The searchers filter too; this keeps a searcher that does not
+		// from leaking another member's privileged chunks into fusion.
+		vectorResults = dropOthersPrivileged(vectorResults, userID)
+		bm25Results = dropOthersPrivileged(bm25Results, userID)
+	}
 
 	slog.Info("[DEBUG-RETRIEVER] search done",
 		"user_id", userID,
@@ -372,3 +465,16 @@ func deduplicate(ranked []RankedChunk, maxPerDoc int) []RankedChunk {
 
 	return result
 }
+
+// dropOthersPrivileged removes results from privileged documents owned by
+// someone other than userID.
+func dropOthersPrivileged(results []VectorSearchResult, userID string) []VectorSearchResult {
+	kept := results[:0]
+	for _, r := range results {
+		if r.Document.IsPrivileged && r.Document.UserID != userID {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strings"
@@ -42,13 +43,15 @@ type mockVectorSearcher struct {
 	capturedThreshold  float64
 	capturedUserID     string
 	capturedExcludePriv bool
+	capturedSpace      model.EmbeddingSpace
 }
 
-func (m *mockVectorSearcher) SimilaritySearch(ctx context.Context, queryVec []float32, topK int, threshold float64, userID string, excludePrivileged bool) ([]VectorSearchResult, error) {
+func (m *mockVectorSearcher) SimilaritySearch(ctx context.Context, queryVec []float32, space model.EmbeddingSpace, topK int, threshold float64, userID string, excludePrivileged bool) ([]VectorSearchResult, error) {
 	m.capturedTopK = topK
 	m.capturedThreshold = threshold
 	m.capturedUserID = userID
 	m.capturedExcludePriv = excludePrivileged
+	m.capturedSpace = space
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -174,6 +177,32 @@ func TestRetrieve_SearchParameters(t *testing.T) {
 	}
 }
 
+func TestRetrieve_SearchesActiveEmbeddingSpace(t *testing.T) {
+	searcher := &mockVectorSearcher{results: []VectorSearchResult{}}
+	svc := NewRetrieverService(&mockQueryEmbedder{}, searcher)
+
+	v1 := model.EmbeddingSpace{Model: "text-embedding-004", Version: 1, Dimensions: 768}
+	svc.SetEmbedding(v1, &mockQueryEmbedder{})
+	svc.Retrieve(context.Background(), "test-user", "test", false)
+	if searcher.capturedSpace != v1 {
+		t.Errorf("space = %+v, want %+v", searcher.capturedSpace, v1)
+	}
+
+	// A vector embedded before a cutover keeps searching its own space.
+	v2 := model.EmbeddingSpace{Model: "text-embedding-005", Version: 2, Dimensions: 768}
+	b := svc.Binding()
+	svc.SetEmbedding(v2, &mockQueryEmbedder{})
+	svc.RetrieveInSpace(context.Background(), b.Space, "test-user", "test", make([]float32, 768), false)
+	if searcher.capturedSpace != v1 {
+		t.Errorf("snapshot space = %+v, want %+v", searcher.capturedSpace, v1)
+	}
+
+	svc.RetrieveWithVec(context.Background(), "test-user", "test", make([]float32, 768), false)
+	if searcher.capturedSpace != v2 {
+		t.Errorf("current space = %+v, want %+v", searcher.capturedSpace, v2)
+	}
+}
+
 func TestRetrieve_ReturnsMax5(t *testing.T) {
 	now := time.Now().UTC()
 	results := make([]VectorSearchResult, 10)
@@ -442,17 +471,61 @@ func TestRetrieve_HybridWithBM25(t *testing.T) {
 
 // mockBM25Searcher implements BM25Searcher for testing.
 type mockBM25Searcher struct {
-	results []VectorSearchResult
-	err     error
+	results         []VectorSearchResult
+	err             error
+	capturedExclude bool
 }
 
-func (m *mockBM25Searcher) FullTextSearch(ctx context.Context, query string, topK int, userID string) ([]VectorSearchResult, error) {
+func (m *mockBM25Searcher) FullTextSearch(ctx context.Context, query string, topK int, userID string, excludePrivileged bool) ([]VectorSearchResult, error) {
+	m.capturedExclude = excludePrivileged
 	if m.err != nil {
 		return nil, m.err
 	}
 	return m.results, nil
 }
 
+func TestRetrieve_BM25DropsOthersPrivilegedDocs(t *testing.T) {
+	now := time.Now().UTC()
+	vectorSearcher := &mockVectorSearcher{results: []VectorSearchResult{makeResult("doc-1", "vector match", 0.90, now, 10)}}
+	vectorSearcher.results[0].Document.UserID = "test-user"
+
+	others := makeResult("doc-memo", "another member's privileged memo", 0.85, now, 5)
+	others.Document.UserID, others.Document.IsPrivileged = "other-user", true
+	own := makeResult("doc-own", "the caller's privileged memo", 0.80, now, 5)
+	own.Document.UserID, own.Document.IsPrivileged = "test-user", true
+
+	for _, tt := range []struct {
+		name          string
+		privilegeMode bool
+		wantMemo      bool
+	}{
+		{"normal mode", false, false},
+		{"privileged mode", true, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			bm25Mock := &mockBM25Searcher{results: []VectorSearchResult{others, own}}
+			svc := NewRetrieverService(&mockQueryEmbedder{}, vectorSearcher)
+			svc.SetBM25(bm25Mock)
+
+			result, err := svc.Retrieve(context.Background(), "test-user", "settlement memo", tt.privilegeMode)
+			if err != nil {
+				t.Fatalf("Retrieve: %v", err)
+			}
+			if bm25Mock.capturedExclude == tt.privilegeMode {
+				t.Errorf("BM25 excludePrivileged = %v in privilege mode %v", bm25Mock.capturedExclude, tt.privilegeMode)
+			}
+			var gotMemo, gotOwn bool
+			for _, c := range result.Chunks {
+				gotMemo = gotMemo || c.Document.ID == "doc-memo"
+				gotOwn = gotOwn || c.Document.ID == "doc-own"
+			}
+			if gotMemo != tt.wantMemo || !gotOwn {
+				t.Errorf("other's privileged doc returned = %v (want %v), own = %v (want true)", gotMemo, tt.wantMemo, gotOwn)
+			}
+		})
+	}
+}
+
 // --- STORY-170: Tenant Isolation Tests ---
 
 // TestRetrieve_TenantIsolation_UserIDPassedToSearch verifies that the userID
@@ -571,7 +644,7 @@ type tenantAwareMockSearcher struct {
 	docsByUser map[string][]VectorSearchResult
 }
 
-func (m *tenantAwareMockSearcher) SimilaritySearch(ctx context.Context, queryVec []float32, topK int, threshold float64, userID string, excludePrivileged bool) ([]VectorSearchResult, error) {
+func (m *tenantAwareMockSearcher) SimilaritySearch(ctx context.Context, queryVec []float32, space model.EmbeddingSpace, topK int, threshold float64, userID string, excludePrivileged bool) ([]VectorSearchResult, error) {
 	results, ok := m.docsByUser[userID]
 	if !ok {
 		return nil, nil
@@ -673,3 +746,78 @@ func TestRerank(t *testing.T) {
 		}
 	}
 }
+
+// --- Vault-scoped retrieval ---
+
+// vaultMockSearcher implements VaultVectorSearcher and VaultBM25Searcher.
+type vaultMockSearcher struct {
+	mockVectorSearcher
+	vaultResults  []VectorSearchResult
+	capturedVault string
+	bm25Vault     string
+}
+
+func (m *vaultMockSearcher) SimilaritySearchInVault(ctx context.Context, queryVec []float32, space model.EmbeddingSpace, topK int, threshold float64, userID, vaultID string, excludePrivileged bool) ([]VectorSearchResult, error) {
+	m.capturedVault = vaultID
+	m.capturedUserID = userID
+	return m.vaultResults, nil
+}
+
+func (m *vaultMockSearcher) FullTextSearch(ctx context.Context, query string, topK int, userID string, excludePrivileged bool) ([]VectorSearchResult, error) {
+	return m.results, nil
+}
+
+func (m *vaultMockSearcher) FullTextSearchInVault(ctx context.Context, query string, topK int, userID, vaultID string, excludePrivileged bool) ([]VectorSearchResult, error) {
+	m.bm25Vault = vaultID
+	return nil, nil
+}
+
+func TestRetrieveInVault_ScopesSearches(t *testing.T) {
+	now := time.Now().UTC()
+	searcher := &vaultMockSearcher{
+		mockVectorSearcher: mockVectorSearcher{results: []VectorSearchResult{makeResult("doc-other", "outside", 0.95, now, 5)}},
+		vaultResults:       []VectorSearchResult{makeResult("doc-vault", "inside", 0.90, now, 5)},
+	}
+	svc := NewRetrieverService(&mockQueryEmbedder{}, searcher)
+	svc.SetBM25(searcher)
+
+	vec := make([]float32, 768)
+	result, err := svc.RetrieveInVault(context.Background(), model.EmbeddingSpace{}, "user-1", "vault-1", "q", vec, false)
+	if err != nil {
+		t.Fatalf("RetrieveInVault: %v", err)
+	}
+	if searcher.capturedVault != "vault-1" || searcher.bm25Vault != "vault-1" {
+		t.Errorf("vault = %q / %q, want vault-1 for both searches", searcher.capturedVault, searcher.bm25Vault)
+	}
+	for _, c := range result.Chunks {
+		if c.Document.ID != "doc-vault" {
+			t.Errorf("chunk from %s leaked into vault-scoped result", c.Document.ID)
+		}
+	}
+}
+
+func TestRetrieveInVault_UnsupportedSearcher(t *testing.T) {
+	svc := NewRetrieverService(&mockQueryEmbedder{}, &mockVectorSearcher{})
+
+	_, err := svc.RetrieveInVault(context.Background(), model.EmbeddingSpace{}, "user-1", "vault-1", "q", make([]float32, 768), false)
+	if !errors.Is(err, ErrVaultScopeUnsupported) {
+		t.Fatalf("err = %v, want ErrVaultScopeUnsupported", err)
+	}
+}
+
+func TestRetrieveInVault_SkipsUnscopedBM25(t *testing.T) {
+	now := time.Now().UTC()
+	searcher := &vaultMockSearcher{
+		vaultResults: []VectorSearchResult{makeResult("doc-vault", "inside", 0.90, now, 5)},
+	}
+	svc := NewRetrieverService(&mockQueryEmbedder{}, searcher)
+	svc.SetBM25(&mockBM25Searcher{results: []VectorSearchResult{makeResult("doc-other", "outside", 0.80, now, 5)}})
+
+	result, err := svc.RetrieveInVault(context.Background(), model.EmbeddingSpace{}, "user-1", "vault-1", "q", make([]float32, 768), false)
+	if err != nil {
+		t.Fatalf("RetrieveInVault: %v", err)
+	}
+	if len(result.Chunks) != 1 || result.Chunks[0].Document.ID != "doc-vault" {
+		t.Errorf("chunks = %+v, want only doc-vault", result.Chunks)
+	}
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// Sharing errors. Handlers map them to HTTP statuses.
+var (
+	ErrInvalidShare      = errors.New("invalid share request")
+	ErrShareNotFound     = errors.New("share grant not found")
+	ErrGranteeNotFound   = errors.New("grantee not found")
+	ErrShareGroupMissing = errors.New("share group not found")
+	ErrShareGroupExists  = errors.New("a share group with that name already exists")
+)
+
+// ShareRepository persists share grants and share groups. Access checks use
+// the shared_documents SQL function (migration 023). Implemented by
+// repository.ShareRepo.
+type ShareRepository interface {
+	// UpsertGrant creates the grant, or updates the role and expiry of the
+	// existing grant for the same resource and grantee (g.ID is set to it).
+	UpsertGrant(ctx context.Context, g *model.ShareGrant) error
+	// GetGrant returns the grant, or nil if it does not exist.
+	GetGrant(ctx context.Context, id string) (*model.ShareGrant, error)
+	ListGrants(ctx context.Context, resourceType model.ShareResourceType, resourceID string) ([]model.ShareGrant, error)
+	DeleteGrant(ctx context.Context, id string) error
+	// CreateGroup returns ErrShareGroupExists if the name is taken in the org.
+	CreateGroup(ctx context.Context, g *model.ShareGroup) error
+	// GetGroup returns the group with its members, or nil if it does not exist.
+	GetGroup(ctx context.Context, id string) (*model.ShareGroup, error)
+	ListGroups(ctx context.Context, orgID string) ([]model.ShareGroup, error)
+	// DeleteGroup deletes the group and the grants that target it, returning them.
+	DeleteGroup(ctx context.Context, id string) ([]model.ShareGrant, error)
+	AddGroupMember(ctx context.Context, groupID, userID string) error
+	RemoveGroupMember(ctx context.Context, groupID, userID string) error
+	// UserExists reports whether userID is a known user.
+	UserExists(ctx context.Context, userID string) (bool, error)
+}
+
+// OrgMembership resolves a user's organization membership (nil if none).
+// Implemented by repository.OrgRepo.
+type OrgMembership interface {
+	Membership(ctx context.Context, userID string) (*model.OrgMember, error)
+}
+
+// ShareAuditLogger records grants, revokes and group membership changes.
+type ShareAuditLogger interface {
+	LogWithDetails(ctx context.Context, action, userID, resourceID, resourceType string, details map[string]interface{}) error
+}
+
+// ShareResource identifies a document or folder being shared. OrgID is the
+// resource's organization (or personal scope).
+type ShareResource struct {
+	Type  model.ShareResourceType
+	ID    string
+	OrgID string
+}
+
+// GrantRequest describes a grant to create or update.
+type GrantRequest struct {
+	GranteeType model.ShareGranteeType
+	GranteeID   string
+	Role        model.ShareRole
+	ExpiresAt   *time.Time
+}
+
+// SharingService manages share grants on documents and folders and the
+// share groups grants can target. Callers check that the actor may manage
+// the resource (owner or Partner) before granting or revoking.
+type SharingService struct {
+	repo    ShareRepository
+	members OrgMembership
+	audit   ShareAuditLogger
+	now     func() time.Time
+}
+
+// NewSharingService creates a SharingService. audit may be nil.
+func NewSharingService(repo ShareRepository, members OrgMembership, audit ShareAuditLogger) *SharingService {
+	return &SharingService{repo: repo, members: members, audit: audit, now: time.Now}
+}
+
+// Grant shares res with a user or group. Users must belong to the
+// resource's organization unless the resource is personal; groups must
+// belong to the resource's organization.
+func (s *SharingService) Grant(ctx context.Context, actorID string, res ShareResource, req GrantRequest) (*model.ShareGrant, error) {
+	if req.Role == "" {
+		req.Role = model.ShareViewer
+	}
+	if !model.ValidShareRole(req.Role) {
+		return nil, fmt.Errorf("%w: role must be viewer or editor", ErrInvalidShare)
+	}
+	if !model.ValidShareGranteeType(req.GranteeType) || req.GranteeID == "" {
+		return nil, fmt.Errorf("%w: granteeType must be user or group with a granteeId", ErrInvalidShare)
+	}
+	now := s.now().UTC()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidShare)
+	}
+	if err := s.checkGrantee(ctx, actorID, res, req); err != nil {
+		return nil, err
+	}
+
+	g := &model.ShareGrant{
+		ID:           uuid.New().String(),
+		ResourceType: res.Type,
+		ResourceID:   res.ID,
+		GranteeType:  req.GranteeType,
+		GranteeID:    req.GranteeID,
+		Role:         req.Role,
+		GrantedBy:    actorID,
+		ExpiresAt:    req.ExpiresAt,
+		CreatedAt:    now,
+	}
+	if err := s.repo.UpsertGrant(ctx, g); err != nil {
+		return nil, fmt.Errorf("service.Sharing.Grant: %w", err)
+	}
+	s.logGrant(ctx, model.AuditShareGrant, actorID, g)
+	return g, nil
+}
+
+// ListGrants lists the grants on res, including expired ones.
+func (s *SharingService) ListGrants(ctx context.Context, res ShareResource) ([]model.ShareGrant, error) {
+	grants, err := s.repo.ListGrants(ctx, res.Type, res.ID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Sharing.ListGrants: %w", err)
+	}
+	return grants, nil
+}
+
+// Revoke deletes a grant on res and returns it.
+func (s *SharingService) Revoke(ctx context.Context, actorID string, res ShareResource, grantID string) (*model.ShareGrant, error) {
+	g, err := s.repo.GetGrant(ctx, grantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Sharing.Revoke: %w", err)
+	}
+	if g == nil || g.ResourceType != res.Type || g.ResourceID != res.ID {
+		return nil, ErrShareNotFound
+	}
+	if err := s.repo.DeleteGrant(ctx, g.ID); err != nil {
+		return nil, fmt.Errorf("service.Sharing.Revoke: %w", err)
+	}
+	s.logGrant(ctx, model.AuditShareRevoke, actorID, g)
+	return g, nil
+}
+
+// GranteeUserIDs returns the users a grant reaches, for cache invalidation.
+func (s *SharingService) GranteeUserIDs(ctx context.Context, g *model.ShareGrant) []string {
+	if g.GranteeType == model.ShareGranteeUser {
+		return []string{g.GranteeID}
+	}
+	group, err := s.repo.GetGroup(ctx, g.GranteeID)
+	if err != nil || group == nil {
+		return nil
+	}
+	return group.MemberIDs
+}
+
+// CreateGroup creates a share group in the caller's organization. Partner only.
+func (s *SharingService) CreateGroup(ctx context.Context, actorID, name string) (*model.ShareGroup, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("%w: name must be 1-100 characters", ErrInvalidShare)
+	}
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	g := &model.ShareGroup{
+		ID:        uuid.New().String(),
+		OrgID:     m.OrgID,
+		Name:      name,
+		CreatedBy: actorID,
+		MemberIDs: []string{},
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.repo.CreateGroup(ctx, g); err != nil {
+		if errors.Is(err, ErrShareGroupExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("service.Sharing.CreateGroup: %w", err)
+	}
+	return g, nil
+}
+
+// ListGroups lists the share groups of the caller's organization.
+func (s *SharingService) ListGroups(ctx context.Context, actorID string) ([]model.ShareGroup, error) {
+	m, err := s.membership(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.repo.ListGroups(ctx, m.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Sharing.ListGroups: %w", err)
+	}
+	return groups, nil
+}
+
+// DeleteGroup deletes a share group and revokes every grant to it. Partner only.
+func (s *SharingService) DeleteGroup(ctx context.Context, actorID, groupID string) (*model.ShareGroup, error) {
+	group, err := s.managedGroup(ctx, actorID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := s.repo.DeleteGroup(ctx, group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Sharing.DeleteGroup: %w", err)
+	}
+	for i := range revoked {
+		s.logGrant(ctx, model.AuditShareRevoke, actorID, &revoked[i])
+	}
+	return group, nil
+}
+
+// AddGroupMember adds an organization member to a share group. Partner only.
+func (s *SharingService) AddGroupMember(ctx context.Context, actorID, groupID, userID string) error {
+	group, err := s.managedGroup(ctx, actorID, groupID)
+	if err != nil {
+		return err
+	}
+	m, err := s.members.Membership(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service.Sharing.AddGroupMember: %w", err)
+	}
+	if m == nil || m.OrgID != group.OrgID {
+		return ErrMemberNotFound
+	}
+	if err := s.repo.AddGroupMember(ctx, group.ID, userID); err != nil {
+		return fmt.Errorf("service.Sharing.AddGroupMember: %w", err)
+	}
+	s.logGroup(ctx, model.AuditShareGroupAdd, actorID, group, userID)
+	return nil
+}
+
+// RemoveGroupMember removes a user from a share group. Partner only.
+func (s *SharingService) RemoveGroupMember(ctx context.Context, actorID, groupID, userID string) error {
+	group, err := s.managedGroup(ctx, actorID, groupID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RemoveGroupMember(ctx, group.ID, userID); err != nil {
+		return fmt.Errorf("service.Sharing.RemoveGroupMember: %w", err)
+	}
+	s.logGroup(ctx, model.AuditShareGroupRemove, actorID, group, userID)
+	return nil
+}
+
+func (s *SharingService) checkGrantee(ctx context.Context, actorID string, res ShareResource, req GrantRequest) error {
+	if req.GranteeType == model.ShareGranteeGroup {
+		group, err := s.repo.GetGroup(ctx, req.GranteeID)
+		if err != nil {
+			return fmt.Errorf("service.Sharing.Grant: %w", err)
+		}
+		if group == nil || group.OrgID != res.OrgID {
+			return ErrShareGroupMissing
+		}
+		return nil
+	}
+
+	if req.GranteeID == actorID {
+		return fmt.Errorf("%w: cannot share with yourself", ErrInvalidShare)
+	}
+	ok, err := s.repo.UserExists(ctx, req.GranteeID)
+	if err != nil {
+		return fmt.Errorf("service.Sharing.Grant: %w", err)
+	}
+	if !ok {
+		return ErrGranteeNotFound
+	}
+	if model.IsPersonalOrg(res.OrgID) {
+		return nil
+	}
+	m, err := s.members.Membership(ctx, req.GranteeID)
+	if err != nil {
+		return fmt.Errorf("service.Sharing.Grant: %w", err)
+	}
+	if m == nil || m.OrgID != res.OrgID {
+		return ErrGranteeNotFound
+	}
+	return nil
+}
+
+func (s *SharingService) managedGroup(ctx context.Context, actorID, groupID string) (*model.ShareGroup, error) {
+	m, err := s.partner(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.repo.GetGroup(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Sharing.Group: %w", err)
+	}
+	if group == nil || group.OrgID != m.OrgID {
+		return nil, ErrShareGroupMissing
+	}
+	return group, nil
+}
+
+func (s *SharingService) membership(ctx context.Context, userID string) (*model.OrgMember, error) {
+	m, err := s.members.Membership(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service.Sharing.membership: %w", err)
+	}
+	if m == nil {
+		return nil, ErrNotOrgMember
+	}
+	return m, nil
+}
+
+func (s *SharingService) partner(ctx context.Context, userID string) (*model.OrgMember, error) {
+	m, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Role != model.UserRolePartner {
+		return nil, ErrOrgForbidden
+	}
+	return m, nil
+}
+
+func (s *SharingService) logGrant(ctx context.Context, action, actorID string, g *model.ShareGrant) {
+	details := map[string]interface{}{
+		"grantId":     g.ID,
+		"granteeType": g.GranteeType,
+		"granteeId":   g.GranteeID,
+		"role":        g.Role,
+	}
+	if g.ExpiresAt != nil {
+		details["expiresAt"] = g.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	s.log(ctx, action, actorID, g.ResourceID, string(g.ResourceType), details)
+}
+
+func (s *SharingService) logGroup(ctx context.Context, action, actorID string, group *model.ShareGroup, userID string) {
+	s.log(ctx, action, actorID, group.ID, "share_group", map[string]interface{}{
+		"groupName": group.Name,
+		"memberId":  userID,
+	})
+}
+
+func (s *SharingService) log(ctx context.Context, action, actorID, resourceID, resourceType string, details map[string]interface{}) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogWithDetails(ctx, action, actorID, resourceID, resourceType, details); err != nil {
+		// Don't fail the change on audit error — log and continue
+		slog.Error("[Sharing] audit log failed", "action", action, "user_id", actorID, "resource_id", resourceID, "error", err)
+	}
+}
+
+// DocumentShareResolver is implemented by document repositories that honour
+// share grants. DocumentShareRole returns "" when no grant reaches userID.
+type DocumentShareResolver interface {
+	DocumentShareRole(ctx context.Context, docID, userID string) (model.ShareRole, error)
+}
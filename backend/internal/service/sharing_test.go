@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/connexus-ai/ragbox-backend/internal/model"
+)
+
+// memShareRepo implements ShareRepository in memory.
+type memShareRepo struct {
+	grants map[string]*model.ShareGrant
+	groups map[string]*model.ShareGroup
+	users  map[string]bool
+}
+
+func newMemShareRepo(users ...string) *memShareRepo {
+	m := &memShareRepo{
+		grants: map[string]*model.ShareGrant{},
+		groups: map[string]*model.ShareGroup{},
+		users:  map[string]bool{},
+	}
+	for _, u := range users {
+		m.users[u] = true
+	}
+	return m
+}
+
+func (m *memShareRepo) UpsertGrant(_ context.Context, g *model.ShareGrant) error {
+	for _, existing := range m.grants {
+		if existing.ResourceType == g.ResourceType && existing.ResourceID == g.ResourceID &&
+			existing.GranteeType == g.GranteeType && existing.GranteeID == g.GranteeID {
+			existing.Role, existing.ExpiresAt = g.Role, g.ExpiresAt
+			g.ID = existing.ID
+			return nil
+		}
+	}
+	cp := *g
+	m.grants[g.ID] = &cp
+	return nil
+}
+
+func (m *memShareRepo) GetGrant(_ context.Context, id string) (*model.ShareGrant, error) {
+	if g, ok := m.grants[id]; ok {
+		cp := *g
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *memShareRepo) ListGrants(_ context.Context, rt model.ShareResourceType, rid string) ([]model.ShareGrant, error) {
+	var out []model.ShareGrant
+	for _, g := range m.grants {
+		if g.ResourceType == rt && g.ResourceID == rid {
+			out = append(out, *g)
+		}
+	}
+	return out, nil
+}
+
+func (m *memShareRepo) DeleteGrant(_ context.Context, id string) error {
+	delete(m.grants, id)
+	return nil
+}
+
+func (m *memShareRepo) CreateGroup(_ context.Context, g *model.ShareGroup) error {
+	for _, existing := range m.groups {
+		if existing.OrgID == g.OrgID && existing.Name == g.Name {
+			return ErrShareGroupExists
+		}
+	}
+	m.groups[g.ID] = g
+	return nil
+}
+
+func (m *memShareRepo) GetGroup(_ context.Context, id string) (*model.ShareGroup, error) {
+	return m.groups[id], nil
+}
+
+func (m *memShareRepo) ListGroups(_ context.Context, orgID string) ([]model.ShareGroup, error) {
+	var out []model.ShareGroup
+	for _, g := range m.groups {
+		if g.OrgID == orgID {
+			out = append(out, *g)
+		}
+	}
+	return out, nil
+}
+
+func (m *memShareRepo) DeleteGroup(_ context.Context, id string) ([]model.ShareGrant, error) {
+	var revoked []model.ShareGrant
+	for gid, g := range m.grants {
+		if g.GranteeType == model.ShareGranteeGroup && g.GranteeID == id {
+			revoked = append(revoked, *g)
+			delete(m.grants, gid)
+		}
+	}
+	delete(m.groups, id)
+	return revoked, nil
+}
+
+func (m *memShareRepo) AddGroupMember(_ context.Context, groupID, userID string) error {
+	m.groups[groupID].MemberIDs = append(m.groups[groupID].MemberIDs, userID)
+	return nil
+}
+
+func (m *memShareRepo) RemoveGroupMember(_ context.Context, groupID, userID string) error {
+	g := m.groups[groupID]
+	for i, id := range g.MemberIDs {
+		if id == userID {
+			g.MemberIDs = append(g.MemberIDs[:i], g.MemberIDs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *memShareRepo) UserExists(_ context.Context, userID string) (bool, error) {
+	return m.users[userID], nil
+}
+
+// recordingAudit captures audit actions.
+type recordingAudit struct {
+	actions []string
+}
+
+func (a *recordingAudit) LogWithDetails(_ context.Context, action, _, _, _ string, _ map[string]interface{}) error {
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+// newSharingFixture returns a service for org-a with partner "p", associate
+// "a", an outsider "x" in org-b and a personal user "solo".
+func newSharingFixture() (*SharingService, *memShareRepo, *recordingAudit) {
+	orgs := newMemOrgRepo()
+	orgs.members["p"] = &model.OrgMember{OrgID: "org-a", UserID: "p", Role: model.UserRolePartner}
+	orgs.members["a"] = &model.OrgMember{OrgID: "org-a", UserID: "a", Role: model.UserRoleAssociate}
+	orgs.members["x"] = &model.OrgMember{OrgID: "org-b", UserID: "x", Role: model.UserRolePartner}
+	repo := newMemShareRepo("p", "a", "x", "solo")
+	audit := &recordingAudit{}
+	return NewSharingService(repo, orgs, audit), repo, audit
+}
+
+var orgDoc = ShareResource{Type: model.ShareResourceDocument, ID: "doc-1", OrgID: "org-a"}
+
+func TestSharing_GrantAndRevokeAreAudited(t *testing.T) {
+	svc, repo, audit := newSharingFixture()
+	ctx := context.Background()
+
+	g, err := svc.Grant(ctx, "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeUser, GranteeID: "a"})
+	if err != nil {
+		t.Fatalf("Grant: %v", err)
+	}
+	if g.Role != model.ShareViewer {
+		t.Errorf("role = %s, want viewer by default", g.Role)
+	}
+
+	// Re-sharing updates the existing grant instead of adding another.
+	g2, err := svc.Grant(ctx, "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeUser, GranteeID: "a", Role: model.ShareEditor})
+	if err != nil {
+		t.Fatalf("Grant (update): %v", err)
+	}
+	if g2.ID != g.ID || len(repo.grants) != 1 || repo.grants[g.ID].Role != model.ShareEditor {
+		t.Errorf("re-share created a second grant or kept the old role")
+	}
+
+	if _, err := svc.Revoke(ctx, "p", orgDoc, g.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	want := []string{model.AuditShareGrant, model.AuditShareGrant, model.AuditShareRevoke}
+	if len(audit.actions) != len(want) {
+		t.Fatalf("audit = %v, want %v", audit.actions, want)
+	}
+	for i := range want {
+		if audit.actions[i] != want[i] {
+			t.Errorf("audit[%d] = %s, want %s", i, audit.actions[i], want[i])
+		}
+	}
+}
+
+func TestSharing_GrantRules(t *testing.T) {
+	svc, _, _ := newSharingFixture()
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+	personal := ShareResource{Type: model.ShareResourceDocument, ID: "doc-2", OrgID: model.PersonalOrgID("solo")}
+
+	tests := []struct {
+		name  string
+		actor string
+		res   ShareResource
+		req   GrantRequest
+		want  error
+	}{
+		{"outside org", "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeUser, GranteeID: "x"}, ErrGranteeNotFound},
+		{"unknown user", "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeUser, GranteeID: "ghost"}, ErrGranteeNotFound},
+		{"self", "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeUser, GranteeID: "p"}, ErrInvalidShare},
+		{"bad role", "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeUser, GranteeID: "a", Role: "owner"}, ErrInvalidShare},
+		{"expired", "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeUser, GranteeID: "a", ExpiresAt: &past}, ErrInvalidShare},
+		{"unknown group", "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeGroup, GranteeID: "nope"}, ErrShareGroupMissing},
+		{"personal doc to anyone", "solo", personal, GrantRequest{GranteeType: model.ShareGranteeUser, GranteeID: "x"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Grant(ctx, tt.actor, tt.res, tt.req)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharing_RevokeChecksResource(t *testing.T) {
+	svc, _, _ := newSharingFixture()
+	ctx := context.Background()
+
+	g, err := svc.Grant(ctx, "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeUser, GranteeID: "a"})
+	if err != nil {
+		t.Fatalf("Grant: %v", err)
+	}
+	other := ShareResource{Type: model.ShareResourceDocument, ID: "doc-9", OrgID: "org-a"}
+	if _, err := svc.Revoke(ctx, "p", other, g.ID); !errors.Is(err, ErrShareNotFound) {
+		t.Errorf("revoke via another resource: err = %v, want ErrShareNotFound", err)
+	}
+}
+
+func TestSharing_Groups(t *testing.T) {
+	svc, repo, audit := newSharingFixture()
+	ctx := context.Background()
+
+	if _, err := svc.CreateGroup(ctx, "a", "Litigation"); !errors.Is(err, ErrOrgForbidden) {
+		t.Errorf("associate create: err = %v, want ErrOrgForbidden", err)
+	}
+	group, err := svc.CreateGroup(ctx, "p", "Litigation")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if _, err := svc.CreateGroup(ctx, "p", "Litigation"); !errors.Is(err, ErrShareGroupExists) {
+		t.Errorf("duplicate: err = %v, want ErrShareGroupExists", err)
+	}
+
+	if err := svc.AddGroupMember(ctx, "p", group.ID, "x"); !errors.Is(err, ErrMemberNotFound) {
+		t.Errorf("add outsider: err = %v, want ErrMemberNotFound", err)
+	}
+	if err := svc.AddGroupMember(ctx, "p", group.ID, "a"); err != nil {
+		t.Fatalf("AddGroupMember: %v", err)
+	}
+
+	g, err := svc.Grant(ctx, "p", orgDoc, GrantRequest{GranteeType: model.ShareGranteeGroup, GranteeID: group.ID})
+	if err != nil {
+		t.Fatalf("Grant to group: %v", err)
+	}
+	if got := svc.GranteeUserIDs(ctx, g); len(got) != 1 || got[0] != "a" {
+		t.Errorf("GranteeUserIDs = %v, want [a]", got)
+	}
+
+	// Another organization cannot use the group.
+	foreign := ShareResource{Type: model.ShareResourceDocument, ID: "doc-b", OrgID: "org-b"}
+	if _, err := svc.Grant(ctx, "x", foreign, GrantRequest{GranteeType: model.ShareGranteeGroup, GranteeID: group.ID}); !errors.Is(err, ErrShareGroupMissing) {
+		t.Errorf("foreign group grant: err = %v, want ErrShareGroupMissing", err)
+	}
+
+	audit.actions = nil
+	if _, err := svc.DeleteGroup(ctx, "p", group.ID); err != nil {
+		t.Fatalf("DeleteGroup: %v", err)
+	}
+	if len(repo.grants) != 0 {
+		t.Errorf("%d grants left after deleting their group", len(repo.grants))
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != model.AuditShareRevoke {
+		t.Errorf("audit = %v, want one revoke", audit.actions)
+	}
+}
@@ -107,7 +107,7 @@ type tenantQuery struct {
 	DocIDs []string // IDs returned
 }
 
-func (m *tenantMockSearcher) SimilaritySearch(_ context.Context, _ []float32, _ int, _ float64, userID string, _ bool) ([]VectorSearchResult, error) {
+func (m *tenantMockSearcher) SimilaritySearch(_ context.Context, _ []float32, _ model.EmbeddingSpace, _ int, _ float64, userID string, _ bool) ([]VectorSearchResult, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -126,7 +126,7 @@ type tenantMockBM25 struct {
 	docsByUser map[string][]VectorSearchResult
 }
 
-func (m *tenantMockBM25) FullTextSearch(_ context.Context, _ string, _ int, userID string) ([]VectorSearchResult, error) {
+func (m *tenantMockBM25) FullTextSearch(_ context.Context, _ string, _ int, userID string, _ bool) ([]VectorSearchResult, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	return m.docsByUser[userID], nil
@@ -384,19 +384,19 @@ func TestTenantMockSearcher_ReturnsOnlyOwnedDocs(t *testing.T) {
 	ctx := context.Background()
 
 	// user-x should only get dx
-	results, _ := searcher.SimilaritySearch(ctx, nil, 10, 0.3, "user-x", false)
+	results, _ := searcher.SimilaritySearch(ctx, nil, model.EmbeddingSpace{}, 10, 0.3, "user-x", false)
 	if len(results) != 1 || results[0].Document.ID != "dx" {
 		t.Errorf("user-x got %d results, want 1 (dx)", len(results))
 	}
 
 	// user-y should only get dy
-	results, _ = searcher.SimilaritySearch(ctx, nil, 10, 0.3, "user-y", false)
+	results, _ = searcher.SimilaritySearch(ctx, nil, model.EmbeddingSpace{}, 10, 0.3, "user-y", false)
 	if len(results) != 1 || results[0].Document.ID != "dy" {
 		t.Errorf("user-y got %d results, want 1 (dy)", len(results))
 	}
 
 	// unknown user should get nothing
-	results, _ = searcher.SimilaritySearch(ctx, nil, 10, 0.3, "user-z", false)
+	results, _ = searcher.SimilaritySearch(ctx, nil, model.EmbeddingSpace{}, 10, 0.3, "user-z", false)
 	if len(results) != 0 {
 		t.Errorf("user-z got %d results, want 0", len(results))
 	}
@@ -415,12 +415,12 @@ func TestTenantMockBM25_ReturnsOnlyOwnedDocs(t *testing.T) {
 
 	ctx := context.Background()
 
-	results, _ := bm25.FullTextSearch(ctx, "query", 10, "user-x")
+	results, _ := bm25.FullTextSearch(ctx, "query", 10, "user-x", true)
 	if len(results) != 1 {
 		t.Errorf("user-x got %d results, want 1", len(results))
 	}
 
-	results, _ = bm25.FullTextSearch(ctx, "query", 10, "user-unknown")
+	results, _ = bm25.FullTextSearch(ctx, "query", 10, "user-unknown", true)
 	if len(results) != 0 {
 		t.Errorf("unknown user got %d results, want 0", len(results))
 	}
@@ -133,44 +133,68 @@ type OverageInfo struct {
 
 // UsageService provides usage tracking and limit enforcement.
 type UsageService struct {
-	repo UsageRepository
+	repo       UsageRepository
+	grace      GracePolicy
+	docCounter DocumentCounter                                  // optional — live documents_stored
+	tierFunc   func(ctx context.Context, userID string) string // optional — tier lookup for Enforce
+	events     UsageEventStore                                  // optional — threshold alert outbox
+	accountFn  func(ctx context.Context, userID string) string // optional — pooled usage account
 }
 
-// NewUsageService creates a new usage service.
+// NewUsageService creates a new usage service with the default grace policy.
 func NewUsageService(repo UsageRepository) *UsageService {
-	return &UsageService{repo: repo}
+	return &UsageService{repo: repo, grace: DefaultGracePolicy()}
+}
+
+// SetAccountResolver pools usage: counters for userID are kept under the
+// account fn returns (the organization for members), so every member of an
+// organization draws on the same limits.
+func (s *UsageService) SetAccountResolver(fn func(ctx context.Context, userID string) string) {
+	s.accountFn = fn
+}
+
+// account returns the usage_tracking key for userID.
+func (s *UsageService) account(ctx context.Context, userID string) string {
+	if s.accountFn == nil {
+		return userID
+	}
+	return s.accountFn(ctx, userID)
 }
 
 // IncrementUsage records one unit of usage for a metric.
 func (s *UsageService) IncrementUsage(ctx context.Context, userID, metric string) error {
-	if err := s.repo.Increment(ctx, userID, metric); err != nil {
+	if s.events != nil {
+		return s.incrementWithEvents(ctx, userID, metric, 1)
+	}
+	if err := s.repo.Increment(ctx, s.account(ctx, userID), metric); err != nil {
 		slog.Error("[Usage] Failed to increment", "user_id", userID, "metric", metric, "error", err)
 		return err
 	}
 	return nil
 }
 
+// IncrementUsageBy records amount units of usage for a metric, e.g. seconds
+// of transcribed audio.
+func (s *UsageService) IncrementUsageBy(ctx context.Context, userID, metric string, amount int64) error {
+	if amount <= 0 {
+		return nil
+	}
+	if s.events != nil {
+		return s.incrementWithEvents(ctx, userID, metric, amount)
+	}
+	if err := s.repo.IncrementBy(ctx, s.account(ctx, userID), metric, amount); err != nil {
+		slog.Error("[Usage] Failed to increment", "user_id", userID, "metric", metric, "amount", amount, "error", err)
+		return err
+	}
+	return nil
+}
+
 // CheckLimit returns whether the user is within their tier limit for a metric.
 // Returns (allowed, currentCount, limit, error).
+// Unlike CheckQuota, it applies no grace allowance.
 func (s *UsageService) CheckLimit(ctx context.Context, userID, metric, tier string) (bool, int64, int64, error) {
-	limits, ok := TierLimitMap[tier]
-	if !ok {
-		limits = TierLimitMap["free"]
-	}
-
-	var limit int64
-	switch metric {
-	case "aegis_queries":
-		limit = limits.AegisQueries
-	case "documents_stored":
-		limit = limits.DocumentsStored
-	case "voice_minutes":
-		limit = limits.VoiceMinutes
-	case "api_calls":
-		limit = limits.APICalls
-	case MetricTokensUsed:
-		limit = limits.TokenBudget
-	default:
+	limit, known := tierLimit(tier, metric)
+	if !known {
 		return true, 0, 0, nil // unknown metric, allow
 	}
 
@@ -178,7 +202,7 @@ func (s *UsageService) CheckLimit(ctx context.Context, userID, metric, tier stri
 		return true, 0, -1, nil // unlimited
 	}
 
-	count, err := s.repo.GetUsage(ctx, userID, metric)
+	count, err := s.currentUsage(ctx, userID, metric)
 	if err != nil {
 		return false, 0, limit, err
 	}
@@ -194,7 +218,7 @@ func (s *UsageService) GetUsageReport(ctx context.Context, userID, tier string)
 		tier = "free"
 	}
 
-	records, err := s.repo.GetAllUsage(ctx, userID)
+	records, err := s.repo.GetAllUsage(ctx, s.account(ctx, userID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get usage: %w", err)
 	}
@@ -204,6 +228,14 @@ func (s *UsageService) GetUsageReport(ctx context.Context, userID, tier string)
 	for _, rec := range records {
 		usageMap[rec.Metric] = rec.Count
 	}
+	usageMap[MetricVoiceMinutes] += (usageMap[MetricVoiceSeconds] + 59) / 60
+	if s.docCounter != nil {
+		if n, err := s.docCounter.CountActiveByUser(ctx, userID); err == nil {
+			usageMap[MetricDocumentsStored] = n
+		} else {
+			slog.Warn("[Usage] document count failed", "user_id", userID, "error", err)
+		}
+	}
 
 	// Build response
 	now := time.Now().UTC()
@@ -211,11 +243,11 @@ func (s *UsageService) GetUsageReport(ctx context.Context, userID, tier string)
 	periodEnd := periodStart.AddDate(0, 1, 0)
 
 	metrics := map[string]int64{
-		"aegis_queries":    limits.AegisQueries,
-		"documents_stored": limits.DocumentsStored,
-		"voice_minutes":    limits.VoiceMinutes,
-		"api_calls":        limits.APICalls,
-		MetricTokensUsed:   limits.TokenBudget,
+		MetricAegisQueries:    limits.AegisQueries,
+		MetricDocumentsStored: limits.DocumentsStored,
+		MetricVoiceMinutes:    limits.VoiceMinutes,
+		MetricAPICalls:        limits.APICalls,
+		MetricTokensUsed:      limits.TokenBudget,
 	}
 
 	usage := map[string]MetricUsage{}
@@ -269,7 +301,11 @@ func (s *UsageService) IncrementTokenUsage(ctx context.Context, userID string, t
 	if tokens <= 0 {
 		return nil
 	}
-	if err := s.repo.IncrementBy(ctx, userID, MetricTokensUsed, tokens); err != nil {
+	if s.events != nil {
+		if err := s.incrementWithEvents(ctx, userID, MetricTokensUsed, tokens); err != nil {
+			return err
+		}
+	} else if err := s.repo.IncrementBy(ctx, s.account(ctx, userID), MetricTokensUsed, tokens); err != nil {
 		slog.Error("[Usage] Failed to increment tokens", "user_id", userID, "tokens", tokens, "error", err)
 		return err
 	}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Usage event types.
+const (
+	// EventUsageThreshold fires when usage reaches 80% or 100% of a limit.
+	EventUsageThreshold = "usage.threshold"
+	// EventUsageOverage fires when usage first exceeds a limit (grace or
+	// otherwise), for billing.
+	EventUsageOverage = "usage.overage"
+)
+
+// UsageAlertThresholds are the percentages of a limit that raise an alert.
+var UsageAlertThresholds = []int{80, 100}
+
+// UsageEvent is a threshold alert or overage event from the usage outbox.
+// Each (user, metric, type, threshold) fires at most once per billing period.
+type UsageEvent struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	UserID      string    `json:"userId"` // usage account: the user, or their organization
+	Metric      string    `json:"metric"`
+	Tier        string    `json:"tier"`
+	Threshold   int       `json:"threshold,omitempty"` // percent; 0 for overage
+	Used        int64     `json:"used"`
+	Limit       int64     `json:"limit"`
+	PeriodStart time.Time `json:"periodStart"`
+	CreatedAt   time.Time `json:"createdAt"`
+
+	Attempts    int      `json:"-"`
+	DeliveredTo []string `json:"-"` // sink names that already accepted it
+}
+
+// UsageEventStore persists usage increments together with the events they
+// raise (transactional outbox). Implemented by repository.UsageRepo.
+type UsageEventStore interface {
+	// IncrementWithEvents adds amount to metric and, in the same transaction,
+	// stores the events crossed(before, after) returns. Events already stored
+	// for the period are ignored.
+	IncrementWithEvents(ctx context.Context, userID, metric string, amount int64,
+		crossed func(before, after int64) []UsageEvent) error
+	// ClaimUsageEvents leases up to limit due events for delivery; a claimed
+	// event is not returned again until lease expires.
+	ClaimUsageEvents(ctx context.Context, limit int, lease time.Duration) ([]UsageEvent, error)
+	MarkUsageEventDelivered(ctx context.Context, id string, sinks []string) error
+	// MarkUsageEventFailed records a failed attempt. dead stops retries.
+	MarkUsageEventFailed(ctx context.Context, id string, sinks []string, lastErr string, retryAt time.Time, dead bool) error
+}
+
+// UsageEventSink delivers usage events to one destination.
+type UsageEventSink interface {
+	// Name identifies the sink in the outbox's delivered_to list; keep it
+	// stable across deploys.
+	Name() string
+	Send(ctx context.Context, ev UsageEvent) error
+}
+
+// SetEventOutbox enables threshold alerts: increments go through store and
+// record 80%/100%/overage events. Requires SetTierResolver for the limits.
+func (s *UsageService) SetEventOutbox(store UsageEventStore) {
+	s.events = store
+}
+
+// incrementWithEvents records amount of metric through the outbox store.
+func (s *UsageService) incrementWithEvents(ctx context.Context, userID, metric string, amount int64) error {
+	tier := "free"
+	if s.tierFunc != nil {
+		tier = s.tierFunc(ctx, userID)
+	}
+	if _, ok := TierLimitMap[tier]; !ok {
+		tier = "free"
+	}
+
+	// Voice is recorded in seconds but limited in minutes.
+	limitMetric, unit := metric, int64(1)
+	if metric == MetricVoiceSeconds {
+		limitMetric, unit = MetricVoiceMinutes, 60
+	}
+	limit, _ := tierLimit(tier, limitMetric)
+
+	// Events are raised for the pooled account, so an organization is
+	// alerted once rather than once per member.
+	account := s.account(ctx, userID)
+	err := s.events.IncrementWithEvents(ctx, account, metric, amount, func(before, after int64) []UsageEvent {
+		return thresholdEvents(account, tier, limitMetric, (before+unit-1)/unit, (after+unit-1)/unit, limit)
+	})
+	if err != nil {
+		slog.Error("[Usage] Failed to increment", "user_id", userID, "metric", metric, "amount", amount, "error", err)
+		return err
+	}
+	return nil
+}
+
+// thresholdEvents returns the events raised by usage moving from before to
+// after against limit. Unlimited (-1) and zero limits raise none.
+func thresholdEvents(userID, tier, metric string, before, after, limit int64) []UsageEvent {
+	if limit <= 0 || after <= before {
+		return nil
+	}
+	var events []UsageEvent
+	for _, pct := range UsageAlertThresholds {
+		mark := (limit*int64(pct) + 99) / 100
+		if before < mark && after >= mark {
+			events = append(events, UsageEvent{
+				Type: EventUsageThreshold, UserID: userID, Metric: metric, Tier: tier,
+				Threshold: pct, Used: after, Limit: limit,
+			})
+		}
+	}
+	if before <= limit && after > limit {
+		events = append(events, UsageEvent{
+			Type: EventUsageOverage, UserID: userID, Metric: metric, Tier: tier,
+			Used: after, Limit: limit,
+		})
+	}
+	return events
+}
+
+// UsageEventDispatcher delivers outbox events to every sink, retrying failed
+// sinks with exponential backoff. Sinks that accepted an event are not sent it
+// again, so a retry only repeats the failures. Safe to run on every instance:
+// claims are leased, so each event is in flight on one instance at a time.
+type UsageEventDispatcher struct {
+	store UsageEventStore
+	sinks []UsageEventSink
+
+	BatchSize   int
+	MaxAttempts int           // attempts before an event is marked failed
+	Lease       time.Duration // how long a claimed event is hidden from other dispatchers
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	now         func() time.Time
+}
+
+// NewUsageEventDispatcher creates a dispatcher with default retry settings.
+func NewUsageEventDispatcher(store UsageEventStore, sinks ...UsageEventSink) *UsageEventDispatcher {
+	return &UsageEventDispatcher{
+		store:       store,
+		sinks:       sinks,
+		BatchSize:   50,
+		MaxAttempts: 8,
+		Lease:       2 * time.Minute,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  time.Hour,
+		now:         time.Now,
+	}
+}
+
+// Run dispatches due events every interval until ctx is cancelled.
+func (d *UsageEventDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("[UsageEvents] dispatch failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce delivers one batch of due events and returns how many were
+// fully delivered.
+func (d *UsageEventDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	events, err := d.store.ClaimUsageEvents(ctx, d.BatchSize, d.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("service.DispatchOnce: %w", err)
+	}
+
+	delivered := 0
+	for _, ev := range events {
+		if d.deliver(ctx, ev) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// deliver sends ev to each sink that has not accepted it yet and records the
+// outcome. It reports whether every sink has now accepted it.
+func (d *UsageEventDispatcher) deliver(ctx context.Context, ev UsageEvent) bool {
+	done := slices.Clone(ev.DeliveredTo)
+	var errs []error
+	for _, sink := range d.sinks {
+		if slices.Contains(done, sink.Name()) {
+			continue
+		}
+		if err := sink.Send(ctx, ev); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		done = append(done, sink.Name())
+	}
+
+	if len(errs) == 0 {
+		if err := d.store.MarkUsageEventDelivered(ctx, ev.ID, done); err != nil {
+			slog.Error("[UsageEvents] mark delivered failed", "event_id", ev.ID, "error", err)
+		}
+		return true
+	}
+
+	sendErr := errors.Join(errs...)
+	dead := ev.Attempts >= d.MaxAttempts
+	retryAt := d.now().Add(d.backoff(ev.Attempts))
+	if dead {
+		slog.Error("[UsageEvents] giving up on event", "event_id", ev.ID, "type", ev.Type,
+			"user_id", ev.UserID, "attempts", ev.Attempts, "error", sendErr)
+	} else {
+		slog.Warn("[UsageEvents] delivery failed, will retry", "event_id", ev.ID,
+			"attempts", ev.Attempts, "retry_at", retryAt, "error", sendErr)
+	}
+	if err := d.store.MarkUsageEventFailed(ctx, ev.ID, done, sendErr.Error(), retryAt, dead); err != nil {
+		slog.Error("[UsageEvents] recording failed attempt failed", "event_id", ev.ID, "error", err)
+	}
+	return false
+}
+
+// backoff returns the retry delay after the given number of attempts.
+func (d *UsageEventDispatcher) backoff(attempts int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempts && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxBackoff {
+		delay = d.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memEventStore implements UsageEventStore in memory, deduplicating events
+// like the usage_events unique index.
+type memEventStore struct {
+	counts map[string]int64
+	events []*UsageEvent
+	failed map[string]bool      // event IDs parked as dead
+	retry  map[string]time.Time // last requested retry time per event
+}
+
+func newMemEventStore() *memEventStore {
+	return &memEventStore{counts: map[string]int64{}, failed: map[string]bool{}, retry: map[string]time.Time{}}
+}
+
+func (m *memEventStore) IncrementWithEvents(_ context.Context, userID, metric string, amount int64,
+	crossed func(before, after int64) []UsageEvent) error {
+	key := userID + ":" + metric
+	m.counts[key] += amount
+	for _, ev := range crossed(m.counts[key]-amount, m.counts[key]) {
+		dup := slices.ContainsFunc(m.events, func(e *UsageEvent) bool {
+			return e.UserID == ev.UserID && e.Metric == ev.Metric && e.Type == ev.Type && e.Threshold == ev.Threshold
+		})
+		if !dup {
+			ev.ID = fmt.Sprintf("ev-%d", len(m.events)+1)
+			m.events = append(m.events, &ev)
+		}
+	}
+	return nil
+}
+
+// ClaimUsageEvents returns every live event regardless of retry time, so
+// tests can drive retries by calling DispatchOnce again.
+func (m *memEventStore) ClaimUsageEvents(_ context.Context, limit int, _ time.Duration) ([]UsageEvent, error) {
+	var out []UsageEvent
+	for _, ev := range m.events {
+		if len(out) == limit {
+			break
+		}
+		if m.failed[ev.ID] {
+			continue
+		}
+		ev.Attempts++
+		out = append(out, *ev)
+	}
+	return out, nil
+}
+
+func (m *memEventStore) find(id string) *UsageEvent {
+	for _, ev := range m.events {
+		if ev.ID == id {
+			return ev
+		}
+	}
+	return nil
+}
+
+func (m *memEventStore) MarkUsageEventDelivered(_ context.Context, id string, sinks []string) error {
+	ev := m.find(id)
+	ev.DeliveredTo = sinks
+	m.events = slices.DeleteFunc(m.events, func(e *UsageEvent) bool { return e.ID == id })
+	return nil
+}
+
+func (m *memEventStore) MarkUsageEventFailed(_ context.Context, id string, sinks []string, _ string, retryAt time.Time, dead bool) error {
+	m.find(id).DeliveredTo = sinks
+	m.retry[id] = retryAt
+	m.failed[id] = dead
+	return nil
+}
+
+// recordingSink collects delivered events and fails the first failN sends.
+type recordingSink struct {
+	name  string
+	failN int
+	got   []UsageEvent
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Send(_ context.Context, ev UsageEvent) error {
+	if s.failN > 0 {
+		s.failN--
+		return errors.New("unavailable")
+	}
+	s.got = append(s.got, ev)
+	return nil
+}
+
+func TestThresholdEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after int64
+		want          []string
+	}{
+		{"below 80%", 10, 79, nil},
+		{"crosses 80%", 79, 80, []string{"usage.threshold/80"}},
+		{"crosses 100%", 99, 100, []string{"usage.threshold/100"}},
+		{"first overage", 100, 101, []string{"usage.overage/0"}},
+		{"jumps every mark", 50, 150, []string{"usage.threshold/80", "usage.threshold/100", "usage.overage/0"}},
+		{"already over", 120, 130, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, ev := range thresholdEvents("u1", "starter", MetricAegisQueries, tt.before, tt.after, 100) {
+				got = append(got, fmt.Sprintf("%s/%d", ev.Type, ev.Threshold))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if evs := thresholdEvents("u1", "enterprise", MetricAegisQueries, 0, 1e6, -1); evs != nil {
+		t.Errorf("unlimited tier raised %v", evs)
+	}
+}
+
+func TestIncrementUsage_WritesOutboxOncePerPeriod(t *testing.T) {
+	store := newMemEventStore()
+	svc := NewUsageService(newStubUsageRepo())
+	svc.SetTierResolver(func(context.Context, string) string { return "free" }) // 25 queries
+	svc.SetEventOutbox(store)
+	ctx := context.Background()
+
+	for i := 0; i < 30; i++ {
+		if err := svc.IncrementUsage(ctx, "u1", MetricAegisQueries); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []string
+	for _, ev := range store.events {
+		got = append(got, fmt.Sprintf("%s/%d@%d", ev.Type, ev.Threshold, ev.Used))
+	}
+	want := []string{"usage.threshold/80@20", "usage.threshold/100@25", "usage.overage/0@26"}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestIncrementUsage_VoiceSecondsAlertInMinutes(t *testing.T) {
+	store := newMemEventStore()
+	svc := NewUsageService(newStubUsageRepo())
+	svc.SetTierResolver(func(context.Context, string) string { return "starter" }) // 60 minutes
+	svc.SetEventOutbox(store)
+
+	svc.IncrementUsageBy(context.Background(), "u1", MetricVoiceSeconds, 47*60+1) // 48 minutes
+	if len(store.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(store.events))
+	}
+	if ev := store.events[0]; ev.Metric != MetricVoiceMinutes || ev.Used != 48 || ev.Threshold != 80 {
+		t.Errorf("event = %+v", ev)
+	}
+}
+
+func TestDispatcher_RetriesOnlyFailedSinks(t *testing.T) {
+	store := newMemEventStore()
+	store.events = []*UsageEvent{{ID: "ev-1", Type: EventUsageThreshold, UserID: "u1", Threshold: 80}}
+	webhook := &recordingSink{name: "webhook"}
+	email := &recordingSink{name: "email", failN: 1}
+	d := NewUsageEventDispatcher(store, webhook, email)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	if n, err := d.DispatchOnce(context.Background()); err != nil || n != 0 {
+		t.Fatalf("first dispatch = %d, %v; want 0 delivered", n, err)
+	}
+	if got := store.retry["ev-1"]; !got.Equal(now.Add(d.BaseBackoff)) {
+		t.Errorf("retry at %v, want %v", got, now.Add(d.BaseBackoff))
+	}
+	if !slices.Equal(store.find("ev-1").DeliveredTo, []string{"webhook"}) {
+		t.Errorf("delivered_to = %v", store.find("ev-1").DeliveredTo)
+	}
+
+	if n, _ := d.DispatchOnce(context.Background()); n != 1 {
+		t.Fatalf("second dispatch delivered %d, want 1", n)
+	}
+	if len(webhook.got) != 1 || len(email.got) != 1 {
+		t.Errorf("webhook got %d, email got %d; want 1 each", len(webhook.got), len(email.got))
+	}
+}
+
+func TestDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	store := newMemEventStore()
+	store.events = []*UsageEvent{{ID: "ev-1", Type: EventUsageOverage, UserID: "u1"}}
+	d := NewUsageEventDispatcher(store, &recordingSink{name: "webhook", failN: 100})
+	d.MaxAttempts = 3
+
+	for i := 0; i < 3; i++ {
+		d.DispatchOnce(context.Background())
+	}
+	if !store.failed["ev-1"] {
+		t.Error("event should be parked as failed after MaxAttempts")
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := NewUsageEventDispatcher(nil)
+	for attempts, want := range map[int]time.Duration{
+		1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 20: time.Hour,
+	} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestWebhookSink_SignsPayload(t *testing.T) {
+	var gotSig, gotTS, gotDelivery string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-RAGbox-Signature")
+		gotTS = r.Header.Get("X-RAGbox-Timestamp")
+		gotDelivery = r.Header.Get("X-RAGbox-Delivery")
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, "s3cret")
+	if err := sink.Send(context.Background(), UsageEvent{ID: "ev-1", Type: EventUsageOverage, UserID: "u1"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if want := SignWebhookPayload([]byte("s3cret"), gotTS, gotBody); gotSig != want {
+		t.Errorf("signature = %q, want %q", gotSig, want)
+	}
+	if gotDelivery != "ev-1" || !strings.Contains(string(gotBody), `"type":"usage.overage"`) {
+		t.Errorf("delivery = %q, body = %s", gotDelivery, gotBody)
+	}
+}
+
+func TestWebhookSink_Non2xxIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	if err := NewWebhookSink(srv.URL, "k").Send(context.Background(), UsageEvent{ID: "ev-1"}); err == nil {
+		t.Error("expected error for 502")
+	}
+}
+
+func TestEmailSink_SendsThresholdAlertsOnly(t *testing.T) {
+	var sent []string
+	sink := NewSMTPEmailSink("smtp.example.com", 587, "", "", "alerts@ragbox.co",
+		func(context.Context, string) (string, error) { return "user@example.com", nil })
+	sink.send = func(_ string, _ smtp.Auth, _ string, to []string, msg []byte) error {
+		sent = append(sent, to[0]+"|"+string(msg))
+		return nil
+	}
+
+	ctx := context.Background()
+	sink.Send(ctx, UsageEvent{ID: "ev-1", Type: EventUsageOverage})
+	if err := sink.Send(ctx, UsageEvent{
+		ID: "ev-2", Type: EventUsageThreshold, Metric: MetricAegisQueries, Threshold: 80, Used: 20, Limit: 25, Tier: "free",
+	}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(sent))
+	}
+	if !strings.HasPrefix(sent[0], "user@example.com|") || !strings.Contains(sent[0], "Subject: RAGbox: You've used 80% of your AEGIS queries") {
+		t.Errorf("email = %q", sent[0])
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSink POSTs usage events as JSON, signed with HMAC-SHA256 so the
+// receiver (billing) can verify them. Headers:
+//
+//	X-RAGbox-Event:     event type
+//	X-RAGbox-Delivery:  event ID — stable across retries, use it to dedupe
+//	X-RAGbox-Timestamp: unix seconds
+//	X-RAGbox-Signature: sha256=<hex HMAC of "<timestamp>.<body>">
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookSink creates a webhook sink posting to url.
+func NewWebhookSink(url, secret string) *WebhookSink {
+	return &WebhookSink{url: url, secret: []byte(secret), client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name implements UsageEventSink.
+func (s *WebhookSink) Name() string { return "webhook" }
+
+// Send implements UsageEventSink. Any non-2xx response is an error.
+func (s *WebhookSink) Send(ctx context.Context, ev UsageEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("service.WebhookSink.Send: marshal: %w", err)
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("service.WebhookSink.Send: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-RAGbox-Event", ev.Type)
+	req.Header.Set("X-RAGbox-Delivery", ev.ID)
+	req.Header.Set("X-RAGbox-Timestamp", ts)
+	req.Header.Set("X-RAGbox-Signature", SignWebhookPayload(s.secret, ts, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("service.WebhookSink.Send: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("service.WebhookSink.Send: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the X-RAGbox-Signature value for body sent at
+// timestamp ts.
+func SignWebhookPayload(secret []byte, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// EmailSink emails threshold alerts to the user over SMTP. Overage events
+// are billing-only and are not emailed.
+type EmailSink struct {
+	addr      string
+	from      string
+	auth      smtp.Auth
+	recipient func(ctx context.Context, accountID string) (string, error)
+	send      func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPEmailSink creates an email sink. recipient resolves the email
+// address for a usage account (a user, or an organization's billing contact).
+// username may be empty for unauthenticated relays.
+func NewSMTPEmailSink(host string, port int, username, password, from string,
+	recipient func(ctx context.Context, userID string) (string, error)) *EmailSink {
+	s := &EmailSink{
+		addr:      net.JoinHostPort(host, strconv.Itoa(port)),
+		from:      from,
+		recipient: recipient,
+		send:      smtp.SendMail,
+	}
+	if username != "" {
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return s
+}
+
+// Name implements UsageEventSink.
+func (s *EmailSink) Name() string { return "email" }
+
+// Send implements UsageEventSink.
+func (s *EmailSink) Send(ctx context.Context, ev UsageEvent) error {
+	if ev.Type != EventUsageThreshold {
+		return nil
+	}
+	to, err := s.recipient(ctx, ev.UserID)
+	if err != nil {
+		return fmt.Errorf("service.EmailSink.Send: recipient: %w", err)
+	}
+	if to == "" {
+		return nil // nobody to tell; retrying will not help
+	}
+	if err := s.send(s.addr, s.auth, s.from, []string{to}, usageAlertEmail(s.from, to, ev)); err != nil {
+		return fmt.Errorf("service.EmailSink.Send: %w", err)
+	}
+	return nil
+}
+
+// usageAlertEmail builds the RFC 5322 message for a threshold alert.
+func usageAlertEmail(from, to string, ev UsageEvent) []byte {
+	what := metricLabel(ev.Metric)
+	subject := fmt.Sprintf("You've used %d%% of your %s", ev.Threshold, what)
+	body := fmt.Sprintf("You've used %d of %d %s on the %s plan.\r\n", ev.Used, ev.Limit, what, ev.Tier)
+	if ev.Threshold >= 100 {
+		body += "Further use may be refused until the next billing period. Upgrade your plan to continue.\r\n"
+	} else {
+		body += "Upgrade your plan before you reach the limit to avoid interruption.\r\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: RAGbox: %s\r\n", subject)
+	fmt.Fprintf(&b, "Message-ID: <%s@ragbox.co>\r\n", ev.ID)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(body)
+	return []byte(b.String())
+}
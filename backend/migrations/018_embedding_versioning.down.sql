@@ -0,0 +1,7 @@
+-- Rollback: 018 embedding model versioning
+DROP TABLE IF EXISTS document_chunk_embeddings_shadow;
+DROP TABLE IF EXISTS reembed_jobs;
+DROP TABLE IF EXISTS embedding_spaces;
+DROP INDEX IF EXISTS idx_document_chunks_embedding_space;
+ALTER TABLE document_chunks DROP COLUMN IF EXISTS embedding_version;
+ALTER TABLE document_chunks DROP COLUMN IF EXISTS embedding_model;
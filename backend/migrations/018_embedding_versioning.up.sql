@@ -0,0 +1,72 @@
+-- 018: Embedding model versioning and online re-embedding.
+-- Every chunk records the embedding space (model + version) that produced its
+-- vector, so retrieval never compares vectors from different models.
+-- Re-embedding writes new vectors to a shadow table; cutover swaps them into
+-- document_chunks.embedding inside a single transaction.
+-- Idempotent: safe to run multiple times.
+
+ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS embedding_model TEXT;
+ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS embedding_version INT;
+
+-- Existing vectors were all produced by text-embedding-004 (generation 1).
+UPDATE document_chunks
+SET embedding_model = 'text-embedding-004', embedding_version = 1
+WHERE embedding IS NOT NULL AND embedding_model IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_document_chunks_embedding_space
+    ON document_chunks(embedding_model, embedding_version);
+
+-- Embedding spaces: exactly one is active and used for retrieval.
+-- version is a global generation counter, incremented for every re-embed.
+CREATE TABLE IF NOT EXISTS embedding_spaces (
+    model        TEXT NOT NULL,
+    version      INT NOT NULL,
+    dimensions   INT NOT NULL,
+    status       TEXT NOT NULL DEFAULT 'building'
+                 CHECK (status IN ('building', 'active', 'retired')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    activated_at TIMESTAMPTZ,
+    PRIMARY KEY (model, version)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_embedding_spaces_single_active
+    ON embedding_spaces((true)) WHERE status = 'active';
+
+INSERT INTO embedding_spaces (model, version, dimensions, status, activated_at)
+SELECT 'text-embedding-004', 1, 768, 'active', now()
+WHERE NOT EXISTS (SELECT 1 FROM embedding_spaces WHERE status = 'active')
+ON CONFLICT (model, version) DO NOTHING;
+
+-- Re-embedding jobs with progress tracking (keyset cursor on chunk id).
+CREATE TABLE IF NOT EXISTS reembed_jobs (
+    id               TEXT PRIMARY KEY,
+    target_model     TEXT NOT NULL,
+    target_version   INT NOT NULL,
+    dimensions       INT NOT NULL,
+    status           TEXT NOT NULL DEFAULT 'running'
+                     CHECK (status IN ('running', 'completed', 'failed', 'cancelled')),
+    total_chunks     INT NOT NULL DEFAULT 0,
+    processed_chunks INT NOT NULL DEFAULT 0,
+    last_chunk_id    TEXT NOT NULL DEFAULT '',
+    error            TEXT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at     TIMESTAMPTZ,
+    FOREIGN KEY (target_model, target_version) REFERENCES embedding_spaces(model, version)
+);
+
+-- Only one job may run at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reembed_jobs_single_running
+    ON reembed_jobs((true)) WHERE status = 'running';
+
+-- Shadow vectors produced by a running job. content_hash pins the vector to the
+-- chunk content it was computed from; chunks re-ingested mid-job are re-embedded
+-- during cutover catch-up.
+CREATE TABLE IF NOT EXISTS document_chunk_embeddings_shadow (
+    job_id       TEXT NOT NULL REFERENCES reembed_jobs(id) ON DELETE CASCADE,
+    chunk_id     TEXT NOT NULL REFERENCES document_chunks(id) ON DELETE CASCADE,
+    content_hash TEXT NOT NULL,
+    embedding    vector NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (job_id, chunk_id)
+);
@@ -0,0 +1,3 @@
+-- Rollback: 019 chunk embedding input hash
+DROP INDEX IF EXISTS idx_document_chunks_input_hash;
+ALTER TABLE document_chunks DROP COLUMN IF EXISTS embedding_input_hash;
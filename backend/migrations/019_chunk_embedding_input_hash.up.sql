@@ -0,0 +1,15 @@
+-- 019: Content-hash vector reuse for re-ingest.
+-- embedding_input_hash is the SHA-256 of the exact text sent to the embedding
+-- model (contextual prefix included). When a document is reprocessed, chunks
+-- whose hash already has a vector in the active embedding space reuse it
+-- instead of calling the embedding API again.
+-- Idempotent: safe to run multiple times.
+
+ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS embedding_input_hash TEXT;
+
+UPDATE document_chunks
+SET embedding_input_hash = encode(sha256(convert_to(content, 'UTF8')), 'hex')
+WHERE embedding_input_hash IS NULL AND embedding IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_document_chunks_input_hash
+    ON document_chunks(embedding_input_hash, embedding_model, embedding_version);
@@ -0,0 +1,4 @@
+-- Rollback: 020 usage events outbox
+DROP INDEX IF EXISTS idx_usage_events_pending;
+DROP INDEX IF EXISTS idx_usage_events_dedup;
+DROP TABLE IF EXISTS usage_events;
@@ -0,0 +1,31 @@
+-- 020: Usage threshold alerts and billing events (transactional outbox).
+-- Rows are written in the same transaction as the usage_tracking increment
+-- that crosses 80% / 100% of a tier limit, or first exceeds it (overage).
+-- The unique index makes each event fire at most once per billing period;
+-- a dispatcher delivers pending rows to the configured sinks with retries.
+-- Idempotent: safe to run multiple times.
+
+CREATE TABLE IF NOT EXISTS usage_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id TEXT NOT NULL,
+  event_type VARCHAR(50) NOT NULL,          -- 'usage.threshold' | 'usage.overage'
+  metric VARCHAR(50) NOT NULL,
+  threshold INT NOT NULL DEFAULT 0,         -- percent of limit (80, 100); 0 for overage
+  tier VARCHAR(50) NOT NULL,
+  used BIGINT NOT NULL,
+  usage_limit BIGINT NOT NULL,
+  period_start TIMESTAMP NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 'pending' | 'delivered' | 'failed'
+  attempts INT NOT NULL DEFAULT 0,
+  delivered_to TEXT[] NOT NULL DEFAULT '{}', -- sinks that already accepted the event
+  last_error TEXT,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  delivered_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_usage_events_dedup
+  ON usage_events(user_id, metric, event_type, threshold, period_start);
+
+CREATE INDEX IF NOT EXISTS idx_usage_events_pending
+  ON usage_events(next_attempt_at) WHERE status = 'pending';
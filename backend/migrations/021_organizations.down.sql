@@ -0,0 +1,24 @@
+-- Rollback: 021 organizations
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'mercury_threads') THEN
+    UPDATE mercury_threads SET tenant_id = 'default' WHERE tenant_id <> 'default';
+  END IF;
+END $$;
+
+DROP TRIGGER IF EXISTS trg_folders_org_id ON folders;
+DROP TRIGGER IF EXISTS trg_vaults_org_id ON vaults;
+DROP TRIGGER IF EXISTS trg_documents_org_id ON documents;
+DROP FUNCTION IF EXISTS default_owner_org_id();
+
+DROP INDEX IF EXISTS idx_folders_org;
+ALTER TABLE folders DROP COLUMN IF EXISTS org_id;
+DROP INDEX IF EXISTS idx_vaults_org;
+ALTER TABLE vaults DROP COLUMN IF EXISTS org_id;
+DROP INDEX IF EXISTS idx_documents_org_deletion;
+ALTER TABLE documents DROP COLUMN IF EXISTS org_id;
+
+DROP FUNCTION IF EXISTS user_org_id(TEXT);
+DROP TABLE IF EXISTS organization_invites;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,97 @@
+-- 021: Organizations (firms) with member roles, invitations, org-owned
+-- vaults/documents/folders and pooled usage.
+--
+-- A user belongs to at most one organization. Users without a membership
+-- work in a personal scope whose org ID is 'personal:<user_id>'; no row in
+-- organizations exists for it. user_org_id() resolves either case and is
+-- what repository queries scope by.
+-- Idempotent: safe to run multiple times.
+
+CREATE TABLE IF NOT EXISTS organizations (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  subscription_tier TEXT NOT NULL DEFAULT 'free', -- pooled limits for all members
+  created_by TEXT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+  org_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  user_id TEXT NOT NULL REFERENCES users(id),
+  role "UserRole" NOT NULL DEFAULT 'Associate',
+  joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (org_id, user_id)
+);
+
+-- One organization per user.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_org_members_user
+  ON organization_members(user_id);
+
+CREATE TABLE IF NOT EXISTS organization_invites (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  email TEXT NOT NULL,
+  role "UserRole" NOT NULL DEFAULT 'Associate',
+  token_hash TEXT NOT NULL UNIQUE, -- SHA-256 hex; the token itself is never stored
+  invited_by TEXT NOT NULL REFERENCES users(id),
+  expires_at TIMESTAMPTZ NOT NULL,
+  accepted_by TEXT REFERENCES users(id),
+  accepted_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_invites_org
+  ON organization_invites(org_id) WHERE accepted_at IS NULL;
+
+-- The organization a user's queries are scoped to.
+CREATE OR REPLACE FUNCTION user_org_id(uid TEXT) RETURNS TEXT
+LANGUAGE sql STABLE AS $$
+  SELECT COALESCE(
+    (SELECT org_id FROM organization_members WHERE user_id = uid),
+    'personal:' || uid
+  )
+$$;
+
+-- Org ownership for content. Rows without one belong to their owner's
+-- organization (their personal scope on the first run).
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS org_id TEXT;
+UPDATE documents SET org_id = user_org_id(user_id) WHERE org_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_documents_org_deletion ON documents(org_id, deletion_status);
+
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS org_id TEXT;
+UPDATE vaults SET org_id = user_org_id(user_id) WHERE org_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_vaults_org ON vaults(org_id);
+
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS org_id TEXT;
+UPDATE folders SET org_id = user_org_id(user_id) WHERE org_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_folders_org ON folders(org_id);
+
+-- The web app inserts documents, vaults and folders through Prisma, whose
+-- schema has no org_id; default it to the owner's organization so those
+-- rows stay visible to queries scoped by user_org_id().
+CREATE OR REPLACE FUNCTION default_owner_org_id() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+  NEW.org_id := COALESCE(NULLIF(NEW.org_id, ''), user_org_id(NEW.user_id));
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_documents_org_id ON documents;
+CREATE TRIGGER trg_documents_org_id BEFORE INSERT ON documents
+  FOR EACH ROW EXECUTE FUNCTION default_owner_org_id();
+DROP TRIGGER IF EXISTS trg_vaults_org_id ON vaults;
+CREATE TRIGGER trg_vaults_org_id BEFORE INSERT ON vaults
+  FOR EACH ROW EXECUTE FUNCTION default_owner_org_id();
+DROP TRIGGER IF EXISTS trg_folders_org_id ON folders;
+CREATE TRIGGER trg_folders_org_id BEFORE INSERT ON folders
+  FOR EACH ROW EXECUTE FUNCTION default_owner_org_id();
+
+-- Mercury threads were all written with the placeholder tenant 'default'.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'mercury_threads') THEN
+    UPDATE mercury_threads SET tenant_id = 'personal:' || user_id WHERE tenant_id = 'default';
+  END IF;
+END $$;
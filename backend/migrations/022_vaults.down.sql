@@ -0,0 +1,8 @@
+-- Rollback: 022 vaults
+DROP TRIGGER IF EXISTS trg_documents_vault_stats ON documents;
+DROP FUNCTION IF EXISTS documents_vault_stats();
+DROP FUNCTION IF EXISTS refresh_vault_stats(TEXT);
+DROP FUNCTION IF EXISTS vault_queryable(TEXT, TEXT);
+DROP FUNCTION IF EXISTS vault_accessible(TEXT, TEXT);
+DROP INDEX IF EXISTS idx_documents_vault_active;
+ALTER TABLE vaults DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,75 @@
+-- 022: First-class vaults — archiving, status-based query access and
+-- document_count / storage_used_bytes kept current by trigger.
+--
+-- Vault status decides who may see and query a vault's documents:
+--   open   — every member of the vault's organization
+--   closed — the vault owner and the organization's Partners
+--   secure — the vault owner only
+-- Archived vaults keep their documents but drop out of listings and retrieval.
+-- Idempotent: safe to run multiple times.
+
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_documents_vault_active
+  ON documents(vault_id) WHERE deletion_status = 'Active';
+
+-- Whether uid may see the vault (archived or not). Documents outside any
+-- vault are governed by org scope alone.
+CREATE OR REPLACE FUNCTION vault_accessible(vid TEXT, uid TEXT) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+  SELECT vid IS NULL OR EXISTS (
+    SELECT 1 FROM vaults v
+    WHERE v.id = vid
+      AND v.org_id = user_org_id(uid)
+      AND (
+        v.user_id = uid
+        OR v.status = 'open'
+        OR (v.status = 'closed' AND EXISTS (
+          SELECT 1 FROM organization_members m
+          WHERE m.org_id = v.org_id AND m.user_id = uid AND m.role = 'Partner'
+        ))
+      )
+  )
+$$;
+
+-- Whether uid may query the vault's documents: accessible and not archived.
+CREATE OR REPLACE FUNCTION vault_queryable(vid TEXT, uid TEXT) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+  SELECT vid IS NULL OR (
+    vault_accessible(vid, uid)
+    AND EXISTS (SELECT 1 FROM vaults v WHERE v.id = vid AND v.archived_at IS NULL)
+  )
+$$;
+
+CREATE OR REPLACE FUNCTION refresh_vault_stats(vid TEXT) RETURNS void
+LANGUAGE sql AS $$
+  UPDATE vaults SET
+    document_count = (
+      SELECT count(*) FROM documents WHERE vault_id = vid AND deletion_status = 'Active'
+    ),
+    storage_used_bytes = (
+      SELECT COALESCE(sum(size_bytes), 0) FROM documents WHERE vault_id = vid AND deletion_status = 'Active'
+    )
+  WHERE id = vid
+$$;
+
+CREATE OR REPLACE FUNCTION documents_vault_stats() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  IF TG_OP <> 'INSERT' AND OLD.vault_id IS NOT NULL THEN
+    PERFORM refresh_vault_stats(OLD.vault_id);
+  END IF;
+  IF TG_OP <> 'DELETE' AND NEW.vault_id IS NOT NULL
+     AND (TG_OP = 'INSERT' OR NEW.vault_id IS DISTINCT FROM OLD.vault_id) THEN
+    PERFORM refresh_vault_stats(NEW.vault_id);
+  END IF;
+  RETURN NULL;
+END $$;
+
+DROP TRIGGER IF EXISTS trg_documents_vault_stats ON documents;
+CREATE TRIGGER trg_documents_vault_stats
+  AFTER INSERT OR DELETE OR UPDATE OF vault_id, deletion_status, size_bytes ON documents
+  FOR EACH ROW EXECUTE FUNCTION documents_vault_stats();
+
+-- Backfill counters that drifted while nothing maintained them.
+SELECT refresh_vault_stats(id) FROM vaults;
@@ -0,0 +1,7 @@
+-- Rollback: 023 sharing
+DROP FUNCTION IF EXISTS shared_documents(TEXT);
+DROP FUNCTION IF EXISTS shared_folders(TEXT);
+DROP FUNCTION IF EXISTS active_share_grants(TEXT);
+DROP TABLE IF EXISTS share_grants;
+DROP TABLE IF EXISTS share_group_members;
+DROP TABLE IF EXISTS share_groups;
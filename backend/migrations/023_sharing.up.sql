@@ -0,0 +1,87 @@
+-- 023: Document and folder sharing — viewer/editor grants to users or
+-- groups, optionally expiring, with folder grants inherited by everything
+-- beneath the folder.
+--
+-- shared_documents(uid) is the single source of truth for shared access and
+-- is used by document listing and every retrieval query.
+-- Idempotent: safe to run multiple times.
+
+CREATE TABLE IF NOT EXISTS share_groups (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  created_by TEXT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (org_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS share_group_members (
+  group_id TEXT NOT NULL REFERENCES share_groups(id) ON DELETE CASCADE,
+  user_id TEXT NOT NULL REFERENCES users(id),
+  added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_group_members_user ON share_group_members(user_id);
+
+CREATE TABLE IF NOT EXISTS share_grants (
+  id TEXT PRIMARY KEY,
+  resource_type TEXT NOT NULL CHECK (resource_type IN ('document', 'folder')),
+  resource_id TEXT NOT NULL,
+  grantee_type TEXT NOT NULL CHECK (grantee_type IN ('user', 'group')),
+  grantee_id TEXT NOT NULL,
+  role TEXT NOT NULL CHECK (role IN ('viewer', 'editor')),
+  granted_by TEXT NOT NULL REFERENCES users(id),
+  expires_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (resource_type, resource_id, grantee_type, grantee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_grants_grantee ON share_grants(grantee_type, grantee_id);
+
+-- Unexpired grants that reach uid directly or through a group of uid's
+-- current organization.
+CREATE OR REPLACE FUNCTION active_share_grants(uid TEXT)
+RETURNS TABLE (resource_type TEXT, resource_id TEXT, role TEXT)
+LANGUAGE sql STABLE AS $$
+  SELECT g.resource_type, g.resource_id, g.role
+  FROM share_grants g
+  WHERE (g.expires_at IS NULL OR g.expires_at > NOW())
+    AND (
+      (g.grantee_type = 'user' AND g.grantee_id = uid)
+      OR (g.grantee_type = 'group' AND EXISTS (
+        SELECT 1 FROM share_group_members m
+        JOIN share_groups sg ON sg.id = m.group_id
+        WHERE m.group_id = g.grantee_id AND m.user_id = uid AND sg.org_id = user_org_id(uid)
+      ))
+    )
+$$;
+
+-- Folders shared with uid, directly or through an ancestor folder, with the
+-- strongest role that reaches each.
+CREATE OR REPLACE FUNCTION shared_folders(uid TEXT)
+RETURNS TABLE (folder_id TEXT, role TEXT)
+LANGUAGE sql STABLE AS $$
+  WITH RECURSIVE tree AS (
+    SELECT g.resource_id AS folder_id, g.role
+    FROM active_share_grants(uid) g WHERE g.resource_type = 'folder'
+    UNION
+    SELECT f.id, tree.role FROM folders f JOIN tree ON f.parent_id = tree.folder_id
+  )
+  SELECT tree.folder_id, CASE WHEN bool_or(tree.role = 'editor') THEN 'editor' ELSE 'viewer' END
+  FROM tree GROUP BY tree.folder_id
+$$;
+
+-- Documents shared with uid, directly or through a shared folder.
+CREATE OR REPLACE FUNCTION shared_documents(uid TEXT)
+RETURNS TABLE (document_id TEXT, role TEXT)
+LANGUAGE sql STABLE AS $$
+  SELECT s.document_id, CASE WHEN bool_or(s.role = 'editor') THEN 'editor' ELSE 'viewer' END
+  FROM (
+    SELECT g.resource_id AS document_id, g.role
+    FROM active_share_grants(uid) g WHERE g.resource_type = 'document'
+    UNION ALL
+    SELECT d.id, sf.role FROM documents d JOIN shared_folders(uid) sf ON d.folder_id = sf.folder_id
+  ) s
+  GROUP BY s.document_id
+$$;
@@ -0,0 +1,2 @@
+-- Rollback: 024 api_tokens
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,22 @@
+-- 024: Personal access tokens for programmatic API access.
+-- Only the SHA-256 of a token is stored; the plaintext is shown once, at
+-- creation. prefix is the first characters of the token so users can tell
+-- their tokens apart. request_count is the per-token usage counter, bumped
+-- with last_used_at on every authenticated request.
+-- Idempotent: safe to run multiple times.
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  prefix TEXT NOT NULL,
+  token_hash TEXT NOT NULL UNIQUE,
+  scopes TEXT[] NOT NULL DEFAULT '{}',   -- read | query | upload | admin
+  expires_at TIMESTAMPTZ,                -- NULL = never expires
+  last_used_at TIMESTAMPTZ,
+  request_count BIGINT NOT NULL DEFAULT 0,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens(user_id, created_at DESC);
@@ -0,0 +1,7 @@
+-- Rollback: 025 audit_chain
+DROP TABLE IF EXISTS audit_chain_heads;
+DROP INDEX IF EXISTS idx_audit_logs_tenant_seq;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS seq;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tenant_id;
+DROP FUNCTION IF EXISTS audit_entry_hash(TEXT, TEXT, TIMESTAMPTZ, JSONB);
@@ -0,0 +1,82 @@
+-- 025: Serialisable audit hash chain, safe across instances.
+--
+-- Previously each process kept the last hash in memory, so two instances
+-- forked the chain. Now every tenant (organization, personal scope, or
+-- 'system' for entries without a user) has a chain head row; an append
+-- locks it, takes the next seq and links to the head's hash in the same
+-- transaction. Entries store seq and prev_hash explicitly and verification
+-- walks by seq instead of created_at.
+--
+-- audit_entry_hash() is the chain formula, computed on the stored row so
+-- JSONB normalisation and timestamp precision cannot break it:
+--   SHA-256(prev_hash || action || created_at (RFC 3339, UTC) || details::text)
+-- Migration 036 replaces it with a formula over every stored column and
+-- re-links the chains hashed here.
+--
+-- Rows written before this migration are re-linked per tenant in
+-- (created_at, id) order and re-hashed with the formula above.
+-- Idempotent: safe to run multiple times.
+
+CREATE OR REPLACE FUNCTION audit_entry_hash(prev TEXT, action TEXT, created_at TIMESTAMPTZ, details JSONB)
+RETURNS TEXT LANGUAGE sql STABLE AS $$
+  SELECT encode(sha256(convert_to(
+    COALESCE(prev, '') || action
+      || to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS')
+      || COALESCE('.' || NULLIF(rtrim(to_char(created_at AT TIME ZONE 'UTC', 'US'), '0'), ''), '')
+      || 'Z'
+      || COALESCE(details::text, ''),
+    'UTF8')), 'hex')
+$$;
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS tenant_id TEXT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS seq BIGINT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash TEXT;
+
+-- A seq is taken at most once per chain, so a fork cannot be stored.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_tenant_seq ON audit_logs(tenant_id, seq);
+
+CREATE TABLE IF NOT EXISTS audit_chain_heads (
+  tenant_id TEXT PRIMARY KEY,
+  seq BIGINT NOT NULL DEFAULT 0,       -- seq of the last entry
+  hash TEXT NOT NULL DEFAULT '',       -- details_hash of the last entry
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Re-link rows that have no seq yet (all rows on the first run; rows
+-- written by old instances during a rolling deploy on later runs).
+DO $$
+DECLARE
+  t RECORD;
+  e RECORD;
+  n BIGINT;
+  prev TEXT;
+BEGIN
+  LOCK TABLE audit_logs IN SHARE ROW EXCLUSIVE MODE;
+
+  UPDATE audit_logs
+  SET tenant_id = CASE WHEN user_id IS NULL THEN 'system' ELSE user_org_id(user_id) END
+  WHERE tenant_id IS NULL;
+
+  FOR t IN SELECT DISTINCT tenant_id FROM audit_logs WHERE seq IS NULL LOOP
+    SELECT h.seq, h.hash INTO n, prev FROM audit_chain_heads h WHERE h.tenant_id = t.tenant_id FOR UPDATE;
+    IF NOT FOUND THEN
+      n := 0;
+      prev := '';
+    END IF;
+
+    FOR e IN
+      SELECT id, action, created_at, details FROM audit_logs
+      WHERE tenant_id = t.tenant_id AND seq IS NULL
+      ORDER BY created_at, id
+    LOOP
+      n := n + 1;
+      UPDATE audit_logs
+      SET seq = n, prev_hash = prev, details_hash = audit_entry_hash(prev, e.action, e.created_at, e.details)
+      WHERE id = e.id
+      RETURNING details_hash INTO prev;
+    END LOOP;
+
+    INSERT INTO audit_chain_heads (tenant_id, seq, hash) VALUES (t.tenant_id, n, prev)
+    ON CONFLICT (tenant_id) DO UPDATE SET seq = EXCLUDED.seq, hash = EXCLUDED.hash, updated_at = NOW();
+  END LOOP;
+END $$;
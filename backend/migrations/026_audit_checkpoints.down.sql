@@ -0,0 +1,2 @@
+-- Rollback: 026 audit_checkpoints
+DROP TABLE IF EXISTS audit_checkpoints;
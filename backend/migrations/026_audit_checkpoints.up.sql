@@ -0,0 +1,23 @@
+-- 026: Signed Merkle checkpoints over the audit hash chains.
+--
+-- A checkpoint covers one tenant's entries with seq in [from_seq, to_seq]:
+-- root_hash is the RFC 6962 Merkle root over their details_hash values and
+-- signature is an Ed25519 signature over the checkpoint (see
+-- internal/auditchain). A tenant's checkpoints are contiguous; the unique
+-- (tenant_id, from_seq) lets instances race to write the next one safely.
+-- Idempotent: safe to run multiple times.
+
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+  id TEXT PRIMARY KEY,
+  tenant_id TEXT NOT NULL,
+  from_seq BIGINT NOT NULL,
+  to_seq BIGINT NOT NULL,
+  root_hash TEXT NOT NULL,
+  signature TEXT NOT NULL,   -- base64
+  key_id TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  UNIQUE (tenant_id, from_seq),
+  CHECK (to_seq >= from_seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_tenant_to ON audit_checkpoints(tenant_id, to_seq);
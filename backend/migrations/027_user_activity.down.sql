@@ -0,0 +1,2 @@
+-- Rollback: 027 user_activity
+ALTER TABLE users DROP COLUMN IF EXISTS last_active_at;
@@ -0,0 +1,8 @@
+-- 027: Track user activity to detect logins for the audit trail.
+--
+-- Sessions are not visible to the API (ID tokens refresh silently), so a
+-- login is the first request after a period without any. last_active_at is
+-- bumped at most once a minute per user; see UserRepo.TouchSession.
+-- Idempotent: safe to run multiple times.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_active_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- Rollback: 028 audit_outbox
+DROP TABLE IF EXISTS audit_outbox;
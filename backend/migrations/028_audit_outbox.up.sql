@@ -0,0 +1,21 @@
+-- 028: Audit outbox — at-least-once delivery of audit entries to external sinks.
+--
+-- AuditRepo.Append writes one row per configured sink in the same
+-- transaction as the entry, so an entry that commits is always queued. The
+-- dispatcher claims due rows by pushing next_attempt_at past a lease,
+-- deletes them once the sink accepts them, and on failure pushes
+-- next_attempt_at out by a backoff. Rows for a sink that is no longer
+-- configured stay until it is configured again.
+-- Idempotent: safe to run multiple times.
+
+CREATE TABLE IF NOT EXISTS audit_outbox (
+  id BIGSERIAL PRIMARY KEY,
+  entry_id TEXT NOT NULL REFERENCES audit_logs(id),
+  sink TEXT NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_outbox_due ON audit_outbox(sink, next_attempt_at, id);
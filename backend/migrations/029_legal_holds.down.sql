@@ -0,0 +1,12 @@
+-- Rollback: 029 legal holds
+DROP TRIGGER IF EXISTS trg_folders_legal_hold ON folders;
+DROP FUNCTION IF EXISTS folders_legal_hold();
+DROP TRIGGER IF EXISTS trg_document_chunks_legal_hold ON document_chunks;
+DROP FUNCTION IF EXISTS document_chunks_legal_hold();
+DROP TRIGGER IF EXISTS trg_documents_legal_hold ON documents;
+DROP FUNCTION IF EXISTS documents_legal_hold();
+DROP FUNCTION IF EXISTS legal_hold_labels(TEXT[]);
+DROP FUNCTION IF EXISTS folder_legal_holds(TEXT);
+DROP FUNCTION IF EXISTS document_legal_holds(TEXT);
+DROP TABLE IF EXISTS legal_hold_scopes;
+DROP TABLE IF EXISTS legal_holds;
@@ -0,0 +1,173 @@
+-- 029: Legal holds — named holds for a litigation matter that freeze
+-- documents, folders or whole vaults until released.
+--
+-- Holds are enforced in the API first (service.LegalHoldService); the
+-- triggers below are the backstop for every other writer, including
+-- hard-delete purges. They raise SQLSTATE LH001, which the repository maps
+-- to service.ErrUnderLegalHold. Released holds are kept for the record.
+-- Idempotent: safe to run multiple times.
+
+CREATE TABLE IF NOT EXISTS legal_holds (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  matter_id TEXT NOT NULL,
+  custodian TEXT NOT NULL,
+  created_by TEXT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  released_by TEXT REFERENCES users(id),
+  released_at TIMESTAMPTZ,
+  release_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_legal_holds_org ON legal_holds(org_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_legal_holds_active ON legal_holds(id) WHERE released_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS legal_hold_scopes (
+  hold_id TEXT NOT NULL REFERENCES legal_holds(id) ON DELETE CASCADE,
+  resource_type TEXT NOT NULL CHECK (resource_type IN ('document', 'folder', 'vault')),
+  resource_id TEXT NOT NULL,
+  PRIMARY KEY (hold_id, resource_type, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_legal_hold_scopes_resource ON legal_hold_scopes(resource_type, resource_id);
+
+-- Active holds covering the document: held directly, through its folder or
+-- any ancestor folder, or through its vault.
+CREATE OR REPLACE FUNCTION document_legal_holds(did TEXT)
+RETURNS TABLE (hold_id TEXT)
+LANGUAGE sql STABLE AS $$
+  WITH RECURSIVE doc AS (
+    SELECT d.folder_id, d.vault_id FROM documents d WHERE d.id = did
+  ), ancestors AS (
+    SELECT f.id, f.parent_id FROM folders f JOIN doc ON f.id = doc.folder_id
+    UNION
+    SELECT f.id, f.parent_id FROM folders f JOIN ancestors a ON f.id = a.parent_id
+  )
+  SELECT DISTINCT h.id
+  FROM legal_holds h JOIN legal_hold_scopes s ON s.hold_id = h.id
+  WHERE h.released_at IS NULL AND (
+    (s.resource_type = 'document' AND s.resource_id = did)
+    OR (s.resource_type = 'folder' AND s.resource_id IN (SELECT id FROM ancestors))
+    OR (s.resource_type = 'vault' AND s.resource_id IN (SELECT vault_id FROM doc))
+  )
+$$;
+
+-- Active holds that deleting the folder would breach: holds on the folder
+-- or an ancestor, and holds on any folder or document beneath it.
+CREATE OR REPLACE FUNCTION folder_legal_holds(fid TEXT)
+RETURNS TABLE (hold_id TEXT)
+LANGUAGE sql STABLE AS $$
+  WITH RECURSIVE ancestors AS (
+    SELECT f.id, f.parent_id FROM folders f WHERE f.id = fid
+    UNION
+    SELECT f.id, f.parent_id FROM folders f JOIN ancestors a ON f.id = a.parent_id
+  ), descendants AS (
+    SELECT fid AS id
+    UNION
+    SELECT f.id FROM folders f JOIN descendants d ON f.parent_id = d.id
+  )
+  SELECT s.hold_id
+  FROM legal_hold_scopes s JOIN legal_holds h ON h.id = s.hold_id
+  WHERE h.released_at IS NULL AND s.resource_type = 'folder'
+    AND (s.resource_id IN (SELECT id FROM ancestors) OR s.resource_id IN (SELECT id FROM descendants))
+  UNION
+  SELECT dh.hold_id
+  FROM documents d, LATERAL document_legal_holds(d.id) dh
+  WHERE d.folder_id IN (SELECT id FROM descendants)
+$$;
+
+-- "Name" (matter M), ... for the holds, as shown in LH001 messages.
+CREATE OR REPLACE FUNCTION legal_hold_labels(ids TEXT[]) RETURNS TEXT
+LANGUAGE sql STABLE AS $$
+  SELECT string_agg(format('"%s" (matter %s)', h.name, h.matter_id), ', ' ORDER BY h.created_at)
+  FROM legal_holds h WHERE h.id = ANY(ids)
+$$;
+
+CREATE OR REPLACE FUNCTION documents_legal_hold() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+  op TEXT;
+  holds TEXT[];
+BEGIN
+  -- Most databases hold nothing; skip the folder walk.
+  IF NOT EXISTS (SELECT 1 FROM legal_holds WHERE released_at IS NULL) THEN
+    RETURN COALESCE(NEW, OLD);
+  END IF;
+
+  IF TG_OP = 'DELETE' THEN
+    op := 'purge';
+  ELSIF NEW.deletion_status IS DISTINCT FROM OLD.deletion_status AND NEW.deletion_status <> 'Active' THEN
+    op := 'delete';
+  ELSIF NEW.filename IS DISTINCT FROM OLD.filename OR NEW.original_name IS DISTINCT FROM OLD.original_name THEN
+    op := 'rename';
+  ELSIF NEW.folder_id IS DISTINCT FROM OLD.folder_id OR NEW.vault_id IS DISTINCT FROM OLD.vault_id THEN
+    op := 'move';
+  ELSIF OLD.extracted_text IS NOT NULL AND NEW.extracted_text IS DISTINCT FROM OLD.extracted_text THEN
+    op := 're-ingest';
+  ELSE
+    RETURN NEW;
+  END IF;
+
+  SELECT array_agg(hold_id) INTO holds FROM document_legal_holds(OLD.id);
+  IF holds IS NOT NULL THEN
+    RAISE EXCEPTION 'cannot % document %: under legal hold %', op, OLD.id, legal_hold_labels(holds)
+      USING ERRCODE = 'LH001';
+  END IF;
+  RETURN COALESCE(NEW, OLD);
+END $$;
+
+DROP TRIGGER IF EXISTS trg_documents_legal_hold ON documents;
+CREATE TRIGGER trg_documents_legal_hold
+  BEFORE DELETE OR UPDATE OF filename, original_name, folder_id, vault_id, deletion_status, extracted_text ON documents
+  FOR EACH ROW EXECUTE FUNCTION documents_legal_hold();
+
+-- Chunks are checked per statement: DeleteByDocumentID removes hundreds of
+-- rows for one document.
+CREATE OR REPLACE FUNCTION document_chunks_legal_hold() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+  did TEXT;
+  holds TEXT[];
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM legal_holds WHERE released_at IS NULL) THEN
+    RETURN NULL;
+  END IF;
+
+  FOR did IN SELECT DISTINCT document_id FROM old_chunks LOOP
+    SELECT array_agg(hold_id) INTO holds FROM document_legal_holds(did);
+    IF holds IS NOT NULL THEN
+      RAISE EXCEPTION 'cannot delete chunks of document %: under legal hold %', did, legal_hold_labels(holds)
+        USING ERRCODE = 'LH001';
+    END IF;
+  END LOOP;
+  RETURN NULL;
+END $$;
+
+DROP TRIGGER IF EXISTS trg_document_chunks_legal_hold ON document_chunks;
+CREATE TRIGGER trg_document_chunks_legal_hold
+  AFTER DELETE ON document_chunks
+  REFERENCING OLD TABLE AS old_chunks
+  FOR EACH STATEMENT EXECUTE FUNCTION document_chunks_legal_hold();
+
+CREATE OR REPLACE FUNCTION folders_legal_hold() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+  holds TEXT[];
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM legal_holds WHERE released_at IS NULL) THEN
+    RETURN OLD;
+  END IF;
+
+  SELECT array_agg(DISTINCT hold_id) INTO holds FROM folder_legal_holds(OLD.id);
+  IF holds IS NOT NULL THEN
+    RAISE EXCEPTION 'cannot delete folder %: under legal hold %', OLD.id, legal_hold_labels(holds)
+      USING ERRCODE = 'LH001';
+  END IF;
+  RETURN OLD;
+END $$;
+
+DROP TRIGGER IF EXISTS trg_folders_legal_hold ON folders;
+CREATE TRIGGER trg_folders_legal_hold
+  BEFORE DELETE ON folders
+  FOR EACH ROW EXECUTE FUNCTION folders_legal_hold();
@@ -0,0 +1,6 @@
+-- Rollback: 030 retention
+DROP INDEX IF EXISTS idx_cortex_entries_source_documents;
+ALTER TABLE cortex_entries DROP COLUMN IF EXISTS source_document_ids;
+DROP INDEX IF EXISTS idx_documents_purge_cleanup;
+DROP INDEX IF EXISTS idx_documents_soft_deleted;
+DROP TABLE IF EXISTS retention_policies;
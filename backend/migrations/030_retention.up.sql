@@ -0,0 +1,43 @@
+-- 030: Retention policies — how long soft-deleted documents are kept before
+-- the purge job hard-deletes them, per vault or per document type.
+--
+-- A vault policy takes precedence over a document type policy; documents
+-- neither covers use the server default (RETENTION_DEFAULT_DAYS). Purged
+-- documents stay as HardDeleted rows with their content scrubbed, so
+-- citations and the audit trail still resolve. Legal holds (migration 029)
+-- block the purge.
+-- Idempotent: safe to run multiple times.
+
+-- Set by the enricher (UpdateDocumentType); older schemas lack it.
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS document_type TEXT;
+
+CREATE TABLE IF NOT EXISTS retention_policies (
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  vault_id TEXT REFERENCES vaults(id) ON DELETE CASCADE,
+  document_type TEXT,
+  retention_days INT NOT NULL CHECK (retention_days BETWEEN 0 AND 36500),
+  created_by TEXT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ((vault_id IS NULL) <> (document_type IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retention_policies_vault
+  ON retention_policies(org_id, vault_id) WHERE vault_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retention_policies_type
+  ON retention_policies(org_id, document_type) WHERE document_type IS NOT NULL;
+
+-- The purge job scans soft-deleted documents by age, and HardDeleted rows
+-- whose storage object is still to be removed.
+CREATE INDEX IF NOT EXISTS idx_documents_soft_deleted
+  ON documents(deleted_at) WHERE deletion_status = 'SoftDeleted';
+CREATE INDEX IF NOT EXISTS idx_documents_purge_cleanup
+  ON documents(id) WHERE deletion_status = 'HardDeleted'
+    AND (storage_path IS NOT NULL OR storage_uri IS NOT NULL);
+
+-- Working memory derived from documents (assistant answers), so a purge
+-- can remove it.
+ALTER TABLE cortex_entries ADD COLUMN IF NOT EXISTS source_document_ids TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_cortex_entries_source_documents
+  ON cortex_entries USING gin (source_document_ids);
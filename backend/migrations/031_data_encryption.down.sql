@@ -0,0 +1,5 @@
+-- Rollback: 031 data encryption
+-- Sealed extracted_text and chunk content cannot be read without the wrapped
+-- keys: decrypt them first. content_tsv stays a plain column; it is only
+-- regenerated from content by re-running the web app's STORY-161 migration.
+DROP TABLE IF EXISTS tenant_data_keys;
@@ -0,0 +1,45 @@
+-- 031: Application-level encryption of documents.extracted_text and
+-- document_chunks.content with per-tenant data encryption keys (DEKs).
+--
+-- The server seals both columns before writing (internal/fieldcrypt); the
+-- DEKs are stored here only wrapped by the key provider's key encryption
+-- key (DATA_KEY_PROVIDER: local or kms). A tenant is the document's
+-- organization, or its owner when it has none.
+--
+-- content_tsv stops being generated from content, which is now ciphertext:
+-- the server writes it from the plaintext at insert, so BM25 keeps working.
+-- Trade-off: the tsvector holds the chunk's stemmed lexemes and positions in
+-- plaintext. Rows written before this migration keep their plaintext and are
+-- read as-is.
+-- Idempotent: safe to run multiple times.
+
+CREATE TABLE IF NOT EXISTS tenant_data_keys (
+  tenant_id TEXT NOT NULL,
+  version INT NOT NULL CHECK (version > 0),
+  wrapped_key BYTEA NOT NULL,
+  kek_id TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_id, version)
+);
+
+-- content_tsv was created as a generated column by the web app's STORY-161
+-- migration; keep its values and index, but make it a plain column. Schemas
+-- without it get the column and its index here.
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'document_chunks' AND column_name = 'content_tsv' AND is_generated = 'ALWAYS'
+  ) THEN
+    ALTER TABLE document_chunks ALTER COLUMN content_tsv DROP EXPRESSION;
+  ELSIF NOT EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'document_chunks' AND column_name = 'content_tsv'
+  ) THEN
+    ALTER TABLE document_chunks ADD COLUMN content_tsv tsvector;
+    UPDATE document_chunks SET content_tsv = to_tsvector('english', content)
+    WHERE content NOT LIKE 'ragbox:enc:v1:%';
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_chunks_content_gin ON document_chunks USING gin (content_tsv);
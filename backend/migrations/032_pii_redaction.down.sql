@@ -0,0 +1,3 @@
+-- Rollback: 032 pii redaction
+DROP TABLE IF EXISTS vault_pii_policies;
+ALTER TABLE document_chunks DROP COLUMN IF EXISTS pii_findings;
@@ -0,0 +1,23 @@
+-- 032: Query-time PII redaction — PII/PHI findings stored per chunk at
+-- ingest, and per-vault policies saying which info types are redacted for
+-- callers outside Privileged Mode.
+--
+-- pii_findings holds the detector's findings as a JSON array of
+-- {infoType, start, end, score}, byte offsets into the plaintext content.
+-- The matched text is never stored. NULL means the chunk has not been
+-- scanned; it is scanned when retrieved instead.
+--
+-- Vaults without a policy, and documents outside any vault, use the server
+-- default (PII_REDACT_TYPES / PII_REDACT_EXEMPT_ROLES). A policy with no
+-- info types turns redaction off for the vault.
+-- Idempotent: safe to run multiple times.
+
+ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS pii_findings JSONB;
+
+CREATE TABLE IF NOT EXISTS vault_pii_policies (
+  vault_id TEXT PRIMARY KEY REFERENCES vaults(id) ON DELETE CASCADE,
+  info_types TEXT[] NOT NULL DEFAULT '{}',
+  exempt_roles TEXT[] NOT NULL DEFAULT '{}',
+  updated_by TEXT NOT NULL REFERENCES users(id),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
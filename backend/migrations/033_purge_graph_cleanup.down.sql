@@ -0,0 +1,6 @@
+-- Rollback: 033 purge graph cleanup
+DROP INDEX IF EXISTS idx_documents_purge_cleanup;
+CREATE INDEX IF NOT EXISTS idx_documents_purge_cleanup
+  ON documents(id) WHERE deletion_status = 'HardDeleted'
+    AND (storage_path IS NOT NULL OR storage_uri IS NOT NULL);
+ALTER TABLE documents DROP COLUMN IF EXISTS graph_cleaned_at;
@@ -0,0 +1,19 @@
+-- 033: Track a purged document's knowledge graph cleanup separately from its
+-- storage object, so a graph failure is retried even for documents that never
+-- had a storage object, and does not hold up storage cleanup.
+--
+-- graph_cleaned_at is set once the purge job has removed the document from
+-- the graph. Purged rows whose storage was already cleared were fully cleaned
+-- up by earlier runs, so they are backfilled.
+-- Idempotent: safe to run multiple times.
+
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS graph_cleaned_at TIMESTAMPTZ;
+
+UPDATE documents SET graph_cleaned_at = COALESCE(hard_delete_at, updated_at)
+WHERE deletion_status = 'HardDeleted' AND graph_cleaned_at IS NULL
+  AND storage_path IS NULL AND storage_uri IS NULL;
+
+DROP INDEX IF EXISTS idx_documents_purge_cleanup;
+CREATE INDEX IF NOT EXISTS idx_documents_purge_cleanup
+  ON documents(id) WHERE deletion_status = 'HardDeleted'
+    AND (storage_path IS NOT NULL OR storage_uri IS NOT NULL OR graph_cleaned_at IS NULL);
@@ -0,0 +1,3 @@
+-- Rollback: 034 chunk plaintext hash
+-- The backfilled hashes are valid under 019 as well; nothing to undo.
+SELECT 1;
@@ -0,0 +1,13 @@
+-- 034: Re-embed change detection compares shadow vectors against
+-- embedding_input_hash instead of md5(content). Sealed content (migration 031)
+-- is ciphertext whose md5 changes every time it is sealed, so the hash is now
+-- computed from the plaintext in Go and stored on write.
+--
+-- Backfill the hash for plaintext rows that never had one (019 only covered
+-- embedded rows). Sealed rows are always written with it. Shadow rows of a
+-- running job that carry an md5 hash are re-embedded before cutover.
+-- Idempotent: safe to run multiple times.
+
+UPDATE document_chunks
+SET embedding_input_hash = encode(sha256(convert_to(content, 'UTF8')), 'hex')
+WHERE embedding_input_hash IS NULL AND content NOT LIKE 'ragbox:enc:v1:%';
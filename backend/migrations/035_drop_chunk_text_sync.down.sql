@@ -0,0 +1,3 @@
+-- Rollback: 035 drop chunk text sync
+-- The trigger copied sealed content into chunk_text; it is not restored.
+SELECT 1;
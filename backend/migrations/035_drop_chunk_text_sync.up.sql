@@ -0,0 +1,39 @@
+-- 035: Stop mirroring document_chunks.content into chunk_text.
+--
+-- The web app's admin migration added a trigger that copies content into
+-- chunk_text on every insert and update, for reads through Prisma. Since
+-- 031 content may be sealed, so the trigger copied ciphertext, and on
+-- re-ingest it kept the earlier plaintext copy because chunk_text was no
+-- longer NULL. The web app now reads chunks through the backend, so drop
+-- the trigger and clear the copies.
+--
+-- Sealed chunks also stop carrying plaintext enrichment: contextual_text and
+-- entities written before the server sealed them are cleared, and come back
+-- sealed when the document is reprocessed. Sealed rows written before this
+-- migration keep plain SHA-256 content_hash and embedding_input_hash values
+-- until then too.
+-- Idempotent: safe to run multiple times.
+
+DROP TRIGGER IF EXISTS trg_sync_chunk_text ON document_chunks;
+DROP FUNCTION IF EXISTS sync_chunk_text();
+
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'document_chunks' AND column_name = 'chunk_text'
+  ) THEN
+    ALTER TABLE document_chunks ALTER COLUMN chunk_text DROP NOT NULL;
+    UPDATE document_chunks SET chunk_text = NULL WHERE chunk_text IS NOT NULL;
+  END IF;
+END $$;
+
+UPDATE document_chunks
+SET contextual_text = NULL
+WHERE content LIKE 'ragbox:enc:v1:%'
+  AND contextual_text IS NOT NULL AND contextual_text NOT LIKE 'ragbox:enc:v1:%';
+
+UPDATE document_chunks
+SET entities = '[]'::jsonb
+WHERE content LIKE 'ragbox:enc:v1:%'
+  AND jsonb_typeof(entities) = 'array' AND entities <> '[]'::jsonb;
@@ -0,0 +1,4 @@
+-- Rollback: 036 audit hash all columns
+-- Re-linked chains are not re-hashed with the 025 formula.
+DROP FUNCTION IF EXISTS audit_entry_hash(TEXT, TEXT, TEXT, BIGINT, TEXT, TEXT, TEXT, TEXT, TEXT, TEXT, TEXT, TIMESTAMPTZ, JSONB);
+DROP FUNCTION IF EXISTS audit_hash_field(TEXT);
@@ -0,0 +1,78 @@
+-- 036: The audit entry hash covers every stored column.
+--
+-- The 025 formula hashed only prev_hash, action, created_at and details, so
+-- user_id, resource_id, resource_type, severity, seq, tenant_id, ip_address
+-- and user_agent could be edited without breaking the chain. The new
+-- audit_entry_hash() is mirrored by EntryHash in internal/auditchain/hash.go:
+--   SHA-256 over prev_hash, id, tenant_id, seq, user_id, action, resource_id,
+--   resource_type, severity, ip_address, user_agent, created_at (RFC 3339,
+--   UTC) and details::text, each written as <byte length>:<value>, or as
+--   '-' when NULL.
+--
+-- Chains still hashed with the 025 formula are re-linked in seq order, and
+-- their checkpoints are deleted so the checkpointer signs the new hashes.
+-- A chain is current when its head entry verifies under the new formula.
+-- Copies already delivered to audit sinks keep the 025 hashes.
+-- Idempotent: safe to run multiple times.
+
+CREATE OR REPLACE FUNCTION audit_hash_field(v TEXT)
+RETURNS TEXT LANGUAGE sql IMMUTABLE AS $$
+  SELECT COALESCE(octet_length(v)::text || ':' || v, '-')
+$$;
+
+CREATE OR REPLACE FUNCTION audit_entry_hash(
+  prev TEXT, id TEXT, tenant_id TEXT, seq BIGINT, user_id TEXT, action TEXT,
+  resource_id TEXT, resource_type TEXT, severity TEXT, ip_address TEXT,
+  user_agent TEXT, created_at TIMESTAMPTZ, details JSONB)
+RETURNS TEXT LANGUAGE sql STABLE AS $$
+  SELECT encode(sha256(convert_to(
+    audit_hash_field(COALESCE(prev, ''))
+      || audit_hash_field(id)
+      || audit_hash_field(COALESCE(tenant_id, ''))
+      || audit_hash_field(seq::text)
+      || audit_hash_field(user_id)
+      || audit_hash_field(action)
+      || audit_hash_field(resource_id)
+      || audit_hash_field(resource_type)
+      || audit_hash_field(severity)
+      || audit_hash_field(ip_address)
+      || audit_hash_field(user_agent)
+      || audit_hash_field(
+           to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS')
+           || COALESCE('.' || NULLIF(rtrim(to_char(created_at AT TIME ZONE 'UTC', 'US'), '0'), ''), '')
+           || 'Z')
+      || audit_hash_field(details::text),
+    'UTF8')), 'hex')
+$$;
+
+DO $$
+DECLARE
+  t RECORD;
+  e RECORD;
+  prev TEXT;
+BEGIN
+  LOCK TABLE audit_logs IN SHARE ROW EXCLUSIVE MODE;
+
+  FOR t IN
+    SELECT h.tenant_id FROM audit_chain_heads h
+    JOIN audit_logs l ON l.tenant_id = h.tenant_id AND l.seq = h.seq
+    WHERE l.details_hash IS DISTINCT FROM audit_entry_hash(l.prev_hash, l.id, l.tenant_id, l.seq, l.user_id,
+      l.action, l.resource_id, l.resource_type, l.severity, l.ip_address, l.user_agent, l.created_at, l.details)
+  LOOP
+    PERFORM 1 FROM audit_chain_heads h WHERE h.tenant_id = t.tenant_id FOR UPDATE;
+    prev := '';
+    FOR e IN
+      SELECT * FROM audit_logs WHERE tenant_id = t.tenant_id AND seq IS NOT NULL ORDER BY seq
+    LOOP
+      UPDATE audit_logs
+      SET prev_hash = prev,
+          details_hash = audit_entry_hash(prev, e.id, e.tenant_id, e.seq, e.user_id, e.action, e.resource_id,
+            e.resource_type, e.severity, e.ip_address, e.user_agent, e.created_at, e.details)
+      WHERE id = e.id
+      RETURNING details_hash INTO prev;
+    END LOOP;
+
+    UPDATE audit_chain_heads SET hash = prev, updated_at = NOW() WHERE tenant_id = t.tenant_id;
+    DELETE FROM audit_checkpoints WHERE tenant_id = t.tenant_id;
+  END LOOP;
+END $$;
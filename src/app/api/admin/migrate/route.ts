@@ -1077,26 +1077,10 @@ export async function POST(request: NextRequest): Promise<NextResponse> {
     await prisma.$executeRawUnsafe(`ALTER TABLE "document_chunks" ADD COLUMN IF NOT EXISTS "content_hash" TEXT`)
     await prisma.$executeRawUnsafe(`ALTER TABLE "document_chunks" ALTER COLUMN "tenant_id" DROP NOT NULL`)
     await prisma.$executeRawUnsafe(`ALTER TABLE "document_chunks" ALTER COLUMN "chunk_text" DROP NOT NULL`)
-    // Sync: copy content → chunk_text where chunk_text is NULL (for Next.js reads)
-    await prisma.$executeRawUnsafe(`UPDATE "document_chunks" SET "chunk_text" = "content" WHERE "chunk_text" IS NULL AND "content" IS NOT NULL`)
-    // Trigger: auto-sync content → chunk_text on INSERT/UPDATE
-    await prisma.$executeRawUnsafe(`
-      CREATE OR REPLACE FUNCTION sync_chunk_text() RETURNS TRIGGER AS $t$
-      BEGIN
-        IF NEW.chunk_text IS NULL AND NEW.content IS NOT NULL THEN
-          NEW.chunk_text := NEW.content;
-        END IF;
-        RETURN NEW;
-      END;
-      $t$ LANGUAGE plpgsql
-    `)
-    await prisma.$executeRawUnsafe(`
-      DO $$ BEGIN
-        CREATE TRIGGER trg_sync_chunk_text BEFORE INSERT OR UPDATE ON document_chunks
-        FOR EACH ROW EXECUTE FUNCTION sync_chunk_text();
-      EXCEPTION WHEN duplicate_object THEN NULL;
-      END $$
-    `)
+    // chunk_text is no longer mirrored from content: content may be sealed
+    // ciphertext, and chunks are read through the Go backend (migration 035).
+    await prisma.$executeRawUnsafe(`DROP TRIGGER IF EXISTS trg_sync_chunk_text ON document_chunks`)
+    await prisma.$executeRawUnsafe(`DROP FUNCTION IF EXISTS sync_chunk_text()`)
 
     // ========================================
     // EPIC-034: Enrichment columns for sovereign pipeline
@@ -20,7 +20,7 @@ import prisma from '@/lib/prisma'
 import { decryptKey } from '@/lib/utils/kms'
 import { validateExternalUrl } from '@/lib/utils/url-validation'
 import { logger } from '@/lib/logger'
-import { GO_BACKEND_URL } from '@/lib/backend-proxy'
+import { GO_BACKEND_URL, clientHeaders } from '@/lib/backend-proxy'
 
 const INTERNAL_AUTH_SECRET = process.env.INTERNAL_AUTH_SECRET || ''
 const DEFAULT_TENANT = 'default'
@@ -242,6 +242,7 @@ export async function POST(request: NextRequest): Promise<NextResponse | Respons
         'Content-Type': 'application/json',
         'X-Internal-Auth': INTERNAL_AUTH_SECRET,
         'X-User-ID': userId,
+        ...clientHeaders(request),
       },
       body: JSON.stringify({
         query: effectiveQuery,
@@ -9,6 +9,7 @@ import { getToken } from 'next-auth/jwt'
 import prisma from '@/lib/prisma'
 import { GO_BACKEND_URL, backendHeaders } from '@/lib/backend-proxy'
 import { logger } from '@/lib/logger'
+import { fetchExtractedText } from '@/lib/documents/text'
 
 type RouteContext = { params: Promise<{ id: string }> }
 
@@ -31,23 +32,21 @@ export async function GET(
   try {
     const document = await prisma.document.findFirst({
       where: { id, userId, deletionStatus: 'Active' },
-      include: {
-        chunks: {
-          orderBy: { chunkIndex: 'asc' },
-          take: 10,
-          select: { content: true },
-        },
-      },
     })
 
     if (!document) {
       return NextResponse.json({ success: false, error: 'Not found' }, { status: 404 })
     }
 
-    // Build content preview from chunks
-    const contentPreview = document.chunks.length > 0
-      ? document.chunks.map(c => c.content).join('\n').slice(0, 5000)
-      : null
+    // Chunk content is sealed in the database; preview the backend's
+    // decrypted copy of the extracted text instead.
+    let contentPreview: string | null = null
+    try {
+      const text = await fetchExtractedText(id, userId)
+      contentPreview = text ? text.slice(0, 5000) : null
+    } catch (err) {
+      logger.error('[Preview] Failed to get document text:', err)
+    }
 
     // Fetch signed URL from Go backend download endpoint (reuse existing logic)
     let signedUrl: string | null = null
@@ -593,9 +593,10 @@ async function processTranscript(event: RoamTranscriptEvent): Promise<void> {
     logger.error('[ROAM Processor] Summary send failed:', error)
   }
 
-  // Store transcript as document in Vault for indexing
+  // Store transcript as document in Vault for indexing. The backend stores
+  // the text so it is sealed at rest.
   try {
-    await prisma.document.create({
+    const doc = await prisma.document.create({
       data: {
         tenantId: tenant.tenantId,
         userId,
@@ -604,7 +605,6 @@ async function processTranscript(event: RoamTranscriptEvent): Promise<void> {
         mimeType: 'text/plain',
         fileType: 'txt',
         sizeBytes: Buffer.byteLength(transcriptContent, 'utf-8'),
-        extractedText: transcriptContent,
         indexStatus: 'Pending',
         metadata: {
           source: 'roam_transcript',
@@ -615,6 +615,20 @@ async function processTranscript(event: RoamTranscriptEvent): Promise<void> {
         },
       },
     })
+
+    const stored = await fetch(`${GO_BACKEND_URL}/api/documents/${doc.id}/text`, {
+      method: 'PUT',
+      headers: {
+        'Content-Type': 'application/json',
+        'X-Internal-Auth': INTERNAL_AUTH_SECRET,
+        'X-User-ID': userId,
+      },
+      body: JSON.stringify({ text: transcriptContent }),
+    }).then((res) => res.ok, () => false)
+    if (!stored) {
+      await prisma.document.delete({ where: { id: doc.id } })
+      throw new Error(`backend did not store text for document ${doc.id}`)
+    }
   } catch (error) {
     logger.error('[ROAM Processor] Transcript document creation failed:', error)
   }
@@ -158,6 +158,7 @@ export async function POST(request: NextRequest): Promise<NextResponse> {
         'Content-Type': 'application/json',
         'X-Internal-Auth': INTERNAL_AUTH_SECRET,
         'X-User-ID': auth.userId,
+        'X-Client-Type': 'api', // metered against the api_calls quota
       },
       body: JSON.stringify({
         filename: file.name,
@@ -2,7 +2,8 @@
  * Webhook Knowledge Ingestion — POST /api/v1/knowledge/ingest
  *
  * Accepts structured knowledge events from external systems.
- * Creates a Document + KnowledgeEvent, publishes to Pub/Sub, returns 202.
+ * Creates a Document + KnowledgeEvent, hands the content to the Go backend
+ * (which seals it at rest), publishes to Pub/Sub, returns 202.
  */
 
 import { NextRequest, NextResponse } from 'next/server'
@@ -12,6 +13,7 @@ import { authenticateApiKey, hasScope } from '@/lib/api/apiKeyMiddleware'
 import { writeAuditEntry } from '@/lib/audit/auditWriter'
 import prisma from '@/lib/prisma'
 import { logger } from '@/lib/logger'
+import { GO_BACKEND_URL, backendHeaders } from '@/lib/backend-proxy'
 
 export const runtime = 'nodejs'
 export const dynamic = 'force-dynamic'
@@ -167,7 +169,6 @@ export async function POST(request: NextRequest): Promise<NextResponse> {
         mimeType: body.content_type,
         fileType,
         sizeBytes: Buffer.byteLength(body.content, 'utf8'),
-        extractedText: body.content,
         indexStatus: 'Pending',
         deletionStatus: 'Active',
         privilegeLevel: body.privilege_level,
@@ -203,6 +204,33 @@ export async function POST(request: NextRequest): Promise<NextResponse> {
     return { doc, event }
   })
 
+  // Extracted text is encrypted at rest, so the backend stores it rather
+  // than Prisma writing it in plaintext.
+  const stored = await fetch(`${GO_BACKEND_URL}/api/documents/${result.doc.id}/text`, {
+    method: 'PUT',
+    headers: backendHeaders(auth.userId),
+    body: JSON.stringify({ text: body.content }),
+  })
+    .then((res) => res.ok)
+    .catch((err: unknown) => {
+      logger.error('[Knowledge Ingest] Backend text store failed:', err)
+      return false
+    })
+
+  if (!stored) {
+    // Roll back so the sender can retry the same event_id.
+    await prisma.$transaction([
+      prisma.knowledgeEvent.delete({ where: { id: result.event.id } }),
+      prisma.document.delete({ where: { id: result.doc.id } }),
+    ]).catch((err: unknown) => {
+      logger.error('[Knowledge Ingest] Rollback failed:', err)
+    })
+    return NextResponse.json(
+      { success: false, error: 'Failed to store content' },
+      { status: 502 }
+    )
+  }
+
   // Fire-and-forget Pub/Sub publish
   getPubSub()
     .topic(PUBSUB_TOPIC)
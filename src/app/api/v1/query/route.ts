@@ -45,6 +45,7 @@ export async function POST(request: NextRequest): Promise<NextResponse> {
         'Content-Type': 'application/json',
         'X-Internal-Auth': INTERNAL_AUTH_SECRET,
         'X-User-ID': auth.userId,
+        'X-Client-Type': 'api', // metered against the api_calls quota
       },
       body: JSON.stringify({
         query: body.query.trim(),
@@ -55,6 +56,12 @@ export async function POST(request: NextRequest): Promise<NextResponse> {
       }),
     })
 
+    if (ragResponse.status === 402) {
+      // Quota exhausted — pass the structured quota error through
+      const quota = await ragResponse.json().catch(() => ({ success: false, error: 'quota_exceeded' }))
+      return NextResponse.json(quota, { status: 402 })
+    }
+
     if (!ragResponse.ok) {
       return NextResponse.json(
         { success: false, error: 'RAG pipeline unavailable' },
@@ -34,6 +34,27 @@ export function backendHeaders(userId?: string): Record<string, string> {
   return headers
 }
 
+/**
+ * Headers carrying the end user's address and User-Agent, so the backend's
+ * audit entries record the user rather than this proxy. The backend only
+ * honours them on requests with a valid X-Internal-Auth secret.
+ */
+export function clientHeaders(request: Request): Record<string, string> {
+  const headers: Record<string, string> = {}
+  // Cloud Run's front end appends the address it saw; entries to its left
+  // are supplied by the client and cannot be trusted.
+  const forwarded = request.headers.get('x-forwarded-for')
+  const ip = forwarded?.split(',').pop()?.trim()
+  if (ip) {
+    headers['X-Client-IP'] = ip
+  }
+  const userAgent = request.headers.get('user-agent')
+  if (userAgent) {
+    headers['X-Client-User-Agent'] = userAgent
+  }
+  return headers
+}
+
 interface ProxyOptions {
   /** Override the backend path (defaults to request pathname). */
   backendPath?: string
@@ -86,6 +107,7 @@ export async function proxyToBackend(
   const headers: Record<string, string> = {
     'X-Internal-Auth': INTERNAL_AUTH_SECRET,
     'X-User-ID': userId,
+    ...clientHeaders(request),
   }
 
   const contentType = request.headers.get('content-type')
@@ -1,12 +1,13 @@
 /**
  * CyGraph Extraction Trigger
  *
- * Fetches chunks for a document from the database and triggers
+ * Fetches chunks for a document from the Go backend and triggers
  * entity/claim/relationship extraction. Designed to be called
  * fire-and-forget after document ingestion completes.
  */
 
 import prisma from '@/lib/prisma'
+import { GO_BACKEND_URL, backendHeaders } from '@/lib/backend-proxy'
 import { extractFromChunks } from './extractionService'
 import { correlateConversationEntities } from './conversationCorrelation'
 import { shouldRunAnalysis, runProactiveAnalysis } from './proactiveDetection'
@@ -14,7 +15,7 @@ import { logger } from '@/lib/logger'
 
 /**
  * Trigger CyGraph extraction for a document.
- * Fetches chunks from the database, then runs async extraction.
+ * Fetches chunks from the Go backend, then runs async extraction.
  * Non-blocking — logs errors but never throws.
  */
 export async function triggerDocumentExtraction(
@@ -22,19 +23,16 @@ export async function triggerDocumentExtraction(
   tenantId: string
 ): Promise<void> {
   try {
-    // Fetch chunks from the database (created by Go backend ingestion)
-    const chunks = await prisma.$queryRawUnsafe<Array<{
-      id: string
-      content: string
-      document_id: string
-      chunk_index: number
-    }>>(
-      `SELECT id, content, document_id, chunk_index
-       FROM document_chunks
-       WHERE document_id = $1
-       ORDER BY chunk_index ASC`,
-      documentId
-    )
+    // Fetch chunks from the Go backend, which created them at ingestion and
+    // decrypts their content (it is encrypted at rest).
+    const res = await fetch(`${GO_BACKEND_URL}/api/documents/${documentId}/chunks`, {
+      headers: backendHeaders(tenantId),
+    })
+    if (!res.ok) {
+      throw new Error(`Backend returned ${res.status} listing chunks`)
+    }
+    const body = await res.json()
+    const chunks: Array<{ id: string; content: string; documentId: string }> = body?.data?.chunks ?? []
 
     if (chunks.length === 0) {
       logger.info('[CyGraph] No chunks found for document — skipping extraction', { documentId })
@@ -44,7 +42,7 @@ export async function triggerDocumentExtraction(
     const chunkInputs = chunks.map(c => ({
       id: c.id,
       content: c.content,
-      documentId: c.document_id,
+      documentId: c.documentId,
       pageNumber: undefined,
     }))
 
@@ -49,6 +49,7 @@ export interface Document {
   deletionStatus: DeletionStatus
   deletedAt: string | null
   hardDeleteScheduledAt: string | null
+  /** Not read or written here: the column is encrypted at rest. Use fetchExtractedText. */
   extractedText?: string
   vaultId?: string
   folderId?: string
@@ -77,7 +78,6 @@ function mapPrismaDocument(row: {
   deletionStatus: string
   deletedAt: Date | null
   hardDeleteAt: Date | null
-  extractedText: string | null
   vaultId: string | null
   folderId: string | null
 }): Document {
@@ -108,7 +108,6 @@ function mapPrismaDocument(row: {
     deletionStatus: row.deletionStatus as DeletionStatus,
     deletedAt: row.deletedAt?.toISOString() ?? null,
     hardDeleteScheduledAt: row.hardDeleteAt?.toISOString() ?? null,
-    extractedText: row.extractedText ?? undefined,
     vaultId: row.vaultId ?? undefined,
     folderId: row.folderId ?? undefined,
   }
@@ -159,7 +158,6 @@ export async function setDocument(doc: Document): Promise<void> {
       metadata: doc.metadata ? JSON.parse(JSON.stringify(doc.metadata)) : undefined,
       deletedAt: doc.deletedAt ? new Date(doc.deletedAt) : null,
       hardDeleteAt: doc.hardDeleteScheduledAt ? new Date(doc.hardDeleteScheduledAt) : null,
-      extractedText: doc.extractedText ?? null,
       vaultId: doc.vaultId ?? null,
       folderId: doc.folderId ?? null,
     },
@@ -175,7 +173,6 @@ export async function setDocument(doc: Document): Promise<void> {
       metadata: doc.metadata ? JSON.parse(JSON.stringify(doc.metadata)) : undefined,
       deletedAt: doc.deletedAt ? new Date(doc.deletedAt) : null,
       hardDeleteAt: doc.hardDeleteScheduledAt ? new Date(doc.hardDeleteScheduledAt) : null,
-      extractedText: doc.extractedText ?? null,
       vaultId: doc.vaultId ?? null,
       folderId: doc.folderId ?? null,
     },
@@ -0,0 +1,24 @@
+/**
+ * Document Text - RAGbox.co
+ *
+ * The Go backend seals documents.extracted_text and document_chunks.content
+ * with per-tenant data keys, so reading those columns through Prisma returns
+ * ciphertext. Read document text through the backend, which decrypts it.
+ */
+
+import { GO_BACKEND_URL, backendHeaders } from '@/lib/backend-proxy'
+
+/**
+ * Fetch a document's decrypted extracted text as userId. Returns null when
+ * the document has no text; throws when the backend refuses or fails.
+ */
+export async function fetchExtractedText(documentId: string, userId: string): Promise<string | null> {
+  const res = await fetch(`${GO_BACKEND_URL}/api/documents/${documentId}`, {
+    headers: backendHeaders(userId),
+  })
+  if (!res.ok) {
+    throw new Error(`Backend returned ${res.status} for document ${documentId}`)
+  }
+  const body = await res.json()
+  return body?.data?.extractedText ?? null
+}
@@ -447,6 +447,13 @@ describe('Meeting transcript.saved → summary', () => {
       }),
     )
 
+    // Transcript text goes to the backend to be sealed, never through Prisma
+    expect(mockDocumentCreate.mock.calls[0][0].data).not.toHaveProperty('extractedText')
+    expect(global.fetch).toHaveBeenCalledWith(
+      expect.stringContaining('/api/documents/doc-transcript/text'),
+      expect.objectContaining({ method: 'PUT' }),
+    )
+
     // 5. Audit record written
     expect(mockActionCreate).toHaveBeenCalledWith(
       expect.objectContaining({
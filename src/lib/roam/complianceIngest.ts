@@ -89,7 +89,6 @@ export async function ingestDailyCompliance(date: string, apiKey?: string): Prom
           mimeType: 'text/plain',
           fileType: 'txt',
           sizeBytes: Buffer.byteLength(docContent, 'utf-8'),
-          extractedText: docContent,
           indexStatus: 'Pending',
           metadata: {
             source: 'roam_compliance',
@@ -101,6 +100,21 @@ export async function ingestDailyCompliance(date: string, apiKey?: string): Prom
         },
       })
 
+      // The backend stores the text so it is sealed at rest
+      const stored = await fetch(`${GO_BACKEND_URL}/api/documents/${doc.id}/text`, {
+        method: 'PUT',
+        headers: {
+          'Content-Type': 'application/json',
+          'X-Internal-Auth': INTERNAL_AUTH_SECRET,
+          'X-User-ID': DEFAULT_USER_ID,
+        },
+        body: JSON.stringify({ text: docContent }),
+      }).then((res) => res.ok, () => false)
+      if (!stored) {
+        await prisma.document.delete({ where: { id: doc.id } })
+        throw new Error('backend did not store document text')
+      }
+
       // Trigger indexing via Go backend
       try {
         await fetch(`${GO_BACKEND_URL}/api/documents/${doc.id}/index`, {
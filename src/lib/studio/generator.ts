@@ -9,6 +9,7 @@ import { storageClient } from '@/lib/gcp/storage-client'
 import { TextToSpeechClient } from '@google-cloud/text-to-speech'
 import { prisma } from '@/lib/prisma'
 import { deletion_status } from '@prisma/client'
+import { fetchExtractedText } from '@/lib/documents/text'
 import { buildGenerationPrompt, getSystemPromptForArtifact } from './prompts'
 import type {
   ArtifactType,
@@ -52,7 +53,6 @@ async function fetchDocumentContent(documentIds: string[], userId: string): Prom
     select: {
       id: true,
       originalName: true,
-      extractedText: true,
     },
   })
 
@@ -60,9 +60,12 @@ async function fetchDocumentContent(documentIds: string[], userId: string): Prom
     throw new Error('No accessible documents found')
   }
 
+  // extractedText is encrypted at rest; the backend decrypts it.
+  const texts = await Promise.all(documents.map((doc) => fetchExtractedText(doc.id, userId)))
+
   // Combine document content with source attribution
   return documents
-    .map((doc) => `[Source: ${doc.originalName}]\n${doc.extractedText || '[No text extracted]'}`)
+    .map((doc, i) => `[Source: ${doc.originalName}]\n${texts[i] || '[No text extracted]'}`)
     .join('\n\n---\n\n')
 }
 